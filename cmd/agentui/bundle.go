@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/app"
+	"github.com/flight505/agentui/internal/bundle"
+	"github.com/flight505/agentui/internal/config"
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/history"
+	"github.com/flight505/agentui/internal/journal"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// runBundle packs a saved session into a bundle to share, with the theme
+// it is viewed in.
+func runBundle(args []string) error {
+	fs := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: agentui-tui bundle [flags] session")
+		fmt.Fprintln(fs.Output(), "The session is named as in the history, \"last\" for the most recent, or is a session file such as one written with --record.")
+		fs.PrintDefaults()
+	}
+	historyDir := fs.String("history-dir", configuredSessionsDir(), "Directory of saved sessions")
+	output := fs.String("o", "", "Bundle file to write (default: the session's name with "+bundle.Ext+")")
+	themeName := fs.String("theme", "", "Theme to bundle (default: the configured theme)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one session")
+	}
+
+	name, path, err := findSession(fs.Arg(0), *historyDir)
+	if err != nil {
+		return err
+	}
+	data, err := journal.Read(path)
+	if err != nil {
+		return err
+	}
+	// Sessions from the history have their environment saved beside them
+	var env *environment.Snapshot
+	if store := history.NewStore(*historyDir); *historyDir != "" && path == store.Path(name) {
+		if env, err = store.Environment(name); err != nil {
+			return err
+		}
+	}
+
+	if *themeName == "" {
+		*themeName = "charm-dark"
+		if path, err := config.Path(); err == nil {
+			if cfg, err := config.Load(path); err == nil && cfg.Theme != "" {
+				*themeName = cfg.Theme
+			}
+		}
+	}
+	t, ok := theme.Available[*themeName]
+	if !ok {
+		return fmt.Errorf("unknown theme: %s", *themeName)
+	}
+
+	if *output == "" {
+		*output = name + bundle.Ext
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	manifest, err := bundle.Write(f, data, t, bundle.Manifest{
+		Session:     name,
+		Title:       history.Title(data),
+		AgentUI:     version,
+		Environment: env,
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+
+	fmt.Printf("Wrote %s: %d messages, theme %s\n", *output, manifest.Messages, manifest.Theme)
+	if len(manifest.MissingBlobs) > 0 {
+		fmt.Printf("Left out files whose copies are gone: %s\n", strings.Join(manifest.MissingBlobs, ", "))
+	}
+	return nil
+}
+
+// findSession returns the name and file of a session given as a file, or
+// as named in the history store in dir.
+func findSession(arg, dir string) (string, string, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		return strings.TrimSuffix(info.Name(), ".jsonl"), arg, nil
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", "", err
+	}
+	if dir == "" {
+		return "", "", fmt.Errorf("no session file %s, and no --history-dir to look in", arg)
+	}
+	store := history.NewStore(dir)
+	name, err := store.Resolve(arg)
+	if err != nil {
+		return "", "", err
+	}
+	return name, store.Path(name), nil
+}
+
+// runOpen shows the session in a bundle, read-only, in the theme it was
+// bundled with.
+func runOpen(args []string) error {
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: agentui-tui open [flags] bundle"+bundle.Ext)
+		fs.PrintDefaults()
+	}
+	themeName := fs.String("theme", "", "Color theme (default: the bundled theme)")
+	links := fs.String("links", views.LinksAuto, "Emit URLs as clickable OSC 8 hyperlinks: auto, on or off")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one bundle file")
+	}
+
+	b, err := bundle.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *themeName == "" && b.Theme != nil {
+		theme.Register(b.Theme)
+		*themeName = b.Theme.ID
+	}
+	if *themeName == "" {
+		*themeName = "charm-dark"
+	}
+	if !theme.SetTheme(*themeName) {
+		return fmt.Errorf("unknown theme: %s", *themeName)
+	}
+	switch *links {
+	case views.LinksAuto, views.LinksOn, views.LinksOff:
+		views.SetHyperlinks(views.ResolveHyperlinks(*links, os.Getenv))
+	default:
+		return fmt.Errorf("invalid --links value: %s (want auto, on or off)", *links)
+	}
+
+	blobs, err := protocol.NewBlobStore()
+	if err != nil {
+		return err
+	}
+	defer blobs.Close()
+
+	tagline := b.Manifest.Title
+	if tagline == "" {
+		tagline = b.Manifest.Session
+	}
+	handler := protocol.NewHandler(strings.NewReader(""), io.Discard)
+	model := app.NewModel(handler, "AgentUI", "Read-only: "+tagline).WithOptions(app.Options{
+		Limits:    app.DefaultContentLimits(),
+		FoldLines: app.DefaultFoldLines,
+		Blobs:     blobs,
+		Resume:    b.Messages,
+		ReadOnly:  true,
+	})
+	_, err = tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+	return err
+}
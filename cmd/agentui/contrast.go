@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// runContrast checks themes for colors with too little contrast. Arguments
+// are theme IDs or JSON theme files; with none, every built-in theme is
+// checked. It fails if any theme has issues.
+func runContrast(args []string) error {
+	fs := flag.NewFlagSet("contrast", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: agentui-tui contrast [theme-id | theme.json]...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		for id := range theme.Available {
+			names = append(names, id)
+		}
+		sort.Strings(names)
+	}
+
+	failed := 0
+	for _, name := range names {
+		t, ok := theme.Available[name]
+		if !ok {
+			var err error
+			if t, err = theme.LoadThemeFromFile(name); err != nil {
+				if os.IsNotExist(err) {
+					return fmt.Errorf("unknown theme: %s", name)
+				}
+				return err
+			}
+		}
+
+		issues := theme.CheckContrast(t)
+		if len(issues) == 0 {
+			fmt.Printf("✓ %s\n", t.ID)
+			continue
+		}
+		failed++
+		fmt.Printf("✗ %s: %d issues\n", t.ID, len(issues))
+		for _, issue := range issues {
+			fmt.Printf("    %s\n", issue)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d themes have too little contrast", failed, len(names))
+	}
+	return nil
+}
@@ -3,15 +3,30 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 
 	"github.com/flight505/agentui/internal/app"
+	"github.com/flight505/agentui/internal/clock"
+	"github.com/flight505/agentui/internal/config"
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/history"
+	"github.com/flight505/agentui/internal/journal"
 	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/replay"
+	"github.com/flight505/agentui/internal/schedule"
+	"github.com/flight505/agentui/internal/telemetry"
 	"github.com/flight505/agentui/internal/theme"
 	"github.com/flight505/agentui/internal/ui/views"
 )
@@ -20,7 +35,28 @@ var (
 	version = "0.1.0"
 )
 
+// subcommands are run instead of the TUI when named as the first argument.
+var subcommands = map[string]func(args []string) error{
+	"bundle":    runBundle,
+	"contrast":  runContrast,
+	"open":      runOpen,
+	"replay":    runReplay,
+	"schema":    runSchema,
+	"stress":    runStress,
+	"telemetry": runTelemetry,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+
 	// Command line flags
 	themeName := flag.String("theme", "charm-dark", "Color theme")
 	appName := flag.String("name", "AgentUI", "Application name")
@@ -28,6 +64,63 @@ func main() {
 	showVersion := flag.Bool("version", false, "Show version")
 	listThemes := flag.Bool("list-themes", false, "List available themes")
 	headless := flag.Bool("headless", false, "Run in headless mode for testing")
+	compressThreshold := flag.Int("compress-threshold", 0, "Gzip outgoing payloads of at least this many bytes (0 disables)")
+	statusRate := flag.Int("status-rate", app.DefaultStatusRate, "Draw floods of status and progress updates at most this many times a second, showing the latest (0 draws every update)")
+	budget := flag.Float64("budget", 0, "Session budget in dollars: warn and tell the agent when the cost it reports in metadata messages goes over it (0 disables)")
+	tokenBudget := flag.Int("token-budget", 0, "Session budget in tokens, as for --budget (0 disables)")
+	maxCodeLines := flag.Int("max-code-lines", app.DefaultMaxCodeLines, "Draw at most this many lines of a code block, with the rest in a full view opened with o (0 draws all)")
+	maxTableRows := flag.Int("max-table-rows", app.DefaultMaxTableRows, "Draw at most this many rows of a table, as for --max-code-lines")
+	maxMarkdown := flag.Int("max-markdown-bytes", app.DefaultMaxMarkdownBytes, "Draw at most this many bytes of a reply, as for --max-code-lines")
+	foldLines := flag.Int("fold-lines", app.DefaultFoldLines, "Fold replies longer than this many lines to a preview, expanded with o (0 never folds)")
+	revealEscapes := flag.Bool("reveal-escapes", false, "Show terminal escape sequences stripped from agent text as visible text, such as ␛[2J, to debug an agent")
+	flowWindow := flag.Int("flow-window", 0, "Grant the agent credits for at most this many messages waiting to be shown, with ready_for_more messages (0 disables)")
+	strict := flag.Bool("strict-protocol", false, "Reject malformed messages, unknown payload fields and invalid values, replying to the agent with protocol_error")
+	links := flag.String("links", views.LinksAuto, "Emit URLs as clickable OSC 8 hyperlinks: auto, on or off")
+	record := flag.String("record", "", "Record incoming protocol messages to this file for replay")
+	journalPath := flag.String("journal", "", "Journal incoming messages to this file before showing them, so --resume-journal can rebuild the transcript after a crash")
+	resumeJournal := flag.String("resume-journal", "", "Rebuild the transcript from this journal, then keep journaling to it")
+	historyDefault, _ := history.DefaultDir()
+	historyDir := flag.String("history-dir", historyDefault, "Save each session's transcript in this directory, to reopen with --resume or ctrl+s (empty disables)")
+	promptsDefault, _ := history.DefaultPromptsPath()
+	inputHistory := flag.String("input-history", promptsDefault, "Keep the prompts you send in this file, to recall with Up at an empty prompt in later sessions (empty keeps them for this session only)")
+	resumeSession := flag.String("resume", "", "Reopen a saved session by name, or \"last\" for the most recent, and continue it")
+	protocolLog := flag.String("protocol-log", "", "Log every message sent and received, with timestamps, to this JSONL file")
+	protocolLogRedact := flag.Bool("protocol-log-redact", true, "Redact secret values in the protocol log")
+	keepAlive := flag.Duration("keepalive", 0, "Send keepalive events at this interval while composing (e.g. 30s, 0 disables)")
+	typingIdle := flag.Duration("typing-idle", 0, "Send typing start/stop events, stopping after this long without a keystroke (e.g. 2s, 0 disables)")
+	heartbeat := flag.Duration("heartbeat", 0, "Ping the agent at this interval to detect when it stops responding (e.g. 5s, 0 disables)")
+	startupTimeout := flag.Duration("startup-timeout", 0, "Keep the input closed until the agent sends its first message, and show what to check if none arrives in this long (e.g. 30s, 0 opens the input at once)")
+	protocolFD := flag.Int("protocol-fd", 0, "Speak the protocol over this inherited file descriptor, such as one end of a socket pair, instead of stdin/stdout, which stay with the terminal (e.g. 3, 0 disables)")
+	tcpAddr := flag.String("tcp", "", "Accept an agent connection on this host:port instead of using stdin/stdout")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file for --tcp")
+	tlsKey := flag.String("tls-key", "", "TLS private key file for --tcp")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA certificate file for --tcp with TLS: only agents presenting a client certificate it signed may connect (mutual TLS)")
+	spawn := flag.Bool("spawn", false, "Run the agent command given after the flags, talking to it over its stdin/stdout, and offer to restart it when it exits")
+	restartAttempts := flag.Int("restart-attempts", 5, "With --spawn, restart a crashed agent by itself at most this many times in a row, backing off exponentially (0 always asks)")
+	reattach := flag.String("reattach", "", "When stdin closes, let a restarted agent resume the session by connecting to this host:port or Unix socket path")
+	encodingName := flag.String("encoding", "json", "Wire encoding: json (JSON Lines) or msgpack")
+	framingName := flag.String("framing", "lines", "Message framing: lines, or length for a 4-byte length prefix")
+	rpcName := flag.String("rpc", "native", "Message dialect: native, or jsonrpc for JSON-RPC 2.0 requests and notifications")
+	setup := flag.Bool("setup", false, "Run the first-run setup again")
+	clockName := flag.String("clock", clock.Locale, "Hour cycle for times: 12h or 24h (default: from the locale)")
+	utc := flag.Bool("utc", false, "Show times in UTC")
+	timestamps := flag.String("timestamps", app.TimestampsMinute, "When messages arrived: minute (centered when the minute changes), message (beside every message), relative (how long ago) or off, which also hides how long replies took")
+	timeFormat := flag.String("time-format", "", "Layout for times in Go's reference format, such as 15:04:05 (default: from the hour cycle)")
+	tabOrderList := flag.String("tab-order", strings.Join(app.DefaultTabOrder, ","), "Order in which Tab moves focus through the chat view's panels")
+	colorVision := flag.String("color-vision", theme.VisionDefault, "Color blind safe status colors: deuteranopia or protanopia")
+	glyphs := flag.String("glyphs", "", "Glyph profile: unicode or ascii (default: the theme's)")
+	layout := flag.String("layout", app.LayoutFull, "How the chat uses a wide terminal: full, center (at most --max-width wide) or columns (with pinned messages beside it)")
+	maxWidth := flag.Int("max-width", app.DefaultMaxWidth, "Widest the chat grows in the center and columns layouts")
+	retryReplaces := flag.Bool("retry-replaces", false, "Retrying the last exchange (ctrl+r, /retry) removes the answer it replaces from the transcript; ctrl+z puts it back")
+	turnTiming := flag.Bool("turn-timing", false, "Show under each turn how long the agent took to start answering, stream its reply and finish")
+	agentControl := flag.Bool("agent-control", false, "Let the agent scroll the transcript, focus the input and collapse messages")
+	hints := flag.Bool("hints", true, "Show keyboard hints under prompts and viewers")
+	showSidebar := flag.Bool("sidebar", false, "Start with the sidebar of recent sessions, tool calls and pinned artifacts shown (ctrl+b toggles it)")
+	var scheduleSpecs []string
+	flag.Func("schedule", "Send the agent a prompt, or a tick event, on a schedule while the session is open, e.g. \"every 15m: Summarize new errors\" or \"0 9 * * 1-5: Daily report\" (repeatable)", func(spec string) error {
+		scheduleSpecs = append(scheduleSpecs, spec)
+		return nil
+	})
 	flag.Parse()
 
 	if *showVersion {
@@ -47,6 +140,81 @@ func main() {
 		os.Exit(0)
 	}
 
+	// User configuration, set up on first launch. Flags take precedence.
+	var cfg config.Config
+	if !*headless {
+		cfg = loadConfig(*setup)
+	}
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	if cfg.Theme != "" && !explicit["theme"] {
+		if _, ok := theme.Available[cfg.Theme]; ok {
+			*themeName = cfg.Theme
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring unknown theme in configuration: %s\n", cfg.Theme)
+		}
+	}
+
+	if !explicit["clock"] {
+		*clockName = cfg.Clock
+	}
+	if !explicit["utc"] {
+		*utc = cfg.UTC
+	}
+	if !explicit["history-dir"] {
+		*historyDir = sessionsDir(cfg)
+	}
+	if !explicit["agent-control"] {
+		*agentControl = cfg.AgentControl
+	}
+	times, err := clock.New(*clockName, *utc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid clock setting: %v\n", err)
+		os.Exit(1)
+	}
+	times.Layout = *timeFormat
+
+	if !explicit["schedule"] {
+		scheduleSpecs = cfg.Schedules
+	}
+	schedules := make([]schedule.Schedule, 0, len(scheduleSpecs))
+	for _, spec := range scheduleSpecs {
+		s, err := schedule.Parse(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid schedule: %v\n", err)
+			os.Exit(1)
+		}
+		schedules = append(schedules, s)
+	}
+
+	if len(cfg.TabOrder) > 0 && !explicit["tab-order"] {
+		*tabOrderList = strings.Join(cfg.TabOrder, ",")
+	}
+	tabOrder, err := app.ParseTabOrder(*tabOrderList)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid tab order: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !explicit["color-vision"] {
+		*colorVision = cfg.ColorVision
+	}
+	if err := theme.SetColorVision(*colorVision); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid color vision: %v\n", err)
+		os.Exit(1)
+	}
+	if !explicit["hints"] {
+		*hints = !cfg.HideHints
+	}
+	theme.Hints = *hints
+	if !explicit["glyphs"] {
+		*glyphs = cfg.Glyphs
+	}
+	if err := theme.SetGlyphs(*glyphs); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid glyph profile: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Set theme
 	if !theme.SetTheme(*themeName) {
 		fmt.Fprintf(os.Stderr, "Unknown theme: %s\n", *themeName)
@@ -54,38 +222,392 @@ func main() {
 		os.Exit(1)
 	}
 
+	switch *layout {
+	case app.LayoutFull, app.LayoutCenter, app.LayoutColumns:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid --layout value: %s (want full, center or columns)\n", *layout)
+		os.Exit(1)
+	}
+
+	switch *timestamps {
+	case app.TimestampsMinute, app.TimestampsMessage, app.TimestampsRelative, app.TimestampsOff:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid --timestamps value: %s (want minute, message, relative or off)\n", *timestamps)
+		os.Exit(1)
+	}
+
+	switch *links {
+	case views.LinksAuto, views.LinksOn, views.LinksOff:
+		views.SetHyperlinks(views.ResolveHyperlinks(*links, os.Getenv))
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid --links value: %s (want auto, on or off)\n", *links)
+		os.Exit(1)
+	}
+
 	// Headless mode for testing
 	if *headless {
-		if err := runHeadless(); err != nil {
+		if err := runHeadless(*strict); err != nil {
 			fmt.Fprintf(os.Stderr, "Error in headless mode: %v\n", err)
 			os.Exit(1)
 		}
 		os.Exit(0)
 	}
 
-	// Create protocol handler for stdin/stdout
+	// Create protocol handler for stdin/stdout, or for agents connecting
+	// over the network
 	handler := protocol.NewHandler(os.Stdin, os.Stdout)
+	var spawner *protocol.Spawner
+	switch {
+	case *protocolFD != 0 && (*spawn || *tcpAddr != ""):
+		fmt.Fprintln(os.Stderr, "--protocol-fd cannot be combined with --spawn or --tcp")
+		os.Exit(1)
+	case *protocolFD != 0:
+		f, err := protocolFile(*protocolFD)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot use --protocol-fd: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		handler = protocol.NewHandler(f, f)
+	case *spawn && (*tcpAddr != "" || *reattach != ""):
+		fmt.Fprintln(os.Stderr, "--spawn cannot be combined with --tcp or --reattach")
+		os.Exit(1)
+	case *spawn && flag.NArg() == 0:
+		fmt.Fprintln(os.Stderr, "--spawn needs the agent command after the flags, e.g. agentui-tui --spawn -- python agent.py")
+		os.Exit(1)
+	case *spawn:
+		spawner = protocol.NewSpawner(flag.Args())
+		handler = protocol.NewListenerHandler(spawner)
+	case flag.NArg() > 0:
+		fmt.Fprintf(os.Stderr, "Unexpected arguments: %s (use --spawn to run an agent)\n", strings.Join(flag.Args(), " "))
+		os.Exit(1)
+	}
+	if *tcpAddr != "" {
+		ln, err := listen(*tcpAddr, *tlsCert, *tlsKey, *tlsClientCA)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot listen: %v\n", err)
+			os.Exit(1)
+		}
+		handler = protocol.NewListenerHandler(ln)
+	} else if *tlsCert != "" || *tlsKey != "" || *tlsClientCA != "" {
+		fmt.Fprintln(os.Stderr, "--tls-cert, --tls-key and --tls-client-ca require --tcp")
+		os.Exit(1)
+	}
+	if *reattach != "" {
+		if *tcpAddr != "" {
+			fmt.Fprintln(os.Stderr, "--reattach is for stdin/stdout agents; with --tcp agents can always reconnect")
+			os.Exit(1)
+		}
+		network := "tcp"
+		if strings.Contains(*reattach, "/") {
+			network = "unix"
+		}
+		ln, err := net.Listen(network, *reattach)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot listen for reattaching agents: %v\n", err)
+			os.Exit(1)
+		}
+		handler.SetReattach(ln)
+	}
+	encoding, err := protocol.ParseEncoding(*encodingName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	handler.SetEncoding(encoding)
+	framing, err := protocol.ParseFraming(*framingName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	handler.SetFraming(framing)
+	rpc, err := protocol.ParseRPC(*rpcName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	handler.SetRPC(rpc)
+	handler.SetCompressThreshold(*compressThreshold)
+	handler.SetTerminalInfo(protocol.TerminalInfo{
+		TrueColor:  lipgloss.ColorProfile() == termenv.TrueColor,
+		Hyperlinks: views.HyperlinksEnabled(),
+	})
+	handler.SetStrict(*strict)
+	blobs, err := protocol.NewBlobStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot keep blobs: %v\n", err)
+		os.Exit(1)
+	}
+	defer blobs.Close()
+	if sharesFilesystem(handler) {
+		handler.SetBlobDir(blobs.Dir())
+	}
+	handler.SetFlowWindow(*flowWindow)
+	if *protocolLog != "" {
+		f, err := os.Create(*protocolLog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot open protocol log: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		handler.SetTrafficLog(protocol.NewTrafficLog(f, *protocolLogRedact))
+	}
+	if *record != "" {
+		f, err := os.Create(*record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot record session: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		handler.SetRecorder(f)
+	}
+	j, resume, err := openJournal(*journalPath, *resumeJournal)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open journal: %v\n", err)
+		os.Exit(1)
+	}
+	if j != nil {
+		defer j.Close()
+		handler.SetJournal(j)
+	}
+	sessions, resumed, err := openHistory(*historyDir, *resumeSession, j != nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open session history: %v\n", err)
+		os.Exit(1)
+	}
+	env := environment.Capture(version, *themeName)
+	if sessions != nil {
+		defer sessions.Close()
+		handler.SetJournal(sessions)
+		sessions.SetEnvironment(env)
+		resume = resumed
+	}
+	// Without its input history the TUI still recalls this session's
+	prompts, err := history.OpenPrompts(*inputHistory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot read input history: %v\n", err)
+	}
 	handler.Start()
 	defer handler.Stop()
 
+	// Usage statistics, if the user opted in
+	var usage *telemetry.Recorder
+	if cfg.Telemetry {
+		usage = openTelemetry()
+		usage.Count("encoding:" + string(encoding))
+		usage.Count("framing:" + string(framing))
+		usage.Count("rpc:" + string(rpc))
+		if *tcpAddr != "" {
+			usage.Count("tcp")
+		}
+		if *tlsClientCA != "" {
+			usage.Count("mtls")
+		}
+		if *spawn {
+			usage.Count("spawn")
+		}
+		if *budget > 0 || *tokenBudget > 0 {
+			usage.Count("budget")
+		}
+		if *resumeSession != "" {
+			usage.Count("resume")
+		}
+	}
+
 	// Create and run the TUI
-	model := app.NewModel(handler, *appName, *tagline)
+	model := app.NewModel(handler, *appName, *tagline).WithOptions(app.Options{
+		KeepAliveInterval: *keepAlive,
+		TypingIdle:        *typingIdle,
+		HeartbeatInterval: *heartbeat,
+		StartupTimeout:    *startupTimeout,
+		Sidebar:           *showSidebar,
+		StatusRate:        *statusRate,
+		Budget:            *budget,
+		TokenBudget:       *tokenBudget,
+		RevealEscapes:     *revealEscapes,
+		Clock:             times,
+		Timestamps:        *timestamps,
+		TabOrder:          tabOrder,
+		AgentControl:      *agentControl,
+		TurnTiming:        *turnTiming,
+		RetryReplaces:     *retryReplaces,
+		Layout:            *layout,
+		MaxWidth:          *maxWidth,
+		Telemetry:         usage,
+		Blobs:             blobs,
+		Resume:            resume,
+		History:           sessions,
+		InputHistory:      prompts,
+		Environment:       &env,
+		CrashDir:          crashDir(),
+		Spawner:           spawner,
+		RestartAttempts:   *restartAttempts,
+		FoldLines:         *foldLines,
+		Schedules:         schedules,
+		Limits: app.ContentLimits{
+			CodeLines:     *maxCodeLines,
+			TableRows:     *maxTableRows,
+			MarkdownBytes: *maxMarkdown,
+		},
+	})
 
 	p := tea.NewProgram(
 		model,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
+		tea.WithOutput(model.Output(os.Stdout)),
 	)
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err := usage.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot save usage statistics: %v\n", err)
+	}
+	// Bubbletea returns no model after catching a panic
+	if final == nil && err == nil {
+		if dir := crashDir(); dir != "" {
+			fmt.Fprintf(os.Stderr, "A crash report was saved in %s; please attach it to a bug report.\n", dir)
+		}
+		os.Exit(1)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// crashDir returns the directory crash reports are written to, or empty
+// if there is nowhere to keep them.
+func crashDir() string {
+	dir, err := history.DataDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "crashes")
+}
+
+// openJournal starts the journal for --journal, or for --resume-journal
+// continues it, returning the messages to rebuild the transcript from.
+func openJournal(path, resume string) (*journal.Journal, []*protocol.Message, error) {
+	switch {
+	case resume == "" && path == "":
+		return nil, nil, nil
+	case resume == "":
+		j, err := journal.Create(path, journal.DefaultSize)
+		return j, nil, err
+	case path != "" && path != resume:
+		return nil, nil, fmt.Errorf("--journal and --resume-journal name different files")
+	}
+
+	data, err := journal.Read(resume)
+	if err != nil {
+		return nil, nil, err
+	}
+	msgs, err := replay.ReadSession(bytes.NewReader(data), false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", resume, err)
+	}
+	j, err := journal.Open(resume, journal.DefaultSize)
+	return j, msgs, err
+}
+
+// openHistory starts saving the session to the history store in dir, or
+// for --resume continues the session named, returning the messages to
+// rebuild the transcript from. A journal given with --journal or
+// --resume-journal records the session instead.
+func openHistory(dir, resume string, journaling bool) (*history.Recorder, []*protocol.Message, error) {
+	switch {
+	case resume != "" && journaling:
+		return nil, nil, fmt.Errorf("--resume cannot be combined with --journal or --resume-journal")
+	case resume != "" && dir == "":
+		return nil, nil, fmt.Errorf("--resume needs a --history-dir")
+	case dir == "" || journaling:
+		return nil, nil, nil
+	}
+
+	r := history.NewRecorder(history.NewStore(dir))
+	if resume == "" {
+		return r, nil, nil
+	}
+	name, err := r.Store().Resolve(resume)
+	if err != nil {
+		return nil, nil, err
+	}
+	msgs, err := r.Resume(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, msgs, nil
+}
+
+// protocolFile opens the inherited file descriptor fd for --protocol-fd.
+// Messages are both read from and written to it, so it must be open for
+// reading and writing, as a socket is.
+func protocolFile(fd int) (*os.File, error) {
+	if fd < 3 {
+		return nil, fmt.Errorf("%d is stdin, stdout or stderr; pass another descriptor such as 3", fd)
+	}
+	f := os.NewFile(uintptr(fd), "protocol")
+	if _, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("descriptor %d is not open", fd)
+	}
+	return f, nil
+}
+
+// sharesFilesystem reports whether agents of handler run on this machine,
+// so they can hand off blobs as files: those on stdin/stdout, spawned ones,
+// and those connecting over loopback.
+func sharesFilesystem(handler *protocol.Handler) bool {
+	addr := handler.ListenAddr()
+	if addr == "" || addr == protocol.SpawnAddr {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// listen opens the TCP listener for --tcp, with TLS if a certificate and key
+// are given. With a client CA too, agents must present a certificate it
+// signed.
+func listen(addr, certFile, keyFile, clientCAFile string) (net.Listener, error) {
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("--tls-client-ca needs --tls-cert and --tls-key")
+		}
+		return net.Listen("tcp", addr)
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no PEM certificates found", clientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tls.Listen("tcp", addr, config)
+}
+
 // runHeadless runs in non-interactive mode for testing.
 // Reads a single JSON message from stdin, renders it, and writes output to stdout.
-func runHeadless() error {
+func runHeadless(strict bool) error {
 	// Read JSON message from stdin
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadBytes('\n')
@@ -94,9 +616,9 @@ func runHeadless() error {
 	}
 
 	// Parse protocol message
-	var msg protocol.Message
-	if err := json.Unmarshal(line, &msg); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
+	msg, err := protocol.DecodeMessage(line, strict)
+	if err != nil {
+		return fmt.Errorf("failed to parse message: %w", err)
 	}
 
 	// Render based on message type
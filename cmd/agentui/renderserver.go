@@ -0,0 +1,60 @@
+//go:build !norenderserver
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/flight505/agentui/internal/renderserver"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// The render server is left out of norenderserver builds.
+func init() {
+	subcommands["render-server"] = runRenderServer
+}
+
+// runRenderServer serves the renderer over HTTP: POST a protocol message,
+// or a JSON array of them, to /render and get back how the TUI draws it.
+func runRenderServer(args []string) error {
+	fs := flag.NewFlagSet("render-server", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: agentui-tui render-server [flags]")
+		fmt.Fprintln(fs.Output(), "\nPOST a message to /render?format=ansi|plain|html&width=N&height=N&theme=NAME")
+		fs.PrintDefaults()
+	}
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	width := fs.Int("width", renderserver.DefaultWidth, "Default width")
+	themeName := fs.String("theme", "charm-dark", "Default color theme")
+	strict := fs.Bool("strict-protocol", false, "Reject messages with unknown fields")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if !theme.SetTheme(*themeName) {
+		return fmt.Errorf("unknown theme: %s", *themeName)
+	}
+	// Output goes to HTTP clients rather than a terminal, so it is always
+	// drawn in full color, for a dark background and without hyperlinks
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	lipgloss.SetHasDarkBackground(true)
+	views.SetHyperlinks(false)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           renderserver.New(renderserver.Options{Width: *width, Theme: *themeName, Strict: *strict}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Printf("Rendering on http://%s/render\n", *addr)
+	return server.ListenAndServe()
+}
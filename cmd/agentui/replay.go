@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/flight505/agentui/internal/app"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/replay"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// runReplay renders a recorded session headlessly. With --assert-frames it
+// compares each frame against the goldens in that directory; with --update
+// it rewrites them instead. Otherwise the final frame is printed.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: agentui-tui replay [flags] session.jsonl")
+		fs.PrintDefaults()
+	}
+	assertDir := fs.String("assert-frames", "", "Compare rendered frames against goldens in this directory")
+	update := fs.Bool("update", false, "Rewrite the goldens in --assert-frames instead of comparing")
+	width := fs.Int("width", 80, "Terminal width")
+	height := fs.Int("height", 24, "Terminal height")
+	themeName := fs.String("theme", "charm-dark", "Color theme")
+	strict := fs.Bool("strict-protocol", false, "Validate recorded messages strictly")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one session file")
+	}
+	if *update && *assertDir == "" {
+		return fmt.Errorf("--update requires --assert-frames")
+	}
+	if !theme.SetTheme(*themeName) {
+		return fmt.Errorf("unknown theme: %s", *themeName)
+	}
+	views.SetHyperlinks(false)
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	msgs, err := replay.ReadSession(f, *strict)
+	if err != nil {
+		return err
+	}
+
+	handler := protocol.NewHandler(strings.NewReader(""), io.Discard)
+	frames := app.NewModel(handler, "AgentUI", "AI Agent Interface").ReplayFrames(msgs, *width, *height)
+
+	switch {
+	case *update:
+		if err := replay.WriteGoldens(*assertDir, frames); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d frames to %s\n", len(frames), *assertDir)
+	case *assertDir != "":
+		if err := replay.AssertFrames(*assertDir, frames); err != nil {
+			return err
+		}
+		fmt.Printf("%d frames match %s\n", len(frames), *assertDir)
+	case len(frames) > 0:
+		fmt.Print(replay.Normalize(frames[len(frames)-1]))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/app"
+	"github.com/flight505/agentui/internal/config"
+	"github.com/flight505/agentui/internal/history"
+)
+
+// loadConfig reads the user configuration, running the first-run setup if
+// there is none yet or if force is set. Problems are reported on stderr and
+// leave the defaults in place.
+func loadConfig(force bool) config.Config {
+	path, err := config.Path()
+	if err != nil {
+		return config.Config{}
+	}
+	cfg, err := config.Load(path)
+	if err == nil && !force {
+		return cfg
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) && !force {
+		fmt.Fprintf(os.Stderr, "Ignoring %s: %v\n", path, err)
+		return config.Config{}
+	}
+
+	suggested, err := history.DefaultDir()
+	if err != nil {
+		suggested = config.DefaultSessionsDir(path)
+	}
+	chosen, ok, err := runSetup(cfg, suggested)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Setup failed: %v\n", err)
+		return cfg
+	}
+	if !ok {
+		return cfg
+	}
+	if err := config.Save(path, chosen); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot save configuration: %v\n", err)
+	}
+	return chosen
+}
+
+// runSetup runs the first-run setup on the terminal. Stdin and stdout carry
+// the protocol, so it talks to the terminal directly; without one the setup
+// is skipped and ok is false.
+func runSetup(cfg config.Config, sessionsDir string) (chosen config.Config, ok bool, err error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return cfg, false, nil
+	}
+	defer tty.Close()
+
+	setup := app.NewOnboarding(cfg, sessionsDir)
+	p := tea.NewProgram(setup, tea.WithInput(tty), tea.WithOutput(tty), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return cfg, false, err
+	}
+	chosen, ok = setup.Result()
+	return chosen, ok, nil
+}
+
+// sessionsDir returns the directory sessions are saved in unless
+// --history-dir is given: the configured one, or the history's default.
+func sessionsDir(cfg config.Config) string {
+	if cfg.SessionsDir != "" {
+		return cfg.SessionsDir
+	}
+	dir, _ := history.DefaultDir()
+	return dir
+}
+
+// configuredSessionsDir is sessionsDir for subcommands, which read the
+// configuration without running the setup.
+func configuredSessionsDir() string {
+	var cfg config.Config
+	if path, err := config.Path(); err == nil {
+		cfg, _ = config.Load(path)
+	}
+	return sessionsDir(cfg)
+}
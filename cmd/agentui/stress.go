@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/app"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/stress"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// runStress generates synthetic traffic at a fixed rate and reports render
+// latencies and dropped frames. By default the model is driven headlessly;
+// with --live it runs in the terminal like a real session.
+func runStress(args []string) error {
+	fs := flag.NewFlagSet("stress", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: agentui-tui stress [flags]")
+		fs.PrintDefaults()
+	}
+	rate := fs.Int("rate", 500, "Messages per second")
+	types := fs.String("types", "text,progress,table", "Comma-separated message types to generate")
+	duration := fs.Duration("duration", 10*time.Second, "How long to generate traffic")
+	live := fs.Bool("live", false, "Render to the terminal instead of headlessly")
+	width := fs.Int("width", 100, "Terminal width in headless mode")
+	height := fs.Int("height", 30, "Terminal height in headless mode")
+	themeName := fs.String("theme", "charm-dark", "Color theme")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rate <= 0 {
+		return fmt.Errorf("--rate must be positive")
+	}
+	if !theme.SetTheme(*themeName) {
+		return fmt.Errorf("unknown theme: %s", *themeName)
+	}
+	views.SetHyperlinks(false)
+
+	gen, err := stress.NewGenerator(*types)
+	if err != nil {
+		return err
+	}
+	stats := stress.NewStats()
+	interval := time.Second / time.Duration(*rate)
+
+	if *live {
+		err = stressLive(gen, stats, interval, *duration)
+	} else {
+		stressHeadless(gen, stats, interval, *duration, *width, *height)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Print(stats.Report())
+	return nil
+}
+
+// stressHeadless applies every message that is due, renders one frame, and
+// repeats, so a slow frame delays and batches the messages behind it. It
+// stops after duration even if the model has fallen behind the rate.
+func stressHeadless(gen *stress.Generator, stats *stress.Stats, interval, duration time.Duration, width, height int) {
+	handler := protocol.NewHandler(strings.NewReader(""), io.Discard)
+	h := app.NewModel(handler, "AgentUI", "AI Agent Interface").Headless(width, height)
+
+	start := time.Now()
+	end := start.Add(duration)
+	next := start
+	var due []string
+	for next.Before(end) && time.Now().Before(end) {
+		if wait := time.Until(next); wait > 0 {
+			time.Sleep(wait)
+		}
+		now := time.Now()
+		for !next.After(now) && now.Before(end) {
+			msg := gen.Next()
+			stats.Sent(msg.ID, next)
+			h.Apply(msg)
+			due = append(due, msg.ID)
+			next = next.Add(interval)
+			now = time.Now()
+		}
+		h.View()
+		stats.Rendered(due, time.Now())
+		due = due[:0]
+	}
+}
+
+// stressLive runs the full TUI with generated traffic piped into its
+// protocol handler, timing each message from send to first render.
+func stressLive(gen *stress.Generator, stats *stress.Stats, interval, duration time.Duration) error {
+	pr, pw := io.Pipe()
+	handler := protocol.NewHandler(pr, io.Discard)
+	handler.Start()
+	defer handler.Stop()
+
+	model := app.NewModel(handler, "AgentUI", "Stress test").WithRenderProbe(func(ids []string) {
+		stats.Rendered(ids, time.Now())
+	})
+	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	go func() {
+		enc := json.NewEncoder(pw)
+		start := time.Now()
+		for next := start; next.Before(start.Add(duration)); next = next.Add(interval) {
+			if wait := time.Until(next); wait > 0 {
+				time.Sleep(wait)
+			}
+			msg := gen.Next()
+			stats.Sent(msg.ID, time.Now())
+			if err := enc.Encode(msg); err != nil {
+				return // TUI exited early
+			}
+		}
+		// Give the last messages a moment to render
+		time.Sleep(500 * time.Millisecond)
+		p.Quit()
+	}()
+
+	_, err := p.Run()
+	pr.Close()
+	return err
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/flight505/agentui/internal/config"
+	"github.com/flight505/agentui/internal/telemetry"
+)
+
+// openTelemetry opens the usage report for this session. Problems are
+// reported on stderr and leave telemetry off.
+func openTelemetry() *telemetry.Recorder {
+	path, err := config.Path()
+	if err != nil {
+		return nil
+	}
+	usage, err := telemetry.Open(telemetry.Path(path), version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Telemetry off: %v\n", err)
+		return nil
+	}
+	return usage
+}
+
+const telemetryUsage = "usage: agentui-tui telemetry show|on|off"
+
+// runTelemetry shows the local usage report or turns telemetry on or off.
+// Turning it off also deletes the report.
+func runTelemetry(args []string) error {
+	if len(args) != 1 {
+		return errors.New(telemetryUsage)
+	}
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	reportPath := telemetry.Path(path)
+
+	switch args[0] {
+	case "show":
+		state := "off"
+		if cfg.Telemetry {
+			state = "on"
+		}
+		fmt.Printf("Telemetry is %s. Change it with: agentui-tui telemetry on|off\n", state)
+		fmt.Printf("Report: %s\n\n", reportPath)
+
+		report, err := telemetry.Load(reportPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Println("Nothing has been recorded.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+
+	case "on", "off":
+		cfg.Telemetry = args[0] == "on"
+		if err := config.Save(path, cfg); err != nil {
+			return err
+		}
+		if !cfg.Telemetry {
+			if err := os.Remove(reportPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		fmt.Printf("Telemetry is %s.\n", args[0])
+		return nil
+
+	default:
+		return errors.New(telemetryUsage)
+	}
+}
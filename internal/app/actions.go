@@ -0,0 +1,190 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flight505/agentui/internal/export"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/ui/components"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// messageAction is an entry in the message actions menu.
+type messageAction string
+
+const (
+	actionSaveCSV      messageAction = "Save table as CSV"
+	actionSaveCode     messageAction = "Save code to file"
+	actionSaveMarkdown messageAction = "Save as Markdown (.md)"
+	actionShowRaw      messageAction = "Show raw JSON"
+	actionHideRaw      messageAction = "Hide raw JSON"
+	actionExpandAll    messageAction = "Expand collapsed messages"
+	actionPin          messageAction = "Pin as an artifact"
+	actionUnpin        messageAction = "Unpin"
+	actionPinTop       messageAction = "Pin to the top"
+	actionUnpinTop     messageAction = "Unpin from the top"
+	actionOpenFull     messageAction = "Open full view"
+)
+
+// linkAction is the action opening url.
+func linkAction(url string) messageAction {
+	return messageAction("Open " + url)
+}
+
+// link returns the URL a link action opens.
+func (a messageAction) link() (string, bool) {
+	url, ok := strings.CutPrefix(string(a), "Open ")
+	return url, ok && len(views.URLs(url)) == 1
+}
+
+// exportDir is where single-message exports are written.
+const exportDir = "."
+
+// messageActions returns the actions available for a message.
+func messageActions(msg Message) []string {
+	switch {
+	case msg.Unsupported != "":
+		if msg.ShowRaw {
+			return []string{string(actionHideRaw)}
+		}
+		return []string{string(actionShowRaw)}
+	case msg.Table != nil:
+		return []string{string(actionSaveCSV)}
+	case msg.IsCode:
+		return []string{string(actionSaveCode)}
+	case msg.Role == "assistant":
+		return []string{string(actionSaveMarkdown)}
+	}
+	return nil
+}
+
+// actionTarget returns the index of the message the actions menu applies to,
+// or -1 if no message has any actions.
+func (m Model) actionTarget() int {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if len(messageActions(m.messages[i])) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// openMessageMenu shows the actions menu for the selected message, or else
+// the target message, and for collapsed messages if there are any.
+func (m *Model) openMessageMenu() {
+	target := m.actionTarget()
+	if m.selecting {
+		target = m.selected
+	}
+	var actions []string
+	if target >= 0 {
+		actions = messageActions(m.messages[target])
+		for _, url := range messageURLs(m.messages[target]) {
+			actions = append(actions, string(linkAction(url)))
+		}
+	}
+	switch {
+	case target >= 0 && m.messages[target].Artifact != "":
+		actions = append(actions, string(actionUnpin))
+	case target >= 0 && pinnable(m.messages[target]):
+		actions = append(actions, string(actionPin))
+	}
+	switch {
+	case target >= 0 && m.messages[target].Pinned:
+		actions = append(actions, string(actionUnpinTop))
+	case target >= 0:
+		actions = append(actions, string(actionPinTop))
+	}
+	if target >= 0 && m.options.Limits.truncated(m.messages[target]) {
+		actions = append(actions, string(actionOpenFull))
+	}
+	if m.hasCollapsed() {
+		actions = append(actions, string(actionExpandAll))
+	}
+	if len(actions) == 0 {
+		m.statusMessage = "No message actions available"
+		return
+	}
+
+	m.currentMenu = components.NewSelectMenu(&protocol.SelectPayload{
+		Label:   "Message actions",
+		Options: protocol.OptionsFromStrings(actions),
+	})
+	m.currentMenu.SetWidth(m.width)
+	m.menuTarget = target
+	m.state = StateMenu
+}
+
+// runMessageAction executes a menu action against the target message.
+func (m *Model) runMessageAction(action messageAction) {
+	switch action {
+	case actionExpandAll:
+		m.expandAll()
+		return
+	}
+	if m.menuTarget < 0 || m.menuTarget >= len(m.messages) {
+		return
+	}
+	msg := m.messages[m.menuTarget]
+
+	switch action {
+	case actionPin:
+		m.pinArtifact(m.menuTarget)
+		return
+	case actionUnpin:
+		m.unpinArtifact(m.menuTarget)
+		return
+	case actionPinTop, actionUnpinTop:
+		m.togglePinned(m.menuTarget)
+		return
+	case actionOpenFull:
+		m.openFullView(msg)
+		return
+	}
+	if url, ok := action.link(); ok {
+		m.openLink(url)
+		return
+	}
+
+	if action == actionShowRaw || action == actionHideRaw {
+		m.messages[m.menuTarget].ShowRaw = action == actionShowRaw
+		m.refreshMessages()
+		return
+	}
+
+	var path string
+	var err error
+	switch action {
+	case actionSaveCSV:
+		if msg.Table == nil {
+			return
+		}
+		path, err = export.SaveTableCSV(exportDir, tableColumns(msg.Table), msg.Table.Rows)
+	case actionSaveCode:
+		path, err = export.SaveCode(exportDir, msg.Content, msg.Language)
+	case actionSaveMarkdown:
+		path, err = export.SaveMarkdown(exportDir, msg.Content)
+	default:
+		return
+	}
+
+	if err != nil {
+		m.setError("Export failed", err.Error(), false)
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Saved %s", path)
+}
+
+// tableColumns converts table column definitions to header strings.
+func tableColumns(t *protocol.TablePayload) []string {
+	cols := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		if s, ok := c.(string); ok {
+			cols[i] = s
+		} else {
+			cols[i] = fmt.Sprintf("%v", c)
+		}
+	}
+	return cols
+}
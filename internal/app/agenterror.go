@@ -0,0 +1,175 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// retryableError is the latest error the agent reported that the user can
+// ask it to retry with ctrl+r.
+type retryableError struct {
+	id      string // the error message's ID
+	payload protocol.ErrorPayload
+
+	// The error card in the transcript, as it was rendered, to find it
+	// again when the hint on it changes
+	index   int
+	content string
+}
+
+// handleAgentError ends the turn the agent failed in and adds an error
+// card to the transcript.
+func (m *Model) handleAgentError(msg *protocol.Message, payload protocol.ErrorPayload) {
+	m.isStreaming = false
+	m.clearProgress()
+	if m.turn != nil {
+		m.turn.done = time.Now()
+		m.finishTurn()
+	}
+
+	hint := ""
+	if payload.Retryable {
+		hint = "ctrl+r to retry"
+		m.statusMessage = "Agent error · ctrl+r to retry"
+	} else {
+		m.statusMessage = "Agent error"
+	}
+	content := renderErrorCard(payload, hint, m.width)
+	m.messages = append(m.messages, Message{
+		Role:      "system",
+		Content:   content,
+		Timestamp: time.Now(),
+		Summary:   payload.Message,
+	})
+	// Only the latest error can be retried
+	m.retry = nil
+	if payload.Retryable {
+		m.retry = &retryableError{id: msg.ID, payload: payload, index: len(m.messages) - 1, content: content}
+	}
+	m.refreshMessages()
+}
+
+// retryAgentError asks the agent to retry the work that failed with the
+// latest retryable error, starting a new turn. It reports whether there
+// was one.
+func (m *Model) retryAgentError() bool {
+	r := m.retry
+	if r == nil {
+		return false
+	}
+	if err := m.handler.SendRetry(r.id, r.payload.Code); err != nil {
+		m.setError("Failed to send retry", err.Error(), true)
+		return true
+	}
+	m.retry = nil
+
+	if r.index < len(m.messages) && m.messages[r.index].Content == r.content {
+		m.messages[r.index].Content = renderErrorCard(r.payload, "Retried", m.width)
+		m.refreshMessages()
+	}
+	if m.handler.Backlog() > 0 {
+		m.statusMessage = "Queued until the agent reconnects"
+		return true
+	}
+	m.isStreaming = true
+	m.statusMessage = "Retrying..."
+	m.startTurn()
+	return true
+}
+
+// retryExchange asks the agent to answer the user's last input again,
+// after a failure or an answer the user did not like, starting a new turn.
+// With replace, what followed the input leaves the transcript first, for
+// ctrl+z to put back; otherwise the input is shown again over the new
+// answer.
+func (m *Model) retryExchange(replace bool) {
+	j := m.askedBefore(len(m.messages) - 1)
+	switch {
+	case j < 0:
+		m.statusMessage = "Nothing to retry"
+		return
+	case m.options.ReadOnly:
+		m.statusMessage = "This session is read-only"
+		return
+	case m.isStreaming:
+		m.statusMessage = "Wait for the agent to finish replying"
+		return
+	case m.startup.waiting:
+		m.statusMessage = "Waiting for the agent to start"
+		return
+	}
+	input := m.messages[j].Content
+	// Nothing followed the input to replace
+	replace = replace && j < len(m.messages)-1
+	if err := m.handler.SendRetryInput(m.tabAgent(), input, replace); err != nil {
+		m.setError("Failed to send retry", err.Error(), true)
+		return
+	}
+	// The latest error is retried with the rest of the exchange
+	m.retry = nil
+
+	if replace {
+		m.clearMessages(func(i int, _ Message) bool { return i > j })
+	} else {
+		m.messages = append(m.messages, Message{Role: "user", Content: input, Timestamp: time.Now()})
+	}
+	m.refreshMessages()
+	m.jumpToNew()
+	if m.handler.Backlog() > 0 {
+		m.statusMessage = "Queued until the agent reconnects"
+		return
+	}
+	m.isStreaming = true
+	m.statusMessage = "Retrying..."
+	if replace {
+		m.statusMessage += theme.Glyphs.Separator + "ctrl+z puts the earlier answer back"
+	}
+	m.startTurn()
+}
+
+// retryCommand retries the last exchange, replacing the earlier answer as
+// configured unless args says "replace" or "keep".
+func (m *Model) retryCommand(args string) tea.Cmd {
+	replace := m.options.RetryReplaces
+	switch args {
+	case "":
+	case "replace":
+		replace = true
+	case "keep":
+		replace = false
+	default:
+		m.statusMessage = "Usage: /retry [replace | keep]"
+		return nil
+	}
+	m.retryExchange(replace)
+	return nil
+}
+
+// renderErrorCard renders an error the agent reported: its message, code
+// and details, with hint below them if set.
+func renderErrorCard(payload protocol.ErrorPayload, hint string, width int) string {
+	colors := theme.Current.Colors
+	style := theme.Current.Styles.AlertError
+	if width > 0 {
+		style = style.Width(width - 4)
+	}
+
+	title := theme.Glyphs.Error + " " + payload.Message
+	if payload.Code != "" {
+		title += lipgloss.NewStyle().Foreground(colors.TextMuted).Render("  " + payload.Code)
+	}
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(title)}
+	if payload.Details != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colors.TextMuted).Render(payload.Details))
+	}
+	if hint != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colors.TextDim).Italic(true).Render(hint))
+	}
+	return style.Render(strings.Join(lines, "\n"))
+}
@@ -2,8 +2,10 @@
 package app
 
 import (
-	"encoding/json"
+	"cmp"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,8 +14,11 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/qr"
+	"github.com/flight505/agentui/internal/schedule"
 	"github.com/flight505/agentui/internal/theme"
 	"github.com/flight505/agentui/internal/ui/animations"
 	"github.com/flight505/agentui/internal/ui/components"
@@ -28,7 +33,20 @@ const (
 	StateForm
 	StateConfirm
 	StateSelect
+	StateSecret
+	StateAutocomplete
+	StateAttach
+	StateMenu
+	StateDiff
 	StateError
+	StateUnresponsive
+	StateDisconnected
+	StateQuestion
+	StateAgentExited
+	StateCompare
+	StateFullView
+	StateSessions
+	StateStartupTimeout
 )
 
 // Message represents a chat message.
@@ -38,6 +56,53 @@ type Message struct {
 	Timestamp time.Time
 	IsCode    bool
 	Language  string
+
+	// ID is the agent-supplied message ID, used to target later patches
+	ID string
+	// ChangedLines are the code lines touched by the most recent patch
+	ChangedLines []int
+	// Open is set on a markdown message that is still receiving appends
+	// and on a code block that is still receiving chunks
+	Open bool
+
+	// Unsupported holds the type of a message this TUI could not render,
+	// Raw its original JSON, and ShowRaw whether that JSON is expanded
+	Unsupported string
+	Raw         string
+	ShowRaw     bool
+
+	// Table keeps the raw table data so it can be exported after rendering
+	Table *protocol.TablePayload
+	// Blob is the blob a note of a received blob refers to
+	Blob *protocol.Blob
+
+	// Agent names the agent the message came from when several share the
+	// connection; see multiplex.go
+	Agent string
+
+	// Timing is how long the turn the message ends took; see turns.go
+	Timing *turnTiming
+
+	// Artifact is the ID of the artifact pinned from the message; see
+	// artifacts.go
+	Artifact string
+
+	// Collapsed shows the message as one line: Summary, or else the first
+	// line of its content
+	Collapsed bool
+	Summary   string
+
+	// Unfolded shows the whole of a reply long enough to be folded; see
+	// fold.go
+	Unfolded bool
+
+	// Pinned keeps the message in the strip at the top of the transcript;
+	// see pinned.go
+	Pinned bool
+
+	// Dashboard is the layout a layout message draws, kept so messages for
+	// its regions can redraw it; see dashboard.go
+	Dashboard *dashboard
 }
 
 // ErrorInfo holds error state.
@@ -60,6 +125,9 @@ type Model struct {
 	height   int
 	quitting bool
 
+	// Bumped on every resize so only the last one is applied
+	resizeSeq int
+
 	// Components
 	viewport viewport.Model
 	input    textarea.Model
@@ -69,35 +137,143 @@ type Model struct {
 	markdownView *views.MarkdownView
 	tableView    *views.TableView
 	codeView     *views.CodeView
-	progressView *views.ProgressView
 	alertView    *views.AlertView
 
+	// Background rendering of large markdown and code messages
+	renders *renderPool
+
+	// The terminal width; the chat may be narrower, see wide.go
+	termWidth int
+
+	// Key outputs kept beside the chat, the tab shown and how far it is
+	// scrolled; see artifacts.go
+	artifacts      []artifact
+	artifactTab    int
+	artifactScroll int
+	artifactSeq    int
+	artifactsView  *artifactsCache
+	layout         *transcriptLayout
+
+	// When floods of status and progress updates were last drawn; see
+	// throttle.go
+	throttle *statusThrottle
+	// search.go
+	search *transcriptSearch
+	// Where the input's caret was drawn, for input methods; see ime.go
+	caret *caretPosition
+	// Message selection, and the toast shown after copying one; see
+	// selection.go, copy.go and toast.go
+	selecting bool
+	selected  int
+	deleted   []deletedMessage // the messages u or ctrl+z put back
+	toast     toast
+	// Prompts recalled with Up and Down; see recall.go
+	recall inputRecall
+	// Whether the help overlay is open; see help.go
+	helpOpen bool
+	// Slash commands the agent registered, and the menu of them shown
+	// while typing one; see commands.go
+	commands commandMenu
+	// Chat tabs, each with its own transcript; see tabs.go
+	tabs chatTabs
+	// Messages the agent added below the view while the user was scrolled
+	// up; see newmessages.go
+	newBelow int
+	// The mouse is let go for the terminal to select text; see
+	// nativeselect.go
+	nativeSelect bool
+	// Prompts and ticks sent on a schedule, and the generation of their
+	// timers; see schedules.go
+	schedules   []schedule.Schedule
+	scheduleGen int
+
 	// Chat state
 	messages      []Message
 	streamingText string
-	isStreaming   bool
+	streamAgent   string // the agent streaming it
+
+	// The turn being timed, from the user's input until the agent is done
+	turn        *turnTiming
+	isStreaming bool
+
+	// The latest error the agent reported that can be retried
+	retry *retryableError
+
+	// The chat view panel keys go to; see focus.go
+	focus string
+
+	// The agent's request being answered, shown by one of the components
+	// below
+	request pendingRequest
 
 	// Form state (using new component)
-	currentForm   *components.Form
-	currentFormID string
+	currentForm *components.Form
 
 	// Confirm state (using new component)
-	currentConfirm   *components.ConfirmDialog
-	currentConfirmID string
+	currentConfirm *components.ConfirmDialog
 
 	// Select state (using new component)
-	currentSelect   *components.SelectMenu
-	currentSelectID string
+	currentSelect *components.SelectMenu
+
+	// Secret prompt state (value is never stored in messages)
+	currentSecret *components.SecretPrompt
+
+	// Autocomplete state
+	currentAutocomplete *components.Autocomplete
+	currentQuestion     *components.Question
+
+	// Attachment path prompt state
+	currentAttach *components.Autocomplete
+	attachDir     string
+
+	// Diff review state
+	currentDiff *components.DiffReview
+
+	// Comparison of two artifacts, and the artifact marked to compare;
+	// see compare.go
+	currentCompare *components.Comparison
+	currentPager   *components.Pager
+	sessionPicker  *components.SelectMenu // see history.go
+	compareMark    string
+
+	// Message actions menu state
+	currentMenu *components.SelectMenu
+	menuTarget  int
+
+	// Agent liveness, and the agent leaving mid-session
+	heartbeat heartbeat
+	reconnect reconnect
+	startup   startupGate
 
-	// Progress state
-	currentProgress *views.ProgressView
+	// The sidebar, and the tool calls it lists; see sidebar.go
+	sidebar sidebar
+	tools   []toolCall
+
+	// Restarts of an agent the TUI spawned
+	supervisor supervisor
+
+	// Whether the agent was told the user is typing
+	typing typingState
+
+	// Set while the messages of a batch are applied
+	batching bool
+
+	// The agent's completion of the draft, if any
+	suggestion *inputSuggestion
+
+	// Active progress bars by ID, in the order they appeared
+	progress      map[string]*views.ProgressView
+	progressOrder []string
 
 	// Error state
 	lastError *ErrorInfo
 
 	// Status
 	statusMessage string
+	agentAddr     string // the connected agent when listening on the network
 	tokenInfo     *protocol.TokenInfo
+	usage         sessionUsage // from metadata messages; see budget.go
+	stripped      int          // escape sequences removed from agent text
 
 	// App info
 	appName    string
@@ -110,19 +286,28 @@ type Model struct {
 
 	// Debug mode
 	debugMode bool
+
+	// When the session started, and whether chat messages show the time
+	// they arrived
+	started   time.Time
+	showTimes bool
+
+	// Optional behavior configured via WithOptions
+	options Options
 }
 
 // NewModel creates a new application model.
 func NewModel(handler *protocol.Handler, appName, tagline string) Model {
 	// Input area
 	ti := textarea.New()
-	ti.Placeholder = "Type a message..."
+	ti.Prompt = theme.Glyphs.Prompt
+	ti.Placeholder = "Type a message... (Alt+Enter for a new line)"
 	ti.Focus()
 	ti.CharLimit = 4096
 	ti.SetWidth(80)
-	ti.SetHeight(3)
+	ti.SetHeight(minInputLines)
 	ti.ShowLineNumbers = false
-	ti.KeyMap.InsertNewline.SetEnabled(false) // Enter sends, not newline
+	ti.KeyMap.InsertNewline.SetKeys(newlineKeys...) // Enter sends; see multiline.go
 
 	// Spinner for loading states
 	s := spinner.New()
@@ -148,11 +333,19 @@ func NewModel(handler *protocol.Handler, appName, tagline string) Model {
 		markdownView:  views.NewMarkdownView(),
 		tableView:     views.NewTableView(),
 		codeView:      views.NewCodeView(),
-		progressView:  views.NewProgressView(),
+		progress:      make(map[string]*views.ProgressView),
 		alertView:     views.NewAlertView(),
+		renders:       newRenderPool(false),
+		layout:        &transcriptLayout{},
+		artifactsView: &artifactsCache{},
+		throttle:      &statusThrottle{},
+		search:        &transcriptSearch{},
+		caret:         &caretPosition{},
 		modalOpacity:  modalOpacity,
 		modalPosition: modalPosition,
 		animating:     false,
+		started:       time.Now(),
+		showTimes:     true,
 	}
 }
 
@@ -162,11 +355,20 @@ func (m Model) Init() tea.Cmd {
 		textarea.Blink,
 		m.spinner.Tick,
 		m.listenForMessages(),
+		m.keepAliveTick(),
+		m.heartbeatTick(),
+		m.startupTimer(),
+		m.armSchedules(),
+		m.relativeTimesTick(),
 	)
 }
 
 // listenForMessages creates a command that listens for protocol messages.
+// It returns nil while a batch is applied, which listens once at its end.
 func (m Model) listenForMessages() tea.Cmd {
+	if m.batching || m.options.ReadOnly {
+		return nil
+	}
 	return func() tea.Msg {
 		select {
 		case msg, ok := <-m.handler.Incoming():
@@ -176,9 +378,17 @@ func (m Model) listenForMessages() tea.Cmd {
 			if msg == nil {
 				return nil
 			}
+			m.handler.Consumed(msg)
+			if _, ok := coalesceKey(msg); ok && m.options.StatusRate > 0 {
+				return m.coalesceUpdates(msg)
+			}
 			return protocolMsg{msg}
 		case err := <-m.handler.Errors():
 			return protocolErrorMsg{err}
+		case ev := <-m.handler.Connections():
+			return connEventMsg{ev}
+		case exit := <-m.options.Spawner.Exits():
+			return agentExitMsg{exit}
 		}
 	}
 }
@@ -198,6 +408,17 @@ type clearErrorMsg struct{}
 
 // Update handles messages and updates the model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer m.options.Telemetry.CatchPanic()
+	defer m.reportCrash()
+	model, cmd := m.update(msg)
+	if next, ok := model.(Model); ok {
+		model = next.seenNew()
+	}
+	// Start renders queued while handling msg
+	return model, tea.Batch(append(m.renders.dispatch(), cmd)...)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -208,6 +429,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.handler.SendQuit()
 			return m, tea.Quit
 		}
+		if m.helpOpen || msg.String() == "f1" {
+			return m.updateHelp(msg), nil
+		}
+		// Prompts from the agent show over the text being selected
+		if m.nativeSelect && m.state == StateChat {
+			return m, m.updateNativeSelect(msg)
+		}
 
 		// Clear error on any key
 		if m.state == StateError && msg.String() != "" {
@@ -215,57 +443,81 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.lastError = nil
 		}
 
-		return m.handleKeyMsg(msg)
-
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.ready = true
-
-		// Update viewport size
-		headerHeight := 3
-		footerHeight := 1
-		inputHeight := 5
-		m.viewport = viewport.New(msg.Width, msg.Height-headerHeight-footerHeight-inputHeight)
-		m.viewport.SetContent(m.renderMessages())
-
-		// Update input width
-		m.input.SetWidth(msg.Width - 4)
-
-		// Update view widths
-		m.markdownView.SetWidth(msg.Width - 4)
-		m.tableView.SetWidth(msg.Width - 4)
-		m.codeView.SetWidth(msg.Width - 4)
-		m.progressView.SetWidth(msg.Width - 4)
-		m.alertView.SetWidth(msg.Width - 4)
-
-		// Update form width if present
-		if m.currentForm != nil {
-			m.currentForm.SetWidth(msg.Width)
+		// Touching a prompt stops the countdown to its default answer
+		if isRequestState(m.state) {
+			m.stopCountdown()
 		}
-		if m.currentConfirm != nil {
-			m.currentConfirm.SetWidth(msg.Width)
+
+		// Chat keys are handled directly; modal components get keys below
+		if m.state == StateChat {
+			return m.handleKeyMsg(msg)
 		}
-		if m.currentSelect != nil {
-			m.currentSelect.SetWidth(msg.Width)
+
+	case tea.MouseMsg:
+		if m.clickNewPill(msg) {
+			return m, nil
 		}
 
-		// Notify Python of resize
-		if err := m.handler.SendResize(msg.Width, msg.Height); err != nil {
-			m.setError("Failed to send resize", err.Error(), false)
+	case tea.WindowSizeMsg:
+		// The first size lays out the UI; later ones are debounced
+		if !m.ready {
+			m.applyResize(msg.Width, msg.Height)
+			return m.resumeJournal(), nil
 		}
+		return m, m.scheduleResize(msg.Width, msg.Height)
 
+	case resizeSettledMsg:
+		if msg.seq == m.resizeSeq {
+			m.applyResize(m.termWidth, m.height)
+		}
 		return m, nil
 
 	case protocolMsg:
-		return m.handleProtocolMsg(msg.msg)
+		m.agentReady()
+		return m.routeMessage(msg.msg)
+
+	case coalescedMsg:
+		m.agentReady()
+		return m.applyBatch(msg.msgs)
+
+	case renderedMsg:
+		m.renders.store(msg)
+		m.refreshMessages()
+		return m, nil
 
 	case protocolErrorMsg:
-		m.setError("Protocol error", msg.err.Error(), true)
+		var perr *protocol.PayloadError
+		if errors.As(msg.err, &perr) {
+			m.addProtocolWarning(perr)
+		} else {
+			m.setError("Protocol error", msg.err.Error(), true)
+		}
 		return m, m.listenForMessages()
 
+	case connEventMsg:
+		m.handleConnEvent(msg.event)
+		return m, m.listenForMessages()
+
+	case agentExitMsg:
+		return m, tea.Batch(m.agentExited(msg.exit), m.listenForMessages())
+
+	case copiedMsg:
+		return m, m.copied(msg)
+
+	case attachedMsg:
+		m.attached(msg)
+		return m, nil
+
+	case toastExpiredMsg:
+		m.expireToast(msg)
+		return m, nil
+
+	case restartAgentMsg:
+		m.handleRestartDue(msg.seq)
+		return m, nil
+
 	case connectionClosedMsg:
-		m.setError("Connection closed", "The Python process has disconnected", false)
+		m.setError("Connection closed", "The agent has disconnected. The transcript is kept; start agentui-tui with --reattach to let a restarted agent resume the session.", false)
 		return m, nil
 
 	case clearErrorMsg:
@@ -275,6 +527,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case keepAliveTickMsg:
+		if m.isComposing() {
+			if err := m.handler.SendKeepAlive(true); err != nil && !notConnected(err) {
+				m.setError("Failed to send keep-alive", err.Error(), true)
+			}
+		}
+		return m, m.keepAliveTick()
+
+	case relativeTimesTickMsg:
+		m.refreshMessages()
+		return m, m.relativeTimesTick()
+
+	case requestTimeoutMsg:
+		m.requestTimedOut(msg.seq)
+		return m, nil
+
+	case requestCountdownMsg:
+		return m, m.requestCountdown(msg.seq)
+
+	case typingIdleMsg:
+		return m, m.handleTypingIdle(msg.seq)
+
+	case scheduleFiredMsg:
+		return m, m.fireSchedule(msg)
+
+	case startupTimeoutMsg:
+		m.handleStartupTimeout()
+		return m, nil
+
+	case heartbeatTickMsg:
+		return m, tea.Batch(m.sendPing(), m.heartbeatTick())
+
+	case pingResultMsg:
+		m.handlePingResult(msg)
+		return m, nil
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -308,17 +596,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Check if form is done
 			if m.currentForm.IsSubmitted() {
-				if err := m.handler.SendFormResponse(m.currentFormID, m.currentForm.GetValues()); err != nil {
+				if err := m.handler.SendFormResponse(m.request.id, m.currentForm.GetValues(), false); err != nil {
 					m.setError("Failed to send form", err.Error(), false)
 				}
 				m.state = StateChat
 				m.currentForm = nil
+				m.closeRequest()
 			} else if m.currentForm.IsCancelled() {
-				if err := m.handler.SendFormResponse(m.currentFormID, nil); err != nil {
+				if err := m.handler.SendFormResponse(m.request.id, nil, false); err != nil {
 					m.setError("Failed to send form", err.Error(), false)
 				}
 				m.state = StateChat
 				m.currentForm = nil
+				m.closeRequest()
 			}
 		}
 
@@ -328,11 +618,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 
 			if m.currentConfirm.HasResponded() {
-				if err := m.handler.SendConfirmResponse(m.currentConfirmID, m.currentConfirm.IsConfirmed()); err != nil {
+				if err := m.handler.SendConfirmResponse(m.request.id, m.currentConfirm.IsConfirmed(), false); err != nil {
 					m.setError("Failed to send confirmation", err.Error(), false)
 				}
 				m.state = StateChat
 				m.currentConfirm = nil
+				m.closeRequest()
 			}
 		}
 
@@ -342,11 +633,149 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 
 			if m.currentSelect.HasResponded() {
-				if err := m.handler.SendSelectResponse(m.currentSelectID, m.currentSelect.GetSelected()); err != nil {
+				if err := m.handler.SendSelectResponse(m.request.id, m.currentSelect.GetSelected(), false); err != nil {
 					m.setError("Failed to send selection", err.Error(), false)
 				}
 				m.state = StateChat
 				m.currentSelect = nil
+				m.closeRequest()
+			}
+		}
+
+	case StateSecret:
+		if m.currentSecret != nil {
+			cmd := m.currentSecret.Update(msg)
+			cmds = append(cmds, cmd)
+
+			if m.currentSecret.HasResponded() {
+				err := m.handler.SendSecretResponse(m.request.id, m.currentSecret.Value(), m.currentSecret.IsCancelled())
+				m.currentSecret.Clear()
+				m.state = StateChat
+				m.currentSecret = nil
+				m.closeRequest()
+				if err != nil {
+					m.setError("Failed to send secret", err.Error(), false)
+				}
+			}
+		}
+
+	case StateDiff:
+		if m.currentDiff != nil {
+			cmd := m.currentDiff.Update(msg)
+			cmds = append(cmds, cmd)
+
+			if m.currentDiff.HasResponded() {
+				if err := m.handler.SendDiffResponse(m.request.id, m.currentDiff.Accepted(), m.currentDiff.IsCancelled()); err != nil {
+					m.setError("Failed to send diff response", err.Error(), false)
+				}
+				m.state = StateChat
+				m.currentDiff = nil
+				m.closeRequest()
+			}
+		}
+
+	case StateAutocomplete:
+		if m.currentAutocomplete != nil {
+			cmd := m.currentAutocomplete.Update(msg)
+			cmds = append(cmds, cmd)
+
+			if m.currentAutocomplete.HasResponded() {
+				if err := m.handler.SendAutocompleteResponse(m.request.id, m.currentAutocomplete.Value(), m.currentAutocomplete.IsCancelled()); err != nil {
+					m.setError("Failed to send autocomplete value", err.Error(), false)
+				}
+				m.state = StateChat
+				m.currentAutocomplete = nil
+				m.closeRequest()
+			}
+		}
+
+	case StateQuestion:
+		if m.currentQuestion != nil {
+			cmd := m.currentQuestion.Update(msg)
+			cmds = append(cmds, cmd)
+
+			if m.currentQuestion.HasResponded() {
+				q := m.currentQuestion
+				if err := m.handler.SendQuestionResponse(m.request.id, q.Value(), q.IsCustom(), q.IsCancelled()); err != nil {
+					m.setError("Failed to send answer", err.Error(), false)
+				}
+				m.state = StateChat
+				m.currentQuestion = nil
+				m.closeRequest()
+			}
+		}
+
+	case StateAttach:
+		if m.currentAttach != nil {
+			cmd := m.currentAttach.Update(msg)
+			cmds = append(cmds, cmd)
+
+			if m.currentAttach.HasResponded() {
+				path := m.currentAttach.Value()
+				cancelled := m.currentAttach.IsCancelled()
+				m.state = StateChat
+				m.currentAttach = nil
+				if !cancelled {
+					cmds = append(cmds, m.sendAttachment(path))
+				}
+			} else {
+				m.refreshAttachSuggestions()
+			}
+		}
+
+	case StateMenu:
+		if m.currentMenu != nil {
+			cmd := m.currentMenu.Update(msg)
+			cmds = append(cmds, cmd)
+
+			if m.currentMenu.HasResponded() {
+				action := messageAction(m.currentMenu.GetSelected())
+				m.state = StateChat
+				m.currentMenu = nil
+				if action != "" {
+					m.runMessageAction(action)
+				}
+			}
+		}
+
+	case StateUnresponsive:
+		cmds = append(cmds, m.updateUnresponsivePrompt(msg))
+
+	case StateDisconnected:
+		cmds = append(cmds, m.updateDisconnectedPrompt(msg))
+
+	case StateAgentExited:
+		cmds = append(cmds, m.updateExitedPrompt(msg))
+
+	case StateStartupTimeout:
+		cmds = append(cmds, m.updateStartupPrompt(msg))
+
+	case StateCompare:
+		if m.currentCompare != nil {
+			cmds = append(cmds, m.currentCompare.Update(msg))
+			if m.currentCompare.Closed() {
+				m.currentCompare = nil
+				m.state = StateChat
+			}
+		}
+
+	case StateFullView:
+		if m.currentPager != nil {
+			cmds = append(cmds, m.currentPager.Update(msg))
+			if m.currentPager.Closed() {
+				m.currentPager = nil
+				m.state = StateChat
+			}
+		}
+
+	case StateSessions:
+		if m.sessionPicker != nil {
+			cmds = append(cmds, m.sessionPicker.Update(msg))
+			if m.sessionPicker.HasResponded() {
+				name := m.sessionPicker.GetSelected()
+				m.sessionPicker = nil
+				m.state = StateChat
+				m = m.reopenSession(name)
 			}
 		}
 	}
@@ -354,6 +783,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// submitInput adds content to the transcript as the user's input and sends
+// it to the agent, starting a turn. It reports whether it was sent.
+func (m *Model) submitInput(content string) bool {
+	m.messages = append(m.messages, Message{
+		Role:      "user",
+		Content:   content,
+		Timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.jumpToNew()
+
+	// Send to Python
+	m.stopTyping("")
+	m.suggestion = nil
+	m.retry = nil
+	if err := m.handler.SendInputTo(m.tabAgent(), content); err != nil {
+		m.setError("Failed to send message", err.Error(), true)
+		return false
+	}
+
+	// With no agent connected the input waits for the next one
+	if m.handler.Backlog() > 0 {
+		m.statusMessage = "Queued until the agent reconnects"
+		return true
+	}
+
+	// Start streaming state
+	m.isStreaming = true
+	m.statusMessage = "Thinking..."
+	m.startTurn()
+	return true
+}
+
 func (m *Model) setError(message, details string, retryable bool) {
 	m.lastError = &ErrorInfo{
 		Message:   message,
@@ -368,6 +830,9 @@ func (m *Model) setError(message, details string, retryable bool) {
 func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.state {
 	case StateChat:
+		if m.search.open {
+			return m.updateSearch(msg)
+		}
 		return m.handleChatKeys(msg)
 	}
 	return m, nil
@@ -375,25 +840,142 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleChatKeys handles keys in chat mode.
 func (m Model) handleChatKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateCommandMenu(msg.String()) {
+		return m, nil
+	}
 	switch msg.String() {
 	case "esc":
+		if m.suggesting() {
+			m.suggestion = nil
+			return m, nil
+		}
+		if m.selecting {
+			m.stopSelecting()
+			m.setFocus(PanelInput)
+			return m, nil
+		}
 		if m.isStreaming {
 			// Cancel streaming (send cancel to Python)
 			m.handler.SendSync(&protocol.Message{Type: protocol.TypeCancel})
 			m.isStreaming = false
 			m.statusMessage = "Cancelled"
+			m.finishTurn()
+			return m, nil
+		}
+		// Otherwise move through the transcript's messages
+		if len(m.messages) > 0 {
+			m.setFocus(PanelTranscript)
+			m.startSelecting()
 		}
 		return m, nil
 
 	case "ctrl+l":
 		// Clear chat
-		m.messages = []Message{}
-		m.viewport.SetContent("")
+		m.clearTranscript("")
+		return m, nil
+
+	case "?":
+		// Help outside the input; in it, ? starts or goes in a message
+		// and F1 opens help
+		if m.focused() != PanelInput {
+			m.helpOpen = true
+			return m, nil
+		}
+
+	case "ctrl+b":
+		m.toggleSidebar()
+		return m, nil
+
+	case "ctrl+z":
+		// Put back what was deleted or cleared last
+		m.undoDelete()
+		return m, nil
+
+	case "ctrl+pgdown", "ctrl+pgup":
+		// Next or previous chat tab
+		if msg.String() == "ctrl+pgup" {
+			m.cycleTab(-1)
+		} else {
+			m.cycleTab(1)
+		}
+		return m, nil
+
+	case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+		if len(m.tabs.list) > 1 {
+			m.switchTab(int(msg.String()[4] - '1'))
+			return m, nil
+		}
+
+	case "tab":
+		if m.suggesting() {
+			m.acceptSuggestion()
+			return m, m.noteTyping()
+		}
+		m.cycleFocus(1)
+		return m, nil
+
+	case "shift+tab":
+		m.cycleFocus(-1)
 		return m, nil
 
 	case "ctrl+d":
-		// Toggle debug mode
+		// Toggle debug mode, reporting memory usage when it turns on
 		m.debugMode = !m.debugMode
+		if m.debugMode {
+			m.showMemoryReport()
+		}
+		// Turn timing shows in debug mode
+		m.refreshMessages()
+		return m, nil
+
+	case "ctrl+k":
+		// Compact the transcript in debug mode; otherwise the textarea
+		// uses it to delete to the end of the line
+		if m.debugMode {
+			m.compactTranscript()
+			return m, nil
+		}
+
+	case "ctrl+x":
+		// Open message actions (export) for the latest message
+		m.openMessageMenu()
+		return m, nil
+
+	case "ctrl+f":
+		// Search the transcript
+		m.openSearch()
+		return m, nil
+
+	case "ctrl+end":
+		// Catch up with what the agent added while scrolled up
+		m.jumpToNew()
+		return m, nil
+
+	case "f2":
+		// Let the terminal select text
+		return m, m.toggleNativeSelect()
+
+	case "ctrl+s":
+		// Reopen a past session
+		m.openSessionPicker()
+		return m, nil
+
+	case "ctrl+o":
+		// Attach a file to send to the agent
+		m.openAttachPrompt("")
+		return m, nil
+
+	case "ctrl+r":
+		// Restart a spawned agent that has exited, or else retry after
+		// the agent's latest retryable error, or else retry the last
+		// exchange
+		if m.options.Spawner != nil && m.supervisor.down {
+			m.restartAgent()
+			return m, nil
+		}
+		if !m.retryAgentError() {
+			m.retryExchange(m.options.RetryReplaces)
+		}
 		return m, nil
 
 	case "pgup":
@@ -405,51 +987,113 @@ func (m Model) handleChatKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "enter":
+		if m.selecting {
+			return m, m.updateSelection(msg)
+		}
 		// Send message if not empty and not streaming
-		if m.isStreaming {
+		if m.isStreaming || m.focused() != PanelInput {
+			return m, nil
+		}
+		if m.options.ReadOnly {
+			m.statusMessage = "This session is read-only"
+			return m, nil
+		}
+		if m.startup.waiting {
+			m.statusMessage = "Waiting for the agent to start"
 			return m, nil
 		}
 
 		content := strings.TrimSpace(m.input.Value())
-		if content != "" {
-			// Add user message to chat
-			m.messages = append(m.messages, Message{
-				Role:      "user",
-				Content:   content,
-				Timestamp: time.Now(),
-			})
-			m.viewport.SetContent(m.renderMessages())
-			m.viewport.GotoBottom()
+		if strings.HasPrefix(content, "/") {
+			m.recordInput(content)
+			m.input.Reset()
+			m.fitInput()
+			return m, m.runCommand(content)
+		}
+		if content != "" && m.submitInput(content) {
+			m.recordInput(content)
+			m.input.Reset()
+			m.fitInput()
+		}
+		return m, nil
+	}
 
-			// Send to Python
-			if err := m.handler.SendInput(content); err != nil {
-				m.setError("Failed to send message", err.Error(), true)
-				return m, nil
-			}
+	if m.focused() != PanelInput {
+		return m, m.updateFocusedPanel(msg)
+	}
 
-			// Clear input
-			m.input.Reset()
+	if m.options.ReadOnly {
+		m.statusMessage = "This session is read-only"
+		return m, nil
+	}
+	if m.startup.waiting {
+		m.statusMessage = "Waiting for the agent to start"
+		return m, nil
+	}
 
-			// Start streaming state
-			m.isStreaming = true
-			m.statusMessage = "Thinking..."
-		}
+	if m.recallInput(msg.String()) {
 		return m, nil
 	}
 
 	// Pass to textarea
 	var cmd tea.Cmd
+	draft := m.input.Value()
 	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != draft {
+		m.fitInput()
+		m.followSuggestion()
+		cmd = tea.Batch(cmd, m.noteTyping())
+	}
 	return m, cmd
 }
 
+// typeFeature names the usage counter for messages of type t. Only types
+// the TUI knows are named; anything else an agent sends counts as
+// unsupported, so nothing the agent wrote ends up in the report.
+func typeFeature(t protocol.MessageType) string {
+	if t != protocol.TypeBatch && !slices.Contains(protocol.RenderTypes, t) {
+		return "type:unsupported"
+	}
+	return "type:" + string(t)
+}
+
 // handleProtocolMsg processes messages from Python.
 func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 	if msg == nil {
 		return m, m.listenForMessages()
 	}
+	if msg.Type != protocol.TypePong {
+		m.options.Telemetry.Count(typeFeature(msg.Type))
+	}
+	m.noteTurnOutput(msg.Type)
+
+	if msg.Region != "" && m.feedRegion(msg) {
+		return m, m.listenForMessages()
+	}
 
 	switch msg.Type {
+	case protocol.TypePong:
+		var payload protocol.PongPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid pong payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		// Pongs to pings that already timed out, or from agents that do
+		// not echo the ping's ID
+		m.handlePong(payload.Seq)
+
+	case protocol.TypeHello:
+		// Compression is settled by the handler
+		var payload protocol.HelloPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid hello payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		if payload.Telemetry != nil && !*payload.Telemetry {
+			m.options.Telemetry.Disable()
+		}
+		m.noteAgent(payload.Agent)
+
 	case protocol.TypeText:
 		var payload protocol.TextPayload
 		if err := msg.ParsePayload(&payload); err != nil {
@@ -457,7 +1101,9 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			return m, m.listenForMessages()
 		}
 		m.streamingText += payload.Content
+		m.noteStreamedText(payload.Content)
 		if payload.Done {
+			m.noteTurnStreamed()
 			m.messages = append(m.messages, Message{
 				Role:      "assistant",
 				Content:   m.streamingText,
@@ -465,9 +1111,10 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			})
 			m.streamingText = ""
 			m.isStreaming = false
+			m.showNewMessage()
+		} else {
+			m.refreshMessages()
 		}
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
 
 	case protocol.TypeMarkdown:
 		var payload protocol.MarkdownPayload
@@ -475,13 +1122,29 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			m.setError("Invalid markdown payload", err.Error(), false)
 			return m, m.listenForMessages()
 		}
+		if payload.Append && payload.Done {
+			m.noteTurnStreamed()
+		}
+		if payload.Append {
+			m.noteStreamedText(payload.Content)
+			if idx := m.findOpenMarkdown(msg.ID); idx >= 0 {
+				m.messages[idx].Content += payload.Content
+				m.messages[idx].Open = !payload.Done
+				m.refreshMessages()
+				break
+			}
+			if payload.Content == "" {
+				break
+			}
+		}
 		m.messages = append(m.messages, Message{
 			Role:      "assistant",
 			Content:   payload.Content,
 			Timestamp: time.Now(),
+			ID:        msg.ID,
+			Open:      payload.Append && !payload.Done,
 		})
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		m.showNewMessage()
 
 	case protocol.TypeCode:
 		var payload protocol.CodePayload
@@ -494,10 +1157,30 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			Content:   payload.Code,
 			Timestamp: time.Now(),
 			IsCode:    true,
-			Language:  payload.Language,
+			Language:  cmp.Or(payload.Language, views.DetectLanguage(payload.Code)),
+			ID:        msg.ID,
 		})
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		m.showNewMessage()
+
+	case protocol.TypeCodePatch:
+		var payload protocol.CodePatchPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid code patch payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		idx := m.findCodeMessage(payload.ID)
+		if idx < 0 {
+			m.setError("Invalid code patch", fmt.Sprintf("no code block with id %q", payload.ID), false)
+			return m, m.listenForMessages()
+		}
+		code, changed, err := views.ApplyCodePatch(m.messages[idx].Content, payload.Op, payload.Start, payload.End, payload.Lines)
+		if err != nil {
+			m.setError("Invalid code patch", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.messages[idx].Content = code
+		m.messages[idx].ChangedLines = changed
+		m.refreshMessages()
 
 	case protocol.TypeTable:
 		var payload protocol.TablePayload
@@ -505,27 +1188,45 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			m.setError("Invalid table payload", err.Error(), false)
 			return m, m.listenForMessages()
 		}
-		// Convert columns to strings
-		cols := make([]string, len(payload.Columns))
-		for i, c := range payload.Columns {
-			if s, ok := c.(string); ok {
-				cols[i] = s
-			} else {
-				cols[i] = fmt.Sprintf("%v", c)
-			}
-		}
-		m.tableView.SetTitle(payload.Title)
-		m.tableView.SetColumns(cols)
-		m.tableView.SetRows(payload.Rows)
-		m.tableView.SetFooter(payload.Footer)
 		// Add rendered table as message
 		m.messages = append(m.messages, Message{
 			Role:      "system",
-			Content:   m.tableView.View(),
+			Content:   m.renderTable(&payload),
 			Timestamp: time.Now(),
+			Table:     &payload,
+			ID:        msg.ID,
 		})
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		m.showNewMessage()
+
+	case protocol.TypeTableChunk:
+		var payload protocol.TableChunkPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid table chunk payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		if err := m.appendTableChunk(payload); err != nil {
+			m.setError("Invalid table chunk", err.Error(), false)
+		}
+
+	case protocol.TypeTableUpdate:
+		var payload protocol.TableUpdatePayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid table update payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		if err := m.updateTable(payload); err != nil {
+			m.setError("Invalid table update", err.Error(), false)
+		}
+
+	case protocol.TypeCodeChunk:
+		var payload protocol.CodeChunkPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid code chunk payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		if err := m.appendCodeChunk(payload); err != nil {
+			m.setError("Invalid code chunk", err.Error(), false)
+		}
 
 	case protocol.TypeForm:
 		var payload protocol.FormPayload
@@ -535,13 +1236,14 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 		}
 		m.currentForm = components.NewForm(&payload)
 		m.currentForm.SetWidth(m.width)
-		m.currentFormID = msg.ID
+		timeout := m.openRequest(msg, cmp.Or(payload.Title, "Form"))
+		countdown := m.answerByDefault(payload.TimeoutSeconds, "the defaults")
 		m.state = StateForm
 
 		// Animate modal in (fade + position)
 		m.modalOpacity.FadeIn()
 		m.modalPosition.SetTarget(0, float64(m.height/6)) // Slide from top
-		return m, animations.TickCmd() // Start animation
+		return m, tea.Batch(animations.TickCmd(), m.listenForMessages(), timeout, countdown) // Start animation
 
 	case protocol.TypeConfirm:
 		var payload protocol.ConfirmPayload
@@ -551,13 +1253,18 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 		}
 		m.currentConfirm = components.NewConfirmDialog(&payload)
 		m.currentConfirm.SetWidth(m.width)
-		m.currentConfirmID = msg.ID
+		timeout := m.openRequest(msg, cmp.Or(payload.Title, payload.Message))
+		answer := m.currentConfirm.CancelLabel
+		if payload.Default {
+			answer = m.currentConfirm.ConfirmLabel
+		}
+		countdown := m.answerByDefault(payload.TimeoutSeconds, answer)
 		m.state = StateConfirm
 
 		// Animate modal in
 		m.modalOpacity.FadeIn()
 		m.modalPosition.SetTarget(0, float64(m.height/6))
-		return m, animations.TickCmd()
+		return m, tea.Batch(animations.TickCmd(), m.listenForMessages(), timeout, countdown)
 
 	case protocol.TypeSelect:
 		var payload protocol.SelectPayload
@@ -567,8 +1274,62 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 		}
 		m.currentSelect = components.NewSelectMenu(&payload)
 		m.currentSelect.SetWidth(m.width)
-		m.currentSelectID = msg.ID
 		m.state = StateSelect
+		timeout := m.openRequest(msg, cmp.Or(payload.Label, "Selection"))
+		countdown := m.answerByDefault(payload.TimeoutSeconds, m.currentSelect.SelectedLabel())
+		return m, tea.Batch(m.listenForMessages(), timeout, countdown)
+
+	case protocol.TypeSecret:
+		var payload protocol.SecretPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid secret payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.currentSecret = components.NewSecretPrompt(&payload)
+		m.currentSecret.SetWidth(m.width)
+		timeout := m.openRequest(msg, cmp.Or(payload.Label, "Secret"))
+		m.state = StateSecret
+
+		m.modalOpacity.FadeIn()
+		m.modalPosition.SetTarget(0, float64(m.height/6))
+		return m, tea.Batch(m.listenForMessages(), animations.TickCmd(), timeout)
+
+	case protocol.TypeDiff:
+		var payload protocol.DiffPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid diff payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.currentDiff = components.NewDiffReview(&payload)
+		m.currentDiff.SetWidth(m.width)
+		timeout := m.openRequest(msg, cmp.Or(payload.Title, payload.Path, "Diff review"))
+		m.state = StateDiff
+
+		m.modalOpacity.FadeIn()
+		m.modalPosition.SetTarget(0, float64(m.height/6))
+		return m, tea.Batch(m.listenForMessages(), animations.TickCmd(), timeout)
+
+	case protocol.TypeAutocomplete:
+		var payload protocol.AutocompletePayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid autocomplete payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.currentAutocomplete = components.NewAutocomplete(&payload)
+		m.currentAutocomplete.SetWidth(m.width)
+		m.state = StateAutocomplete
+		return m, tea.Batch(m.listenForMessages(), m.openRequest(msg, cmp.Or(payload.Label, "Autocomplete")))
+
+	case protocol.TypeQuestion:
+		var payload protocol.QuestionPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid question payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.currentQuestion = components.NewQuestion(&payload)
+		m.currentQuestion.SetWidth(m.width)
+		m.state = StateQuestion
+		return m, tea.Batch(m.listenForMessages(), m.openRequest(msg, payload.Question))
 
 	case protocol.TypeProgress:
 		var payload protocol.ProgressPayload
@@ -576,25 +1337,10 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			m.setError("Invalid progress payload", err.Error(), false)
 			return m, m.listenForMessages()
 		}
-		m.progressView.SetMessage(payload.Message)
-		if payload.Percent != nil {
-			m.progressView.SetPercent(*payload.Percent)
-		} else {
-			m.progressView.SetPercent(-1)
-		}
-		if len(payload.Steps) > 0 {
-			steps := make([]views.ProgressStep, len(payload.Steps))
-			for i, s := range payload.Steps {
-				steps[i] = views.ProgressStep{
-					Label:  s.Label,
-					Status: s.Status,
-					Detail: s.Detail,
-				}
-			}
-			m.progressView.SetSteps(steps)
+		m.setProgress(payload)
+		if !payload.Done {
+			m.statusMessage = payload.Message
 		}
-		m.currentProgress = m.progressView
-		m.statusMessage = payload.Message
 
 	case protocol.TypeAlert:
 		var payload protocol.AlertPayload
@@ -611,8 +1357,113 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			Content:   m.alertView.View(),
 			Timestamp: time.Now(),
 		})
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		m.showNewMessage()
+
+	case protocol.TypeError:
+		var payload protocol.ErrorPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid error payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.handleAgentError(msg, payload)
+
+	case protocol.TypeArtifact:
+		var payload protocol.ArtifactPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid artifact payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.handleArtifact(msg, payload)
+
+	case protocol.TypeMetadata:
+		var payload protocol.MetadataPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid metadata payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.handleMetadata(payload)
+
+	case protocol.TypeRich:
+		var payload protocol.RichPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid rich payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.handleRich(msg, payload)
+
+	case protocol.TypeQRCode:
+		var payload protocol.QRCodePayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid qrcode payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		level, err := qr.ParseLevel(payload.Level)
+		if err != nil {
+			m.setError("Invalid qrcode payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		qrView := views.NewQRCodeView()
+		if err := qrView.SetData(payload.Data, level); err != nil {
+			m.setError("Invalid qrcode payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		qrView.SetTitle(payload.Title)
+		qrView.SetCaption(payload.Caption)
+		qrView.SetWidth(m.width)
+		m.messages = append(m.messages, Message{
+			Role:      "system",
+			Content:   qrView.View(),
+			Timestamp: time.Now(),
+			ID:        msg.ID,
+		})
+		m.showNewMessage()
+
+	case protocol.TypeBlob:
+		var payload protocol.BlobPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid blob payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		if err := m.receiveBlob(payload); err != nil {
+			m.setError("Invalid blob", err.Error(), false)
+		}
+
+	case protocol.TypeMap:
+		var payload protocol.MapPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid map payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		content, err := m.renderMap(&payload)
+		if err != nil {
+			m.setError("Invalid map payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.messages = append(m.messages, Message{
+			Role:      "system",
+			Content:   content,
+			Timestamp: time.Now(),
+			ID:        msg.ID,
+		})
+		m.showNewMessage()
+
+	case protocol.TypeMath:
+		var payload protocol.MathPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid math payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		mathView := views.NewMathView()
+		mathView.SetTex(payload.Tex)
+		mathView.SetTitle(payload.Title)
+		mathView.SetWidth(m.width)
+		m.messages = append(m.messages, Message{
+			Role:      "system",
+			Content:   mathView.View(),
+			Timestamp: time.Now(),
+			ID:        msg.ID,
+		})
+		m.showNewMessage()
 
 	case protocol.TypeStatus:
 		var payload protocol.StatusPayload
@@ -622,6 +1473,7 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 		}
 		m.statusMessage = payload.Message
 		m.tokenInfo = payload.Tokens
+		m.noteStreamTokens(payload.Tokens)
 
 	case protocol.TypeSpinner:
 		var payload protocol.SpinnerPayload
@@ -638,19 +1490,17 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			m.setError("Invalid clear payload", err.Error(), false)
 			return m, m.listenForMessages()
 		}
-		if payload.Scope == "chat" || payload.Scope == "all" {
-			m.messages = []Message{}
-			m.viewport.SetContent("")
-		}
-		if payload.Scope == "progress" || payload.Scope == "all" {
-			m.currentProgress = nil
-		}
+		m.handleClear(payload)
 
 	case protocol.TypeDone:
 		var payload protocol.DonePayload
 		msg.ParsePayload(&payload) // Ignore error, summary is optional
 		m.isStreaming = false
-		m.currentProgress = nil
+		m.clearProgress()
+		if m.turn != nil {
+			m.turn.done = time.Now()
+			m.finishTurn()
+		}
 		if payload.Summary != "" {
 			m.statusMessage = payload.Summary
 		} else {
@@ -665,14 +1515,23 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			return m, m.listenForMessages()
 		}
 
-		// Update current progress if it exists
-		// This is the primary use case for progressive streaming
-		if m.currentProgress != nil && payload.Fields != nil {
+		// Stream suggestions into an open autocomplete prompt
+		if m.currentAutocomplete != nil && payload.ID == m.request.id {
+			if suggestions, ok := stringSlice(payload.Fields["suggestions"]); ok {
+				if appendMode, _ := payload.Fields["append"].(bool); appendMode {
+					m.currentAutocomplete.AppendSuggestions(suggestions)
+				} else {
+					m.currentAutocomplete.SetSuggestions(suggestions)
+				}
+			}
+			break
+		}
+
+		// Update the progress bar with this ID
+		if payload.Fields != nil && m.updateProgressFields(payload.ID, payload.Fields) {
 			if msgField, ok := payload.Fields["message"].(string); ok {
 				m.statusMessage = msgField
 			}
-			// For now, progressive updates to progress indicators work through status message
-			// Full component update implementation would require tracking component IDs
 		}
 
 	case protocol.TypeLayout:
@@ -682,123 +1541,35 @@ func (m Model) handleProtocolMsg(msg *protocol.Message) (tea.Model, tea.Cmd) {
 			m.setError("Invalid layout payload", err.Error(), false)
 			return m, m.listenForMessages()
 		}
+		m.addLayout(payload)
 
-		// Render layout title if present
-		var layoutContent strings.Builder
-		if payload.Title != "" {
-			titleStyle := lipgloss.NewStyle().
-				Bold(true).
-				Foreground(theme.Current.Colors.Primary).
-				MarginBottom(1)
-			layoutContent.WriteString(titleStyle.Render(payload.Title) + "\n")
-		}
-
-		if payload.Description != "" {
-			descStyle := lipgloss.NewStyle().
-				Foreground(theme.Current.Colors.TextMuted).
-				MarginBottom(1)
-			layoutContent.WriteString(descStyle.Render(payload.Description) + "\n")
-		}
+	case protocol.TypeScrollTo, protocol.TypeFocusInput, protocol.TypeCollapse, protocol.TypeExpand:
+		m.handleControl(msg)
 
-		// Render each component in the layout
-		// For now, render components vertically (dashboard-style horizontal layout would require more complex TUI logic)
-		for i, component := range payload.Components {
-			// Create a protocol message for each component and render it
-			componentMsg := &protocol.Message{
-				Type: protocol.MessageType(component.Type),
-			}
+	case protocol.TypeInputSuggestion:
+		m.handleInputSuggestion(msg)
 
-			// Marshal the component payload back to JSON for parsing
-			componentPayloadBytes, err := json.Marshal(component.Payload)
-			if err != nil {
-				continue // Skip invalid components
-			}
-			componentMsg.Payload = componentPayloadBytes
-
-			// Render the component based on its type
-			var componentView string
-			switch component.Type {
-			case "table":
-				var tablePayload protocol.TablePayload
-				if err := componentMsg.ParsePayload(&tablePayload); err == nil {
-					// Convert columns to strings
-					cols := make([]string, len(tablePayload.Columns))
-					for j, c := range tablePayload.Columns {
-						if s, ok := c.(string); ok {
-							cols[j] = s
-						} else {
-							cols[j] = fmt.Sprintf("%v", c)
-						}
-					}
-					tableView := views.NewTableView()
-					tableView.SetTitle(tablePayload.Title)
-					tableView.SetColumns(cols)
-					tableView.SetRows(tablePayload.Rows)
-					tableView.SetFooter(tablePayload.Footer)
-					tableView.SetWidth(m.width - 4)
-					componentView = tableView.View()
-				}
-			case "code":
-				var codePayload protocol.CodePayload
-				if err := componentMsg.ParsePayload(&codePayload); err == nil {
-					codeView := views.NewCodeView()
-					codeView.SetCode(codePayload.Code)
-					codeView.SetLanguage(codePayload.Language)
-					codeView.SetTitle(codePayload.Title)
-					codeView.SetWidth(m.width - 4)
-					componentView = codeView.View()
-				}
-			case "progress":
-				var progressPayload protocol.ProgressPayload
-				if err := componentMsg.ParsePayload(&progressPayload); err == nil {
-					progressView := views.NewProgressView()
-					progressView.SetWidth(m.width - 4)
-					progressView.SetMessage(progressPayload.Message)
-					if progressPayload.Percent != nil {
-						progressView.SetPercent(*progressPayload.Percent)
-					}
-					if progressPayload.Steps != nil {
-						steps := make([]views.ProgressStep, len(progressPayload.Steps))
-						for j, s := range progressPayload.Steps {
-							steps[j] = views.ProgressStep{
-								Label:  s.Label,
-								Status: s.Status,
-								Detail: s.Detail,
-							}
-						}
-						progressView.SetSteps(steps)
-					}
-					componentView = progressView.View()
-				}
-			case "alert":
-				var alertPayload protocol.AlertPayload
-				if err := componentMsg.ParsePayload(&alertPayload); err == nil {
-					alertView := views.NewAlertView()
-					alertView.SetMessage(alertPayload.Message)
-					alertView.SetTitle(alertPayload.Title)
-					alertView.SetSeverity(alertPayload.Severity)
-					alertView.SetWidth(m.width - 4)
-					componentView = alertView.View()
-				}
-			}
+	case protocol.TypeCommands:
+		var payload protocol.CommandsPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid commands payload", err.Error(), false)
+			return m, m.listenForMessages()
+		}
+		m.handleCommands(payload)
 
-			if componentView != "" {
-				layoutContent.WriteString(componentView)
-				// Add spacing between components
-				if i < len(payload.Components)-1 {
-					layoutContent.WriteString("\n")
-				}
-			}
+	case protocol.TypeTool:
+		var payload protocol.ToolPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid tool payload", err.Error(), false)
+			return m, m.listenForMessages()
 		}
+		m.handleTool(msg, payload)
 
-		// Add the rendered layout to messages
-		m.messages = append(m.messages, Message{
-			Role:      "assistant",
-			Content:   layoutContent.String(),
-			Timestamp: time.Now(),
-		})
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+	case protocol.TypeBatch:
+		return m.applyBatch(msg.Batch)
+
+	default:
+		m.addUnsupported(msg)
 	}
 
 	return m, m.listenForMessages()
@@ -810,46 +1581,77 @@ func (m Model) renderMessages() string {
 	styles := theme.Current.Styles
 	colors := theme.Current.Colors
 
-	for _, msg := range m.messages {
+	streaming := m.hasOpenMessage()
+	m.renders.beginPass()
+	m.renders.beginQualityPass(streaming)
+	defer m.renders.endPass()
+	defer func(start time.Time) {
+		m.renders.observe(time.Since(start), streaming)
+	}(time.Now())
+
+	var lastTime, lastAgent string
+	var lines int
+	starts := make([]int, 0, len(m.messages))
+	foldable := make([]bool, len(m.messages))
+	for i, msg := range m.messages {
+		starts = append(starts, lines)
+		timeLine := m.renderTime(msg.Timestamp, &lastTime)
+		if msg.Role == "user" {
+			lastAgent = ""
+		}
+		timeLine += m.renderAgentLabel(msg.Agent, &lastAgent)
+		timeLine += m.renderSelected(i)
 		var content string
 
-		switch msg.Role {
-		case "user":
-			prefix := "👤 "
+		switch {
+		case msg.Collapsed:
+			content = renderCollapsed(msg, m.width)
+
+		case msg.Role == "user":
+			prefix := theme.Glyphs.User
 			style := styles.UserMessage
 			if m.width > 0 {
 				style = style.Width(m.width - 4)
 			}
 			content = style.Render(prefix + msg.Content)
 
-		case "assistant":
-			prefix := "🤖 "
-			if msg.IsCode {
-				// Render as code block
-				m.codeView.SetCode(msg.Content)
-				m.codeView.SetLanguage(msg.Language)
-				content = m.codeView.View()
-			} else {
-				// Render markdown
-				m.markdownView.SetContent(msg.Content)
-				rendered := m.markdownView.View()
-				// Add prefix to first line
-				lines := strings.SplitN(rendered, "\n", 2)
-				if len(lines) > 1 {
-					content = prefix + lines[0] + "\n" + lines[1]
-				} else {
-					content = prefix + rendered
-				}
+		case msg.Role == "assistant":
+			content = m.renderAssistantMessage(msg)
+			if foldable[i] = m.foldable(msg, content); foldable[i] && !msg.Unfolded {
+				content = m.renderFolded(msg, content)
 			}
 
-		case "system":
+		case msg.Role == "system":
+			if msg.Unsupported != "" {
+				content = renderUnsupported(msg)
+				break
+			}
 			// System messages are pre-rendered (tables, alerts, etc.)
 			content = msg.Content
 		}
 
+		// Tables link their own cells, code is left as written and
+		// markdown is linked as it renders
+		if msg.Role == "user" && !msg.Collapsed {
+			content = views.Linkify(content)
+		}
+		if msg.Timing != nil {
+			timing := m.renderElapsed(msg.Timing)
+			if m.showTurnTiming() {
+				timing = m.renderWaterfall(msg.Timing)
+			}
+			if timing != "" {
+				content += "\n" + timing
+			}
+		}
+
+		sb.WriteString(timeLine)
 		sb.WriteString(content)
 		sb.WriteString("\n")
+		lines += strings.Count(timeLine, "\n") + strings.Count(content, "\n") + 1
 	}
+	m.layout.starts = starts
+	m.layout.foldable = foldable
 
 	// Render streaming text
 	if m.streamingText != "" {
@@ -857,27 +1659,42 @@ func (m Model) renderMessages() string {
 		if m.width > 0 {
 			style = style.Width(m.width - 4)
 		}
-		sb.WriteString(style.Render("🤖 " + m.streamingText + "▌"))
+		text := m.streamingText
+		if limit := m.options.Limits.MarkdownBytes; limit > 0 && len(text) > limit {
+			text = cutText(text, limit)
+		}
+		sb.WriteString(m.renderAgentLabel(m.streamAgent, &lastAgent))
+		sb.WriteString(views.Linkify(style.Render(theme.Glyphs.Assistant + text + theme.Glyphs.Cursor)))
 		sb.WriteString("\n")
+		if len(text) < len(m.streamingText) {
+			sb.WriteString(renderTruncation(formatBytes(int64(len(text)))+" of "+formatBytes(int64(len(m.streamingText)))+" so far", false))
+			sb.WriteString("\n")
+		}
 	}
 
-	// Render current progress if any
-	if m.currentProgress != nil {
+	// Render active progress bars
+	if len(m.progressOrder) > 0 {
 		sb.WriteString("\n")
-		sb.WriteString(m.currentProgress.View())
+		sb.WriteString(m.renderProgress())
 	}
 
-	return sb.String()
+	m.layout.rendered = sb.String()
+	return m.layout.rendered
 }
 
 // View renders the UI.
 func (m Model) View() string {
+	defer m.options.Telemetry.CatchPanic()
+	defer m.reportCrash()
 	if !m.ready {
 		return m.spinner.View() + " Initializing..."
 	}
 
 	if m.quitting {
-		return "Goodbye! 👋\n"
+		return "Goodbye!" + theme.Glyphs.Goodbye + "\n"
+	}
+	if m.nativeSelect && m.state == StateChat {
+		return m.renderNativeSelect()
 	}
 
 	styles := theme.Current.Styles
@@ -887,7 +1704,10 @@ func (m Model) View() string {
 	headerStyle := styles.Header.Width(m.width)
 	headerContent := m.appName
 	if m.appTagline != "" {
-		headerContent += " · " + m.appTagline
+		headerContent += theme.Glyphs.Separator + m.appTagline
+	}
+	if tabs := m.renderTabs(); tabs != "" {
+		headerContent = ansi.Truncate(headerContent+"   "+tabs, max(0, m.width-headerStyle.GetHorizontalFrameSize()), theme.Glyphs.Ellipsis)
 	}
 	header := headerStyle.Render(headerContent)
 
@@ -895,31 +1715,93 @@ func (m Model) View() string {
 	var content string
 	switch m.state {
 	case StateChat:
-		content = m.viewport.View()
+		content = m.placeCommandMenu(m.renderPinned() + m.placeToast(m.placeNewPill(m.highlightSearch(m.viewport.View(), m.viewport.YOffset))))
 	case StateForm:
 		if m.currentForm != nil {
-			content = m.centerVertically(m.currentForm.View())
+			content = m.centerVertically(m.withCountdown(m.currentForm.View()))
 		}
 	case StateConfirm:
 		if m.currentConfirm != nil {
-			content = m.centerVertically(m.currentConfirm.View())
+			content = m.centerVertically(m.withCountdown(m.currentConfirm.View()))
 		}
 	case StateSelect:
 		if m.currentSelect != nil {
-			content = m.centerVertically(m.currentSelect.View())
+			content = m.centerVertically(m.withCountdown(m.currentSelect.View()))
+		}
+	case StateSecret:
+		if m.currentSecret != nil {
+			content = m.centerVertically(m.currentSecret.View())
+		}
+	case StateAutocomplete:
+		if m.currentAutocomplete != nil {
+			content = m.centerVertically(m.currentAutocomplete.View())
+		}
+	case StateQuestion:
+		if m.currentQuestion != nil {
+			content = m.centerVertically(m.currentQuestion.View())
+		}
+	case StateAttach:
+		if m.currentAttach != nil {
+			content = m.centerVertically(m.currentAttach.View())
+		}
+	case StateMenu:
+		if m.currentMenu != nil {
+			content = m.centerVertically(m.currentMenu.View())
+		}
+	case StateDiff:
+		if m.currentDiff != nil {
+			content = m.centerVertically(m.currentDiff.View())
 		}
 	case StateError:
 		content = m.centerVertically(m.renderError())
+	case StateUnresponsive:
+		if m.heartbeat.prompt != nil {
+			content = m.centerVertically(m.heartbeat.prompt.View())
+		}
+	case StateDisconnected:
+		if m.reconnect.prompt != nil {
+			content = m.centerVertically(m.reconnect.prompt.View())
+		}
+	case StateAgentExited:
+		if m.supervisor.prompt != nil {
+			content = m.centerVertically(m.supervisor.prompt.View())
+		}
+	case StateStartupTimeout:
+		content = m.centerVertically(m.renderStartupTimeout())
+	case StateCompare:
+		if m.currentCompare != nil {
+			content = m.currentCompare.View()
+		}
+	case StateFullView:
+		if m.currentPager != nil {
+			content = m.currentPager.View()
+		}
+	case StateSessions:
+		if m.sessionPicker != nil {
+			content = m.centerVertically(m.sessionPicker.View())
+		}
+	}
+	if m.helpOpen {
+		content = m.centerVertically(m.renderHelp())
 	}
 
 	// Input area (only in chat mode)
 	var inputArea string
+	var inputStyle lipgloss.Style
 	if m.state == StateChat {
-		inputStyle := styles.InputFieldFocus.Width(m.width - 4)
-		if m.isStreaming {
+		inputStyle = styles.InputFieldFocus.Width(m.width - 4)
+		if m.isStreaming || m.focused() != PanelInput {
 			inputStyle = styles.InputField.Width(m.width - 4)
 		}
-		inputArea = inputStyle.Render(m.input.View())
+		inputView := m.input.View()
+		if m.suggesting() {
+			inputView = m.renderSuggestion(inputView)
+		}
+		if m.search.open {
+			inputStyle = styles.InputFieldFocus.Width(m.width - 4).Height(lipgloss.Height(inputView))
+			inputView = m.renderSearchBar()
+		}
+		inputArea = inputStyle.Render(inputView)
 	}
 
 	// Status bar
@@ -928,33 +1810,74 @@ func (m Model) View() string {
 	if m.isStreaming {
 		statusContent = m.spinner.View() + " " + statusContent
 	}
+	if m.state == StateChat {
+		statusContent = m.renderFocus() + m.renderSuggestionHint() + statusContent
+	}
+	if m.state == StateChat && m.search.open {
+		statusContent = styles.Focused.Render(theme.Glyphs.Focus+m.searchCount()) + "  " + statusContent
+	}
+
+	if m.renders.degraded {
+		statusContent += lipgloss.NewStyle().Foreground(colors.TextMuted).Render(" · fast render")
+	}
 
-	// Token info on right side
-	if m.tokenInfo != nil && m.tokenInfo.Input > 0 {
-		tokenStr := fmt.Sprintf("↑%d ↓%d", m.tokenInfo.Input, m.tokenInfo.Output)
-		padding := m.width - lipgloss.Width(statusContent) - lipgloss.Width(tokenStr) - 4
+	// Usage, token info and connection state on right side
+	right := m.renderConnState()
+	if rate := m.renderStreamRate(); rate != "" {
+		if right != "" {
+			right = rate + "  " + right
+		} else {
+			right = rate
+		}
+	}
+	if usage := m.renderUsage(); usage != "" {
+		if right != "" {
+			right = usage + "  " + right
+		} else {
+			right = usage
+		}
+	} else if m.tokenInfo != nil && m.tokenInfo.Input > 0 {
+		tokenStr := lipgloss.NewStyle().Foreground(colors.TextMuted).Render(fmt.Sprintf("%s%d %s%d", theme.Glyphs.Up, m.tokenInfo.Input, theme.Glyphs.Down, m.tokenInfo.Output))
+		if right != "" {
+			right = tokenStr + "  " + right
+		} else {
+			right = tokenStr
+		}
+	}
+	if right != "" {
+		padding := m.width - lipgloss.Width(statusContent) - lipgloss.Width(right) - 4
 		if padding > 0 {
 			statusContent += strings.Repeat(" ", padding)
-			statusContent += lipgloss.NewStyle().Foreground(colors.TextMuted).Render(tokenStr)
+			statusContent += right
 		}
 	}
 
 	// Debug info
 	if m.debugMode {
-		debugInfo := fmt.Sprintf(" | State: %d | Msgs: %d", m.state, len(m.messages))
+		debugInfo := fmt.Sprintf(" | State: %d | Msgs: %d | Session: %s", m.state, len(m.messages), m.sessionDuration())
+		queue := m.handler.QueueStats()
+		debugInfo += fmt.Sprintf(" | Queue: %d/%d (peak %d)", queue.Depth, queue.Capacity, queue.Peak)
+		if queue.Window > 0 {
+			debugInfo += fmt.Sprintf(" | Credits: %d/%d", queue.Credits, queue.Window)
+		}
+		if m.stripped > 0 {
+			debugInfo += fmt.Sprintf(" | Stripped: %d", m.stripped)
+		}
 		statusContent += lipgloss.NewStyle().Foreground(colors.Warning).Render(debugInfo)
 	}
 
 	statusBar := statusStyle.Render(statusContent)
 
 	// Combine
-	return lipgloss.JoinVertical(
+	frame := m.placeWide(lipgloss.JoinVertical(
 		lipgloss.Left,
 		header,
 		content,
 		inputArea,
 		statusBar,
-	)
+	))
+	m.trackCaret(frame, lipgloss.Height(header)+lipgloss.Height(content), inputStyle)
+	return frame
 }
 
 func (m Model) centerVertically(content string) string {
@@ -983,7 +1906,7 @@ func (m Model) renderError() string {
 	titleStyle := lipgloss.NewStyle().
 		Foreground(colors.Error).
 		Bold(true)
-	sb.WriteString(titleStyle.Render("⚠ " + m.lastError.Message))
+	sb.WriteString(titleStyle.Render(theme.Glyphs.Warning + " " + m.lastError.Message))
 	sb.WriteString("\n\n")
 
 	// Details
@@ -1005,3 +1928,46 @@ func (m Model) renderError() string {
 	containerStyle := styles.AlertError.Width(60)
 	return containerStyle.Render(sb.String())
 }
+
+// stringSlice converts a decoded JSON array into a []string.
+func stringSlice(v any) ([]string, bool) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		} else {
+			out = append(out, fmt.Sprintf("%v", item))
+		}
+	}
+	return out, true
+}
+
+// findOpenMarkdown returns the index of the open markdown message with the
+// given ID, or the most recent open one when id is empty; -1 if none.
+func (m Model) findOpenMarkdown(id string) int {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		msg := m.messages[i]
+		if msg.Open && msg.Role == "assistant" && !msg.IsCode && (id == "" || msg.ID == id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// findCodeMessage returns the index of the code message with the given ID,
+// or -1 if there is none.
+func (m Model) findCodeMessage(id string) int {
+	if id == "" {
+		return -1
+	}
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].IsCode && m.messages[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+func TestTypeFeature(t *testing.T) {
+	tests := map[protocol.MessageType]string{
+		protocol.TypeMarkdown: "type:markdown",
+		protocol.TypeBatch:    "type:batch",
+		"my secret prompt":    "type:unsupported",
+		protocol.TypeInput:    "type:unsupported", // sent by the TUI, never rendered
+	}
+	for typ, want := range tests {
+		if got := typeFeature(typ); got != want {
+			t.Errorf("typeFeature(%q) = %q, want %q", typ, got, want)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// The artifacts panel is shown beside the chat when there is room for it
+// to be at least minArtifactsWidth wide, artifactsGap away from a chat at
+// least minChatWidth wide.
+const (
+	minArtifactsWidth = 40
+	minChatWidth      = 60
+	artifactsGap      = 2
+)
+
+// maxArtifactVersions is how many earlier versions of an artifact are kept
+// for comparing with.
+const maxArtifactVersions = 10
+
+// artifact is a key output kept in the artifacts panel, in a tab of its
+// own: a message pinned from the chat, or one the agent sent as an
+// artifact. Versions are the contents it had before the agent replaced
+// it, oldest first.
+type artifact struct {
+	id       string
+	title    string
+	msg      Message
+	versions []Message
+}
+
+// artifactsCache keeps the lines of the artifact shown, as last rendered,
+// since View runs on every frame and the artifact rarely changes.
+type artifactsCache struct {
+	key   uint64
+	lines []string
+}
+
+// pinnable reports whether msg can be pinned as an artifact: tables, code
+// and the agent's replies.
+func pinnable(msg Message) bool {
+	return msg.Table != nil || msg.IsCode || msg.Role == "assistant"
+}
+
+// artifactsWidth returns how wide the artifacts panel is, or 0 if it is
+// not shown.
+func (m Model) artifactsWidth() int {
+	if len(m.artifacts) == 0 {
+		return 0
+	}
+	if width := m.termWidth - m.sidebarSpace() - m.width - artifactsGap; width >= minArtifactsWidth {
+		return width
+	}
+	return 0
+}
+
+// addArtifact shows a in its own tab, replacing the artifact with the same
+// ID if there is one. It reports whether a is new.
+func (m *Model) addArtifact(a artifact) bool {
+	i := slices.IndexFunc(m.artifacts, func(b artifact) bool { return b.id == a.id })
+	added := i < 0
+	if added {
+		m.artifacts = append(m.artifacts, a)
+		i = len(m.artifacts) - 1
+	} else {
+		old := m.artifacts[i]
+		a.versions = append(old.versions, old.msg)
+		if len(a.versions) > maxArtifactVersions {
+			a.versions = a.versions[len(a.versions)-maxArtifactVersions:]
+		}
+		m.artifacts[i] = a
+	}
+	if added || i == m.artifactTab {
+		m.artifactTab = i
+		m.artifactScroll = 0
+	}
+	if added && len(m.artifacts) == 1 {
+		m.layoutArtifacts()
+	}
+	return added
+}
+
+// closeArtifact removes the artifact in tab i, unpinning the message it
+// was pinned from.
+func (m *Model) closeArtifact(i int) {
+	id := m.artifacts[i].id
+	m.artifacts = slices.Delete(m.artifacts, i, i+1)
+	for j := range m.messages {
+		if m.messages[j].Artifact == id {
+			m.messages[j].Artifact = ""
+		}
+	}
+	if m.compareMark == id {
+		m.compareMark = ""
+	}
+	if m.artifactTab >= len(m.artifacts) {
+		m.artifactTab = max(len(m.artifacts)-1, 0)
+	}
+	m.artifactScroll = 0
+	if len(m.artifacts) == 0 {
+		if m.focused() == PanelArtifacts {
+			m.setFocus(PanelInput)
+		}
+		m.layoutArtifacts()
+	}
+}
+
+// layoutArtifacts lays the UI out again as the artifacts panel appears or
+// goes, since the chat may narrow or widen with it.
+func (m *Model) layoutArtifacts() {
+	if m.ready {
+		m.applyResize(m.termWidth, m.height)
+	}
+}
+
+// pinArtifact pins message i as an artifact.
+func (m *Model) pinArtifact(i int) {
+	m.artifactSeq++
+	id := fmt.Sprintf("pin-%d", m.artifactSeq)
+	m.messages[i].Artifact = id
+	m.addArtifact(artifact{id: id, title: artifactTitle(m.messages[i], ""), msg: m.messages[i]})
+	if m.artifactsWidth() == 0 {
+		m.statusMessage = "Pinned; widen the terminal to show artifacts"
+	} else {
+		m.statusMessage = "Pinned as an artifact"
+	}
+}
+
+// unpinArtifact closes the artifact pinned from message i.
+func (m *Model) unpinArtifact(i int) {
+	id := m.messages[i].Artifact
+	if j := slices.IndexFunc(m.artifacts, func(a artifact) bool { return a.id == id }); j >= 0 {
+		m.closeArtifact(j)
+	}
+	m.messages[i].Artifact = ""
+}
+
+// handleArtifact shows an artifact the agent sent, noting new ones in the
+// chat.
+func (m *Model) handleArtifact(msg *protocol.Message, payload protocol.ArtifactPayload) {
+	content := Message{Role: "assistant", Content: payload.Markdown, Timestamp: time.Now(), ID: msg.ID}
+	switch {
+	case payload.Code != nil:
+		content.Content = payload.Code.Code
+		content.IsCode = true
+		content.Language = cmp.Or(payload.Code.Language, views.DetectLanguage(payload.Code.Code))
+	case payload.Table != nil:
+		content.Role = "system"
+		content.Table = payload.Table
+	}
+
+	id := msg.ID
+	if id == "" {
+		m.artifactSeq++
+		id = fmt.Sprintf("artifact-%d", m.artifactSeq)
+	}
+	title := artifactTitle(content, payload.Title)
+	if !m.addArtifact(artifact{id: id, title: title, msg: content}) {
+		return
+	}
+
+	note := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).
+		Render(theme.Glyphs.Info + " Artifact: " + title)
+	m.messages = append(m.messages, Message{Role: "system", Content: note, Timestamp: time.Now(), Agent: msg.AgentID})
+	m.refreshMessages()
+	if m.artifactsWidth() == 0 {
+		m.statusMessage = "Widen the terminal to show artifacts"
+	}
+}
+
+// artifactTitle names the tab of an artifact made from msg, unless the
+// agent gave it a title.
+func artifactTitle(msg Message, title string) string {
+	switch {
+	case title != "":
+		return title
+	case msg.Table != nil:
+		return cmp.Or(msg.Table.Title, "Table")
+	case msg.IsCode:
+		return cmp.Or(msg.Language, "Code")
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(msg.Content), "\n")
+	line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+	if runes := []rune(line); len(runes) > 24 {
+		line = string(runes[:23]) + theme.Glyphs.Ellipsis
+	}
+	return cmp.Or(line, "Reply")
+}
+
+// updateArtifactsPanel handles a key while the artifacts panel has focus.
+func (m *Model) updateArtifactsPanel(msg tea.KeyMsg) tea.Cmd {
+	if len(m.artifacts) == 0 {
+		return nil
+	}
+	switch msg.String() {
+	case "left", "h":
+		m.artifactTab = (m.artifactTab + len(m.artifacts) - 1) % len(m.artifacts)
+		m.artifactScroll = 0
+	case "right", "l":
+		m.artifactTab = (m.artifactTab + 1) % len(m.artifacts)
+		m.artifactScroll = 0
+	case "up", "k":
+		m.artifactScroll = max(m.artifactScroll-1, 0)
+	case "down", "j":
+		m.artifactScroll++
+	case "pgup":
+		m.artifactScroll = max(m.artifactScroll-10, 0)
+	case "pgdown":
+		m.artifactScroll += 10
+	case "x":
+		m.closeArtifact(m.artifactTab)
+	case "v":
+		m.compareVersions()
+	case "c":
+		m.markForComparison()
+	case "o":
+		m.openFullView(m.artifacts[m.artifactTab].msg)
+	}
+	return nil
+}
+
+// renderArtifacts renders the artifacts panel: a row of tabs over the
+// artifact shown, scrolled to artifactScroll and cut off at the bottom of
+// the terminal.
+func (m Model) renderArtifacts(width int) string {
+	colors := theme.Current.Colors
+	active := lipgloss.NewStyle().Bold(true).Underline(true).Foreground(colors.Primary)
+	inactive := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	if m.focused() != PanelArtifacts {
+		active = active.Underline(false)
+	}
+
+	tabs := make([]string, len(m.artifacts))
+	for i, a := range m.artifacts {
+		title := a.title
+		if a.id == m.compareMark {
+			title = theme.Glyphs.Bullet + title
+		}
+		if i == m.artifactTab {
+			tabs[i] = active.Render(title)
+		} else {
+			tabs[i] = inactive.Render(title)
+		}
+	}
+	bar := strings.Join(tabs, inactive.Render(" "+theme.Glyphs.Separator+" "))
+	if lipgloss.Width(bar) > width {
+		// Too many tabs to list: show the one open and where it is
+		a := m.artifacts[m.artifactTab]
+		bar = active.Render(a.title) + inactive.Render(fmt.Sprintf("  %d/%d", m.artifactTab+1, len(m.artifacts)))
+	}
+
+	lines := m.artifactLines(width)
+	height := max(m.height-2, 1)
+	scroll := min(m.artifactScroll, max(len(lines)-height, 0))
+	body := lines[scroll:min(scroll+height, len(lines))]
+
+	return lipgloss.NewStyle().Width(width).MaxWidth(width).MaxHeight(m.height).
+		Render(bar + "\n\n" + strings.Join(body, "\n"))
+}
+
+// artifactLines returns the lines of the artifact shown, rendered at the
+// width of the panel.
+func (m Model) artifactLines(width int) []string {
+	a := m.artifacts[m.artifactTab]
+	h := fnv.New64a()
+	fmt.Fprint(h, width, theme.Current.Name, a.id, len(a.msg.Content), a.msg.Content)
+	if a.msg.Table != nil {
+		fmt.Fprint(h, len(a.msg.Table.Rows), a.msg.Table.Rows)
+	}
+	key := h.Sum64()
+	if m.artifactsView.lines != nil && m.artifactsView.key == key {
+		return m.artifactsView.lines
+	}
+
+	msg, shown := m.options.Limits.truncate(a.msg)
+	rendered := renderArtifact(msg, width)
+	if shown != "" {
+		rendered += "\n" + renderTruncation(shown, true)
+	}
+	lines := strings.Split(rendered, "\n")
+	*m.artifactsView = artifactsCache{key: key, lines: lines}
+	return lines
+}
+
+// renderArtifact renders msg for a panel width wide. Tables, code and
+// markdown reflow to it; anything else is shown as rendered for the chat.
+func renderArtifact(msg Message, width int) string {
+	if msg.Table != nil {
+		tv := views.NewTableView()
+		tv.SetTitle(msg.Table.Title)
+		tv.SetColumns(tableColumns(msg.Table))
+		tv.SetRows(msg.Table.Rows)
+		tv.SetFooter(msg.Table.Footer)
+		tv.SetWidth(width)
+		return tv.View()
+	}
+	if msg.IsCode || msg.Role == "assistant" {
+		markdownView, codeView := views.NewMarkdownView(), views.NewCodeView()
+		markdownView.SetWidth(width)
+		codeView.SetWidth(width)
+		return renderAssistant(msg, markdownView, codeView)
+	}
+	return msg.Content
+}
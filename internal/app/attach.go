@@ -0,0 +1,154 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// openAttachPrompt shows a path prompt with filesystem suggestions,
+// starting from the given partial path.
+func (m *Model) openAttachPrompt(initial string) {
+	m.currentAttach = components.NewAutocomplete(&protocol.AutocompletePayload{
+		Label:       theme.Glyphs.Attachment + "Attach file",
+		Placeholder: "path/to/file",
+		Suggestions: listPathSuggestions(initial),
+		Default:     initial,
+	})
+	m.currentAttach.SetWidth(m.width)
+	m.attachDir = pathDir(initial)
+	m.state = StateAttach
+}
+
+// refreshAttachSuggestions reloads suggestions when the typed path moves
+// into another directory.
+func (m *Model) refreshAttachSuggestions() {
+	typed := m.currentAttach.Typed()
+	dir := pathDir(typed)
+	if dir == m.attachDir {
+		return
+	}
+	m.attachDir = dir
+	m.currentAttach.SetSuggestions(listPathSuggestions(typed))
+}
+
+// attachedMsg reports the outcome of sending an attachment.
+type attachedMsg struct {
+	info *protocol.AttachmentPayload
+	err  error
+}
+
+// sendAttachment sends the file at path to Python in the background, as
+// reading and writing a large file takes a while and the agent may be slow
+// to read it; attached notes it in the transcript once it is sent.
+func (m *Model) sendAttachment(path string) tea.Cmd {
+	path = expandHome(strings.TrimSpace(path))
+	if path == "" {
+		return nil
+	}
+
+	// Choosing a directory descends into it instead of sending it
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		if !strings.HasSuffix(path, string(filepath.Separator)) {
+			path += string(filepath.Separator)
+		}
+		m.openAttachPrompt(path)
+		return nil
+	}
+
+	m.statusMessage = "Attaching " + filepath.Base(path) + "..."
+	handler := m.handler
+	return func() tea.Msg {
+		info, err := handler.SendAttachment(path)
+		return attachedMsg{info, err}
+	}
+}
+
+// attached notes a sent attachment in the transcript.
+func (m *Model) attached(msg attachedMsg) {
+	if msg.err != nil {
+		m.setError("Failed to attach file", msg.err.Error(), false)
+		return
+	}
+	info := msg.info
+	m.messages = append(m.messages, Message{
+		Role:      "user",
+		Content:   fmt.Sprintf("%s%s (%s, %s)", theme.Glyphs.Attachment, info.Name, info.MimeType, formatBytes(info.Size)),
+		Timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.statusMessage = "Attached " + info.Name
+}
+
+// pathDir returns the directory portion of a partially typed path.
+func pathDir(typed string) string {
+	if typed == "" {
+		return "."
+	}
+	if strings.HasSuffix(typed, string(filepath.Separator)) {
+		return filepath.Clean(expandHome(typed))
+	}
+	return filepath.Dir(expandHome(typed))
+}
+
+// listPathSuggestions lists entries of the directory being typed, keeping
+// the typed directory prefix so suggestions complete the whole path.
+func listPathSuggestions(typed string) []string {
+	dir := pathDir(typed)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	prefix := ""
+	if i := strings.LastIndex(typed, string(filepath.Separator)); i >= 0 {
+		prefix = typed[:i+1]
+	}
+
+	suggestions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if e.IsDir() {
+			name += string(filepath.Separator)
+		}
+		suggestions = append(suggestions, prefix+name)
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// formatBytes renders a byte count as a short human-readable size.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// applyBatch applies the messages of a batch in order within one update,
+// so the burst is drawn as a single frame instead of one per message.
+func (m Model) applyBatch(msgs []*protocol.Message) (tea.Model, tea.Cmd) {
+	// Listen for the next message once, after the whole batch
+	m.batching = true
+	cmds := make([]tea.Cmd, 0, len(msgs)+1)
+	for _, msg := range msgs {
+		model, cmd := m.routeMessage(msg)
+		m = model.(Model)
+		cmds = append(cmds, cmd)
+	}
+	m.batching = false
+	return m, tea.Batch(append(cmds, m.listenForMessages())...)
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// receiveBlob stores one blob message, noting the blob in the transcript
+// once it is complete. A blob sent again replaces the earlier note.
+func (m *Model) receiveBlob(p protocol.BlobPayload) error {
+	if m.options.Blobs == nil {
+		return errors.New("this TUI does not keep blobs")
+	}
+	blob, err := m.options.Blobs.Add(p)
+	if err != nil {
+		return err
+	}
+	if blob == nil {
+		m.statusMessage = chunkProgress("Receiving "+blobName(p), p.Chunk+1, p.Chunks, "chunks")
+		return nil
+	}
+
+	note := Message{
+		Role:      "system",
+		Content:   renderBlob(blob),
+		Timestamp: time.Now(),
+		ID:        blob.ID,
+		Blob:      blob,
+		Summary:   theme.Glyphs.Attachment + blob.Name,
+	}
+	if idx := m.findBlobMessage(blob.ID); idx >= 0 {
+		m.messages[idx] = note
+	} else {
+		m.messages = append(m.messages, note)
+	}
+	m.statusMessage = fmt.Sprintf("Received %s (%s)", blob.Name, formatBytes(blob.Size))
+	m.refreshMessages()
+	return nil
+}
+
+// renderBlob renders the note of a received blob, linking to its file.
+func renderBlob(blob *protocol.Blob) string {
+	muted := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted)
+	return theme.Glyphs.Attachment +
+		views.Hyperlink("file://"+blob.Path, blob.Name) +
+		muted.Render(fmt.Sprintf(" (%s, %s)", blob.MimeType, formatBytes(blob.Size)))
+}
+
+// blobName names a blob still being received.
+func blobName(p protocol.BlobPayload) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// findBlobMessage returns the index of the note of the blob with the given
+// ID, or -1 if there is none.
+func (m Model) findBlobMessage(id string) int {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Blob != nil && m.messages[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// budgetBarWidth is how many cells the budget bar in the status bar has.
+const budgetBarWidth = 8
+
+// sessionUsage adds up the tokens and cost the agent reported in metadata
+// messages over the session.
+type sessionUsage struct {
+	input, output int
+	cost          float64
+	reported      bool // a metadata message has arrived
+	exceeded      bool // the user and agent were told of going over budget
+}
+
+func (u sessionUsage) tokens() int {
+	return u.input + u.output
+}
+
+// handleMetadata adds what a step of the agent's work used to the session,
+// warning the user and telling the agent the first time it goes over
+// budget.
+func (m *Model) handleMetadata(payload protocol.MetadataPayload) {
+	m.usage.reported = true
+	m.usage.cost += payload.Cost
+	if payload.Tokens != nil {
+		m.usage.input += payload.Tokens.Input
+		m.usage.output += payload.Tokens.Output
+	}
+	if m.usage.exceeded || m.budgetUsed() < 1 {
+		return
+	}
+	m.usage.exceeded = true
+
+	err := m.handler.SendBudgetExceeded(protocol.BudgetExceededPayload{
+		Cost:        m.usage.cost,
+		Tokens:      m.usage.tokens(),
+		Budget:      m.options.Budget,
+		TokenBudget: m.options.TokenBudget,
+	})
+	if err != nil && !notConnected(err) {
+		m.setError("Failed to send budget_exceeded", err.Error(), true)
+	}
+
+	m.alertView.SetTitle("Session budget exceeded")
+	m.alertView.SetMessage(fmt.Sprintf("The session has used %s after %s. The agent has been told.",
+		m.usageAgainstBudget(), m.sessionDuration()))
+	m.alertView.SetSeverity("warning")
+	m.messages = append(m.messages, Message{
+		Role:      "system",
+		Content:   m.alertView.View(),
+		Timestamp: time.Now(),
+		Summary:   "Session budget exceeded",
+	})
+	m.refreshMessages()
+	m.statusMessage = "Session budget exceeded"
+}
+
+// budgetUsed returns the share of the session budget used: the larger of
+// the cost and token shares, for whichever budgets are set. It is 0 if
+// none is.
+func (m Model) budgetUsed() float64 {
+	used := 0.0
+	if m.options.Budget > 0 {
+		used = m.usage.cost / m.options.Budget
+	}
+	if m.options.TokenBudget > 0 {
+		used = max(used, float64(m.usage.tokens())/float64(m.options.TokenBudget))
+	}
+	return used
+}
+
+// usageAgainstBudget describes what the session used of each budget set,
+// e.g. "$1.02 of $1.00".
+func (m Model) usageAgainstBudget() string {
+	var parts []string
+	if m.options.Budget > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %s", formatCost(m.usage.cost), formatCost(m.options.Budget)))
+	}
+	if m.options.TokenBudget > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d tokens", m.usage.tokens(), m.options.TokenBudget))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// renderUsage renders the session's stopwatch, tokens and cost for the
+// status bar, with a bar filling up as the budget is used. It is empty
+// until the agent reports usage, unless a budget is set.
+func (m Model) renderUsage() string {
+	budgeted := m.options.Budget > 0 || m.options.TokenBudget > 0
+	if !m.usage.reported && !budgeted {
+		return ""
+	}
+	colors := theme.Current.Colors
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+
+	parts := []string{m.sessionDuration()}
+	if m.usage.tokens() > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d %s%d", theme.Glyphs.Up, m.usage.input, theme.Glyphs.Down, m.usage.output))
+	}
+	switch {
+	case m.options.Budget > 0:
+		parts = append(parts, formatCost(m.usage.cost)+"/"+formatCost(m.options.Budget))
+	case m.usage.cost > 0:
+		parts = append(parts, formatCost(m.usage.cost))
+	}
+	usage := muted.Render(strings.Join(parts, " "+theme.Glyphs.Separator+" "))
+	if !budgeted {
+		return usage
+	}
+
+	used := m.budgetUsed()
+	filled := min(int(math.Round(used*budgetBarWidth)), budgetBarWidth)
+	color := colors.Primary
+	switch {
+	case used >= 1:
+		color = colors.Error
+	case used >= 0.8:
+		color = colors.Warning
+	}
+	bar := lipgloss.NewStyle().Foreground(color).Render(strings.Repeat(theme.Glyphs.BarFull, filled)) +
+		muted.Render(strings.Repeat(theme.Glyphs.BarEmpty, budgetBarWidth-filled))
+	return usage + " " + bar
+}
+
+// formatCost formats dollars, with cents, or to a tenth of a cent below
+// one dollar.
+func formatCost(dollars float64) string {
+	if dollars < 1 {
+		return fmt.Sprintf("$%.3f", dollars)
+	}
+	return fmt.Sprintf("$%.2f", dollars)
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// renderTable renders table data with the shared table view.
+func (m Model) renderTable(t *protocol.TablePayload) string {
+	rows, shown := m.options.Limits.truncateTable(t)
+	m.tableView.SetTitle(t.Title)
+	m.tableView.SetColumns(tableColumns(t))
+	m.tableView.SetRows(rows)
+	m.tableView.SetFooter(t.Footer)
+	if shown != "" {
+		return m.tableView.View() + "\n" + renderTruncation(shown, true)
+	}
+	return m.tableView.View()
+}
+
+// appendTableChunk adds streamed rows to a table sent earlier.
+func (m *Model) appendTableChunk(p protocol.TableChunkPayload) error {
+	idx := m.findTableMessage(p.ID)
+	if idx < 0 {
+		return fmt.Errorf("no table with id %q", p.ID)
+	}
+	table := m.messages[idx].Table
+	table.Rows = append(table.Rows, p.Rows...)
+	m.messages[idx].Content = m.renderTable(table)
+
+	if p.Done {
+		m.statusMessage = fmt.Sprintf("Received %d rows", len(table.Rows))
+	} else {
+		m.statusMessage = chunkProgress("Receiving table", len(table.Rows), p.Total, "rows")
+	}
+	m.refreshMessages()
+	return nil
+}
+
+// updateTable appends or replaces rows of a table sent earlier.
+func (m *Model) updateTable(p protocol.TableUpdatePayload) error {
+	idx := m.findTableMessage(p.ID)
+	if idx < 0 {
+		return fmt.Errorf("no table with id %q", p.ID)
+	}
+	table := m.messages[idx].Table
+
+	switch p.Op {
+	case "", protocol.TableUpdateAppend:
+		if p.Footer != "" {
+			table.Footer = p.Footer
+		}
+		// Appending is streaming the rows in one chunk
+		return m.appendTableChunk(protocol.TableChunkPayload{ID: p.ID, Rows: p.Rows, Done: true})
+	case protocol.TableUpdateReplace:
+		if p.Start == nil {
+			table.Rows = p.Rows
+			break
+		}
+		start := *p.Start
+		if start < 0 || start > len(table.Rows) {
+			return fmt.Errorf("start row %d out of range (0-%d)", start, len(table.Rows))
+		}
+		for i, row := range p.Rows {
+			if start+i < len(table.Rows) {
+				table.Rows[start+i] = row
+			} else {
+				table.Rows = append(table.Rows, row)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown table update op %q", p.Op)
+	}
+	if p.Footer != "" {
+		table.Footer = p.Footer
+	}
+
+	m.messages[idx].Content = m.renderTable(table)
+	m.refreshMessages()
+	return nil
+}
+
+// appendCodeChunk adds streamed text to a code block sent earlier.
+func (m *Model) appendCodeChunk(p protocol.CodeChunkPayload) error {
+	idx := m.findCodeMessage(p.ID)
+	if idx < 0 {
+		return fmt.Errorf("no code block with id %q", p.ID)
+	}
+	m.messages[idx].Content += p.Code
+	m.messages[idx].Open = !p.Done
+
+	if p.Done {
+		// Untagged code is guessed at once it is all there
+		if m.messages[idx].Language == "" {
+			m.messages[idx].Language = views.DetectLanguage(m.messages[idx].Content)
+		}
+		m.statusMessage = "Received " + formatBytes(int64(len(m.messages[idx].Content)))
+	} else {
+		m.statusMessage = chunkProgress("Receiving code", len(m.messages[idx].Content), p.Total, "bytes")
+	}
+	m.refreshMessages()
+	return nil
+}
+
+// refreshMessages re-renders the chat, following the bottom only if the
+// user had not scrolled away from it. Otherwise the line at the top of the
+// view stays there, even when messages above it changed height.
+func (m *Model) refreshMessages() {
+	atBottom := m.viewport.AtBottom()
+	anchor, into := m.topMessage()
+	if m.height > 0 {
+		// Pinning and unpinning grow and shrink the strip above
+		m.viewport.Height = m.transcriptHeight()
+	}
+	m.viewport.SetContent(m.renderMessages())
+	switch {
+	case atBottom:
+		m.viewport.GotoBottom()
+	case anchor >= 0 && anchor < len(m.layout.starts):
+		end := m.viewport.TotalLineCount()
+		if anchor+1 < len(m.layout.starts) {
+			end = m.layout.starts[anchor+1]
+		}
+		m.viewport.SetYOffset(m.layout.starts[anchor] + min(into, max(0, end-m.layout.starts[anchor]-1)))
+	}
+}
+
+// topMessage returns the message the top line of the view is in, as last
+// rendered, and how many lines into it that line is, or -1 if messages
+// were removed since, as the index may no longer be the same message.
+func (m Model) topMessage() (int, int) {
+	starts := m.layout.starts
+	if len(starts) == 0 || len(starts) > len(m.messages) {
+		return -1, 0
+	}
+	i := max(0, sort.SearchInts(starts, m.viewport.YOffset+1)-1)
+	return i, m.viewport.YOffset - starts[i]
+}
+
+// findTableMessage returns the index of the table message with the given ID,
+// or -1 if there is none.
+func (m Model) findTableMessage(id string) int {
+	if id == "" {
+		return -1
+	}
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Table != nil && m.messages[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// chunkProgress formats a status line for a chunked transfer.
+func chunkProgress(label string, received, total int, unit string) string {
+	if total <= 0 {
+		return fmt.Sprintf("%s%s %d %s", label, theme.Glyphs.Ellipsis, received, unit)
+	}
+	percent := received * 100 / total
+	if percent > 100 {
+		percent = 100
+	}
+	return fmt.Sprintf("%s%s %d/%d %s (%d%%)", label, theme.Glyphs.Ellipsis, received, total, unit, percent)
+}
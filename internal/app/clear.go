@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// handleClear carries out a clear message. What it removes from the
+// transcript can be put back with ctrl+z, so an agent tidying up its own
+// output never loses the user anything for good.
+func (m *Model) handleClear(payload protocol.ClearPayload) {
+	// Without a target these would match every message without an ID
+	if (payload.Scope == protocol.ClearByID || payload.Scope == protocol.ClearBeforeID) && payload.Target == "" {
+		m.statusMessage = fmt.Sprintf("Ignored %s clear without a target", payload.Scope)
+		return
+	}
+	var drop func(i int, msg Message) bool
+	switch payload.Scope {
+	case protocol.ClearProgress:
+		m.clearProgress()
+		return
+	case protocol.ClearAll:
+		m.clearProgress()
+		fallthrough
+	case protocol.ClearChat:
+		drop = func(int, Message) bool { return true }
+	case protocol.ClearLastTurn:
+		asked := m.askedBefore(len(m.messages) - 1)
+		drop = func(i int, _ Message) bool { return i > asked }
+	case protocol.ClearSystem:
+		drop = func(_ int, msg Message) bool { return msg.Role == "system" }
+	case protocol.ClearByID:
+		drop = func(_ int, msg Message) bool { return msg.ID == payload.Target }
+	case protocol.ClearBeforeID:
+		target := m.findMessage(payload.Target)
+		if target < 0 {
+			m.statusMessage = fmt.Sprintf("No message %q to clear before", payload.Target)
+			return
+		}
+		drop = func(i int, _ Message) bool { return i < target }
+	default:
+		m.statusMessage = fmt.Sprintf("Ignored unknown clear scope %q", payload.Scope)
+		return
+	}
+
+	cleared := m.clearMessages(drop)
+	switch {
+	case cleared == 0:
+		return
+	case cleared == 1:
+		m.statusMessage = "The agent cleared a message"
+	default:
+		m.statusMessage = fmt.Sprintf("The agent cleared %d messages", cleared)
+	}
+	m.statusMessage += theme.Glyphs.Separator + "ctrl+z to undo"
+}
+
+// clearMessages removes the messages drop picks from the transcript,
+// keeping them for undoDelete to put back, and returns how many it
+// removed. Removing none keeps what an earlier delete or clear removed.
+func (m *Model) clearMessages(drop func(i int, msg Message) bool) int {
+	var deleted []deletedMessage
+	kept := make([]Message, 0, len(m.messages))
+	for i, msg := range m.messages {
+		if drop(i, msg) {
+			deleted = append(deleted, deletedMessage{index: i, msg: msg})
+		} else {
+			kept = append(kept, msg)
+		}
+	}
+	if len(deleted) == 0 {
+		return 0
+	}
+	m.deleted = deleted
+	m.messages = kept
+	m.stopSelecting()
+	m.refreshMessages()
+	return len(deleted)
+}
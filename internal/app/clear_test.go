@@ -0,0 +1,61 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+func TestHandleClear(t *testing.T) {
+	transcript := []Message{
+		{Role: "user", Content: "a"},
+		{Role: "assistant", Content: "b", ID: "m1"},
+		{Role: "system", Content: "c"},
+		{Role: "user", Content: "d"},
+		{Role: "assistant", Content: "e", ID: "m2"},
+		{Role: "system", Content: "f"},
+	}
+	tests := []struct {
+		name    string
+		payload protocol.ClearPayload
+		want    []string // the contents left
+	}{
+		{"chat", protocol.ClearPayload{Scope: protocol.ClearChat}, nil},
+		{"all", protocol.ClearPayload{Scope: protocol.ClearAll}, nil},
+		{"progress", protocol.ClearPayload{Scope: protocol.ClearProgress}, []string{"a", "b", "c", "d", "e", "f"}},
+		{"last turn", protocol.ClearPayload{Scope: protocol.ClearLastTurn}, []string{"a", "b", "c", "d"}},
+		{"system", protocol.ClearPayload{Scope: protocol.ClearSystem}, []string{"a", "b", "d", "e"}},
+		{"by id", protocol.ClearPayload{Scope: protocol.ClearByID, Target: "m1"}, []string{"a", "c", "d", "e", "f"}},
+		{"by id without target", protocol.ClearPayload{Scope: protocol.ClearByID}, []string{"a", "b", "c", "d", "e", "f"}},
+		{"by unknown id", protocol.ClearPayload{Scope: protocol.ClearByID, Target: "m9"}, []string{"a", "b", "c", "d", "e", "f"}},
+		{"before id", protocol.ClearPayload{Scope: protocol.ClearBeforeID, Target: "m2"}, []string{"e", "f"}},
+		{"before id without target", protocol.ClearPayload{Scope: protocol.ClearBeforeID}, []string{"a", "b", "c", "d", "e", "f"}},
+		{"before unknown id", protocol.ClearPayload{Scope: protocol.ClearBeforeID, Target: "m9"}, []string{"a", "b", "c", "d", "e", "f"}},
+		{"unknown scope", protocol.ClearPayload{Scope: "everything"}, []string{"a", "b", "c", "d", "e", "f"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(nil, "test", "")
+			m.messages = slices.Clone(transcript)
+			m.handleClear(tt.payload)
+			if got := contents(m.messages); !slices.Equal(got, tt.want) {
+				t.Fatalf("left %q, want %q (status %q)", got, tt.want, m.statusMessage)
+			}
+
+			// Whatever was cleared can be put back
+			m.undoDelete()
+			if got, want := contents(m.messages), contents(transcript); len(tt.want) < len(transcript) && !slices.Equal(got, want) {
+				t.Errorf("after undo %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func contents(messages []Message) []string {
+	var out []string
+	for _, msg := range messages {
+		out = append(out, msg.Content)
+	}
+	return out
+}
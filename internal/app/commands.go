@@ -0,0 +1,285 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/export"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// maxCommandMatches is the most commands the menu shown while typing a
+// slash command lists.
+const maxCommandMatches = 6
+
+// slashCommand is a command typed in the input as /name args. Commands
+// without run are the agent's, sent to it in a command event.
+type slashCommand struct {
+	name        string
+	args        string // hint, e.g. "[name]"
+	description string
+	run         func(m *Model, args string) tea.Cmd
+}
+
+// commandMenu is the state of slash commands: those the agent registered,
+// and the menu of matches shown while the user types a command's name.
+type commandMenu struct {
+	agent     []protocol.AgentCommand
+	selected  int
+	dismissed string // the draft the menu was dismissed at with Esc
+}
+
+// builtinCommands returns the TUI's own commands.
+func builtinCommands() []slashCommand {
+	return []slashCommand{
+		{name: "clear", description: "Clear the transcript", run: (*Model).clearTranscript},
+		{name: "export", description: "Save the transcript as Markdown", run: (*Model).exportTranscript},
+		{name: "help", description: "List the commands", run: (*Model).showCommandHelp},
+		{name: "retry", args: "[replace | keep]", description: "Ask the agent to answer the last input again", run: (*Model).retryCommand},
+		{name: "schedule", args: "[when: prompt | clear]", description: "Send a prompt or tick on a schedule", run: (*Model).scheduleCommand},
+		{name: "tab", args: "[agent_id | number | close]", description: "Open a chat tab for an agent, or list the tabs", run: (*Model).tabCommand},
+		{name: "theme", args: "[name]", description: "Switch the color theme", run: (*Model).switchTheme},
+	}
+}
+
+// slashCommands returns the commands that can be typed: the TUI's own,
+// then the agent's in the order it registered them, leaving out any named
+// like one of the TUI's.
+func (m Model) slashCommands() []slashCommand {
+	commands := builtinCommands()
+	builtin := make(map[string]bool, len(commands))
+	for _, c := range commands {
+		builtin[c.name] = true
+	}
+	for _, c := range m.commands.agent {
+		if !builtin[c.Name] {
+			commands = append(commands, slashCommand{name: c.Name, args: c.Args, description: c.Description})
+		}
+	}
+	return commands
+}
+
+// handleCommands registers the agent's commands.
+func (m *Model) handleCommands(payload protocol.CommandsPayload) {
+	m.commands.agent = payload.Commands
+	m.commands.selected = 0
+}
+
+// runCommand runs the command line typed in the input, which starts with
+// a slash. Commands the agent registered are shown as the user's input and
+// sent to it.
+func (m *Model) runCommand(line string) tea.Cmd {
+	name, args, _ := strings.Cut(strings.TrimPrefix(line, "/"), " ")
+	args = strings.TrimSpace(args)
+	for _, c := range m.slashCommands() {
+		if c.name != name {
+			continue
+		}
+		if c.run != nil {
+			return c.run(m, args)
+		}
+		m.messages = append(m.messages, Message{Role: "user", Content: line, Timestamp: time.Now()})
+		m.refreshMessages()
+		m.viewport.GotoBottom()
+		if err := m.handler.SendCommand(name, args); err != nil {
+			m.setError("Failed to send command", err.Error(), true)
+			return nil
+		}
+		m.statusMessage = "Sent /" + name
+		return nil
+	}
+	m.statusMessage = fmt.Sprintf("Unknown command /%s; /help lists the commands", name)
+	return nil
+}
+
+// clearTranscript empties the transcript. ctrl+z puts it back.
+func (m *Model) clearTranscript(string) tea.Cmd {
+	if m.clearMessages(func(int, Message) bool { return true }) > 0 {
+		m.statusMessage = "Cleared the transcript" + theme.Glyphs.Separator + "ctrl+z to undo"
+	}
+	m.setFocus(PanelInput)
+	return nil
+}
+
+// exportTranscript saves the transcript as a Markdown file.
+func (m *Model) exportTranscript(string) tea.Cmd {
+	if len(m.messages) == 0 {
+		m.statusMessage = "Nothing to export"
+		return nil
+	}
+	path, err := export.SaveTranscript(exportDir, m.transcriptMarkdown())
+	if err != nil {
+		m.setError("Export failed", err.Error(), false)
+		return nil
+	}
+	m.statusMessage = fmt.Sprintf("Saved %s", path)
+	return nil
+}
+
+// transcriptMarkdown returns the transcript as Markdown, each message
+// under a heading naming who it is from.
+func (m Model) transcriptMarkdown() string {
+	var sb strings.Builder
+	for _, msg := range m.messages {
+		who := "Agent"
+		switch {
+		case msg.Role == "user":
+			who = "You"
+		case msg.Role == "system":
+			who = "Note"
+		case msg.Agent != "":
+			who = msg.Agent
+		}
+		text, _ := copyText(msg)
+		switch {
+		case msg.IsCode:
+			text = "```" + msg.Language + "\n" + strings.TrimRight(text, "\n") + "\n```"
+		case msg.Table != nil:
+			text = "```\n" + strings.TrimRight(text, "\n") + "\n```"
+		case msg.Dashboard != nil:
+			text = ansi.Strip(text)
+		}
+		fmt.Fprintf(&sb, "## %s\n\n%s\n\n", who, strings.TrimSpace(text))
+	}
+	return sb.String()
+}
+
+// showCommandHelp lists the commands in the transcript.
+func (m *Model) showCommandHelp(string) tea.Cmd {
+	var lines []string
+	for _, c := range m.slashCommands() {
+		lines = append(lines, strings.TrimSpace("/"+c.name+" "+c.args)+" — "+c.description)
+	}
+	m.addReport("Commands", strings.Join(lines, "\n"))
+	return nil
+}
+
+// switchTheme switches to the named theme, or lists the themes.
+func (m *Model) switchTheme(name string) tea.Cmd {
+	if name == "" {
+		names := make([]string, 0, len(theme.Available))
+		for id := range theme.Available {
+			if id == theme.Current.ID {
+				id += " (current)"
+			}
+			names = append(names, id)
+		}
+		sort.Strings(names)
+		m.addReport("Themes", strings.Join(names, "\n"))
+		return nil
+	}
+	if !theme.SetTheme(name) {
+		m.statusMessage = fmt.Sprintf("Unknown theme %s; /theme lists them", name)
+		return nil
+	}
+	m.redrawDashboards()
+	m.refreshMessages()
+	m.statusMessage = "Theme " + name
+	return nil
+}
+
+// commandMatches returns the commands whose name starts with what is
+// typed while the user types a command's name, or nil when the menu is
+// not shown.
+func (m Model) commandMatches() []slashCommand {
+	draft := m.input.Value()
+	if m.focused() != PanelInput || m.recall.back > 0 || draft == m.commands.dismissed ||
+		!strings.HasPrefix(draft, "/") || strings.ContainsAny(draft, " \n") {
+		return nil
+	}
+	var matches []slashCommand
+	for _, c := range m.slashCommands() {
+		if strings.HasPrefix(c.name, draft[1:]) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// updateCommandMenu handles the keys of the command menu, reporting
+// whether it took key: Up and Down move through the matches, Tab
+// completes the one picked, as does Enter unless a command is typed in
+// full, and Esc hides the menu.
+func (m *Model) updateCommandMenu(key string) bool {
+	matches := m.commandMatches()
+	if len(matches) == 0 {
+		return false
+	}
+	selected := min(m.commands.selected, len(matches)-1)
+	switch key {
+	case "up":
+		m.commands.selected = (selected + len(matches) - 1) % len(matches)
+	case "down":
+		m.commands.selected = (selected + 1) % len(matches)
+	case "enter":
+		if m.input.Value() == "/"+matches[selected].name {
+			return false
+		}
+		fallthrough
+	case "tab":
+		m.input.SetValue("/" + matches[selected].name + " ")
+		m.commands.selected = 0
+		m.fitInput()
+	case "esc":
+		m.commands.dismissed = m.input.Value()
+	default:
+		return false
+	}
+	return true
+}
+
+// placeCommandMenu draws the command menu, if it is shown, over the
+// bottom lines of view, just above the input.
+func (m Model) placeCommandMenu(view string) string {
+	matches := m.commandMatches()
+	if len(matches) == 0 {
+		return view
+	}
+	colors := theme.Current.Colors
+	selected := min(m.commands.selected, len(matches)-1)
+	first := max(0, selected-maxCommandMatches+1)
+	shown := matches[first:min(len(matches), first+maxCommandMatches)]
+
+	nameWidth := 0
+	for _, c := range shown {
+		nameWidth = max(nameWidth, ansi.StringWidth(strings.TrimSpace("/"+c.name+" "+c.args)))
+	}
+	width := min(m.width-4, 72)
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	rows := make([]string, len(shown))
+	for i, c := range shown {
+		name := strings.TrimSpace("/" + c.name + " " + c.args)
+		row := name + strings.Repeat(" ", nameWidth-ansi.StringWidth(name)) + "  " + muted.Render(c.description)
+		row = ansi.Truncate(row, width-4, theme.Glyphs.Ellipsis)
+		if first+i == selected {
+			row = lipgloss.NewStyle().Bold(true).Foreground(colors.Primary).Render(theme.Glyphs.Focus) + row
+		} else {
+			row = " " + row
+		}
+		rows[i] = row
+	}
+	box := lipgloss.NewStyle().
+		Border(theme.Glyphs.Border).
+		BorderForeground(colors.Primary).
+		Foreground(colors.Text).
+		Render(strings.Join(rows, "\n"))
+
+	lines := strings.Split(view, "\n")
+	boxLines := strings.Split(box, "\n")
+	if len(lines) < len(boxLines) {
+		return view
+	}
+	start := len(lines) - len(boxLines)
+	viewWidth := lipgloss.Width(view)
+	for i, boxLine := range boxLines {
+		lines[start+i] = boxLine + strings.Repeat(" ", max(0, viewWidth-lipgloss.Width(boxLine)))
+	}
+	return strings.Join(lines, "\n")
+}
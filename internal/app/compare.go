@@ -0,0 +1,63 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// compareVersions compares the artifact shown with its previous version.
+func (m *Model) compareVersions() {
+	a := m.artifacts[m.artifactTab]
+	if len(a.versions) == 0 {
+		m.statusMessage = "The agent has not revised " + a.title
+		return
+	}
+	before := a.versions[len(a.versions)-1]
+	m.openComparison(a.title+" (before)", before, a.title+" (after)", a.msg)
+}
+
+// markForComparison marks the artifact shown to compare with the next one
+// marked, comparing the two once there is one. Marking it again unmarks it.
+func (m *Model) markForComparison() {
+	a := m.artifacts[m.artifactTab]
+	switch m.compareMark {
+	case "":
+		if comparable(a.msg) {
+			m.compareMark = a.id
+			m.statusMessage = "Press c on another artifact to compare it with " + a.title
+		} else {
+			m.statusMessage = "Only code and markdown can be compared"
+		}
+		return
+	case a.id:
+		m.compareMark = ""
+		m.statusMessage = ""
+		return
+	}
+
+	i := slices.IndexFunc(m.artifacts, func(b artifact) bool { return b.id == m.compareMark })
+	m.compareMark = ""
+	if i >= 0 {
+		marked := m.artifacts[i]
+		m.openComparison(marked.title, marked.msg, a.title, a.msg)
+	}
+}
+
+// comparable reports whether an artifact's content can be compared line
+// by line: code and markdown can, tables cannot.
+func comparable(msg Message) bool {
+	return msg.Table == nil
+}
+
+// openComparison shows two artifact contents side by side.
+func (m *Model) openComparison(leftTitle string, left Message, rightTitle string, right Message) {
+	if !comparable(left) || !comparable(right) {
+		m.statusMessage = "Only code and markdown can be compared"
+		return
+	}
+	m.currentCompare = components.NewComparison(leftTitle, left.Content, rightTitle, right.Content)
+	m.currentCompare.SetSize(m.width, m.height-headerHeight-footerHeight)
+	m.statusMessage = ""
+	m.state = StateCompare
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// transcriptLayout records the line each message starts on in the
+// rendered transcript, so the view can scroll to it. It is shared by every
+// copy of the Model and updated by renderMessages.
+type transcriptLayout struct {
+	starts   []int
+	foldable []bool // messages long enough to fold; see fold.go
+	rendered string // the transcript as last rendered, for search.go
+}
+
+// handleControl carries out a scroll_to, focus_input, collapse or expand
+// message. Agents may only move the view when the user allows it, so an
+// agent cannot scroll away from what the user is reading.
+func (m *Model) handleControl(msg *protocol.Message) {
+	if !m.options.AgentControl {
+		m.statusMessage = fmt.Sprintf("Ignored the agent's %s (start with --agent-control to allow it)", msg.Type)
+		return
+	}
+
+	switch msg.Type {
+	case protocol.TypeScrollTo:
+		var payload protocol.ScrollToPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid scroll payload", err.Error(), false)
+			return
+		}
+		m.scrollTo(payload.Target)
+
+	case protocol.TypeFocusInput:
+		// Requests and prompts keep the keyboard until they are answered
+		if m.state == StateChat {
+			m.setFocus(PanelInput)
+		}
+
+	case protocol.TypeCollapse, protocol.TypeExpand:
+		var payload protocol.CollapsePayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid collapse payload", err.Error(), false)
+			return
+		}
+		idx := m.findMessage(payload.Target)
+		if idx < 0 {
+			m.statusMessage = fmt.Sprintf("No message %q to %s", payload.Target, msg.Type)
+			return
+		}
+		m.messages[idx].Collapsed = msg.Type == protocol.TypeCollapse
+		m.messages[idx].Summary = payload.Summary
+		m.refreshMessages()
+	}
+}
+
+// scrollTo scrolls the transcript to the top, the bottom or the start of
+// the message with the given ID.
+func (m *Model) scrollTo(target string) {
+	switch target {
+	case protocol.ScrollTop:
+		m.viewport.GotoTop()
+	case protocol.ScrollBottom:
+		m.viewport.GotoBottom()
+	default:
+		idx := m.findMessage(target)
+		if idx < 0 || idx >= len(m.layout.starts) {
+			m.statusMessage = fmt.Sprintf("No message %q to scroll to", target)
+			return
+		}
+		m.viewport.SetYOffset(m.layout.starts[idx])
+	}
+}
+
+// findMessage returns the index of the latest message with the given ID,
+// or -1 if there is none.
+func (m Model) findMessage(id string) int {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasCollapsed reports whether any message is collapsed.
+func (m Model) hasCollapsed() bool {
+	for _, msg := range m.messages {
+		if msg.Collapsed {
+			return true
+		}
+	}
+	return false
+}
+
+// expandAll expands every collapsed message.
+func (m *Model) expandAll() {
+	for i := range m.messages {
+		m.messages[i].Collapsed = false
+	}
+	m.refreshMessages()
+}
+
+// renderCollapsed renders a collapsed message as a single dim line.
+func renderCollapsed(msg Message, width int) string {
+	summary := msg.Summary
+	if summary == "" {
+		for _, line := range strings.Split(ansi.Strip(msg.Content), "\n") {
+			if summary = strings.TrimSpace(line); summary != "" {
+				break
+			}
+		}
+	}
+	dim := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted)
+	hint := "  (collapsed, ctrl+x to expand)"
+	line := theme.Glyphs.Fold + summary
+	if width > 0 {
+		line = ansi.Truncate(line, max(10, width-4-len(hint)), theme.Glyphs.Ellipsis)
+	}
+	return dim.Render(line) + dim.Faint(true).Render(hint)
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/clipboard"
+)
+
+// copiedMsg reports the outcome of copying a message to the clipboard.
+type copiedMsg struct {
+	what string
+	err  error
+}
+
+// copyText returns what copying msg puts on the clipboard, and what that
+// is, for the toast: the source of a reply rather than how it renders,
+// just the code of a code block and a table as plain text.
+func copyText(msg Message) (string, string) {
+	switch {
+	case msg.Blob != nil:
+		return msg.Blob.Path, "file path"
+	case msg.Table != nil:
+		return plainTable(msg.Table), "table"
+	case msg.IsCode:
+		return msg.Content, "code"
+	case msg.Role == "system":
+		return strings.TrimSpace(ansi.Strip(msg.Content)), "message"
+	}
+	return msg.Content, "message"
+}
+
+// copyCmd copies text to the clipboard off the UI goroutine, since a
+// clipboard command may take a moment.
+func copyCmd(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		return copiedMsg{what: what, err: clipboard.Copy(text, os.Stdout)}
+	}
+}
+
+// copied shows the outcome of copying.
+func (m *Model) copied(msg copiedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.setError("Cannot copy", msg.err.Error(), false)
+		return nil
+	}
+	return m.showToast("Copied " + msg.what)
+}
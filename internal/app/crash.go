@@ -0,0 +1,50 @@
+package app
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// sessionEnvironment returns the session's environment as it is now, and
+// false if it is not recorded.
+func (m Model) sessionEnvironment() (environment.Snapshot, bool) {
+	if m.options.Environment == nil {
+		return environment.Snapshot{}, false
+	}
+	env := *m.options.Environment
+	env.Theme = theme.Current.ID
+	return env, true
+}
+
+// noteAgent records how the agent identified itself in its hello, saving
+// it with the session.
+func (m *Model) noteAgent(agent *protocol.AgentInfo) {
+	if agent == nil || m.options.Environment == nil {
+		return
+	}
+	m.options.Environment.Agent = agent
+	if env, ok := m.sessionEnvironment(); ok && m.options.History != nil {
+		m.options.History.SetEnvironment(env)
+	}
+}
+
+// reportCrash writes a crash report with the environment to CrashDir for
+// a panic in progress, and lets the panic continue. Defer it directly:
+//
+//	defer m.reportCrash()
+func (m Model) reportCrash() {
+	if m.options.CrashDir == "" {
+		return
+	}
+	v := recover()
+	if v == nil {
+		return
+	}
+	env, _ := m.sessionEnvironment()
+	environment.WriteCrashReport(m.options.CrashDir, env, v, debug.Stack(), time.Now())
+	panic(v)
+}
@@ -0,0 +1,300 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// dashboard is a layout message in the transcript. When the layout has
+// rows they make a grid of regions, and messages naming one of them draw
+// into it rather than adding to the transcript; the message is then
+// redrawn in place.
+type dashboard struct {
+	protocol.LayoutPayload
+	feeds map[string]*regionFeed
+}
+
+// regionFeed is what a region shows: the latest message drawn into it,
+// or for text, everything streamed into it so far.
+type regionFeed struct {
+	typ     protocol.MessageType
+	payload json.RawMessage
+	text    string
+}
+
+// addLayout adds a layout message to the transcript: its components one
+// under another, then its grid if it has rows.
+func (m *Model) addLayout(payload protocol.LayoutPayload) {
+	d := &dashboard{LayoutPayload: payload, feeds: make(map[string]*regionFeed)}
+	m.messages = append(m.messages, Message{
+		Role:      "assistant",
+		Content:   d.draw(m.width - 4),
+		Timestamp: time.Now(),
+		Dashboard: d,
+	})
+	m.showNewMessage()
+}
+
+// feedRegion draws msg into the region it names on the latest dashboard
+// that has it, reporting false if there is none or msg is of a type
+// regions do not show.
+func (m *Model) feedRegion(msg *protocol.Message) bool {
+	idx := m.findDashboard(msg.Region)
+	if idx < 0 {
+		return false
+	}
+	d := m.messages[idx].Dashboard
+	feed := d.feeds[msg.Region]
+
+	switch msg.Type {
+	case protocol.TypeText:
+		var payload protocol.TextPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid text payload", err.Error(), false)
+			return true
+		}
+		if feed == nil || feed.typ != protocol.TypeText {
+			feed = &regionFeed{typ: protocol.TypeText}
+		}
+		feed.text += payload.Content
+	case protocol.TypeMarkdown:
+		var payload protocol.MarkdownPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			m.setError("Invalid markdown payload", err.Error(), false)
+			return true
+		}
+		if !payload.Append || feed == nil || feed.typ != protocol.TypeMarkdown {
+			feed = &regionFeed{typ: protocol.TypeMarkdown}
+		}
+		feed.text += payload.Content
+	case protocol.TypeTable, protocol.TypeCode, protocol.TypeProgress, protocol.TypeAlert:
+		feed = &regionFeed{typ: msg.Type, payload: msg.Payload}
+	case protocol.TypeClear:
+		feed = nil
+	default:
+		return false
+	}
+
+	if feed == nil {
+		delete(d.feeds, msg.Region)
+	} else {
+		d.feeds[msg.Region] = feed
+	}
+	m.messages[idx].Content = d.draw(m.width - 4)
+	m.refreshMessages()
+	return true
+}
+
+// findDashboard returns the index of the latest transcript message showing
+// a dashboard with the given region, or -1 if there is none.
+func (m Model) findDashboard(region string) int {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		d := m.messages[i].Dashboard
+		if d == nil {
+			continue
+		}
+		for _, row := range d.Rows {
+			for _, r := range row {
+				if r.ID == region {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// redrawDashboards draws the layout messages again at the current width.
+func (m *Model) redrawDashboards() {
+	for i, msg := range m.messages {
+		if msg.Dashboard != nil {
+			m.messages[i].Content = msg.Dashboard.draw(m.width - 4)
+		}
+	}
+}
+
+// draw draws the layout width columns wide: its title and description,
+// its components and its grid.
+func (d *dashboard) draw(width int) string {
+	var sb strings.Builder
+	if d.Title != "" {
+		titleStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Current.Colors.Primary).
+			MarginBottom(1)
+		sb.WriteString(titleStyle.Render(d.Title) + "\n")
+	}
+	if d.Description != "" {
+		descStyle := lipgloss.NewStyle().
+			Foreground(theme.Current.Colors.TextMuted).
+			MarginBottom(1)
+		sb.WriteString(descStyle.Render(d.Description) + "\n")
+	}
+
+	// Components are drawn one under another
+	var parts []string
+	for _, component := range d.Components {
+		data, err := json.Marshal(component.Payload)
+		if err != nil {
+			continue // Skip invalid components
+		}
+		if view := renderComponent(protocol.MessageType(component.Type), data, width); view != "" {
+			parts = append(parts, view)
+		}
+	}
+	if len(d.Rows) > 0 {
+		parts = append(parts, d.drawGrid(width))
+	}
+	sb.WriteString(strings.Join(parts, "\n"))
+	return sb.String()
+}
+
+// drawGrid draws the dashboard's regions width columns wide. Each row
+// splits the width between its regions by span, and its regions are as
+// tall as the tallest of them.
+func (d *dashboard) drawGrid(width int) string {
+	colors := theme.Current.Colors
+	frame := theme.Current.Styles.Border.Padding(0, 1)
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(colors.Primary)
+	waiting := lipgloss.NewStyle().Foreground(colors.TextMuted).Italic(true)
+	// Border and padding take two columns on each side
+	const chrome = 4
+
+	rows := make([]string, 0, len(d.Rows))
+	for _, row := range d.Rows {
+		total := 0
+		for _, r := range row {
+			total += max(r.Span, 1)
+		}
+		cells := make([][]string, len(row))
+		widths := make([]int, len(row))
+		height := 0
+		left := width
+		for i, r := range row {
+			w := width * max(r.Span, 1) / total
+			if i == len(row)-1 {
+				w = left
+			}
+			left -= w
+			widths[i] = max(w, chrome+1)
+
+			inner := widths[i] - chrome
+			var lines []string
+			if feed := d.feeds[r.ID]; feed != nil {
+				lines = strings.Split(feed.draw(inner), "\n")
+			} else {
+				lines = []string{waiting.Render("waiting…")}
+			}
+			if r.Height > 0 && len(lines) > r.Height {
+				lines = lines[len(lines)-r.Height:]
+			}
+			if r.Title != "" {
+				lines = append([]string{titleStyle.Render(ansi.Truncate(r.Title, inner, theme.Glyphs.Ellipsis))}, lines...)
+			}
+			cells[i] = lines
+			height = max(height, len(lines))
+		}
+
+		drawn := make([]string, len(row))
+		for i, lines := range cells {
+			drawn[i] = frame.Width(widths[i] - 2).Height(height).Render(strings.Join(lines, "\n"))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, drawn...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// draw draws what the region shows width columns wide.
+func (f *regionFeed) draw(width int) string {
+	switch f.typ {
+	case protocol.TypeText:
+		return lipgloss.NewStyle().Width(width).Render(strings.TrimRight(f.text, "\n"))
+	case protocol.TypeMarkdown:
+		view := views.NewMarkdownView()
+		view.SetContent(f.text)
+		view.SetWidth(width)
+		return strings.Trim(view.View(), "\n")
+	}
+	return renderComponent(f.typ, f.payload, width)
+}
+
+// renderComponent draws a table, code block, progress bar or alert from
+// its payload, or returns "" for other types and payloads that do not
+// parse.
+func renderComponent(typ protocol.MessageType, payload json.RawMessage, width int) string {
+	msg := &protocol.Message{Type: typ, Payload: payload}
+	switch typ {
+	case protocol.TypeTable:
+		var tablePayload protocol.TablePayload
+		if err := msg.ParsePayload(&tablePayload); err == nil {
+			// Convert columns to strings
+			cols := make([]string, len(tablePayload.Columns))
+			for j, c := range tablePayload.Columns {
+				if s, ok := c.(string); ok {
+					cols[j] = s
+				} else {
+					cols[j] = fmt.Sprintf("%v", c)
+				}
+			}
+			tableView := views.NewTableView()
+			tableView.SetTitle(tablePayload.Title)
+			tableView.SetColumns(cols)
+			tableView.SetRows(tablePayload.Rows)
+			tableView.SetFooter(tablePayload.Footer)
+			tableView.SetWidth(width)
+			return tableView.View()
+		}
+	case protocol.TypeCode:
+		var codePayload protocol.CodePayload
+		if err := msg.ParsePayload(&codePayload); err == nil {
+			codeView := views.NewCodeView()
+			codeView.SetCode(codePayload.Code)
+			codeView.SetLanguage(codePayload.Language)
+			codeView.SetTitle(codePayload.Title)
+			codeView.SetWidth(width)
+			return codeView.View()
+		}
+	case protocol.TypeProgress:
+		var progressPayload protocol.ProgressPayload
+		if err := msg.ParsePayload(&progressPayload); err == nil {
+			progressView := views.NewProgressView()
+			progressView.SetWidth(width)
+			progressView.SetMessage(progressPayload.Message)
+			if progressPayload.Percent != nil {
+				progressView.SetPercent(*progressPayload.Percent)
+			}
+			if progressPayload.Steps != nil {
+				steps := make([]views.ProgressStep, len(progressPayload.Steps))
+				for j, s := range progressPayload.Steps {
+					steps[j] = views.ProgressStep{
+						Label:  s.Label,
+						Status: s.Status,
+						Detail: s.Detail,
+					}
+				}
+				progressView.SetSteps(steps)
+			}
+			return progressView.View()
+		}
+	case protocol.TypeAlert:
+		var alertPayload protocol.AlertPayload
+		if err := msg.ParsePayload(&alertPayload); err == nil {
+			alertView := views.NewAlertView()
+			alertView.SetMessage(alertPayload.Message)
+			alertView.SetTitle(alertPayload.Title)
+			alertView.SetSeverity(alertPayload.Severity)
+			alertView.SetWidth(width)
+			return alertView.View()
+		}
+	}
+	return ""
+}
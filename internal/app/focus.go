@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// Panels of the chat view that can take keyboard focus.
+const (
+	PanelInput      = "input"
+	PanelTranscript = "transcript"
+	PanelArtifacts  = "artifacts"
+	PanelSidebar    = "sidebar"
+)
+
+// DefaultTabOrder is the order Tab moves focus through the chat view.
+var DefaultTabOrder = []string{PanelInput, PanelTranscript, PanelArtifacts, PanelSidebar}
+
+// panel describes a part of the chat view that Tab can focus. New panels,
+// such as sidebars, are added to panels and to DefaultTabOrder.
+type panel struct {
+	label string // shown in the status bar while focused
+	hint  string // the keys the panel takes
+	// available reports whether the panel can take focus now; nil if it
+	// always can
+	available func(m Model) bool
+}
+
+var panels = map[string]panel{
+	PanelInput: {label: "Input"},
+	PanelTranscript: {
+		label: "Transcript",
+		hint:  "arrows, PgUp and PgDn to scroll, o to expand or open long output, v to select",
+		available: func(m Model) bool {
+			return len(m.messages) > 0
+		},
+	},
+	PanelArtifacts: {
+		label: "Artifacts",
+		hint:  "arrows to browse, c or v to compare, o to open, x to close",
+		available: func(m Model) bool {
+			return m.artifactsWidth() > 0
+		},
+	},
+	PanelSidebar: {
+		label: "Sidebar",
+		hint:  "arrows to move, Enter to open, ctrl+b to hide",
+		available: func(m Model) bool {
+			return m.sidebarSpace() > 0
+		},
+	},
+}
+
+// ParseTabOrder parses a comma-separated list of panels, such as
+// "transcript,input". The input must be listed; panels left out are
+// skipped by Tab.
+func ParseTabOrder(s string) ([]string, error) {
+	var order []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := panels[name]; !ok {
+			return nil, fmt.Errorf("unknown panel %q (want %s)", name, strings.Join(DefaultTabOrder, ", "))
+		}
+		if slices.Contains(order, name) {
+			return nil, fmt.Errorf("panel %q listed twice", name)
+		}
+		order = append(order, name)
+	}
+	if !slices.Contains(order, PanelInput) {
+		return nil, fmt.Errorf("the tab order must include %q", PanelInput)
+	}
+	return order, nil
+}
+
+// focused returns the panel that keys go to.
+func (m Model) focused() string {
+	if m.focus == "" {
+		return PanelInput
+	}
+	return m.focus
+}
+
+// tabOrder returns the configured tab order.
+func (m Model) tabOrder() []string {
+	if len(m.options.TabOrder) == 0 {
+		return DefaultTabOrder
+	}
+	return m.options.TabOrder
+}
+
+// cycleFocus moves focus to the next available panel in the tab order, or
+// the previous one if step is -1.
+func (m *Model) cycleFocus(step int) {
+	order := m.tabOrder()
+	i := slices.Index(order, m.focused())
+	for range order {
+		i = (i + step + len(order)) % len(order)
+		p := panels[order[i]]
+		if p.available == nil || p.available(*m) {
+			m.setFocus(order[i])
+			return
+		}
+	}
+}
+
+// setFocus gives the keyboard to a panel. Only the focused input shows a
+// cursor, and messages are only selected in the transcript.
+func (m *Model) setFocus(name string) {
+	if name != PanelTranscript {
+		m.stopSelecting()
+	}
+	m.focus = name
+	if name == PanelInput {
+		m.input.Focus()
+	} else {
+		m.input.Blur()
+	}
+}
+
+// updateFocusedPanel passes a key to the focused panel other than the
+// input.
+func (m *Model) updateFocusedPanel(msg tea.KeyMsg) tea.Cmd {
+	switch m.focused() {
+	case PanelTranscript:
+		if m.selecting {
+			return m.updateSelection(msg)
+		}
+		if msg.String() == "v" {
+			m.startSelecting()
+			return nil
+		}
+		if msg.String() == "o" {
+			if i := m.foldedInView(); i >= 0 {
+				m.toggleFold(i)
+			} else if i := m.truncatedInView(); i >= 0 {
+				m.openFullView(m.messages[i])
+			} else {
+				m.statusMessage = "No folded or truncated output to open"
+			}
+			return nil
+		}
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return cmd
+	case PanelArtifacts:
+		return m.updateArtifactsPanel(msg)
+	case PanelSidebar:
+		return m.updateSidebar(msg)
+	}
+	return nil
+}
+
+// renderFocus shows which panel other than the input has focus, at the
+// start of the status bar.
+func (m Model) renderFocus() string {
+	name := m.focused()
+	if name == PanelInput {
+		return ""
+	}
+	p := panels[name]
+	s := theme.Current.Styles.Focused.Render(theme.Glyphs.Focus + p.label)
+	hint := "Tab to move on"
+	if p.hint != "" {
+		hint = p.hint + theme.Glyphs.Separator + hint
+	}
+	return s + " " + lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Render(hint) + "  "
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// DefaultFoldLines is how many lines a reply may take before it is folded,
+// unless configured.
+const DefaultFoldLines = 40
+
+// foldPreviewLines is how many lines of a folded reply are shown.
+const foldPreviewLines = 12
+
+// foldable reports whether msg is a finished reply long enough to fold,
+// given its rendered content.
+func (m Model) foldable(msg Message, content string) bool {
+	limit := m.options.FoldLines
+	if limit <= 0 || msg.Role != "assistant" || msg.Open {
+		return false
+	}
+	if msg.IsCode {
+		return strings.Count(strings.TrimSuffix(msg.Content, "\n"), "\n") >= limit
+	}
+	return strings.Count(content, "\n") >= limit
+}
+
+// renderFolded renders a reply folded to its first lines, with a note
+// saying how many more there are. Code is cut before it is drawn, so its
+// box stays whole; markdown is cut as drawn.
+func (m Model) renderFolded(msg Message, content string) string {
+	preview := min(foldPreviewLines, m.options.FoldLines)
+	var hidden int
+	if msg.IsCode {
+		lines := strings.Split(strings.TrimSuffix(msg.Content, "\n"), "\n")
+		hidden = len(lines) - preview
+		msg.Content = strings.Join(lines[:preview], "\n")
+		content = m.renderAssistantMessage(msg)
+	} else {
+		lines := strings.Split(content, "\n")
+		hidden = len(lines) - preview
+		content = strings.Join(lines[:preview], "\n")
+	}
+	return content + "\n" + renderFoldNote(hidden)
+}
+
+// renderFoldNote renders the line under a folded reply.
+func renderFoldNote(hidden int) string {
+	note := fmt.Sprintf("%s %d more lines (press o to expand)", theme.Glyphs.Ellipsis, hidden)
+	if hidden == 1 {
+		note = theme.Glyphs.Ellipsis + " 1 more line (press o to expand)"
+	}
+	return lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Italic(true).Render(note)
+}
+
+// toggleFold folds message i if the user expanded it, or expands it.
+func (m *Model) toggleFold(i int) {
+	m.messages[i].Unfolded = !m.messages[i].Unfolded
+	m.refreshMessages()
+}
+
+// foldedInView returns the index of the folded reply nearest the bottom of
+// the transcript as scrolled, or -1 if there is none.
+func (m Model) foldedInView() int {
+	bottom := m.viewport.YOffset + m.viewport.Height
+	found := -1
+	for i, msg := range m.messages {
+		if i < len(m.layout.starts) && m.layout.starts[i] >= bottom && found >= 0 {
+			break
+		}
+		if i < len(m.layout.foldable) && m.layout.foldable[i] && !msg.Unfolded {
+			found = i
+		}
+	}
+	return found
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// renderMap renders a map payload at the current width.
+func (m Model) renderMap(p *protocol.MapPayload) (string, error) {
+	view := views.NewMapView()
+	if err := view.SetRegion(p.Region); err != nil {
+		return "", err
+	}
+	points := make([]views.MapPoint, len(p.Points))
+	for i, pt := range p.Points {
+		points[i] = views.MapPoint{Label: pt.Label, Lat: pt.Lat, Lon: pt.Lon}
+	}
+	view.SetPoints(points)
+	if p.Center != nil {
+		view.SetZoom(p.Zoom, &views.MapPoint{Lat: p.Center.Lat, Lon: p.Center.Lon})
+	} else {
+		view.SetZoom(p.Zoom, nil)
+	}
+	view.SetTitle(p.Title)
+	view.SetWidth(m.width)
+	return view.View(), nil
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// heartbeat tracks whether the agent still answers pings. Pings are sent
+// as requests that time out when unanswered for too long.
+type heartbeat struct {
+	seq      int       // the last ping sent
+	first    int       // the first ping sent to the current agent
+	answered int       // the last ping answered
+	waited   int       // the last ping sent before the user chose to wait
+	alive    bool      // the agent has answered a ping since it connected
+	heard    time.Time // when the agent last answered, or pinging started
+
+	// The prompt shown while the agent is not responding, and the state to
+	// go back to when it is dismissed
+	prompt *components.SelectMenu
+	resume State
+}
+
+// Choices offered while the agent is not responding.
+const (
+	unresponsiveWait    = "wait"
+	unresponsiveRestart = "restart"
+	unresponsiveQuit    = "quit"
+)
+
+// heartbeatTickMsg fires on every heartbeat interval.
+type heartbeatTickMsg struct{}
+
+// heartbeatTick schedules the next ping.
+func (m Model) heartbeatTick() tea.Cmd {
+	if m.options.HeartbeatInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.options.HeartbeatInterval, func(time.Time) tea.Msg {
+		return heartbeatTickMsg{}
+	})
+}
+
+// unresponsiveAfter is how long a ping may go unanswered before the agent
+// is reported as not responding.
+func (m Model) unresponsiveAfter() time.Duration {
+	return 2 * m.options.HeartbeatInterval
+}
+
+// pingResultMsg reports whether ping seq was answered in time.
+type pingResultMsg struct {
+	seq      int
+	answered bool
+}
+
+// sendPing pings the agent, reporting the outcome as a pingResultMsg.
+func (m *Model) sendPing() tea.Cmd {
+	m.heartbeat.seq++
+	seq := m.heartbeat.seq
+	if m.heartbeat.heard.IsZero() {
+		m.heartbeat.heard = time.Now()
+	}
+	pong, _ := m.handler.Ping(seq, m.unresponsiveAfter())
+	return func() tea.Msg {
+		_, answered := <-pong
+		return pingResultMsg{seq: seq, answered: answered}
+	}
+}
+
+// handlePingResult asks the user what to do when a ping times out. Agents
+// that have never answered a ping are assumed not to support them and are
+// never reported, and neither are pings to a previous agent or sent before
+// the user last chose to wait.
+func (m *Model) handlePingResult(r pingResultMsg) {
+	if r.answered {
+		m.handlePong(r.seq)
+		return
+	}
+	if !m.heartbeat.alive || r.seq < m.heartbeat.first ||
+		r.seq <= m.heartbeat.answered || r.seq <= m.heartbeat.waited {
+		return
+	}
+	silent := time.Since(m.heartbeat.heard)
+	m.statusMessage = fmt.Sprintf("Agent not responding for %s", silent.Round(time.Second))
+	if m.state != StateUnresponsive {
+		m.openUnresponsivePrompt()
+	}
+}
+
+// handlePong records that the agent answered ping seq, dismissing the
+// prompt if it was showing.
+func (m *Model) handlePong(seq int) {
+	m.heartbeat.alive = true
+	m.heartbeat.answered = max(m.heartbeat.answered, seq)
+	m.heartbeat.heard = time.Now()
+	if m.state == StateUnresponsive {
+		m.closeUnresponsivePrompt()
+		m.statusMessage = "Agent is responding again"
+	}
+}
+
+// resetHeartbeat forgets the previous agent when one connects or leaves.
+func (m *Model) resetHeartbeat() {
+	if m.state == StateUnresponsive {
+		m.closeUnresponsivePrompt()
+	}
+	m.heartbeat = heartbeat{seq: m.heartbeat.seq, first: m.heartbeat.seq + 1}
+}
+
+func (m *Model) openUnresponsivePrompt() {
+	options := []protocol.SelectOption{
+		{Label: "Wait", Value: unresponsiveWait, Description: "Keep waiting for the agent"},
+	}
+	if m.handler.ListenAddr() != "" {
+		options = append(options, protocol.SelectOption{
+			Label:       "Restart",
+			Value:       unresponsiveRestart,
+			Description: "Disconnect the agent so a restarted one can connect",
+		})
+	}
+	options = append(options, protocol.SelectOption{
+		Label: "Quit", Value: unresponsiveQuit, Description: "Close the TUI",
+	})
+
+	m.heartbeat.prompt = components.NewSelectMenu(&protocol.SelectPayload{
+		Label:   "Agent not responding",
+		Options: options,
+	})
+	m.heartbeat.prompt.SetWidth(m.width)
+	m.heartbeat.resume = m.state
+	m.state = StateUnresponsive
+}
+
+func (m *Model) closeUnresponsivePrompt() {
+	m.state = m.heartbeat.resume
+	m.heartbeat.prompt = nil
+}
+
+// updateUnresponsivePrompt passes msg to the prompt and carries out the
+// user's choice. Esc keeps waiting.
+func (m *Model) updateUnresponsivePrompt(msg tea.Msg) tea.Cmd {
+	if m.heartbeat.prompt == nil {
+		return nil
+	}
+	cmd := m.heartbeat.prompt.Update(msg)
+	if !m.heartbeat.prompt.HasResponded() {
+		return cmd
+	}
+
+	choice := m.heartbeat.prompt.GetSelected()
+	m.closeUnresponsivePrompt()
+	switch choice {
+	case unresponsiveRestart:
+		m.handler.DropAgent()
+		m.isStreaming = false
+		m.statusMessage = "Disconnected the agent, waiting for it to reconnect"
+	case unresponsiveQuit:
+		m.quitting = true
+		m.handler.SendQuit()
+		return tea.Quit
+	default:
+		// Ask again if the agent stays silent for as long again
+		m.heartbeat.waited = m.heartbeat.seq
+		m.statusMessage = "Waiting for the agent"
+	}
+	return cmd
+}
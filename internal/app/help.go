@@ -0,0 +1,248 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// chatKeys are the keys of the chat with the input focused.
+var chatKeys = []components.Binding{
+	{Keys: "Enter", Does: "send the input"},
+	{Keys: "Alt+Enter, ctrl+j", Does: "new line"},
+	{Keys: "Up, Down", Does: "recall sent prompts"},
+	{Keys: "/", Does: "slash commands; /help lists them"},
+	{Keys: "Tab, Shift+Tab", Does: "move between panels, or accept a suggestion"},
+	{Keys: "Esc", Does: "cancel the reply, or select messages"},
+	{Keys: "ctrl+f", Does: "search the transcript"},
+	{Keys: "ctrl+End", Does: "jump to new messages below"},
+	{Keys: "F2", Does: "let the terminal select text; F2 again to go back"},
+	{Keys: "ctrl+x", Does: "actions for the latest message"},
+	{Keys: "ctrl+o", Does: "attach a file"},
+	{Keys: "ctrl+s", Does: "reopen a past session"},
+	{Keys: "ctrl+b", Does: "show or hide the sidebar"},
+	{Keys: "ctrl+r", Does: "retry the last exchange or error, or restart the agent"},
+	{Keys: "ctrl+l", Does: "clear the transcript"},
+	{Keys: "ctrl+z", Does: "put back what was deleted or cleared"},
+	{Keys: "ctrl+PgDn, ctrl+PgUp", Does: "next or previous chat tab"},
+	{Keys: "Alt+1…Alt+9", Does: "open a chat tab; /tab opens one"},
+	{Keys: "ctrl+d", Does: "debug mode"},
+}
+
+// selectionKeys are the keys of message selection.
+var selectionKeys = []components.Binding{
+	{Keys: "Up, Down, j, k", Does: "select another message"},
+	{Keys: "y", Does: "copy"},
+	{Keys: "c", Does: "collapse or expand"},
+	{Keys: "o", Does: "fold, expand or open long output"},
+	{Keys: "r", Does: "ask again"},
+	{Keys: "d, u", Does: "delete, undo"},
+	{Keys: "p", Does: "pin to the top, or unpin"},
+	{Keys: "l", Does: "open links"},
+	{Keys: "Enter", Does: "more actions"},
+	{Keys: "Esc, v", Does: "stop selecting"},
+}
+
+// transcriptKeys are the keys of the transcript panel.
+var transcriptKeys = []components.Binding{
+	{Keys: "Up, Down, PgUp, PgDn", Does: "scroll"},
+	{Keys: "v", Does: "select messages"},
+	{Keys: "o", Does: "expand or open long output"},
+	{Keys: "Tab", Does: "move to the next panel"},
+}
+
+// sidebarKeys are the keys of the sidebar.
+var sidebarKeys = []components.Binding{
+	{Keys: "Up, Down, j, k", Does: "move between entries"},
+	{Keys: "Enter", Does: "reopen the session, describe the tool call or show the pinned artifact"},
+	{Keys: "Esc", Does: "back to the input"},
+	{Keys: "ctrl+b", Does: "hide the sidebar"},
+}
+
+// artifactKeys are the keys of the artifacts panel.
+var artifactKeys = []components.Binding{
+	{Keys: "Left, Right", Does: "switch artifacts"},
+	{Keys: "Up, Down", Does: "scroll"},
+	{Keys: "c, v", Does: "compare two artifacts"},
+	{Keys: "o", Does: "open in full"},
+	{Keys: "x", Does: "close the panel"},
+}
+
+// promptKeys are the keymaps of the agent's prompts and of the TUI's
+// viewers, which their hint bars show too.
+var promptKeys = map[State]func() []components.Binding{
+	StateForm:         components.FormKeys,
+	StateConfirm:      components.ConfirmKeys,
+	StateSelect:       components.SelectKeys,
+	StateSecret:       components.SecretKeys,
+	StateAutocomplete: components.AutocompleteKeys,
+	StateQuestion:     components.QuestionKeys,
+	StateDiff:         components.DiffReviewKeys,
+	StateCompare:      components.ComparisonKeys,
+	StateFullView:     components.PagerKeys,
+}
+
+// updateHelp handles a key while the help overlay is open, or F1, which
+// opens it: Esc, q, ? and F1 close it and other keys do nothing, so
+// nothing happens behind it.
+func (m Model) updateHelp(msg tea.KeyMsg) Model {
+	if !m.helpOpen {
+		m.helpOpen = true
+		return m
+	}
+	switch msg.String() {
+	case "esc", "q", "?", "f1":
+		m.helpOpen = false
+	}
+	return m
+}
+
+// renderHelp renders the help overlay: the keys of what is on screen,
+// what is going on, and the connection to the agent.
+func (m Model) renderHelp() string {
+	colors := theme.Current.Colors
+	heading := lipgloss.NewStyle().Bold(true).Foreground(colors.Primary)
+	keyStyle := lipgloss.NewStyle().Foreground(colors.Text)
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+
+	title, keys := m.helpKeys()
+	keys = append(keys, components.Binding{Keys: "F1, or ? outside the input", Does: "this help"}, components.Binding{Keys: "ctrl+c", Does: "quit"})
+	keyWidth := 0
+	for _, k := range keys {
+		keyWidth = max(keyWidth, lipgloss.Width(k.Keys))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(heading.Render(title) + "\n")
+	for _, k := range keys {
+		sb.WriteString(keyStyle.Render(k.Keys+strings.Repeat(" ", keyWidth-lipgloss.Width(k.Keys))) + "  " + muted.Render(k.Does) + "\n")
+	}
+	if now := m.helpActivity(); len(now) > 0 {
+		sb.WriteString("\n" + heading.Render("Now") + "\n")
+		for _, line := range now {
+			sb.WriteString(muted.Render(line) + "\n")
+		}
+	}
+	sb.WriteString("\n" + heading.Render("Connection") + "\n")
+	for _, line := range m.helpConnection() {
+		sb.WriteString(muted.Render(line) + "\n")
+	}
+	sb.WriteString("\n" + muted.Italic(true).Render("Esc to close"))
+
+	return lipgloss.NewStyle().
+		Border(theme.Glyphs.Border).
+		BorderForeground(colors.Primary).
+		Padding(0, 2).
+		MaxWidth(m.width).
+		Render(sb.String())
+}
+
+// helpKeys returns the name of what has the keyboard and its keys.
+func (m Model) helpKeys() (string, []components.Binding) {
+	if keys, ok := promptKeys[m.state]; ok {
+		if isRequestState(m.state) {
+			return "Keys for this prompt", keys()
+		}
+		return "Keys", keys()
+	}
+	switch m.state {
+	case StateChat:
+	case StateMenu, StateSessions, StateUnresponsive, StateDisconnected, StateAgentExited, StateStartupTimeout:
+		return "Keys for this menu", components.SelectKeys()
+	default:
+		return "Keys", nil
+	}
+	switch {
+	case m.search.open:
+		return "Search keys", []components.Binding{
+			{Keys: "Enter", Does: "stop typing, then next match"},
+			{Keys: "Up, Down", Does: "previous or next match"},
+			{Keys: "ctrl+f", Does: "search again"},
+			{Keys: "Esc", Does: "close the search"},
+		}
+	case m.selecting:
+		return "Selection keys", selectionKeys
+	case m.focused() == PanelTranscript:
+		return "Transcript keys", transcriptKeys
+	case m.focused() == PanelArtifacts:
+		return "Artifact keys", artifactKeys
+	case m.focused() == PanelSidebar:
+		return "Sidebar keys", sidebarKeys
+	}
+	return "Chat keys", chatKeys
+}
+
+// helpActivity describes what is going on: the agent replying or asking,
+// input waiting to be sent and schedules.
+func (m Model) helpActivity() []string {
+	var lines []string
+	if m.startup.waiting {
+		lines = append(lines, "Waiting for the agent to start; the input opens when it is ready")
+	}
+	if m.isStreaming {
+		lines = append(lines, "The agent is replying; Esc cancels it")
+	}
+	if isRequestState(m.state) {
+		line := "The agent is waiting for an answer"
+		if m.request.title != "" {
+			line += ": " + m.request.title
+		}
+		if !m.request.deadline.IsZero() {
+			line += fmt.Sprintf(" (%s left)", time.Until(m.request.deadline).Round(time.Second))
+		}
+		lines = append(lines, line)
+	}
+	if n := m.handler.Backlog(); n > 0 {
+		lines = append(lines, fmt.Sprintf("%d messages queued until the agent reconnects", n))
+	}
+	if n := len(m.schedules); n > 0 {
+		lines = append(lines, fmt.Sprintf("%d schedules; /schedule lists them", n))
+	}
+	if n := len(m.commands.agent); n > 0 {
+		lines = append(lines, fmt.Sprintf("%d commands from the agent; /help lists them", n))
+	}
+	if m.options.ReadOnly {
+		lines = append(lines, "This session is read-only")
+	}
+	return lines
+}
+
+// helpConnection describes the connection to the agent.
+func (m Model) helpConnection() []string {
+	var lines []string
+	listen := m.handler.ListenAddr()
+	switch {
+	case m.options.ReadOnly:
+		lines = append(lines, "No agent")
+	case listen == "":
+		lines = append(lines, "Agent on standard input and output")
+	case m.agentAddr == "" && listen == protocol.SpawnAddr:
+		lines = append(lines, "Agent stopped")
+	case m.agentAddr == "":
+		lines = append(lines, "Waiting for an agent on "+listen)
+	default:
+		lines = append(lines, "Agent at "+m.agentAddr)
+	}
+	if env := m.options.Environment; env != nil && env.Agent != nil && env.Agent.Name != "" {
+		lines = append(lines, strings.TrimSpace(env.Agent.Name+" "+env.Agent.Version))
+	}
+	if m.options.HeartbeatInterval > 0 && !m.options.ReadOnly {
+		if m.heartbeat.alive {
+			lines = append(lines, "Answered a ping "+time.Since(m.heartbeat.heard).Round(time.Second).String()+" ago")
+		} else {
+			lines = append(lines, "Has not answered a ping yet")
+		}
+	}
+	if queue := m.handler.QueueStats(); queue.Window > 0 {
+		lines = append(lines, fmt.Sprintf("Flow control: %d of %d credits left", queue.Credits, queue.Window))
+	}
+	lines = append(lines, fmt.Sprintf("Protocol version %d", protocol.ProtocolVersion))
+	return lines
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// maxSessionTitle is how many characters of a session's first input the
+// session picker shows.
+const maxSessionTitle = 60
+
+// openSessionPicker lists the sessions in the history store, most recent
+// first, for the user to reopen one.
+func (m *Model) openSessionPicker() {
+	if m.options.History == nil {
+		m.statusMessage = "Session history is off"
+		return
+	}
+	if m.isStreaming {
+		m.statusMessage = "Wait for the agent to finish before reopening a session"
+		return
+	}
+	sessions, err := m.options.History.Store().List()
+	if err != nil {
+		m.setError("Cannot list sessions", err.Error(), false)
+		return
+	}
+	current := m.options.History.Name()
+	var options []protocol.SelectOption
+	for _, s := range sessions {
+		title := s.Title
+		if title == "" {
+			title = "(nothing sent)"
+		}
+		if runes := []rune(title); len(runes) > maxSessionTitle {
+			title = string(runes[:maxSessionTitle-1]) + theme.Glyphs.Ellipsis
+		}
+		description := s.Modified.Format("Mon Jan 2") + " " + m.options.Clock.Time(s.Modified) +
+			theme.Glyphs.Separator + formatBytes(s.Size)
+		if s.Name == current {
+			description += theme.Glyphs.Separator + "current"
+		}
+		options = append(options, protocol.SelectOption{Label: title, Value: s.Name, Description: description})
+	}
+	if len(options) == 0 {
+		m.statusMessage = "No saved sessions yet"
+		return
+	}
+
+	m.sessionPicker = components.NewSelectMenu(&protocol.SelectPayload{
+		Label:   "Reopen a session",
+		Options: options,
+	})
+	m.sessionPicker.SetWidth(m.width)
+	m.state = StateSessions
+}
+
+// reopenSession replaces the transcript with that of session name,
+// rendered afresh, and records what follows to it.
+func (m Model) reopenSession(name string) Model {
+	if name == "" || name == m.options.History.Name() {
+		return m
+	}
+	msgs, err := m.options.History.Resume(name)
+	if err != nil {
+		m.setError("Cannot reopen session", err.Error(), false)
+		return m
+	}
+
+	m.messages = nil
+	m.streamingText = ""
+	m.setFocus(PanelInput)
+	m.options.Resume = msgs
+	return m.resumeJournal()
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Input methods for Chinese, Japanese and Korean run in the terminal: it
+// draws the text being composed at its cursor, places the candidate
+// window there, and sends the TUI only the committed text. The TUI hides
+// the terminal's cursor and draws its own, so without help the composition
+// shows at the bottom left of the screen. After each frame the cursor is
+// moved, still hidden, to the input's caret.
+
+// caretMarker marks the caret in a rendering of the input box. It is an
+// escape sequence terminals ignore, so it takes up no columns.
+const caretMarker = "\x1b_agentui-caret\x1b\\"
+
+// caretPosition is where the input's caret was drawn in the latest frame,
+// shared with the terminal the frames are written to.
+type caretPosition struct {
+	mu       sync.Mutex
+	row, col int
+	shown    bool
+}
+
+func (c *caretPosition) set(row, col int, shown bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.row, c.col, c.shown = row, col, shown
+}
+
+func (c *caretPosition) get() (row, col int, shown bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.row, c.col, c.shown
+}
+
+// composing reports whether the user is typing in the input, so the
+// caret is where input methods compose.
+func (m Model) composing() bool {
+	return m.state == StateChat && !m.search.open && m.focused() == PanelInput &&
+		m.input.Focused() && !m.options.ReadOnly
+}
+
+// trackCaret records where the input's caret is in frame, whose input box
+// starts top rows down and is drawn in style.
+func (m Model) trackCaret(frame string, top int, style lipgloss.Style) {
+	if m.caret == nil {
+		return
+	}
+	if !m.composing() {
+		m.caret.set(0, 0, false)
+		return
+	}
+
+	// Draw the input box again with the caret marked. Its cursor is drawn
+	// as shown even while it blinks off, since only the shown cursor has
+	// a style of its own.
+	input := m.input
+	input.Cursor.Blink = false
+	input.Cursor.Style = input.Cursor.Style.Transform(func(s string) string {
+		return caretMarker + s
+	})
+	view := input.View()
+	if m.suggesting() {
+		view = m.renderSuggestion(view)
+	}
+	box := strings.Split(style.Render(view), "\n")
+
+	row, col := -1, 0
+	for i, line := range box {
+		if j := strings.Index(line, caretMarker); j >= 0 {
+			row, col = i, ansi.StringWidth(line[:j])
+			break
+		}
+	}
+	lines := strings.Split(frame, "\n")
+	if row < 0 || top+row >= len(lines) {
+		m.caret.set(0, 0, false)
+		return
+	}
+
+	// The box may be placed off the left edge, so find it in its row
+	drawn := ansi.Strip(lines[top+row])
+	left := strings.Index(drawn, ansi.Strip(box[row]))
+	if left < 0 {
+		m.caret.set(0, 0, false)
+		return
+	}
+	m.caret.set(top+row, ansi.StringWidth(drawn[:left])+col, true)
+}
+
+// TerminalOutput is the terminal the TUI draws to. It moves the terminal's
+// cursor to the input's caret after each frame.
+type TerminalOutput struct {
+	*os.File
+	caret *caretPosition
+}
+
+// Output wraps the terminal f for drawing m on, for tea.WithOutput. It
+// expects the alternate screen, where each frame is drawn from the top
+// left corner.
+func (m Model) Output(f *os.File) *TerminalOutput {
+	return &TerminalOutput{File: f, caret: m.caret}
+}
+
+// Write writes p, then moves the cursor to the caret if p is a frame.
+func (o *TerminalOutput) Write(p []byte) (int, error) {
+	n, err := o.File.Write(p)
+	if err != nil || o.caret == nil || !bytes.HasPrefix(p, []byte(ansi.HomeCursorPosition)) {
+		return n, err
+	}
+	if row, col, shown := o.caret.get(); shown {
+		_, err = o.File.WriteString(ansi.SetCursorPosition(col+1, row+1))
+	}
+	return n, err
+}
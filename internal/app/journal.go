@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// transient lists message types not rebuilt from a journal: prompts the
+// agent that sent them no longer waits on, and indicators of work that
+// ended with it.
+var transient = map[protocol.MessageType]bool{
+	protocol.TypeForm:         true,
+	protocol.TypeConfirm:      true,
+	protocol.TypeSelect:       true,
+	protocol.TypeSecret:       true,
+	protocol.TypeAutocomplete: true,
+	protocol.TypeDiff:         true,
+	protocol.TypeQuestion:     true,
+	protocol.TypeSpinner:      true,
+	protocol.TypeProgress:     true,
+	protocol.TypeFocusInput:   true,
+}
+
+// resumeJournal rebuilds the transcript from the journaled messages in
+// the options, or those of a session reopened from the history, once the
+// UI has been laid out so they render at its width.
+func (m Model) resumeJournal() Model {
+	msgs := m.options.Resume
+	if len(msgs) == 0 {
+		return m
+	}
+	m.options.Resume = nil
+
+	// Nothing is listened for until the app starts
+	m.batching = true
+	for _, msg := range msgs {
+		m = m.resumeMessage(msg)
+	}
+	m.batching = false
+
+	// Text the agent was still streaming is kept as it was
+	m.setDownStream()
+	m.isStreaming = false
+
+	m.refreshMessages()
+	m.viewport.GotoBottom()
+	m.statusMessage = fmt.Sprintf("Restored %d messages from the journal", len(msgs))
+	switch {
+	case m.options.ReadOnly:
+		m.statusMessage = fmt.Sprintf("Opened %d messages read-only", len(msgs))
+	case m.options.History != nil:
+		m.statusMessage = fmt.Sprintf("Reopened session %s (%d messages)", m.options.History.Name(), len(msgs))
+	}
+	return m
+}
+
+// resumeMessage applies one journaled message: the user's input, or one
+// from the agent that is not transient.
+func (m Model) resumeMessage(msg *protocol.Message) Model {
+	switch {
+	case msg.Type == protocol.TypeInput:
+		var payload protocol.InputPayload
+		if msg.ParsePayload(&payload) == nil {
+			m.messages = append(m.messages, Message{
+				Role:      "user",
+				Content:   payload.Content,
+				Timestamp: time.Now(),
+			})
+		}
+		return m
+
+	case msg.Type == protocol.TypeBatch:
+		for _, elem := range msg.Batch {
+			m = m.resumeMessage(elem)
+		}
+		return m
+
+	case transient[msg.Type], !slices.Contains(protocol.RenderTypes, msg.Type):
+		return m
+	}
+
+	model, _ := m.routeMessage(msg)
+	return model.(Model)
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// Default content limits; see ContentLimits.
+const (
+	DefaultMaxCodeLines     = 2000
+	DefaultMaxTableRows     = 500
+	DefaultMaxMarkdownBytes = 256 << 10
+)
+
+// maxPagerCellWidth is the widest a column of a table opened in full view
+// is padded to.
+const maxPagerCellWidth = 40
+
+// ContentLimits caps how much of a message the transcript and artifacts
+// panel draw, so a pathological output, such as a code block of a million
+// lines, cannot stall the UI. The rest is cut with a note, and the whole
+// can be opened in a full view drawn as plain text. Zero leaves that kind
+// of content uncapped.
+type ContentLimits struct {
+	CodeLines     int // lines of a code block
+	TableRows     int // rows of a table
+	MarkdownBytes int // bytes of a reply, markdown or streamed text
+}
+
+// DefaultContentLimits returns the limits used unless configured.
+func DefaultContentLimits() ContentLimits {
+	return ContentLimits{
+		CodeLines:     DefaultMaxCodeLines,
+		TableRows:     DefaultMaxTableRows,
+		MarkdownBytes: DefaultMaxMarkdownBytes,
+	}
+}
+
+// truncated reports whether the limits cut msg.
+func (l ContentLimits) truncated(msg Message) bool {
+	switch {
+	case msg.Table != nil:
+		return l.TableRows > 0 && len(msg.Table.Rows) > l.TableRows
+	case msg.IsCode:
+		return l.CodeLines > 0 && strings.Count(strings.TrimSuffix(msg.Content, "\n"), "\n") >= l.CodeLines
+	case msg.Role == "assistant":
+		return l.MarkdownBytes > 0 && len(msg.Content) > l.MarkdownBytes
+	}
+	return false
+}
+
+// truncate returns the part of msg within the limits, and what it shows
+// of the whole, such as "2000 of 52113 lines", or "" if msg is within
+// them.
+func (l ContentLimits) truncate(msg Message) (Message, string) {
+	if !l.truncated(msg) {
+		return msg, ""
+	}
+	switch {
+	case msg.Table != nil:
+		table := *msg.Table
+		var shown string
+		table.Rows, shown = l.truncateTable(msg.Table)
+		msg.Table = &table
+		return msg, shown
+	case msg.IsCode:
+		total := strings.Count(strings.TrimSuffix(msg.Content, "\n"), "\n") + 1
+		end := 0
+		for range l.CodeLines {
+			end += strings.IndexByte(msg.Content[end:], '\n') + 1
+		}
+		msg.Content = msg.Content[:end-1]
+		return msg, fmt.Sprintf("%d of %d lines", l.CodeLines, total)
+	}
+	total := len(msg.Content)
+	msg.Content = cutText(msg.Content, l.MarkdownBytes)
+	return msg, fmt.Sprintf("%s of %s", formatBytes(int64(len(msg.Content))), formatBytes(int64(total)))
+}
+
+// truncateTable returns the rows of t within the limits, and what they
+// show of the whole, or "" if all are.
+func (l ContentLimits) truncateTable(t *protocol.TablePayload) ([][]string, string) {
+	if l.TableRows <= 0 || len(t.Rows) <= l.TableRows {
+		return t.Rows, ""
+	}
+	return t.Rows[:l.TableRows], fmt.Sprintf("%d of %d rows", l.TableRows, len(t.Rows))
+}
+
+// cutText cuts s to at most n bytes, at the end of a line if one ends in
+// the last quarter of them, and otherwise between runes.
+func cutText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	if i := strings.LastIndexByte(s, '\n'); i >= n*3/4 {
+		return s[:i]
+	}
+	for len(s) > 0 {
+		if r, size := utf8.DecodeLastRuneInString(s); r != utf8.RuneError || size > 1 {
+			break
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// renderTruncation renders the note under output cut to its limit, which
+// says how much of it is shown and, if it can be opened in full, how.
+func renderTruncation(shown string, openable bool) string {
+	note := "Output truncated: showing " + shown
+	if openable {
+		note += " " + theme.Glyphs.Bullet + " press o to open the full view"
+	}
+	return lipgloss.NewStyle().Foreground(theme.Current.Colors.Warning).Italic(true).Render(note)
+}
+
+// truncatedInView returns the index of the truncated message nearest the
+// bottom of the transcript as scrolled, or -1 if there is none.
+func (m Model) truncatedInView() int {
+	bottom := m.viewport.YOffset + m.viewport.Height
+	found := -1
+	for i, msg := range m.messages {
+		if i < len(m.layout.starts) && m.layout.starts[i] >= bottom && found >= 0 {
+			break
+		}
+		if m.options.Limits.truncated(msg) {
+			found = i
+		}
+	}
+	return found
+}
+
+// openFullView shows the whole of msg, which the limits may cut, as plain
+// text.
+func (m *Model) openFullView(msg Message) {
+	text := msg.Content
+	if msg.Table != nil {
+		text = plainTable(msg.Table)
+	}
+	m.currentPager = components.NewPager(artifactTitle(msg, ""), text)
+	m.currentPager.SetSize(m.width, m.height-headerHeight-footerHeight)
+	m.statusMessage = ""
+	m.state = StateFullView
+}
+
+// plainTable lays a table out as plain text, a row to a line, with the
+// header first. Columns are padded to their widest cell, up to
+// maxPagerCellWidth.
+func plainTable(t *protocol.TablePayload) string {
+	rows := append([][]string{tableColumns(t)}, t.Rows...)
+	var widths []int
+	for _, row := range rows {
+		for j, cell := range row {
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = min(max(widths[j], lipgloss.Width(cell)), maxPagerCellWidth)
+		}
+	}
+
+	var sb strings.Builder
+	sep := " " + theme.Glyphs.Table.Left + " "
+	for i, row := range rows {
+		cells := slices.Clone(row)
+		for j, cell := range cells {
+			if w := lipgloss.Width(cell); w < widths[j] && j < len(cells)-1 {
+				cells[j] = cell + strings.Repeat(" ", widths[j]-w)
+			}
+		}
+		sb.WriteString(strings.Join(cells, sep))
+		sb.WriteString("\n")
+		if i == 0 {
+			total := 0
+			for _, w := range widths {
+				total += w
+			}
+			total += lipgloss.Width(sep) * max(len(widths)-1, 0)
+			sb.WriteString(strings.Repeat(theme.Glyphs.Table.Top, total))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// compactKeep is how many recent messages a manual compaction keeps.
+const compactKeep = 100
+
+// memoryUsage is an estimate of where the TUI's memory goes. Transcript and
+// render sizes count string and table bytes, not Go allocation overhead.
+type memoryUsage struct {
+	Messages   int
+	Transcript int // message text, raw JSON and code metadata
+	Tables     int // table data kept for export
+	Rendered   int // the rendered transcript held by the viewport
+	HeapAlloc  uint64
+	HeapSys    uint64
+	NumGC      uint32
+}
+
+// measureMemory estimates the memory held by the transcript and its render.
+func (m Model) measureMemory() memoryUsage {
+	u := memoryUsage{
+		Messages: len(m.messages),
+		Rendered: len(m.renderMessages()),
+	}
+	for _, msg := range m.messages {
+		u.Transcript += len(msg.Content) + len(msg.Raw) + len(msg.ID) + len(msg.Language) + 8*len(msg.ChangedLines)
+		if msg.Table != nil {
+			for _, col := range msg.Table.Columns {
+				u.Tables += len(fmt.Sprint(col))
+			}
+			for _, row := range msg.Table.Rows {
+				for _, cell := range row {
+					u.Tables += len(cell)
+				}
+			}
+		}
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	u.HeapAlloc, u.HeapSys, u.NumGC = stats.HeapAlloc, stats.HeapSys, stats.NumGC
+	return u
+}
+
+// String formats the usage as one line per category.
+func (u memoryUsage) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Transcript:  %s in %d messages\n", formatBytes(int64(u.Transcript)), u.Messages)
+	fmt.Fprintf(&sb, "Table data:  %s\n", formatBytes(int64(u.Tables)))
+	fmt.Fprintf(&sb, "Rendered:    %s\n", formatBytes(int64(u.Rendered)))
+	fmt.Fprintf(&sb, "Go heap:     %s in use, %s reserved, %d GCs", formatBytes(int64(u.HeapAlloc)), formatBytes(int64(u.HeapSys)), u.NumGC)
+	return sb.String()
+}
+
+// showMemoryReport appends the current memory usage to the chat.
+func (m *Model) showMemoryReport() {
+	m.addReport("Memory usage", m.measureMemory().String()+"\n\nctrl+k compacts the transcript")
+}
+
+// compactTranscript drops all but the most recent messages, returns freed
+// memory to the OS and reports the difference.
+func (m *Model) compactTranscript() {
+	before := m.measureMemory()
+	dropped := len(m.messages) - compactKeep
+	if dropped <= 0 {
+		m.statusMessage = fmt.Sprintf("Nothing to compact (%d messages)", len(m.messages))
+		return
+	}
+
+	kept := make([]Message, compactKeep)
+	copy(kept, m.messages[dropped:])
+	m.messages = kept
+	debug.FreeOSMemory()
+	after := m.measureMemory()
+
+	m.addReport("Transcript compacted", fmt.Sprintf(
+		"Dropped %d earlier messages, freeing %s of transcript and %s of heap\n\n%s",
+		dropped,
+		formatBytes(int64(max(0, before.Transcript+before.Tables-after.Transcript-after.Tables))),
+		formatBytes(max(0, int64(before.HeapAlloc)-int64(after.HeapAlloc))),
+		after,
+	))
+}
+
+// addReport appends an info alert to the chat.
+func (m *Model) addReport(title, body string) {
+	m.alertView.SetTitle(title)
+	m.alertView.SetMessage(body)
+	m.alertView.SetSeverity("info")
+	m.messages = append(m.messages, Message{
+		Role:      "system",
+		Content:   m.alertView.View(),
+		Timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The input grows with the draft from minInputLines to maxInputLines
+// lines of text, taking the room from the transcript, which keeps at
+// least minTranscriptLines.
+const (
+	minInputLines      = 3
+	maxInputLines      = 10
+	minTranscriptLines = 3
+
+	// inputBorder is the lines the input box's border takes
+	inputBorder = 2
+)
+
+// newlineKeys insert a newline in the draft, since Enter sends it. Most
+// terminals send Shift+Enter as Enter; those set up to send it as
+// Alt+Enter insert a newline with it too.
+var newlineKeys = []string{"alt+enter", "ctrl+j"}
+
+// inputHeight returns the height of the input box.
+func (m Model) inputHeight() int {
+	return m.input.Height() + inputBorder
+}
+
+// draftLines returns how many lines the draft takes in the input, wrapped
+// at its width. Lines wrap at words, so a line may take one more.
+func (m Model) draftLines() int {
+	width := max(1, m.input.Width())
+	n := 0
+	for _, line := range strings.Split(m.input.Value(), "\n") {
+		// A line as wide as the input wraps to leave room for the cursor
+		n += ansi.StringWidth(line)/width + 1
+	}
+	return n
+}
+
+// fitInput grows or shrinks the input to the draft, resizing the
+// transcript to match.
+func (m *Model) fitInput() {
+	lines := max(minInputLines, min(m.draftLines(), maxInputLines))
+	if m.height > 0 {
+		room := m.height - headerHeight - footerHeight - m.pinnedHeight() - inputBorder - minTranscriptLines
+		lines = min(lines, max(minInputLines, room))
+	}
+	if lines == m.input.Height() {
+		return
+	}
+
+	grew := lines > m.input.Height()
+	m.input.SetHeight(lines)
+	if grew {
+		m.scrollInputToTop()
+	}
+
+	atBottom := m.viewport.AtBottom()
+	m.viewport.Height = m.transcriptHeight()
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// scrollInputToTop scrolls the draft as far up as it goes with the cursor
+// in view. The input only scrolls as the cursor moves, so a draft that
+// scrolled while the input was shorter would otherwise stay scrolled with
+// room to spare.
+func (m *Model) scrollInputToTop() {
+	info := m.input.LineInfo()
+	row, col := m.input.Line(), info.StartColumn+info.ColumnOffset
+	for m.input.Line() > 0 {
+		m.input.CursorUp()
+	}
+	m.input.CursorStart()
+	m.input, _ = m.input.Update(nil)
+	for m.input.Line() < row {
+		m.input.CursorDown()
+	}
+	m.input.SetCursor(col)
+	m.input, _ = m.input.Update(nil)
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"hash/fnv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// routeMessage handles msg from one of several agents sharing the
+// connection, told apart by their agent_id (see the protocol's
+// multiplex.go). Text another agent was still streaming is set down as a
+// message of its own first, since only one reply streams at a time, and
+// the messages msg adds to the transcript are tagged with its agent so
+// each agent's messages show under its name, and pinned if msg is.
+// Escape sequences in its strings are stripped first, so they never reach
+// the terminal. With several chat tabs open, msg is handled in its agent's
+// tab.
+func (m Model) routeMessage(msg *protocol.Message) (tea.Model, tea.Cmd) {
+	if msg.Type == protocol.TypeBatch {
+		// Its messages are routed one by one
+		return m.handleProtocolMsg(msg)
+	}
+	if i := m.tabFor(msg.AgentID); len(m.tabs.list) > 1 && i != m.tabs.active {
+		return m.routeToTab(i, msg)
+	}
+	m.stripped += msg.Sanitize(m.options.RevealEscapes)
+	if msg.Type == protocol.TypeText && msg.AgentID != m.streamAgent {
+		m.setDownStream()
+		m.streamAgent = msg.AgentID
+	}
+
+	n := len(m.messages)
+	model, cmd := m.handleProtocolMsg(msg)
+	m = model.(Model)
+	if msg.AgentID != "" {
+		for i := n; i < len(m.messages); i++ {
+			if m.messages[i].Role != "user" && m.messages[i].Agent == "" {
+				m.messages[i].Agent = msg.AgentID
+			}
+		}
+	}
+	if msg.Pinned && len(m.messages) > n {
+		for i := n; i < len(m.messages); i++ {
+			m.messages[i].Pinned = true
+		}
+		m.refreshMessages()
+	}
+	return m, cmd
+}
+
+// setDownStream ends the text being streamed as a message.
+func (m *Model) setDownStream() {
+	if m.streamingText == "" {
+		return
+	}
+	m.messages = append(m.messages, Message{
+		Role:      "assistant",
+		Content:   m.streamingText,
+		Timestamp: time.Now(),
+		Agent:     m.streamAgent,
+	})
+	m.streamingText = ""
+}
+
+// renderAgentLabel renders the name of the agent a message comes from
+// when it starts a run of that agent's messages, prev being the agent of
+// the message before. Messages of an unnamed agent get no label.
+func (m Model) renderAgentLabel(agent string, prev *string) string {
+	if agent == *prev {
+		return ""
+	}
+	*prev = agent
+	if agent == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(agentColor(agent)).Render(agent) + "\n"
+}
+
+// agentColor picks a theme accent for an agent, the same each time it
+// appears.
+func agentColor(agent string) lipgloss.TerminalColor {
+	c := theme.Current.Colors
+	palette := []lipgloss.TerminalColor{c.Accent1, c.Accent2, c.Accent3, c.Primary, c.Secondary}
+	h := fnv.New32a()
+	h.Write([]byte(agent))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// toggleNativeSelect lets go of the mouse for the terminal to select text
+// with, or takes it back. While the TUI captures the mouse the terminal
+// cannot select text, so F2 lets go of it and draws nothing but the
+// transcript: what the user selects is the messages alone, without the
+// header, the input's box, the sidebar or the status bar.
+func (m *Model) toggleNativeSelect() tea.Cmd {
+	m.nativeSelect = !m.nativeSelect
+	m.stopSelecting()
+	// The transcript takes the whole terminal but for the hint
+	m.refreshMessages()
+	if m.nativeSelect {
+		return tea.DisableMouse
+	}
+	return tea.EnableMouseCellMotion
+}
+
+// updateNativeSelect handles a key while the terminal selects text: the
+// transcript still scrolls, and F2, Esc or q bring the TUI back.
+func (m *Model) updateNativeSelect(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "f2", "esc", "q":
+		return m.toggleNativeSelect()
+	case "pgup":
+		m.viewport.LineUp(10)
+	case "pgdown":
+		m.viewport.LineDown(10)
+	default:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// renderNativeSelect renders the transcript over a line saying how to
+// bring the TUI back.
+func (m Model) renderNativeSelect() string {
+	hint := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).
+		Render("Select text with the mouse" + theme.Glyphs.Separator + "PgUp and PgDn scroll" + theme.Glyphs.Separator + "F2 or Esc to go back")
+	return m.viewport.View() + "\n" + hint
+}
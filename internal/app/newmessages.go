@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// showNewMessage shows a message the agent added at the end of the
+// transcript. The view follows it if it was at the bottom; otherwise it
+// stays where the user scrolled to, and a pill over the bottom of the
+// transcript counts the messages below.
+func (m *Model) showNewMessage() {
+	atBottom := m.viewport.AtBottom()
+	m.refreshMessages()
+	if !atBottom {
+		m.newBelow++
+	}
+}
+
+// jumpToNew scrolls to the bottom of the transcript, where the new
+// messages are.
+func (m *Model) jumpToNew() {
+	m.viewport.GotoBottom()
+	m.newBelow = 0
+}
+
+// seenNew forgets the new messages once the view reached the bottom,
+// however it got there.
+func (m Model) seenNew() Model {
+	if m.newBelow > 0 && m.viewport.AtBottom() {
+		m.newBelow = 0
+	}
+	return m
+}
+
+// newPillRow returns the terminal row the pill is drawn on, the last of
+// the transcript.
+func (m Model) newPillRow() int {
+	return headerHeight + m.pinnedHeight() + m.viewport.Height - 1
+}
+
+// clickNewPill jumps to the new messages when the user clicks the pill.
+func (m *Model) clickNewPill(msg tea.MouseMsg) bool {
+	if m.state != StateChat || m.newBelow == 0 || m.viewport.AtBottom() {
+		return false
+	}
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft || msg.Y != m.newPillRow() {
+		return false
+	}
+	m.jumpToNew()
+	return true
+}
+
+// placeNewPill draws the count of new messages below the view, if there
+// are any, centered over the last line of view.
+func (m Model) placeNewPill(view string) string {
+	if m.newBelow == 0 || m.viewport.AtBottom() {
+		return view
+	}
+	colors := theme.Current.Colors
+	label := "1 new message"
+	if m.newBelow > 1 {
+		label = fmt.Sprintf("%d new messages", m.newBelow)
+	}
+	pill := lipgloss.NewStyle().Bold(true).Foreground(colors.Primary).Render(" "+theme.Glyphs.Down+" "+label) +
+		lipgloss.NewStyle().Foreground(colors.TextMuted).Render(theme.Glyphs.Separator+"ctrl+end ")
+
+	lines := strings.Split(view, "\n")
+	last := len(lines) - 1
+	width := lipgloss.Width(view)
+	left := (width - lipgloss.Width(pill)) / 2
+	if left < 0 {
+		return view
+	}
+	line := ansi.Truncate(lines[last], left, "")
+	if w := ansi.StringWidth(line); w < left {
+		line += strings.Repeat(" ", left-w)
+	}
+	lines[last] = line + "\x1b[0m" + pill
+	return strings.Join(lines, "\n")
+}
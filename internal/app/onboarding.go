@@ -0,0 +1,228 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/config"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// onboardingStep is a page of the first-run setup.
+type onboardingStep int
+
+const (
+	stepTheme onboardingStep = iota
+	stepSessions
+)
+
+// Onboarding is the first-run setup, run as its own program before the TUI
+// starts. It asks for a theme, previewing each one as it is highlighted,
+// then for a sessions directory and whether to share usage statistics, and
+// reports the resulting configuration.
+type Onboarding struct {
+	cfg         config.Config
+	sessionsDir string // suggested when the config has none
+
+	step     onboardingStep
+	themes   *components.SelectMenu
+	sessions *components.Form
+	width    int
+
+	done    bool
+	aborted bool
+}
+
+// NewOnboarding creates the setup, starting from cfg. sessionsDir is the
+// sessions directory offered by default.
+func NewOnboarding(cfg config.Config, sessionsDir string) *Onboarding {
+	ids := make([]string, 0, len(theme.Available))
+	for id := range theme.Available {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	options := make([]protocol.SelectOption, len(ids))
+	for i, id := range ids {
+		t := theme.Available[id]
+		options[i] = protocol.SelectOption{Label: t.Name, Value: id, Description: t.Description}
+	}
+	current := cfg.Theme
+	if current == "" {
+		current = theme.Current.ID
+	}
+
+	o := &Onboarding{
+		cfg:         cfg,
+		sessionsDir: sessionsDir,
+		themes: components.NewSelectMenu(&protocol.SelectPayload{
+			Label:   "Choose a theme",
+			Options: options,
+			Default: current,
+		}),
+	}
+	o.previewTheme()
+	return o
+}
+
+// Result returns the configuration chosen. ok is false if the user quit
+// before finishing, in which case nothing should be saved.
+func (o *Onboarding) Result() (cfg config.Config, ok bool) {
+	return o.cfg, o.done && !o.aborted
+}
+
+// Init implements tea.Model.
+func (o *Onboarding) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (o *Onboarding) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		o.width = msg.Width
+		o.themes.SetWidth(msg.Width / 2)
+		if o.sessions != nil {
+			o.sessions.SetWidth(msg.Width)
+		}
+		return o, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			o.aborted = true
+			return o, tea.Quit
+		}
+	}
+
+	switch o.step {
+	case stepTheme:
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "esc":
+				// Keep the defaults, and don't ask again
+				o.done = true
+				return o, tea.Quit
+			case "enter":
+				o.cfg.Theme = o.themes.GetSelected()
+				o.step = stepSessions
+				o.sessions = o.newSessionsForm()
+				return o, nil
+			}
+		}
+		cmd := o.themes.Update(msg)
+		o.previewTheme()
+		return o, cmd
+
+	case stepSessions:
+		cmd := o.sessions.Update(msg)
+		if o.sessions.IsCancelled() {
+			o.step = stepTheme
+			return o, nil
+		}
+		if o.sessions.IsSubmitted() {
+			values := o.sessions.GetValues()
+			dir, _ := values["sessions_dir"].(string)
+			o.cfg.SessionsDir = strings.TrimSpace(dir)
+			o.cfg.Telemetry, _ = values["telemetry"].(bool)
+			o.done = true
+			return o, tea.Quit
+		}
+		return o, cmd
+	}
+	return o, nil
+}
+
+// previewTheme switches to the highlighted theme so the whole setup shows
+// in it.
+func (o *Onboarding) previewTheme() {
+	if id := o.themes.GetSelected(); id != "" {
+		theme.SetTheme(id)
+	}
+}
+
+func (o *Onboarding) newSessionsForm() *components.Form {
+	dir := o.cfg.SessionsDir
+	if dir == "" {
+		dir = o.sessionsDir
+	}
+	form := components.NewForm(&protocol.FormPayload{
+		Title:       "Sessions and telemetry",
+		Description: "Sessions are saved here, to reopen with --resume or ctrl+s. Pass --history-dir \"\" to save nothing.",
+		Fields: []protocol.FormField{{
+			Name:    "sessions_dir",
+			Label:   "Sessions directory",
+			Type:    "text",
+			Default: dir,
+		}, {
+			Name:        "telemetry",
+			Label:       "Share anonymous usage statistics",
+			Type:        "checkbox",
+			Default:     o.cfg.Telemetry,
+			Description: "Feature use counts and crash signatures only. Inspect them with: agentui-tui telemetry show",
+		}},
+		SubmitLabel: "Finish",
+		CancelLabel: "Back",
+	})
+	form.SetWidth(o.width)
+	return form
+}
+
+// View implements tea.Model.
+func (o *Onboarding) View() string {
+	if o.done || o.aborted {
+		return ""
+	}
+	styles := theme.Current.Styles
+
+	header := styles.Header.Width(o.width).Render("Welcome to AgentUI · First-run setup")
+	var content string
+	switch o.step {
+	case stepTheme:
+		content = lipgloss.JoinHorizontal(lipgloss.Top, o.themes.View(), "  ", o.renderPreview())
+	case stepSessions:
+		content = o.sessions.View()
+	}
+	hint := "Change these later with --setup or in " + configHint()
+	if o.step == stepTheme {
+		hint += " · Esc keeps the defaults"
+	}
+	hint = lipgloss.NewStyle().Foreground(theme.Current.Colors.TextDim).Italic(true).
+		Render(hint + " · Ctrl+C quits without saving")
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", content, "", hint)
+}
+
+// renderPreview shows a sample conversation in the current theme.
+func (o *Onboarding) renderPreview() string {
+	styles := theme.Current.Styles
+	colors := theme.Current.Colors
+	width := 40
+	if o.width > 0 {
+		width = max(30, min(60, o.width/2-4))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(styles.FormTitle.Render("Preview"))
+	sb.WriteString("\n\n")
+	sb.WriteString(styles.UserMessage.Render("Summarize the test results"))
+	sb.WriteString("\n")
+	sb.WriteString(styles.AssistantMessage.Render(theme.Glyphs.Assistant + "42 passed, 1 skipped. The skipped test needs network access."))
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(colors.Success).Render(theme.Glyphs.Success+" passed") + "  " +
+		lipgloss.NewStyle().Foreground(colors.Warning).Render(theme.Glyphs.Warning+" skipped") + "  " +
+		lipgloss.NewStyle().Foreground(colors.Error).Render(theme.Glyphs.Error+" failed"))
+	return styles.FormContainer.Width(width).Render(sb.String())
+}
+
+// configHint names the configuration file for display.
+func configHint() string {
+	if path, err := config.Path(); err == nil {
+		return path
+	}
+	return "the config file"
+}
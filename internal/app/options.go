@@ -0,0 +1,183 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/clock"
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/history"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/schedule"
+	"github.com/flight505/agentui/internal/telemetry"
+)
+
+// Options configures optional Model behavior.
+// The zero value leaves all optional features disabled.
+type Options struct {
+	// KeepAliveInterval sends keepalive events at this interval while the
+	// user is composing a prompt. Zero disables keep-alives.
+	KeepAliveInterval time.Duration
+
+	// TypingIdle sends typing start and stop events while the user
+	// composes a prompt, stopping after this long without a keystroke.
+	// Zero disables typing events.
+	TypingIdle time.Duration
+
+	// HeartbeatInterval pings the agent at this interval and asks the user
+	// what to do when it stops answering. Zero disables pings.
+	HeartbeatInterval time.Duration
+
+	// StartupTimeout keeps the input closed until the first message from
+	// the agent, usually its hello, and shows what to check if none
+	// arrives in this long. Zero opens the input at once.
+	StartupTimeout time.Duration
+
+	// Sidebar shows the sidebar from the start; ctrl+b toggles it.
+	Sidebar bool
+
+	// StatusRate caps how many times a second status and progress updates
+	// are drawn. Updates arriving faster are coalesced, keeping the latest
+	// status and the latest state of each progress bar. Zero draws every
+	// update.
+	StatusRate int
+
+	// Budget and TokenBudget cap what the session may cost, in dollars,
+	// and how many tokens it may use, as the agent reports in metadata
+	// messages. Going over either warns the user and sends the agent a
+	// budget_exceeded event. Zero sets no budget.
+	Budget      float64
+	TokenBudget int
+
+	// Limits caps how much of a code block, table or reply is drawn; the
+	// rest can be opened in a full view. The zero value draws everything.
+	Limits ContentLimits
+
+	// FoldLines folds replies longer than this many lines to their first
+	// lines, which the user can expand with o. Zero never folds.
+	FoldLines int
+
+	// RevealEscapes shows the terminal escape sequences stripped from
+	// agent text as visible text, such as ␛[2J, to debug an agent that
+	// sends them.
+	RevealEscapes bool
+
+	// Clock formats times and durations. The zero value uses a 24 hour
+	// clock in local time.
+	Clock clock.Clock
+
+	// Timestamps is how the transcript shows when messages arrived:
+	// TimestampsMinute, TimestampsMessage, TimestampsRelative or
+	// TimestampsOff, which also hides how long each reply took. Empty
+	// means TimestampsMinute.
+	Timestamps string
+
+	// TabOrder is the order Tab moves focus through the panels of the chat
+	// view; see ParseTabOrder. Empty uses DefaultTabOrder.
+	TabOrder []string
+
+	// AgentControl lets the agent scroll the transcript, focus the input
+	// and collapse or expand messages. Off, such messages are ignored.
+	AgentControl bool
+
+	// Layout is how the chat uses a wide terminal: LayoutFull, LayoutCenter
+	// or LayoutColumns. The last two keep it at most MaxWidth wide; see
+	// wide.go. Empty means LayoutFull.
+	Layout   string
+	MaxWidth int
+
+	// RetryReplaces makes retrying the last exchange (ctrl+r, /retry)
+	// remove the answer it replaces from the transcript; otherwise the
+	// input is shown again and both answers stay.
+	RetryReplaces bool
+
+	// TurnTiming shows under each turn how long the agent took to start
+	// answering, to stream its reply and to finish, tool calls included.
+	// Debug mode shows it too.
+	TurnTiming bool
+
+	// Resume holds journaled messages to rebuild the transcript from once
+	// the UI is laid out; see the journal package.
+	Resume []*protocol.Message
+
+	// History records the session to a store of past sessions, which the
+	// user can reopen with ctrl+s. Nil turns session history off.
+	History *history.Recorder
+
+	// InputHistory keeps the prompts the user sent, which Up at an empty
+	// prompt recalls. Nil keeps those of this session only.
+	InputHistory *history.Prompts
+
+	// ReadOnly shows the transcript rebuilt from Resume with no agent:
+	// nothing is listened for and nothing typed is sent. Bundles shared
+	// by other users are opened this way; see the bundle package.
+	ReadOnly bool
+
+	// Blobs keeps binary content sent by the agent in blob messages. Nil
+	// rejects blobs.
+	Blobs *protocol.BlobStore
+
+	// Spawner runs the agent as a child process. When it exits its code
+	// and last lines of standard error are shown, and it is offered a
+	// restart. Nil when the agent was not spawned by the TUI.
+	Spawner *protocol.Spawner
+
+	// RestartAttempts is how many times in a row a spawned agent that
+	// crashed is restarted by itself, waiting longer before each restart.
+	// After that, or for an agent that exited cleanly, the user is asked.
+	RestartAttempts int
+
+	// Telemetry counts feature use and crashes when the user has opted in.
+	// Nil leaves telemetry off.
+	Telemetry *telemetry.Recorder
+
+	// Environment describes the session for History and crash reports;
+	// the agent's hello fills in the agent. Nil records none.
+	Environment *environment.Snapshot
+
+	// Schedules send the agent prompts, or tick events, by themselves
+	// while the session is open; the user can add more with /schedule.
+	Schedules []schedule.Schedule
+
+	// CrashDir is where a report of a crash, with the environment, is
+	// written. Empty writes none.
+	CrashDir string
+}
+
+// WithOptions returns the model configured with the given options.
+func (m Model) WithOptions(opts Options) Model {
+	m.options = opts
+	m.schedules = append([]schedule.Schedule(nil), opts.Schedules...)
+	if opts.InputHistory == nil {
+		m.options.InputHistory, _ = history.OpenPrompts("")
+	}
+	if opts.Sidebar {
+		m.sidebar.open = true
+		m.listSidebarSessions()
+	}
+	if opts.ReadOnly {
+		m.input.Placeholder = "Read-only session"
+	} else if opts.StartupTimeout > 0 {
+		m.waitForAgent()
+	}
+	return m
+}
+
+// keepAliveTickMsg fires on every keep-alive interval.
+type keepAliveTickMsg struct{}
+
+// keepAliveTick schedules the next keep-alive check.
+func (m Model) keepAliveTick() tea.Cmd {
+	if m.options.KeepAliveInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.options.KeepAliveInterval, func(time.Time) tea.Msg {
+		return keepAliveTickMsg{}
+	})
+}
+
+// isComposing reports whether the user has an unsent draft in the input.
+func (m Model) isComposing() bool {
+	return m.state == StateChat && m.input.Value() != ""
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// maxPinnedShown is the most pinned messages the strip at the top of the
+// transcript shows, the latest ones; it counts the rest.
+const maxPinnedShown = 3
+
+// pinnedCount returns how many messages are pinned.
+func (m Model) pinnedCount() int {
+	n := 0
+	for _, msg := range m.messages {
+		if msg.Pinned {
+			n++
+		}
+	}
+	return n
+}
+
+// pinnedHeight returns the height of the pinned strip: a line for each
+// message shown and a rule under them, or 0 if nothing is pinned.
+func (m Model) pinnedHeight() int {
+	n := m.pinnedCount()
+	if n == 0 {
+		return 0
+	}
+	return min(n, maxPinnedShown) + 1
+}
+
+// transcriptHeight returns the height of the transcript: what the header,
+// status bar, input and pinned strip leave, or all but the hint's line
+// while the terminal selects text.
+func (m Model) transcriptHeight() int {
+	if m.nativeSelect {
+		return max(0, m.height-footerHeight)
+	}
+	return max(0, m.height-headerHeight-footerHeight-m.inputHeight()-m.pinnedHeight())
+}
+
+// togglePinned pins message i to the strip at the top of the transcript,
+// or unpins it.
+func (m *Model) togglePinned(i int) {
+	m.messages[i].Pinned = !m.messages[i].Pinned
+	if m.messages[i].Pinned {
+		m.statusMessage = "Pinned to the top"
+	} else {
+		m.statusMessage = "Unpinned"
+	}
+	m.refreshMessages()
+}
+
+// renderPinned renders the pinned strip: a line for each of the latest
+// pinned messages, over a rule counting those left out.
+func (m Model) renderPinned() string {
+	var pinned []Message
+	for _, msg := range m.messages {
+		if msg.Pinned {
+			pinned = append(pinned, msg)
+		}
+	}
+	if len(pinned) == 0 {
+		return ""
+	}
+	colors := theme.Current.Colors
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	mark := lipgloss.NewStyle().Foreground(colors.Accent1).Render(theme.Glyphs.Bullet) + " "
+
+	var sb strings.Builder
+	hidden := max(0, len(pinned)-maxPinnedShown)
+	for _, msg := range pinned[hidden:] {
+		line := pinnedSummary(msg)
+		if msg.Agent != "" {
+			line = msg.Agent + ": " + line
+		}
+		sb.WriteString(mark + ansi.Truncate(line, max(10, m.width-4), theme.Glyphs.Ellipsis) + "\n")
+	}
+	rule := ""
+	if hidden > 0 {
+		rule = fmt.Sprintf("%d more pinned ", hidden)
+	}
+	rule += strings.Repeat(theme.Glyphs.Border.Top, max(0, m.width-lipgloss.Width(rule)))
+	sb.WriteString(muted.Render(rule) + "\n")
+	return sb.String()
+}
+
+// pinnedSummary sums msg up in a line: its summary if collapsed with one,
+// a table's title or the first line of its content.
+func pinnedSummary(msg Message) string {
+	switch {
+	case msg.Summary != "":
+		return msg.Summary
+	case msg.Table != nil:
+		if msg.Table.Title != "" {
+			return msg.Table.Title
+		}
+		return "Table"
+	}
+	for _, line := range strings.Split(ansi.Strip(msg.Content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if msg.IsCode && msg.Language != "" {
+				return msg.Language + ": " + line
+			}
+			return line
+		}
+	}
+	return "(empty)"
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// setProgress creates or updates the progress bar with the payload's ID.
+// Payloads without an ID share a single default bar. Done removes the bar.
+func (m *Model) setProgress(p protocol.ProgressPayload) {
+	if p.Done {
+		m.removeProgress(p.ID)
+		return
+	}
+
+	bar, ok := m.progress[p.ID]
+	if !ok {
+		bar = views.NewProgressView()
+		bar.SetWidth(m.width - 4)
+		m.progress[p.ID] = bar
+		m.progressOrder = append(m.progressOrder, p.ID)
+	}
+
+	bar.SetMessage(p.Message)
+	if p.Percent != nil {
+		bar.SetPercent(*p.Percent)
+	} else {
+		bar.SetPercent(-1)
+	}
+	if p.ETA != nil {
+		bar.SetETA(m.formatETA(*p.ETA))
+	} else {
+		bar.SetETA("")
+	}
+	if len(p.Steps) > 0 {
+		steps := make([]views.ProgressStep, len(p.Steps))
+		for i, s := range p.Steps {
+			steps[i] = views.ProgressStep{
+				Label:  s.Label,
+				Status: s.Status,
+				Detail: s.Detail,
+			}
+		}
+		bar.SetSteps(steps)
+	}
+}
+
+// updateProgressFields applies an update message to the progress bar with
+// the given ID. Returns false if there is no such bar.
+func (m *Model) updateProgressFields(id string, fields map[string]any) bool {
+	bar, ok := m.progress[id]
+	if !ok {
+		return false
+	}
+	if message, ok := fields["message"].(string); ok {
+		bar.SetMessage(message)
+	}
+	if percent, ok := fields["percent"].(float64); ok {
+		bar.SetPercent(percent)
+	}
+	if eta, ok := fields["eta"].(float64); ok {
+		bar.SetETA(m.formatETA(eta))
+	}
+	if done, _ := fields["done"].(bool); done {
+		m.removeProgress(id)
+	}
+	return true
+}
+
+// formatETA formats an estimate of the seconds remaining.
+func (m *Model) formatETA(seconds float64) string {
+	return m.options.Clock.Duration(time.Duration(seconds * float64(time.Second)))
+}
+
+// removeProgress drops a single progress bar.
+func (m *Model) removeProgress(id string) {
+	if _, ok := m.progress[id]; !ok {
+		return
+	}
+	delete(m.progress, id)
+	for i, existing := range m.progressOrder {
+		if existing == id {
+			m.progressOrder = append(m.progressOrder[:i:i], m.progressOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// clearProgress drops all progress bars.
+func (m *Model) clearProgress() {
+	m.progress = make(map[string]*views.ProgressView)
+	m.progressOrder = nil
+}
+
+// setProgressWidth resizes all progress bars.
+func (m *Model) setProgressWidth(width int) {
+	for _, bar := range m.progress {
+		bar.SetWidth(width)
+	}
+}
+
+// renderProgress renders active progress bars stacked in creation order.
+func (m Model) renderProgress() string {
+	bars := make([]string, 0, len(m.progressOrder))
+	for _, id := range m.progressOrder {
+		bars = append(bars, m.progress[id].View())
+	}
+	return strings.Join(bars, "\n")
+}
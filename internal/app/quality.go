@@ -0,0 +1,58 @@
+package app
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// renderBudget is the longest a chat render may take while a message is
+// streaming before streaming messages fall back to plain rendering.
+const renderBudget = 25 * time.Millisecond
+
+// beginQualityPass is called before rendering the chat. Once nothing is
+// streaming, full quality is restored.
+func (p *renderPool) beginQualityPass(streaming bool) {
+	if !streaming {
+		p.degraded = false
+	}
+}
+
+// observe records how long a chat render took. A render over budget while a
+// message is streaming degrades the streaming messages until they finish.
+// Inline pools never degrade, so headless frames do not depend on timing.
+func (p *renderPool) observe(elapsed time.Duration, streaming bool) {
+	if streaming && !p.inline && elapsed > renderBudget {
+		p.degraded = true
+	}
+}
+
+// hasOpenMessage reports whether a markdown or code message is still
+// receiving chunks.
+func (m Model) hasOpenMessage() bool {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Open {
+			return true
+		}
+	}
+	return false
+}
+
+// renderPlain renders an assistant message cheaply: code without syntax
+// highlighting and markdown as wrapped text.
+func (m Model) renderPlain(msg Message) string {
+	if msg.IsCode {
+		m.codeView.SetHighlight(false)
+		defer m.codeView.SetHighlight(true)
+		return renderAssistant(msg, m.markdownView, m.codeView)
+	}
+
+	style := lipgloss.NewStyle().Foreground(theme.Current.Colors.Text)
+	if m.width > 0 {
+		style = style.Width(m.width - 4)
+	}
+	return views.Linkify(style.Render(theme.Glyphs.Assistant + msg.Content))
+}
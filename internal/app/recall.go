@@ -0,0 +1,61 @@
+package app
+
+// inputRecall is how far Up and Down have moved back through the prompts
+// sent before, like a shell's history.
+type inputRecall struct {
+	// back counts prompts back from the latest, which is 1; 0 is the
+	// draft being typed
+	back int
+}
+
+// recallInput moves through the prompts sent before for Up and Down,
+// reporting whether it handled the key. Up recalls the prompt before at
+// an empty prompt, or on the first line of a recalled one; Down recalls
+// the prompt after on the last line of a recalled one, or returns to an
+// empty prompt after the latest.
+func (m *Model) recallInput(key string) bool {
+	if m.options.InputHistory == nil {
+		return false
+	}
+	prompts := m.options.InputHistory.List()
+	back := m.recall.back
+	switch key {
+	case "up":
+		recalling := back > 0 && m.input.Line() == 0
+		if !recalling && (back > 0 || m.input.Value() != "") {
+			return false
+		}
+		if back >= len(prompts) {
+			return len(prompts) > 0
+		}
+		back++
+	case "down":
+		if back == 0 || m.input.Line() < m.input.LineCount()-1 {
+			return false
+		}
+		back--
+	default:
+		return false
+	}
+
+	m.recall.back = back
+	if back == 0 {
+		m.input.Reset()
+	} else {
+		m.input.SetValue(prompts[len(prompts)-back])
+	}
+	m.suggestion = nil
+	m.fitInput()
+	return true
+}
+
+// recordInput adds content to the prompts Up recalls.
+func (m *Model) recordInput(content string) {
+	m.recall = inputRecall{}
+	if m.options.InputHistory == nil {
+		return
+	}
+	if err := m.options.InputHistory.Add(content); err != nil {
+		m.statusMessage = "Cannot save input history: " + err.Error()
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// reconnect holds the prompt shown when the agent leaves in the middle of
+// a turn, and the state to go back to when it is dismissed.
+type reconnect struct {
+	prompt *components.SelectMenu
+	resume State
+}
+
+// Choices offered when the agent disconnects.
+const (
+	disconnectedWait = "wait"
+	disconnectedQuit = "quit"
+)
+
+// midTurn reports whether the user is waiting on the agent or the agent on
+// the user, so that losing the agent interrupts the session.
+func (m Model) midTurn() bool {
+	return m.isStreaming || m.hasOpenMessage() || isRequestState(m.state)
+}
+
+// agentLeft keeps the transcript when the agent disconnects and, if that
+// interrupted the session, asks whether to wait for it to come back. Open
+// requests stay open: answers given meanwhile are delivered to the next
+// agent to connect.
+func (m *Model) agentLeft(addr string) {
+	interrupted := m.midTurn() || addr == protocol.StdinAddr
+
+	// The reply in progress will not be finished
+	m.setDownStream()
+	for i := range m.messages {
+		m.messages[i].Open = false
+	}
+	m.isStreaming = false
+	m.refreshMessages()
+
+	// A spawned agent cannot reconnect; its exit is handled in supervise.go
+	if interrupted && m.state != StateDisconnected && m.options.Spawner == nil {
+		m.openDisconnectedPrompt()
+	}
+}
+
+// agentReturned dismisses the prompt when an agent connects again. The
+// handler has already delivered what was queued for it.
+func (m *Model) agentReturned() {
+	if m.state == StateDisconnected {
+		m.closeDisconnectedPrompt()
+	}
+}
+
+func (m *Model) openDisconnectedPrompt() {
+	wait := "Keep the transcript and wait for the agent to reconnect"
+	if addr := m.handler.ListenAddr(); addr != "" {
+		wait += " on " + addr
+	}
+	m.reconnect.prompt = components.NewSelectMenu(&protocol.SelectPayload{
+		Label: "Agent disconnected",
+		Options: []protocol.SelectOption{
+			{Label: "Wait", Value: disconnectedWait, Description: wait},
+			{Label: "Quit", Value: disconnectedQuit, Description: "Close the TUI"},
+		},
+	})
+	m.reconnect.prompt.SetWidth(m.width)
+	m.reconnect.resume = m.state
+	m.state = StateDisconnected
+}
+
+func (m *Model) closeDisconnectedPrompt() {
+	m.state = m.reconnect.resume
+	m.reconnect.prompt = nil
+}
+
+// updateDisconnectedPrompt passes msg to the prompt and carries out the
+// user's choice. Esc keeps waiting.
+func (m *Model) updateDisconnectedPrompt(msg tea.Msg) tea.Cmd {
+	if m.reconnect.prompt == nil {
+		return nil
+	}
+	cmd := m.reconnect.prompt.Update(msg)
+	if !m.reconnect.prompt.HasResponded() {
+		return cmd
+	}
+
+	choice := m.reconnect.prompt.GetSelected()
+	m.closeDisconnectedPrompt()
+	if choice == disconnectedQuit {
+		m.quitting = true
+		return tea.Quit
+	}
+	m.statusMessage = "Waiting for the agent to reconnect"
+	return cmd
+}
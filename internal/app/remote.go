@@ -0,0 +1,73 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// connEventMsg reports an agent connecting to or leaving a listening
+// handler.
+type connEventMsg struct {
+	event protocol.ConnEvent
+}
+
+// handleConnEvent tracks the connected agent. A newly connected agent is
+// sent the terminal size, since it missed the resizes before it arrived,
+// and liveness checks start over with each agent. See reconnect.go for
+// agents leaving and coming back.
+func (m *Model) handleConnEvent(ev protocol.ConnEvent) {
+	m.resetHeartbeat()
+	if !ev.Connected {
+		m.agentAddr = ""
+		// The exit of a spawned agent says more, whichever comes first
+		if ev.Addr != protocol.SpawnAddr {
+			m.statusMessage = "Agent at " + ev.Addr + " disconnected"
+		}
+		m.agentLeft(ev.Addr)
+		return
+	}
+
+	m.agentAddr = ev.Addr
+	m.statusMessage = "Agent connected from " + ev.Addr
+	if ev.Peer != "" {
+		// Verified by its client certificate
+		m.agentAddr = ev.Peer + "@" + ev.Addr
+		m.statusMessage = "Agent " + ev.Peer + " connected from " + ev.Addr
+	}
+	if ev.Addr == protocol.SpawnAddr {
+		m.statusMessage = "Agent started"
+	}
+	m.agentReturned()
+	if m.ready {
+		if err := m.handler.SendResize(m.width, m.height); err != nil {
+			m.setError("Failed to send resize", err.Error(), false)
+		}
+	}
+}
+
+// renderConnState renders the connection indicator shown in the status bar
+// when agents connect over the network.
+func (m Model) renderConnState() string {
+	listen := m.handler.ListenAddr()
+	if listen == "" {
+		return ""
+	}
+	colors := theme.Current.Colors
+	if m.agentAddr == "" && listen == protocol.SpawnAddr {
+		return lipgloss.NewStyle().Foreground(colors.Warning).Render(theme.Glyphs.Empty + " agent stopped")
+	}
+	if m.agentAddr == "" {
+		return lipgloss.NewStyle().Foreground(colors.Warning).Render(theme.Glyphs.Empty + " waiting on " + listen)
+	}
+	return lipgloss.NewStyle().Foreground(colors.Success).Render(theme.Glyphs.Filled + " " + m.agentAddr)
+}
+
+// notConnected reports whether err is only that no agent is connected yet,
+// which background notifications such as resizes can ignore.
+func notConnected(err error) bool {
+	return errors.Is(err, protocol.ErrNotConnected)
+}
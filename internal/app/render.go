@@ -0,0 +1,219 @@
+package app
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"runtime"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// asyncRenderBytes is the size above which markdown and code are rendered
+// by a background worker instead of inside Update.
+const asyncRenderBytes = 16 << 10
+
+// renderedMsg carries a finished background render back to Update.
+type renderedMsg struct {
+	key     uint64
+	content string
+	skipped bool // the chat stopped showing the message before it ran
+}
+
+// renderJob is one message waiting for a background render.
+type renderJob struct {
+	key   uint64
+	msg   Message
+	width int
+}
+
+// renderPool renders large assistant messages on a bounded number of
+// background workers. Finished renders are cached by content, width and
+// theme; until one arrives the chat shows a placeholder. It is shared by
+// every copy of the Model.
+type renderPool struct {
+	inline   bool          // render everything in Update, for headless use
+	slots    chan struct{} // limits how many renders run at once
+	degraded bool          // streaming messages render plain; see quality.go
+
+	mu       sync.Mutex
+	cache    map[uint64]string
+	wanted   map[uint64]bool // keys shown by the latest renderMessages
+	inflight map[uint64]bool
+	queued   []renderJob
+}
+
+// newRenderPool creates a pool with one worker per CPU. With inline set,
+// every message is rendered synchronously.
+func newRenderPool(inline bool) *renderPool {
+	return &renderPool{
+		inline:   inline,
+		slots:    make(chan struct{}, max(2, runtime.NumCPU())),
+		cache:    make(map[uint64]string),
+		wanted:   make(map[uint64]bool),
+		inflight: make(map[uint64]bool),
+	}
+}
+
+// beginPass starts a renderMessages pass. Cache entries not used by the
+// pass are dropped when it ends.
+func (p *renderPool) beginPass() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.wanted = make(map[uint64]bool)
+}
+
+// endPass drops renders the latest pass did not use, such as those for an
+// old width or an earlier version of a streamed message.
+func (p *renderPool) endPass() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key := range p.cache {
+		if !p.wanted[key] {
+			delete(p.cache, key)
+		}
+	}
+}
+
+// lookup returns the cached render for msg, queueing a job for it if there
+// is none. ok is false while the render is pending.
+func (p *renderPool) lookup(msg Message, width int) (content string, ok bool) {
+	key := renderKey(msg, width)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.wanted[key] = true
+	if content, ok := p.cache[key]; ok {
+		return content, true
+	}
+	if !p.inflight[key] {
+		p.inflight[key] = true
+		p.queued = append(p.queued, renderJob{key: key, msg: msg, width: width})
+	}
+	return "", false
+}
+
+// store caches a finished render.
+func (p *renderPool) store(r renderedMsg) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inflight, r.key)
+	if !r.skipped && p.wanted[r.key] {
+		p.cache[r.key] = r.content
+	}
+}
+
+// dispatch turns the jobs queued since the last call into commands.
+func (p *renderPool) dispatch() []tea.Cmd {
+	p.mu.Lock()
+	jobs := p.queued
+	p.queued = nil
+	p.mu.Unlock()
+
+	cmds := make([]tea.Cmd, len(jobs))
+	for i, job := range jobs {
+		cmds[i] = p.run(job)
+	}
+	return cmds
+}
+
+// run renders a job once a worker slot is free. Jobs the chat no longer
+// shows by then are skipped.
+func (p *renderPool) run(job renderJob) tea.Cmd {
+	return func() tea.Msg {
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+
+		p.mu.Lock()
+		wanted := p.wanted[job.key]
+		p.mu.Unlock()
+		if !wanted {
+			return renderedMsg{key: job.key, skipped: true}
+		}
+
+		// Glamour renderers are not safe for concurrent use, so each job
+		// gets its own views
+		markdownView, codeView := views.NewMarkdownView(), views.NewCodeView()
+		markdownView.SetWidth(job.width)
+		codeView.SetWidth(job.width)
+		return renderedMsg{key: job.key, content: renderAssistant(job.msg, markdownView, codeView)}
+	}
+}
+
+// renderKey identifies a render of msg at width in the current theme.
+func renderKey(msg Message, width int) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	writeInt := func(n int) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(n))
+		h.Write(buf[:])
+	}
+	writeInt(width)
+	if msg.IsCode {
+		writeInt(1)
+	} else {
+		writeInt(0)
+	}
+	writeInt(len(msg.ChangedLines))
+	for _, n := range msg.ChangedLines {
+		writeInt(n)
+	}
+	for _, s := range []string{theme.Current.Name, msg.Language, msg.Content} {
+		writeInt(len(s))
+		h.Write([]byte(s))
+	}
+	return h.Sum64()
+}
+
+// renderAssistantMessage renders an assistant message, handing large ones to
+// the render pool and showing a placeholder until they are ready. Only the
+// part within the content limits is rendered.
+func (m Model) renderAssistantMessage(msg Message) string {
+	if cut, shown := m.options.Limits.truncate(msg); shown != "" {
+		return m.renderAssistantMessage(cut) + "\n" + renderTruncation(shown, true)
+	}
+	if msg.Open && m.renders.degraded {
+		return m.renderPlain(msg)
+	}
+	if m.renders.inline || len(msg.Content) < asyncRenderBytes {
+		return renderAssistant(msg, m.markdownView, m.codeView)
+	}
+	if content, ok := m.renders.lookup(msg, m.width-4); ok {
+		return content
+	}
+
+	kind := "markdown"
+	if msg.IsCode {
+		kind = "code"
+	}
+	placeholder := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Italic(true).
+		Render("Rendering " + kind + " (" + formatBytes(int64(len(msg.Content))) + ")" + theme.Glyphs.Ellipsis)
+	if msg.IsCode {
+		return placeholder
+	}
+	return theme.Glyphs.Assistant + placeholder
+}
+
+// renderAssistant renders an assistant message with the given views: code
+// as a highlighted block, anything else as markdown with links.
+func renderAssistant(msg Message, markdownView *views.MarkdownView, codeView *views.CodeView) string {
+	if msg.IsCode {
+		codeView.SetCode(msg.Content)
+		codeView.SetLanguage(msg.Language)
+		codeView.SetChangedLines(msg.ChangedLines)
+		return codeView.View()
+	}
+
+	markdownView.SetContent(msg.Content)
+	rendered := markdownView.View()
+	// Add prefix to first line
+	lines := strings.SplitN(rendered, "\n", 2)
+	if len(lines) > 1 {
+		return views.Linkify(theme.Glyphs.Assistant + lines[0] + "\n" + lines[1])
+	}
+	return views.Linkify(theme.Glyphs.Assistant + rendered)
+}
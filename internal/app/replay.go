@@ -0,0 +1,53 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// Headless drives a model without a terminal. Commands returned by Update
+// are not run, so timers and animations do not make the output vary.
+type Headless struct {
+	model tea.Model
+}
+
+// Headless returns a driver for the model sized to width x height. Large
+// messages are rendered in place rather than in the background, so every
+// frame is complete, and message times are left out, so frames do not
+// depend on when they are rendered.
+func (m Model) Headless(width, height int) *Headless {
+	m.renders = newRenderPool(true)
+	m.showTimes = false
+	var model tea.Model = m
+	model, _ = model.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return &Headless{model: model}
+}
+
+// Apply feeds one protocol message to the model.
+func (h *Headless) Apply(msg *protocol.Message) {
+	h.model, _ = h.model.Update(protocolMsg{msg})
+}
+
+// View renders the current frame.
+func (h *Headless) View() string {
+	return h.model.View()
+}
+
+// Transcript renders the whole transcript at the model's width, without
+// the header, input and status bar of a frame or its scrolling.
+func (h *Headless) Transcript() string {
+	return h.model.(Model).renderMessages()
+}
+
+// ReplayFrames feeds recorded messages through the model at the given size
+// and returns the frame rendered after each one.
+func (m Model) ReplayFrames(msgs []*protocol.Message, width, height int) []string {
+	h := m.Headless(width, height)
+	frames := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		h.Apply(msg)
+		frames = append(frames, h.View())
+	}
+	return frames
+}
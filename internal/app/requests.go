@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// pendingRequest is the agent's request the user is answering: a form,
+// confirmation, selection, question, secret, autocomplete or diff review.
+// Only one is open at a time; a new request replaces the previous one.
+// These are the agent's requests, which the TUI answers, so they are
+// tracked here rather than with protocol.Handler.Request, which awaits the
+// agent's answers to the TUI's own.
+type pendingRequest struct {
+	id       string // echoed in the response
+	seq      int    // tells apart requests that reuse an ID
+	title    string // names the request when it times out
+	timeout  time.Duration
+	deadline time.Time // zero if the agent waits indefinitely
+
+	// The prompt's default answer, sent at answerBy unless the user
+	// touches the prompt first. answerBy is zero if there is none.
+	answer   string // names the answer in the countdown
+	answerIn time.Duration
+	answerBy time.Time
+}
+
+// requestTimeoutMsg fires when the agent stops waiting for request seq.
+type requestTimeoutMsg struct{ seq int }
+
+// requestCountdownMsg ticks the countdown to the default answer of
+// request seq.
+type requestCountdownMsg struct{ seq int }
+
+// openRequest records msg as the request being answered and schedules its
+// timeout, if the agent gave one.
+func (m *Model) openRequest(msg *protocol.Message, title string) tea.Cmd {
+	seq := m.request.seq + 1
+	m.request = pendingRequest{id: msg.ID, seq: seq, title: title}
+	if msg.Timeout <= 0 {
+		return nil
+	}
+	m.request.timeout = time.Duration(msg.Timeout * float64(time.Second))
+	m.request.deadline = time.Now().Add(m.request.timeout)
+	return tea.Tick(m.request.timeout, func(time.Time) tea.Msg {
+		return requestTimeoutMsg{seq: seq}
+	})
+}
+
+// closeRequest forgets the request once it has been answered.
+func (m *Model) closeRequest() {
+	m.request = pendingRequest{seq: m.request.seq}
+}
+
+// requestTimedOut closes the request the agent no longer waits for, so the
+// user does not answer into the void, and says so in the transcript.
+func (m *Model) requestTimedOut(seq int) {
+	if seq != m.request.seq || m.request.deadline.IsZero() {
+		return // answered in time, or replaced
+	}
+	title, timeout := m.request.title, m.request.timeout
+	m.closeRequest()
+	m.dismissRequest()
+
+	m.alertView.SetTitle(title + " timed out")
+	m.alertView.SetMessage(fmt.Sprintf("The agent stopped waiting for an answer after %s.", m.options.Clock.Duration(timeout)))
+	m.alertView.SetSeverity("warning")
+	m.messages = append(m.messages, Message{
+		Role:      "system",
+		Content:   m.alertView.View(),
+		Timestamp: time.Now(),
+	})
+	m.refreshMessages()
+	m.statusMessage = title + " timed out"
+}
+
+// answerByDefault starts the countdown to answering the request with its
+// default, named answer, if the agent set a timeout for it.
+func (m *Model) answerByDefault(seconds float64, answer string) tea.Cmd {
+	if seconds <= 0 {
+		return nil
+	}
+	m.request.answer = answer
+	m.request.answerIn = time.Duration(seconds * float64(time.Second))
+	m.request.answerBy = time.Now().Add(m.request.answerIn)
+	return m.countdownTick()
+}
+
+// countdownTick schedules the next tick of the countdown, when the number
+// of seconds left changes.
+func (m Model) countdownTick() tea.Cmd {
+	seq := m.request.seq
+	wait := time.Until(m.request.answerBy) % time.Second
+	if wait <= 0 {
+		wait = time.Second
+	}
+	return tea.Tick(wait, func(time.Time) tea.Msg {
+		return requestCountdownMsg{seq: seq}
+	})
+}
+
+// stopCountdown leaves the request for the user to answer, since they
+// have started to.
+func (m *Model) stopCountdown() {
+	m.request.answerBy = time.Time{}
+}
+
+// requestCountdown sends the default answer to request seq once its
+// countdown runs out, and otherwise keeps it ticking.
+func (m *Model) requestCountdown(seq int) tea.Cmd {
+	if seq != m.request.seq || m.request.answerBy.IsZero() {
+		return nil // answered, touched or replaced
+	}
+	if time.Now().Before(m.request.answerBy) {
+		return m.countdownTick()
+	}
+
+	var err error
+	switch {
+	case m.currentForm != nil:
+		err = m.handler.SendFormResponse(m.request.id, m.currentForm.GetValues(), true)
+	case m.currentConfirm != nil:
+		err = m.handler.SendConfirmResponse(m.request.id, m.currentConfirm.Default, true)
+	case m.currentSelect != nil:
+		err = m.handler.SendSelectResponse(m.request.id, m.currentSelect.GetSelected(), true)
+	default:
+		return nil
+	}
+	title, answer, after := m.request.title, m.request.answer, m.request.answerIn
+	m.closeRequest()
+	m.dismissRequest()
+	if err != nil {
+		m.setError("Failed to send default answer", err.Error(), false)
+		return nil
+	}
+
+	m.alertView.SetTitle(title + ": answered by default")
+	m.alertView.SetMessage(fmt.Sprintf("No answer after %s, so the agent was sent %s.", m.options.Clock.Duration(after), answer))
+	m.alertView.SetSeverity("info")
+	m.messages = append(m.messages, Message{
+		Role:      "system",
+		Content:   m.alertView.View(),
+		Timestamp: time.Now(),
+		Summary:   title + ": answered by default",
+	})
+	m.refreshMessages()
+	m.statusMessage = "Answered by default"
+	return nil
+}
+
+// withCountdown adds the countdown to the default answer, if running,
+// below the prompt view.
+func (m Model) withCountdown(view string) string {
+	if m.request.answerBy.IsZero() {
+		return view
+	}
+	left := max(int(math.Ceil(time.Until(m.request.answerBy).Seconds())), 0)
+	line := lipgloss.NewStyle().Foreground(theme.Current.Colors.Warning).Italic(true).PaddingLeft(2).
+		Render(fmt.Sprintf("Answering %s in %ds %s press a key to answer yourself",
+			m.request.answer, left, theme.Glyphs.Bullet))
+	return view + "\n" + line
+}
+
+// dismissRequest takes the prompt of a closed request off the screen.
+func (m *Model) dismissRequest() {
+	if m.currentSecret != nil {
+		m.currentSecret.Clear()
+	}
+	m.currentForm = nil
+	m.currentConfirm = nil
+	m.currentSelect = nil
+	m.currentSecret = nil
+	m.currentAutocomplete = nil
+	m.currentQuestion = nil
+	m.currentDiff = nil
+	if isRequestState(m.state) {
+		m.state = StateChat
+	}
+	// Prompts shown over the request go back to the chat instead
+	if isRequestState(m.heartbeat.resume) {
+		m.heartbeat.resume = StateChat
+	}
+	if isRequestState(m.reconnect.resume) {
+		m.reconnect.resume = StateChat
+	}
+}
+
+// isRequestState reports whether s shows one of the agent's requests.
+func isRequestState(s State) bool {
+	switch s {
+	case StateForm, StateConfirm, StateSelect, StateSecret, StateAutocomplete, StateDiff, StateQuestion:
+		return true
+	}
+	return false
+}
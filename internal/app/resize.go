@@ -0,0 +1,119 @@
+package app
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resizeDebounce is how long the terminal size must hold still before the
+// chat is reflowed and the agent is told the new size.
+const resizeDebounce = 100 * time.Millisecond
+
+// Heights of the fixed parts of the chat layout.
+const (
+	headerHeight = 3
+	footerHeight = 1
+)
+
+// resizeSettledMsg fires resizeDebounce after a resize. It is stale if
+// another resize has happened since.
+type resizeSettledMsg struct {
+	seq int
+}
+
+// scheduleResize records a new terminal size and waits for resizing to
+// settle. Until then the viewport is resized but keeps its old content, so
+// dragging a window edge does not re-render the transcript for every step.
+func (m *Model) scheduleResize(width, height int) tea.Cmd {
+	m.termWidth = width
+	width = m.chatWidth(width)
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = m.transcriptHeight()
+
+	m.resizeSeq++
+	seq := m.resizeSeq
+	return tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+		return resizeSettledMsg{seq: seq}
+	})
+}
+
+// applyResize lays the UI out for the given terminal size, reflows the
+// chat and tells the agent the size of the chat. Large messages reflow in
+// the background.
+func (m *Model) applyResize(width, height int) {
+	m.termWidth = width
+	width = m.chatWidth(width)
+	m.width = width
+	m.height = height
+
+	// Update input width, and its height, which the draft wraps to
+	m.input.SetWidth(width - 4)
+	m.fitInput()
+
+	// Update view widths
+	m.markdownView.SetWidth(width - 4)
+	m.tableView.SetWidth(width - 4)
+	m.codeView.SetWidth(width - 4)
+	m.setProgressWidth(width - 4)
+	m.alertView.SetWidth(width - 4)
+
+	// Update form width if present
+	if m.currentForm != nil {
+		m.currentForm.SetWidth(width)
+	}
+	if m.currentConfirm != nil {
+		m.currentConfirm.SetWidth(width)
+	}
+	if m.currentSelect != nil {
+		m.currentSelect.SetWidth(width)
+	}
+	if m.currentSecret != nil {
+		m.currentSecret.SetWidth(width)
+	}
+	if m.currentAutocomplete != nil {
+		m.currentAutocomplete.SetWidth(width)
+	}
+	if m.currentQuestion != nil {
+		m.currentQuestion.SetWidth(width)
+	}
+	if m.currentAttach != nil {
+		m.currentAttach.SetWidth(width)
+	}
+	if m.currentDiff != nil {
+		m.currentDiff.SetWidth(width)
+	}
+	if m.currentMenu != nil {
+		m.currentMenu.SetWidth(width)
+	}
+	if m.sessionPicker != nil {
+		m.sessionPicker.SetWidth(width)
+	}
+	if m.currentCompare != nil {
+		m.currentCompare.SetSize(width, height-headerHeight-footerHeight)
+	}
+	if m.currentPager != nil {
+		m.currentPager.SetSize(width, height-headerHeight-footerHeight)
+	}
+
+	// Update viewport size
+	viewportHeight := m.transcriptHeight()
+	if !m.ready {
+		m.viewport = viewport.New(width, viewportHeight)
+		m.viewport.SetContent(m.renderMessages())
+		m.ready = true
+	} else {
+		m.viewport.Width = width
+		m.viewport.Height = viewportHeight
+		m.redrawDashboards()
+		m.refreshMessages()
+	}
+
+	// Notify Python of resize
+	if err := m.handler.SendResize(width, height); err != nil && !notConnected(err) {
+		m.setError("Failed to send resize", err.Error(), false)
+	}
+}
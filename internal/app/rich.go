@@ -0,0 +1,114 @@
+package app
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// Patterns turning HTML into text.
+var (
+	htmlHidden = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)>`)
+	htmlBreak  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6]|table|pre|thead|tbody)>`)
+	htmlCell   = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTag    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// handleRich shows the best representation of a rich message that can be
+// rendered, trying the next best when one cannot, such as an image that
+// does not decode.
+func (m *Model) handleRich(msg *protocol.Message, p protocol.RichPayload) {
+	note := Message{Role: "system"}
+	rendered := false
+	for _, mime := range p.Representations() {
+		if note, rendered = m.richMessage(mime, p.Text(mime)); rendered {
+			break
+		}
+	}
+	if !rendered {
+		var mimes []string
+		for mime := range p.Data {
+			mimes = append(mimes, mime)
+		}
+		note.Content = lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Italic(true).
+			Render("Rich output this TUI cannot show: " + strings.Join(mimes, ", "))
+	}
+	note.Timestamp = time.Now()
+	note.ID = msg.ID
+	m.messages = append(m.messages, note)
+	m.showNewMessage()
+}
+
+// richMessage returns the transcript message showing text, a
+// representation of type mime, or false if it cannot be shown.
+func (m Model) richMessage(mime, text string) (Message, bool) {
+	if strings.TrimSpace(text) == "" {
+		return Message{}, false
+	}
+	switch mime {
+	case "text/markdown":
+		return Message{Role: "assistant", Content: text}, true
+
+	case "image/png", "image/jpeg", "image/gif":
+		return m.richImage(text)
+
+	case "text/latex":
+		tex := strings.TrimSpace(text)
+		for _, delims := range [][2]string{{"$$", "$$"}, {`\[`, `\]`}, {"$", "$"}} {
+			if inner, ok := strings.CutPrefix(tex, delims[0]); ok && strings.HasSuffix(inner, delims[1]) {
+				tex = strings.TrimSuffix(inner, delims[1])
+				break
+			}
+		}
+		view := views.NewMathView()
+		view.SetTex(tex)
+		view.SetWidth(m.width)
+		return Message{Role: "system", Content: view.View()}, true
+
+	case "application/json":
+		return Message{Role: "assistant", Content: text, IsCode: true, Language: "json"}, true
+
+	case "text/plain":
+		return Message{Role: "assistant", Content: strings.TrimRight(text, "\n"), IsCode: true, Language: "text"}, true
+
+	case "text/html":
+		text = htmlText(text)
+		if text == "" {
+			return Message{}, false
+		}
+		return Message{Role: "assistant", Content: text, IsCode: true, Language: "text"}, true
+	}
+	return Message{}, false
+}
+
+// htmlText reduces HTML to its text, a line to each paragraph, row or
+// list item, with table cells spaced apart.
+func htmlText(s string) string {
+	s = htmlHidden.ReplaceAllString(s, "")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlCell.ReplaceAllString(s, "  ")
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+
+	var lines []string
+	blank := true
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,33 @@
+//go:build !noimages
+
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	_ "image/gif" // decoders for rich images
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// richImage returns the transcript message drawing text, a base64 image,
+// in half blocks, or false if it does not decode. Build with the noimages
+// tag to leave the image decoders out of the binary.
+func (m Model) richImage(text string) (Message, bool) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	if err != nil {
+		return Message{}, false
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Message{}, false
+	}
+	view := views.NewImageView()
+	view.SetImage(img)
+	view.SetWidth(m.width - 4)
+	return Message{Role: "system", Content: view.View()}, true
+}
@@ -0,0 +1,9 @@
+//go:build noimages
+
+package app
+
+// richImage cannot draw images in noimages builds, which do not list them
+// among the rich representations they render.
+func (m Model) richImage(string) (Message, bool) {
+	return Message{}, false
+}
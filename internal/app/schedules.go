@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/schedule"
+)
+
+// scheduleFiredMsg fires when schedule index of generation gen is due.
+// Clearing the schedules starts a new generation, so their timers are
+// ignored.
+type scheduleFiredMsg struct {
+	gen   int
+	index int
+	at    time.Time
+}
+
+// armSchedules starts the timers of every schedule.
+func (m Model) armSchedules() tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.schedules))
+	for i := range m.schedules {
+		cmds[i] = m.armSchedule(i, time.Now())
+	}
+	return tea.Batch(cmds...)
+}
+
+// armSchedule waits for the first time after after that schedule i fires.
+func (m Model) armSchedule(i int, after time.Time) tea.Cmd {
+	if m.options.ReadOnly {
+		return nil
+	}
+	next := m.schedules[i].Next(after)
+	if next.IsZero() {
+		return nil
+	}
+	gen := m.scheduleGen
+	return tea.Tick(time.Until(next), func(time.Time) tea.Msg {
+		return scheduleFiredMsg{gen: gen, index: i, at: next}
+	})
+}
+
+// fireSchedule sends the prompt of the schedule that fired, or a tick if
+// it has none, and waits for it to fire again. A prompt due while the
+// agent is still answering, or the user is answering it, is skipped, as
+// the user could not have sent it then either.
+func (m *Model) fireSchedule(msg scheduleFiredMsg) tea.Cmd {
+	if msg.gen != m.scheduleGen || msg.index >= len(m.schedules) {
+		return nil
+	}
+	s := m.schedules[msg.index]
+	switch {
+	case s.Prompt == "":
+		if err := m.handler.SendTick(s.When, msg.at); err != nil && !notConnected(err) {
+			m.setError("Failed to send tick", err.Error(), true)
+		}
+	case m.isStreaming || m.state != StateChat:
+		m.statusMessage = "Skipped scheduled prompt: " + s.When
+	default:
+		m.submitInput(s.Prompt)
+	}
+	return m.armSchedule(msg.index, msg.at)
+}
+
+// scheduleCommand runs /schedule with the given arguments: none lists the
+// schedules, "clear" removes them and anything else adds a schedule.
+func (m *Model) scheduleCommand(args string) tea.Cmd {
+	switch args {
+	case "", "list":
+		m.addReport("Schedules", m.describeSchedules())
+		return nil
+	case "clear":
+		m.schedules = nil
+		m.scheduleGen++
+		m.statusMessage = "Schedules cleared"
+		return nil
+	}
+	s, err := schedule.Parse(args)
+	if err != nil {
+		m.statusMessage = "Invalid schedule: " + err.Error()
+		return nil
+	}
+	m.schedules = append(m.schedules, s)
+	m.statusMessage = "Scheduled " + s.When
+	return m.armSchedule(len(m.schedules)-1, time.Now())
+}
+
+// describeSchedules lists the schedules with when each next fires.
+func (m Model) describeSchedules() string {
+	if len(m.schedules) == 0 {
+		return "No schedules. Add one with /schedule every 15m: <prompt>, or leave out the prompt to send ticks."
+	}
+	var lines []string
+	now := time.Now()
+	for i, s := range m.schedules {
+		what := "tick"
+		if s.Prompt != "" {
+			what = fmt.Sprintf("%q", s.Prompt)
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s: %s, next at %s", i+1, s.When, what, m.options.Clock.Time(s.Next(now))))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,324 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// searchHit is a match of the search query in the rendered transcript: a
+// line and the cells it spans on it.
+type searchHit struct {
+	line       int
+	start, end int
+}
+
+// transcriptSearch finds a query in the transcript as rendered, opened
+// with ctrl+f. While typing, each keystroke searches again and scrolls to
+// the first hit from where the transcript was; after Enter, n and N move
+// between hits. Hits are found again whenever the transcript changes.
+type transcriptSearch struct {
+	open   bool // the search bar replaces the input
+	typing bool // keys edit the query rather than move between hits
+	input  textinput.Model
+
+	hits    []searchHit
+	current int
+	// What hits were found in, to find them again when either changes
+	query   string
+	content string
+}
+
+// openSearch shows the search bar, keeping the last query to edit.
+func (m *Model) openSearch() {
+	s := m.search
+	if !s.open {
+		s.input = textinput.New()
+		s.input.Prompt = "Find: "
+		s.input.Placeholder = "text in the transcript"
+		s.input.SetValue(s.query)
+		s.input.CursorEnd()
+	}
+	if !s.open {
+		s.current = -1
+	}
+	s.open = true
+	s.typing = true
+	s.input.Focus()
+	m.input.Blur()
+}
+
+// closeSearch hides the search bar and its highlights, giving the keyboard
+// back to the input.
+func (m *Model) closeSearch() {
+	m.search.open = false
+	m.search.typing = false
+	m.search.input.Blur()
+	m.setFocus(m.focused())
+}
+
+// updateSearch handles a key while the search bar is open.
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.search
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.closeSearch()
+		return m, nil
+	case "ctrl+f", "/":
+		if !s.typing {
+			m.openSearch()
+			return m, nil
+		}
+	case "enter":
+		if s.typing {
+			s.typing = false
+			s.input.Blur()
+			return m, nil
+		}
+		m.jumpToHit(1)
+		return m, nil
+	case "down", "ctrl+n":
+		m.jumpToHit(1)
+		return m, nil
+	case "up", "ctrl+p":
+		m.jumpToHit(-1)
+		return m, nil
+	case "pgup":
+		m.viewport.LineUp(10)
+		return m, nil
+	case "pgdown":
+		m.viewport.LineDown(10)
+		return m, nil
+	}
+	if !s.typing {
+		switch msg.String() {
+		case "n":
+			m.jumpToHit(1)
+		case "N":
+			m.jumpToHit(-1)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	if s.input.Value() != s.query {
+		// Search as the query is typed, from the top of the view
+		s.find(s.input.Value(), m.layout.rendered)
+		s.current = -1
+		m.jumpToHit(1)
+	}
+	return m, cmd
+}
+
+// find finds query in the rendered transcript content, unless it was the
+// last search. The search ignores case unless the query has capitals.
+func (s *transcriptSearch) find(query, content string) {
+	if query == s.query && content == s.content {
+		return
+	}
+	s.query, s.content = query, content
+	s.hits = s.hits[:0]
+	if query == "" {
+		s.current = -1
+		return
+	}
+	fold := !strings.ContainsFunc(query, unicode.IsUpper)
+	if fold {
+		query = strings.ToLower(query)
+	}
+	for i, line := range strings.Split(content, "\n") {
+		text := ansi.Strip(line)
+		if fold {
+			text = strings.ToLower(text)
+		}
+		for at := 0; ; {
+			j := strings.Index(text[at:], query)
+			if j < 0 {
+				break
+			}
+			start := ansi.StringWidth(text[:at+j])
+			s.hits = append(s.hits, searchHit{line: i, start: start, end: start + ansi.StringWidth(query)})
+			at += j + len(query)
+		}
+	}
+	s.current = min(s.current, len(s.hits)-1)
+}
+
+// jumpToHit scrolls to the next hit after the current one, or the previous
+// one if step is -1, wrapping around. With no current hit, the next is the
+// first at or below the top of the view.
+func (m *Model) jumpToHit(step int) {
+	s := m.search
+	s.find(s.input.Value(), m.layout.rendered)
+	if len(s.hits) == 0 {
+		return
+	}
+	if s.current < 0 {
+		s.current = len(s.hits) - 1
+		for i, hit := range s.hits {
+			if hit.line >= m.viewport.YOffset {
+				s.current = i - 1
+				break
+			}
+		}
+		if step < 0 {
+			s.current++
+		}
+	}
+	s.current = (s.current + step + len(s.hits)) % len(s.hits)
+
+	// Keep the hit a third of the way down the view
+	line := s.hits[s.current].line
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height/3)
+	}
+}
+
+// highlightSearch highlights the hits in view, the visible lines of the
+// transcript from the top line on.
+func (m Model) highlightSearch(view string, top int) string {
+	s := m.search
+	if !s.open || s.input.Value() == "" {
+		return view
+	}
+	s.find(s.input.Value(), m.layout.rendered)
+	if len(s.hits) == 0 {
+		return view
+	}
+
+	colors := theme.Current.Colors
+	match := lipgloss.NewStyle().Background(colors.Warning).Foreground(colors.Background)
+	current := lipgloss.NewStyle().Background(colors.Primary).Foreground(colors.Background).Bold(true)
+
+	lines := strings.Split(view, "\n")
+	for i, hit := range s.hits {
+		row := hit.line - top
+		if row < 0 || row >= len(lines) {
+			continue
+		}
+		style := match
+		if i == s.current {
+			style = current
+		}
+		lines[row] = highlightCells(lines[row], hit.start, hit.end, style)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightCells renders the cells of line from start to end, exclusive,
+// in style, keeping the styling of the rest of the line: the SGR sequences
+// in effect are written again after the span, since the highlight ends
+// with a reset. Other escape sequences in the span, such as hyperlinks,
+// follow it.
+func highlightCells(line string, start, end int, style lipgloss.Style) string {
+	var out, span, held, sgr strings.Builder
+	flush := func() {
+		out.WriteString(style.Render(span.String()))
+		out.WriteString(sgr.String())
+		out.WriteString(held.String())
+		span.Reset()
+	}
+	col := 0
+	for i := 0; i < len(line); {
+		inSpan := col >= start && col < end
+		if line[i] == ansi.ESC {
+			seq := line[i : i+escapeLen(line[i:])]
+			if strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m") {
+				if seq == "\x1b[0m" || seq == "\x1b[m" {
+					sgr.Reset()
+				} else {
+					sgr.WriteString(seq)
+				}
+			}
+			switch {
+			case !inSpan:
+				out.WriteString(seq)
+			case !strings.HasSuffix(seq, "m"):
+				held.WriteString(seq)
+			}
+			i += len(seq)
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		char := line[i : i+size]
+		width := ansi.StringWidth(char)
+		if inSpan {
+			span.WriteString(char)
+			if col+width >= end {
+				flush()
+			}
+		} else {
+			out.WriteString(char)
+		}
+		col += width
+		i += size
+	}
+	if span.Len() > 0 {
+		flush()
+	}
+	return out.String()
+}
+
+// escapeLen returns the length of the escape sequence s starts with: a
+// control sequence, an operating system command such as a hyperlink, or
+// an escape and one character.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		for j := 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+	case ']':
+		for j := 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return j + 1
+			}
+			if s[j] == ansi.ESC && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
+
+// renderSearchBar renders the search bar shown instead of the input.
+func (m Model) renderSearchBar() string {
+	s := m.search
+	hint := "Enter to browse hits"
+	if !s.typing {
+		hint = "n/N next/previous " + theme.Glyphs.Bullet + " / to edit"
+	}
+	hint += " " + theme.Glyphs.Bullet + " Esc to close"
+	return s.input.View() + "  " + lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Render(m.searchCount()+theme.Glyphs.Separator+hint)
+}
+
+// searchCount describes the hits for the status bar: which one is shown
+// out of how many.
+func (m Model) searchCount() string {
+	s := m.search
+	switch {
+	case s.input.Value() == "":
+		return "Type to search"
+	case len(s.hits) == 0:
+		return "No matches"
+	case s.current < 0:
+		return fmt.Sprintf("%d matches", len(s.hits))
+	}
+	return fmt.Sprintf("Match %d of %d", s.current+1, len(s.hits))
+}
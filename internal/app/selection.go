@@ -0,0 +1,271 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/browser"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/components"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// deletedMessage is a message deleted last, kept with where it was so it
+// can be put back.
+type deletedMessage struct {
+	index int
+	msg   Message
+}
+
+// startSelecting selects the message nearest the bottom of the view, for
+// the user to move between messages and act on one. The transcript panel
+// has focus meanwhile.
+func (m *Model) startSelecting() {
+	if len(m.messages) == 0 {
+		return
+	}
+	bottom := m.viewport.YOffset + m.viewport.Height
+	m.selected = 0
+	for i, start := range m.layout.starts {
+		if start < bottom {
+			m.selected = min(i, len(m.messages)-1)
+		}
+	}
+	m.selecting = true
+	m.showSelection()
+}
+
+// stopSelecting leaves message selection.
+func (m *Model) stopSelecting() {
+	if !m.selecting {
+		return
+	}
+	m.selecting = false
+	m.statusMessage = ""
+	m.refreshMessages()
+}
+
+// moveSelection selects the next message, or the previous one if step is
+// -1, scrolling to its start if it is out of view.
+func (m *Model) moveSelection(step int) {
+	m.selected = max(0, min(m.selected+step, len(m.messages)-1))
+	m.showSelection()
+}
+
+// showSelection marks the selected message and scrolls to it.
+func (m *Model) showSelection() {
+	m.refreshMessages()
+	if m.selected < len(m.layout.starts) {
+		start := m.layout.starts[m.selected]
+		if start < m.viewport.YOffset || start >= m.viewport.YOffset+m.viewport.Height {
+			m.viewport.SetYOffset(start)
+		}
+	}
+	m.statusMessage = fmt.Sprintf("Message %d of %d", m.selected+1, len(m.messages))
+}
+
+// updateSelection handles a key in the transcript panel while a message
+// is selected.
+func (m *Model) updateSelection(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "u" {
+		m.undoDelete()
+		return nil
+	}
+	if m.selected >= len(m.messages) {
+		return nil
+	}
+	switch msg.String() {
+	case "up", "k":
+		m.moveSelection(-1)
+	case "down", "j":
+		m.moveSelection(1)
+	case "home", "g":
+		m.moveSelection(-len(m.messages))
+	case "end", "G":
+		m.moveSelection(len(m.messages))
+	case "v":
+		m.stopSelecting()
+	case "o":
+		switch {
+		case m.selected < len(m.layout.foldable) && m.layout.foldable[m.selected]:
+			m.toggleFold(m.selected)
+		case m.options.Limits.truncated(m.messages[m.selected]):
+			m.openFullView(m.messages[m.selected])
+		}
+	case "y":
+		text, what := copyText(m.messages[m.selected])
+		return copyCmd(text, what)
+	case "c":
+		m.messages[m.selected].Collapsed = !m.messages[m.selected].Collapsed
+		m.showSelection()
+	case "r":
+		m.reask(m.selected)
+	case "d":
+		m.deleteMessage(m.selected)
+	case "l":
+		m.openLinks(m.selected)
+	case "p":
+		m.togglePinned(m.selected)
+	case "enter":
+		m.openMessageMenu()
+	}
+	return nil
+}
+
+// renderSelected renders the line above the selected message, with the
+// keys that act on it, or nothing for other messages.
+func (m Model) renderSelected(i int) string {
+	if !m.selecting || i != m.selected {
+		return ""
+	}
+	msg := m.messages[i]
+	keys := []string{"y to copy"}
+	if msg.IsCode {
+		keys[0] = "y to copy the code"
+	}
+	if msg.Collapsed {
+		keys = append(keys, "c to expand")
+	} else {
+		keys = append(keys, "c to collapse")
+	}
+	if m.askedBefore(i) >= 0 {
+		keys = append(keys, "r to ask again")
+	}
+	keys = append(keys, "d to delete")
+	if msg.Pinned {
+		keys = append(keys, "p to unpin")
+	} else {
+		keys = append(keys, "p to pin")
+	}
+	if len(messageURLs(msg)) > 0 {
+		keys = append(keys, "l to open links")
+	}
+	switch {
+	case i < len(m.layout.foldable) && m.layout.foldable[i]:
+		if msg.Unfolded {
+			keys = append(keys, "o to fold")
+		} else {
+			keys = append(keys, "o to expand")
+		}
+	case m.options.Limits.truncated(msg):
+		keys = append(keys, "o to open")
+	}
+	keys = append(keys, "Enter for more", "Esc to stop")
+	return theme.Current.Styles.Focused.Render(theme.Glyphs.Focus+"Selected") + " " +
+		lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Render(strings.Join(keys, theme.Glyphs.Separator)) + "\n"
+}
+
+// askedBefore returns the index of the user's input at or before message
+// i, which asking again sends once more, or -1 if there is none.
+func (m Model) askedBefore(i int) int {
+	for ; i >= 0; i-- {
+		if m.messages[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
+// reask sends the user's input that led to message i to the agent again,
+// as a new turn at the bottom of the transcript.
+func (m *Model) reask(i int) {
+	j := m.askedBefore(i)
+	switch {
+	case j < 0:
+		m.statusMessage = "No input to ask again"
+	case m.options.ReadOnly:
+		m.statusMessage = "This session is read-only"
+	case m.isStreaming:
+		m.statusMessage = "Wait for the agent to finish replying"
+	default:
+		m.stopSelecting()
+		m.setFocus(PanelInput)
+		m.submitInput(m.messages[j].Content)
+	}
+}
+
+// deleteMessage removes message i from the transcript. Only the view
+// changes: the agent and the saved session keep it. u puts it back.
+func (m *Model) deleteMessage(i int) {
+	m.deleted = []deletedMessage{{index: i, msg: m.messages[i]}}
+	m.messages = slices.Delete(m.messages, i, i+1)
+	if len(m.messages) == 0 {
+		m.stopSelecting()
+		m.setFocus(PanelInput)
+		m.statusMessage = "Deleted the message"
+		return
+	}
+	m.selected = min(i, len(m.messages)-1)
+	m.showSelection()
+	m.statusMessage = "Deleted the message" + theme.Glyphs.Separator + "u to undo"
+}
+
+// undoDelete puts back the messages deleted or cleared last.
+func (m *Model) undoDelete() {
+	if len(m.deleted) == 0 {
+		m.statusMessage = "Nothing to undo"
+		return
+	}
+	// In order of index, so each goes back where it was
+	i := 0
+	for _, d := range m.deleted {
+		i = min(d.index, len(m.messages))
+		m.messages = slices.Insert(m.messages, i, d.msg)
+	}
+	restored := len(m.deleted)
+	m.deleted = nil
+	if m.selecting {
+		m.selected = i
+		m.showSelection()
+		return
+	}
+	m.refreshMessages()
+	m.statusMessage = fmt.Sprintf("Put back %d messages", restored)
+	if restored == 1 {
+		m.statusMessage = "Put back the message"
+	}
+}
+
+// messageURLs returns the links in a message.
+func messageURLs(msg Message) []string {
+	return views.URLs(ansi.Strip(msg.Content))
+}
+
+// openLinks opens the link in message i in the browser, or lets the user
+// pick one if it has several.
+func (m *Model) openLinks(i int) {
+	urls := messageURLs(m.messages[i])
+	switch len(urls) {
+	case 0:
+		m.statusMessage = "No links in this message"
+	case 1:
+		m.openLink(urls[0])
+	default:
+		options := make([]string, len(urls))
+		for i, url := range urls {
+			options[i] = string(linkAction(url))
+		}
+		m.currentMenu = components.NewSelectMenu(&protocol.SelectPayload{
+			Label:   "Open link",
+			Options: protocol.OptionsFromStrings(options),
+		})
+		m.currentMenu.SetWidth(m.width)
+		m.menuTarget = i
+		m.state = StateMenu
+	}
+}
+
+// openLink opens url in the browser.
+func (m *Model) openLink(url string) {
+	if err := browser.Open(url); err != nil {
+		m.setError("Cannot open link", err.Error(), false)
+		return
+	}
+	m.statusMessage = "Opened " + url
+}
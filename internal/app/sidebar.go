@@ -0,0 +1,277 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/history"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// The sidebar is shown left of the chat, sidebarGap away from it, when
+// the chat can stay minChatWidth wide beside it.
+const (
+	sidebarWidth = 28
+	sidebarGap   = 2
+)
+
+// The most sessions and tool calls the sidebar lists; tool calls that
+// finished are forgotten first.
+const (
+	maxSidebarSessions = 5
+	maxToolCalls       = 8
+)
+
+// sidebar is the state of the sidebar: an overview of recent sessions,
+// the agent's tool calls and the pinned artifacts, which ctrl+b shows and
+// hides. Sessions are listed when it opens, not on every frame.
+type sidebar struct {
+	open     bool
+	selected int
+	sessions []history.Info
+}
+
+// toolCall is a tool call the agent reported.
+type toolCall struct {
+	id      string
+	name    string
+	status  string
+	detail  string
+	agent   string
+	started time.Time
+	ended   time.Time
+}
+
+// sidebarItem is an entry of the sidebar that can be selected.
+type sidebarItem struct {
+	section string // "sessions", "tools" or "pinned"
+	index   int    // into the section's list
+}
+
+// sidebarWidthFor returns how wide the sidebar is beside a terminal
+// termWidth wide, or 0 if it is not shown.
+func (m Model) sidebarWidthFor(termWidth int) int {
+	if !m.sidebar.open || termWidth-sidebarWidth-sidebarGap < minChatWidth {
+		return 0
+	}
+	return sidebarWidth
+}
+
+// sidebarSpace returns how many columns the sidebar takes, with its gap.
+func (m Model) sidebarSpace() int {
+	if width := m.sidebarWidthFor(m.termWidth); width > 0 {
+		return width + sidebarGap
+	}
+	return 0
+}
+
+// toggleSidebar shows or hides the sidebar.
+func (m *Model) toggleSidebar() {
+	m.sidebar.open = !m.sidebar.open
+	if m.sidebar.open {
+		m.listSidebarSessions()
+		if m.sidebarWidthFor(m.termWidth) == 0 {
+			m.statusMessage = "Widen the terminal to show the sidebar"
+		}
+	} else if m.focused() == PanelSidebar {
+		m.setFocus(PanelInput)
+	}
+	m.layoutArtifacts()
+}
+
+// listSidebarSessions lists the most recent sessions other than this one.
+func (m *Model) listSidebarSessions() {
+	m.sidebar.sessions = nil
+	if m.options.History == nil {
+		return
+	}
+	sessions, err := m.options.History.Store().List()
+	if err != nil {
+		return
+	}
+	current := m.options.History.Name()
+	for _, s := range sessions {
+		if s.Name != current && len(m.sidebar.sessions) < maxSidebarSessions {
+			m.sidebar.sessions = append(m.sidebar.sessions, s)
+		}
+	}
+}
+
+// handleTool records a tool call the agent started or finished.
+func (m *Model) handleTool(msg *protocol.Message, payload protocol.ToolPayload) {
+	i := slices.IndexFunc(m.tools, func(t toolCall) bool { return t.id == payload.ID })
+	if i < 0 {
+		m.tools = append(m.tools, toolCall{id: payload.ID, started: time.Now()})
+		i = len(m.tools) - 1
+	}
+	t := &m.tools[i]
+	t.name = payload.Name
+	t.status = payload.Status
+	t.detail = payload.Detail
+	t.agent = msg.AgentID
+	if payload.Status != protocol.ToolRunning && t.ended.IsZero() {
+		t.ended = time.Now()
+	}
+	if payload.Status == protocol.ToolRunning {
+		m.statusMessage = "Running " + payload.Name
+	}
+
+	for len(m.tools) > maxToolCalls {
+		j := slices.IndexFunc(m.tools, func(t toolCall) bool { return t.status != protocol.ToolRunning })
+		if j < 0 {
+			j = 0
+		}
+		m.tools = slices.Delete(m.tools, j, j+1)
+	}
+}
+
+// sidebarItems returns the entries of the sidebar in the order they are
+// shown.
+func (m Model) sidebarItems() []sidebarItem {
+	var items []sidebarItem
+	for i := range m.sidebar.sessions {
+		items = append(items, sidebarItem{"sessions", i})
+	}
+	for i := range m.tools {
+		items = append(items, sidebarItem{"tools", i})
+	}
+	for i := range m.artifacts {
+		items = append(items, sidebarItem{"pinned", i})
+	}
+	return items
+}
+
+// updateSidebar handles a key while the sidebar has focus: Up and Down
+// move through its entries, Enter opens the one selected and Esc goes back
+// to the input.
+func (m *Model) updateSidebar(msg tea.KeyMsg) tea.Cmd {
+	items := m.sidebarItems()
+	m.sidebar.selected = max(0, min(m.sidebar.selected, len(items)-1))
+	switch msg.String() {
+	case "up", "k":
+		m.sidebar.selected = max(0, m.sidebar.selected-1)
+	case "down", "j":
+		m.sidebar.selected = max(0, min(m.sidebar.selected+1, len(items)-1))
+	case "esc":
+		m.setFocus(PanelInput)
+	case "enter":
+		if m.sidebar.selected < len(items) {
+			m.openSidebarItem(items[m.sidebar.selected])
+		}
+	}
+	return nil
+}
+
+// openSidebarItem reopens a session, describes a tool call or shows a
+// pinned artifact.
+func (m *Model) openSidebarItem(item sidebarItem) {
+	switch item.section {
+	case "sessions":
+		if m.isStreaming {
+			m.statusMessage = "Wait for the agent to finish before reopening a session"
+			return
+		}
+		*m = m.reopenSession(m.sidebar.sessions[item.index].Name)
+		m.listSidebarSessions()
+		m.sidebar.selected = 0
+	case "tools":
+		t := m.tools[item.index]
+		m.statusMessage = strings.TrimSpace(t.name + " " + t.status + ": " + t.detail)
+	case "pinned":
+		if m.artifactsWidth() > 0 {
+			m.artifactTab = item.index
+			m.artifactScroll = 0
+			m.setFocus(PanelArtifacts)
+		} else {
+			m.openFullView(m.artifacts[item.index].msg)
+		}
+	}
+}
+
+// renderSidebar renders the sidebar, height lines tall.
+func (m Model) renderSidebar(width, height int) string {
+	colors := theme.Current.Colors
+	heading := lipgloss.NewStyle().Bold(true).Foreground(colors.Primary)
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	focused := m.focused() == PanelSidebar
+	selected := -1
+	if focused {
+		selected = m.sidebar.selected
+	}
+
+	n := 0
+	var lines []string
+	item := func(icon, label string) {
+		marker := theme.Glyphs.Blur
+		if n == selected {
+			marker = theme.Glyphs.Focus
+		}
+		line := ansi.Truncate(marker+icon+label, width, theme.Glyphs.Ellipsis)
+		if n == selected {
+			line = lipgloss.NewStyle().Bold(true).Foreground(colors.Text).Render(line)
+		}
+		lines = append(lines, line)
+		n++
+	}
+	section := func(title string, empty string, count int) {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, heading.Render(title))
+		if count == 0 {
+			lines = append(lines, muted.Render(theme.Glyphs.Blur+empty))
+		}
+	}
+
+	section("Sessions", "none saved", len(m.sidebar.sessions))
+	for _, s := range m.sidebar.sessions {
+		item("", sessionTitle(s))
+	}
+	section("Activity", "no tool calls", len(m.tools))
+	for _, t := range m.tools {
+		icon := lipgloss.NewStyle().Foreground(colors.Warning).Render(theme.Glyphs.Filled) + " "
+		label := t.name
+		switch t.status {
+		case protocol.ToolDone:
+			icon = lipgloss.NewStyle().Foreground(colors.Success).Render(theme.Glyphs.Success) + " "
+			label += muted.Render(" " + t.ended.Sub(t.started).Round(time.Second).String())
+		case protocol.ToolFailed:
+			icon = lipgloss.NewStyle().Foreground(colors.Error).Render(theme.Glyphs.Error) + " "
+		}
+		if t.agent != "" {
+			label = t.agent + ": " + label
+		}
+		item(icon, label)
+	}
+	section("Pinned", "nothing pinned", len(m.artifacts))
+	for _, a := range m.artifacts {
+		item("", a.title)
+	}
+	if focused {
+		lines = append(lines, "", muted.Render(fmt.Sprintf("Enter opens%sEsc leaves", theme.Glyphs.Separator)))
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		Foreground(colors.Text).
+		Render(strings.Join(lines, "\n"))
+}
+
+// sessionTitle names a session by the first input sent in it.
+func sessionTitle(s history.Info) string {
+	if s.Title == "" {
+		return "(nothing sent)"
+	}
+	return s.Title
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// startupGate keeps the input closed until the agent sends its first
+// message, usually its hello, so nothing is typed before anyone is there
+// to read it.
+type startupGate struct {
+	waiting     bool
+	placeholder string // the input's placeholder once the agent is ready
+
+	// The prompt shown when the agent takes too long to start, and the
+	// state to go back to when it is dismissed
+	prompt *components.SelectMenu
+	resume State
+}
+
+// Choices offered when the agent takes too long to start.
+const (
+	startupWait = "wait"
+	startupType = "type"
+	startupQuit = "quit"
+)
+
+// startupTimeoutMsg fires when the agent has had StartupTimeout to start.
+type startupTimeoutMsg struct{}
+
+// startupTimer waits StartupTimeout for the agent to start.
+func (m Model) startupTimer() tea.Cmd {
+	if !m.startup.waiting {
+		return nil
+	}
+	return tea.Tick(m.options.StartupTimeout, func(time.Time) tea.Msg {
+		return startupTimeoutMsg{}
+	})
+}
+
+// waitForAgent closes the input until the agent is ready.
+func (m *Model) waitForAgent() {
+	m.startup.waiting = true
+	m.startup.placeholder = m.input.Placeholder
+	m.input.Placeholder = "Waiting for the agent to start" + theme.Glyphs.Ellipsis
+}
+
+// agentReady opens the input when the first message from the agent
+// arrives.
+func (m *Model) agentReady() {
+	if !m.startup.waiting {
+		return
+	}
+	m.openInput()
+	m.statusMessage = "Agent ready"
+}
+
+// openInput lets the user type, whether or not the agent is ready.
+func (m *Model) openInput() {
+	m.startup.waiting = false
+	m.input.Placeholder = m.startup.placeholder
+	if m.state == StateStartupTimeout {
+		m.closeStartupPrompt()
+	}
+}
+
+// handleStartupTimeout shows what to check when the agent has not started
+// in time.
+func (m *Model) handleStartupTimeout() {
+	if !m.startup.waiting || m.state == StateStartupTimeout {
+		return
+	}
+	m.startup.prompt = components.NewSelectMenu(&protocol.SelectPayload{
+		Label: "The agent has not started",
+		Options: []protocol.SelectOption{
+			{Label: "Wait", Value: startupWait, Description: "Keep waiting; the input opens when the agent is ready"},
+			{Label: "Type anyway", Value: startupType, Description: "Open the input; what you send waits for the agent"},
+			{Label: "Quit", Value: startupQuit, Description: "Close the TUI"},
+		},
+	})
+	m.startup.prompt.SetWidth(m.width)
+	m.startup.resume = m.state
+	m.state = StateStartupTimeout
+}
+
+func (m *Model) closeStartupPrompt() {
+	m.state = m.startup.resume
+	m.startup.prompt = nil
+}
+
+// updateStartupPrompt passes msg to the prompt and carries out the user's
+// choice. Esc keeps waiting.
+func (m *Model) updateStartupPrompt(msg tea.Msg) tea.Cmd {
+	if m.startup.prompt == nil {
+		return nil
+	}
+	cmd := m.startup.prompt.Update(msg)
+	if !m.startup.prompt.HasResponded() {
+		return cmd
+	}
+
+	choice := m.startup.prompt.GetSelected()
+	m.closeStartupPrompt()
+	switch choice {
+	case startupType:
+		m.openInput()
+		m.statusMessage = "The agent has not started; what you send waits for it"
+	case startupQuit:
+		m.quitting = true
+		m.handler.SendQuit()
+		return tea.Quit
+	default:
+		// Ask again if it takes as long again
+		m.statusMessage = "Waiting for the agent to start"
+		return tea.Batch(cmd, m.startupTimer())
+	}
+	return cmd
+}
+
+// renderStartupTimeout renders the prompt with what to check, which
+// depends on how the agent is reached.
+func (m Model) renderStartupTimeout() string {
+	var checks []string
+	listen := m.handler.ListenAddr()
+	switch {
+	case listen == protocol.SpawnAddr && m.agentAddr == "":
+		checks = append(checks, "The agent command is not running: run it on its own to see why it stops")
+	case listen == protocol.SpawnAddr:
+		checks = append(checks, "The agent command is running but has sent nothing: check that it uses the agentui bridge or writes messages to its standard output")
+	case listen != "" && m.agentAddr == "":
+		checks = append(checks, "No agent has connected to "+listen+": check that it is running and pointed at this address")
+	case listen != "":
+		checks = append(checks, "The agent at "+m.agentAddr+" connected but has sent nothing")
+	default:
+		checks = append(checks, "The agent talks over standard input and output: check that it writes messages, not logs, to its standard output")
+	}
+	checks = append(checks,
+		"Agents using the Python bridge send a hello when they start; others are ready once they send any message",
+		"--protocol-log FILE records every message that arrives",
+		"--startup-timeout 0 opens the input at once",
+	)
+
+	colors := theme.Current.Colors
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n", lipgloss.NewStyle().Bold(true).Foreground(colors.Warning).
+		Render(fmt.Sprintf("No message from the agent in %s", m.options.StartupTimeout)))
+	for _, check := range checks {
+		sb.WriteString(muted.Width(max(20, m.width-8)).Render(theme.Glyphs.Bullet+" "+check) + "\n")
+	}
+	if m.startup.prompt != nil {
+		sb.WriteString("\n" + m.startup.prompt.View())
+	}
+	return sb.String()
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// charsPerToken is roughly how many characters of English text a token
+// holds, to estimate a rate from streamed text when the agent reports no
+// token counts.
+const charsPerToken = 4
+
+// minRateWindow is how long a rate is measured over before it is shown, so
+// the first chunks do not show as a burst.
+const minRateWindow = time.Second
+
+// streamRate measures how fast the agent answers a turn: from the text it
+// streamed, and from the output token counts it reported in status
+// messages, which are preferred.
+type streamRate struct {
+	chars int
+
+	// The first and latest output token counts reported in the turn
+	firstTokens, lastTokens int
+	firstAt, lastAt         time.Time
+}
+
+// noteStreamedText counts text the agent streamed in the turn.
+func (m *Model) noteStreamedText(text string) {
+	if m.turn != nil {
+		m.turn.rate.chars += len(text)
+	}
+}
+
+// noteStreamTokens records token counts the agent reported in the turn.
+func (m *Model) noteStreamTokens(tokens *protocol.TokenInfo) {
+	if m.turn == nil || tokens == nil {
+		return
+	}
+	r := &m.turn.rate
+	if r.firstAt.IsZero() {
+		r.firstTokens, r.firstAt = tokens.Output, time.Now()
+	}
+	r.lastTokens, r.lastAt = tokens.Output, time.Now()
+}
+
+// tokensPerSecond returns how many tokens a second the turn is producing,
+// and whether that is estimated from the streamed text. It returns 0 until
+// there is enough to measure.
+func (t *turnTiming) tokensPerSecond(now time.Time) (rate float64, estimated bool) {
+	r := t.rate
+	if d := r.lastAt.Sub(r.firstAt); d >= minRateWindow && r.lastTokens > r.firstTokens {
+		return float64(r.lastTokens-r.firstTokens) / d.Seconds(), false
+	}
+	if d := now.Sub(t.first); !t.first.IsZero() && d >= minRateWindow && r.chars > 0 {
+		return float64(r.chars) / charsPerToken / d.Seconds(), true
+	}
+	return 0, false
+}
+
+// renderStreamRate renders how long the agent has been answering and how
+// fast, for the status bar, so a slow reply can be told from a stuck one.
+// It is empty unless the agent is working on a turn.
+func (m Model) renderStreamRate() string {
+	if !m.isStreaming || m.turn == nil {
+		return ""
+	}
+	now := time.Now()
+	s := m.options.Clock.Duration(now.Sub(m.turn.sent))
+	if rate, estimated := m.turn.tokensPerSecond(now); rate > 0 {
+		approx := ""
+		if estimated {
+			approx = "~"
+		}
+		s += fmt.Sprintf("%s%s%.0f tok/s", theme.Glyphs.Separator, approx, rate)
+	}
+	return lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Render(s)
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// renderProbe wraps the model and, each time a frame is rendered, reports
+// the IDs of the protocol messages applied since the previous frame.
+type renderProbe struct {
+	model    tea.Model
+	pending  []string
+	onRender func(ids []string)
+}
+
+// WithRenderProbe returns the model wrapped so onRender is called from View
+// with the IDs of protocol messages that frame is the first to show. It is
+// used to measure render latency against a live terminal.
+func (m Model) WithRenderProbe(onRender func(ids []string)) tea.Model {
+	return &renderProbe{model: m, onRender: onRender}
+}
+
+func (p *renderProbe) Init() tea.Cmd {
+	return p.model.Init()
+}
+
+func (p *renderProbe) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if pm, ok := msg.(protocolMsg); ok {
+		if pm.msg.Type == protocol.TypeBatch {
+			for _, m := range pm.msg.Batch {
+				p.pending = append(p.pending, m.ID)
+			}
+		} else {
+			p.pending = append(p.pending, pm.msg.ID)
+		}
+	}
+	var cmd tea.Cmd
+	p.model, cmd = p.model.Update(msg)
+	return p, cmd
+}
+
+func (p *renderProbe) View() string {
+	view := p.model.View()
+	if len(p.pending) > 0 {
+		p.onRender(p.pending)
+		p.pending = nil
+	}
+	return view
+}
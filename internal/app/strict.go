@@ -0,0 +1,38 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// addProtocolWarning notes a message rejected in strict mode in the
+// transcript. The handler has already sent the details to the agent, so
+// the session goes on.
+func (m *Model) addProtocolWarning(perr *protocol.PayloadError) {
+	title := "Rejected an invalid message"
+	if perr.Type != "" {
+		title = "Rejected an invalid " + string(perr.Type) + " message"
+	}
+	var lines []string
+	for _, f := range perr.Fields {
+		if f.Field != "" {
+			lines = append(lines, f.Field+": "+f.Problem)
+		} else {
+			lines = append(lines, f.Problem)
+		}
+	}
+	lines = append(lines, "The agent was sent a protocol_error with these details.")
+
+	m.alertView.SetTitle(title)
+	m.alertView.SetMessage(strings.Join(lines, "\n"))
+	m.alertView.SetSeverity("warning")
+	m.messages = append(m.messages, Message{
+		Role:      "system",
+		Content:   m.alertView.View(),
+		Timestamp: time.Now(),
+	})
+	m.refreshMessages()
+	m.statusMessage = title
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// inputSuggestion is the agent's completion of the user's draft, shown as
+// ghost text after the cursor until the user accepts it with Tab, dismisses
+// it with Esc or types something else.
+type inputSuggestion struct {
+	draft      string // the draft it completes
+	completion string
+}
+
+// handleInputSuggestion shows an input_suggestion, unless the user has
+// since typed past what it suggests.
+func (m *Model) handleInputSuggestion(msg *protocol.Message) {
+	var payload protocol.InputSuggestionPayload
+	if err := msg.ParsePayload(&payload); err != nil {
+		m.setError("Invalid input suggestion payload", err.Error(), false)
+		return
+	}
+	m.suggestion = nil
+	if payload.Completion != "" {
+		m.suggestion = &inputSuggestion{draft: payload.Draft, completion: payload.Completion}
+		m.followSuggestion()
+	}
+}
+
+// followSuggestion keeps the suggestion in step with the draft: characters
+// typed as suggested are taken off its start, and anything else drops it.
+func (m *Model) followSuggestion() {
+	s := m.suggestion
+	if s == nil {
+		return
+	}
+	draft := m.input.Value()
+	if typed, ok := strings.CutPrefix(draft, s.draft); ok {
+		if rest, ok := strings.CutPrefix(s.completion, typed); ok && rest != "" {
+			m.suggestion = &inputSuggestion{draft: draft, completion: rest}
+			return
+		}
+	}
+	m.suggestion = nil
+}
+
+// suggesting reports whether a suggestion is shown: the draft is a single
+// line and the cursor is at its end, where the completion would go.
+func (m Model) suggesting() bool {
+	if m.suggestion == nil || m.focused() != PanelInput {
+		return false
+	}
+	draft := m.input.Value()
+	if draft == "" || strings.Contains(draft, "\n") {
+		return false
+	}
+	info := m.input.LineInfo()
+	return info.StartColumn+info.ColumnOffset == len([]rune(draft))
+}
+
+// acceptSuggestion completes the draft with the suggestion.
+func (m *Model) acceptSuggestion() {
+	m.input.InsertString(m.suggestion.completion)
+	m.suggestion = nil
+	m.fitInput()
+}
+
+// renderSuggestion draws the first line of the suggestion from the cursor
+// on in the input's view, cut to the space left on the cursor's row. The
+// cursor stays visible on the first character of the suggestion.
+func (m Model) renderSuggestion(view string) string {
+	info := m.input.LineInfo()
+	lines := strings.Split(view, "\n")
+	// A draft taller than the input is scrolled to the cursor at its end
+	row := min(info.RowOffset, len(lines)-1)
+
+	col := lipgloss.Width(m.input.Prompt) + info.CharOffset
+	space := m.input.Width() + lipgloss.Width(m.input.Prompt) - col
+	ghost, _, _ := strings.Cut(m.suggestion.completion, "\n")
+	ghost = ansi.Truncate(ghost, space, theme.Glyphs.Ellipsis)
+	if space <= 0 || ghost == "" {
+		return view
+	}
+
+	muted := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted)
+	_, size := utf8.DecodeRuneInString(ghost)
+	first, rest := ghost[:size], ghost[size:]
+	padding := max(0, lipgloss.Width(lines[row])-col-lipgloss.Width(ghost))
+	lines[row] = ansi.Truncate(lines[row], col, "") + ansi.ResetStyle +
+		muted.Reverse(true).Render(first) + muted.Render(rest) + strings.Repeat(" ", padding)
+	return strings.Join(lines, "\n")
+}
+
+// renderSuggestionHint names the keys that take a shown suggestion, at
+// the start of the status bar.
+func (m Model) renderSuggestionHint() string {
+	if !m.suggesting() {
+		return ""
+	}
+	hint := "Tab to accept" + theme.Glyphs.Separator + "Esc to dismiss"
+	return lipgloss.NewStyle().Foreground(theme.Current.Colors.TextMuted).Render(hint) + "  "
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/ui/components"
+)
+
+// Restart backoff for a spawned agent: the first restart waits
+// restartDelay, each one after that twice as long, up to maxRestartDelay.
+const (
+	restartDelay    = time.Second
+	maxRestartDelay = 30 * time.Second
+)
+
+// stableRun is how long a spawned agent has to run for its restarts to be
+// counted afresh.
+const stableRun = time.Minute
+
+// supervisor restarts the agent the TUI spawned when it exits.
+type supervisor struct {
+	attempts int  // restarts in a row, without the agent running for long
+	seq      int  // the scheduled restart; others are stale
+	pending  bool // a restart is scheduled
+	down     bool // the agent is not running
+
+	// The prompt shown when the agent exits, and the state to go back to
+	// when it is dismissed
+	prompt *components.SelectMenu
+	resume State
+}
+
+// Choices offered when the spawned agent exits.
+const (
+	exitedRestart = "restart"
+	exitedStop    = "stop"
+	exitedQuit    = "quit"
+)
+
+// agentExitMsg reports that the spawned agent exited.
+type agentExitMsg struct {
+	exit protocol.AgentExit
+}
+
+// restartAgentMsg fires when restart seq is due.
+type restartAgentMsg struct {
+	seq int
+}
+
+// agentExited notes how the spawned agent ended in the transcript and
+// offers to restart it, scheduling the restart itself after a crash unless
+// it has crashed too often in a row.
+func (m *Model) agentExited(exit protocol.AgentExit) tea.Cmd {
+	if m.quitting {
+		return nil
+	}
+	m.supervisor.down = true
+	if exit.Ran >= stableRun {
+		m.supervisor.attempts = 0
+	}
+	m.addExitNote(exit)
+
+	var cmd tea.Cmd
+	label := "Agent exited"
+	crashed := exit.Code != 0 || exit.Err != nil
+	if crashed && m.supervisor.attempts < m.options.RestartAttempts {
+		delay := min(restartDelay<<m.supervisor.attempts, maxRestartDelay)
+		m.supervisor.attempts++
+		m.supervisor.seq++
+		m.supervisor.pending = true
+		seq := m.supervisor.seq
+		cmd = tea.Tick(delay, func(time.Time) tea.Msg {
+			return restartAgentMsg{seq: seq}
+		})
+		label = fmt.Sprintf("Agent exited · restarting in %s (attempt %d of %d)",
+			delay, m.supervisor.attempts, m.options.RestartAttempts)
+	}
+	m.statusMessage = label
+	m.openExitedPrompt(label)
+	return cmd
+}
+
+// addExitNote adds the exit code and last lines of standard error of the
+// spawned agent to the transcript.
+func (m *Model) addExitNote(exit protocol.AgentExit) {
+	title := fmt.Sprintf("Agent exited with code %d", exit.Code)
+	severity := "warning"
+	switch {
+	case exit.Err != nil:
+		title = "Agent could not be started"
+		severity = "error"
+	case exit.Code < 0:
+		title = "Agent was killed"
+	case exit.Code == 0:
+		severity = "info"
+	}
+
+	lines := exit.Stderr
+	if exit.Err != nil {
+		lines = append([]string{exit.Err.Error()}, lines...)
+	}
+	if len(lines) == 0 {
+		lines = []string{"It wrote nothing to standard error."}
+	}
+
+	m.alertView.SetTitle(title)
+	m.alertView.SetMessage(strings.Join(lines, "\n"))
+	m.alertView.SetSeverity(severity)
+	m.messages = append(m.messages, Message{
+		Role:      "system",
+		Content:   m.alertView.View(),
+		Timestamp: time.Now(),
+		Summary:   title,
+	})
+	m.refreshMessages()
+}
+
+// restartAgent starts the spawned agent again, cancelling any scheduled
+// restart.
+func (m *Model) restartAgent() {
+	if m.state == StateAgentExited {
+		m.closeExitedPrompt()
+	}
+	m.supervisor.seq++
+	m.supervisor.pending = false
+	m.supervisor.down = false
+	m.options.Spawner.Restart()
+	m.statusMessage = "Restarting the agent"
+}
+
+// handleRestartDue restarts the agent when the scheduled restart is due,
+// unless it was cancelled or replaced.
+func (m *Model) handleRestartDue(seq int) {
+	if seq == m.supervisor.seq && m.supervisor.pending {
+		m.restartAgent()
+	}
+}
+
+func (m *Model) openExitedPrompt(label string) {
+	var options []protocol.SelectOption
+	if m.supervisor.pending {
+		options = []protocol.SelectOption{
+			{Label: "Restart now", Value: exitedRestart, Description: "Start the agent again without waiting"},
+			{Label: "Don't restart", Value: exitedStop, Description: "Keep the transcript; ctrl+r restarts the agent later"},
+		}
+	} else {
+		options = []protocol.SelectOption{
+			{Label: "Restart", Value: exitedRestart, Description: "Start the agent again, keeping the transcript"},
+		}
+	}
+	options = append(options, protocol.SelectOption{
+		Label: "Quit", Value: exitedQuit, Description: "Close the TUI",
+	})
+
+	if m.state == StateAgentExited {
+		m.closeExitedPrompt()
+	}
+	m.supervisor.prompt = components.NewSelectMenu(&protocol.SelectPayload{
+		Label:   label,
+		Options: options,
+	})
+	m.supervisor.prompt.SetWidth(m.width)
+	m.supervisor.resume = m.state
+	m.state = StateAgentExited
+}
+
+func (m *Model) closeExitedPrompt() {
+	m.state = m.supervisor.resume
+	m.supervisor.prompt = nil
+}
+
+// updateExitedPrompt passes msg to the prompt and carries out the user's
+// choice. Esc dismisses the prompt, leaving a scheduled restart in place.
+func (m *Model) updateExitedPrompt(msg tea.Msg) tea.Cmd {
+	if m.supervisor.prompt == nil {
+		return nil
+	}
+	cmd := m.supervisor.prompt.Update(msg)
+	if !m.supervisor.prompt.HasResponded() {
+		return cmd
+	}
+
+	choice := m.supervisor.prompt.GetSelected()
+	m.closeExitedPrompt()
+	switch choice {
+	case exitedRestart:
+		m.restartAgent()
+	case exitedStop:
+		m.supervisor.seq++
+		m.supervisor.pending = false
+		m.statusMessage = "Not restarting the agent; ctrl+r restarts it"
+	case exitedQuit:
+		m.quitting = true
+		return tea.Quit
+	}
+	return cmd
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// conversation is the state of one chat tab. Input typed in a tab opened
+// for an agent is addressed to its agent_id, and the messages that agent
+// sends land in the tab; everything else lands in the first tab, which is
+// for no agent in particular.
+type conversation struct {
+	agent         string
+	messages      []Message
+	streamingText string
+	streamAgent   string
+	isStreaming   bool
+	turn          *turnTiming
+	deleted       []deletedMessage
+	draft         string
+	yOffset       int
+	atBottom      bool
+	unread        int // messages added since the tab was last open
+}
+
+// chatTabs are the chat tabs, once a second one is opened. The open tab's
+// state lives in the Model's own fields and its entry is stale until
+// another tab is opened.
+type chatTabs struct {
+	list   []conversation
+	active int
+}
+
+// tabAgent returns the agent the open tab's input is addressed to.
+func (m Model) tabAgent() string {
+	if len(m.tabs.list) == 0 {
+		return ""
+	}
+	return m.tabs.list[m.tabs.active].agent
+}
+
+// tabFor returns the tab messages from agent land in.
+func (m Model) tabFor(agent string) int {
+	for i, t := range m.tabs.list {
+		if i > 0 && t.agent == agent {
+			return i
+		}
+	}
+	return 0
+}
+
+// saveTab keeps the open tab's state in its entry.
+func (m *Model) saveTab() {
+	t := &m.tabs.list[m.tabs.active]
+	t.messages = m.messages
+	t.streamingText = m.streamingText
+	t.streamAgent = m.streamAgent
+	t.isStreaming = m.isStreaming
+	t.turn = m.turn
+	t.deleted = m.deleted
+}
+
+// loadTab makes tab i the one whose state the Model's fields hold.
+func (m *Model) loadTab(i int) {
+	m.tabs.active = i
+	t := m.tabs.list[i]
+	m.messages = t.messages
+	m.streamingText = t.streamingText
+	m.streamAgent = t.streamAgent
+	m.isStreaming = t.isStreaming
+	m.turn = t.turn
+	m.deleted = t.deleted
+}
+
+// switchTab opens tab i, with its draft and where it was scrolled to.
+func (m *Model) switchTab(i int) {
+	if i == m.tabs.active || i < 0 || i >= len(m.tabs.list) {
+		return
+	}
+	m.stopSelecting()
+	m.closeSearch()
+	m.suggestion = nil
+	m.saveTab()
+	t := &m.tabs.list[m.tabs.active]
+	t.draft = m.input.Value()
+	t.yOffset = m.viewport.YOffset
+	t.atBottom = m.viewport.AtBottom()
+
+	m.loadTab(i)
+	m.newBelow = 0
+	t = &m.tabs.list[i]
+	t.unread = 0
+	m.input.SetValue(t.draft)
+	m.fitInput()
+	m.viewport.SetContent(m.renderMessages())
+	if t.atBottom {
+		m.viewport.GotoBottom()
+	} else {
+		m.viewport.SetYOffset(t.yOffset)
+	}
+	m.statusMessage = "Tab " + m.tabName(i)
+}
+
+// openTab opens the tab for agent, adding it if there is none.
+func (m *Model) openTab(agent string) {
+	if len(m.tabs.list) == 0 {
+		m.tabs.list = []conversation{{atBottom: true}}
+	}
+	if i := m.tabFor(agent); i > 0 {
+		m.switchTab(i)
+		return
+	}
+	m.tabs.list = append(m.tabs.list, conversation{agent: agent, messages: []Message{}, atBottom: true})
+	m.switchTab(len(m.tabs.list) - 1)
+}
+
+// closeTab closes the open tab, dropping its transcript, and opens the one
+// before it. The first tab cannot be closed.
+func (m *Model) closeTab() {
+	i := m.tabs.active
+	if i == 0 {
+		m.statusMessage = "The first tab cannot be closed"
+		return
+	}
+	name := m.tabName(i)
+	m.switchTab(i - 1)
+	m.tabs.list = slices.Delete(m.tabs.list, i, i+1)
+	if len(m.tabs.list) == 1 {
+		m.tabs.list = nil
+	}
+	m.statusMessage = "Closed tab " + name
+}
+
+// cycleTab opens the next tab, or the previous one if step is -1.
+func (m *Model) cycleTab(step int) {
+	if n := len(m.tabs.list); n > 1 {
+		m.switchTab((m.tabs.active + step + n) % n)
+	}
+}
+
+// routeToTab handles msg for a tab that is not open, then goes back to
+// the open tab where the user left it.
+func (m Model) routeToTab(i int, msg *protocol.Message) (tea.Model, tea.Cmd) {
+	active := m.tabs.active
+	yOffset, atBottom, newBelow := m.viewport.YOffset, m.viewport.AtBottom(), m.newBelow
+	m.saveTab()
+	m.loadTab(i)
+	n := len(m.messages)
+
+	model, cmd := m.routeMessage(msg)
+	m = model.(Model)
+	m.tabs.list[i].unread += max(0, len(m.messages)-n)
+	m.saveTab()
+	m.loadTab(active)
+	m.newBelow = newBelow
+
+	m.viewport.SetContent(m.renderMessages())
+	if atBottom {
+		m.viewport.GotoBottom()
+	} else {
+		m.viewport.SetYOffset(yOffset)
+	}
+	return m, cmd
+}
+
+// tabCommand runs /tab with the given arguments: none lists the tabs,
+// "close" closes the open one, a number opens that tab and an agent_id
+// opens the tab for that agent.
+func (m *Model) tabCommand(args string) tea.Cmd {
+	switch args {
+	case "":
+		m.addReport("Tabs", m.describeTabs())
+	case "close":
+		m.closeTab()
+	default:
+		if n, err := strconv.Atoi(args); err == nil {
+			if n < 1 || n > max(1, len(m.tabs.list)) {
+				m.statusMessage = fmt.Sprintf("No tab %d", n)
+				return nil
+			}
+			m.switchTab(n - 1)
+			return nil
+		}
+		if strings.ContainsAny(args, " \t") {
+			m.statusMessage = "An agent_id has no spaces"
+			return nil
+		}
+		m.openTab(args)
+	}
+	return nil
+}
+
+// describeTabs lists the tabs.
+func (m Model) describeTabs() string {
+	if len(m.tabs.list) == 0 {
+		return "One tab. Open one for an agent with /tab <agent_id>; its input goes to that agent and its messages show there."
+	}
+	lines := make([]string, len(m.tabs.list))
+	for i, t := range m.tabs.list {
+		n := len(t.messages)
+		if i == m.tabs.active {
+			n = len(m.messages)
+		}
+		line := fmt.Sprintf("%d. %s: %d messages", i+1, m.tabName(i), n)
+		if i == m.tabs.active {
+			line += " (open)"
+		} else if t.unread > 0 {
+			line += fmt.Sprintf(", %d unread", t.unread)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tabName names tab i after its agent.
+func (m Model) tabName(i int) string {
+	if agent := m.tabs.list[i].agent; agent != "" {
+		return agent
+	}
+	return "main"
+}
+
+// renderTabs renders the tab bar shown in the header once there are
+// several tabs: each tab's number and name, the open one highlighted and
+// the others with how many messages arrived in them unseen.
+func (m Model) renderTabs() string {
+	if len(m.tabs.list) < 2 {
+		return ""
+	}
+	colors := theme.Current.Colors
+	open := lipgloss.NewStyle().Bold(true).Foreground(colors.Primary)
+	other := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	unread := lipgloss.NewStyle().Foreground(colors.Accent1)
+
+	tabs := make([]string, len(m.tabs.list))
+	for i, t := range m.tabs.list {
+		label := fmt.Sprintf("%d %s", i+1, m.tabName(i))
+		switch {
+		case i == m.tabs.active:
+			tabs[i] = open.Render(theme.Glyphs.Focus + label)
+		case t.unread > 0:
+			tabs[i] = other.Render(label) + unread.Render(fmt.Sprintf(" %s%d", theme.Glyphs.Bullet, t.unread))
+		default:
+			tabs[i] = other.Render(label)
+		}
+	}
+	return strings.Join(tabs, other.Render(theme.Glyphs.Separator))
+}
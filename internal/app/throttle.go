@@ -0,0 +1,85 @@
+package app
+
+import (
+	"encoding/json"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// DefaultStatusRate is how many times a second floods of status and
+// progress updates are drawn by default.
+const DefaultStatusRate = 20
+
+// statusThrottle remembers when status and progress updates were last
+// delivered to the app. Only the one pending listenForMessages command
+// uses it.
+type statusThrottle struct {
+	last time.Time
+}
+
+// coalescedMsg carries the latest of a flood of status and progress
+// updates, possibly followed by the message that ended the flood, to be
+// applied in one update.
+type coalescedMsg struct {
+	msgs []*protocol.Message
+}
+
+// coalesceKey returns what msg updates if it only replaces what an earlier
+// message of its kind showed: the status line, or one progress bar.
+func coalesceKey(msg *protocol.Message) (string, bool) {
+	switch msg.Type {
+	case protocol.TypeStatus:
+		return "status", true
+	case protocol.TypeProgress:
+		var p struct {
+			ID string `json:"id"`
+		}
+		json.Unmarshal(msg.Payload, &p)
+		return "progress:" + p.ID, true
+	}
+	return "", false
+}
+
+// coalesceUpdates delivers first, a status or progress update, no sooner
+// than 1/StatusRate seconds after the last updates were. Meanwhile later
+// updates replace earlier ones of the same kind, so a flood is drawn at
+// most StatusRate times a second with the latest values. Any other
+// message ends the wait and is delivered after the updates, keeping the
+// order.
+func (m Model) coalesceUpdates(first *protocol.Message) tea.Msg {
+	key, _ := coalesceKey(first)
+	msgs := []*protocol.Message{first}
+	keys := map[string]int{key: 0}
+
+	next := m.throttle.last.Add(time.Second / time.Duration(m.options.StatusRate))
+	timer := time.NewTimer(max(time.Until(next), 0))
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			m.throttle.last = time.Now()
+			return coalescedMsg{msgs}
+		case msg, ok := <-m.handler.Incoming():
+			if !ok || msg == nil {
+				// The closed stream is seen again by the next listen
+				m.throttle.last = time.Now()
+				return coalescedMsg{msgs}
+			}
+			m.handler.Consumed(msg)
+			key, ok := coalesceKey(msg)
+			if !ok {
+				m.throttle.last = time.Now()
+				return coalescedMsg{append(msgs, msg)}
+			}
+			if i, seen := keys[key]; seen {
+				msgs[i] = msg
+			} else {
+				keys[key] = len(msgs)
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// How the transcript shows when messages arrived.
+const (
+	TimestampsMinute   = "minute"   // centered above a message when the minute changed since the previous one
+	TimestampsMessage  = "message"  // the time beside every message
+	TimestampsRelative = "relative" // how long ago beside every message, such as "5m ago"
+	TimestampsOff      = "off"      // no times, nor how long replies took
+)
+
+// timestamps returns how the transcript shows times, TimestampsMinute if
+// not set.
+func (m Model) timestamps() string {
+	if !m.showTimes {
+		return TimestampsOff
+	}
+	if m.options.Timestamps == "" {
+		return TimestampsMinute
+	}
+	return m.options.Timestamps
+}
+
+// renderTime renders the time a message arrived above it. In the minute
+// mode it is centered and returns "" when the time shows the same as the
+// previous message's, so the chat only marks the minutes in which something
+// happened; the other modes put it at the right of every message.
+func (m Model) renderTime(t time.Time, prev *string) string {
+	if t.IsZero() {
+		return ""
+	}
+	style := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextDim)
+	var stamp string
+	switch m.timestamps() {
+	case TimestampsMinute:
+		stamp = m.options.Clock.Time(t)
+		if stamp == *prev {
+			return ""
+		}
+		*prev = stamp
+		if m.width > 4 {
+			style = style.Width(m.width - 4).Align(lipgloss.Center)
+		}
+		return style.Render(stamp) + "\n"
+	case TimestampsMessage:
+		stamp = m.options.Clock.Time(t)
+	case TimestampsRelative:
+		stamp = m.options.Clock.Ago(t, time.Now())
+	default:
+		return ""
+	}
+	if m.width > 4 {
+		style = style.Width(m.width - 4).Align(lipgloss.Right)
+	}
+	return style.Render(stamp) + "\n"
+}
+
+// renderElapsed renders how long the agent took to answer the turn t
+// ended, under its last message; the waterfall replaces it when shown.
+func (m Model) renderElapsed(t *turnTiming) string {
+	if m.timestamps() == TimestampsOff {
+		return ""
+	}
+	elapsed := t.elapsed()
+	if elapsed <= 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(theme.Current.Colors.TextDim).Render("  answered in " + roundDuration(elapsed))
+}
+
+// relativeTimesTickMsg fires every minute while times show how long ago
+// messages arrived.
+type relativeTimesTickMsg struct{}
+
+// relativeTimesTick schedules the next refresh of relative times.
+func (m Model) relativeTimesTick() tea.Cmd {
+	if m.options.Timestamps != TimestampsRelative {
+		return nil
+	}
+	return tea.Tick(time.Minute, func(time.Time) tea.Msg {
+		return relativeTimesTickMsg{}
+	})
+}
+
+// sessionDuration formats how long the TUI has been running.
+func (m Model) sessionDuration() string {
+	return m.options.Clock.Duration(time.Since(m.started))
+}
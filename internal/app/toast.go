@@ -0,0 +1,76 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// toastDuration is how long a toast is shown.
+const toastDuration = 2 * time.Second
+
+// toast is a short note, such as "Copied", drawn over the bottom right of
+// the transcript until it goes by itself.
+type toast struct {
+	text string
+	seq  int // tells which toast a toastExpiredMsg is for
+}
+
+// toastExpiredMsg hides the toast it is for.
+type toastExpiredMsg struct {
+	seq int
+}
+
+// showToast shows text in a toast, replacing any shown.
+func (m *Model) showToast(text string) tea.Cmd {
+	m.toast.seq++
+	m.toast.text = text
+	seq := m.toast.seq
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{seq}
+	})
+}
+
+// expireToast hides the toast if msg is for the one shown.
+func (m *Model) expireToast(msg toastExpiredMsg) {
+	if msg.seq == m.toast.seq {
+		m.toast.text = ""
+	}
+}
+
+// placeToast draws the toast, if one is shown, over the bottom right
+// corner of view.
+func (m Model) placeToast(view string) string {
+	if m.toast.text == "" {
+		return view
+	}
+	colors := theme.Current.Colors
+	box := lipgloss.NewStyle().
+		Border(theme.Glyphs.Border).
+		BorderForeground(colors.Success).
+		Foreground(colors.Text).
+		Padding(0, 1).
+		Render(theme.Glyphs.Success + " " + m.toast.text)
+
+	lines := strings.Split(view, "\n")
+	boxLines := strings.Split(box, "\n")
+	width := lipgloss.Width(view)
+	left := width - lipgloss.Width(box) - 1
+	if left < 0 || len(lines) < len(boxLines) {
+		return view
+	}
+	first := len(lines) - len(boxLines)
+	for i, boxLine := range boxLines {
+		line := ansi.Truncate(lines[first+i], left, "")
+		if w := ansi.StringWidth(line); w < left {
+			line += strings.Repeat(" ", left-w)
+		}
+		lines[first+i] = line + "\x1b[0m" + boxLine
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// turnTiming records when a turn reached each of its phases: the user sent
+// input, the agent's first output arrived, its text finished streaming and
+// it said it was done, tool calls included. Phases not reached are zero.
+type turnTiming struct {
+	sent     time.Time
+	first    time.Time
+	streamed time.Time
+	done     time.Time
+
+	// The index of the first message of the turn
+	start int
+	// How fast the agent is answering; see streamrate.go
+	rate streamRate
+}
+
+// notOutput lists agent messages that do not count as the first output of
+// a turn: indicators of work, and messages about the connection or UI.
+var notOutput = map[protocol.MessageType]bool{
+	protocol.TypeStatus:          true,
+	protocol.TypeSpinner:         true,
+	protocol.TypeProgress:        true,
+	protocol.TypeDone:            true,
+	protocol.TypeHello:           true,
+	protocol.TypePong:            true,
+	protocol.TypeBatch:           true,
+	protocol.TypeScrollTo:        true,
+	protocol.TypeFocusInput:      true,
+	protocol.TypeCollapse:        true,
+	protocol.TypeExpand:          true,
+	protocol.TypeInputSuggestion: true,
+	protocol.TypeTool:            true,
+}
+
+// startTurn starts timing the turn begun by the user's input, finishing
+// the previous one if the agent never said it was done.
+func (m *Model) startTurn() {
+	m.finishTurn()
+	m.turn = &turnTiming{sent: time.Now(), start: len(m.messages)}
+}
+
+// noteTurnOutput records the first output of the turn.
+func (m *Model) noteTurnOutput(t protocol.MessageType) {
+	if m.turn != nil && m.turn.first.IsZero() && !notOutput[t] {
+		m.turn.first = time.Now()
+	}
+}
+
+// noteTurnStreamed records that the agent's reply finished streaming.
+func (m *Model) noteTurnStreamed() {
+	if m.turn != nil && m.turn.streamed.IsZero() {
+		m.turn.streamed = time.Now()
+	}
+}
+
+// finishTurn ends the turn being timed, attaching its timing to the last
+// message the agent added in it. A turn the agent added nothing to is
+// dropped.
+func (m *Model) finishTurn() {
+	turn := m.turn
+	m.turn = nil
+	if turn == nil {
+		return
+	}
+	for i := len(m.messages) - 1; i >= turn.start && i < len(m.messages); i-- {
+		if m.messages[i].Role != "user" {
+			m.messages[i].Timing = turn
+			if m.showTurnTiming() || m.timestamps() != TimestampsOff {
+				m.refreshMessages()
+			}
+			return
+		}
+	}
+}
+
+// elapsed returns how long the turn took, from the user's input to the last
+// phase it reached.
+func (t *turnTiming) elapsed() time.Duration {
+	end := t.sent
+	for _, at := range []time.Time{t.first, t.streamed, t.done} {
+		if at.After(end) {
+			end = at
+		}
+	}
+	return end.Sub(t.sent)
+}
+
+// showTurnTiming reports whether waterfalls are shown under each turn.
+func (m Model) showTurnTiming() bool {
+	return m.options.TurnTiming || m.debugMode
+}
+
+// renderWaterfall renders how long each phase of a turn took, as bars on
+// a shared time axis so the slowest phase stands out.
+func (m Model) renderWaterfall(t *turnTiming) string {
+	type phase struct {
+		label      string
+		start, end time.Time
+	}
+	// Tools run after the text finished streaming, or after the first
+	// output for agents that do not stream
+	toolsFrom := t.streamed
+	if toolsFrom.IsZero() {
+		toolsFrom = t.first
+	}
+	phases := []phase{
+		{"first output", t.sent, t.first},
+		{"streaming", t.first, t.streamed},
+		{"tools", toolsFrom, t.done},
+	}
+
+	end := t.sent
+	for _, p := range phases {
+		if p.end.After(end) {
+			end = p.end
+		}
+	}
+	total := end.Sub(t.sent)
+	if total <= 0 {
+		return ""
+	}
+
+	colors := theme.Current.Colors
+	dim := lipgloss.NewStyle().Foreground(colors.TextDim)
+	bar := lipgloss.NewStyle().Foreground(colors.Primary)
+	width := min(max(m.width-36, 10), 40)
+
+	var sb strings.Builder
+	sb.WriteString(dim.Render(fmt.Sprintf("  turn %s", roundDuration(total))))
+	for _, p := range phases {
+		if p.start.IsZero() || p.end.IsZero() {
+			continue
+		}
+		// Every phase gets at least one cell, however short
+		from := min(int(float64(width)*float64(p.start.Sub(t.sent))/float64(total)), width-1)
+		to := min(max(int(float64(width)*float64(p.end.Sub(t.sent))/float64(total)), from+1), width)
+		sb.WriteString("\n")
+		sb.WriteString(dim.Render(fmt.Sprintf("  %-12s ", p.label)))
+		sb.WriteString(dim.Render(strings.Repeat("·", from)))
+		sb.WriteString(bar.Render(strings.Repeat("█", to-from)))
+		sb.WriteString(dim.Render(strings.Repeat("·", width-to)))
+		sb.WriteString(dim.Render(" " + roundDuration(p.end.Sub(p.start))))
+	}
+	return sb.String()
+}
+
+// roundDuration formats d to a precision that suits its size.
+func roundDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// typingState tracks whether the agent has been told the user is typing.
+// One start is sent per burst of keystrokes and one stop when the user
+// sends or clears the draft or pauses for Options.TypingIdle, so a fast
+// typist does not flood the agent.
+type typingState struct {
+	active bool
+	last   time.Time // latest keystroke that changed the draft
+	seq    int       // tells apart idle checks of different bursts
+}
+
+// typingIdleMsg checks whether burst seq has gone idle.
+type typingIdleMsg struct{ seq int }
+
+// noteTyping records a keystroke that changed the draft, telling the agent
+// when a burst starts or the draft is emptied.
+func (m *Model) noteTyping() tea.Cmd {
+	if m.options.TypingIdle <= 0 {
+		return nil
+	}
+	if m.input.Value() == "" {
+		m.stopTyping("")
+		return nil
+	}
+	m.typing.last = time.Now()
+	if m.typing.active {
+		return nil
+	}
+	m.typing.active = true
+	m.typing.seq++
+	m.sendTyping(protocol.TypingStart, "")
+	return m.typingIdleCheck(m.options.TypingIdle)
+}
+
+// typingIdleCheck schedules an idle check of the current burst.
+func (m Model) typingIdleCheck(after time.Duration) tea.Cmd {
+	seq := m.typing.seq
+	return tea.Tick(after, func(time.Time) tea.Msg {
+		return typingIdleMsg{seq: seq}
+	})
+}
+
+// handleTypingIdle stops the burst if no key was typed for TypingIdle, or
+// checks again when that much time will have passed since the last one.
+func (m *Model) handleTypingIdle(seq int) tea.Cmd {
+	if !m.typing.active || seq != m.typing.seq {
+		return nil
+	}
+	if wait := m.options.TypingIdle - time.Since(m.typing.last); wait > 0 {
+		return m.typingIdleCheck(wait)
+	}
+	m.stopTyping(m.input.Value())
+	return nil
+}
+
+// stopTyping ends the current burst, if any, with draft if the user only
+// paused.
+func (m *Model) stopTyping(draft string) {
+	if !m.typing.active {
+		return
+	}
+	m.typing.active = false
+	m.sendTyping(protocol.TypingStop, draft)
+}
+
+func (m *Model) sendTyping(state, draft string) {
+	if err := m.handler.SendTyping(state, draft); err != nil && !notConnected(err) {
+		m.statusMessage = "Failed to send typing event: " + err.Error()
+	}
+}
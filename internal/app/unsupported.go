@@ -0,0 +1,45 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// addUnsupported records a message of unknown type in the chat and tells the
+// agent which types this TUI can render.
+func (m *Model) addUnsupported(msg *protocol.Message) {
+	raw, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		raw = []byte(err.Error())
+	}
+	m.messages = append(m.messages, Message{
+		Role:        "system",
+		Timestamp:   time.Now(),
+		Unsupported: string(msg.Type),
+		Raw:         string(raw),
+	})
+	m.refreshMessages()
+
+	if err := m.handler.SendUnsupported(msg.ID, msg.Type); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to report unsupported message: %v", err)
+	}
+}
+
+// renderUnsupported renders a dim placeholder for an unknown message, with
+// its raw JSON when expanded.
+func renderUnsupported(msg Message) string {
+	colors := theme.Current.Colors
+	dim := lipgloss.NewStyle().Foreground(colors.TextDim)
+
+	line := dim.Render(fmt.Sprintf("unsupported message: %s", msg.Unsupported))
+	if !msg.ShowRaw {
+		return line + dim.Faint(true).Render("  (ctrl+x to show raw JSON)")
+	}
+	return line + "\n" + dim.Render(msg.Raw)
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Layouts for terminals wider than the chat reads well at, where lines run
+// across hundreds of columns.
+const (
+	LayoutFull    = "full"    // the chat spans the terminal, less the artifacts panel
+	LayoutCenter  = "center"  // the chat is at most MaxWidth wide, centered
+	LayoutColumns = "columns" // the chat is at most MaxWidth wide, the artifacts panel takes the rest
+)
+
+// DefaultMaxWidth is the widest the chat grows in the center and columns
+// layouts.
+const DefaultMaxWidth = 120
+
+// chatWidth returns how wide the chat is in a terminal termWidth wide,
+// less the sidebar. Outside the columns layout the chat narrows to make
+// room for artifacts, if it can stay minChatWidth wide.
+func (m Model) chatWidth(termWidth int) int {
+	if side := m.sidebarWidthFor(termWidth); side > 0 {
+		termWidth -= side + sidebarGap
+	}
+	width := termWidth
+	switch m.options.Layout {
+	case LayoutCenter, LayoutColumns:
+		if m.options.MaxWidth > 0 {
+			width = min(termWidth, m.options.MaxWidth)
+		}
+	}
+	if len(m.artifacts) > 0 && m.options.Layout != LayoutColumns {
+		panel := max(termWidth/3, minArtifactsWidth)
+		if rest := termWidth - panel - artifactsGap; rest >= minChatWidth {
+			width = min(width, rest)
+		}
+	}
+	return width
+}
+
+// placeWide places the UI, laid out at the chat width, in the terminal:
+// right of the sidebar, and beside the artifacts panel or centered in the
+// rest.
+func (m Model) placeWide(ui string) string {
+	if side := m.sidebarSpace(); side > 0 {
+		sidebar := lipgloss.NewStyle().PaddingRight(sidebarGap).Render(m.renderSidebar(side-sidebarGap, lipgloss.Height(ui)))
+		return lipgloss.JoinHorizontal(lipgloss.Top, sidebar, m.placeBeside(ui, m.termWidth-side))
+	}
+	return m.placeBeside(ui, m.termWidth)
+}
+
+// placeBeside places the UI in the width left of the terminal, beside the
+// artifacts panel or centered.
+func (m Model) placeBeside(ui string, width int) string {
+	if m.width >= width {
+		return ui
+	}
+	if width := m.artifactsWidth(); width > 0 {
+		panel := lipgloss.NewStyle().PaddingLeft(artifactsGap).Render(m.renderArtifacts(width))
+		return lipgloss.JoinHorizontal(lipgloss.Top, ui, panel)
+	}
+	return lipgloss.PlaceHorizontal(width, lipgloss.Center, ui)
+}
@@ -0,0 +1,38 @@
+// Package browser opens URLs in the user's web browser.
+package browser
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ErrRemote is returned when running over SSH, where a browser would open
+// on the remote machine rather than the user's.
+var ErrRemote = errors.New("cannot open a browser over SSH")
+
+// command returns the command that opens url on goos.
+func command(goos, url string) []string {
+	switch goos {
+	case "darwin":
+		return []string{"open", url}
+	case "windows":
+		return []string{"rundll32", "url.dll,FileProtocolHandler", url}
+	}
+	return []string{"xdg-open", url}
+}
+
+// Open opens url in the default browser without waiting for it.
+func Open(url string) error {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return ErrRemote
+	}
+	args := command(runtime.GOOS, url)
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() // reap it
+	return nil
+}
@@ -0,0 +1,21 @@
+package browser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	url := "https://example.com/a?b=c"
+	tests := map[string][]string{
+		"darwin":  {"open", url},
+		"windows": {"rundll32", "url.dll,FileProtocolHandler", url},
+		"linux":   {"xdg-open", url},
+		"freebsd": {"xdg-open", url},
+	}
+	for goos, want := range tests {
+		if got := command(goos, url); !slices.Equal(got, want) {
+			t.Errorf("command(%q) = %q, want %q", goos, got, want)
+		}
+	}
+}
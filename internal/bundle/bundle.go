@@ -0,0 +1,266 @@
+// Package bundle packs a saved session into a single file to share, for
+// attaching to a bug report or a design review, and unpacks it for
+// another user to open read-only. A bundle is a zip archive, named with
+// Ext, holding a manifest, the session's messages with the blobs they
+// refer to, and the theme it was viewed in.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/replay"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// Ext is the file extension of bundles.
+const Ext = ".aui"
+
+// Format and Version identify bundles in their manifest. Version goes up
+// when bundles change in a way older versions cannot open.
+const (
+	Format  = "agentui-bundle"
+	Version = 1
+)
+
+// Files in a bundle.
+const (
+	manifestFile = "manifest.json"
+	sessionFile  = "session.jsonl"
+	themeFile    = "theme.json"
+)
+
+// maxFileSize bounds a file unpacked from a bundle, which may come from
+// anyone.
+const maxFileSize = 1 << 30 // 1 GiB
+
+// Manifest describes a bundle.
+type Manifest struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	// Session is the name of the session bundled, and Title the first
+	// input sent in it
+	Session string    `json:"session"`
+	Title   string    `json:"title,omitempty"`
+	Created time.Time `json:"created"`
+	// AgentUI is the version of the TUI that made the bundle
+	AgentUI  string `json:"agentui,omitempty"`
+	Theme    string `json:"theme"`
+	Messages int    `json:"messages"`
+	// Environment is where the session ran, if it was saved with it
+	Environment *environment.Snapshot `json:"environment,omitempty"`
+	// MissingBlobs names blobs the agent handed over as files that were
+	// gone when the bundle was made, and so are left out
+	MissingBlobs []string `json:"missing_blobs,omitempty"`
+}
+
+// Bundle is an unpacked bundle.
+type Bundle struct {
+	Manifest Manifest
+	Messages []*protocol.Message
+	Theme    *theme.Theme
+}
+
+// Write packs the lines of a session, as saved in the history, into a
+// bundle written to w, with theme t. Blobs the agent handed over as files
+// are packed in while their files last. It returns the manifest written,
+// which completes manifest with what was packed.
+func Write(w io.Writer, session []byte, t *theme.Theme, manifest Manifest) (Manifest, error) {
+	lines, missing, err := inlineBlobs(session)
+	if err != nil {
+		return manifest, err
+	}
+	msgs, err := replay.ReadSession(bytes.NewReader(lines), false)
+	if err != nil {
+		return manifest, fmt.Errorf("session: %w", err)
+	}
+	themeJSON, err := theme.ExportThemeToJSON(t)
+	if err != nil {
+		return manifest, err
+	}
+
+	manifest.Format, manifest.Version = Format, Version
+	manifest.Theme = t.ID
+	manifest.Messages = len(msgs)
+	manifest.MissingBlobs = missing
+	if manifest.Created.IsZero() {
+		manifest.Created = time.Now()
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+
+	z := zip.NewWriter(w)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{manifestFile, manifestJSON},
+		{sessionFile, lines},
+		{themeFile, themeJSON},
+	} {
+		f, err := z.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: manifest.Created})
+		if err != nil {
+			return manifest, err
+		}
+		if _, err := f.Write(file.data); err != nil {
+			return manifest, err
+		}
+	}
+	return manifest, z.Close()
+}
+
+// inlineBlobs rewrites blob messages whose content the agent handed over
+// as a file to carry it as base64 instead, so the session stands alone.
+// Those whose file is gone are dropped, and their names returned.
+func inlineBlobs(session []byte) ([]byte, []string, error) {
+	var out bytes.Buffer
+	var missing []string
+	for _, line := range bytes.Split(session, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !protocol.IsBatch(line) {
+			line, name := inlineBlob(line)
+			if name != "" {
+				missing = append(missing, name)
+			}
+			if line != nil {
+				out.Write(line)
+				out.WriteByte('\n')
+			}
+			continue
+		}
+
+		var elems []json.RawMessage
+		if err := json.Unmarshal(line, &elems); err != nil {
+			return nil, nil, fmt.Errorf("session: %w", err)
+		}
+		kept := elems[:0]
+		for _, elem := range elems {
+			elem, name := inlineBlob(elem)
+			if name != "" {
+				missing = append(missing, name)
+			}
+			if elem != nil {
+				kept = append(kept, elem)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		line, err := json.Marshal(kept)
+		if err != nil {
+			return nil, nil, err
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), missing, nil
+}
+
+// inlineBlob returns the message in line with the file of a blob handed
+// over inlined, or nil and the blob's name if the file is gone. Other
+// messages are returned as they are.
+func inlineBlob(line []byte) ([]byte, string) {
+	var msg protocol.Message
+	if json.Unmarshal(line, &msg) != nil || msg.Type != protocol.TypeBlob {
+		return line, ""
+	}
+	var payload protocol.BlobPayload
+	if msg.ParsePayload(&payload) != nil || payload.Path == "" {
+		return line, ""
+	}
+
+	data, err := os.ReadFile(payload.Path)
+	if err != nil {
+		name := payload.Name
+		if name == "" {
+			name = payload.ID
+		}
+		return nil, name
+	}
+	payload.Data = base64.StdEncoding.EncodeToString(data)
+	payload.Encoding = protocol.BlobBase64
+	payload.Size = int64(len(data))
+	payload.Path = ""
+	payload.Chunk, payload.Chunks = 0, 1
+	if msg.Payload, err = json.Marshal(payload); err != nil {
+		return line, ""
+	}
+	msg.Compression = ""
+	if inlined, err := json.Marshal(msg); err == nil {
+		line = inlined
+	}
+	return line, ""
+}
+
+// Open unpacks the bundle at path.
+func Open(path string) (*Bundle, error) {
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a bundle: %w", path, err)
+	}
+	defer z.Close()
+
+	files := map[string][]byte{}
+	for _, f := range z.File {
+		switch f.Name {
+		case manifestFile, sessionFile, themeFile:
+		default:
+			continue
+		}
+		if f.UncompressedSize64 > maxFileSize {
+			return nil, fmt.Errorf("%s in %s is larger than %d bytes", f.Name, path, maxFileSize)
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(r, maxFileSize))
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s in %s: %w", f.Name, path, err)
+		}
+		files[f.Name] = data
+	}
+
+	var b Bundle
+	data, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a bundle: no %s", path, manifestFile)
+	}
+	if err := json.Unmarshal(data, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestFile, err)
+	}
+	switch {
+	case b.Manifest.Format != Format:
+		return nil, fmt.Errorf("%s is not a bundle: format %q", path, b.Manifest.Format)
+	case b.Manifest.Version > Version:
+		return nil, fmt.Errorf("%s is a version %d bundle; this agentui-tui opens up to version %d", path, b.Manifest.Version, Version)
+	}
+
+	if b.Messages, err = replay.ReadSession(bytes.NewReader(files[sessionFile]), false); err != nil {
+		return nil, fmt.Errorf("%s: %w", sessionFile, err)
+	}
+	if data, ok := files[themeFile]; ok {
+		if b.Theme, err = theme.LoadThemeFromJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", themeFile, err)
+		}
+	}
+	if len(b.Messages) == 0 {
+		return nil, errors.New("the bundled session is empty")
+	}
+	return &b, nil
+}
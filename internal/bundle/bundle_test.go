@@ -0,0 +1,84 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+func TestWriteOpen(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(kept, []byte("all good"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	session := fmt.Sprintf(`{"type":"input","payload":{"content":"Make a report"}}
+{"type":"markdown","payload":{"content":"Here it is"}}
+[{"type":"blob","payload":{"id":"r1","name":"report.txt","path":%q}},{"type":"blob","payload":{"id":"r2","name":"gone.txt","path":"/no/such/file"}}]
+`, kept)
+
+	path := filepath.Join(dir, "session"+Ext)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &environment.Snapshot{AgentUI: "1.0.0", Protocol: 2, Agent: &protocol.AgentInfo{Name: "reporter"}}
+	manifest, err := Write(f, []byte(session), theme.Available["charm-dark"], Manifest{Session: "s1", Title: "Make a report", Environment: env})
+	f.Close()
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if manifest.Messages != 3 || len(manifest.MissingBlobs) != 1 || manifest.MissingBlobs[0] != "gone.txt" {
+		t.Errorf("manifest = %+v, want 3 messages and gone.txt missing", manifest)
+	}
+
+	b, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if b.Manifest.Session != "s1" || b.Manifest.Theme != "charm-dark" || b.Theme == nil || b.Theme.ID != "charm-dark" {
+		t.Errorf("opened %+v with theme %v", b.Manifest, b.Theme)
+	}
+	if e := b.Manifest.Environment; e == nil || e.AgentUI != "1.0.0" || e.Agent == nil || e.Agent.Name != "reporter" {
+		t.Errorf("opened environment %+v, want the session's", e)
+	}
+	if len(b.Messages) != 3 || b.Messages[2].Type != protocol.TypeBatch || len(b.Messages[2].Batch) != 1 {
+		t.Fatalf("opened %d messages, want the batch left with the blob found", len(b.Messages))
+	}
+	var blob protocol.BlobPayload
+	if err := b.Messages[2].Batch[0].ParsePayload(&blob); err != nil {
+		t.Fatal(err)
+	}
+	if blob.Path != "" || blob.Data != "YWxsIGdvb2Q=" || blob.Size != 8 {
+		t.Errorf("blob = %+v, want its file inlined", blob)
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	dir := t.TempDir()
+	notZip := filepath.Join(dir, "notes"+Ext)
+	os.WriteFile(notZip, []byte("hello"), 0o600)
+	if _, err := Open(notZip); err == nil {
+		t.Error("Open accepted a file that is not a zip archive")
+	}
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	w, _ := z.Create(manifestFile)
+	fmt.Fprintf(w, `{"format":%q,"version":%d}`, Format, Version+1)
+	w, _ = z.Create(sessionFile)
+	fmt.Fprintln(w, `{"type":"markdown","payload":{"content":"hi"}}`)
+	z.Close()
+	future := filepath.Join(dir, "future"+Ext)
+	os.WriteFile(future, buf.Bytes(), 0o600)
+	if _, err := Open(future); err == nil {
+		t.Error("Open accepted a bundle from a newer version")
+	}
+}
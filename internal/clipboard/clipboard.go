@@ -0,0 +1,117 @@
+// Package clipboard copies text to the system clipboard. The text is sent
+// to the terminal in an OSC 52 escape sequence, which terminals that
+// support it apply on the user's machine, even over SSH. On the machine
+// the TUI runs on it is also handed to the platform's clipboard command,
+// such as pbcopy or wl-copy, for terminals that do not.
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// MaxOSC52 is the most text sent to the terminal with OSC 52, encoded to
+// about 100,000 bytes; some terminals drop longer sequences.
+const MaxOSC52 = 74994
+
+// ErrUnavailable is returned when neither the terminal nor a clipboard
+// command could take the text.
+var ErrUnavailable = errors.New("no clipboard: the output is not a terminal and no clipboard command (pbcopy, wl-copy, xclip or xsel) was found")
+
+// lookPath finds clipboard commands; tests replace it.
+var lookPath = exec.LookPath
+
+// Sequence returns the OSC 52 escape sequence that sets the clipboard to
+// text, wrapped to pass through tmux or GNU screen when running in them,
+// as told by getenv.
+func Sequence(text string, getenv func(string) string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(getenv("TERM"), "screen"):
+		// Screen limits a DCS string to 768 bytes, so the sequence is sent
+		// in pieces
+		var sb strings.Builder
+		for len(seq) > 0 {
+			n := min(len(seq), 76)
+			sb.WriteString("\x1bP" + seq[:n] + "\x1b\\")
+			seq = seq[n:]
+		}
+		return sb.String()
+	}
+	return seq
+}
+
+// command returns the clipboard command to run on goos, or nil if none is
+// installed.
+func command(goos string, getenv func(string) string) []string {
+	var candidates [][]string
+	switch goos {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip.exe"}}
+	default:
+		if getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+	for _, c := range candidates {
+		if _, err := lookPath(c[0]); err == nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// remote reports whether the TUI runs over SSH, where a clipboard command
+// would copy to the remote machine's clipboard rather than the user's.
+func remote(getenv func(string) string) bool {
+	return getenv("SSH_CONNECTION") != "" || getenv("SSH_TTY") != ""
+}
+
+// Copy sets the clipboard to text: with OSC 52 if terminal is a terminal
+// and text not too long for it, and with a clipboard command unless
+// running over SSH. It fails only if neither could be tried.
+func Copy(text string, terminal *os.File) error {
+	sent := false
+	if isTerminal(terminal) && len(text) <= MaxOSC52 {
+		if _, err := terminal.WriteString(Sequence(text, os.Getenv)); err == nil {
+			sent = true
+		}
+	}
+	var args []string
+	if !remote(os.Getenv) {
+		args = command(runtime.GOOS, os.Getenv)
+	}
+	if args == nil {
+		if !sent {
+			return ErrUnavailable
+		}
+		return nil
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil && !sent {
+		return err
+	}
+	return nil
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
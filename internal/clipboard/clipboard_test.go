@@ -0,0 +1,57 @@
+package clipboard
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestSequence(t *testing.T) {
+	plain := Sequence("hi", env(nil))
+	if plain != "\x1b]52;c;aGk=\a" {
+		t.Errorf("Sequence = %q", plain)
+	}
+	tmux := Sequence("hi", env(map[string]string{"TMUX": "/tmp/tmux-0/default,1,0"}))
+	if tmux != "\x1bPtmux;\x1b\x1b]52;c;aGk=\a\x1b\\" {
+		t.Errorf("Sequence in tmux = %q", tmux)
+	}
+	screen := Sequence(strings.Repeat("x", 200), env(map[string]string{"TERM": "screen-256color"}))
+	if n := strings.Count(screen, "\x1bP"); n < 2 {
+		t.Errorf("Sequence in screen is sent in %d pieces, want it split", n)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	defer func(old func(string) (string, error)) { lookPath = old }(lookPath)
+	installed := []string{"xclip", "wl-copy"}
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{"wayland", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, "wl-copy"},
+		{"x11", "linux", nil, "xclip"},
+		{"macos without pbcopy", "darwin", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := command(tt.goos, env(tt.env))
+			if (got == nil) != (tt.want == "") || (got != nil && got[0] != tt.want) {
+				t.Errorf("command = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
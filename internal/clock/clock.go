@@ -0,0 +1,121 @@
+// Package clock formats times and durations for display, following the
+// user's locale unless the configuration picks a 12 or 24 hour clock.
+package clock
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Hour cycle settings.
+const (
+	Locale = ""    // follow the locale
+	Hour12 = "12h" // 3:04 PM
+	Hour24 = "24h" // 15:04
+)
+
+// hour12Regions are the countries whose locales use a 12 hour clock.
+var hour12Regions = map[string]bool{
+	"US": true, "CA": true, "AU": true, "NZ": true, "PH": true, "IN": true,
+	"PK": true, "BD": true, "EG": true, "SA": true, "JO": true, "MY": true,
+	"KR": true, "TW": true, "CO": true, "MX": true,
+}
+
+// Clock formats times and durations.
+type Clock struct {
+	// Hour12 uses a 12 hour clock with AM and PM
+	Hour12 bool
+	// UTC shows times in UTC instead of local time, for log-style output
+	UTC bool
+	// Layout, if set, formats times of day instead of the hour cycle, in
+	// Go's reference time format, such as "15:04:05"
+	Layout string
+}
+
+// New returns the clock for the hour cycle setting (Hour12, Hour24 or
+// Locale) and the UTC toggle.
+func New(setting string, utc bool) (Clock, error) {
+	c := Clock{UTC: utc}
+	switch setting {
+	case Hour12:
+		c.Hour12 = true
+	case Hour24:
+	case Locale:
+		c.Hour12 = Uses12Hour(CurrentLocale())
+	default:
+		return c, fmt.Errorf("unknown hour cycle %q (want %s or %s)", setting, Hour12, Hour24)
+	}
+	return c, nil
+}
+
+// CurrentLocale returns the locale used for times, from LC_ALL, LC_TIME or
+// LANG in that order.
+func CurrentLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Uses12Hour reports whether locale, such as "en_US.UTF-8", uses a 12 hour
+// clock.
+func Uses12Hour(locale string) bool {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	_, region, ok := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	return ok && hour12Regions[strings.ToUpper(region)]
+}
+
+// Time formats the time of day of t.
+func (c Clock) Time(t time.Time) string {
+	layout := "15:04"
+	switch {
+	case c.Layout != "":
+		layout = c.Layout
+	case c.Hour12:
+		layout = "3:04 PM"
+	}
+	if c.UTC {
+		return t.UTC().Format(layout) + " UTC"
+	}
+	return t.Local().Format(layout)
+}
+
+// Ago formats how long before now t was, to the minute, such as "just
+// now" or "5m ago"; times a day or more ago show as the time of day.
+func (c Clock) Ago(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	}
+	return c.Time(t)
+}
+
+// Duration formats d to the second, or to the minute once it reaches an
+// hour: "45s", "2m 05s", "1h 02m".
+func (c Clock) Duration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = 0
+	}
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	s := int(d % time.Minute / time.Second)
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh %02dm", h, m)
+	case m > 0:
+		return fmt.Sprintf("%dm %02ds", m, s)
+	default:
+		return fmt.Sprintf("%ds", s)
+	}
+}
@@ -0,0 +1,85 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUses12Hour(t *testing.T) {
+	tests := map[string]bool{
+		"en_US.UTF-8": true,
+		"en-AU":       true,
+		"ko_KR.UTF-8": true,
+		"en_GB.UTF-8": false,
+		"de_DE@euro":  false,
+		"C":           false,
+		"":            false,
+	}
+	for locale, want := range tests {
+		if got := Uses12Hour(locale); got != want {
+			t.Errorf("Uses12Hour(%q) = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	at := time.Date(2024, 3, 1, 15, 4, 5, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		clock Clock
+		want  string
+	}{
+		{Clock{UTC: true}, "14:04 UTC"},
+		{Clock{Hour12: true, UTC: true}, "2:04 PM UTC"},
+		{Clock{Hour12: true, UTC: true, Layout: "15:04:05"}, "14:04:05 UTC"},
+	}
+	for _, tt := range tests {
+		if got := tt.clock.Time(at); got != tt.want {
+			t.Errorf("%+v.Time() = %q, want %q", tt.clock, got, tt.want)
+		}
+	}
+}
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2024, 3, 1, 15, 4, 5, 0, time.UTC)
+	tests := map[time.Duration]string{
+		-time.Second:                "just now",
+		30 * time.Second:            "just now",
+		5*time.Minute + time.Second: "5m ago",
+		2*time.Hour + time.Minute:   "2h ago",
+		25 * time.Hour:              "14:04 UTC",
+	}
+	for d, want := range tests {
+		if got := (Clock{UTC: true}).Ago(now.Add(-d), now); got != want {
+			t.Errorf("Ago(%v before) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                     "0s",
+		-time.Second:                          "0s",
+		45*time.Second + 400*time.Millisecond: "45s",
+		2*time.Minute + 5*time.Second:         "2m 05s",
+		time.Hour + 2*time.Minute + 59*time.Second: "1h 02m",
+	}
+	for d, want := range tests {
+		if got := (Clock{}).Duration(d); got != want {
+			t.Errorf("Duration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_TIME", "en_US.UTF-8")
+	if c, err := New(Locale, false); err != nil || !c.Hour12 {
+		t.Errorf("New(Locale) with en_US = %+v, %v; want a 12 hour clock", c, err)
+	}
+	if c, err := New(Hour24, true); err != nil || c.Hour12 || !c.UTC {
+		t.Errorf("New(Hour24, true) = %+v, %v", c, err)
+	}
+	if _, err := New("13h", false); err == nil {
+		t.Error("New accepted an unknown setting")
+	}
+}
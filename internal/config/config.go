@@ -0,0 +1,100 @@
+// Package config reads and writes the TUI's user configuration file, which
+// holds the choices made in the first-run setup.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Config is the user configuration. Command line flags take precedence
+// over it.
+type Config struct {
+	// Theme is the ID of the theme to use when --theme is not given.
+	Theme string `json:"theme,omitempty"`
+
+	// SessionsDir is where sessions are saved to reopen with --resume when
+	// --history-dir is not given. Empty saves them in the history's default
+	// directory.
+	SessionsDir string `json:"sessions_dir,omitempty"`
+
+	// Telemetry opts in to anonymous usage statistics, kept in a local
+	// report; see package telemetry.
+	Telemetry bool `json:"telemetry,omitempty"`
+
+	// Clock is "12h" or "24h" to override the locale's hour cycle when
+	// --clock is not given; see package clock.
+	Clock string `json:"clock,omitempty"`
+
+	// UTC shows times in UTC, as in logs, unless --utc=false is given.
+	UTC bool `json:"utc,omitempty"`
+
+	// ColorVision is "deuteranopia" or "protanopia" to replace the semantic
+	// colors of every theme with color blind safe ones; see
+	// theme.SetColorVision.
+	ColorVision string `json:"color_vision,omitempty"`
+
+	// Glyphs is "ascii" or "unicode" to override the glyph profile of
+	// every theme when --glyphs is not given; see theme.SetGlyphs.
+	Glyphs string `json:"glyphs,omitempty"`
+
+	// AgentControl lets agents scroll the transcript, focus the input and
+	// collapse messages unless --agent-control=false is given.
+	AgentControl bool `json:"agent_control,omitempty"`
+
+	// TabOrder is the order Tab moves focus through the chat view's panels
+	// when --tab-order is not given, e.g. ["input", "transcript"].
+	TabOrder []string `json:"tab_order,omitempty"`
+
+	// HideHints hides the line of keyboard hints under prompts and
+	// viewers unless --hints is given; see theme.Hints.
+	HideHints bool `json:"hide_hints,omitempty"`
+
+	// Schedules send the agent prompts or ticks while a session is open
+	// when --schedule is not given, e.g. ["every 15m: Check the queue"];
+	// see package schedule.
+	Schedules []string `json:"schedules,omitempty"`
+}
+
+// Path returns the location of the configuration file, or an error if the
+// user has no configuration directory.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "agentui", "config.json"), nil
+}
+
+// DefaultSessionsDir returns a sessions directory next to the configuration
+// file, suggested when there is no data directory to keep the history in.
+func DefaultSessionsDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "sessions")
+}
+
+// Load reads the configuration at path. It returns an error satisfying
+// errors.Is(err, fs.ErrNotExist) if there is none yet.
+func Load(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Save writes cfg to path, creating its directory if needed.
+func Save(path string, cfg Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
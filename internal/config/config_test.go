@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agentui", "config.json")
+
+	if _, err := Load(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Load before Save = %v, want fs.ErrNotExist", err)
+	}
+
+	want := Config{
+		Theme:       "charm-light",
+		SessionsDir: DefaultSessionsDir(path),
+		TabOrder:    []string{"transcript", "input"},
+		Schedules:   []string{"every 15m: Check the queue"},
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+	if want.SessionsDir != filepath.Join(filepath.Dir(path), "sessions") {
+		t.Errorf("DefaultSessionsDir = %q, want a sibling of the config file", want.SessionsDir)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{theme"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Load of invalid JSON = %v, want a parse error", err)
+	}
+}
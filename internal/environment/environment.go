@@ -0,0 +1,112 @@
+// Package environment describes where a session ran: the TUI's version
+// and protocol version, its theme, the terminal, the operating system and
+// the agent. Session bundles and crash reports carry it, so a problem
+// reported from another machine can be matched to the versions involved.
+package environment
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// Snapshot is the environment of a session.
+type Snapshot struct {
+	AgentUI  string `json:"agentui"`
+	Protocol int    `json:"protocol"`
+	Theme    string `json:"theme,omitempty"`
+	Terminal string `json:"terminal,omitempty"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	// Agent is how the agent identified itself in its hello, if it did
+	Agent *protocol.AgentInfo `json:"agent,omitempty"`
+}
+
+// Capture returns the environment of a TUI of the given version drawing
+// in themeName. The agent is filled in once it says hello.
+func Capture(version, themeName string) Snapshot {
+	return Snapshot{
+		AgentUI:  version,
+		Protocol: protocol.ProtocolVersion,
+		Theme:    themeName,
+		Terminal: terminal(os.Getenv),
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+	}
+}
+
+// terminal names the terminal emulator, with the TERM it sets, such as
+// "iTerm.app 3.5.0 (xterm-256color)", or just TERM if it does not say.
+func terminal(getenv func(string) string) string {
+	name := strings.TrimSpace(getenv("TERM_PROGRAM") + " " + getenv("TERM_PROGRAM_VERSION"))
+	term := getenv("TERM")
+	switch {
+	case name == "":
+		return term
+	case term == "":
+		return name
+	}
+	return name + " (" + term + ")"
+}
+
+// Lines returns the snapshot as "name: value" lines, leaving out what is
+// not known.
+func (s Snapshot) Lines() []string {
+	lines := []string{
+		"agentui: " + s.AgentUI,
+		fmt.Sprintf("protocol: %d", s.Protocol),
+	}
+	add := func(name, value string) {
+		if value != "" {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	add("theme", s.Theme)
+	add("terminal", s.Terminal)
+	add("os", s.OS+"/"+s.Arch)
+	if s.Agent != nil {
+		add("agent", Agent(*s.Agent))
+	}
+	return lines
+}
+
+// Agent describes an agent in a few words, such as "helper 1.2
+// (agentui-python 0.1.0)".
+func Agent(a protocol.AgentInfo) string {
+	s := strings.TrimSpace(a.Name + " " + a.Version)
+	if a.SDK != "" {
+		s = strings.TrimSpace(s + " (" + a.SDK + ")")
+	}
+	return s
+}
+
+// WriteCrashReport writes a report of the panic v, raised at stack, in a
+// new file in dir, returning its path.
+func WriteCrashReport(dir string, s Snapshot, v any, stack []byte, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "agentui crash report, %s\n\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "panic: %v\n\n", v)
+	for _, line := range s.Lines() {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+	sb.Write(stack)
+
+	f, err := os.CreateTemp(dir, "crash-"+now.Format("2006-01-02-150405")+"-*.txt")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return filepath.Clean(f.Name()), f.Close()
+}
@@ -0,0 +1,61 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+func TestTerminal(t *testing.T) {
+	for _, tt := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"TERM_PROGRAM": "WezTerm", "TERM_PROGRAM_VERSION": "20240203", "TERM": "xterm-256color"}, "WezTerm 20240203 (xterm-256color)"},
+		{map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, "Apple_Terminal"},
+		{map[string]string{"TERM": "screen"}, "screen"},
+		{map[string]string{}, ""},
+	} {
+		if got := terminal(func(key string) string { return tt.env[key] }); got != tt.want {
+			t.Errorf("terminal(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "crashes")
+	env := Snapshot{
+		AgentUI: "1.0.0", Protocol: 2, Theme: "charm-dark", OS: "linux", Arch: "arm64",
+		Agent: &protocol.AgentInfo{Name: "helper", SDK: "agentui-python 0.1.0"},
+	}
+	path, err := WriteCrashReport(dir, env, "index out of range", []byte("goroutine 1 [running]:\n"), time.Now())
+	if err != nil {
+		t.Fatalf("WriteCrashReport failed: %v", err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Errorf("report written to %s, want in %s", path, dir)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"panic: index out of range",
+		"agentui: 1.0.0",
+		"protocol: 2",
+		"os: linux/arm64",
+		"agent: helper (agentui-python 0.1.0)",
+		"goroutine 1",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("report lacks %q:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "terminal:") {
+		t.Error("report names a terminal that was not known")
+	}
+}
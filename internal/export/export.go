@@ -0,0 +1,134 @@
+// Package export writes individual transcript items (tables, code, markdown) to files.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// languageExtensions maps code languages to file extensions.
+var languageExtensions = map[string]string{
+	"python":     "py",
+	"go":         "go",
+	"golang":     "go",
+	"javascript": "js",
+	"typescript": "ts",
+	"rust":       "rs",
+	"ruby":       "rb",
+	"java":       "java",
+	"c":          "c",
+	"cpp":        "cpp",
+	"c++":        "cpp",
+	"csharp":     "cs",
+	"bash":       "sh",
+	"sh":         "sh",
+	"shell":      "sh",
+	"json":       "json",
+	"yaml":       "yaml",
+	"toml":       "toml",
+	"html":       "html",
+	"css":        "css",
+	"sql":        "sql",
+	"markdown":   "md",
+}
+
+// ExtensionFor returns the file extension for a code language, or "txt" if unknown.
+func ExtensionFor(language string) string {
+	if ext, ok := languageExtensions[strings.ToLower(language)]; ok {
+		return ext
+	}
+	return "txt"
+}
+
+// Filename builds a timestamped filename such as "agentui-table-20260115-093000.csv".
+func Filename(kind, ext string, t time.Time) string {
+	return fmt.Sprintf("agentui-%s-%s.%s", kind, t.Format("20060102-150405"), ext)
+}
+
+// WriteTableCSV writes a table as CSV with the columns as the header row.
+func WriteTableCSV(w io.Writer, columns []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// SaveTableCSV writes a table to a CSV file in dir and returns its path.
+func SaveTableCSV(dir string, columns []string, rows [][]string) (string, error) {
+	return save(dir, "table", "csv", func(w io.Writer) error {
+		if err := WriteTableCSV(w, columns, rows); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return nil
+	})
+}
+
+// SaveCode writes a code block to a file in dir, choosing the extension from the language.
+func SaveCode(dir, code, language string) (string, error) {
+	return saveText(dir, "code", ExtensionFor(language), code)
+}
+
+// SaveMarkdown writes markdown content to a .md file in dir.
+func SaveMarkdown(dir, content string) (string, error) {
+	return saveText(dir, "message", "md", content)
+}
+
+// SaveTranscript writes a whole transcript, as Markdown, to a .md file in dir.
+func SaveTranscript(dir, content string) (string, error) {
+	return saveText(dir, "transcript", "md", content)
+}
+
+// saveText writes content, ending in a newline, to a new file in dir.
+func saveText(dir, kind, ext, content string) (string, error) {
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return save(dir, kind, ext, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+}
+
+// maxSuffix is the most files of a kind save numbers within one second.
+const maxSuffix = 100
+
+// save creates a new file in dir named by Filename and writes it with
+// write, returning its path. A file already there is never overwritten:
+// exports within the same second get a -2, -3… suffix.
+func save(dir, kind, ext string, write func(io.Writer) error) (string, error) {
+	name := Filename(kind, ext, time.Now())
+	base := strings.TrimSuffix(name, "."+ext)
+	var f *os.File
+	var path string
+	for n := 1; f == nil; n++ {
+		path = filepath.Join(dir, name)
+		if n > 1 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d.%s", base, n, ext))
+		}
+		var err error
+		f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil && (!errors.Is(err, fs.ErrExist) || n == maxSuffix) {
+			return "", fmt.Errorf("failed to create %s: %w", path, err)
+		}
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtensionFor(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"python", "py"},
+		{"Go", "go"},
+		{"typescript", "ts"},
+		{"", "txt"},
+		{"brainfuck", "txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			if got := ExtensionFor(tt.language); got != tt.want {
+				t.Errorf("ExtensionFor(%q) = %q, want %q", tt.language, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilename(t *testing.T) {
+	ts := time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC)
+	got := Filename("table", "csv", ts)
+	if got != "agentui-table-20260115-093000.csv" {
+		t.Errorf("Filename() = %q", got)
+	}
+}
+
+func TestSaveDoesNotOverwrite(t *testing.T) {
+	dir := t.TempDir()
+
+	var paths []string
+	for _, content := range []string{"one", "two", "three"} {
+		path, err := SaveMarkdown(dir, content)
+		if err != nil {
+			t.Fatalf("SaveMarkdown failed: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	for i, content := range []string{"one", "two", "three"} {
+		data, err := os.ReadFile(paths[i])
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", paths[i], err)
+		}
+		if string(data) != content+"\n" {
+			t.Errorf("%s = %q, want %q", paths[i], data, content+"\n")
+		}
+	}
+	// Within a second, the later exports are numbered
+	if paths[0] == paths[1] || paths[1] == paths[2] {
+		t.Errorf("exports share a path: %q", paths)
+	}
+}
+
+func TestWriteTableCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteTableCSV(&buf, []string{"Name", "Note"}, [][]string{
+		{"alpha", "plain"},
+		{"beta", "has, comma"},
+	})
+	if err != nil {
+		t.Fatalf("WriteTableCSV failed: %v", err)
+	}
+
+	want := "Name,Note\nalpha,plain\nbeta,\"has, comma\"\n"
+	if buf.String() != want {
+		t.Errorf("WriteTableCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSaveCode(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := SaveCode(dir, "print('hi')", "python")
+	if err != nil {
+		t.Fatalf("SaveCode failed: %v", err)
+	}
+
+	if filepath.Ext(path) != ".py" {
+		t.Errorf("SaveCode() path = %s, want .py extension", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read saved file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "print('hi')") {
+		t.Errorf("Saved content = %q", string(data))
+	}
+}
@@ -0,0 +1,314 @@
+// Package history keeps the transcripts of past sessions on disk, so a
+// conversation can be reopened later with the TUI rendering it as it did
+// at the time. Each session is a journal (see package journal) of the
+// messages the agent sent and the input the user typed, in a store
+// directory.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/journal"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/replay"
+)
+
+// SessionSize is the most disk space a session uses. Longer sessions keep
+// their most recent messages.
+const SessionSize = 32 << 20 // 32 MiB
+
+// Latest names the most recent session to Resolve.
+const Latest = "last"
+
+// ext is the file extension of sessions in the store, and envExt that of
+// the environment saved beside each.
+const (
+	ext    = ".jsonl"
+	envExt = ".env.json"
+)
+
+// titleScan is how much of a session is read to find its title.
+const titleScan = 64 << 10
+
+// DefaultDir returns the store directory used unless configured:
+// agentui/sessions in $XDG_DATA_HOME, or in ~/.local/share without it.
+func DefaultDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+// DataDir returns the directory agentui keeps its data in: agentui in
+// $XDG_DATA_HOME, or in ~/.local/share without it.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "agentui"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "agentui"), nil
+}
+
+// Info describes a session in the store.
+type Info struct {
+	Name     string
+	Modified time.Time
+	Size     int64
+	// Title is the first input the user sent, or empty if there was none
+	Title string
+}
+
+// Store is a directory of sessions.
+type Store struct {
+	dir string
+}
+
+// NewStore returns the store in dir, which is created when the first
+// session is.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the store's directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Path returns the file of session name in the store.
+func (s *Store) Path(name string) string {
+	return filepath.Join(s.dir, name+ext)
+}
+
+// List returns the sessions in the store, most recent first.
+func (s *Store) List() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Info
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ext)
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		head, size, err := journal.Head(s.Path(name), titleScan)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, Info{Name: name, Modified: info.ModTime(), Size: size, Title: Title(head)})
+	}
+	slices.SortFunc(sessions, func(a, b Info) int {
+		return b.Modified.Compare(a.Modified)
+	})
+	return sessions, nil
+}
+
+// firstInput returns the content of the first input message in the lines
+// of a session.
+func Title(lines []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(lines))
+	scanner.Buffer(make([]byte, 0, 4096), titleScan)
+	for scanner.Scan() {
+		var msg struct {
+			Type    protocol.MessageType  `json:"type"`
+			Payload protocol.InputPayload `json:"payload"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &msg) == nil && msg.Type == protocol.TypeInput {
+			return msg.Payload.Content
+		}
+	}
+	return ""
+}
+
+// Resolve returns the name of a session given as its name, its file in
+// the store, or Latest.
+func (s *Store) Resolve(name string) (string, error) {
+	if name == Latest {
+		sessions, err := s.List()
+		if err != nil {
+			return "", err
+		}
+		if len(sessions) == 0 {
+			return "", fmt.Errorf("no sessions in %s", s.dir)
+		}
+		return sessions[0].Name, nil
+	}
+	name = strings.TrimSuffix(filepath.Base(name), ext)
+	if _, err := os.Stat(s.Path(name)); err != nil {
+		return "", fmt.Errorf("no session %q in %s", name, s.dir)
+	}
+	return name, nil
+}
+
+// Environment returns the environment session name ran in, or nil if
+// none was saved with it.
+func (s *Store) Environment(name string) (*environment.Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, name+envExt))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var env environment.Snapshot
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("session %s environment: %w", name, err)
+	}
+	return &env, nil
+}
+
+// saveEnvironment saves env beside session name.
+func (s *Store) saveEnvironment(name string, env environment.Snapshot) error {
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, name+envExt), append(data, '\n'), 0o600)
+}
+
+// Open reads the session name to rebuild its transcript from and
+// continues it, so what follows is added to it.
+func (s *Store) Open(name string) (*journal.Journal, []*protocol.Message, error) {
+	data, err := journal.Read(s.Path(name))
+	if err != nil {
+		return nil, nil, err
+	}
+	msgs, err := replay.ReadSession(bytes.NewReader(data), false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("session %s: %w", name, err)
+	}
+	j, err := journal.Open(s.Path(name), SessionSize)
+	return j, msgs, err
+}
+
+// create starts a new session named for when it started.
+func (s *Store) create(now time.Time) (string, *journal.Journal, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", nil, err
+	}
+	base := now.Format("2006-01-02-150405")
+	name := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(s.Path(name)); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	j, err := journal.Create(s.Path(name), SessionSize)
+	return name, j, err
+}
+
+// Recorder journals the running session to a store, for the protocol
+// handler to write messages to. The session is only created with its
+// first message, so sessions where nothing was said leave nothing behind,
+// and the recorder can move on to a session the user reopens.
+type Recorder struct {
+	mu      sync.Mutex
+	store   *Store
+	name    string
+	journal *journal.Journal
+	env     *environment.Snapshot
+}
+
+// NewRecorder records a new session in store.
+func NewRecorder(store *Store) *Recorder {
+	return &Recorder{store: store}
+}
+
+// Store returns the store the recorder records to.
+func (r *Recorder) Store() *Store {
+	return r.store
+}
+
+// Name returns the name of the session being recorded, or empty if it
+// has not started.
+func (r *Recorder) Name() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.name
+}
+
+// Write appends message lines to the session, starting it if need be.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.journal == nil {
+		name, j, err := r.store.create(time.Now())
+		if err != nil {
+			return 0, err
+		}
+		r.name, r.journal = name, j
+		r.saveEnvironment()
+	}
+	return r.journal.Write(p)
+}
+
+// SetEnvironment saves env beside the session, now if it has started or
+// else when it does.
+func (r *Recorder) SetEnvironment(env environment.Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.env = &env
+	if r.journal != nil {
+		r.saveEnvironment()
+	}
+}
+
+// saveEnvironment saves the environment set, if any, beside the session.
+// It only helps bug reports, so failing to is not worth stopping for.
+func (r *Recorder) saveEnvironment() {
+	if r.env != nil {
+		r.store.saveEnvironment(r.name, *r.env)
+	}
+}
+
+// Resume opens session name, returning the messages to rebuild its
+// transcript from, and records to it from now on instead.
+func (r *Recorder) Resume(name string) ([]*protocol.Message, error) {
+	j, msgs, err := r.store.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.journal != nil {
+		r.journal.Close()
+	}
+	r.name, r.journal = name, j
+	r.saveEnvironment()
+	return msgs, nil
+}
+
+// Close closes the session being recorded.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.Close()
+}
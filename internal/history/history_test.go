@@ -0,0 +1,119 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flight505/agentui/internal/environment"
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+func writeLines(t *testing.T, r *Recorder, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := r.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+}
+
+func TestRecorderStartsSessionLazily(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sessions")
+	r := NewRecorder(NewStore(dir))
+	if r.Name() != "" {
+		t.Error("a session started before anything was written")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("the store was created before anything was written")
+	}
+
+	writeLines(t, r,
+		`{"type":"input","payload":{"content":"What is new?"}}`,
+		`{"type":"markdown","payload":{"content":"Not much"}}`,
+	)
+	r.Close()
+
+	sessions, err := r.Store().List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != r.Name() {
+		t.Fatalf("sessions = %+v, want %s", sessions, r.Name())
+	}
+	if sessions[0].Title != "What is new?" {
+		t.Errorf("title = %q, want the first input", sessions[0].Title)
+	}
+}
+
+func TestResumeContinuesSession(t *testing.T) {
+	store := NewStore(t.TempDir())
+	first := NewRecorder(store)
+	writeLines(t, first, `{"type":"input","payload":{"content":"hi"}}`)
+	first.Close()
+
+	// An older session, to check Latest picks by time
+	old, j, err := store.create(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	os.Chtimes(store.Path(old), time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+
+	name, err := store.Resolve(Latest)
+	if err != nil || name != first.Name() {
+		t.Fatalf("Resolve(last) = %q, %v; want %q", name, err, first.Name())
+	}
+	if _, err := store.Resolve("no-such-session"); err == nil {
+		t.Error("Resolve accepted a missing session")
+	}
+
+	second := NewRecorder(store)
+	msgs, err := second.Resume(name)
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Type != protocol.TypeInput {
+		t.Fatalf("resumed %+v, want the input", msgs)
+	}
+	writeLines(t, second, `{"type":"markdown","payload":{"content":"hello"}}`)
+	second.Close()
+
+	j, msgs, err = store.Open(name)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	j.Close()
+	if len(msgs) != 2 || msgs[1].Type != protocol.TypeMarkdown {
+		t.Errorf("the session holds %d messages, want the reply added", len(msgs))
+	}
+}
+
+func TestRecorderSavesEnvironment(t *testing.T) {
+	store := NewStore(t.TempDir())
+	r := NewRecorder(store)
+	env := environment.Snapshot{AgentUI: "1.0.0", Protocol: 2, OS: "linux", Arch: "amd64"}
+	r.SetEnvironment(env)
+	writeLines(t, r, `{"type":"input","payload":{"content":"hi"}}`)
+
+	// The agent says who it is after the session started
+	env.Agent = &protocol.AgentInfo{Name: "helper", Version: "2.1"}
+	r.SetEnvironment(env)
+	r.Close()
+
+	saved, err := store.Environment(r.Name())
+	if err != nil {
+		t.Fatalf("Environment failed: %v", err)
+	}
+	if saved == nil || saved.AgentUI != "1.0.0" || saved.Agent == nil || saved.Agent.Name != "helper" {
+		t.Errorf("saved environment = %+v, want the latest set", saved)
+	}
+	sessions, _ := store.List()
+	if len(sessions) != 1 {
+		t.Errorf("listed %d sessions, want the environment file left out", len(sessions))
+	}
+	if env, err := store.Environment("no-such-session"); env != nil || err != nil {
+		t.Errorf("Environment of a session without one = %v, %v", env, err)
+	}
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// MaxPrompts is how many of the latest prompts are kept.
+const MaxPrompts = 1000
+
+// DefaultPromptsPath returns the prompts file used unless configured:
+// agentui/prompts.jsonl in $XDG_DATA_HOME, or in ~/.local/share without
+// it.
+func DefaultPromptsPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prompts.jsonl"), nil
+}
+
+// Prompts is the input the user sent, across sessions, for recalling like
+// a shell's history. The file holds a JSON string per line, oldest first,
+// so prompts may span lines.
+type Prompts struct {
+	mu    sync.Mutex
+	path  string
+	list  []string
+	lines int // in the file, which is rewritten when it grows too long
+}
+
+// OpenPrompts reads the prompts in the file at path, which is created
+// when the first prompt is added. An empty path keeps prompts in memory
+// only.
+func OpenPrompts(path string) (*Prompts, error) {
+	p := &Prompts{path: path}
+	if path == "" {
+		return p, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	for scanner.Scan() {
+		var prompt string
+		p.lines++
+		// Skip lines a crash left half written
+		if json.Unmarshal(scanner.Bytes(), &prompt) == nil {
+			p.list = append(p.list, prompt)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.list) > MaxPrompts {
+		p.list = p.list[len(p.list)-MaxPrompts:]
+	}
+	return p, nil
+}
+
+// List returns the prompts, oldest first.
+func (p *Prompts) List() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.list...)
+}
+
+// Add records prompt as the latest, unless it is blank or repeats the
+// latest.
+func (p *Prompts) Add(prompt string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if strings.TrimSpace(prompt) == "" || (len(p.list) > 0 && p.list[len(p.list)-1] == prompt) {
+		return nil
+	}
+	p.list = append(p.list, prompt)
+	if len(p.list) > MaxPrompts {
+		p.list = p.list[len(p.list)-MaxPrompts:]
+	}
+	if p.path == "" {
+		return nil
+	}
+
+	// Append, rewriting the file once it holds twice what is kept
+	if p.lines >= 2*MaxPrompts {
+		return p.rewrite()
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	line, _ := json.Marshal(prompt)
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	p.lines++
+	return f.Close()
+}
+
+// rewrite replaces the file with the prompts kept.
+func (p *Prompts) rewrite() error {
+	var sb strings.Builder
+	for _, prompt := range p.list {
+		line, _ := json.Marshal(prompt)
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return err
+	}
+	p.lines = len(p.list)
+	return nil
+}
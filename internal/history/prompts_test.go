@@ -0,0 +1,87 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPromptsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agentui", "prompts.jsonl")
+	p, err := OpenPrompts(path)
+	if err != nil {
+		t.Fatalf("OpenPrompts failed: %v", err)
+	}
+	for _, prompt := range []string{"first", "two\nlines", "two\nlines", "  ", "last"} {
+		if err := p.Add(prompt); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	want := []string{"first", "two\nlines", "last"}
+	if got := p.List(); !slices.Equal(got, want) {
+		t.Errorf("List = %q, want %q without repeats or blanks", got, want)
+	}
+
+	// A half-written line is skipped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`"cut sh`)
+	f.Close()
+
+	reopened, err := OpenPrompts(path)
+	if err != nil {
+		t.Fatalf("OpenPrompts failed: %v", err)
+	}
+	if got := reopened.List(); !slices.Equal(got, want) {
+		t.Errorf("reopened List = %q, want %q", got, want)
+	}
+}
+
+func TestPromptsKeepLatest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.jsonl")
+	p, err := OpenPrompts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 2*MaxPrompts + 10
+	for i := range total {
+		if err := p.Add(fmt.Sprintf("prompt %d", i)); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	list := p.List()
+	if len(list) != MaxPrompts || list[len(list)-1] != fmt.Sprintf("prompt %d", total-1) {
+		t.Errorf("kept %d prompts ending %q, want the latest %d", len(list), list[len(list)-1], MaxPrompts)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines > 2*MaxPrompts {
+		t.Errorf("file has %d lines, want it rewritten", lines)
+	}
+	reopened, err := OpenPrompts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(reopened.List(), list) {
+		t.Error("reopened prompts differ from those kept")
+	}
+}
+
+func TestPromptsInMemory(t *testing.T) {
+	p, err := OpenPrompts("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Add("hello")
+	if got := p.List(); !slices.Equal(got, []string{"hello"}) {
+		t.Errorf("List = %q", got)
+	}
+}
@@ -0,0 +1,171 @@
+// Package journal keeps a write-ahead record of the messages an agent sent,
+// so the transcript can be rebuilt after the TUI crashes or its terminal
+// goes away.
+package journal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// DefaultSize is the most disk space a journal uses.
+const DefaultSize = 8 << 20 // 8 MiB
+
+// Journal is a ring of two files holding the most recent message lines:
+// the current segment at its path, and the previous one beside it with a
+// ".1" suffix. When the current segment would grow past half the size
+// limit it replaces the previous one, so the oldest lines are dropped.
+//
+// Lines are written straight to the file, unbuffered, so they survive the
+// process dying as soon as Write returns.
+type Journal struct {
+	mu    sync.Mutex
+	path  string
+	limit int64 // bytes per segment
+	file  *os.File
+	size  int64
+}
+
+// Create starts a new journal at path, removing any earlier one. A size
+// of zero or less uses DefaultSize.
+func Create(path string, size int64) (*Journal, error) {
+	if err := os.Remove(previous(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return newJournal(path, size, f, 0), nil
+}
+
+// Open continues the journal at path, creating it if there is none. A line
+// left incomplete by a crash is discarded first.
+func Open(path string, size int64) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	end := int64(bytes.LastIndexByte(data, '\n') + 1)
+	if err := f.Truncate(end); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(end, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return newJournal(path, size, f, end), nil
+}
+
+func newJournal(path string, size int64, f *os.File, used int64) *Journal {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Journal{path: path, limit: size / 2, file: f, size: used}
+}
+
+// Write appends complete lines to the journal, starting a new segment
+// first if they would overflow the current one.
+func (j *Journal) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return 0, fs.ErrClosed
+	}
+	if j.size > 0 && j.size+int64(len(p)) > j.limit {
+		if err := j.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := j.file.Write(p)
+	j.size += int64(n)
+	return n, err
+}
+
+// rotate makes the current segment the previous one and starts another.
+func (j *Journal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	j.file = nil
+	if err := os.Rename(j.path, previous(j.path)); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	j.file = f
+	j.size = 0
+	return nil
+}
+
+// Close closes the journal, leaving it on disk.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Read returns the complete lines of the journal at path, oldest first,
+// in the format of a recorded session.
+func Read(path string) ([]byte, error) {
+	old, err := os.ReadFile(previous(path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	current = current[:bytes.LastIndexByte(current, '\n')+1]
+	return append(old, current...), nil
+}
+
+// Head returns up to n bytes from the start of the journal at path, its
+// oldest lines, and the total size of its segments.
+func Head(path string, n int) ([]byte, int64, error) {
+	var size int64
+	first := ""
+	for _, p := range []string{previous(path), path} {
+		info, err := os.Stat(p)
+		if errors.Is(err, fs.ErrNotExist) && p != path {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		size += info.Size()
+		if first == "" {
+			first = p
+		}
+	}
+
+	f, err := os.Open(first)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, int64(n)))
+	return data, size, err
+}
+
+// previous returns the path of the previous segment of the journal at path.
+func previous(path string) string {
+	return path + ".1"
+}
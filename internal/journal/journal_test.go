@@ -0,0 +1,107 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func line(i int) string {
+	return fmt.Sprintf(`{"type":"text","payload":{"content":"line %02d"}}`+"\n", i)
+}
+
+func TestJournalRing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	size := int64(4 * len(line(0))) // two lines per segment
+	j, err := Create(path, size)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for i := 0; i < 7; i++ {
+		if _, err := j.Write([]byte(line(i))); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	j.Close()
+
+	data, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	// Lines 4 and 5 fill the previous segment, line 6 starts the current
+	if want := line(4) + line(5) + line(6); string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestJournalOpenDropsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	torn := line(0) + `{"type":"text","pay`
+	if err := os.WriteFile(path, []byte(torn), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Read(path)
+	if err != nil || string(data) != line(0) {
+		t.Errorf("Read returned %q, %v; want the complete line", data, err)
+	}
+
+	j, err := Open(path, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	j.Write([]byte(line(1)))
+	j.Close()
+	if data, _ := Read(path); string(data) != line(0)+line(1) {
+		t.Errorf("got %q after continuing the journal", data)
+	}
+}
+
+func TestJournalCreateRemovesOld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	os.WriteFile(path, []byte(line(0)), 0o600)
+	os.WriteFile(path+".1", []byte(line(1)), 0o600)
+
+	j, err := Create(path, 0)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	j.Close()
+	if data, err := Read(path); err != nil || len(data) != 0 {
+		t.Errorf("Read returned %q, %v; want an empty journal", data, err)
+	}
+}
+
+func TestJournalReadMissing(t *testing.T) {
+	_, err := Read(filepath.Join(t.TempDir(), "none.jsonl"))
+	if err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Errorf("got %v, want a missing file error", err)
+	}
+}
+
+func TestJournalHead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	size := int64(4 * len(line(0)))
+	j, err := Create(path, size)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		j.Write([]byte(line(i)))
+	}
+	j.Close()
+
+	head, total, err := Head(path, len(line(0)))
+	if err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+	// Lines 0 and 1 are in the previous segment, line 2 in the current
+	if string(head) != line(0) {
+		t.Errorf("head = %q, want %q", head, line(0))
+	}
+	if want := int64(3 * len(line(0))); total != want {
+		t.Errorf("size = %d, want %d", total, want)
+	}
+}
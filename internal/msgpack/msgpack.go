@@ -0,0 +1,349 @@
+// Package msgpack converts between JSON and MessagePack, so protocol messages
+// can travel as MessagePack while being handled as JSON internally. It
+// supports the types JSON can represent; binary values become base64
+// strings, as encoding/json does for []byte.
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// ErrUnsupported is returned for MessagePack values with no JSON equivalent,
+// such as extension types and non-string map keys.
+var ErrUnsupported = errors.New("msgpack value has no JSON equivalent")
+
+// ReadJSON reads one MessagePack value from r and returns it as JSON. It
+// returns io.EOF if r is at the end before the value starts.
+func ReadJSON(r *bufio.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := transcode(r, &buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FromJSON converts a JSON document to MessagePack. Object keys are written
+// in sorted order.
+func FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maxDepth bounds nesting so hostile input cannot exhaust the stack.
+const maxDepth = 1000
+
+// transcode copies one MessagePack value from r to w as JSON.
+func transcode(r *bufio.Reader, w *bytes.Buffer, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("msgpack nesting deeper than %d", maxDepth)
+	}
+	b, err := r.ReadByte()
+	if err != nil {
+		if depth > 0 && err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	switch {
+	case b <= 0x7f: // positive fixint
+		w.WriteString(strconv.Itoa(int(b)))
+		return nil
+	case b >= 0xe0: // negative fixint
+		w.WriteString(strconv.Itoa(int(int8(b))))
+		return nil
+	case b >= 0xa0 && b <= 0xbf:
+		return transcodeString(r, w, int(b&0x1f))
+	case b >= 0x90 && b <= 0x9f:
+		return transcodeArray(r, w, int(b&0x0f), depth)
+	case b >= 0x80 && b <= 0x8f:
+		return transcodeMap(r, w, int(b&0x0f), depth)
+	}
+
+	switch b {
+	case 0xc0:
+		w.WriteString("null")
+	case 0xc2:
+		w.WriteString("false")
+	case 0xc3:
+		w.WriteString("true")
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readUint(r, 1<<(b-0xcc))
+		if err != nil {
+			return err
+		}
+		w.WriteString(strconv.FormatUint(n, 10))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		n, err := readUint(r, size)
+		if err != nil {
+			return err
+		}
+		// Sign-extend from the value's width
+		shift := 64 - 8*size
+		w.WriteString(strconv.FormatInt(int64(n<<shift)>>shift, 10))
+	case 0xca:
+		n, err := readUint(r, 4)
+		if err != nil {
+			return err
+		}
+		return writeFloat(w, float64(math.Float32frombits(uint32(n))), 32)
+	case 0xcb:
+		n, err := readUint(r, 8)
+		if err != nil {
+			return err
+		}
+		return writeFloat(w, math.Float64frombits(n), 64)
+	case 0xd9, 0xda, 0xdb:
+		n, err := readUint(r, 1<<(b-0xd9))
+		if err != nil {
+			return err
+		}
+		return transcodeString(r, w, int(n))
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readUint(r, 1<<(b-0xc4))
+		if err != nil {
+			return err
+		}
+		data, err := readN(r, int(n))
+		if err != nil {
+			return err
+		}
+		w.WriteByte('"')
+		w.WriteString(base64.StdEncoding.EncodeToString(data))
+		w.WriteByte('"')
+	case 0xdc, 0xdd:
+		n, err := readUint(r, 2<<(b-0xdc))
+		if err != nil {
+			return err
+		}
+		return transcodeArray(r, w, int(n), depth)
+	case 0xde, 0xdf:
+		n, err := readUint(r, 2<<(b-0xde))
+		if err != nil {
+			return err
+		}
+		return transcodeMap(r, w, int(n), depth)
+	default:
+		return fmt.Errorf("%w: type byte 0x%02x", ErrUnsupported, b)
+	}
+	return nil
+}
+
+func transcodeString(r *bufio.Reader, w *bytes.Buffer, n int) error {
+	data, err := readN(r, n)
+	if err != nil {
+		return err
+	}
+	// Encode leaves <, > and & alone, unlike Marshal
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(string(data)); err != nil {
+		return err
+	}
+	w.Truncate(w.Len() - 1) // Encode's trailing newline
+	return nil
+}
+
+func transcodeArray(r *bufio.Reader, w *bytes.Buffer, n, depth int) error {
+	w.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := transcode(r, w, depth+1); err != nil {
+			return err
+		}
+	}
+	w.WriteByte(']')
+	return nil
+}
+
+func transcodeMap(r *bufio.Reader, w *bytes.Buffer, n, depth int) error {
+	w.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		// Keys must be strings to be valid JSON
+		b, err := r.ReadByte()
+		if err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		if err := r.UnreadByte(); err != nil {
+			return err
+		}
+		if !(b >= 0xa0 && b <= 0xbf) && b != 0xd9 && b != 0xda && b != 0xdb {
+			return fmt.Errorf("%w: map key type byte 0x%02x", ErrUnsupported, b)
+		}
+		if err := transcode(r, w, depth+1); err != nil {
+			return err
+		}
+		w.WriteByte(':')
+		if err := transcode(r, w, depth+1); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('}')
+	return nil
+}
+
+func writeFloat(w *bytes.Buffer, f float64, bits int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%w: float %v", ErrUnsupported, f)
+	}
+	w.WriteString(strconv.FormatFloat(f, 'g', -1, bits))
+	return nil
+}
+
+// readUint reads a big-endian unsigned integer of size bytes.
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	data, err := readN(r, size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(data[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(data)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(data)), nil
+	default:
+		return binary.BigEndian.Uint64(data), nil
+	}
+}
+
+// readN reads n bytes, growing the buffer as data arrives rather than
+// trusting a length prefix up front.
+func readN(r *bufio.Reader, n int) ([]byte, error) {
+	var buf bytes.Buffer
+	copied, err := io.CopyN(&buf, r, int64(n))
+	if copied < int64(n) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes a value decoded from JSON with UseNumber as MessagePack.
+func encode(w *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		w.WriteByte(0xc0)
+	case bool:
+		if v {
+			w.WriteByte(0xc3)
+		} else {
+			w.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			encodeInt(w, n)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		w.WriteByte(0xcb)
+		w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+	case string:
+		encodeString(w, v)
+	case []any:
+		writeHeader(w, len(v), 0x90, 0xdc)
+		for _, item := range v {
+			if err := encode(w, item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeHeader(w, len(v), 0x80, 0xde)
+		for _, k := range keys {
+			encodeString(w, k)
+			if err := encode(w, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: cannot encode %T", v)
+	}
+	return nil
+}
+
+func encodeInt(w *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0 && n <= 0x7f:
+		w.WriteByte(byte(n))
+	case n < 0 && n >= -32:
+		w.WriteByte(byte(int8(n)))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		w.Write([]byte{0xd0, byte(int8(n))})
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		w.WriteByte(0xd1)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		w.WriteByte(0xd2)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		w.WriteByte(0xd3)
+		w.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+	}
+}
+
+func encodeString(w *bytes.Buffer, s string) {
+	switch n := len(s); {
+	case n <= 31:
+		w.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		w.Write([]byte{0xd9, byte(n)})
+	case n <= math.MaxUint16:
+		w.WriteByte(0xda)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		w.WriteByte(0xdb)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+	w.WriteString(s)
+}
+
+// writeHeader writes an array or map header: the fix form for up to 15
+// entries, else the 16- or 32-bit form (code16, code16+1).
+func writeHeader(w *bytes.Buffer, n int, fix, code16 byte) {
+	switch {
+	case n <= 15:
+		w.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(code16)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		w.WriteByte(code16 + 1)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
@@ -0,0 +1,124 @@
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFromJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		want string // hex
+	}{
+		{`null`, "c0"},
+		{`true`, "c3"},
+		{`false`, "c2"},
+		{`7`, "07"},
+		{`-1`, "ff"},
+		{`-33`, "d0df"},
+		{`300`, "d1012c"},
+		{`70000`, "d2000111 70"},
+		{`1.5`, "cb3ff8000000000000"},
+		{`"hi"`, "a26869"},
+		{`[1,2]`, "920102"},
+		{`{"b":1,"a":[]}`, "82a16190a16201"},
+	}
+	for _, tt := range tests {
+		got, err := FromJSON([]byte(tt.json))
+		if err != nil {
+			t.Errorf("FromJSON(%s) failed: %v", tt.json, err)
+			continue
+		}
+		if want := strings.ReplaceAll(tt.want, " ", ""); hex.EncodeToString(got) != want {
+			t.Errorf("FromJSON(%s) = %x, want %s", tt.json, got, want)
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string
+	}{
+		{"c0", `null`},
+		{"e0", `-32`},
+		{"cc ff", `255`},
+		{"cf ffffffffffffffff", `18446744073709551615`},
+		{"d3 fffffffffffffffe", `-2`},
+		{"ca 3fc00000", `1.5`},
+		{"d9 03 613c62", `"a<b"`},
+		{"c4 03 010203", `"AQID"`},
+		{"dc 0002 c3 c2", `[true,false]`},
+		{"81 a474797065 a474657874", `{"type":"text"}`},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(strings.ReplaceAll(tt.hex, " ", ""))
+		got, err := ReadJSON(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			t.Errorf("ReadJSON(%s) failed: %v", tt.hex, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("ReadJSON(%s) = %s, want %s", tt.hex, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTripStream(t *testing.T) {
+	docs := []string{
+		`{"payload":{"content":"line one\nline two","done":false},"type":"text"}`,
+		`{"payload":{"rows":[["a",1],["b",-2.25]],"total":100000},"type":"table_chunk"}`,
+		`{"type":"quit"}`,
+	}
+	var stream bytes.Buffer
+	for _, doc := range docs {
+		data, err := FromJSON([]byte(doc))
+		if err != nil {
+			t.Fatalf("FromJSON failed: %v", err)
+		}
+		stream.Write(data)
+	}
+
+	r := bufio.NewReader(&stream)
+	for _, doc := range docs {
+		got, err := ReadJSON(r)
+		if err != nil {
+			t.Fatalf("ReadJSON failed: %v", err)
+		}
+		if string(got) != doc {
+			t.Errorf("round trip = %s, want %s", got, doc)
+		}
+	}
+	if _, err := ReadJSON(r); err != io.EOF {
+		t.Errorf("ReadJSON at end = %v, want io.EOF", err)
+	}
+}
+
+func TestReadJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		want error
+	}{
+		{"truncated map", "82 a161 01", io.ErrUnexpectedEOF},
+		{"truncated string", "a5 6869", io.ErrUnexpectedEOF},
+		{"huge length prefix", "db ffffffff 00", io.ErrUnexpectedEOF},
+		{"integer key", "81 01 02", ErrUnsupported},
+		{"extension", "d4 01 00", ErrUnsupported},
+		{"NaN", "cb 7ff8000000000001", ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(strings.ReplaceAll(tt.hex, " ", ""))
+			_, err := ReadJSON(bufio.NewReader(bytes.NewReader(data)))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ReadJSON(%s) error = %v, want %v", tt.hex, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package protocol
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// MaxAttachmentSize is the largest file the TUI will send as an attachment.
+const MaxAttachmentSize = 16 << 20 // 16 MiB
+
+// AttachmentChunkSize is the number of raw bytes carried by each attachment message.
+const AttachmentChunkSize = 256 << 10 // 256 KiB
+
+// NewAttachmentID returns a random identifier for an attachment.
+func NewAttachmentID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("att-%d", os.Getpid())
+	}
+	return "att-" + hex.EncodeToString(b)
+}
+
+// DetectMimeType guesses a MIME type from the file extension, falling back
+// to content sniffing.
+func DetectMimeType(path string, head []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return http.DetectContentType(head)
+}
+
+// ReadAttachment reads a file and splits it into attachment payloads of at
+// most chunkSize raw bytes each. Empty files produce a single empty chunk.
+func ReadAttachment(path string, chunkSize int) ([]AttachmentPayload, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	if info.Size() > MaxAttachmentSize {
+		return nil, fmt.Errorf("%s is %d bytes, larger than the %d byte limit", path, info.Size(), MaxAttachmentSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = AttachmentChunkSize
+	}
+	chunks := (len(data) + chunkSize - 1) / chunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	id := NewAttachmentID()
+	mimeType := DetectMimeType(path, data[:min(len(data), 512)])
+
+	payloads := make([]AttachmentPayload, chunks)
+	for i := 0; i < chunks; i++ {
+		start := i * chunkSize
+		end := min(start+chunkSize, len(data))
+		payloads[i] = AttachmentPayload{
+			ID:       id,
+			Name:     filepath.Base(path),
+			Path:     absPath,
+			MimeType: mimeType,
+			Size:     info.Size(),
+			Encoding: "base64",
+			Data:     base64.StdEncoding.EncodeToString(data[start:end]),
+			Chunk:    i,
+			Chunks:   chunks,
+		}
+	}
+
+	return payloads, nil
+}
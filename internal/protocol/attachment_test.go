@@ -0,0 +1,71 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadAttachment(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		content    string
+		chunkSize  int
+		wantChunks int
+		wantMime   string
+	}{
+		{"empty.txt", "", 4, 1, "text/plain"},
+		{"small.txt", "hello", 16, 1, "text/plain"},
+		{"split.json", `{"a": 1, "b": 2}`, 5, 4, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			payloads, err := ReadAttachment(path, tt.chunkSize)
+			if err != nil {
+				t.Fatalf("ReadAttachment failed: %v", err)
+			}
+
+			if len(payloads) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(payloads), tt.wantChunks)
+			}
+
+			var rebuilt []byte
+			for i, p := range payloads {
+				if p.Chunk != i || p.Chunks != tt.wantChunks {
+					t.Errorf("chunk %d has index %d/%d", i, p.Chunk, p.Chunks)
+				}
+				if p.ID != payloads[0].ID {
+					t.Error("chunks should share one attachment ID")
+				}
+				data, err := base64.StdEncoding.DecodeString(p.Data)
+				if err != nil {
+					t.Fatalf("chunk %d is not valid base64: %v", i, err)
+				}
+				rebuilt = append(rebuilt, data...)
+			}
+
+			if string(rebuilt) != tt.content {
+				t.Errorf("reassembled content = %q, want %q", rebuilt, tt.content)
+			}
+			if !strings.HasPrefix(payloads[0].MimeType, tt.wantMime) {
+				t.Errorf("MimeType = %q, want prefix %q", payloads[0].MimeType, tt.wantMime)
+			}
+		})
+	}
+}
+
+func TestReadAttachmentRejectsDirectory(t *testing.T) {
+	if _, err := ReadAttachment(t.TempDir(), 0); err == nil {
+		t.Error("ReadAttachment should fail for a directory")
+	}
+}
@@ -0,0 +1,73 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// TypeBatch is the type of the message a handler delivers for a line
+// holding a JSON array of messages. It never appears on the wire: agents
+// send the array itself, and the TUI applies the messages in Batch
+// together, without rendering between them.
+const TypeBatch MessageType = "batch"
+
+// IsBatch reports whether line holds a JSON array of messages rather than
+// a single message.
+func IsBatch(line []byte) bool {
+	line = bytes.TrimLeft(line, " \t\r\n")
+	return len(line) > 0 && line[0] == '['
+}
+
+// splitBatch splits a batch line into the JSON of its messages.
+func splitBatch(line []byte) ([]json.RawMessage, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(line, &elems); err != nil {
+		return nil, err
+	}
+	return elems, nil
+}
+
+// DecodeBatch parses a line holding a JSON array of messages into a batch
+// message, failing if any of them cannot be decoded.
+func DecodeBatch(line []byte, strict bool) (*Message, error) {
+	elems, err := splitBatch(line)
+	if err != nil {
+		return nil, err
+	}
+	batch := &Message{Type: TypeBatch, Batch: make([]*Message, 0, len(elems))}
+	for i, elem := range elems {
+		msg, err := DecodeMessage(elem, strict)
+		if err != nil {
+			return nil, fmt.Errorf("batch message %d: %w", i, err)
+		}
+		batch.Batch = append(batch.Batch, msg)
+	}
+	return batch, nil
+}
+
+// decodeBatch decodes the messages of a batch line as readFrom does single
+// lines. Those that fail to decode are reported on Errors and left out, and
+// responses to this TUI's requests settle them; the rest are returned as a
+// batch message, or nil if none are left.
+func (h *Handler) decodeBatch(line []byte) (*Message, error) {
+	elems, err := splitBatch(line)
+	if err != nil {
+		return h.decode(line, err)
+	}
+	batch := &Message{Type: TypeBatch}
+	for _, elem := range elems {
+		msg, err := h.decode(elem, nil)
+		if err != nil {
+			h.reportError(err)
+			continue
+		}
+		if msg.ID == "" || !h.settle(msg.ID, msg) {
+			batch.Batch = append(batch.Batch, msg)
+		}
+	}
+	if len(batch.Batch) == 0 {
+		return nil, nil
+	}
+	return batch, nil
+}
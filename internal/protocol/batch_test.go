@@ -0,0 +1,50 @@
+package protocol
+
+import (
+	"bufio"
+	"io"
+	"testing"
+)
+
+func TestHandlerBatch(t *testing.T) {
+	stdin, agentOut := io.Pipe()
+	agentIn, stdout := io.Pipe()
+	go io.Copy(io.Discard, bufio.NewReader(agentIn))
+
+	h := NewHandler(stdin, stdout)
+	h.Start()
+	defer h.Stop()
+
+	io.WriteString(agentOut, `[{"type":"clear"},{"type":"markdown","id":"m1","payload":{"content":"hi"}},`+
+		`not json,{"type":"status","payload":{"message":"done"}}]`+"\n")
+	if err := <-h.Errors(); err == nil {
+		t.Error("the malformed batch was not reported")
+	}
+
+	io.WriteString(agentOut, ` [{"type":"clear"}, 42, {"type":"status","payload":{"message":"done"}}]`+"\n")
+	if err := <-h.Errors(); err == nil {
+		t.Error("the invalid batch message was not reported")
+	}
+	msg := <-h.Incoming()
+	if msg.Type != TypeBatch || len(msg.Batch) != 2 ||
+		msg.Batch[0].Type != TypeClear || msg.Batch[1].Type != TypeStatus {
+		t.Errorf("received %+v, want a batch of clear and status", msg)
+	}
+}
+
+func TestDecodeBatch(t *testing.T) {
+	line := []byte(`[{"type":"clear"},{"type":"text","payload":{"content":"hi"}}]`)
+	if !IsBatch(line) || IsBatch([]byte(`{"type":"clear"}`)) {
+		t.Error("IsBatch did not tell arrays from messages")
+	}
+	msg, err := DecodeBatch(line, true)
+	if err != nil {
+		t.Fatalf("DecodeBatch failed: %v", err)
+	}
+	if len(msg.Batch) != 2 || msg.Batch[1].Type != TypeText {
+		t.Errorf("decoded %+v", msg.Batch)
+	}
+	if _, err := DecodeBatch([]byte(`[{"type":"clear","extra":1}]`), true); err == nil {
+		t.Error("DecodeBatch accepted an unknown field in strict mode")
+	}
+}
@@ -0,0 +1,218 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Ways the TUI accepts blobs, advertised in its hello.
+const (
+	BlobBase64 = "base64"
+	BlobFile   = "file"
+)
+
+// MaxBlobSize is the largest blob the TUI will accept from the agent.
+const MaxBlobSize = 64 << 20 // 64 MiB
+
+// Blob is binary content received from the agent, kept in a file.
+type Blob struct {
+	ID       string
+	Name     string
+	MimeType string
+	Size     int64
+	Path     string
+}
+
+// BlobStore assembles blob messages into files in a directory it owns.
+type BlobStore struct {
+	dir string
+
+	mu      sync.Mutex
+	partial map[string]*partialBlob
+	blobs   map[string]*Blob
+}
+
+// partialBlob is a chunked blob still being received.
+type partialBlob struct {
+	blob *Blob
+	file *os.File
+	next int // the chunk expected next
+}
+
+// NewBlobStore creates a store keeping blobs in a new temporary directory,
+// removed by Close.
+func NewBlobStore() (*BlobStore, error) {
+	dir, err := os.MkdirTemp("", "agentui-blobs-")
+	if err != nil {
+		return nil, err
+	}
+	return &BlobStore{
+		dir:     dir,
+		partial: make(map[string]*partialBlob),
+		blobs:   make(map[string]*Blob),
+	}, nil
+}
+
+// Dir returns the directory blobs are kept in, where agents sharing the
+// TUI's filesystem can write files to hand off.
+func (s *BlobStore) Dir() string {
+	return s.dir
+}
+
+// Get returns the complete blob with the given ID, or nil.
+func (s *BlobStore) Get(id string) *Blob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blobs[id]
+}
+
+// Add takes one blob message, returning the blob once it is complete and
+// nil while chunks are still to come. A blob sent again under the same ID
+// replaces the earlier one.
+func (s *BlobStore) Add(p BlobPayload) (*Blob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.Path != "" {
+		return s.handoff(p)
+	}
+	return s.addChunk(p)
+}
+
+// handoff takes over a file the agent wrote to the store's directory.
+func (s *BlobStore) handoff(p BlobPayload) (*Blob, error) {
+	path := filepath.Clean(p.Path)
+	if rel, err := filepath.Rel(s.dir, path); err != nil || rel == "." || strings.HasPrefix(rel, "..") || strings.ContainsRune(rel, filepath.Separator) {
+		return nil, fmt.Errorf("blob %s: %s is not in %s", p.ID, p.Path, s.dir)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, fmt.Errorf("blob %s: %w", p.ID, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("blob %s: %s is not a regular file", p.ID, p.Path)
+	}
+	if info.Size() > MaxBlobSize {
+		os.Remove(path)
+		return nil, fmt.Errorf("blob %s is %d bytes, larger than the %d byte limit", p.ID, info.Size(), MaxBlobSize)
+	}
+	return s.finish(p, path, info.Size())
+}
+
+// addChunk appends one base64 chunk to its blob's file.
+func (s *BlobStore) addChunk(p BlobPayload) (*Blob, error) {
+	data, err := base64.StdEncoding.DecodeString(p.Data)
+	if err != nil {
+		return nil, fmt.Errorf("blob %s chunk %d: %w", p.ID, p.Chunk, err)
+	}
+
+	part := s.partial[p.ID]
+	if p.Chunk == 0 {
+		if part != nil {
+			s.abandon(p.ID)
+		}
+		f, err := os.CreateTemp(s.dir, "blob-*"+filepath.Ext(p.Name))
+		if err != nil {
+			return nil, err
+		}
+		part = &partialBlob{blob: &Blob{ID: p.ID}, file: f}
+		s.partial[p.ID] = part
+	}
+	if part == nil || p.Chunk != part.next {
+		return nil, fmt.Errorf("blob %s: unexpected chunk %d", p.ID, p.Chunk)
+	}
+	if part.blob.Size+int64(len(data)) > MaxBlobSize {
+		s.abandon(p.ID)
+		return nil, fmt.Errorf("blob %s is larger than the %d byte limit", p.ID, MaxBlobSize)
+	}
+	if _, err := part.file.Write(data); err != nil {
+		s.abandon(p.ID)
+		return nil, err
+	}
+	part.blob.Size += int64(len(data))
+	part.next++
+	if part.next < max(p.Chunks, 1) {
+		return nil, nil
+	}
+
+	delete(s.partial, p.ID)
+	if err := part.file.Close(); err != nil {
+		os.Remove(part.file.Name())
+		return nil, err
+	}
+	if p.Size > 0 && p.Size != part.blob.Size {
+		os.Remove(part.file.Name())
+		return nil, fmt.Errorf("blob %s is %d bytes, want %d", p.ID, part.blob.Size, p.Size)
+	}
+	return s.finish(p, part.file.Name(), part.blob.Size)
+}
+
+// finish records the blob kept at path, replacing any with the same ID.
+func (s *BlobStore) finish(p BlobPayload, path string, size int64) (*Blob, error) {
+	name := p.Name
+	switch {
+	case name != "":
+	case p.Path != "":
+		name = filepath.Base(path)
+	default:
+		name = p.ID
+	}
+	mimeType := p.MimeType
+	if mimeType == "" {
+		mimeType = sniffMimeType(name, path)
+	}
+	if old := s.blobs[p.ID]; old != nil && old.Path != path {
+		os.Remove(old.Path)
+	}
+	blob := &Blob{ID: p.ID, Name: name, MimeType: mimeType, Size: size, Path: path}
+	s.blobs[p.ID] = blob
+	return blob, nil
+}
+
+// abandon drops a chunked blob that will not be completed.
+func (s *BlobStore) abandon(id string) {
+	part := s.partial[id]
+	delete(s.partial, id)
+	part.file.Close()
+	os.Remove(part.file.Name())
+}
+
+// Close removes the store's directory and every blob in it.
+func (s *BlobStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.partial {
+		s.abandon(id)
+	}
+	return os.RemoveAll(s.dir)
+}
+
+// sniffMimeType guesses the MIME type of the blob kept at path.
+func sniffMimeType(name, path string) string {
+	head := make([]byte, 512)
+	if f, err := os.Open(path); err == nil {
+		n, _ := io.ReadFull(f, head)
+		head = head[:n]
+		f.Close()
+	}
+	return DetectMimeType(name, head)
+}
+
+// SetBlobDir offers agents the file handoff of blobs, written to dir, in
+// the hello message. Only set it when agents share the TUI's filesystem.
+// Call before Start.
+func (h *Handler) SetBlobDir(dir string) {
+	h.blobDir = dir
+}
+
+// blobModes lists the ways blobs can be sent, for the hello message.
+func (h *Handler) blobModes() []string {
+	if h.blobDir == "" {
+		return []string{BlobBase64}
+	}
+	return []string{BlobBase64, BlobFile}
+}
@@ -0,0 +1,160 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestBlobStore(t *testing.T) *BlobStore {
+	t.Helper()
+	s, err := NewBlobStore()
+	if err != nil {
+		t.Fatalf("NewBlobStore failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestBlobStoreChunks(t *testing.T) {
+	s := newTestBlobStore(t)
+	data := bytes.Repeat([]byte{0, 1, 2, 0xff}, 100)
+
+	chunks := [][]byte{data[:150], data[150:300], data[300:]}
+	var blob *Blob
+	for i, chunk := range chunks {
+		b, err := s.Add(BlobPayload{
+			ID:       "b1",
+			Name:     "plot.png",
+			Size:     int64(len(data)),
+			Encoding: BlobBase64,
+			Data:     base64.StdEncoding.EncodeToString(chunk),
+			Chunk:    i,
+			Chunks:   len(chunks),
+		})
+		if err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+		if (b != nil) != (i == len(chunks)-1) {
+			t.Fatalf("chunk %d: got blob %v", i, b)
+		}
+		blob = b
+	}
+
+	if blob.Name != "plot.png" || blob.MimeType != "image/png" || blob.Size != int64(len(data)) {
+		t.Errorf("got %+v", blob)
+	}
+	got, err := os.ReadFile(blob.Path)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("the blob's file holds %d bytes (%v), want the data sent", len(got), err)
+	}
+	if s.Get("b1") != blob {
+		t.Error("Get did not return the blob")
+	}
+}
+
+func TestBlobStoreRejectsChunks(t *testing.T) {
+	s := newTestBlobStore(t)
+	chunk := func(i int) BlobPayload {
+		return BlobPayload{ID: "b1", Data: "AAAA", Chunk: i, Chunks: 3}
+	}
+
+	if _, err := s.Add(chunk(1)); err == nil {
+		t.Error("a chunk before the first was accepted")
+	}
+	if _, err := s.Add(chunk(0)); err != nil {
+		t.Fatalf("first chunk: %v", err)
+	}
+	if _, err := s.Add(chunk(2)); err == nil {
+		t.Error("a chunk out of order was accepted")
+	}
+	if _, err := s.Add(BlobPayload{ID: "b2", Data: "not base64!"}); err == nil {
+		t.Error("invalid base64 was accepted")
+	}
+	if _, err := s.Add(BlobPayload{ID: "b3", Data: "AAAA", Size: 5}); err == nil {
+		t.Error("a blob of the wrong size was accepted")
+	}
+}
+
+func TestBlobStoreHandoff(t *testing.T) {
+	s := newTestBlobStore(t)
+
+	path := filepath.Join(s.Dir(), "report.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := s.Add(BlobPayload{ID: "r1", Name: "report.pdf", Path: path})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if blob.Path != path || blob.Size != 8 || blob.MimeType != "application/pdf" {
+		t.Errorf("got %+v", blob)
+	}
+
+	outside := filepath.Join(t.TempDir(), "secret")
+	os.WriteFile(outside, []byte("x"), 0o600)
+	for _, p := range []string{outside, filepath.Join(s.Dir(), "..", filepath.Base(outside)), s.Dir()} {
+		if _, err := s.Add(BlobPayload{ID: "x", Path: p}); err == nil {
+			t.Errorf("handoff of %s was accepted", p)
+		}
+	}
+}
+
+func TestBlobStoreClose(t *testing.T) {
+	s, err := NewBlobStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(BlobPayload{ID: "b1", Data: "AAAA", Chunks: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(s.Dir()); !os.IsNotExist(err) {
+		t.Errorf("the directory is still there: %v", err)
+	}
+}
+
+func TestBlobPayloadValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload BlobPayload
+		field   string
+	}{
+		{"valid chunk", BlobPayload{ID: "b", Data: "AA==", Chunk: 1, Chunks: 2}, ""},
+		{"valid file", BlobPayload{ID: "b", Path: "/tmp/x"}, ""},
+		{"no id", BlobPayload{Data: "AA=="}, "id"},
+		{"chunk out of range", BlobPayload{ID: "b", Chunk: 2, Chunks: 2}, "chunk"},
+		{"unknown encoding", BlobPayload{ID: "b", Encoding: "hex"}, "encoding"},
+		{"path and data", BlobPayload{ID: "b", Path: "/tmp/x", Data: "AA=="}, "data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.payload.Validate()
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Validate failed: %v", err)
+				}
+				return
+			}
+			fe, ok := err.(*FieldError)
+			if !ok || fe.Field != tt.field {
+				t.Errorf("got %v, want a problem with %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestHelloOffersBlobHandoff(t *testing.T) {
+	h := NewHandler(nil, nil)
+	if modes := h.blobModes(); len(modes) != 1 || modes[0] != BlobBase64 {
+		t.Errorf("got %v without a blob directory", modes)
+	}
+	h.SetBlobDir("/tmp/blobs")
+	if modes := h.blobModes(); len(modes) != 2 || modes[1] != BlobFile {
+		t.Errorf("got %v with a blob directory", modes)
+	}
+}
@@ -0,0 +1,89 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// CompressionGzip marks a payload compressed with gzip.
+const CompressionGzip = "gzip"
+
+// SupportedCompressions lists the compressions Decompress understands, in
+// order of preference. zstd is deliberately not among them: it would add a
+// dependency here and in the Python package for little gain over gzip on
+// JSON.
+var SupportedCompressions = []string{CompressionGzip}
+
+// maxDecompressedSize bounds decompressed payloads to protect against
+// compression bombs.
+const maxDecompressedSize = 64 << 20 // 64 MiB
+
+// Compress replaces the message payload with a base64 string of the
+// compressed JSON and sets the Compression envelope field.
+func Compress(msg *Message, encoding string) error {
+	if msg.Compression != "" || len(msg.Payload) == 0 {
+		return nil
+	}
+	if encoding != CompressionGzip {
+		return fmt.Errorf("unsupported compression: %s", encoding)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(msg.Payload); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	msg.Payload = encoded
+	msg.Compression = encoding
+	return nil
+}
+
+// Decompress restores a compressed payload in place. Messages without a
+// Compression field are left untouched.
+func Decompress(msg *Message) error {
+	if msg.Compression == "" {
+		return nil
+	}
+	if msg.Compression != CompressionGzip {
+		return fmt.Errorf("unsupported compression: %s", msg.Compression)
+	}
+
+	var encoded string
+	if err := json.Unmarshal(msg.Payload, &encoded); err != nil {
+		return fmt.Errorf("compressed payload must be a base64 string: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid base64 in compressed payload: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("invalid gzip payload: %w", err)
+	}
+	defer zr.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(zr, maxDecompressedSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	if len(payload) > maxDecompressedSize {
+		return fmt.Errorf("decompressed payload exceeds %d bytes", maxDecompressedSize)
+	}
+
+	msg.Payload = payload
+	msg.Compression = ""
+	return nil
+}
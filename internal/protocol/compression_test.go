@@ -0,0 +1,143 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	original, err := NewMessage(TypeMarkdown, MarkdownPayload{Content: strings.Repeat("# Heading\n", 500)})
+	if err != nil {
+		t.Fatalf("NewMessage failed: %v", err)
+	}
+	want := string(original.Payload)
+
+	msg := *original
+	if err := Compress(&msg, CompressionGzip); err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	if msg.Compression != CompressionGzip {
+		t.Errorf("Compression = %q, want gzip", msg.Compression)
+	}
+	if len(msg.Payload) >= len(want) {
+		t.Errorf("compressed payload (%d bytes) not smaller than original (%d bytes)", len(msg.Payload), len(want))
+	}
+
+	// Survives a trip over the wire
+	line, err := json.Marshal(&msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var received Message
+	if err := json.Unmarshal(line, &received); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if err := Decompress(&received); err != nil {
+		t.Fatalf("Decompress failed: %v", err)
+	}
+	if string(received.Payload) != want {
+		t.Error("decompressed payload does not match original")
+	}
+	if received.Compression != "" {
+		t.Error("Compression should be cleared after Decompress")
+	}
+}
+
+func TestDecompressErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"unknown encoding", Message{Compression: "lz4", Payload: json.RawMessage(`"abc"`)}},
+		{"not a string", Message{Compression: CompressionGzip, Payload: json.RawMessage(`{"a":1}`)}},
+		{"bad base64", Message{Compression: CompressionGzip, Payload: json.RawMessage(`"!!!"`)}},
+		{"not gzip", Message{Compression: CompressionGzip, Payload: json.RawMessage(`"aGVsbG8="`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Decompress(&tt.msg); err == nil {
+				t.Error("Decompress should fail")
+			}
+		})
+	}
+}
+
+func TestHandlerHello(t *testing.T) {
+	// Unknown hello fields are ignored even in strict mode
+	in := bytes.NewBufferString(`{"type":"hello","payload":{"compression":["zstd","gzip"],"sixel":true}}` + "\n" +
+		`{"type":"text","payload":{"content":"hi"}}` + "\n")
+	var out, record bytes.Buffer
+	h := NewHandler(in, &out)
+	h.SetCompressThreshold(64)
+	h.SetRecorder(&record)
+	h.SetStrict(true)
+	h.SetTerminalInfo(TerminalInfo{TrueColor: true})
+
+	large, err := NewMessage(TypeInput, InputPayload{Content: strings.Repeat("x", 100)})
+	if err != nil {
+		t.Fatalf("NewMessage failed: %v", err)
+	}
+	readSent := func() Message {
+		t.Helper()
+		line, err := out.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("reading sent message: %v", err)
+		}
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", line, err)
+		}
+		return msg
+	}
+
+	// Nothing is compressed until the agent says what it accepts
+	if err := h.SendSync(large); err != nil {
+		t.Fatalf("SendSync failed: %v", err)
+	}
+	if msg := readSent(); msg.Compression != "" {
+		t.Errorf("compressed with %q before hello", msg.Compression)
+	}
+
+	h.Start()
+	defer h.Stop()
+
+	// The TUI introduces itself first thing
+	greeting := readSent()
+	var hello HelloPayload
+	if err := greeting.ParsePayload(&hello); err != nil || greeting.Type != TypeHello {
+		t.Fatalf("first message = %s %s, want hello", greeting.Type, greeting.Payload)
+	}
+	if hello.ProtocolVersion != ProtocolVersion || len(hello.Types) != len(RenderTypes) ||
+		len(hello.Compression) == 0 || hello.Terminal == nil || !hello.Terminal.TrueColor {
+		t.Errorf("hello = %+v, want version, types, compressions and terminal info", hello)
+	}
+
+	for _, want := range []MessageType{TypeHello, TypeText} {
+		select {
+		case msg := <-h.Incoming():
+			if msg.Type != want {
+				t.Errorf("received %s, want %s", msg.Type, want)
+			}
+		case err := <-h.Errors():
+			t.Fatalf("handler error: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no %s message received", want)
+		}
+	}
+
+	if strings.Contains(record.String(), "hello") {
+		t.Errorf("recording contains the hello message: %q", record.String())
+	}
+
+	if err := h.SendSync(large); err != nil {
+		t.Fatalf("SendSync failed: %v", err)
+	}
+	if msg := readSent(); msg.Compression != CompressionGzip {
+		t.Errorf("Compression after hello = %q, want gzip", msg.Compression)
+	}
+}
@@ -0,0 +1,79 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+
+	"github.com/flight505/agentui/internal/msgpack"
+)
+
+// Encoding is the wire format of a message stream.
+type Encoding string
+
+// Wire encodings. JSON Lines is the default; MessagePack streams messages
+// back to back with no separators and is cheaper to produce and parse for
+// high-frequency traffic such as token-by-token text.
+const (
+	EncodingJSON    Encoding = "json"
+	EncodingMsgpack Encoding = "msgpack"
+)
+
+// ParseEncoding parses an encoding name. An empty name selects JSON.
+func ParseEncoding(name string) (Encoding, error) {
+	switch Encoding(name) {
+	case "", EncodingJSON:
+		return EncodingJSON, nil
+	case EncodingMsgpack:
+		return EncodingMsgpack, nil
+	}
+	return "", fmt.Errorf("unknown encoding %q (want json or msgpack)", name)
+}
+
+// SetEncoding sets the wire encoding for both directions. Messages are still
+// handled and recorded as JSON. Call before Start.
+func (h *Handler) SetEncoding(enc Encoding) {
+	h.encoding = enc
+}
+
+// readMessage reads the next message from r as JSON, without the trailing
+// newline of a JSON line.
+func (h *Handler) readMessage(r *bufio.Reader) ([]byte, error) {
+	if h.framing == FramingLength {
+		frame, err := readFrame(r)
+		if err != nil {
+			return nil, err
+		}
+		if h.encoding == EncodingMsgpack {
+			return msgpack.ReadJSON(bufio.NewReader(bytes.NewReader(frame)))
+		}
+		return frame, nil
+	}
+
+	if h.encoding == EncodingMsgpack {
+		return msgpack.ReadJSON(r)
+	}
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return line[:len(line)-1], nil
+}
+
+// encodeMessage converts a JSON message to the wire encoding and framing.
+func (h *Handler) encodeMessage(data []byte) ([]byte, error) {
+	if h.encoding == EncodingMsgpack {
+		var err error
+		if data, err = msgpack.FromJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case h.framing == FramingLength:
+		return appendFrame(data)
+	case h.encoding == EncodingMsgpack:
+		return data, nil
+	default:
+		return append(data, '\n'), nil
+	}
+}
@@ -0,0 +1,79 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flight505/agentui/internal/msgpack"
+)
+
+func TestHandlerMsgpack(t *testing.T) {
+	var in bytes.Buffer
+	for _, line := range []string{
+		`{"type":"text","payload":{"content":"hello\nworld"}}`,
+		`{"type":"markdown","id":"m1","payload":{"content":"# Title"}}`,
+	} {
+		data, err := msgpack.FromJSON([]byte(line))
+		if err != nil {
+			t.Fatalf("FromJSON failed: %v", err)
+		}
+		in.Write(data)
+	}
+
+	var out, record bytes.Buffer
+	h := NewHandler(&in, &out)
+	h.SetEncoding(EncodingMsgpack)
+	h.SetRecorder(&record)
+	h.Start()
+	defer h.Stop()
+
+	for _, want := range []MessageType{TypeText, TypeMarkdown} {
+		select {
+		case msg := <-h.Incoming():
+			if msg.Type != want {
+				t.Errorf("received %s, want %s", msg.Type, want)
+			}
+		case err := <-h.Errors():
+			t.Fatalf("handler error: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no %s message received", want)
+		}
+	}
+
+	// Sessions are recorded as JSON lines so they can be replayed
+	if lines := strings.Split(strings.TrimSpace(record.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "{") {
+		t.Errorf("recording = %q, want two JSON lines", record.String())
+	}
+
+	if err := h.SendResize(80, 24); err != nil {
+		t.Fatalf("SendResize failed: %v", err)
+	}
+	r := bufio.NewReader(&out)
+	for _, want := range []MessageType{TypeHello, TypeResize} {
+		got, err := msgpack.ReadJSON(r)
+		if err != nil {
+			t.Fatalf("reading sent message: %v", err)
+		}
+		msg, err := DecodeMessage(got, true)
+		if err != nil {
+			t.Fatalf("DecodeMessage(%s) failed: %v", got, err)
+		}
+		if msg.Type != want {
+			t.Errorf("sent %s, want %s", msg.Type, want)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for name, want := range map[string]Encoding{"": EncodingJSON, "json": EncodingJSON, "msgpack": EncodingMsgpack} {
+		if got, err := ParseEncoding(name); err != nil || got != want {
+			t.Errorf("ParseEncoding(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseEncoding("cbor"); err == nil {
+		t.Error("ParseEncoding(\"cbor\") succeeded, want error")
+	}
+}
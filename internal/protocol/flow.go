@@ -0,0 +1,135 @@
+package protocol
+
+import (
+	"errors"
+	"sync"
+)
+
+// flowControl keeps a fast agent from overrunning the incoming queue. The
+// agent is granted credits with ready_for_more messages and spends one per
+// message, so at most window messages wait for the app at a time. Credits
+// are granted back in chunks of at least half the window, as the app
+// consumes messages, rather than one message at a time.
+type flowControl struct {
+	mu      sync.Mutex
+	window  int
+	credits int // granted and not yet spent by the agent
+	queued  int // received and not yet consumed by the app
+}
+
+// used spends a credit on a message from the agent. An agent that sends
+// more than it was granted is not refused, just granted no more.
+func (f *flowControl) used() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.credits = max(0, f.credits-1)
+}
+
+// queue counts a message put on the incoming queue.
+func (f *flowControl) queue() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queued++
+}
+
+// grant returns how many credits to grant now, if any.
+func (f *flowControl) grant() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.window - f.queued - f.credits
+	if n < max(1, f.window/2) {
+		return 0
+	}
+	f.credits += n
+	return n
+}
+
+// SetFlowWindow enables flow control: agents are told with ready_for_more
+// messages how many messages they may send, so that at most window wait
+// to be shown. The window is capped at the incoming queue's capacity.
+// Zero disables flow control. Call before Start.
+func (h *Handler) SetFlowWindow(window int) {
+	if window <= 0 {
+		h.flow = nil
+		return
+	}
+	h.flow = &flowControl{window: min(window, cap(h.incoming))}
+}
+
+// startFlow grants a newly connected agent its first credits. Credits
+// granted to a previous agent are void.
+func (h *Handler) startFlow() {
+	if h.flow == nil {
+		return
+	}
+	h.flow.mu.Lock()
+	h.flow.credits = 0
+	h.flow.mu.Unlock()
+	h.grantCredits()
+}
+
+// grantCredits sends the agent a ready_for_more message once enough of
+// the window is free.
+func (h *Handler) grantCredits() {
+	if h.flow == nil {
+		return
+	}
+	n := h.flow.grant()
+	if n == 0 {
+		return
+	}
+	msg, err := NewMessage(TypeReadyForMore, ReadyForMorePayload{Credits: n, Window: h.flow.window})
+	if err == nil {
+		err = h.SendSync(msg)
+	}
+	if err != nil && !errors.Is(err, ErrNotConnected) {
+		h.reportError(err)
+	}
+}
+
+// Consumed tells the handler the app has taken msg off Incoming, so its
+// place in the window can be granted to the agent again.
+func (h *Handler) Consumed(msg *Message) {
+	if h.flow == nil || msg.connection() {
+		return
+	}
+	h.flow.mu.Lock()
+	h.flow.queued = max(0, h.flow.queued-1)
+	h.flow.mu.Unlock()
+	h.grantCredits()
+}
+
+// QueueStats describes the incoming queue, for the debug overlay.
+type QueueStats struct {
+	Depth    int // messages waiting for the app
+	Capacity int
+	Peak     int // the most that have waited at once
+	Window   int // the flow control window, or 0 without flow control
+	Credits  int // messages the agent may still send under flow control
+}
+
+// QueueStats returns the state of the incoming queue.
+func (h *Handler) QueueStats() QueueStats {
+	stats := QueueStats{
+		Depth:    len(h.incoming),
+		Capacity: cap(h.incoming),
+		Peak:     int(h.peak.Load()),
+	}
+	if h.flow != nil {
+		h.flow.mu.Lock()
+		stats.Window, stats.Credits = h.flow.window, h.flow.credits
+		h.flow.mu.Unlock()
+	}
+	return stats
+}
+
+// notePeak records the queue's depth if it is the deepest yet.
+func (h *Handler) notePeak() {
+	depth := int64(len(h.incoming))
+	for {
+		peak := h.peak.Load()
+		if depth <= peak || h.peak.CompareAndSwap(peak, depth) {
+			return
+		}
+	}
+}
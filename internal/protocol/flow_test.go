@@ -0,0 +1,61 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestFlowControl(t *testing.T) {
+	stdin, agentOut := io.Pipe()
+	agentIn, stdout := io.Pipe()
+	agent := bufio.NewReader(agentIn)
+
+	h := NewHandler(stdin, stdout)
+	h.SetFlowWindow(4)
+	go h.Start()
+	defer h.Stop()
+
+	grants := make(chan ReadyForMorePayload, 10)
+	go func() {
+		for {
+			line, err := agent.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var msg Message
+			json.Unmarshal(line, &msg)
+			if msg.Type == TypeReadyForMore {
+				var p ReadyForMorePayload
+				msg.ParsePayload(&p)
+				grants <- p
+			}
+		}
+	}()
+
+	if p := <-grants; p.Credits != 4 || p.Window != 4 {
+		t.Fatalf("first grant = %+v, want 4 credits of a window of 4", p)
+	}
+
+	// Hellos are not counted against the window
+	io.WriteString(agentOut, `{"type":"hello","payload":{"compression":[]}}`+"\n")
+	for i := 0; i < 4; i++ {
+		io.WriteString(agentOut, `{"type":"text","payload":{"content":"hi"}}`+"\n")
+	}
+	msgs := make([]*Message, 5)
+	for i := range msgs {
+		msgs[i] = <-h.Incoming()
+	}
+	if stats := h.QueueStats(); stats.Credits != 0 || stats.Window != 4 || stats.Capacity != 100 {
+		t.Errorf("stats = %+v, want no credits left of a window of 4", stats)
+	}
+
+	// Credits come back once half the window is consumed
+	h.Consumed(msgs[0]) // the hello
+	h.Consumed(msgs[1])
+	h.Consumed(msgs[2])
+	if p := <-grants; p.Credits != 2 {
+		t.Errorf("grant after consuming two messages = %+v, want 2 credits", p)
+	}
+}
@@ -0,0 +1,73 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Framing is how messages are delimited on the wire.
+type Framing string
+
+// Message framings. Line framing ends each JSON message with a newline
+// (MessagePack values need no delimiter). Length framing puts a 4-byte
+// big-endian length before each message, so a message may contain raw
+// newlines and large blobs without corrupting the stream.
+const (
+	FramingLines  Framing = "lines"
+	FramingLength Framing = "length"
+)
+
+// MaxFrameSize bounds a length-prefixed message, so a corrupt length
+// cannot make the reader wait for gigabytes that never arrive.
+const MaxFrameSize = 64 << 20
+
+// ParseFraming parses a framing name. An empty name selects line framing.
+func ParseFraming(name string) (Framing, error) {
+	switch Framing(name) {
+	case "", FramingLines:
+		return FramingLines, nil
+	case FramingLength:
+		return FramingLength, nil
+	}
+	return "", fmt.Errorf("unknown framing %q (want lines or length)", name)
+}
+
+// SetFraming sets the message framing for both directions. Call before
+// Start.
+func (h *Handler) SetFraming(f Framing) {
+	h.framing = f
+}
+
+// readFrame reads one length-prefixed frame. It returns io.EOF if r ends
+// cleanly between frames.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err // io.EOF between frames, io.ErrUnexpectedEOF inside one
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the %d byte limit", size, MaxFrameSize)
+	}
+
+	var frame bytes.Buffer
+	if _, err := io.CopyN(&frame, r, int64(size)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame.Bytes(), nil
+}
+
+// appendFrame prefixes data with its length.
+func appendFrame(data []byte) ([]byte, error) {
+	if len(data) > MaxFrameSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the %d byte frame limit", len(data), MaxFrameSize)
+	}
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(data)), uint32(len(data)))
+	return append(frame, data...), nil
+}
@@ -0,0 +1,102 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flight505/agentui/internal/msgpack"
+)
+
+func frame(data []byte) []byte {
+	out, _ := appendFrame(data)
+	return out
+}
+
+func TestHandlerLengthFraming(t *testing.T) {
+	// Pretty-printed JSON spans lines, which line framing cannot carry
+	pretty := "{\n  \"type\": \"text\",\n  \"payload\": {\"content\": \"one\\ntwo\"}\n}"
+	packed, err := msgpack.FromJSON([]byte(`{"type":"markdown","payload":{"content":"# Hi"}}`))
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		encoding Encoding
+		input    []byte
+		want     MessageType
+	}{
+		{"json", EncodingJSON, frame([]byte(pretty)), TypeText},
+		{"msgpack", EncodingMsgpack, frame(packed), TypeMarkdown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			h := NewHandler(bytes.NewReader(tt.input), &out)
+			h.SetEncoding(tt.encoding)
+			h.SetFraming(FramingLength)
+			h.Start()
+			defer h.Stop()
+
+			select {
+			case msg := <-h.Incoming():
+				if msg.Type != tt.want {
+					t.Errorf("received %s, want %s", msg.Type, tt.want)
+				}
+			case err := <-h.Errors():
+				t.Fatalf("handler error: %v", err)
+			case <-time.After(2 * time.Second):
+				t.Fatal("no message received")
+			}
+
+			if err := h.SendResize(80, 24); err != nil {
+				t.Fatalf("SendResize failed: %v", err)
+			}
+			r := bufio.NewReader(&out)
+			for _, want := range []string{`"hello"`, `"resize"`} {
+				sent, err := readFrame(r)
+				if err != nil {
+					t.Fatalf("reading sent frame: %v", err)
+				}
+				if tt.encoding == EncodingMsgpack {
+					if sent, err = msgpack.ReadJSON(bufio.NewReader(bytes.NewReader(sent))); err != nil {
+						t.Fatalf("decoding sent frame: %v", err)
+					}
+				}
+				if !strings.Contains(string(sent), want) {
+					t.Errorf("sent frame %s, want a %s message", sent, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadFrameErrors(t *testing.T) {
+	oversize := binary.BigEndian.AppendUint32(nil, MaxFrameSize+1)
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"truncated header", []byte{0, 0}, io.ErrUnexpectedEOF.Error()},
+		{"truncated body", []byte{0, 0, 0, 5, '{', '}'}, io.ErrUnexpectedEOF.Error()},
+		{"oversize", oversize, "exceeds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readFrame(bufio.NewReader(bytes.NewReader(tt.input)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("readFrame error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(nil))); err != io.EOF {
+		t.Errorf("readFrame at end = %v, want io.EOF", err)
+	}
+}
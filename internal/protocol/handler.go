@@ -3,8 +3,14 @@ package protocol
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"io"
+	"net"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 // Handler manages JSON protocol communication over streams.
@@ -13,6 +19,67 @@ type Handler struct {
 	writer  io.Writer
 	writeMu sync.Mutex
 
+	// Outgoing payloads at least this large are compressed (0 disables)
+	// with peerCompression, the agent's choice from its hello message
+	compressThreshold int
+	peerCompression   string // guarded by writeMu
+
+	// Sent in the hello message; see SetTerminalInfo
+	terminal TerminalInfo
+
+	// Offered to agents for handing off blobs; see blob.go
+	blobDir string
+
+	// Reject unknown fields and invalid payloads in incoming messages
+	strict bool
+
+	// Wire format in both directions; see encoding.go and framing.go
+	encoding Encoding
+	framing  Framing
+
+	// Incoming lines are copied here when recording a session
+	recorder io.Writer
+	// and here, with the user's input, before they are shown
+	journal io.Writer
+	// traffic logs every message sent and received, if set
+	traffic *TrafficLog
+
+	// Grants the agent credits to send messages, if set; see flow.go
+	flow *flowControl
+	// The deepest the incoming queue has been
+	peak atomic.Int64
+
+	// Set when agents connect over the network; see listener.go
+	listener    net.Listener
+	conn        net.Conn // the connected agent, guarded by writeMu
+	connections chan ConnEvent
+
+	// Accepts a restarted agent once the stream ends; see resume.go
+	reattach net.Listener
+	detached atomic.Bool
+
+	// User events sent while no agent is connected, delivered to the next
+	// one; guarded by writeMu
+	backlog []*Message
+
+	// The agent_id of agent requests awaiting an answer, by ID; see
+	// multiplex.go
+	routes   map[string]string
+	routesMu sync.Mutex
+
+	// Message dialect on the wire; see jsonrpc.go. The JSON-RPC IDs of
+	// agent requests by native ID, and the response type of the TUI's
+	// requests awaiting a JSON-RPC response
+	rpc        RPC
+	rpcIDs     map[string]json.RawMessage
+	rpcPending map[string]MessageType
+	rpcMu      sync.Mutex
+
+	// Messages sent with Request awaiting a response, by ID
+	requests   map[string]*request
+	requestsMu sync.Mutex
+	requestSeq atomic.Int64
+
 	// Channels for async message handling
 	incoming chan *Message
 	outgoing chan *Message
@@ -32,15 +99,75 @@ func NewHandler(r io.Reader, w io.Writer) *Handler {
 	}
 }
 
+// SetCompressThreshold enables compression of outgoing payloads of at least
+// the given size in bytes, once the agent has said in a hello message which
+// compressions it accepts. Zero disables compression.
+// Incoming compressed messages are always accepted.
+func (h *Handler) SetCompressThreshold(bytes int) {
+	h.compressThreshold = bytes
+}
+
+// SetTerminalInfo sets the terminal capabilities advertised to agents in
+// the hello message sent when one connects. Call before Start.
+func (h *Handler) SetTerminalInfo(info TerminalInfo) {
+	h.terminal = info
+}
+
+// SetStrict enables strict protocol validation of incoming messages.
+// Call before Start.
+func (h *Handler) SetStrict(strict bool) {
+	h.strict = strict
+}
+
+// SetRecorder copies every incoming message line to w, producing a session
+// file that can be replayed later. Call before Start.
+func (h *Handler) SetRecorder(w io.Writer) {
+	h.recorder = w
+}
+
+// SetJournal copies every incoming message line, and each input the user
+// sends, to w before the message is shown, so the transcript can be rebuilt
+// if the TUI dies. Call before Start.
+func (h *Handler) SetJournal(w io.Writer) {
+	h.journal = w
+}
+
+// SetTrafficLog logs every message sent and received to l. Call before
+// Start.
+func (h *Handler) SetTrafficLog(l *TrafficLog) {
+	h.traffic = l
+}
+
 // Start begins async read/write loops.
 func (h *Handler) Start() {
-	go h.readLoop()
+	if h.listener != nil {
+		go h.acceptLoop(h.listener)
+	} else {
+		// Written before anything else, so the agent can adapt from the start
+		if err := h.sendHello(); err != nil {
+			h.reportError(err)
+		}
+		h.startFlow()
+		go h.readLoop()
+	}
 	go h.writeLoop()
 }
 
 // Stop terminates the handler.
 func (h *Handler) Stop() {
 	close(h.done)
+	if h.listener != nil {
+		h.listener.Close()
+	}
+	if h.reattach != nil {
+		h.reattach.Close()
+	}
+	h.writeMu.Lock()
+	if h.conn != nil {
+		h.conn.Close()
+	}
+	h.writeMu.Unlock()
+	h.cancelRequests()
 }
 
 // Incoming returns the channel of incoming messages from Python.
@@ -65,21 +192,66 @@ func (h *Handler) Send(msg *Message) {
 func (h *Handler) SendSync(msg *Message) error {
 	h.writeMu.Lock()
 	defer h.writeMu.Unlock()
+	return h.sendLocked(msg)
+}
+
+// sendLocked writes msg with writeMu held.
+func (h *Handler) sendLocked(msg *Message) error {
+	msg = h.route(msg)
+	if h.writer == nil {
+		if h.queueable(msg.Type) {
+			h.backlog = append(h.backlog, msg)
+			return nil
+		}
+		return ErrNotConnected
+	}
+
+	original := msg
+	if h.compressThreshold > 0 && h.peerCompression != "" && h.rpc != RPCJSONRPC && len(msg.Payload) >= h.compressThreshold {
+		compressed := *msg
+		if err := Compress(&compressed, h.peerCompression); err != nil {
+			return err
+		}
+		msg = &compressed
+	}
 
-	data, err := json.Marshal(msg)
+	var data []byte
+	var err error
+	if h.rpc == RPCJSONRPC {
+		data, err = h.toRPC(msg)
+	} else {
+		data, err = json.Marshal(msg)
+	}
 	if err != nil {
 		return err
 	}
 
-	data = append(data, '\n')
-	_, err = h.writer.Write(data)
-	return err
+	data, err = h.encodeMessage(data)
+	if err != nil {
+		return err
+	}
+	if _, err := h.writer.Write(data); err != nil {
+		return err
+	}
+	if h.traffic != nil {
+		h.traffic.sent(original)
+	}
+	return nil
 }
 
-// readLoop continuously reads messages from stdin.
+// readLoop continuously reads messages from stdin. When stdin ends it waits
+// for a restarted agent if reattaching is enabled.
 func (h *Handler) readLoop() {
-	defer close(h.incoming)
+	h.readFrom(h.reader)
+	if h.reattach != nil {
+		h.detach()
+		return
+	}
+	close(h.incoming)
+}
 
+// readFrom reads messages from r until it ends or the handler stops.
+func (h *Handler) readFrom(r *bufio.Reader) {
 	for {
 		select {
 		case <-h.done:
@@ -87,9 +259,11 @@ func (h *Handler) readLoop() {
 		default:
 		}
 
-		line, err := h.reader.ReadBytes('\n')
+		line, err := h.readMessage(r)
 		if err != nil {
-			if err != io.EOF {
+			// A connection closed by DropAgent, or reset by an agent that
+			// crashed, ends like one the agent closed
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) && !errors.Is(err, syscall.ECONNRESET) {
 				select {
 				case h.errors <- err:
 				case <-h.done:
@@ -98,25 +272,155 @@ func (h *Handler) readLoop() {
 			return
 		}
 
-		if len(line) == 0 || (len(line) == 1 && line[0] == '\n') {
+		if len(line) == 0 {
+			continue
+		}
+		var rpcErr error
+		if h.rpc == RPCJSONRPC {
+			if line, rpcErr = h.fromRPC(line); len(line) == 0 {
+				continue
+			}
+		}
+
+		var msg *Message
+		if rpcErr != nil {
+			msg, err = h.decode(line, rpcErr)
+		} else if IsBatch(line) {
+			msg, err = h.decodeBatch(line)
+		} else {
+			msg, err = h.decode(line, nil)
+		}
+
+		connection := err == nil && msg != nil && msg.connection()
+		if h.flow != nil && !connection {
+			h.flow.used()
+		}
+
+		// Responses to requests from this TUI are not part of the session
+		if err == nil && (msg == nil || msg.ID != "" && h.settle(msg.ID, msg)) {
+			h.grantCredits()
 			continue
 		}
 
-		var msg Message
-		if err := json.Unmarshal(line, &msg); err != nil {
+		if h.recorder != nil && !connection {
+			h.recorder.Write(append(line, '\n'))
+		}
+		if h.journal != nil && !connection && err == nil {
+			h.journal.Write(append(line, '\n'))
+		}
+
+		if err != nil {
 			select {
 			case h.errors <- err:
 			case <-h.done:
 			}
+			h.grantCredits()
 			continue
 		}
 
+		if h.flow != nil && !connection {
+			h.flow.queue()
+		}
 		select {
-		case h.incoming <- &msg:
+		case h.incoming <- msg:
 		case <-h.done:
 			return
 		}
+		h.notePeak()
+	}
+}
+
+// connection reports whether a message belongs to the connection rather
+// than the session, as hellos and pongs do.
+func (m *Message) connection() bool {
+	return m.Type == TypeHello || m.Type == TypePong
+}
+
+// decode decodes one message line, handling hellos and logging and, in
+// strict mode, checking it. A non-nil err is an error already found in the
+// line.
+func (h *Handler) decode(line []byte, err error) (*Message, error) {
+	var msg *Message
+	if err == nil {
+		msg, err = DecodeMessage(line, h.strict)
+	}
+	if err == nil && msg.Type == TypeHello {
+		h.handleHello(msg)
+	}
+	if err == nil {
+		h.noteRoute(msg)
+	}
+	if h.traffic != nil {
+		h.traffic.received(line, msg, err)
+	}
+	if h.strict {
+		err = h.check(line, msg, err)
+	}
+	if err == nil {
+		h.ackRPC(msg)
+	}
+	return msg, err
+}
+
+// check rejects a message that failed to decode, or whose payload is
+// invalid, in strict mode: the agent is told why, and the returned
+// *PayloadError is delivered in place of the message.
+func (h *Handler) check(line []byte, msg *Message, err error) error {
+	var perr *PayloadError
+	if err != nil {
+		perr = rejectedLine(line, err)
+	} else if err := CheckPayload(msg); err != nil {
+		perr = err.(*PayloadError)
+	} else {
+		return nil
+	}
+	if err := h.sendProtocolError(perr); err != nil && !errors.Is(err, ErrNotConnected) {
+		h.reportError(err)
+	}
+	return perr
+}
+
+// sendHello tells a newly connected agent what this TUI supports.
+func (h *Handler) sendHello() error {
+	terminal := h.terminal
+	msg, err := NewMessage(TypeHello, HelloPayload{
+		Compression:     SupportedCompressions,
+		ProtocolVersion: ProtocolVersion,
+		Types:           RenderTypes,
+		Terminal:        &terminal,
+		Batch:           true,
+		Blobs:           h.blobModes(),
+		BlobDir:         h.blobDir,
+		Rich:            RichMimeTypes,
+	})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// handleHello settles which compression to use for outgoing payloads. The
+// message is still delivered, for the settings that concern the app. Hello
+// messages are part of the connection, not the session, so they are not
+// recorded. They are parsed leniently even in strict mode, so agents can
+// advertise features this TUI does not know about.
+func (h *Handler) handleHello(msg *Message) {
+	msg.strict = false
+	var hello HelloPayload
+	if err := msg.ParsePayload(&hello); err != nil {
+		h.reportError(err)
+		return
+	}
+
+	h.writeMu.Lock()
+	h.peerCompression = ""
+	for _, name := range SupportedCompressions {
+		if slices.Contains(hello.Compression, name) {
+			h.peerCompression = name
+			break
+		}
 	}
+	h.writeMu.Unlock()
 }
 
 // writeLoop continuously writes messages to stdout.
@@ -140,16 +444,29 @@ func (h *Handler) writeLoop() {
 
 // SendInput sends a user input message.
 func (h *Handler) SendInput(content string) error {
+	return h.SendInputTo("", content)
+}
+
+// SendInputTo sends user input addressed to the agent with the given
+// agent_id, or to none if it is empty; see multiplex.go.
+func (h *Handler) SendInputTo(agentID, content string) error {
 	msg, err := NewMessage(TypeInput, InputPayload{Content: content})
 	if err != nil {
 		return err
 	}
+	msg.AgentID = agentID
+	if h.journal != nil {
+		if data, err := json.Marshal(msg); err == nil {
+			h.journal.Write(append(data, '\n'))
+		}
+	}
 	return h.SendSync(msg)
 }
 
-// SendFormResponse sends form response.
-func (h *Handler) SendFormResponse(id string, values map[string]any) error {
-	msg, err := NewMessageWithID(TypeFormResponse, id, FormResponsePayload{Values: values})
+// SendFormResponse sends form response. timedOut marks the defaults sent
+// when the user did not answer in time.
+func (h *Handler) SendFormResponse(id string, values map[string]any, timedOut bool) error {
+	msg, err := NewMessageWithID(TypeFormResponse, id, FormResponsePayload{Values: values, TimedOut: timedOut})
 	if err != nil {
 		return err
 	}
@@ -157,8 +474,8 @@ func (h *Handler) SendFormResponse(id string, values map[string]any) error {
 }
 
 // SendConfirmResponse sends confirmation response.
-func (h *Handler) SendConfirmResponse(id string, confirmed bool) error {
-	msg, err := NewMessageWithID(TypeConfirmResponse, id, ConfirmResponsePayload{Confirmed: confirmed})
+func (h *Handler) SendConfirmResponse(id string, confirmed, timedOut bool) error {
+	msg, err := NewMessageWithID(TypeConfirmResponse, id, ConfirmResponsePayload{Confirmed: confirmed, TimedOut: timedOut})
 	if err != nil {
 		return err
 	}
@@ -166,14 +483,164 @@ func (h *Handler) SendConfirmResponse(id string, confirmed bool) error {
 }
 
 // SendSelectResponse sends selection response.
-func (h *Handler) SendSelectResponse(id string, value string) error {
-	msg, err := NewMessageWithID(TypeSelectResponse, id, SelectResponsePayload{Value: value})
+func (h *Handler) SendSelectResponse(id string, value string, timedOut bool) error {
+	msg, err := NewMessageWithID(TypeSelectResponse, id, SelectResponsePayload{Value: value, TimedOut: timedOut})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendQuestionResponse sends the answer to a question: a chosen option's
+// value, or the user's own text if custom is set.
+func (h *Handler) SendQuestionResponse(id string, value string, custom, cancelled bool) error {
+	msg, err := NewMessageWithID(TypeQuestionResponse, id, QuestionResponsePayload{Value: value, Custom: custom, Cancelled: cancelled})
 	if err != nil {
 		return err
 	}
 	return h.SendSync(msg)
 }
 
+// SendSecretResponse sends the value entered in a secret prompt.
+func (h *Handler) SendSecretResponse(id string, value string, cancelled bool) error {
+	msg, err := NewMessageWithID(TypeSecretResponse, id, SecretResponsePayload{Value: value, Cancelled: cancelled})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendAutocompleteResponse sends the value chosen in an autocomplete prompt.
+func (h *Handler) SendAutocompleteResponse(id string, value string, cancelled bool) error {
+	msg, err := NewMessageWithID(TypeAutocompleteResponse, id, AutocompleteResponsePayload{Value: value, Cancelled: cancelled})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendKeepAlive sends an idle keep-alive event.
+func (h *Handler) SendKeepAlive(composing bool) error {
+	msg, err := NewMessage(TypeKeepAlive, KeepAlivePayload{Composing: composing})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendTyping tells the agent the user started or stopped typing.
+func (h *Handler) SendTyping(state, draft string) error {
+	msg, err := NewMessage(TypeTyping, TypingPayload{State: state, Draft: draft})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendRetry asks the agent to retry the work that failed with the error
+// message id and code.
+func (h *Handler) SendRetry(id, code string) error {
+	msg, err := NewMessage(TypeRetry, RetryPayload{ID: id, Code: code})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendRetryInput asks the agent agentID to answer the user's last input
+// again, in place of its earlier answer, which replaced says was removed
+// from the transcript.
+func (h *Handler) SendRetryInput(agentID, input string, replaced bool) error {
+	msg, err := NewMessage(TypeRetry, RetryPayload{Input: input, Replaced: replaced})
+	if err != nil {
+		return err
+	}
+	msg.AgentID = agentID
+	return h.SendSync(msg)
+}
+
+// SendBudgetExceeded tells the agent the session went over its budget.
+func (h *Handler) SendBudgetExceeded(payload BudgetExceededPayload) error {
+	msg, err := NewMessage(TypeBudgetExceeded, payload)
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendCommand tells the agent the user ran its slash command name.
+func (h *Handler) SendCommand(name, args string) error {
+	msg, err := NewMessage(TypeCommand, CommandPayload{Name: name, Args: args})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendTick tells the agent a schedule without a prompt fired at at.
+func (h *Handler) SendTick(schedule string, at time.Time) error {
+	msg, err := NewMessage(TypeTick, TickPayload{Schedule: schedule, At: at})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// Ping sends a liveness check numbered seq, returning a channel that
+// receives the agent's pong; see Request.
+func (h *Handler) Ping(seq int, timeout time.Duration) (<-chan *Message, func()) {
+	msg, _ := NewMessage(TypePing, PingPayload{Seq: seq})
+	return h.Request(msg, timeout)
+}
+
+// SendDiffResponse sends the accepted hunk indices for a diff review.
+func (h *Handler) SendDiffResponse(id string, accepted []int, cancelled bool) error {
+	if accepted == nil {
+		accepted = []int{}
+	}
+	msg, err := NewMessageWithID(TypeDiffResponse, id, DiffResponsePayload{Accepted: accepted, Cancelled: cancelled})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendUnsupported reports that a received message type is not understood.
+// The original message ID is echoed so pending requests can fail fast.
+func (h *Handler) SendUnsupported(id string, msgType MessageType) error {
+	msg, err := NewMessageWithID(TypeUnsupported, id, UnsupportedPayload{
+		Type:      msgType,
+		Supported: RenderTypes,
+	})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
+
+// SendAttachment reads a file and sends it as one or more attachment messages.
+// Returns the first chunk's payload (without data) for display purposes.
+func (h *Handler) SendAttachment(path string) (*AttachmentPayload, error) {
+	payloads, err := ReadAttachment(path, AttachmentChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range payloads {
+		msg, err := NewMessage(TypeAttachment, p)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.SendSync(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	info := payloads[0]
+	info.Data = ""
+	return &info, nil
+}
+
 // SendQuit sends quit message.
 func (h *Handler) SendQuit() error {
 	msg, _ := NewMessage(TypeQuit, nil)
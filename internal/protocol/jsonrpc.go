@@ -0,0 +1,267 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// RPC is the message dialect of a stream.
+type RPC string
+
+// Message dialects. The native one is this package's Message envelope.
+// JSON-RPC 2.0 carries the same messages for agent frameworks that already
+// speak it:
+//
+//   - A message becomes a notification whose method is its type and whose
+//     params are its payload.
+//   - An agent request the user answers, such as confirm, is a JSON-RPC
+//     request; the answer is its result. Other agent messages sent as
+//     requests, e.g. to give them an ID later messages refer to, are
+//     acknowledged with a null result as soon as they are accepted.
+//   - The TUI's pings are requests whose result is the pong payload.
+//   - Unsupported and rejected requests are answered with JSON-RPC errors.
+//
+// The other envelope fields, such as timeout and agent_id, are not carried.
+const (
+	RPCNative  RPC = "native"
+	RPCJSONRPC RPC = "jsonrpc"
+)
+
+// ParseRPC parses a dialect name. An empty name selects the native one.
+func ParseRPC(name string) (RPC, error) {
+	switch RPC(name) {
+	case "", RPCNative:
+		return RPCNative, nil
+	case RPCJSONRPC:
+		return RPCJSONRPC, nil
+	}
+	return "", fmt.Errorf("unknown rpc dialect %q (want native or jsonrpc)", name)
+}
+
+// SetRPC sets the message dialect for both directions. Messages are still
+// handled, recorded and journaled in the native dialect. Call before Start.
+func (h *Handler) SetRPC(rpc RPC) {
+	h.rpc = rpc
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// rpcResults maps the TUI's requests to the type of message their result
+// is delivered as.
+var rpcResults = map[MessageType]MessageType{
+	TypePing: TypePong,
+}
+
+// rpcMessage is a JSON-RPC 2.0 request, notification or response.
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// fromRPC converts a JSON-RPC message, or batch of them, to native JSON.
+// Responses that are not delivered as messages convert to nothing. On
+// error the line is returned as it was, to be reported.
+func (h *Handler) fromRPC(line []byte) ([]byte, error) {
+	if !IsBatch(line) {
+		msg, err := h.fromRPCMessage(line)
+		if err != nil || msg == nil {
+			return line, err
+		}
+		return json.Marshal(msg)
+	}
+
+	elems, err := splitBatch(line)
+	if err != nil {
+		return line, err
+	}
+	msgs := make([]*Message, 0, len(elems))
+	for i, elem := range elems {
+		msg, err := h.fromRPCMessage(elem)
+		if err != nil {
+			return line, fmt.Errorf("batch message %d: %w", i, err)
+		}
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(msgs)
+}
+
+// fromRPCMessage converts one JSON-RPC message.
+func (h *Handler) fromRPCMessage(data []byte) (*Message, error) {
+	var rpc rpcMessage
+	if err := json.Unmarshal(data, &rpc); err != nil {
+		return nil, err
+	}
+	if rpc.JSONRPC != "2.0" {
+		return nil, errors.New(`jsonrpc: missing "jsonrpc": "2.0"`)
+	}
+	id, err := rpcID(rpc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rpc.Method != "" {
+		if id != "" {
+			h.rpcMu.Lock()
+			if h.rpcIDs == nil {
+				h.rpcIDs = make(map[string]json.RawMessage)
+			}
+			h.rpcIDs[id] = rpc.ID
+			h.rpcMu.Unlock()
+		}
+		return &Message{Type: MessageType(rpc.Method), ID: id, Payload: rpc.Params}, nil
+	}
+
+	// A response to one of the TUI's requests
+	h.rpcMu.Lock()
+	msgType, ok := h.rpcPending[id]
+	delete(h.rpcPending, id)
+	h.rpcMu.Unlock()
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("jsonrpc: response to unknown request %s", rpc.ID)
+	case rpc.Error != nil || rpc.Result == nil:
+		// Not answered, as when the agent does not support the request
+		return nil, nil
+	}
+	return &Message{Type: msgType, ID: id, Payload: rpc.Result}, nil
+}
+
+// rpcID returns a JSON-RPC ID as a native one: strings as they are, and
+// numbers as written.
+func rpcID(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var id string
+		err := json.Unmarshal(raw, &id)
+		return id, err
+	}
+	if _, err := strconv.ParseFloat(string(raw), 64); err != nil {
+		return "", fmt.Errorf("jsonrpc: id %s is neither a string nor a number", raw)
+	}
+	return string(raw), nil
+}
+
+// takeRPCID returns the JSON-RPC ID the agent sent for a native ID, which
+// may be a number, forgetting it since it is answered.
+func (h *Handler) takeRPCID(id string) json.RawMessage {
+	h.rpcMu.Lock()
+	defer h.rpcMu.Unlock()
+	if raw, ok := h.rpcIDs[id]; ok {
+		delete(h.rpcIDs, id)
+		return raw
+	}
+	raw, _ := json.Marshal(id)
+	return raw
+}
+
+// toRPC converts a native message to JSON-RPC.
+func (h *Handler) toRPC(msg *Message) ([]byte, error) {
+	rpc := rpcMessage{JSONRPC: "2.0"}
+	switch {
+	case msg.ID != "" && responseTypes[msg.Type]:
+		rpc.ID = h.takeRPCID(msg.ID)
+		rpc.Result = msg.Payload
+		if len(rpc.Result) == 0 {
+			rpc.Result = json.RawMessage("null")
+		}
+
+	case msg.ID != "" && msg.Type == TypeUnsupported:
+		rpc.ID = h.takeRPCID(msg.ID)
+		var p UnsupportedPayload
+		json.Unmarshal(msg.Payload, &p)
+		rpc.Error = &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + string(p.Type), Data: msg.Payload}
+
+	case msg.ID != "" && msg.Type == TypeProtocolError:
+		rpc.ID = h.takeRPCID(msg.ID)
+		var p ProtocolErrorPayload
+		json.Unmarshal(msg.Payload, &p)
+		code := rpcInvalidParams
+		if p.Type == "" {
+			code = rpcInvalidRequest
+		}
+		rpc.Error = &rpcError{Code: code, Message: p.Error, Data: msg.Payload}
+
+	case msg.ID != "" && rpcResults[msg.Type] != "":
+		rpc.ID, _ = json.Marshal(msg.ID)
+		rpc.Method = string(msg.Type)
+		rpc.Params = msg.Payload
+		h.rpcMu.Lock()
+		if h.rpcPending == nil {
+			h.rpcPending = make(map[string]MessageType)
+		}
+		h.rpcPending[msg.ID] = rpcResults[msg.Type]
+		h.rpcMu.Unlock()
+
+	default:
+		rpc.Method = string(msg.Type)
+		rpc.Params = msg.Payload
+	}
+	return json.Marshal(rpc)
+}
+
+// responseTypes are the TUI's answers to agent requests.
+var responseTypes = map[MessageType]bool{
+	TypeFormResponse:         true,
+	TypeConfirmResponse:      true,
+	TypeSelectResponse:       true,
+	TypeSecretResponse:       true,
+	TypeAutocompleteResponse: true,
+	TypeDiffResponse:         true,
+	TypeQuestionResponse:     true,
+}
+
+// ackRPC answers an agent message sent as a JSON-RPC request that the user
+// does not answer, so the agent is not left waiting for a result.
+func (h *Handler) ackRPC(msg *Message) {
+	if h.rpc != RPCJSONRPC || msg.ID == "" || requestTypes[msg.Type] || !slices.Contains(RenderTypes, msg.Type) {
+		return
+	}
+	data, err := json.Marshal(rpcMessage{JSONRPC: "2.0", ID: h.takeRPCID(msg.ID), Result: json.RawMessage("null")})
+	if err == nil {
+		err = h.writeRaw(data)
+	}
+	if err != nil && !errors.Is(err, ErrNotConnected) {
+		h.reportError(err)
+	}
+}
+
+// writeRaw writes a message already in the wire dialect.
+func (h *Handler) writeRaw(data []byte) error {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	if h.writer == nil {
+		return ErrNotConnected
+	}
+	data, err := h.encodeMessage(data)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(data)
+	return err
+}
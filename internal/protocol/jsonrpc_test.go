@@ -0,0 +1,107 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestJSONRPCDialect(t *testing.T) {
+	stdin, agentOut := io.Pipe()
+	agentIn, stdout := io.Pipe()
+	h := NewHandler(stdin, stdout)
+	h.SetRPC(RPCJSONRPC)
+
+	sent := make(chan rpcMessage, 8)
+	go func() {
+		r := bufio.NewReader(agentIn)
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var msg rpcMessage
+			if err := json.Unmarshal(line, &msg); err != nil || msg.JSONRPC != "2.0" {
+				t.Errorf("sent %s, not JSON-RPC", line)
+				continue
+			}
+			if msg.Method != string(TypeHello) {
+				sent <- msg
+			}
+		}
+	}()
+	next := func() rpcMessage {
+		t.Helper()
+		select {
+		case msg := <-sent:
+			return msg
+		case <-time.After(2 * time.Second):
+			t.Fatal("nothing was sent")
+		}
+		return rpcMessage{}
+	}
+	h.Start()
+	defer h.Stop()
+
+	// A request the user answers, with a numeric ID
+	io.WriteString(agentOut, `{"jsonrpc":"2.0","id":7,"method":"confirm","params":{"message":"ok?"}}`+"\n")
+	select {
+	case msg := <-h.Incoming():
+		if msg.Type != TypeConfirm || msg.ID != "7" {
+			t.Fatalf("got %s %q, want confirm 7", msg.Type, msg.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the confirm was not delivered")
+	}
+	h.SendConfirmResponse("7", true, false)
+	if msg := next(); string(msg.ID) != "7" || string(msg.Result) != `{"confirmed":true}` {
+		t.Errorf("answered %s with %s, want 7 with the confirmation", msg.ID, msg.Result)
+	}
+
+	// Other requests are acknowledged at once
+	io.WriteString(agentOut, `{"jsonrpc":"2.0","id":"m1","method":"markdown","params":{"content":"hi"}}`+"\n")
+	<-h.Incoming()
+	if msg := next(); string(msg.ID) != `"m1"` || string(msg.Result) != "null" {
+		t.Errorf("acknowledged %s with %s, want \"m1\" with null", msg.ID, msg.Result)
+	}
+
+	// Notifications are plain messages, in both directions
+	io.WriteString(agentOut, `{"jsonrpc":"2.0","method":"text","params":{"content":"hi","done":true}}`+"\n")
+	if msg := <-h.Incoming(); msg.Type != TypeText || msg.ID != "" {
+		t.Errorf("got %s %q, want text without an ID", msg.Type, msg.ID)
+	}
+	h.SendInput("hello")
+	if msg := next(); msg.Method != "input" || msg.ID != nil || string(msg.Params) != `{"content":"hello"}` {
+		t.Errorf("sent %+v, want an input notification", msg)
+	}
+
+	// Unknown methods are answered with an error
+	h.SendUnsupported("9", "teleport")
+	if msg := next(); msg.Error == nil || msg.Error.Code != rpcMethodNotFound {
+		t.Errorf("sent %+v, want a method not found error", msg)
+	}
+
+	// The TUI's pings are requests
+	pong, _ := h.Ping(1, 2*time.Second)
+	ping := next()
+	if ping.Method != "ping" || ping.ID == nil {
+		t.Fatalf("sent %+v, want a ping request", ping)
+	}
+	io.WriteString(agentOut, `{"jsonrpc":"2.0","id":`+string(ping.ID)+`,"result":{"seq":1}}`+"\n")
+	if msg := <-pong; msg == nil || msg.Type != TypePong {
+		t.Errorf("got %+v, want the pong", msg)
+	}
+}
+
+func TestParseRPC(t *testing.T) {
+	for name, want := range map[string]RPC{"": RPCNative, "native": RPCNative, "jsonrpc": RPCJSONRPC} {
+		if got, err := ParseRPC(name); err != nil || got != want {
+			t.Errorf("ParseRPC(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseRPC("grpc"); err == nil {
+		t.Error("ParseRPC accepted grpc")
+	}
+}
@@ -0,0 +1,169 @@
+package protocol
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrNotConnected is returned when sending while no agent is connected to a
+// listening handler.
+var ErrNotConnected = errors.New("no agent connected")
+
+// handshakeTimeout is how long an agent connecting over TLS has to
+// complete the handshake.
+const handshakeTimeout = 10 * time.Second
+
+// ConnEvent reports an agent connecting to or disconnecting from a
+// listening handler.
+type ConnEvent struct {
+	Connected bool
+	Addr      string // the agent's address
+	// The common name of the agent's client certificate, verified when
+	// agents must present one (mutual TLS)
+	Peer string
+}
+
+// NewListenerHandler creates a handler that serves agents connecting to ln,
+// one at a time. Connections made while an agent is connected are closed
+// straight away. Messages sent while no agent is connected fail with
+// ErrNotConnected. The Incoming channel stays open across connections.
+func NewListenerHandler(ln net.Listener) *Handler {
+	h := NewHandler(nil, nil)
+	h.reader = nil
+	h.listener = ln
+	h.connections = make(chan ConnEvent, 10)
+	return h
+}
+
+// ListenAddr returns the address a listening handler accepts agents on, or
+// "" for a handler reading a stream. Once a stream has ended, it is the
+// address restarted agents can reattach on, if enabled.
+func (h *Handler) ListenAddr() string {
+	switch {
+	case h.listener != nil:
+		return h.listener.Addr().String()
+	case h.detached.Load():
+		return h.reattach.Addr().String()
+	}
+	return ""
+}
+
+// Connections returns the channel of connect and disconnect events. It is
+// nil for a handler reading a stream.
+func (h *Handler) Connections() <-chan ConnEvent {
+	return h.connections
+}
+
+// DropAgent disconnects the connected agent so another can connect in its
+// place, such as a restarted agent replacing one that stopped responding.
+// It does nothing for a handler reading a stream or when no agent is
+// connected.
+func (h *Handler) DropAgent() {
+	h.writeMu.Lock()
+	conn := h.conn
+	h.writeMu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// acceptLoop accepts agents on ln until the handler stops.
+func (h *Handler) acceptLoop(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			select {
+			case <-h.done:
+				return
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			h.reportError(err)
+			continue
+		}
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			go h.admitTLS(tlsConn)
+			continue
+		}
+		h.admit(conn, "")
+	}
+}
+
+// admitTLS completes the TLS handshake with a connecting agent before
+// admitting it, so an agent without a valid certificate is turned away
+// without taking the place of one that has.
+func (h *Handler) admitTLS(conn *tls.Conn) {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := conn.Handshake(); err != nil {
+		conn.Close()
+		h.reportError(fmt.Errorf("rejected agent at %s: %w", conn.RemoteAddr(), err))
+		return
+	}
+	conn.SetDeadline(time.Time{})
+
+	peer := ""
+	if certs := conn.ConnectionState().PeerCertificates; len(certs) > 0 {
+		peer = certs[0].Subject.CommonName
+	}
+	h.admit(conn, peer)
+}
+
+// admit serves a connecting agent, unless another one is connected.
+func (h *Handler) admit(conn net.Conn, peer string) {
+	h.writeMu.Lock()
+	busy := h.conn != nil
+	if !busy {
+		h.conn = conn
+		h.writer = conn
+		h.peerCompression = "" // until the new agent says hello
+	}
+	h.writeMu.Unlock()
+	if busy {
+		conn.Close()
+		return
+	}
+	go h.serve(conn, peer)
+}
+
+// serve reads messages from a connected agent until it disconnects.
+func (h *Handler) serve(conn net.Conn, peer string) {
+	addr := conn.RemoteAddr().String()
+	if err := h.sendHello(); err != nil {
+		h.reportError(err)
+	}
+	h.startFlow()
+	if err := h.flushBacklog(); err != nil {
+		h.reportError(err)
+	}
+	h.notify(ConnEvent{Connected: true, Addr: addr, Peer: peer})
+
+	h.readFrom(bufio.NewReader(conn))
+
+	h.writeMu.Lock()
+	h.conn = nil
+	h.writer = nil
+	h.writeMu.Unlock()
+	conn.Close()
+
+	h.notify(ConnEvent{Connected: false, Addr: addr, Peer: peer})
+}
+
+func (h *Handler) notify(ev ConnEvent) {
+	select {
+	case h.connections <- ev:
+	case <-h.done:
+	}
+}
+
+func (h *Handler) reportError(err error) {
+	select {
+	case h.errors <- err:
+	case <-h.done:
+	}
+}
@@ -0,0 +1,228 @@
+package protocol
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListenerHandler(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	h := NewListenerHandler(ln)
+	h.Start()
+	defer h.Stop()
+
+	if h.ListenAddr() != ln.Addr().String() {
+		t.Errorf("ListenAddr() = %q, want %q", h.ListenAddr(), ln.Addr())
+	}
+	if err := h.SendResize(80, 24); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("SendResize with no agent = %v, want ErrNotConnected", err)
+	}
+
+	for round := 0; round < 2; round++ {
+		agent, err := net.Dial("tcp", h.ListenAddr())
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		if ev := waitEvent(t, h); !ev.Connected || ev.Addr != agent.LocalAddr().String() {
+			t.Fatalf("round %d: event = %+v, want connect from %s", round, ev, agent.LocalAddr())
+		}
+
+		// A second agent is turned away while the first is connected
+		other, err := net.Dial("tcp", h.ListenAddr())
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		other.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, err := other.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("round %d: second agent read = %v, want EOF", round, err)
+		}
+		other.Close()
+
+		// Messages flow both ways
+		io.WriteString(agent, `{"type":"text","payload":{"content":"hi"}}`+"\n")
+		select {
+		case msg := <-h.Incoming():
+			if msg.Type != TypeText {
+				t.Errorf("round %d: received %s, want text", round, msg.Type)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("round %d: no message received", round)
+		}
+		if err := h.SendResize(80, 24); err != nil {
+			t.Fatalf("round %d: SendResize failed: %v", round, err)
+		}
+		agent.SetReadDeadline(time.Now().Add(2 * time.Second))
+		r := bufio.NewReader(agent)
+		for _, want := range []string{`"hello"`, `"resize"`} {
+			line, err := r.ReadString('\n')
+			if err != nil || !strings.Contains(line, want) {
+				t.Errorf("round %d: agent read %q, %v; want a %s message", round, line, err, want)
+			}
+		}
+
+		agent.Close()
+		if ev := waitEvent(t, h); ev.Connected {
+			t.Fatalf("round %d: event = %+v, want disconnect", round, ev)
+		}
+	}
+}
+
+func TestListenerDropAgent(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	h := NewListenerHandler(ln)
+	h.Start()
+	defer h.Stop()
+
+	agent, err := net.Dial("tcp", h.ListenAddr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer agent.Close()
+	waitEvent(t, h)
+
+	h.DropAgent()
+	if ev := waitEvent(t, h); ev.Connected {
+		t.Fatalf("event = %+v, want disconnect", ev)
+	}
+	select {
+	case err := <-h.Errors():
+		t.Errorf("dropping the agent reported %v", err)
+	default:
+	}
+
+	// A replacement agent can connect
+	next, err := net.Dial("tcp", h.ListenAddr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer next.Close()
+	if ev := waitEvent(t, h); !ev.Connected {
+		t.Fatalf("event = %+v, want connect", ev)
+	}
+}
+
+func TestListenerMutualTLS(t *testing.T) {
+	ca, caKey := newTestCert(t, "Test CA", nil, nil)
+	server, serverKey := newTestCert(t, "127.0.0.1", ca, caKey)
+	client, clientKey := newTestCert(t, "build-agent", ca, caKey)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{server.Raw}, PrivateKey: serverKey}},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	h := NewListenerHandler(ln)
+	h.Start()
+	defer h.Stop()
+
+	// An agent without a client certificate is turned away
+	impostor, err := tls.Dial("tcp", h.ListenAddr(), &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"})
+	if err == nil {
+		impostor.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, err = impostor.Read(make([]byte, 1))
+		impostor.Close()
+	}
+	if err == nil {
+		t.Fatal("agent without a client certificate could read from the TUI")
+	}
+	select {
+	case err := <-h.Errors():
+		if !strings.Contains(err.Error(), "rejected agent") {
+			t.Errorf("error = %v, want a rejected agent", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("rejecting the agent reported no error")
+	}
+	select {
+	case ev := <-h.Connections():
+		t.Fatalf("rejected agent sent event %+v", ev)
+	default:
+	}
+
+	// One with a certificate the CA signed is let in and named by it
+	agent, err := tls.Dial("tcp", h.ListenAddr(), &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{client.Raw}, PrivateKey: clientKey}},
+		RootCAs:      pool,
+		ServerName:   "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer agent.Close()
+	if ev := waitEvent(t, h); !ev.Connected || ev.Peer != "build-agent" {
+		t.Fatalf("event = %+v, want connect from build-agent", ev)
+	}
+	agent.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(agent).ReadString('\n')
+	if err != nil || !strings.Contains(line, `"hello"`) {
+		t.Errorf("agent read %q, %v; want a hello message", line, err)
+	}
+}
+
+// newTestCert creates a certificate for name, signed by parent, or a CA
+// signing itself if parent is nil.
+func newTestCert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func waitEvent(t *testing.T, h *Handler) ConnEvent {
+	t.Helper()
+	select {
+	case ev := <-h.Connections():
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("no connection event")
+		return ConnEvent{}
+	}
+}
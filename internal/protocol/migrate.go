@@ -0,0 +1,68 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is the current message format version. Messages without a
+// version field come from older SDKs and are treated as version 1.
+const ProtocolVersion = 2
+
+// payloadMigration rewrites a payload from one version to the next.
+type payloadMigration func(json.RawMessage) (json.RawMessage, error)
+
+// migrations[v] upgrades payloads from version v to v+1, keyed by message
+// type. Types without an entry are unchanged between those versions.
+var migrations = map[int]map[MessageType]payloadMigration{
+	1: {
+		TypeSelect: migrateSelectOptions,
+	},
+}
+
+// Migrate upconverts an incoming message's payload to ProtocolVersion.
+// Messages from newer versions are left untouched.
+func Migrate(msg *Message) error {
+	version := msg.Version
+	if version == 0 {
+		version = 1
+	}
+	if version >= ProtocolVersion {
+		return nil
+	}
+
+	for v := version; v < ProtocolVersion; v++ {
+		migrate, ok := migrations[v][msg.Type]
+		if !ok || len(msg.Payload) == 0 {
+			continue
+		}
+		payload, err := migrate(msg.Payload)
+		if err != nil {
+			return fmt.Errorf("migrating %s payload from v%d: %w", msg.Type, v, err)
+		}
+		msg.Payload = payload
+	}
+	msg.Version = ProtocolVersion
+	return nil
+}
+
+// migrateSelectOptions converts v1 string options to v2 option objects.
+func migrateSelectOptions(raw json.RawMessage) (json.RawMessage, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	if err := json.Unmarshal(payload["options"], &labels); err != nil {
+		// Already in the new shape
+		return raw, nil
+	}
+
+	options, err := json.Marshal(OptionsFromStrings(labels))
+	if err != nil {
+		return nil, err
+	}
+	payload["options"] = options
+	return json.Marshal(payload)
+}
@@ -0,0 +1,68 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateSelectOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []SelectOption
+	}{
+		{
+			name: "v1 string options",
+			line: `{"type":"select","id":"1","payload":{"label":"Pick","options":["a","b"]}}`,
+			want: []SelectOption{{Label: "a", Value: "a"}, {Label: "b", Value: "b"}},
+		},
+		{
+			name: "v2 rich options",
+			line: `{"type":"select","id":"1","version":2,"payload":{"label":"Pick","options":[{"label":"A","value":"a","description":"first"}]}}`,
+			want: []SelectOption{{Label: "A", Value: "a", Description: "first"}},
+		},
+		{
+			name: "unversioned rich options",
+			line: `{"type":"select","id":"1","payload":{"label":"Pick","options":[{"label":"A","value":"a"}]}}`,
+			want: []SelectOption{{Label: "A", Value: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			if err := json.Unmarshal([]byte(tt.line), &msg); err != nil {
+				t.Fatal(err)
+			}
+			if err := Migrate(&msg); err != nil {
+				t.Fatalf("Migrate() error = %v", err)
+			}
+			if msg.Version != ProtocolVersion {
+				t.Errorf("Version = %d, want %d", msg.Version, ProtocolVersion)
+			}
+
+			var payload SelectPayload
+			if err := msg.ParsePayload(&payload); err != nil {
+				t.Fatalf("ParsePayload() error = %v", err)
+			}
+			if payload.Label != "Pick" {
+				t.Errorf("Label = %q, want Pick", payload.Label)
+			}
+			if !reflect.DeepEqual(payload.Options, tt.want) {
+				t.Errorf("Options = %+v, want %+v", payload.Options, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateLeavesOtherTypes(t *testing.T) {
+	raw := json.RawMessage(`{"content":"hi"}`)
+	msg := Message{Type: TypeMarkdown, Payload: raw}
+	if err := Migrate(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.Payload) != string(raw) {
+		t.Errorf("payload changed: %s", msg.Payload)
+	}
+}
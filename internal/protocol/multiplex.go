@@ -0,0 +1,52 @@
+package protocol
+
+// Several logical agents, such as an orchestrator and its workers, can share
+// one connection by naming themselves in the agent_id envelope field. The
+// TUI shows each agent's messages under its name, and answers to an agent's
+// requests carry its agent_id back, so the other end can route them. User
+// input typed in a chat tab opened for an agent is addressed to it; other
+// input and events the TUI starts carry none.
+
+// requestTypes are the agent messages the TUI answers, by their ID.
+var requestTypes = map[MessageType]bool{
+	TypeForm:         true,
+	TypeConfirm:      true,
+	TypeSelect:       true,
+	TypeSecret:       true,
+	TypeAutocomplete: true,
+	TypeDiff:         true,
+	TypeQuestion:     true,
+}
+
+// noteRoute remembers which agent sent a request, so the answer can be
+// addressed to it.
+func (h *Handler) noteRoute(msg *Message) {
+	if msg.AgentID == "" || msg.ID == "" || !requestTypes[msg.Type] {
+		return
+	}
+	h.routesMu.Lock()
+	defer h.routesMu.Unlock()
+	if h.routes == nil {
+		h.routes = make(map[string]string)
+	}
+	h.routes[msg.ID] = msg.AgentID
+}
+
+// route returns msg addressed to the agent whose request it answers, if
+// that agent named itself. The answer settles the request, so later
+// messages with the same ID are not addressed.
+func (h *Handler) route(msg *Message) *Message {
+	if msg.AgentID != "" || msg.ID == "" {
+		return msg
+	}
+	h.routesMu.Lock()
+	agent, ok := h.routes[msg.ID]
+	delete(h.routes, msg.ID)
+	h.routesMu.Unlock()
+	if !ok {
+		return msg
+	}
+	routed := *msg
+	routed.AgentID = agent
+	return &routed
+}
@@ -0,0 +1,58 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestResponsesAreRoutedToTheAsker(t *testing.T) {
+	stdin, agentOut := io.Pipe()
+	agentIn, stdout := io.Pipe()
+	h := NewHandler(stdin, stdout)
+
+	sent := make(chan *Message, 5)
+	go func() {
+		r := bufio.NewReader(agentIn)
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var msg Message
+			if json.Unmarshal(line, &msg) == nil && msg.Type != TypeHello {
+				sent <- &msg
+			}
+		}
+	}()
+	h.Start()
+	defer h.Stop()
+
+	io.WriteString(agentOut, `{"type":"confirm","id":"c1","agent_id":"worker-2","payload":{"message":"ok?"}}`+"\n")
+	select {
+	case msg := <-h.Incoming():
+		if msg.AgentID != "worker-2" {
+			t.Fatalf("agent_id %q, want worker-2", msg.AgentID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the confirm was not delivered")
+	}
+
+	h.SendConfirmResponse("c1", true, false)
+	h.SendConfirmResponse("c1", false, false) // already answered
+	h.SendInput("hello")
+	h.SendInputTo("worker-3", "hi")
+	h.SendRetryInput("worker-3", "hi", true)
+	for i, want := range []string{"worker-2", "", "", "worker-3", "worker-3"} {
+		select {
+		case msg := <-sent:
+			if msg.AgentID != want {
+				t.Errorf("message %d (%s) sent to %q, want %q", i, msg.Type, msg.AgentID, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("message %d was not sent", i)
+		}
+	}
+}
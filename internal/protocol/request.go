@@ -0,0 +1,93 @@
+package protocol
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// request is a message sent with Request whose response is awaited.
+type request struct {
+	response chan *Message
+	timer    *time.Timer // nil without a timeout
+}
+
+// Request sends msg and returns a channel that receives the agent's
+// response: the next incoming message with the same ID. msg is given an ID
+// if it has none. Responses are delivered only on the returned channel,
+// never on Incoming.
+//
+// Request is for messages the TUI sends and awaits an answer to, such as
+// Ping. Requests the agent sends the user, such as forms and
+// confirmations, go the other way: they arrive on Incoming, and the app
+// keeps their ID and timeout until it sends the answer.
+//
+// The channel is closed without a value if no response arrives within
+// timeout (zero waits indefinitely), if cancel is called, if msg cannot be
+// sent or when the handler stops. The timeout is sent along with msg, so
+// the agent knows when its answer is no longer awaited. Send errors other
+// than ErrNotConnected are also reported on Errors.
+func (h *Handler) Request(msg *Message, timeout time.Duration) (<-chan *Message, func()) {
+	if msg.ID == "" {
+		msg.ID = "tui-" + strconv.FormatInt(h.requestSeq.Add(1), 10)
+	}
+	msg.Timeout = timeout.Seconds()
+
+	id := msg.ID
+	cancel := func() { h.settle(id, nil) }
+	req := &request{response: make(chan *Message, 1)}
+
+	h.requestsMu.Lock()
+	if h.requests == nil {
+		h.requests = make(map[string]*request)
+	}
+	if old := h.requests[id]; old != nil {
+		h.finishLocked(id, old, nil)
+	}
+	h.requests[id] = req
+	if timeout > 0 {
+		req.timer = time.AfterFunc(timeout, cancel)
+	}
+	h.requestsMu.Unlock()
+
+	if err := h.SendSync(msg); err != nil {
+		if !errors.Is(err, ErrNotConnected) {
+			h.reportError(err)
+		}
+		cancel()
+	}
+	return req.response, cancel
+}
+
+// settle ends the request with the given ID, delivering response unless
+// it is nil. It reports whether such a request was pending.
+func (h *Handler) settle(id string, response *Message) bool {
+	h.requestsMu.Lock()
+	defer h.requestsMu.Unlock()
+	req, ok := h.requests[id]
+	if ok {
+		h.finishLocked(id, req, response)
+	}
+	return ok
+}
+
+// finishLocked removes a pending request with requestsMu held.
+func (h *Handler) finishLocked(id string, req *request, response *Message) {
+	delete(h.requests, id)
+	if req.timer != nil {
+		req.timer.Stop()
+	}
+	if response != nil {
+		req.response <- response
+	}
+	close(req.response)
+}
+
+// cancelRequests ends every pending request when the handler stops.
+func (h *Handler) cancelRequests() {
+	h.requestsMu.Lock()
+	defer h.requestsMu.Unlock()
+	for id, req := range h.requests {
+		h.finishLocked(id, req, nil)
+	}
+}
@@ -0,0 +1,78 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	stdin, agentOut := io.Pipe()
+	agentIn, stdout := io.Pipe()
+	h := NewHandler(stdin, stdout)
+
+	// Read the requests as the agent
+	sent := make(chan *Message, 2)
+	go func() {
+		r := bufio.NewReader(agentIn)
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var msg Message
+			if json.Unmarshal(line, &msg) == nil && msg.Type == TypePing {
+				sent <- &msg
+			}
+		}
+	}()
+	h.Start()
+	defer h.Stop()
+
+	ping, _ := NewMessage(TypePing, PingPayload{Seq: 1})
+	response, _ := h.Request(ping, time.Minute)
+	req := <-sent
+	if req.ID == "" || req.Timeout != 60 {
+		t.Fatalf("request sent with ID %q and timeout %v, want an ID and 60", req.ID, req.Timeout)
+	}
+
+	// The response goes to the requester; other messages to Incoming
+	io.WriteString(agentOut, `{"type":"pong","id":"`+req.ID+`","payload":{"seq":1}}`+"\n")
+	io.WriteString(agentOut, `{"type":"pong","id":"`+req.ID+`","payload":{"seq":2}}`+"\n")
+	select {
+	case msg := <-response:
+		if msg == nil || msg.Type != TypePong {
+			t.Errorf("response = %v, want the pong", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no response")
+	}
+	if _, ok := <-response; ok {
+		t.Error("response channel still open after the response")
+	}
+	select {
+	case msg := <-h.Incoming():
+		if string(msg.Payload) != `{"seq":2}` {
+			t.Errorf("Incoming received %s, want only the unrequested pong", msg.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the second pong was not delivered to Incoming")
+	}
+
+	// Requests end without a response on timeout or cancel
+	timedOut, _ := h.Request(&Message{Type: TypePing}, 10*time.Millisecond)
+	cancelled, cancel := h.Request(&Message{Type: TypePing}, 0)
+	cancel()
+	for name, ch := range map[string]<-chan *Message{"timed out": timedOut, "cancelled": cancelled} {
+		select {
+		case msg, ok := <-ch:
+			if ok {
+				t.Errorf("%s request received %v, want the channel closed", name, msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("%s request still pending", name)
+		}
+	}
+}
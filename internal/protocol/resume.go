@@ -0,0 +1,68 @@
+package protocol
+
+import "net"
+
+// StdinAddr is the address reported when the agent on stdin and stdout
+// disconnects.
+const StdinAddr = "stdin"
+
+// SetReattach lets a restarted agent resume the session after stdin ends,
+// by connecting to ln; the handler then works like one made by
+// NewListenerHandler. The end of stdin is reported as a disconnect from
+// StdinAddr instead of closing the Incoming channel. Call before Start.
+func (h *Handler) SetReattach(ln net.Listener) {
+	h.reattach = ln
+	h.connections = make(chan ConnEvent, 10)
+}
+
+// detach drops the stdin agent and waits for a restarted one.
+func (h *Handler) detach() {
+	h.writeMu.Lock()
+	h.writer = nil
+	h.peerCompression = ""
+	h.writeMu.Unlock()
+	h.detached.Store(true)
+
+	h.notify(ConnEvent{Connected: false, Addr: StdinAddr})
+	h.acceptLoop(h.reattach)
+}
+
+// queueable reports whether a message sent while no agent is connected is
+// kept for the next agent. Those are the user's input and answers to
+// requests, so nothing the user did while the agent was away is lost; the
+// rest only matter to the agent they were meant for. Messages are only
+// kept by handlers that agents can connect to.
+func (h *Handler) queueable(t MessageType) bool {
+	if h.listener == nil && h.reattach == nil {
+		return false
+	}
+	switch t {
+	case TypeInput, TypeFormResponse, TypeConfirmResponse, TypeSelectResponse,
+		TypeSecretResponse, TypeAutocompleteResponse, TypeDiffResponse, TypeQuestionResponse,
+		TypeAttachment, TypeCancel, TypeRetry, TypeCommand:
+		return true
+	}
+	return false
+}
+
+// Backlog returns the number of messages waiting for an agent to connect.
+func (h *Handler) Backlog() int {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	return len(h.backlog)
+}
+
+// flushBacklog delivers the messages kept while no agent was connected to
+// the agent that just connected.
+func (h *Handler) flushBacklog() error {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	for len(h.backlog) > 0 {
+		if err := h.sendLocked(h.backlog[0]); err != nil {
+			return err
+		}
+		h.backlog = h.backlog[1:]
+	}
+	h.backlog = nil
+	return nil
+}
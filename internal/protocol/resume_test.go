@@ -0,0 +1,78 @@
+package protocol
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReattach(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	stdin, agentOut := io.Pipe()
+	h := NewHandler(stdin, io.Discard)
+	h.SetReattach(ln)
+	h.Start()
+	defer h.Stop()
+
+	if h.ListenAddr() != "" {
+		t.Errorf("ListenAddr() = %q before stdin ended, want none", h.ListenAddr())
+	}
+
+	// The agent on stdin exits
+	agentOut.Close()
+	if ev := waitEvent(t, h); ev.Connected || ev.Addr != StdinAddr {
+		t.Fatalf("event = %+v, want stdin disconnect", ev)
+	}
+	if h.ListenAddr() != ln.Addr().String() {
+		t.Errorf("ListenAddr() = %q, want %q", h.ListenAddr(), ln.Addr())
+	}
+
+	// Answers given meanwhile wait for the next agent; other events don't
+	if err := h.SendFormResponse("form-1", map[string]any{"name": "x"}, false); err != nil {
+		t.Fatalf("SendFormResponse while detached = %v, want it kept", err)
+	}
+	if err := h.SendResize(80, 24); err == nil {
+		t.Error("SendResize while detached succeeded, want ErrNotConnected")
+	}
+	if h.Backlog() != 1 {
+		t.Errorf("Backlog() = %d, want 1", h.Backlog())
+	}
+
+	agent, err := net.Dial("tcp", h.ListenAddr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer agent.Close()
+	if ev := waitEvent(t, h); !ev.Connected {
+		t.Fatalf("event = %+v, want connect", ev)
+	}
+
+	agent.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r := bufio.NewReader(agent)
+	for _, want := range []string{`"hello"`, `"form-1"`} {
+		line, err := r.ReadString('\n')
+		if err != nil || !strings.Contains(line, want) {
+			t.Errorf("agent read %q, %v; want %s", line, err, want)
+		}
+	}
+	if h.Backlog() != 0 {
+		t.Errorf("Backlog() = %d after reattaching, want 0", h.Backlog())
+	}
+
+	// The Incoming channel stays open for the new agent
+	io.WriteString(agent, `{"type":"text","payload":{"content":"back"}}`+"\n")
+	select {
+	case msg, ok := <-h.Incoming():
+		if !ok || msg.Type != TypeText {
+			t.Errorf("received %v, %v; want the new agent's text", msg, ok)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message from the reattached agent")
+	}
+}
@@ -0,0 +1,60 @@
+package protocol
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// RichMimeTypes lists the representations of a rich message the TUI
+// renders, best first: markdown, images drawn with half blocks, LaTeX as
+// display math, JSON as code, plain text, and HTML with its tags
+// stripped as a last resort.
+var RichMimeTypes = slices.Concat(
+	[]string{"text/markdown"},
+	richImageTypes,
+	[]string{"text/latex", "application/json", "text/plain", "text/html"},
+)
+
+// Representations returns the MIME types of the representations in r that
+// the TUI renders, best first.
+func (r RichPayload) Representations() []string {
+	var mimes []string
+	for _, mime := range RichMimeTypes {
+		if _, ok := r.Data[mime]; ok {
+			mimes = append(mimes, mime)
+		}
+	}
+	return mimes
+}
+
+// Text returns the representation of type mime as text: a string as it
+// is, a list of strings joined, and other JSON values indented.
+func (r RichPayload) Text(mime string) string {
+	switch v := r.Data[mime].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		lines := make([]string, 0, len(v))
+		for _, line := range v {
+			s, ok := line.(string)
+			if !ok {
+				return indentJSON(v)
+			}
+			lines = append(lines, s)
+		}
+		return strings.Join(lines, "")
+	default:
+		return indentJSON(v)
+	}
+}
+
+func indentJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,7 @@
+//go:build !noimages
+
+package protocol
+
+// richImageTypes are the image formats rich messages may carry, left out
+// of noimages builds.
+var richImageTypes = []string{"image/png", "image/jpeg", "image/gif"}
@@ -0,0 +1,6 @@
+//go:build noimages
+
+package protocol
+
+// richImageTypes is empty: noimages builds do not draw images.
+var richImageTypes []string
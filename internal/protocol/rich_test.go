@@ -0,0 +1,33 @@
+package protocol
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRichRepresentations(t *testing.T) {
+	msg, err := DecodeMessage([]byte(`{"type":"rich","payload":{"data":{
+		"text/plain": ["   a  b\n", "0  1  2"],
+		"text/html": "<table></table>",
+		"application/json": {"a": [1, 2]},
+		"application/x-unknown": "?"
+	}}}`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p RichPayload
+	if err := msg.ParsePayload(&p); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"application/json", "text/plain", "text/html"}
+	if got := p.Representations(); !slices.Equal(got, want) {
+		t.Errorf("Representations() = %v, want %v", got, want)
+	}
+	if got := p.Text("text/plain"); got != "   a  b\n0  1  2" {
+		t.Errorf("Text(text/plain) = %q, want the lines joined", got)
+	}
+	if got := p.Text("application/json"); got != "{\n  \"a\": [\n    1,\n    2\n  ]\n}" {
+		t.Errorf("Text(application/json) = %q, want it indented", got)
+	}
+}
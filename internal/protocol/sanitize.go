@@ -0,0 +1,190 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sanitize neutralizes terminal control sequences in the strings of the
+// message, so an agent, malicious or buggy, cannot clear the screen, move
+// the cursor, retitle the window or otherwise take over the terminal the
+// TUI draws on. Escape sequences and control characters other than tabs
+// and newlines are removed or, with reveal, kept as visible text such as
+// "␛[2J". It returns how many were found.
+func (m *Message) Sanitize(reveal bool) int {
+	n := 0
+	m.Type = MessageType(sanitizeString(string(m.Type), reveal, &n))
+	m.AgentID = sanitizeString(m.AgentID, reveal, &n)
+	m.Region = sanitizeString(m.Region, reveal, &n)
+	if !mayHoldControls(m.Payload) {
+		return n
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(m.Payload))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return n // reported when the payload is parsed
+	}
+	found := 0
+	v = sanitizeValue(v, reveal, &found)
+	if found == 0 {
+		return n
+	}
+	if data, err := json.Marshal(v); err == nil {
+		m.Payload = data
+	}
+	return n + found
+}
+
+// mayHoldControls reports whether a JSON payload may hold a control
+// character: as an escape, since JSON does not allow them raw in strings,
+// or as a C1 control, which it does.
+func mayHoldControls(payload []byte) bool {
+	for _, esc := range []string{`\u`, `\r`, `\b`, `\f`} {
+		if bytes.Contains(payload, []byte(esc)) {
+			return true
+		}
+	}
+	for i := 0; i+1 < len(payload); i++ {
+		if payload[i] == 0xc2 && payload[i+1] >= 0x80 && payload[i+1] <= 0x9f {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitizeValue(v any, reveal bool, n *int) any {
+	switch v := v.(type) {
+	case string:
+		return sanitizeString(v, reveal, n)
+	case []any:
+		for i := range v {
+			v[i] = sanitizeValue(v[i], reveal, n)
+		}
+	case map[string]any:
+		for k, elem := range v {
+			v[k] = sanitizeValue(elem, reveal, n)
+		}
+	}
+	return v
+}
+
+// isControl reports whether r is a control character a terminal acts on:
+// C0 controls other than tab and newline, DEL and C1 controls.
+func isControl(r rune) bool {
+	return r < 0x20 && r != '\t' && r != '\n' || r >= 0x7f && r <= 0x9f
+}
+
+// sanitizeString removes each control character from s along with the
+// rest of the escape sequence it starts, adding how many there were to n.
+func sanitizeString(s string, reveal bool, n *int) string {
+	if strings.IndexFunc(s, isControl) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !isControl(r) {
+			b.WriteString(s[i : i+size])
+			i += size
+			continue
+		}
+		end := i + size + sequenceLen(r, s[i+size:])
+		if reveal {
+			b.WriteString(revealControls(s[i:end]))
+		}
+		*n++
+		i = end
+	}
+	return b.String()
+}
+
+// sequenceLen returns the length of the rest of the escape sequence that
+// intro starts, at the start of rest. An unterminated string sequence,
+// such as an OSC, runs to the end of rest, as it would in a terminal.
+func sequenceLen(intro rune, rest string) int {
+	switch intro {
+	case 0x1b: // ESC
+	case 0x9b: // CSI
+		return csiLen(rest)
+	case 0x90, 0x98, 0x9d, 0x9e, 0x9f: // DCS, SOS, OSC, PM, APC
+		return stringLen(rest)
+	default:
+		return 0
+	}
+	if rest == "" {
+		return 0
+	}
+	switch c := rest[0]; {
+	case c == '[':
+		return 1 + csiLen(rest[1:])
+	case c == ']' || c == 'P' || c == 'X' || c == '^' || c == '_':
+		return 1 + stringLen(rest[1:])
+	case c >= 0x20 && c <= 0x2f:
+		// Intermediate bytes then a final byte, as in ESC ( B
+		j := 1
+		for j < len(rest) && rest[j] >= 0x20 && rest[j] <= 0x2f {
+			j++
+		}
+		if j < len(rest) && rest[j] >= 0x30 && rest[j] <= 0x7e {
+			j++
+		}
+		return j
+	case c >= 0x30 && c <= 0x7e:
+		return 1
+	}
+	return 0
+}
+
+// csiLen returns the length of a control sequence's parameter and
+// intermediate bytes and its final byte.
+func csiLen(s string) int {
+	j := 0
+	for j < len(s) && s[j] >= 0x20 && s[j] <= 0x3f {
+		j++
+	}
+	if j < len(s) && s[j] >= 0x40 && s[j] <= 0x7e {
+		j++
+	}
+	return j
+}
+
+// stringLen returns the length of a control string and its terminator:
+// BEL, ST or ESC \.
+func stringLen(s string) int {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == 0x07 || r == 0x9c:
+			return i + size
+		case r == 0x1b && i+1 < len(s) && s[i+1] == '\\':
+			return i + 2
+		}
+		i += size
+	}
+	return len(s)
+}
+
+// revealControls shows the control characters of an escape sequence as
+// visible symbols: C0 controls and DEL as their control pictures, such as
+// ␛ for ESC, and C1 controls as ␛ and the character of their 7-bit form.
+func revealControls(seq string) string {
+	var b strings.Builder
+	for _, r := range seq {
+		switch {
+		case r < 0x20:
+			b.WriteRune(0x2400 + r)
+		case r == 0x7f:
+			b.WriteRune('␡')
+		case r >= 0x80 && r <= 0x9f:
+			b.WriteRune('␛')
+			b.WriteRune(r - 0x40)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSanitizeString(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   string
+		reveal string
+		found  int
+	}{
+		{name: "plain text", in: "hi\tthere\n", want: "hi\tthere\n", reveal: "hi\tthere\n"},
+		{name: "color", in: "\x1b[31mred\x1b[0m", want: "red", reveal: "␛[31mred␛[0m", found: 2},
+		{name: "clear screen", in: "a\x1b[2Jb", want: "ab", reveal: "a␛[2Jb", found: 1},
+		{name: "title with BEL", in: "\x1b]0;pwned\x07ok", want: "ok", reveal: "␛]0;pwned␇ok", found: 1},
+		{name: "hyperlink with ST", in: "\x1b]8;;http://x\x1b\\link", want: "link", reveal: "␛]8;;http://x␛\\link", found: 1},
+		{name: "unterminated OSC", in: "ok\x1b]52;c;aGk=", want: "ok", reveal: "ok␛]52;c;aGk=", found: 1},
+		{name: "charset", in: "\x1b(Bx", want: "x", reveal: "␛(Bx", found: 1},
+		{name: "C1 CSI", in: "a\u009b2Jb", want: "ab", reveal: "a␛[2Jb", found: 1},
+		{name: "carriage return and backspace", in: "abc\rX\bY", want: "abcXY", reveal: "abc␍X␈Y", found: 2},
+		{name: "lone ESC at end", in: "a\x1b", want: "a", reveal: "a␛", found: 1},
+		{name: "unicode kept", in: "héllo ✓", want: "héllo ✓", reveal: "héllo ✓"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := 0
+			if got := sanitizeString(tt.in, false, &n); got != tt.want || n != tt.found {
+				t.Errorf("sanitizeString(%q) = %q, %d; want %q, %d", tt.in, got, n, tt.want, tt.found)
+			}
+			n = 0
+			if got := sanitizeString(tt.in, true, &n); got != tt.reveal {
+				t.Errorf("sanitizeString(%q, reveal) = %q, want %q", tt.in, got, tt.reveal)
+			}
+		})
+	}
+}
+
+func TestMessageSanitize(t *testing.T) {
+	line := `{"type":"table","agent_id":"w\u001b[1m1","payload":{"columns":["a","b"],"rows":[["\u001b[2J1","2"]],"total":12345678901234567}}`
+	msg, err := DecodeMessage([]byte(line), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := msg.Sanitize(false); n != 2 {
+		t.Errorf("Sanitize() = %d, want 2", n)
+	}
+	if msg.AgentID != "w1" {
+		t.Errorf("AgentID = %q, want w1", msg.AgentID)
+	}
+	var p struct {
+		Rows  [][]string `json:"rows"`
+		Total json.Number
+	}
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Rows[0][0] != "1" {
+		t.Errorf("cell = %q, want 1", p.Rows[0][0])
+	}
+	if p.Total != "12345678901234567" {
+		t.Errorf("total = %s, want it unchanged", p.Total)
+	}
+
+	// Payloads without controls are left as sent
+	clean := `{"type":"text","payload":{"content":"café"}}`
+	msg, _ = DecodeMessage([]byte(clean), false)
+	before := string(msg.Payload)
+	if n := msg.Sanitize(false); n != 0 || string(msg.Payload) != before {
+		t.Errorf("Sanitize() = %d, payload %s; want 0, %s", n, msg.Payload, before)
+	}
+}
@@ -0,0 +1,215 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaURI is the JSON Schema dialect of Schema.
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// payloads gives the payload of every message type, sent by the agent
+// ("agent"), the TUI ("tui") or either. A nil payload means the message
+// carries none.
+var payloads = map[MessageType]struct {
+	sender  string
+	payload any
+}{
+	// Python → Go
+	TypeText:            {"agent", TextPayload{}},
+	TypeMarkdown:        {"agent", MarkdownPayload{}},
+	TypeProgress:        {"agent", ProgressPayload{}},
+	TypeForm:            {"agent", FormPayload{}},
+	TypeTable:           {"agent", TablePayload{}},
+	TypeCode:            {"agent", CodePayload{}},
+	TypeConfirm:         {"agent", ConfirmPayload{}},
+	TypeSelect:          {"agent", SelectPayload{}},
+	TypeAlert:           {"agent", AlertPayload{}},
+	TypeSpinner:         {"agent", SpinnerPayload{}},
+	TypeStatus:          {"agent", StatusPayload{}},
+	TypeClear:           {"agent", ClearPayload{}},
+	TypeDone:            {"agent", DonePayload{}},
+	TypeUpdate:          {"agent", UpdatePayload{}},
+	TypeLayout:          {"agent", LayoutPayload{}},
+	TypeSecret:          {"agent", SecretPayload{}},
+	TypeAutocomplete:    {"agent", AutocompletePayload{}},
+	TypeCodePatch:       {"agent", CodePatchPayload{}},
+	TypeTableChunk:      {"agent", TableChunkPayload{}},
+	TypeCodeChunk:       {"agent", CodeChunkPayload{}},
+	TypeDiff:            {"agent", DiffPayload{}},
+	TypeTableUpdate:     {"agent", TableUpdatePayload{}},
+	TypeQRCode:          {"agent", QRCodePayload{}},
+	TypeMap:             {"agent", MapPayload{}},
+	TypeMath:            {"agent", MathPayload{}},
+	TypeHello:           {"", HelloPayload{}},
+	TypePong:            {"agent", PongPayload{}},
+	TypeScrollTo:        {"agent", ScrollToPayload{}},
+	TypeFocusInput:      {"agent", nil},
+	TypeCollapse:        {"agent", CollapsePayload{}},
+	TypeExpand:          {"agent", CollapsePayload{}},
+	TypeInputSuggestion: {"agent", InputSuggestionPayload{}},
+	TypeQuestion:        {"agent", QuestionPayload{}},
+	TypeBlob:            {"agent", BlobPayload{}},
+	TypeError:           {"agent", ErrorPayload{}},
+	TypeArtifact:        {"agent", ArtifactPayload{}},
+	TypeMetadata:        {"agent", MetadataPayload{}},
+	TypeRich:            {"agent", RichPayload{}},
+	TypeCommands:        {"agent", CommandsPayload{}},
+	TypeTool:            {"agent", ToolPayload{}},
+
+	// Go → Python
+	TypeInput:                {"tui", InputPayload{}},
+	TypeFormResponse:         {"tui", FormResponsePayload{}},
+	TypeConfirmResponse:      {"tui", ConfirmResponsePayload{}},
+	TypeSelectResponse:       {"tui", SelectResponsePayload{}},
+	TypeSecretResponse:       {"tui", SecretResponsePayload{}},
+	TypeAutocompleteResponse: {"tui", AutocompleteResponsePayload{}},
+	TypeCancel:               {"tui", nil},
+	TypeQuit:                 {"tui", nil},
+	TypeResize:               {"tui", ResizePayload{}},
+	TypeKeepAlive:            {"tui", KeepAlivePayload{}},
+	TypeAttachment:           {"tui", AttachmentPayload{}},
+	TypeUnsupported:          {"tui", UnsupportedPayload{}},
+	TypeDiffResponse:         {"tui", DiffResponsePayload{}},
+	TypePing:                 {"tui", PingPayload{}},
+	TypeProtocolError:        {"tui", ProtocolErrorPayload{}},
+	TypeTyping:               {"tui", TypingPayload{}},
+	TypeReadyForMore:         {"tui", ReadyForMorePayload{}},
+	TypeQuestionResponse:     {"tui", QuestionResponsePayload{}},
+	TypeRetry:                {"tui", RetryPayload{}},
+	TypeBudgetExceeded:       {"tui", BudgetExceededPayload{}},
+	TypeTick:                 {"tui", TickPayload{}},
+	TypeCommand:              {"tui", CommandPayload{}},
+}
+
+// Schema returns a JSON Schema describing every protocol message: the
+// envelope, with the payload of each message type. It is generated from
+// the Go types, so it cannot drift from what the TUI sends and accepts.
+// Each message definition is named after its type, e.g. "FormResponseMessage",
+// and records in "x-sender" whether the agent or the TUI sends it. Value
+// constraints checked by Validate methods are not included.
+func Schema() map[string]any {
+	defs := map[string]any{}
+	types := make([]string, 0, len(payloads))
+	for t := range payloads {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	var messages []any
+	for _, t := range types {
+		p := payloads[MessageType(t)]
+		name := camelCase(t) + "Message"
+
+		properties := structSchema(reflect.TypeOf(Message{}), defs)["properties"].(map[string]any)
+		properties["type"] = map[string]any{"const": t}
+		if p.payload == nil {
+			delete(properties, "payload")
+		} else {
+			properties["payload"] = reflectSchema(reflect.TypeOf(p.payload), defs)
+		}
+
+		def := map[string]any{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{"type"},
+		}
+		if p.sender != "" {
+			def["x-sender"] = p.sender
+		}
+		defs[name] = def
+		messages = append(messages, map[string]any{"$ref": "#/$defs/" + name})
+	}
+
+	return map[string]any{
+		"$schema":            SchemaURI,
+		"title":              "AgentUI protocol message",
+		"x-protocol-version": ProtocolVersion,
+		"oneOf":              messages,
+		"$defs":              defs,
+	}
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// reflectSchema returns the schema of values of type t as encoded by
+// encoding/json. Named structs are added to defs and referenced.
+func reflectSchema(t reflect.Type, defs map[string]any) any {
+	if t == rawMessageType {
+		return map[string]any{}
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return reflectSchema(t.Elem(), defs)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": reflectSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": reflectSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // stops recursion
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	}
+	return map[string]any{} // interfaces take any value
+}
+
+// structSchema lists the exported fields of t under their JSON names.
+// Fields without omitempty are required, since the TUI always sends them.
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = reflectSchema(f.Type, defs)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// camelCase turns a message type such as "form_response" into
+// "FormResponse".
+func camelCase(s string) string {
+	var sb strings.Builder
+	for _, word := range strings.Split(s, "_") {
+		if word != "" {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
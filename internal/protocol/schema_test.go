@@ -0,0 +1,67 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded struct {
+		OneOf []struct {
+			Ref string `json:"$ref"`
+		} `json:"oneOf"`
+		Defs map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+			Sender     string                    `json:"x-sender"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	// Every type the TUI renders is described, and every reference resolves
+	for _, typ := range RenderTypes {
+		if _, ok := payloads[typ]; !ok {
+			t.Errorf("no schema for %q", typ)
+		}
+	}
+	for _, ref := range decoded.OneOf {
+		if _, ok := decoded.Defs[ref.Ref[len("#/$defs/"):]]; !ok {
+			t.Errorf("dangling reference %s", ref.Ref)
+		}
+	}
+
+	form := decoded.Defs["FormResponseMessage"]
+	if form.Sender != "tui" || form.Properties["type"]["const"] != "form_response" {
+		t.Errorf("FormResponseMessage = %+v", form)
+	}
+	if ref := form.Properties["payload"]["$ref"]; ref != "#/$defs/FormResponsePayload" {
+		t.Errorf("form_response payload = %v", ref)
+	}
+	if _, ok := decoded.Defs["QuitMessage"].Properties["payload"]; ok {
+		t.Error("quit messages have no payload")
+	}
+
+	// Fields follow their JSON tags; omitempty ones are optional
+	progress := decoded.Defs["ProgressPayload"]
+	if !reflect.DeepEqual(progress.Required, []string{"message"}) {
+		t.Errorf("ProgressPayload required = %v, want [message]", progress.Required)
+	}
+	if progress.Properties["eta"]["type"] != "number" {
+		t.Errorf("ProgressPayload eta = %v, want a number", progress.Properties["eta"])
+	}
+	if progress.Properties["steps"]["items"].(map[string]any)["$ref"] != "#/$defs/ProgressStep" {
+		t.Errorf("ProgressPayload steps = %v", progress.Properties["steps"])
+	}
+	if !slices.Contains(decoded.Defs["UpdatePayload"].Required, "id") {
+		t.Error("UpdatePayload does not require an id")
+	}
+}
@@ -0,0 +1,265 @@
+package protocol
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpawnAddr is the address reported for an agent the TUI spawned.
+const SpawnAddr = "spawned agent"
+
+// stderrLines is how many of its last lines of standard error are kept
+// for when a spawned agent exits.
+const stderrLines = 10
+
+// spawnGrace is how long a spawned agent has to exit once its standard
+// input is closed before it is killed.
+const spawnGrace = 2 * time.Second
+
+// AgentExit reports how a spawned agent ended.
+type AgentExit struct {
+	Code   int           // its exit code, or -1 if it was killed or never started
+	Err    error         // why it could not be started, if it was not
+	Stderr []string      // its last lines of standard error
+	Ran    time.Duration // how long it ran
+}
+
+// Spawner runs the agent as a child process whose standard input and
+// output carry the protocol. It is a net.Listener whose Accept starts the
+// agent, straight away the first time and after that once Restart is
+// called, so a handler made with NewListenerHandler serves each run in
+// turn and the transcript is kept across restarts. Each run's end is
+// reported on Exits.
+type Spawner struct {
+	command []string
+	starts  chan struct{}
+	exits   chan AgentExit
+
+	mu      sync.Mutex
+	running *spawnConn
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewSpawner creates a spawner running command, a program and its
+// arguments.
+func NewSpawner(command []string) *Spawner {
+	s := &Spawner{
+		command: command,
+		starts:  make(chan struct{}, 1),
+		exits:   make(chan AgentExit, 1),
+		closed:  make(chan struct{}),
+	}
+	s.starts <- struct{}{}
+	return s
+}
+
+// Accept waits until the agent is to be started, starts it and returns
+// the connection to it. An agent that cannot be started is reported on
+// Exits, and Accept waits for Restart.
+func (s *Spawner) Accept() (net.Conn, error) {
+	for {
+		select {
+		case <-s.starts:
+		case <-s.closed:
+			return nil, net.ErrClosed
+		}
+		conn, err := s.start()
+		if err == nil {
+			return conn, nil
+		}
+		s.report(AgentExit{Code: -1, Err: err})
+	}
+}
+
+// Restart starts the agent again once the previous run has ended. Calls
+// while a start is already pending do nothing.
+func (s *Spawner) Restart() {
+	select {
+	case s.starts <- struct{}{}:
+	default:
+	}
+}
+
+// Exits returns the channel on which the end of each run is reported. It
+// is nil for a nil Spawner, so callers can select on it either way.
+func (s *Spawner) Exits() <-chan AgentExit {
+	if s == nil {
+		return nil
+	}
+	return s.exits
+}
+
+// Close stops starting the agent and stops the one running: its standard
+// input is closed and, if it has not exited after a grace period, it is
+// killed.
+func (s *Spawner) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	s.mu.Lock()
+	conn := s.running
+	s.mu.Unlock()
+	if conn != nil {
+		conn.stop(spawnGrace)
+	}
+	return nil
+}
+
+// Addr returns the address reported for the spawned agent.
+func (s *Spawner) Addr() net.Addr {
+	return spawnAddr{}
+}
+
+// start runs the agent with pipes for its standard input and output.
+func (s *Spawner) start() (*spawnConn, error) {
+	if len(s.command) == 0 {
+		return nil, errors.New("no agent command")
+	}
+	cmd := exec.Command(s.command[0], s.command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	// Wait would close a pipe made by StdoutPipe before the last messages
+	// are read, so the read end is kept here
+	stdout, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = w
+	stderr := &lineTail{max: stderrLines}
+	cmd.Stderr = stderr
+
+	started := time.Now()
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		stdout.Close()
+		return nil, err
+	}
+
+	conn := &spawnConn{stdin: stdin, stdout: stdout, cmd: cmd, exited: make(chan struct{})}
+	s.mu.Lock()
+	s.running = conn
+	s.mu.Unlock()
+
+	go func() {
+		err := cmd.Wait()
+		close(conn.exited)
+		s.mu.Lock()
+		if s.running == conn {
+			s.running = nil
+		}
+		s.mu.Unlock()
+
+		exit := AgentExit{Code: cmd.ProcessState.ExitCode(), Stderr: stderr.Lines(), Ran: time.Since(started)}
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			exit.Err = err
+		}
+		s.report(exit)
+	}()
+	return conn, nil
+}
+
+// report delivers exit unless the spawner has been closed.
+func (s *Spawner) report(exit AgentExit) {
+	select {
+	case s.exits <- exit:
+	case <-s.closed:
+	}
+}
+
+// spawnConn is the connection to a spawned agent: its standard output is
+// read and its standard input written.
+type spawnConn struct {
+	stdin  io.WriteCloser
+	stdout *os.File
+	cmd    *exec.Cmd
+	exited chan struct{}
+}
+
+// Read reads the agent's standard output. Once the run has been stopped
+// it ends like the agent closing it.
+func (c *spawnConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if errors.Is(err, os.ErrClosed) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (c *spawnConn) Write(p []byte) (int, error) { return c.stdin.Write(p) }
+
+// Close ends the run without waiting for the agent to exit, as when the
+// handler is done with it or DropAgent restarts an agent that stopped
+// responding.
+func (c *spawnConn) Close() error {
+	go c.stop(spawnGrace)
+	return nil
+}
+
+// stop closes the agent's standard input and kills it if it has not
+// exited within grace.
+func (c *spawnConn) stop(grace time.Duration) {
+	c.stdin.Close()
+	select {
+	case <-c.exited:
+	case <-time.After(grace):
+		c.cmd.Process.Kill()
+	}
+	c.stdout.Close()
+}
+
+func (c *spawnConn) LocalAddr() net.Addr                { return spawnAddr{} }
+func (c *spawnConn) RemoteAddr() net.Addr               { return spawnAddr{} }
+func (c *spawnConn) SetDeadline(t time.Time) error      { return c.stdout.SetDeadline(t) }
+func (c *spawnConn) SetReadDeadline(t time.Time) error  { return c.stdout.SetReadDeadline(t) }
+func (c *spawnConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// spawnAddr is the net.Addr of a spawned agent.
+type spawnAddr struct{}
+
+func (spawnAddr) Network() string { return "pipe" }
+func (spawnAddr) String() string  { return SpawnAddr }
+
+// lineTail keeps the last lines written to it.
+type lineTail struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial string
+}
+
+func (t *lineTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parts := strings.Split(t.partial+string(p), "\n")
+	t.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		t.lines = append(t.lines, strings.TrimRight(line, "\r"))
+	}
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+	return len(p), nil
+}
+
+// Lines returns the last lines written, including an unfinished one.
+func (t *lineTail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := append([]string(nil), t.lines...)
+	if t.partial != "" {
+		lines = append(lines, t.partial)
+	}
+	if len(lines) > t.max {
+		lines = lines[len(lines)-t.max:]
+	}
+	return lines
+}
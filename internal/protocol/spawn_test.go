@@ -0,0 +1,79 @@
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestSpawnHelper is the agent spawned by TestSpawner: it reads the TUI's
+// hello, sends a message and fails.
+func TestSpawnHelper(t *testing.T) {
+	if os.Getenv("AGENTUI_SPAWN_HELPER") != "1" {
+		t.Skip("run by TestSpawner")
+	}
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Println(`{"type":"markdown","payload":{"content":"hi"}}`)
+	fmt.Fprintln(os.Stderr, "starting")
+	fmt.Fprint(os.Stderr, "boom")
+	os.Exit(3)
+}
+
+func TestSpawner(t *testing.T) {
+	t.Setenv("AGENTUI_SPAWN_HELPER", "1")
+	spawner := NewSpawner([]string{os.Args[0], "-test.run=^TestSpawnHelper$"})
+	h := NewListenerHandler(spawner)
+	h.Start()
+	defer h.Stop()
+
+	for run := 1; run <= 2; run++ {
+		select {
+		case msg := <-h.Incoming():
+			if msg.Type != TypeMarkdown {
+				t.Fatalf("run %d: got a %s message", run, msg.Type)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("run %d: the agent sent nothing", run)
+		}
+
+		select {
+		case exit := <-spawner.Exits():
+			if exit.Code != 3 || exit.Err != nil {
+				t.Errorf("run %d: exited with %d, %v; want code 3", run, exit.Code, exit.Err)
+			}
+			if want := []string{"starting", "boom"}; !reflect.DeepEqual(exit.Stderr, want) {
+				t.Errorf("run %d: stderr %q, want %q", run, exit.Stderr, want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("run %d: the exit was not reported", run)
+		}
+		spawner.Restart()
+	}
+}
+
+func TestSpawnerReportsStartFailure(t *testing.T) {
+	spawner := NewSpawner([]string{"/nonexistent/agent"})
+	defer spawner.Close()
+	go spawner.Accept()
+
+	select {
+	case exit := <-spawner.Exits():
+		if exit.Err == nil || exit.Code != -1 {
+			t.Errorf("got %+v, want a start error", exit)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the failure was not reported")
+	}
+}
+
+func TestLineTail(t *testing.T) {
+	tail := &lineTail{max: 2}
+	fmt.Fprint(tail, "one\ntwo\r\nthr")
+	fmt.Fprint(tail, "ee\nfour")
+	if got, want := tail.Lines(), []string{"three", "four"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
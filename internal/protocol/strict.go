@@ -0,0 +1,124 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PayloadError is a message from the agent rejected in strict mode. The
+// handler reports it back to the agent as a protocol_error message and
+// delivers it on Errors instead of the message.
+type PayloadError struct {
+	Type   MessageType // empty if the message could not be read
+	ID     string
+	Fields []FieldError
+	Err    error
+}
+
+func (e *PayloadError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("invalid message: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid %s message: %v", e.Type, e.Err)
+}
+
+func (e *PayloadError) Unwrap() error { return e.Err }
+
+// CheckPayload parses the payload of a message decoded in strict mode as
+// its type's payload, returning a *PayloadError if it has unknown fields,
+// values of the wrong type or fails validation. Hellos, which are parsed
+// leniently, and types without a known payload are not checked.
+func CheckPayload(msg *Message) error {
+	p, ok := payloads[msg.Type]
+	if !ok || p.payload == nil || p.sender == "tui" || msg.Type == TypeHello {
+		return nil
+	}
+	v := reflect.New(reflect.TypeOf(p.payload)).Interface()
+	if err := msg.ParsePayload(v); err != nil {
+		return newPayloadError(msg.Type, msg.ID, err)
+	}
+	return nil
+}
+
+// rejectedLine returns the *PayloadError for a line that DecodeMessage
+// rejected, naming the message's type and ID if they can be read.
+func rejectedLine(line []byte, err error) *PayloadError {
+	var envelope struct {
+		Type MessageType `json:"type"`
+		ID   string      `json:"id"`
+	}
+	json.Unmarshal(line, &envelope) // best effort
+	return newPayloadError(envelope.Type, envelope.ID, err)
+}
+
+func newPayloadError(msgType MessageType, id string, err error) *PayloadError {
+	return &PayloadError{Type: msgType, ID: id, Fields: fieldErrors(err), Err: err}
+}
+
+// fieldErrors describes err field by field, as far as it can tell which
+// fields are at fault.
+func fieldErrors(err error) []FieldError {
+	var fieldErr *FieldError
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &fieldErr):
+		return []FieldError{*fieldErr}
+	case errors.As(err, &typeErr):
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Problem: fmt.Sprintf("want %s, got %s", jsonKind(typeErr.Type), typeErr.Value),
+		}}
+	case errors.As(err, &syntaxErr):
+		return []FieldError{{Problem: fmt.Sprintf("malformed JSON at offset %d: %v", syntaxErr.Offset, syntaxErr)}}
+	}
+	// encoding/json has no type for unknown fields
+	if quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if name, uerr := strconv.Unquote(quoted); uerr == nil {
+			return []FieldError{{Field: name, Problem: "unknown field"}}
+		}
+	}
+	return []FieldError{{Problem: err.Error()}}
+}
+
+// jsonKind names the JSON type that values of t are decoded from.
+func jsonKind(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonKind(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return "value"
+}
+
+// sendProtocolError tells the agent why its message was rejected.
+func (h *Handler) sendProtocolError(perr *PayloadError) error {
+	msg, err := NewMessageWithID(TypeProtocolError, perr.ID, ProtocolErrorPayload{
+		Type:   perr.Type,
+		Error:  perr.Err.Error(),
+		Fields: perr.Fields,
+	})
+	if err != nil {
+		return err
+	}
+	return h.SendSync(msg)
+}
@@ -0,0 +1,136 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckPayload(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []FieldError // nil if the message is valid
+	}{
+		{
+			name: "valid",
+			line: `{"type":"progress","payload":{"message":"x","percent":50}}`,
+		},
+		{
+			name: "unknown field",
+			line: `{"type":"markdown","payload":{"content":"hi","colour":"red"}}`,
+			want: []FieldError{{Field: "colour", Problem: "unknown field"}},
+		},
+		{
+			name: "wrong type",
+			line: `{"type":"progress","payload":{"message":"x","percent":"half"}}`,
+			want: []FieldError{{Field: "percent", Problem: "want number, got string"}},
+		},
+		{
+			name: "invalid nested field",
+			line: `{"type":"progress","payload":{"message":"x","steps":[{"label":"a","status":"done"}]}}`,
+			want: []FieldError{{Field: "steps[0].status", Problem: `unknown status "done"`}},
+		},
+		{
+			name: "invalid map point",
+			line: `{"type":"map","payload":{"points":[{"lat":95,"lon":0}]}}`,
+			want: []FieldError{{Field: "points[0].lat", Problem: "point 0: latitude 95 out of range"}},
+		},
+		{
+			name: "unknown type is left to the app",
+			line: `{"type":"sparkline","payload":{"values":[1,2]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := DecodeMessage([]byte(tt.line), true)
+			if err != nil {
+				t.Fatalf("DecodeMessage() error = %v", err)
+			}
+			err = CheckPayload(msg)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("CheckPayload() error = %v, want none", err)
+				}
+				return
+			}
+			var perr *PayloadError
+			if !errors.As(err, &perr) {
+				t.Fatalf("CheckPayload() error = %v, want a *PayloadError", err)
+			}
+			if perr.Type != msg.Type || !reflect.DeepEqual(perr.Fields, tt.want) {
+				t.Errorf("CheckPayload() = %s %+v, want %s %+v", perr.Type, perr.Fields, msg.Type, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictHandlerRepliesWithProtocolError(t *testing.T) {
+	stdin, agentOut := io.Pipe()
+	agentIn, stdout := io.Pipe()
+	h := NewHandler(stdin, stdout)
+	h.SetStrict(true)
+
+	replies := make(chan *Message, 2)
+	go func() {
+		r := bufio.NewReader(agentIn)
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var msg Message
+			if json.Unmarshal(line, &msg) == nil && msg.Type == TypeProtocolError {
+				replies <- &msg
+			}
+		}
+	}()
+	h.Start()
+	defer h.Stop()
+
+	io.WriteString(agentOut, `{"type":"alert","id":"a1","payload":{"message":"hi","severity":"loud"}}`+"\n")
+	io.WriteString(agentOut, `{"type":"alert","id":"a2","payload":{"message":"hi"}`+"\n")
+
+	for _, want := range []struct {
+		id     string
+		msg    MessageType
+		fields int
+	}{
+		{"a1", TypeAlert, 1},
+		{"", "", 1},
+	} {
+		select {
+		case reply := <-replies:
+			var payload ProtocolErrorPayload
+			if err := reply.ParsePayload(&payload); err != nil {
+				t.Fatal(err)
+			}
+			if reply.ID != want.id || payload.Type != want.msg || len(payload.Fields) != want.fields || payload.Error == "" {
+				t.Errorf("protocol_error %q %+v, want ID %q about %q", reply.ID, payload, want.id, want.msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("no protocol_error sent")
+		}
+
+		select {
+		case err := <-h.Errors():
+			var perr *PayloadError
+			if !errors.As(err, &perr) {
+				t.Errorf("Errors received %v, want a *PayloadError", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("rejection not reported")
+		}
+	}
+
+	select {
+	case msg := <-h.Incoming():
+		t.Errorf("rejected message delivered: %v", msg)
+	default:
+	}
+}
@@ -0,0 +1,85 @@
+package protocol
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Directions of a TrafficEntry.
+const (
+	TrafficIn  = "in"
+	TrafficOut = "out"
+)
+
+// redacted replaces secret values in a traffic log.
+const redacted = "[redacted]"
+
+// TrafficEntry is one line of a traffic log.
+type TrafficEntry struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"dir"`
+	Message   json.RawMessage `json:"message,omitempty"` // as sent, or as decoded if received
+	Raw       string          `json:"raw,omitempty"`     // a received line that could not be decoded
+	Error     string          `json:"error,omitempty"`   // why Raw could not be decoded
+}
+
+// TrafficLog writes every message a handler sends and receives, one JSON
+// TrafficEntry per line, so agent developers can see what the TUI was
+// sent and how it understood it. Received messages are logged after
+// decompression and migration, sent ones before compression. Messages
+// rejected in strict mode are followed by the protocol_error sent back.
+type TrafficLog struct {
+	mu     sync.Mutex
+	w      io.Writer
+	redact bool
+	now    func() time.Time
+}
+
+// NewTrafficLog creates a log writing to w. With redact set, the values
+// of secret responses are replaced with "[redacted]".
+func NewTrafficLog(w io.Writer, redact bool) *TrafficLog {
+	return &TrafficLog{w: w, redact: redact, now: time.Now}
+}
+
+// received logs a message read from the agent: msg if it was decoded, or
+// else the line itself and err, why it could not be.
+func (l *TrafficLog) received(line []byte, msg *Message, err error) {
+	entry := TrafficEntry{Direction: TrafficIn}
+	if msg != nil {
+		entry.Message, _ = json.Marshal(msg)
+	} else {
+		entry.Raw = string(line)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	l.write(entry)
+}
+
+// sent logs a message written to the agent.
+func (l *TrafficLog) sent(msg *Message) {
+	if l.redact && msg.Type == TypeSecretResponse {
+		var payload SecretResponsePayload
+		if json.Unmarshal(msg.Payload, &payload) == nil && payload.Value != "" {
+			payload.Value = redacted
+		}
+		copied := *msg
+		copied.Payload, _ = json.Marshal(payload)
+		msg = &copied
+	}
+	data, _ := json.Marshal(msg)
+	l.write(TrafficEntry{Direction: TrafficOut, Message: data})
+}
+
+func (l *TrafficLog) write(entry TrafficEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.Time = l.now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.w.Write(append(data, '\n'))
+}
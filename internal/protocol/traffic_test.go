@@ -0,0 +1,86 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for the handler's goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) entries(t *testing.T) []TrafficEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var entries []TrafficEntry
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var e TrafficEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestTrafficLog(t *testing.T) {
+	for _, redact := range []bool{true, false} {
+		stdin, agentOut := io.Pipe()
+		agentIn, stdout := io.Pipe()
+		go io.Copy(io.Discard, bufio.NewReader(agentIn))
+
+		var log syncBuffer
+		h := NewHandler(stdin, stdout)
+		h.SetTrafficLog(NewTrafficLog(&log, redact))
+		h.Start()
+
+		io.WriteString(agentOut, `{"type":"text","payload":{"content":"hi"}}`+"\n")
+		io.WriteString(agentOut, `not json`+"\n")
+		<-h.Incoming()
+		<-h.Errors()
+		secret, _ := NewMessageWithID(TypeSecretResponse, "s1", SecretResponsePayload{Value: "hunter2"})
+		if err := h.SendSync(secret); err != nil {
+			t.Fatal(err)
+		}
+		h.Stop()
+
+		entries := log.entries(t)
+		var in, bad, out *TrafficEntry
+		for i, e := range entries {
+			switch {
+			case e.Direction == TrafficIn && e.Raw != "":
+				bad = &entries[i]
+			case e.Direction == TrafficIn:
+				in = &entries[i]
+			case e.Direction == TrafficOut && strings.Contains(string(e.Message), "secret_response"):
+				out = &entries[i]
+			}
+		}
+		if in == nil || !strings.Contains(string(in.Message), `"content":"hi"`) || time.Since(in.Time) > time.Minute {
+			t.Errorf("received message logged as %+v", in)
+		}
+		if bad == nil || bad.Raw != "not json" || bad.Error == "" {
+			t.Errorf("undecodable line logged as %+v", bad)
+		}
+		if out == nil {
+			t.Fatalf("sent message not logged in %+v", entries)
+		}
+		if leaked := strings.Contains(string(out.Message), "hunter2"); leaked == redact {
+			t.Errorf("redact=%v: secret response logged as %s", redact, out.Message)
+		}
+	}
+}
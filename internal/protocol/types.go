@@ -3,6 +3,7 @@ package protocol
 
 import (
 	"encoding/json"
+	"time"
 )
 
 // MessageType identifies the type of message.
@@ -10,32 +11,85 @@ type MessageType string
 
 // Message types from Python → Go (render commands)
 const (
-	TypeText     MessageType = "text"
-	TypeMarkdown MessageType = "markdown"
-	TypeProgress MessageType = "progress"
-	TypeForm     MessageType = "form"
-	TypeTable    MessageType = "table"
-	TypeCode     MessageType = "code"
-	TypeConfirm  MessageType = "confirm"
-	TypeSelect   MessageType = "select"
-	TypeAlert    MessageType = "alert"
-	TypeSpinner  MessageType = "spinner"
-	TypeStatus   MessageType = "status"
-	TypeClear    MessageType = "clear"
-	TypeDone     MessageType = "done"
-	TypeUpdate   MessageType = "update" // Phase 3: Progressive streaming
-	TypeLayout   MessageType = "layout" // Phase 5: Multi-component layouts
+	TypeText            MessageType = "text"
+	TypeMarkdown        MessageType = "markdown"
+	TypeProgress        MessageType = "progress"
+	TypeForm            MessageType = "form"
+	TypeTable           MessageType = "table"
+	TypeCode            MessageType = "code"
+	TypeConfirm         MessageType = "confirm"
+	TypeSelect          MessageType = "select"
+	TypeAlert           MessageType = "alert"
+	TypeSpinner         MessageType = "spinner"
+	TypeStatus          MessageType = "status"
+	TypeClear           MessageType = "clear"
+	TypeDone            MessageType = "done"
+	TypeUpdate          MessageType = "update" // Phase 3: Progressive streaming
+	TypeLayout          MessageType = "layout" // Phase 5: Multi-component layouts
+	TypeSecret          MessageType = "secret"
+	TypeAutocomplete    MessageType = "autocomplete"
+	TypeCodePatch       MessageType = "code_patch"
+	TypeTableChunk      MessageType = "table_chunk"
+	TypeCodeChunk       MessageType = "code_chunk"
+	TypeDiff            MessageType = "diff"
+	TypeTableUpdate     MessageType = "table_update"
+	TypeQRCode          MessageType = "qrcode"
+	TypeMap             MessageType = "map"
+	TypeMath            MessageType = "math"
+	TypeHello           MessageType = "hello" // sent both ways; see compression.go
+	TypePong            MessageType = "pong"
+	TypeScrollTo        MessageType = "scroll_to"
+	TypeFocusInput      MessageType = "focus_input"
+	TypeCollapse        MessageType = "collapse"
+	TypeExpand          MessageType = "expand"
+	TypeInputSuggestion MessageType = "input_suggestion"
+	TypeQuestion        MessageType = "question"
+	TypeBlob            MessageType = "blob"
+	TypeError           MessageType = "error"
+	TypeArtifact        MessageType = "artifact"
+	TypeMetadata        MessageType = "metadata"
+	TypeRich            MessageType = "rich"
+	TypeCommands        MessageType = "commands"
+	TypeTool            MessageType = "tool"
 )
 
+// RenderTypes lists the Python → Go message types this TUI understands.
+// It is reported back to the agent when an unknown type arrives.
+var RenderTypes = []MessageType{
+	TypeText, TypeMarkdown, TypeProgress, TypeForm, TypeTable, TypeCode,
+	TypeConfirm, TypeSelect, TypeAlert, TypeSpinner, TypeStatus, TypeClear,
+	TypeDone, TypeUpdate, TypeLayout, TypeSecret, TypeAutocomplete,
+	TypeCodePatch, TypeTableChunk, TypeCodeChunk, TypeDiff, TypeTableUpdate,
+	TypeQRCode, TypeMap, TypeMath, TypeHello, TypePong, TypeScrollTo,
+	TypeFocusInput, TypeCollapse, TypeExpand, TypeInputSuggestion,
+	TypeQuestion, TypeBlob, TypeError, TypeArtifact, TypeMetadata,
+	TypeRich, TypeCommands, TypeTool,
+}
+
 // Message types from Go → Python (user events)
 const (
-	TypeInput           MessageType = "input"
-	TypeFormResponse    MessageType = "form_response"
-	TypeConfirmResponse MessageType = "confirm_response"
-	TypeSelectResponse  MessageType = "select_response"
-	TypeCancel          MessageType = "cancel"
-	TypeQuit            MessageType = "quit"
-	TypeResize          MessageType = "resize"
+	TypeInput                MessageType = "input"
+	TypeFormResponse         MessageType = "form_response"
+	TypeConfirmResponse      MessageType = "confirm_response"
+	TypeSelectResponse       MessageType = "select_response"
+	TypeSecretResponse       MessageType = "secret_response"
+	TypeAutocompleteResponse MessageType = "autocomplete_response"
+	TypeCancel               MessageType = "cancel"
+	TypeQuit                 MessageType = "quit"
+	TypeResize               MessageType = "resize"
+	TypeKeepAlive            MessageType = "keepalive"
+	TypeAttachment           MessageType = "attachment"
+	TypeUnsupported          MessageType = "unsupported"
+	TypeDiffResponse         MessageType = "diff_response"
+	TypePing                 MessageType = "ping"
+	TypeProtocolError        MessageType = "protocol_error"
+	TypeTyping               MessageType = "typing"
+	TypeReadyForMore         MessageType = "ready_for_more"
+	TypeQuestionResponse     MessageType = "question_response"
+	TypeRetry                MessageType = "retry"
+	TypeBudgetExceeded       MessageType = "budget_exceeded"
+	TypeTick                 MessageType = "tick"
+	TypeCommand              MessageType = "command"
 )
 
 // Message is the base message structure for all protocol communication.
@@ -43,6 +97,38 @@ type Message struct {
 	Type    MessageType     `json:"type"`
 	ID      string          `json:"id,omitempty"`
 	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Compression names the encoding of a compressed payload (e.g. "gzip").
+	// Compressed payloads are base64 strings; see Compress and Decompress.
+	Compression string `json:"compression,omitempty"`
+
+	// Version is the payload format version; see Migrate.
+	Version int `json:"version,omitempty"`
+
+	// Timeout is how many seconds the sender of a request waits for the
+	// response. Past it the request can be dropped, as nobody will read
+	// the answer. Zero means the sender waits indefinitely.
+	Timeout float64 `json:"timeout,omitempty"`
+
+	// AgentID names the logical agent a message comes from or goes to when
+	// several share one connection, e.g. an orchestrator and its workers.
+	// Empty means the agent, as with a single one; see multiplex.go.
+	AgentID string `json:"agent_id,omitempty"`
+
+	// Region names the dashboard region a message draws into, one of the
+	// regions of a layout message's rows. Messages for a region no
+	// dashboard has go to the transcript as usual.
+	Region string `json:"region,omitempty"`
+
+	// Pinned keeps the messages this one adds to the transcript in the
+	// strip at the top of it, so they do not scroll away.
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Batch holds the messages of a TypeBatch message, in the order sent.
+	Batch []*Message `json:"-"`
+
+	// strict rejects unknown payload fields and invalid payloads
+	strict bool
 }
 
 // --- Payload types from Python → Go ---
@@ -57,6 +143,10 @@ type TextPayload struct {
 type MarkdownPayload struct {
 	Content string `json:"content"`
 	Title   string `json:"title,omitempty"`
+	// Append adds Content to the open markdown message with the same
+	// message ID instead of starting a new one; Done closes it.
+	Append bool `json:"append,omitempty"`
+	Done   bool `json:"done,omitempty"`
 }
 
 // ProgressStep represents a step in a multi-step progress.
@@ -71,6 +161,11 @@ type ProgressPayload struct {
 	Message string         `json:"message"`
 	Percent *float64       `json:"percent,omitempty"`
 	Steps   []ProgressStep `json:"steps,omitempty"`
+	// ID names one of several concurrent progress bars; Done removes it.
+	ID   string `json:"id,omitempty"`
+	Done bool   `json:"done,omitempty"`
+	// ETA is the estimated number of seconds remaining.
+	ETA *float64 `json:"eta,omitempty"`
 }
 
 // FormField defines a single form field.
@@ -92,6 +187,10 @@ type FormPayload struct {
 	Fields      []FormField `json:"fields"`
 	SubmitLabel string      `json:"submit_label,omitempty"`
 	CancelLabel string      `json:"cancel_label,omitempty"`
+
+	// TimeoutSeconds, if set, submits the fields' defaults once the form
+	// has gone that long untouched
+	TimeoutSeconds float64 `json:"timeout_seconds,omitempty"`
 }
 
 // TablePayload displays a data table.
@@ -110,6 +209,61 @@ type CodePayload struct {
 	LineNumbers bool   `json:"line_numbers,omitempty"`
 }
 
+// Code patch operations.
+const (
+	CodePatchAppend  = "append"
+	CodePatchReplace = "replace"
+)
+
+// CodePatchPayload edits a code block previously sent with the given
+// message ID. "append" adds Lines to the end; "replace" swaps lines
+// Start through End (1-based, inclusive) for Lines. End may be Start-1 to
+// insert without removing anything, and empty Lines deletes the range.
+type CodePatchPayload struct {
+	ID    string   `json:"id"`
+	Op    string   `json:"op"`
+	Start int      `json:"start,omitempty"`
+	End   int      `json:"end,omitempty"`
+	Lines []string `json:"lines,omitempty"`
+}
+
+// TableChunkPayload streams more rows into a table previously sent with the
+// given message ID. Total is the expected final row count, if known.
+type TableChunkPayload struct {
+	ID    string     `json:"id"`
+	Rows  [][]string `json:"rows,omitempty"`
+	Total int        `json:"total,omitempty"`
+	Done  bool       `json:"done,omitempty"`
+}
+
+// Table update operations.
+const (
+	TableUpdateAppend  = "append"
+	TableUpdateReplace = "replace"
+)
+
+// TableUpdatePayload changes the rows of a table previously sent with the
+// given message ID. "append" (the default) adds Rows to the end. "replace"
+// swaps all rows for Rows, or when Start is set, overwrites rows from that
+// zero-based index onwards. A non-empty Footer replaces the footer.
+type TableUpdatePayload struct {
+	ID     string     `json:"id"`
+	Op     string     `json:"op,omitempty"`
+	Start  *int       `json:"start,omitempty"`
+	Rows   [][]string `json:"rows,omitempty"`
+	Footer string     `json:"footer,omitempty"`
+}
+
+// CodeChunkPayload streams more text onto the end of a code block previously
+// sent with the given message ID. Total is the expected final size in bytes,
+// if known.
+type CodeChunkPayload struct {
+	ID    string `json:"id"`
+	Code  string `json:"code,omitempty"`
+	Total int    `json:"total,omitempty"`
+	Done  bool   `json:"done,omitempty"`
+}
+
 // ConfirmPayload requests yes/no confirmation.
 type ConfirmPayload struct {
 	Message      string `json:"message"`
@@ -117,13 +271,77 @@ type ConfirmPayload struct {
 	ConfirmLabel string `json:"confirm_label,omitempty"`
 	CancelLabel  string `json:"cancel_label,omitempty"`
 	Destructive  bool   `json:"destructive,omitempty"`
+
+	// TimeoutSeconds, if set, answers Default once the dialog has gone
+	// that long untouched
+	TimeoutSeconds float64 `json:"timeout_seconds,omitempty"`
+	Default        bool    `json:"default,omitempty"`
 }
 
 // SelectPayload requests selection from options.
 type SelectPayload struct {
-	Label   string   `json:"label"`
-	Options []string `json:"options"`
-	Default string   `json:"default,omitempty"`
+	Label   string         `json:"label"`
+	Options []SelectOption `json:"options"`
+	Default string         `json:"default,omitempty"`
+
+	// TimeoutSeconds, if set, selects Default, or else the first option,
+	// once the menu has gone that long untouched
+	TimeoutSeconds float64 `json:"timeout_seconds,omitempty"`
+}
+
+// SelectOption is one choice in a select menu. Value is what gets sent back
+// and defaults to Label.
+type SelectOption struct {
+	Label       string `json:"label"`
+	Value       string `json:"value,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// OptionValue returns the value sent back when the option is chosen.
+func (o SelectOption) OptionValue() string {
+	if o.Value != "" {
+		return o.Value
+	}
+	return o.Label
+}
+
+// OptionsFromStrings builds select options whose labels are their values.
+func OptionsFromStrings(labels []string) []SelectOption {
+	options := make([]SelectOption, len(labels))
+	for i, label := range labels {
+		options[i] = SelectOption{Label: label, Value: label}
+	}
+	return options
+}
+
+// SecretPayload requests a single masked value (API key, password).
+// The value is never echoed on screen or stored in the transcript.
+type SecretPayload struct {
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+}
+
+// QuestionPayload asks a clarifying question with suggested answers,
+// plus a last row where the user types their own. Other labels that row
+// and defaults to "Other". Default is the value of the option selected at
+// first.
+type QuestionPayload struct {
+	Question    string         `json:"question"`
+	Options     []SelectOption `json:"options,omitempty"`
+	Other       string         `json:"other,omitempty"`
+	Placeholder string         `json:"placeholder,omitempty"`
+	Default     string         `json:"default,omitempty"`
+}
+
+// AutocompletePayload requests a value from a text prompt with a filtered
+// suggestion dropdown. More suggestions can be streamed with TypeUpdate
+// messages carrying the request ID and a "suggestions" field.
+type AutocompletePayload struct {
+	Label       string   `json:"label"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	Default     string   `json:"default,omitempty"`
 }
 
 // AlertPayload shows a notification.
@@ -133,6 +351,39 @@ type AlertPayload struct {
 	Severity string `json:"severity,omitempty"`
 }
 
+// QRCodePayload shows a URL or text as a scannable QR code.
+type QRCodePayload struct {
+	Data    string `json:"data"`
+	Title   string `json:"title,omitempty"`
+	Caption string `json:"caption,omitempty"`
+	Level   string `json:"level,omitempty"` // "L", "M" (default), "Q" or "H"
+}
+
+// MapPoint is a labelled location on a map.
+type MapPoint struct {
+	Label string  `json:"label,omitempty"`
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+}
+
+// MapPayload plots points on an ASCII world map. Region names an area to
+// show ("world" by default); each Zoom level halves the span around Center,
+// or around the points when Center is omitted.
+type MapPayload struct {
+	Title  string     `json:"title,omitempty"`
+	Points []MapPoint `json:"points"`
+	Region string     `json:"region,omitempty"`
+	Zoom   int        `json:"zoom,omitempty"`
+	Center *MapPoint  `json:"center,omitempty"`
+}
+
+// MathPayload shows a LaTeX expression as display math, approximated with
+// Unicode.
+type MathPayload struct {
+	Tex   string `json:"tex"`
+	Title string `json:"title,omitempty"`
+}
+
 // SpinnerPayload shows a loading spinner.
 type SpinnerPayload struct {
 	Message string `json:"message"`
@@ -150,9 +401,92 @@ type TokenInfo struct {
 	Output int `json:"output"`
 }
 
+// Clear scopes. The TUI can put back what a scope other than progress
+// removed from the transcript.
+const (
+	ClearChat     = "chat"      // the whole transcript
+	ClearProgress = "progress"  // progress bars
+	ClearAll      = "all"       // both
+	ClearLastTurn = "last_turn" // everything after the user's latest input
+	ClearSystem   = "system"    // notes from the TUI, such as reports and errors
+	ClearByID     = "by_id"     // the message with ID Target
+	ClearBeforeID = "before_id" // everything before the message with ID Target
+)
+
 // ClearPayload clears part of the UI.
 type ClearPayload struct {
 	Scope string `json:"scope"`
+	// Target is the message ID the by_id and before_id scopes need
+	Target string `json:"target,omitempty"`
+}
+
+// Scroll targets besides message IDs.
+const (
+	ScrollTop    = "top"
+	ScrollBottom = "bottom"
+)
+
+// ScrollToPayload scrolls the transcript to the message with ID Target,
+// or to its top or bottom. Like the other control messages it is ignored
+// unless the user allows agents to move the view.
+type ScrollToPayload struct {
+	Target string `json:"target"`
+}
+
+// CollapsePayload collapses the message with ID Target to one line, or
+// expands it again. Summary, if given, replaces the collapsed message's
+// first line.
+type CollapsePayload struct {
+	Target  string `json:"target"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// InputSuggestionPayload suggests how to complete the user's draft, shown
+// as ghost text after the cursor. Draft is the draft it completes; if the
+// user has typed on since, the suggestion is kept only while what they
+// typed matches its start. An empty Completion withdraws the suggestion.
+type InputSuggestionPayload struct {
+	Draft      string `json:"draft"`
+	Completion string `json:"completion"`
+}
+
+// ErrorPayload reports that the agent failed, ending its turn. Code is a
+// short machine-readable name for the failure, such as "rate_limited".
+// If Retryable is set, the user can ask for the failed work to be tried
+// again, which sends a retry event.
+type ErrorPayload struct {
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message"`
+	Details   string `json:"details,omitempty"`
+	Retryable bool   `json:"retryable,omitempty"`
+}
+
+// ArtifactPayload puts a key output in the artifacts panel beside the
+// chat, where it stays as the chat scrolls on. It holds one of Markdown,
+// Code or Table. An artifact sent with the ID of an earlier one replaces
+// it.
+type ArtifactPayload struct {
+	Title    string        `json:"title,omitempty"`
+	Markdown string        `json:"markdown,omitempty"`
+	Code     *CodePayload  `json:"code,omitempty"`
+	Table    *TablePayload `json:"table,omitempty"`
+}
+
+// MetadataPayload reports what a step of the agent's work used, such as
+// one model call: its tokens and its cost in dollars. The TUI adds them up
+// over the session and checks them against the session budget.
+type MetadataPayload struct {
+	Tokens *TokenInfo `json:"tokens,omitempty"`
+	Cost   float64    `json:"cost,omitempty"`
+}
+
+// RichPayload carries one output in several representations keyed by MIME
+// type, as in a Jupyter MIME bundle, for the TUI to show the best it can
+// render; see RichMimeTypes. A value is a string, base64 for images, a
+// list of strings joined as lines are in notebooks, or any JSON value for
+// application/json.
+type RichPayload struct {
+	Data map[string]any `json:"data"`
 }
 
 // DonePayload indicates agent completion.
@@ -201,11 +535,61 @@ type LayoutComponent struct {
 	Height  *int           `json:"height,omitempty"` // Height hint
 }
 
+// LayoutRegion is a named cell of a dashboard, filled by the messages
+// whose region is its ID.
+type LayoutRegion struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	// Span is the region's share of its row's width; zero counts as 1.
+	Span int `json:"span,omitempty"`
+	// Height is the most lines the region shows, keeping the latest; zero
+	// shows them all.
+	Height int `json:"height,omitempty"`
+}
+
 // LayoutPayload displays multiple components in a dashboard-style layout (Phase 5).
 type LayoutPayload struct {
 	Title       string            `json:"title,omitempty"`
 	Description string            `json:"description,omitempty"`
 	Components  []LayoutComponent `json:"components"`
+
+	// Rows makes the layout a live dashboard: a grid of regions, top to
+	// bottom, that later messages draw into by naming a region.
+	Rows [][]LayoutRegion `json:"rows,omitempty"`
+}
+
+// AgentCommand is a slash command the agent handles, offered to the user
+// as they type / in the input. Args hints at its arguments, e.g.
+// "<env> [--force]".
+type AgentCommand struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Args        string `json:"args,omitempty"`
+}
+
+// CommandsPayload registers the agent's slash commands, replacing those it
+// registered before. Commands named like the TUI's own are ignored.
+type CommandsPayload struct {
+	Commands []AgentCommand `json:"commands"`
+}
+
+// Statuses of a tool call.
+const (
+	ToolRunning = "running"
+	ToolDone    = "done"
+	ToolFailed  = "failed"
+)
+
+// ToolPayload reports a tool call the agent started or finished, shown
+// among the activity in the sidebar. Later messages with the same ID
+// update the call.
+type ToolPayload struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Detail is a short description, such as the call's arguments or what
+	// it returned
+	Detail string `json:"detail,omitempty"`
 }
 
 // --- Payload types from Go → Python ---
@@ -215,19 +599,45 @@ type InputPayload struct {
 	Content string `json:"content"`
 }
 
-// FormResponsePayload returns form values.
+// FormResponsePayload returns form values. TimedOut is set when the
+// defaults were submitted because the user did not answer in time.
 type FormResponsePayload struct {
-	Values map[string]any `json:"values"`
+	Values   map[string]any `json:"values"`
+	TimedOut bool           `json:"timed_out,omitempty"`
 }
 
-// ConfirmResponsePayload returns confirmation result.
+// ConfirmResponsePayload returns confirmation result, with TimedOut set
+// when it is the default answer.
 type ConfirmResponsePayload struct {
 	Confirmed bool `json:"confirmed"`
+	TimedOut  bool `json:"timed_out,omitempty"`
 }
 
-// SelectResponsePayload returns selection result.
+// SelectResponsePayload returns selection result, with TimedOut set when
+// it is the default answer.
 type SelectResponsePayload struct {
-	Value string `json:"value"`
+	Value    string `json:"value"`
+	TimedOut bool   `json:"timed_out,omitempty"`
+}
+
+// QuestionResponsePayload returns the chosen option's value, or the
+// user's own answer with Custom set.
+type QuestionResponsePayload struct {
+	Value     string `json:"value"`
+	Custom    bool   `json:"custom,omitempty"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+}
+
+// SecretResponsePayload returns the entered secret.
+type SecretResponsePayload struct {
+	Value     string `json:"value"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+}
+
+// AutocompleteResponsePayload returns the chosen or typed value.
+type AutocompleteResponsePayload struct {
+	Value     string `json:"value"`
+	Cancelled bool   `json:"cancelled,omitempty"`
 }
 
 // ResizePayload notifies of terminal resize.
@@ -236,6 +646,205 @@ type ResizePayload struct {
 	Height int `json:"height"`
 }
 
+// DiffHunk is one "@@" section of a unified diff.
+type DiffHunk struct {
+	Header string   `json:"header"`
+	Lines  []string `json:"lines"`
+}
+
+// DiffPayload asks the user to review a diff hunk by hunk. Either Hunks or a
+// unified Diff string may be given.
+type DiffPayload struct {
+	Title string     `json:"title,omitempty"`
+	Path  string     `json:"path,omitempty"`
+	Diff  string     `json:"diff,omitempty"`
+	Hunks []DiffHunk `json:"hunks,omitempty"`
+}
+
+// DiffResponsePayload lists the zero-based indices of accepted hunks.
+type DiffResponsePayload struct {
+	Accepted  []int `json:"accepted"`
+	Cancelled bool  `json:"cancelled,omitempty"`
+}
+
+// HelloPayload advertises what a side of the connection supports. The TUI
+// sends one as soon as an agent is connected; the agent may send its own.
+// Fields a side does not know are ignored, so either can add more.
+type HelloPayload struct {
+	// Compression lists the payload compressions the sender can decode.
+	Compression []string `json:"compression"`
+
+	// Sent by the TUI: its protocol version, the message types it renders
+	// and what its terminal can display.
+	ProtocolVersion int           `json:"protocol_version,omitempty"`
+	Types           []MessageType `json:"types,omitempty"`
+	Terminal        *TerminalInfo `json:"terminal,omitempty"`
+
+	// Batch is sent by TUIs that accept a JSON array of messages on one
+	// line, applied together; see TypeBatch.
+	Batch bool `json:"batch,omitempty"`
+
+	// Blobs lists the ways the TUI accepts blob messages: BlobBase64
+	// chunks, and BlobFile handoffs of files written to BlobDir when it
+	// shares a filesystem with the agent.
+	Blobs   []string `json:"blobs,omitempty"`
+	BlobDir string   `json:"blob_dir,omitempty"`
+
+	// Rich lists the MIME types the TUI renders in rich messages, best
+	// first, so agents can leave out representations it would not use.
+	Rich []string `json:"rich,omitempty"`
+
+	// Telemetry false turns the TUI's opt-in usage statistics off for the
+	// session, for agents whose users have not agreed to them.
+	Telemetry *bool `json:"telemetry,omitempty"`
+
+	// Agent is sent by agents to identify themselves in the TUI's session
+	// bundles and crash reports.
+	Agent *AgentInfo `json:"agent,omitempty"`
+}
+
+// AgentInfo identifies an agent and the SDK it is built with.
+type AgentInfo struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	SDK     string `json:"sdk,omitempty"`
+}
+
+// TerminalInfo describes what the TUI's terminal can display, so agents can
+// choose content it will show well.
+type TerminalInfo struct {
+	TrueColor  bool `json:"truecolor"`
+	Images     bool `json:"images"`
+	Hyperlinks bool `json:"hyperlinks"`
+}
+
+// UnsupportedPayload tells the agent that a message type it sent is not
+// understood by this TUI, along with the types that are.
+type UnsupportedPayload struct {
+	Type      MessageType   `json:"type"`
+	Supported []MessageType `json:"supported"`
+}
+
+// ProtocolErrorPayload tells the agent that a message it sent was rejected
+// in strict mode, with the fields at fault where they are known. The
+// rejected message's ID, if it had one, is echoed in the envelope.
+type ProtocolErrorPayload struct {
+	Type   MessageType  `json:"type,omitempty"` // empty if the message could not be read
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// AttachmentPayload carries a file sent by the user. Large files are split
+// across several messages sharing the same ID; Chunk is zero-based and
+// Chunks is the total count. Data is base64-encoded.
+type AttachmentPayload struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Encoding string `json:"encoding"`
+	Data     string `json:"data"`
+	Chunk    int    `json:"chunk"`
+	Chunks   int    `json:"chunks"`
+}
+
+// BlobPayload carries binary content from the agent, such as an image or
+// a generated file, too large for one message. With base64 encoding it is
+// split across messages sharing the same ID like an attachment, Chunk
+// being zero-based and Chunks the total count. With Path set instead, the
+// agent hands off a file it wrote to the BlobDir from the TUI's hello,
+// which the TUI then owns.
+type BlobPayload struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Data     string `json:"data,omitempty"`
+	Chunk    int    `json:"chunk,omitempty"`
+	Chunks   int    `json:"chunks,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+// KeepAlivePayload is sent periodically while the user composes a prompt
+// so idle-timeout transports keep the connection open.
+type KeepAlivePayload struct {
+	Composing bool `json:"composing"`
+}
+
+// Typing states.
+const (
+	TypingStart = "start"
+	TypingStop  = "stop"
+)
+
+// TypingPayload tells the agent the user started composing a prompt, or
+// stopped: they sent it, cleared it or paused typing. When they paused,
+// Draft holds the prompt so far, which the agent may complete with an
+// input_suggestion.
+type TypingPayload struct {
+	State string `json:"state"`
+	Draft string `json:"draft,omitempty"`
+}
+
+// RetryPayload asks the agent to try again the work that failed with a
+// retryable error. ID is the error message's ID, if it had one.
+//
+// When the user retries the last exchange instead, after a failure or an
+// answer they did not like, Input is their last input, for the agent to
+// answer anew in place of its earlier answer. Replaced says the earlier
+// answer was removed from the transcript.
+type RetryPayload struct {
+	ID       string `json:"id,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Input    string `json:"input,omitempty"`
+	Replaced bool   `json:"replaced,omitempty"`
+}
+
+// BudgetExceededPayload tells the agent the session has used more than its
+// budget: its cost in dollars and tokens so far, and the budgets set.
+type BudgetExceededPayload struct {
+	Cost        float64 `json:"cost"`
+	Tokens      int     `json:"tokens"`
+	Budget      float64 `json:"budget,omitempty"`
+	TokenBudget int     `json:"token_budget,omitempty"`
+}
+
+// CommandPayload is sent when the user runs one of the agent's slash
+// commands: Name without the slash, and Args, the rest of the line.
+type CommandPayload struct {
+	Name string `json:"name"`
+	Args string `json:"args,omitempty"`
+}
+
+// TickPayload is sent when a schedule without a prompt fires: Schedule is
+// when it fires, as the user wrote it, and At the time it fired.
+type TickPayload struct {
+	Schedule string    `json:"schedule"`
+	At       time.Time `json:"at"`
+}
+
+// ReadyForMorePayload grants the agent Credits more messages under flow
+// control. Each line the agent sends, other than hellos and pongs, uses
+// one; once they run out it waits for the next grant. Window is the most
+// messages the TUI lets wait to be shown.
+type ReadyForMorePayload struct {
+	Credits int `json:"credits"`
+	Window  int `json:"window"`
+}
+
+// PingPayload asks the agent to prove it is still responsive. The agent
+// answers with a pong carrying the same message ID and sequence number.
+type PingPayload struct {
+	Seq int `json:"seq"`
+}
+
+// PongPayload answers a ping.
+type PongPayload struct {
+	Seq int `json:"seq"`
+}
+
 // NewMessage creates a new message with the given type and payload.
 func NewMessage(msgType MessageType, payload any) (*Message, error) {
 	payloadBytes, err := json.Marshal(payload)
@@ -245,6 +854,7 @@ func NewMessage(msgType MessageType, payload any) (*Message, error) {
 	return &Message{
 		Type:    msgType,
 		Payload: payloadBytes,
+		Version: ProtocolVersion,
 	}, nil
 }
 
@@ -258,7 +868,17 @@ func NewMessageWithID(msgType MessageType, id string, payload any) (*Message, er
 	return msg, nil
 }
 
-// ParsePayload unmarshals the payload into the given type.
+// ParsePayload unmarshals the payload into the given type. Messages decoded
+// in strict mode also reject unknown fields and run payload validation.
 func (m *Message) ParsePayload(v any) error {
-	return json.Unmarshal(m.Payload, v)
+	if err := decodeJSON(m.Payload, v, m.strict); err != nil {
+		return err
+	}
+	if !m.strict {
+		return nil
+	}
+	if validator, ok := v.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
 }
@@ -0,0 +1,395 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Validator is implemented by payloads that can check their own contents.
+// Validation problems are ignored in lenient mode and returned as errors from
+// ParsePayload in strict mode.
+type Validator interface {
+	Validate() error
+}
+
+// DecodeMessage parses one JSON line into a message, undoing compression and
+// migrating old payload shapes. In strict mode unknown envelope fields are
+// rejected and the message's payload is later parsed strictly.
+func DecodeMessage(line []byte, strict bool) (*Message, error) {
+	msg := &Message{strict: strict}
+	if err := decodeJSON(line, msg, strict); err != nil {
+		return nil, err
+	}
+	if err := Decompress(msg); err != nil {
+		return nil, err
+	}
+	if err := Migrate(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// decodeJSON unmarshals data, optionally rejecting unknown fields.
+func decodeJSON(data []byte, v any, strict bool) error {
+	if !strict {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Validate checks the percent range, ETA and step statuses.
+func (p ProgressPayload) Validate() error {
+	if p.Percent != nil && (*p.Percent < 0 || *p.Percent > 100) {
+		return fieldErrorf("percent", "%v out of range 0-100", *p.Percent)
+	}
+	if p.ETA != nil && *p.ETA < 0 {
+		return fieldErrorf("eta", "negative eta %v", *p.ETA)
+	}
+	for i, step := range p.Steps {
+		switch step.Status {
+		case "pending", "running", "complete", "error":
+		default:
+			return fieldErrorf(fmt.Sprintf("steps[%d].status", i), "unknown status %q", step.Status)
+		}
+	}
+	return nil
+}
+
+// Validate checks that every row has one cell per column.
+func (t TablePayload) Validate() error {
+	for i, row := range t.Rows {
+		if len(row) != len(t.Columns) {
+			return fieldErrorf(fmt.Sprintf("rows[%d]", i), "row %d has %d cells, want %d", i, len(row), len(t.Columns))
+		}
+	}
+	return nil
+}
+
+// Validate checks field names are present and unique, that select fields
+// have options and that the timeout is not negative.
+func (f FormPayload) Validate() error {
+	if f.TimeoutSeconds < 0 {
+		return fieldErrorf("timeout_seconds", "negative timeout_seconds %v", f.TimeoutSeconds)
+	}
+	seen := make(map[string]bool, len(f.Fields))
+	for i, field := range f.Fields {
+		if field.Name == "" {
+			return fieldErrorf(fmt.Sprintf("fields[%d].name", i), "field %d has no name", i)
+		}
+		if seen[field.Name] {
+			return fieldErrorf(fmt.Sprintf("fields[%d].name", i), "duplicate field name %q", field.Name)
+		}
+		seen[field.Name] = true
+		if field.Type == "select" && len(field.Options) == 0 {
+			return fieldErrorf(fmt.Sprintf("fields[%d].options", i), "select field %q has no options", field.Name)
+		}
+	}
+	return nil
+}
+
+// Validate checks the timeout is not negative.
+func (c ConfirmPayload) Validate() error {
+	if c.TimeoutSeconds < 0 {
+		return fieldErrorf("timeout_seconds", "negative timeout_seconds %v", c.TimeoutSeconds)
+	}
+	return nil
+}
+
+// Validate checks there is at least one labelled option and that the
+// timeout is not negative.
+func (s SelectPayload) Validate() error {
+	if s.TimeoutSeconds < 0 {
+		return fieldErrorf("timeout_seconds", "negative timeout_seconds %v", s.TimeoutSeconds)
+	}
+	if len(s.Options) == 0 {
+		return fieldErrorf("options", "select has no options")
+	}
+	for i, opt := range s.Options {
+		if opt.Label == "" {
+			return fieldErrorf(fmt.Sprintf("options[%d].label", i), "option %d has no label", i)
+		}
+	}
+	return nil
+}
+
+// Validate checks the tokens and cost are not negative.
+func (m MetadataPayload) Validate() error {
+	if m.Cost < 0 {
+		return fieldErrorf("cost", "negative cost %v", m.Cost)
+	}
+	if m.Tokens != nil && (m.Tokens.Input < 0 || m.Tokens.Output < 0) {
+		return fieldErrorf("tokens", "negative token count")
+	}
+	return nil
+}
+
+// Validate checks there is a question and every option is labelled.
+func (q QuestionPayload) Validate() error {
+	if strings.TrimSpace(q.Question) == "" {
+		return fieldErrorf("question", "question is empty")
+	}
+	for i, opt := range q.Options {
+		if opt.Label == "" {
+			return fieldErrorf(fmt.Sprintf("options[%d].label", i), "option %d has no label", i)
+		}
+	}
+	return nil
+}
+
+// Validate checks dashboard regions have IDs, unique across the grid, and
+// that no span or height is negative.
+func (l LayoutPayload) Validate() error {
+	seen := make(map[string]bool)
+	for i, row := range l.Rows {
+		for j, region := range row {
+			field := fmt.Sprintf("rows[%d][%d]", i, j)
+			if region.ID == "" {
+				return fieldErrorf(field+".id", "region has no id")
+			}
+			if seen[region.ID] {
+				return fieldErrorf(field+".id", "duplicate region id %q", region.ID)
+			}
+			seen[region.ID] = true
+			if region.Span < 0 {
+				return fieldErrorf(field+".span", "negative span %d", region.Span)
+			}
+			if region.Height < 0 {
+				return fieldErrorf(field+".height", "negative height %d", region.Height)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks every command has a name, unique and without spaces or
+// a leading slash.
+func (c CommandsPayload) Validate() error {
+	seen := make(map[string]bool, len(c.Commands))
+	for i, cmd := range c.Commands {
+		field := fmt.Sprintf("commands[%d].name", i)
+		switch {
+		case cmd.Name == "":
+			return fieldErrorf(field, "command %d has no name", i)
+		case strings.HasPrefix(cmd.Name, "/") || strings.ContainsFunc(cmd.Name, unicode.IsSpace):
+			return fieldErrorf(field, "command name %q has a slash or space", cmd.Name)
+		case seen[cmd.Name]:
+			return fieldErrorf(field, "duplicate command name %q", cmd.Name)
+		}
+		seen[cmd.Name] = true
+	}
+	return nil
+}
+
+// Validate checks the call has an ID and a name, and a status this TUI
+// knows.
+func (t ToolPayload) Validate() error {
+	switch {
+	case t.ID == "":
+		return fieldErrorf("id", "tool call has no id")
+	case t.Name == "":
+		return fieldErrorf("name", "tool call has no name")
+	}
+	switch t.Status {
+	case ToolRunning, ToolDone, ToolFailed:
+		return nil
+	}
+	return fieldErrorf("status", "unknown tool status %q", t.Status)
+}
+
+// Validate checks the severity is one the alert view knows.
+func (a AlertPayload) Validate() error {
+	switch a.Severity {
+	case "", "info", "success", "warning", "error":
+		return nil
+	}
+	return fieldErrorf("severity", "unknown severity %q", a.Severity)
+}
+
+// Validate checks the error has a message.
+func (e ErrorPayload) Validate() error {
+	if strings.TrimSpace(e.Message) == "" {
+		return fieldErrorf("message", "message is empty")
+	}
+	return nil
+}
+
+// Validate checks the artifact holds exactly one kind of content.
+func (a ArtifactPayload) Validate() error {
+	kinds := 0
+	for _, set := range []bool{a.Markdown != "", a.Code != nil, a.Table != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fieldErrorf("", "artifact must hold one of markdown, code or table, not %d", kinds)
+	}
+	if a.Table != nil {
+		if err := a.Table.Validate(); err != nil {
+			var ferr *FieldError
+			if errors.As(err, &ferr) {
+				return fieldErrorf("table."+ferr.Field, "%s", ferr.Problem)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the clear scope, and that scopes naming a message have
+// its ID.
+func (c ClearPayload) Validate() error {
+	switch c.Scope {
+	case ClearChat, ClearProgress, ClearAll, ClearLastTurn, ClearSystem:
+		return nil
+	case ClearByID, ClearBeforeID:
+		if c.Target == "" {
+			return fieldErrorf("target", "clear scope %s has no target", c.Scope)
+		}
+		return nil
+	}
+	return fieldErrorf("scope", "unknown clear scope %q", c.Scope)
+}
+
+// Validate checks the patch targets a block and uses a known op.
+func (p CodePatchPayload) Validate() error {
+	if p.ID == "" {
+		return fieldErrorf("id", "code patch has no id")
+	}
+	switch p.Op {
+	case CodePatchAppend, CodePatchReplace:
+		return nil
+	}
+	return fieldErrorf("op", "unknown patch op %q", p.Op)
+}
+
+// Validate checks there is data to encode and the error correction level.
+func (q QRCodePayload) Validate() error {
+	if q.Data == "" {
+		return fieldErrorf("data", "qrcode has no data")
+	}
+	switch q.Level {
+	case "", "L", "M", "Q", "H":
+		return nil
+	}
+	return fieldErrorf("level", "unknown error correction level %q", q.Level)
+}
+
+// Validate checks coordinates are in range and the zoom level.
+func (m MapPayload) Validate() error {
+	if m.Zoom < 0 || m.Zoom > 8 {
+		return fieldErrorf("zoom", "zoom %d out of range 0-8", m.Zoom)
+	}
+	if m.Center != nil {
+		if err := m.Center.validate(); err != nil {
+			return err.in("center", "center")
+		}
+	}
+	for i, p := range m.Points {
+		if err := p.validate(); err != nil {
+			return err.in(fmt.Sprintf("points[%d]", i), fmt.Sprintf("point %d", i))
+		}
+	}
+	return nil
+}
+
+// Validate checks there is a target.
+func (s ScrollToPayload) Validate() error {
+	if s.Target == "" {
+		return fieldErrorf("target", "scroll_to has no target")
+	}
+	return nil
+}
+
+// Validate checks there is a target.
+func (c CollapsePayload) Validate() error {
+	if c.Target == "" {
+		return fieldErrorf("target", "collapse has no target")
+	}
+	return nil
+}
+
+// Validate checks the expression is not empty.
+func (m MathPayload) Validate() error {
+	if strings.TrimSpace(m.Tex) == "" {
+		return fieldErrorf("tex", "math has no tex")
+	}
+	return nil
+}
+
+// Validate checks there is a representation and that each is keyed by a
+// MIME type.
+func (r RichPayload) Validate() error {
+	if len(r.Data) == 0 {
+		return fieldErrorf("data", "rich has no data")
+	}
+	for mime := range r.Data {
+		if !strings.Contains(mime, "/") {
+			return fieldErrorf("data", "%q is not a MIME type", mime)
+		}
+	}
+	return nil
+}
+
+// Validate checks the blob has an ID and either a file or chunks in range.
+func (b BlobPayload) Validate() error {
+	if b.ID == "" {
+		return fieldErrorf("id", "blob has no id")
+	}
+	if b.Size < 0 {
+		return fieldErrorf("size", "negative size %d", b.Size)
+	}
+	if b.Path != "" {
+		if b.Data != "" {
+			return fieldErrorf("data", "blob has both a path and data")
+		}
+		return nil
+	}
+	switch b.Encoding {
+	case "", BlobBase64:
+	default:
+		return fieldErrorf("encoding", "unknown blob encoding %q", b.Encoding)
+	}
+	if b.Chunk < 0 || b.Chunk >= max(b.Chunks, 1) {
+		return fieldErrorf("chunk", "chunk %d out of range 0-%d", b.Chunk, max(b.Chunks, 1)-1)
+	}
+	return nil
+}
+
+func (p MapPoint) validate() *FieldError {
+	if p.Lat < -90 || p.Lat > 90 {
+		return fieldErrorf("lat", "latitude %v out of range", p.Lat)
+	}
+	if p.Lon < -180 || p.Lon > 180 {
+		return fieldErrorf("lon", "longitude %v out of range", p.Lon)
+	}
+	return nil
+}
+
+// FieldError is a problem with one field of a payload. Field is a path of
+// JSON names and indexes, such as "steps[2].status"; it is empty when the
+// problem is not with one field.
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Problem string `json:"problem"`
+}
+
+func fieldErrorf(field, format string, args ...any) *FieldError {
+	return &FieldError{Field: field, Problem: fmt.Sprintf(format, args...)}
+}
+
+// Error returns the problem; the field is usually named in it already.
+func (e *FieldError) Error() string {
+	return e.Problem
+}
+
+// in returns e as a problem with a field of parent, described as label.
+func (e *FieldError) in(parent, label string) *FieldError {
+	return &FieldError{Field: parent + "." + e.Field, Problem: label + ": " + e.Problem}
+}
@@ -0,0 +1,187 @@
+package protocol
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeMessageStrict(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		strict     bool
+		wantDecode string // substring of DecodeMessage error, "" for none
+		wantParse  string // substring of ParsePayload error, "" for none
+	}{
+		{
+			name: "lenient ignores unknown payload field",
+			line: `{"type":"markdown","payload":{"content":"hi","colour":"red"}}`,
+		},
+		{
+			name:      "strict rejects unknown payload field",
+			line:      `{"type":"markdown","payload":{"content":"hi","colour":"red"}}`,
+			strict:    true,
+			wantParse: `unknown field "colour"`,
+		},
+		{
+			name:       "strict rejects unknown envelope field",
+			line:       `{"type":"markdown","payload":{"content":"hi"},"extra":1}`,
+			strict:     true,
+			wantDecode: `unknown field "extra"`,
+		},
+		{
+			name: "lenient accepts invalid table",
+			line: `{"type":"table","payload":{"columns":["a","b"],"rows":[["1"]]}}`,
+		},
+		{
+			name:      "strict reports invalid table",
+			line:      `{"type":"table","payload":{"columns":["a","b"],"rows":[["1"]]}}`,
+			strict:    true,
+			wantParse: "row 0 has 1 cells, want 2",
+		},
+		{
+			name:      "strict reports percent out of range",
+			line:      `{"type":"progress","payload":{"message":"x","percent":150}}`,
+			strict:    true,
+			wantParse: "out of range",
+		},
+		{
+			name:      "strict reports negative eta",
+			line:      `{"type":"progress","payload":{"message":"x","eta":-5}}`,
+			strict:    true,
+			wantParse: "negative eta",
+		},
+		{
+			name:      "strict reports clear by id without target",
+			line:      `{"type":"clear","payload":{"scope":"by_id"}}`,
+			strict:    true,
+			wantParse: "has no target",
+		},
+		{
+			name:   "strict accepts clear before id",
+			line:   `{"type":"clear","payload":{"scope":"before_id","target":"m3"}}`,
+			strict: true,
+		},
+		{
+			name:      "strict reports artifact without content",
+			line:      `{"type":"artifact","payload":{"title":"Plan"}}`,
+			strict:    true,
+			wantParse: "one of markdown, code or table",
+		},
+		{
+			name:      "strict reports artifact with two kinds of content",
+			line:      `{"type":"artifact","payload":{"markdown":"# Plan","code":{"code":"x"}}}`,
+			strict:    true,
+			wantParse: "not 2",
+		},
+		{
+			name:      "strict reports invalid artifact table",
+			line:      `{"type":"artifact","payload":{"table":{"columns":["a","b"],"rows":[["1"]]}}}`,
+			strict:    true,
+			wantParse: "row 0 has 1 cells, want 2",
+		},
+		{
+			name:      "strict reports negative confirm timeout",
+			line:      `{"type":"confirm","payload":{"message":"Deploy?","timeout_seconds":-1}}`,
+			strict:    true,
+			wantParse: "negative timeout_seconds",
+		},
+		{
+			name:      "strict reports negative select timeout",
+			line:      `{"type":"select","payload":{"label":"Pick","options":[{"label":"a"}],"timeout_seconds":-1}}`,
+			strict:    true,
+			wantParse: "negative timeout_seconds",
+		},
+		{
+			name:      "strict reports negative cost",
+			line:      `{"type":"metadata","payload":{"tokens":{"input":10,"output":2},"cost":-0.5}}`,
+			strict:    true,
+			wantParse: "negative cost",
+		},
+		{
+			name:      "strict reports rich data not keyed by MIME type",
+			line:      `{"type":"rich","payload":{"data":{"markdown":"# Hi"}}}`,
+			strict:    true,
+			wantParse: "not a MIME type",
+		},
+		{
+			name:      "strict reports duplicate layout region",
+			line:      `{"type":"layout","payload":{"components":[],"rows":[[{"id":"cpu"}],[{"id":"cpu"}]]}}`,
+			strict:    true,
+			wantParse: `duplicate region id "cpu"`,
+		},
+		{
+			name:      "strict reports layout region without id",
+			line:      `{"type":"layout","payload":{"components":[],"rows":[[{"title":"CPU"}]]}}`,
+			strict:    true,
+			wantParse: "region has no id",
+		},
+		{
+			name:   "strict accepts dashboard layout",
+			line:   `{"type":"layout","payload":{"components":[],"rows":[[{"id":"cpu","span":2},{"id":"mem"}],[{"id":"log","height":5}]]}}`,
+			strict: true,
+		},
+		{
+			name:      "strict reports command name with a slash",
+			line:      `{"type":"commands","payload":{"commands":[{"name":"/deploy"}]}}`,
+			strict:    true,
+			wantParse: "has a slash or space",
+		},
+		{
+			name:      "strict reports duplicate command",
+			line:      `{"type":"commands","payload":{"commands":[{"name":"deploy"},{"name":"deploy"}]}}`,
+			strict:    true,
+			wantParse: `duplicate command name "deploy"`,
+		},
+		{
+			name:      "strict reports unknown tool status",
+			line:      `{"type":"tool","payload":{"id":"t1","name":"search","status":"pending"}}`,
+			strict:    true,
+			wantParse: `unknown tool status "pending"`,
+		},
+		{
+			name:   "strict accepts valid payload",
+			line:   `{"type":"progress","payload":{"message":"x","percent":50,"eta":12.5}}`,
+			strict: true,
+		},
+	}
+
+	payloads := map[MessageType]func() any{
+		TypeMarkdown: func() any { return &MarkdownPayload{} },
+		TypeTable:    func() any { return &TablePayload{} },
+		TypeProgress: func() any { return &ProgressPayload{} },
+		TypeArtifact: func() any { return &ArtifactPayload{} },
+		TypeConfirm:  func() any { return &ConfirmPayload{} },
+		TypeSelect:   func() any { return &SelectPayload{} },
+		TypeMetadata: func() any { return &MetadataPayload{} },
+		TypeRich:     func() any { return &RichPayload{} },
+		TypeLayout:   func() any { return &LayoutPayload{} },
+		TypeCommands: func() any { return &CommandsPayload{} },
+		TypeTool:     func() any { return &ToolPayload{} },
+		TypeClear:    func() any { return &ClearPayload{} },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := DecodeMessage([]byte(tt.line), tt.strict)
+			if !errorMatches(err, tt.wantDecode) {
+				t.Fatalf("DecodeMessage() error = %v, want %q", err, tt.wantDecode)
+			}
+			if err != nil {
+				return
+			}
+
+			err = msg.ParsePayload(payloads[msg.Type]())
+			if !errorMatches(err, tt.wantParse) {
+				t.Errorf("ParsePayload() error = %v, want %q", err, tt.wantParse)
+			}
+		})
+	}
+}
+
+func errorMatches(err error, want string) bool {
+	if want == "" {
+		return err == nil
+	}
+	return err != nil && strings.Contains(err.Error(), want)
+}
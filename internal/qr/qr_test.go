@@ -0,0 +1,125 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRemainder(t *testing.T) {
+	// "HELLO WORLD" at 1-M, from the worked example in the standard's annex
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := remainder(data, generator(len(want))); !bytes.Equal(got, want) {
+		t.Errorf("remainder() = %v, want %v", got, want)
+	}
+}
+
+func TestBCH(t *testing.T) {
+	// Level M, mask 5 and version 7 from the standard's format/version tables
+	if got := (0b00101<<10 | bch(0b00101, 0x537, 10)) ^ 0x5412; got != 0b100000011001110 {
+		t.Errorf("format bits = %015b", got)
+	}
+	if got := 7<<12 | bch(7, 0x1F25, 12); got != 0b000111110010010100 {
+		t.Errorf("version bits = %018b", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{"", M, false},
+		{"l", L, false},
+		{"Q", Q, false},
+		{"h", H, false},
+		{"X", M, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestEncodeVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		level   Level
+		version int
+	}{
+		{"fits version 1", 14, M, 1},
+		{"overflows version 1", 15, M, 2},
+		{"higher level needs more room", 14, H, 2},
+		{"long count field", 250, L, 10},
+		{"largest", 2953, L, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode(bytes.Repeat([]byte("x"), tt.size), tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Version != tt.version || c.Size != tt.version*4+17 {
+				t.Errorf("version %d size %d, want version %d", c.Version, c.Size, tt.version)
+			}
+		})
+	}
+
+	if _, err := Encode(bytes.Repeat([]byte("x"), 2954), L); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode() error = %v, want ErrTooLong", err)
+	}
+}
+
+func TestEncodeMatrix(t *testing.T) {
+	want := []string{
+		"#######...#...#######",
+		"#.....#..####.#.....#",
+		"#.###.#.#.#.#.#.###.#",
+		"#.###.#.####..#.###.#",
+		"#.###.#.#...#.#.###.#",
+		"#.....#.####..#.....#",
+		"#######.#.#.#.#######",
+		"........##...........",
+		"#.#####..###..#####..",
+		"#.##.#.###.#####.##.#",
+		"##..#.##.##.#.##.#.#.",
+		"#..#.#...######..###.",
+		".#..#.#.....#..##....",
+		"........###.#..####.#",
+		"#######..#.#.#...###.",
+		"#.....#.#.#......####",
+		"#.###.#.#..#.#.....#.",
+		"#.###.#.#######.##...",
+		"#.###.#.###.#.#......",
+		"#.....#...######.##..",
+		"#######.#...#....#.#.",
+	}
+	c, err := Encode([]byte("agentui"), M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for y := 0; y < c.Size; y++ {
+		var row strings.Builder
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		got = append(got, row.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("matrix =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if c.Dark(-1, 0) || c.Dark(0, c.Size) {
+		t.Error("modules outside the symbol should be light")
+	}
+}
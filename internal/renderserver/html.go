@@ -0,0 +1,252 @@
+package renderserver
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ansiColors are the xterm colors of the 16 basic ANSI colors.
+var ansiColors = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// sgrState is the style set by SGR sequences so far.
+type sgrState struct {
+	fg, bg                                          string // CSS colors, empty for the default
+	bold, faint, italic, underline, strike, reverse bool
+}
+
+// css returns the inline style of text in state s, empty if it has none.
+func (s sgrState) css() string {
+	fg, bg := s.fg, s.bg
+	if s.reverse {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = "var(--agentui-bg)"
+		}
+		if bg == "" {
+			bg = "var(--agentui-fg)"
+		}
+	}
+	var props []string
+	if fg != "" {
+		props = append(props, "color:"+fg)
+	}
+	if bg != "" {
+		props = append(props, "background:"+bg)
+	}
+	if s.bold {
+		props = append(props, "font-weight:bold")
+	}
+	if s.faint {
+		props = append(props, "opacity:0.6")
+	}
+	if s.italic {
+		props = append(props, "font-style:italic")
+	}
+	var lines []string
+	if s.underline {
+		lines = append(lines, "underline")
+	}
+	if s.strike {
+		lines = append(lines, "line-through")
+	}
+	if lines != nil {
+		props = append(props, "text-decoration:"+strings.Join(lines, " "))
+	}
+	return strings.Join(props, ";")
+}
+
+// apply updates s with the parameters of one SGR sequence.
+func (s *sgrState) apply(params string) {
+	fields := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	if len(fields) == 0 {
+		fields = []string{"0"}
+	}
+	for i := 0; i < len(fields); i++ {
+		n, _ := strconv.Atoi(fields[i])
+		switch {
+		case n == 0:
+			*s = sgrState{}
+		case n == 1:
+			s.bold = true
+		case n == 2:
+			s.faint = true
+		case n == 3:
+			s.italic = true
+		case n == 4:
+			s.underline = true
+		case n == 7:
+			s.reverse = true
+		case n == 9:
+			s.strike = true
+		case n == 22:
+			s.bold, s.faint = false, false
+		case n == 23:
+			s.italic = false
+		case n == 24:
+			s.underline = false
+		case n == 27:
+			s.reverse = false
+		case n == 29:
+			s.strike = false
+		case n >= 30 && n <= 37:
+			s.fg = ansiColors[n-30]
+		case n >= 90 && n <= 97:
+			s.fg = ansiColors[n-90+8]
+		case n == 39:
+			s.fg = ""
+		case n >= 40 && n <= 47:
+			s.bg = ansiColors[n-40]
+		case n >= 100 && n <= 107:
+			s.bg = ansiColors[n-100+8]
+		case n == 49:
+			s.bg = ""
+		case n == 38 || n == 48:
+			color, used := extendedColor(fields[i+1:])
+			i += used
+			if n == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+		}
+	}
+}
+
+// extendedColor reads the color of a 38 or 48 SGR parameter from the
+// parameters after it, returning it and how many parameters it took.
+func extendedColor(fields []string) (string, int) {
+	atoi := func(i int) int {
+		if i >= len(fields) {
+			return 0
+		}
+		n, _ := strconv.Atoi(fields[i])
+		return max(0, min(n, 255))
+	}
+	if len(fields) == 0 {
+		return "", 0
+	}
+	switch fields[0] {
+	case "5":
+		return paletteColor(atoi(1)), 2
+	case "2":
+		return fmt.Sprintf("#%02x%02x%02x", atoi(1), atoi(2), atoi(3)), 4
+	}
+	return "", 1
+}
+
+// paletteColor returns the xterm color of a 256-color palette index.
+func paletteColor(n int) string {
+	switch {
+	case n < 16:
+		return ansiColors[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + 40*v
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	}
+	gray := 8 + 10*(n-232)
+	return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+}
+
+// cssColor returns c as a CSS color.
+func cssColor(c lipgloss.TerminalColor) string {
+	switch c := c.(type) {
+	case lipgloss.Color:
+		if n, err := strconv.Atoi(string(c)); err == nil {
+			return paletteColor(max(0, min(n, 255)))
+		}
+		return string(c)
+	case lipgloss.AdaptiveColor:
+		if lipgloss.HasDarkBackground() {
+			return cssColor(lipgloss.Color(c.Dark))
+		}
+		return cssColor(lipgloss.Color(c.Light))
+	case lipgloss.CompleteColor:
+		return c.TrueColor
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// toHTML renders text drawn with SGR escape sequences as a <pre> block in
+// the given colors, with a span for each styled run. Other escape
+// sequences, such as hyperlinks, are dropped.
+func toHTML(text string, fg, bg lipgloss.TerminalColor) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<pre class="agentui" style="--agentui-fg:%[1]s;--agentui-bg:%[2]s;color:%[1]s;background:%[2]s;padding:1em">`,
+		cssColor(fg), cssColor(bg))
+
+	var state sgrState
+	open := ""
+	var run strings.Builder
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		style := state.css()
+		if style != open {
+			if open != "" {
+				sb.WriteString("</span>")
+			}
+			if style != "" {
+				sb.WriteString(`<span style="` + style + `">`)
+			}
+			open = style
+		}
+		sb.WriteString(html.EscapeString(run.String()))
+		run.Reset()
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '\x1b' {
+			run.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(text) {
+			break
+		}
+		switch text[i+1] {
+		case '[':
+			end := i + 2
+			for end < len(text) && (text[end] < 0x40 || text[end] > 0x7e) {
+				end++
+			}
+			if end < len(text) && text[end] == 'm' {
+				flush()
+				state.apply(text[i+2 : end])
+			}
+			i = end
+		case ']', 'P', '_', '^':
+			// A string ends at BEL or ST
+			end := i + 2
+			for end < len(text) && text[end] != '\a' && !(text[end] == '\x1b' && end+1 < len(text) && text[end+1] == '\\') {
+				end++
+			}
+			if end < len(text) && text[end] == '\x1b' {
+				end++
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	flush()
+	if open != "" {
+		sb.WriteString("</span>")
+	}
+	sb.WriteString("</pre>\n")
+	return sb.String()
+}
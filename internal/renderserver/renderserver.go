@@ -0,0 +1,209 @@
+// Package renderserver renders protocol messages over HTTP the way the TUI
+// draws them, so CI systems and web services can show agent output
+// without a terminal.
+package renderserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/flight505/agentui/internal/app"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/replay"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// Output formats.
+const (
+	FormatANSI  = "ansi"
+	FormatPlain = "plain"
+	FormatHTML  = "html"
+)
+
+const (
+	// DefaultWidth is the width rendered at unless a request sets one.
+	DefaultWidth = 80
+	// maxWidth and maxHeight bound the size a request may ask for.
+	maxWidth  = 1000
+	maxHeight = 1000
+	// maxBodySize bounds a request's messages.
+	maxBodySize = 16 << 20
+	// transcriptHeight is the screen height transcripts are rendered at; it
+	// does not change how they look.
+	transcriptHeight = 24
+)
+
+// Options configures a Server.
+type Options struct {
+	Width  int    // default width, DefaultWidth if 0
+	Theme  string // default theme, charm-dark if empty
+	Strict bool   // reject unknown fields, as --strict-protocol does
+}
+
+// Server answers POST /render with the rendering of the protocol message,
+// or JSON array of messages, in the body. Query parameters choose the
+// output:
+//
+//	format  ansi (the default), plain or html
+//	width   columns to render at
+//	height  render the whole screen at this many rows, as replay does,
+//	        rather than just the transcript
+//	theme   color theme
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+
+	// mu serializes renders, since the theme is global
+	mu sync.Mutex
+}
+
+// New creates a server.
+func New(opts Options) *Server {
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+	if opts.Theme == "" {
+		opts.Theme = "charm-dark"
+	}
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/render", s.handleRender)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// request is what a render request asks for.
+type request struct {
+	msg           *protocol.Message
+	format        string
+	width, height int
+	theme         string
+}
+
+func (s *Server) handleRender(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "use POST with a protocol message as the body", http.StatusMethodNotAllowed)
+		return
+	}
+	req, err := s.parseRequest(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !theme.SetTheme(req.theme) {
+		http.Error(w, "unknown theme: "+req.theme, http.StatusBadRequest)
+		return
+	}
+	out := render(req)
+
+	switch req.format {
+	case FormatHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		out = toHTML(out, theme.Current.Colors.Text, theme.Current.Colors.Background)
+	case FormatPlain:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		out = replay.Normalize(out)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		out = strings.TrimRight(out, "\n") + "\n"
+	}
+	io.WriteString(w, out)
+}
+
+// parseRequest reads the messages and query parameters of a request.
+func (s *Server) parseRequest(w http.ResponseWriter, r *http.Request) (request, error) {
+	query := r.URL.Query()
+	req := request{
+		format: query.Get("format"),
+		width:  s.opts.Width,
+		theme:  s.opts.Theme,
+	}
+	switch req.format {
+	case "":
+		req.format = FormatANSI
+	case FormatANSI, FormatPlain, FormatHTML:
+	default:
+		return req, fmt.Errorf("unknown format %q: use ansi, plain or html", req.format)
+	}
+	if t := query.Get("theme"); t != "" {
+		req.theme = t
+	}
+	var err error
+	if req.width, err = sizeParam(query.Get("width"), req.width, maxWidth); err != nil {
+		return req, fmt.Errorf("width: %w", err)
+	}
+	if req.height, err = sizeParam(query.Get("height"), 0, maxHeight); err != nil {
+		return req, fmt.Errorf("height: %w", err)
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		return req, err
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return req, errors.New("no message in the body")
+	}
+	decode := protocol.DecodeMessage
+	if protocol.IsBatch(body) {
+		decode = protocol.DecodeBatch
+	}
+	if req.msg, err = decode(body, s.opts.Strict); err != nil {
+		return req, fmt.Errorf("invalid message: %w", err)
+	}
+	msgs := []*protocol.Message{req.msg}
+	if req.msg.Type == protocol.TypeBatch {
+		msgs = req.msg.Batch
+	}
+	for _, msg := range msgs {
+		if err := protocol.CheckPayload(msg); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// sizeParam parses a width or height, def if empty.
+func sizeParam(value string, def, limit int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > limit {
+		return 0, fmt.Errorf("%q is not between 1 and %d", value, limit)
+	}
+	return n, nil
+}
+
+// render draws the request's messages in the current theme: the whole
+// screen if it gives a height, otherwise the transcript.
+func render(req request) string {
+	handler := protocol.NewHandler(strings.NewReader(""), io.Discard)
+	model := app.NewModel(handler, "AgentUI", "AI Agent Interface")
+	if req.height > 0 {
+		h := model.Headless(req.width, req.height)
+		h.Apply(req.msg)
+		return h.View()
+	}
+	h := model.Headless(req.width, transcriptHeight)
+	h.Apply(req.msg)
+	return h.Transcript()
+}
@@ -0,0 +1,104 @@
+package renderserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// post sends body to the server's /render with query and returns the
+// response code and body.
+func post(t *testing.T, s *Server, query, body string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/render?"+query, strings.NewReader(body)))
+	return rec.Code, rec.Body.String()
+}
+
+func TestRenderPlain(t *testing.T) {
+	s := New(Options{})
+	code, body := post(t, s, "format=plain&width=40",
+		`[{"type":"markdown","payload":{"content":"Hello **there**"}},
+		  {"type":"table","payload":{"columns":["name"],"rows":[["alpha"]]}}]`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %q", code, body)
+	}
+	for _, want := range []string{"Hello there", "name", "alpha"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("transcript lacks %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "\x1b") {
+		t.Errorf("plain output has escape sequences: %q", body)
+	}
+	if strings.Contains(body, "Type a message") {
+		t.Errorf("transcript has the input box:\n%s", body)
+	}
+}
+
+func TestRenderFrame(t *testing.T) {
+	code, body := post(t, New(Options{}), "format=plain&width=50&height=16",
+		`{"type":"text","payload":{"content":"hello","done":true}}`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %q", code, body)
+	}
+	if !strings.Contains(body, "hello") || !strings.Contains(body, "Type a message") {
+		t.Errorf("frame lacks the message or the input:\n%s", body)
+	}
+	if lines := strings.Count(body, "\n"); lines > 16 {
+		t.Errorf("frame has %d lines, want at most 16", lines)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	code, body := post(t, New(Options{}), "format=html",
+		`{"type":"code","payload":{"language":"text","code":"a < b && c"}}`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %q", code, body)
+	}
+	if !strings.HasPrefix(body, `<pre class="agentui"`) || !strings.Contains(body, "a &lt; b &amp;&amp; c") {
+		t.Errorf("html = %q", body)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	s := New(Options{Strict: true})
+	tests := []struct {
+		name, query, body string
+		code              int
+	}{
+		{"bad json", "", "{", http.StatusBadRequest},
+		{"empty", "", "  ", http.StatusBadRequest},
+		{"format", "format=pdf", `{"type":"text","payload":{"content":"x"}}`, http.StatusBadRequest},
+		{"width", "width=0", `{"type":"text","payload":{"content":"x"}}`, http.StatusBadRequest},
+		{"theme", "theme=nope", `{"type":"text","payload":{"content":"x"}}`, http.StatusBadRequest},
+		{"invalid payload", "", `{"type":"progress","payload":{"percent":200}}`, http.StatusBadRequest},
+		{"invalid in batch", "", `[{"type":"text","payload":{"content":"x"}},{"type":"progress","payload":{"percent":-1}}]`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code, body := post(t, s, tt.query, tt.body); code != tt.code {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, code, tt.code, body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d", rec.Code)
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	got := toHTML("\x1b[1;38;2;255;0;0mred\x1b[0m <plain> \x1b[4;38;5;21mblue\x1b[24m\x1b]8;;http://x\x07link\x1b]8;;\x07\x1b[m",
+		lipgloss.Color("#ffffff"), lipgloss.Color("#000000"))
+	want := `<pre class="agentui" style="--agentui-fg:#ffffff;--agentui-bg:#000000;color:#ffffff;background:#000000;padding:1em">` +
+		`<span style="color:#ff0000;font-weight:bold">red</span> &lt;plain&gt; ` +
+		`<span style="color:#0000ff;text-decoration:underline">blue</span>` +
+		`<span style="color:#0000ff">link</span></pre>` + "\n"
+	if got != want {
+		t.Errorf("toHTML() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+// Package replay loads recorded protocol sessions and compares the frames
+// they render against stored golden files.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// maxLineSize bounds a single recorded message line.
+const maxLineSize = 64 << 20
+
+// ReadSession parses a recorded session: one protocol message, or a JSON
+// array of messages applied together, per line, as written by --record.
+// Blank lines are skipped.
+func ReadSession(r io.Reader, strict bool) ([]*protocol.Message, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var msgs []*protocol.Message
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		decode := protocol.DecodeMessage
+		if protocol.IsBatch(line) {
+			decode = protocol.DecodeBatch
+		}
+		msg, err := decode(line, strict)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// Normalize strips escape sequences and trailing spaces so frames compare
+// the same across terminals and color profiles.
+func Normalize(frame string) string {
+	lines := strings.Split(ansi.Strip(frame), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// FrameName returns the golden file name for the i-th (zero-based) frame.
+func FrameName(i int) string {
+	return fmt.Sprintf("frame-%04d.txt", i+1)
+}
+
+// WriteGoldens stores normalized frames in dir, removing stale goldens.
+func WriteGoldens(dir string, frames []string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	stale, err := filepath.Glob(filepath.Join(dir, "frame-*.txt"))
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	for i, frame := range frames {
+		if err := os.WriteFile(filepath.Join(dir, FrameName(i)), []byte(Normalize(frame)), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssertFrames compares frames against the goldens in dir. It returns an
+// error with a line diff of the first frame that differs.
+func AssertFrames(dir string, frames []string) error {
+	goldens, err := filepath.Glob(filepath.Join(dir, "frame-*.txt"))
+	if err != nil {
+		return err
+	}
+	if len(goldens) != len(frames) {
+		return fmt.Errorf("session rendered %d frames, %s has %d goldens", len(frames), dir, len(goldens))
+	}
+
+	for i, frame := range frames {
+		path := filepath.Join(dir, FrameName(i))
+		want, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		got := Normalize(frame)
+		if string(want) != got {
+			return fmt.Errorf("frame %d differs from %s:\n%s", i+1, path, Diff(string(want), got))
+		}
+	}
+	return nil
+}
+
+// Diff returns a line diff of want and got, prefixing removed lines with
+// "-", added lines with "+" and unchanged lines with a space.
+func Diff(want, got string) string {
+	a := strings.Split(strings.TrimRight(want, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(got, "\n"), "\n")
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
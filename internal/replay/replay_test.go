@@ -0,0 +1,67 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSession(t *testing.T) {
+	session := `{"type":"markdown","payload":{"content":"hi"}}
+
+{"type":"select","payload":{"label":"Pick","options":["a"]}}
+`
+	msgs, err := ReadSession(strings.NewReader(session), false)
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	if msgs[1].Type != "select" {
+		t.Errorf("second message type = %q", msgs[1].Type)
+	}
+
+	_, err = ReadSession(strings.NewReader("{\"type\":\"text\"}\nnot json\n"), false)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error on line 2, got %v", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize("\x1b[1mbold\x1b[0m   \nplain\n\n")
+	if got != "bold\nplain\n" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
+
+func TestAssertFrames(t *testing.T) {
+	dir := t.TempDir()
+	frames := []string{"one\n", "two\nlines\n"}
+	if err := WriteGoldens(dir, frames); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AssertFrames(dir, frames); err != nil {
+		t.Errorf("matching frames: %v", err)
+	}
+
+	err := AssertFrames(dir, []string{"one\n", "two\nchanged\n"})
+	if err == nil || !strings.Contains(err.Error(), "frame 2") {
+		t.Fatalf("expected frame 2 mismatch, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "- lines\n+ changed") {
+		t.Errorf("diff missing from error: %v", err)
+	}
+
+	if err := AssertFrames(dir, frames[:1]); err == nil {
+		t.Error("expected frame count mismatch")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff("a\nb\nc\n", "a\nx\nc\n")
+	want := "  a\n- b\n+ x\n  c\n"
+	if got != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
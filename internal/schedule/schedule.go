@@ -0,0 +1,228 @@
+// Package schedule parses the schedules on which the TUI sends the agent
+// a prompt, or a tick event, by itself while the session is open, for
+// monitoring and periodic report agents.
+//
+// A schedule is written as when it fires, optionally followed by a colon
+// and the prompt to send:
+//
+//	every 15m: Summarize new errors in the logs
+//	0 9 * * 1-5: Write the daily report
+//	@hourly
+//
+// When is "every" and a duration, a cron expression of five fields
+// (minute, hour, day of month, month, day of week) or one of @hourly,
+// @daily, @weekly and @monthly. A schedule without a prompt sends ticks.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinInterval is the shortest interval an "every" schedule may have.
+const MinInterval = time.Second
+
+// horizon is how far ahead Next looks for a time a cron expression
+// matches, enough for one that only matches on February 29.
+const horizon = 5 * 366 * 24 * time.Hour
+
+// aliases are the cron expressions the @ names stand for.
+var aliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// Schedule is when to send a prompt or tick.
+type Schedule struct {
+	// When is the schedule's time as written, e.g. "every 15m"
+	When string
+	// Prompt is sent as if the user typed it; empty sends a tick
+	Prompt string
+
+	every time.Duration
+	cron  *cron
+}
+
+// Parse parses a schedule written as described in the package comment.
+func Parse(spec string) (Schedule, error) {
+	when, prompt, _ := strings.Cut(spec, ":")
+	s := Schedule{When: strings.Join(strings.Fields(when), " "), Prompt: strings.TrimSpace(prompt)}
+	if s.When == "" {
+		return s, fmt.Errorf("schedule %q has no time", spec)
+	}
+
+	if rest, ok := strings.CutPrefix(s.When, "every "); ok {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return s, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		if d < MinInterval {
+			return s, fmt.Errorf("schedule %q: interval %v is shorter than %v", spec, d, MinInterval)
+		}
+		s.every = d
+		return s, nil
+	}
+
+	expr := s.When
+	if alias, ok := aliases[expr]; ok {
+		expr = alias
+	}
+	c, err := parseCron(expr)
+	if err != nil {
+		return s, fmt.Errorf("schedule %q: %w", spec, err)
+	}
+	s.cron = c
+	if s.Next(time.Now()).IsZero() {
+		return s, fmt.Errorf("schedule %q never fires", spec)
+	}
+	return s, nil
+}
+
+// Next returns the first time after after the schedule fires, or the
+// zero time if it never does.
+func (s Schedule) Next(after time.Time) time.Time {
+	if s.every > 0 {
+		return after.Add(s.every)
+	}
+	if s.cron == nil {
+		return time.Time{}
+	}
+	return s.cron.next(after)
+}
+
+// String returns the schedule as Parse reads it.
+func (s Schedule) String() string {
+	if s.Prompt == "" {
+		return s.When
+	}
+	return s.When + ": " + s.Prompt
+}
+
+// cron is a parsed cron expression, as the set of values each field
+// matches.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// Day of month and day of week are both restricted, so a day matching
+	// either matches, as in cron
+	either bool
+}
+
+// cronFields are the ranges of the five fields of a cron expression.
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// parseCron parses a five field cron expression. Each field is a comma
+// separated list of *, a number or a range a-b, each optionally followed
+// by /step.
+func parseCron(expr string) (*cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("want every, @hourly, @daily, @weekly, @monthly or a cron expression of 5 fields, not %q", expr)
+	}
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cronFields[i].name, err)
+		}
+		sets[i] = set
+	}
+	// Sunday is both 0 and 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &cron{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		either: !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField returns the set of values from min to max a field
+// matches.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		span, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if span != "*" {
+			from, to, isRange := strings.Cut(span, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", to)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// next returns the first minute after after that the expression matches,
+// in after's location, or the zero time if there is none within horizon.
+func (c *cron) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	end := after.Add(horizon)
+	for t.Before(end) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches the day of month and
+// day of week fields.
+func (c *cron) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.either {
+		return dom || dow
+	}
+	return dom && dow
+}
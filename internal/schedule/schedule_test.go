@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		when    string
+		prompt  string
+		wantErr string // substring of the error, "" for none
+	}{
+		{spec: "every 15m: Summarize new errors", when: "every 15m", prompt: "Summarize new errors"},
+		{spec: "  every   1h  ", when: "every 1h"},
+		{spec: "0 9 * * 1-5: Report: today", when: "0 9 * * 1-5", prompt: "Report: today"},
+		{spec: "@daily", when: "@daily"},
+		{spec: "every 10ms", wantErr: "shorter than"},
+		{spec: "every soon", wantErr: "invalid duration"},
+		{spec: ": hello", wantErr: "has no time"},
+		{spec: "0 9 * *", wantErr: "5 fields"},
+		{spec: "61 * * * *", wantErr: "minute"},
+		{spec: "*/0 * * * *", wantErr: "invalid step"},
+		{spec: "0 0 30 2 *", wantErr: "never fires"},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.spec, err)
+			continue
+		}
+		if s.When != tt.when || s.Prompt != tt.prompt {
+			t.Errorf("Parse(%q) = %q, %q; want %q, %q", tt.spec, s.When, s.Prompt, tt.when, tt.prompt)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// A Wednesday
+	at := time.Date(2024, 3, 6, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"every 90s", at.Add(90 * time.Second)},
+		{"*/15 * * * *", time.Date(2024, 3, 6, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC)},
+		{"30 8 * * 0", time.Date(2024, 3, 10, 8, 30, 0, 0, time.UTC)},
+		{"30 8 * * 7", time.Date(2024, 3, 10, 8, 30, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Day of month or day of week: the 8th, a Friday, comes first
+		{"0 12 8 * 1", time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC)},
+		{"17 10 * * *", time.Date(2024, 3, 7, 10, 17, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.spec, err)
+		}
+		if got := s.Next(at); !got.Equal(tt.want) {
+			t.Errorf("%q.Next() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, spec := range []string{"every 5m: Check the queue", "@hourly"} {
+		s, err := Parse(spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", spec, err)
+		}
+		if s.String() != spec {
+			t.Errorf("String() = %q, want %q", s.String(), spec)
+		}
+	}
+}
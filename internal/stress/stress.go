@@ -0,0 +1,184 @@
+// Package stress generates synthetic protocol traffic and measures how
+// quickly it is rendered, for validating performance work.
+package stress
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+// Types lists the message types the generator can produce.
+var Types = []protocol.MessageType{
+	protocol.TypeText, protocol.TypeMarkdown, protocol.TypeProgress,
+	protocol.TypeTable, protocol.TypeCode, protocol.TypeAlert,
+}
+
+// FrameInterval is the 60 fps frame budget dropped frames are counted
+// against.
+const FrameInterval = time.Second / 60
+
+// Generator produces an endless stream of synthetic messages, cycling
+// through its types.
+type Generator struct {
+	types []protocol.MessageType
+	seq   int
+}
+
+// NewGenerator parses a comma-separated list of message types.
+func NewGenerator(types string) (*Generator, error) {
+	g := &Generator{}
+	for _, name := range strings.Split(types, ",") {
+		t := protocol.MessageType(strings.TrimSpace(name))
+		if !slices.Contains(Types, t) {
+			return nil, fmt.Errorf("unsupported stress type %q (want %s)", t, typeNames())
+		}
+		g.types = append(g.types, t)
+	}
+	return g, nil
+}
+
+func typeNames() string {
+	names := make([]string, len(Types))
+	for i, t := range Types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Next returns the next message. Every message has a unique ID.
+func (g *Generator) Next() *protocol.Message {
+	n := g.seq
+	g.seq++
+	t := g.types[n%len(g.types)]
+
+	var payload any
+	switch t {
+	case protocol.TypeText:
+		// Stream a sentence a word at a time, committing every 20 words
+		payload = protocol.TextPayload{Content: fmt.Sprintf("word%d ", n), Done: n%20 == 19}
+	case protocol.TypeMarkdown:
+		payload = protocol.MarkdownPayload{Content: fmt.Sprintf("## Update %d\n\nSome **bold** text and a `code span`.", n)}
+	case protocol.TypeProgress:
+		percent := float64(n % 101)
+		payload = protocol.ProgressPayload{ID: "stress", Message: fmt.Sprintf("Step %d", n), Percent: &percent}
+	case protocol.TypeTable:
+		rows := make([][]string, 5)
+		for i := range rows {
+			rows[i] = []string{fmt.Sprint(n*5 + i), fmt.Sprintf("item-%d", i), fmt.Sprintf("%.2f", float64(n+i)/3)}
+		}
+		payload = protocol.TablePayload{Title: fmt.Sprintf("Batch %d", n), Columns: []any{"#", "Name", "Value"}, Rows: rows}
+	case protocol.TypeCode:
+		payload = protocol.CodePayload{Code: fmt.Sprintf("func f%d() int {\n\treturn %d\n}", n, n), Language: "go"}
+	case protocol.TypeAlert:
+		payload = protocol.AlertPayload{Message: fmt.Sprintf("Event %d", n), Severity: "info"}
+	}
+
+	msg, _ := protocol.NewMessageWithID(t, fmt.Sprintf("stress-%d", n), payload)
+	return msg
+}
+
+// Stats records when messages were sent and when the first frame showing
+// them was rendered. It is safe for concurrent use.
+type Stats struct {
+	mu        sync.Mutex
+	sent      map[string]time.Time
+	latencies []time.Duration
+	dropped   int
+	start     time.Time
+	last      time.Time
+}
+
+// NewStats creates an empty Stats.
+func NewStats() *Stats {
+	return &Stats{sent: make(map[string]time.Time)}
+}
+
+// Sent records that the message with the given ID was sent at t.
+func (s *Stats) Sent(id string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.start.IsZero() {
+		s.start = t
+	}
+	s.sent[id] = t
+}
+
+// Rendered records a frame finished at done that first shows the messages
+// with the given IDs. Every frame interval the oldest of them waited beyond
+// the first counts as a dropped frame.
+func (s *Stats) Rendered(ids []string, done time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var oldest time.Time
+	for _, id := range ids {
+		sent, ok := s.sent[id]
+		if !ok {
+			continue
+		}
+		delete(s.sent, id)
+		s.latencies = append(s.latencies, done.Sub(sent))
+		if oldest.IsZero() || sent.Before(oldest) {
+			oldest = sent
+		}
+	}
+	if !oldest.IsZero() {
+		s.dropped += int(done.Sub(oldest) / FrameInterval)
+		s.last = done
+	}
+}
+
+// Report summarises the recorded traffic.
+func (s *Stats) Report() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := Report{
+		Sent:     len(s.latencies) + len(s.sent),
+		Rendered: len(s.latencies),
+		Dropped:  s.dropped,
+	}
+	if !s.last.IsZero() {
+		r.Elapsed = s.last.Sub(s.start)
+	}
+	if len(s.latencies) == 0 {
+		return r
+	}
+	sorted := slices.Clone(s.latencies)
+	slices.Sort(sorted)
+	at := func(q float64) time.Duration {
+		return sorted[int(q*float64(len(sorted)-1))]
+	}
+	r.P50, r.P95, r.P99, r.Max = at(0.50), at(0.95), at(0.99), sorted[len(sorted)-1]
+	return r
+}
+
+// Report is a summary of a stress run.
+type Report struct {
+	Sent, Rendered     int
+	Elapsed            time.Duration
+	P50, P95, P99, Max time.Duration
+	Dropped            int
+}
+
+// String formats the report for the terminal.
+func (r Report) String() string {
+	var sb strings.Builder
+	rate := 0.0
+	if r.Elapsed > 0 {
+		rate = float64(r.Rendered) / r.Elapsed.Seconds()
+	}
+	fmt.Fprintf(&sb, "Messages:        %d sent, %d rendered in %s (%.0f/s)\n",
+		r.Sent, r.Rendered, r.Elapsed.Round(time.Millisecond), rate)
+	fmt.Fprintf(&sb, "Render latency:  p50 %s  p95 %s  p99 %s  max %s\n",
+		round(r.P50), round(r.P95), round(r.P99), round(r.Max))
+	fmt.Fprintf(&sb, "Dropped frames:  %d (at 60 fps)\n", r.Dropped)
+	return sb.String()
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
@@ -0,0 +1,66 @@
+package stress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+func TestGenerator(t *testing.T) {
+	g, err := NewGenerator("text, progress,table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []protocol.MessageType{protocol.TypeText, protocol.TypeProgress, protocol.TypeTable, protocol.TypeText}
+	seen := map[string]bool{}
+	for i, wantType := range want {
+		msg := g.Next()
+		if msg.Type != wantType {
+			t.Errorf("message %d type = %q, want %q", i, msg.Type, wantType)
+		}
+		if seen[msg.ID] {
+			t.Errorf("duplicate ID %q", msg.ID)
+		}
+		seen[msg.ID] = true
+	}
+
+	if _, err := NewGenerator("text,form"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
+
+func TestStats(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := NewStats()
+	s.Sent("a", start)
+	s.Sent("b", start.Add(time.Millisecond))
+	s.Sent("c", start.Add(2*time.Millisecond))
+
+	s.Rendered([]string{"a"}, start.Add(5*time.Millisecond))
+	// b waited 3.5 frame intervals, so this frame dropped 3
+	s.Rendered([]string{"b", "c", "unknown"}, start.Add(time.Millisecond+FrameInterval*7/2))
+
+	r := s.Report()
+	if r.Sent != 3 || r.Rendered != 3 {
+		t.Errorf("sent %d rendered %d, want 3 and 3", r.Sent, r.Rendered)
+	}
+	if r.Dropped != 3 {
+		t.Errorf("Dropped = %d, want 3", r.Dropped)
+	}
+	if want := FrameInterval*7/2 - time.Millisecond; r.P50 != want {
+		t.Errorf("P50 = %s, want %s", r.P50, want)
+	}
+	if r.Max != FrameInterval*7/2 {
+		t.Errorf("Max = %s, want %s", r.Max, FrameInterval*7/2)
+	}
+}
+
+func TestStatsUnrendered(t *testing.T) {
+	s := NewStats()
+	s.Sent("a", time.Now())
+	r := s.Report()
+	if r.Sent != 1 || r.Rendered != 0 || r.Max != 0 {
+		t.Errorf("unexpected report %+v", r)
+	}
+}
@@ -0,0 +1,166 @@
+// Package telemetry keeps opt-in, anonymous usage statistics: how often each
+// feature is used and the signatures of crashes. Reports hold counts only,
+// never message contents, paths or panic messages, and are kept in a local
+// file the user can inspect with "agentui-tui telemetry show".
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Report is everything telemetry collects.
+type Report struct {
+	Version  string         `json:"version"`
+	OS       string         `json:"os"`
+	Arch     string         `json:"arch"`
+	Features map[string]int `json:"features"`
+	Crashes  map[string]int `json:"crashes"`
+}
+
+// Recorder counts feature use and crashes into a report file. A nil
+// Recorder is valid and records nothing, which is how telemetry is off.
+type Recorder struct {
+	path string
+
+	mu       sync.Mutex
+	report   Report
+	disabled bool
+}
+
+// Path returns the report file for the configuration file at configPath.
+func Path(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "telemetry.json")
+}
+
+// Open returns a recorder adding to the report at path, which is created
+// on the first Save if it does not exist yet.
+func Open(path, version string) (*Recorder, error) {
+	report, err := Load(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	report.Version = version
+	report.OS = runtime.GOOS
+	report.Arch = runtime.GOARCH
+	return &Recorder{path: path, report: report}, nil
+}
+
+// Load reads the report at path.
+func Load(path string) (Report, error) {
+	report := Report{Features: make(map[string]int), Crashes: make(map[string]int)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("%s: %w", path, err)
+	}
+	if report.Features == nil {
+		report.Features = make(map[string]int)
+	}
+	if report.Crashes == nil {
+		report.Crashes = make(map[string]int)
+	}
+	return report, nil
+}
+
+// Disable stops recording for the rest of the session and discards what
+// this session recorded. Agents use it to turn telemetry off for their
+// users.
+func (r *Recorder) Disable() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disabled = true
+}
+
+// Count records one use of a feature.
+func (r *Recorder) Count(feature string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.disabled {
+		r.report.Features[feature]++
+	}
+}
+
+// CatchPanic records the signature of a panic in progress, saves the
+// report and lets the panic continue. Defer it directly:
+//
+//	defer rec.CatchPanic()
+func (r *Recorder) CatchPanic() {
+	if r == nil {
+		return
+	}
+	v := recover()
+	if v == nil {
+		return
+	}
+	r.mu.Lock()
+	if !r.disabled {
+		r.report.Crashes[signature(v, 1)]++
+	}
+	r.mu.Unlock()
+	r.Save()
+	panic(v)
+}
+
+// Save writes the report, unless telemetry was disabled for the session.
+func (r *Recorder) Save() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.disabled {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// modulePrefix is trimmed from function names in crash signatures.
+const modulePrefix = "github.com/flight505/agentui/internal/"
+
+// signature identifies a panic by the type of its value and the innermost
+// functions of this module on the stack, leaving out anything that could
+// hold user data. skip is the number of frames to leave out, counted from
+// the caller of signature.
+func signature(v any, skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var funcs []string
+	for len(funcs) < 3 {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, modulePrefix); ok {
+			funcs = append(funcs, name)
+		}
+		if !more {
+			break
+		}
+	}
+	if len(funcs) == 0 {
+		funcs = append(funcs, "unknown")
+	}
+	return fmt.Sprintf("%T in %s", v, strings.Join(funcs, " < "))
+}
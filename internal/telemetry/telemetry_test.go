@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountAccumulates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.json")
+	for run := 0; run < 2; run++ {
+		rec, err := Open(path, "1.0.0")
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		rec.Count("type:markdown")
+		if err := rec.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	report, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if report.Features["type:markdown"] != 2 || report.Version != "1.0.0" || report.OS == "" {
+		t.Errorf("report = %+v, want two markdown uses across runs", report)
+	}
+}
+
+func TestDisable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.json")
+	rec, err := Open(path, "1.0.0")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	rec.Count("type:text")
+	rec.Disable()
+	rec.Count("type:text")
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := Load(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Load after a disabled session = %v, want no report written", err)
+	}
+
+	// Telemetry that is off is a nil recorder
+	var off *Recorder
+	off.Count("type:text")
+	off.Disable()
+	if err := off.Save(); err != nil {
+		t.Errorf("nil Save = %v, want nil", err)
+	}
+}
+
+func TestCatchPanic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.json")
+	rec, err := Open(path, "1.0.0")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	func() {
+		defer func() {
+			if v := recover(); v == nil {
+				t.Error("CatchPanic swallowed the panic")
+			}
+		}()
+		defer rec.CatchPanic()
+		var rows []string
+		_ = rows[len("secret user data")]
+	}()
+
+	report, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(report.Crashes) != 1 {
+		t.Fatalf("crashes = %v, want one signature", report.Crashes)
+	}
+	for sig := range report.Crashes {
+		if !strings.HasPrefix(sig, "runtime.boundsError in telemetry.TestCatchPanic") {
+			t.Errorf("signature = %q, want the panic type and the panicking function", sig)
+		}
+		if strings.Contains(sig, "16") || strings.Contains(sig, "secret") {
+			t.Errorf("signature %q leaks the panic message", sig)
+		}
+	}
+}
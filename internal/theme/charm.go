@@ -161,16 +161,11 @@ var CharmAuto = Theme{
 }
 
 func init() {
-	// Build styles from colors
-	CharmDark.Styles = BuildStyles(CharmDark.Colors)
-	CharmLight.Styles = BuildStyles(CharmLight.Colors)
-	CharmAuto.Styles = BuildStyles(CharmAuto.Colors)
-
-	// Register themes
+	// Register themes; styles are built when a theme is selected
 	Register(&CharmDark)
 	Register(&CharmLight)
 	Register(&CharmAuto)
 
 	// Set default theme
-	Current = CharmDark
+	SetTheme(CharmDark.ID)
 }
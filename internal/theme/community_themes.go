@@ -56,7 +56,6 @@ var CatppuccinMocha = Theme{
 	Author:      "Catppuccin",
 	Version:     "1.0.0",
 	Colors:      catppuccinMochaColors,
-	Styles:      BuildStyles(catppuccinMochaColors),
 }
 
 // Catppuccin Latte - light theme
@@ -93,7 +92,6 @@ var CatppuccinLatte = Theme{
 	Author:      "Catppuccin",
 	Version:     "1.0.0",
 	Colors:      catppuccinLatteColors,
-	Styles:      BuildStyles(catppuccinLatteColors),
 }
 
 // Dracula theme
@@ -126,7 +124,6 @@ var Dracula = Theme{
 	Author:      "Dracula Theme",
 	Version:     "1.0.0",
 	Colors:      draculaColors,
-	Styles:      BuildStyles(draculaColors),
 }
 
 // Nord theme
@@ -159,7 +156,6 @@ var Nord = Theme{
 	Author:      "Arctic Ice Studio",
 	Version:     "1.0.0",
 	Colors:      nordColors,
-	Styles:      BuildStyles(nordColors),
 }
 
 // Tokyo Night theme
@@ -192,5 +188,4 @@ var TokyoNight = Theme{
 	Author:      "Folke Lemaitre",
 	Version:     "1.0.0",
 	Colors:      tokyoNightColors,
-	Styles:      BuildStyles(tokyoNightColors),
 }
@@ -0,0 +1,208 @@
+package theme
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Minimum contrast ratios, after WCAG 2: text needs 4.5:1 and other
+// visible elements such as borders 3:1.
+const (
+	MinTextContrast   = 4.5
+	MinBorderContrast = 3.0
+)
+
+// ContrastIssue is a color pair in a theme whose contrast is too low.
+type ContrastIssue struct {
+	Element string  // e.g. "Styles.StatusBar" or "Colors.TextDim"
+	Mode    string  // "dark" or "light" for adaptive colors, else ""
+	Ratio   float64 // the contrast ratio found
+	Min     float64 // the ratio required
+}
+
+func (i ContrastIssue) String() string {
+	s := fmt.Sprintf("%s: contrast %.2f:1, want at least %.1f:1", i.Element, i.Ratio, i.Min)
+	if i.Mode != "" {
+		s += " (" + i.Mode + " background)"
+	}
+	return s
+}
+
+// paletteText are the colors used for text directly on the background,
+// outside of Styles.
+var paletteText = []string{
+	"Primary", "Text", "TextMuted", "TextDim", "Success", "Warning", "Error", "Info",
+}
+
+// CheckContrast reports every Styles entry whose text or border, and every
+// palette color used for text, has too little contrast with what it is
+// drawn on. Themes with adaptive colors are checked on dark and light
+// backgrounds. Colors that cannot be resolved, such as names, are skipped.
+func CheckContrast(t *Theme) []ContrastIssue {
+	styles := BuildStyles(t.Colors)
+	modes := []string{""}
+	if isAdaptive(t.Colors) {
+		modes = []string{"dark", "light"}
+	}
+
+	var issues []ContrastIssue
+	for _, mode := range modes {
+		dark := mode != "light"
+		check := func(element string, fg, bg lipgloss.TerminalColor, min float64) {
+			ratio, ok := Contrast(fg, bg, dark)
+			if ok && ratio < min {
+				issues = append(issues, ContrastIssue{Element: element, Mode: mode, Ratio: ratio, Min: min})
+			}
+		}
+
+		colors := reflect.ValueOf(t.Colors)
+		for _, name := range paletteText {
+			fg, _ := colors.FieldByName(name).Interface().(lipgloss.TerminalColor)
+			check("Colors."+name, fg, t.Colors.Background, MinTextContrast)
+		}
+
+		v := reflect.ValueOf(styles)
+		for i := 0; i < v.NumField(); i++ {
+			name := "Styles." + v.Type().Field(i).Name
+			style := v.Field(i).Interface().(lipgloss.Style)
+
+			bg := style.GetBackground()
+			if isNoColor(bg) {
+				bg = t.Colors.Background
+			}
+			fg := style.GetForeground()
+			if isNoColor(fg) {
+				fg = t.Colors.Text
+			}
+			check(name, fg, bg, MinTextContrast)
+
+			if border := style.GetBorderTopForeground(); !isNoColor(border) {
+				check(name+" border", border, t.Colors.Background, MinBorderContrast)
+			}
+		}
+	}
+	return issues
+}
+
+// Contrast returns the WCAG contrast ratio between two colors, from 1 to
+// 21, picking the dark or light variant of adaptive colors. ok is false if
+// either color cannot be resolved to RGB.
+func Contrast(fg, bg lipgloss.TerminalColor, dark bool) (ratio float64, ok bool) {
+	l1, ok1 := luminance(fg, dark)
+	l2, ok2 := luminance(bg, dark)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05), true
+}
+
+// luminance returns the relative luminance of c.
+func luminance(c lipgloss.TerminalColor, dark bool) (float64, bool) {
+	r, g, b, ok := resolveRGB(c, dark)
+	if !ok {
+		return 0, false
+	}
+	channel := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(r) + 0.7152*channel(g) + 0.0722*channel(b), true
+}
+
+// resolveRGB resolves hex and ANSI 256 colors.
+func resolveRGB(c lipgloss.TerminalColor, dark bool) (r, g, b uint8, ok bool) {
+	var s string
+	switch c := c.(type) {
+	case lipgloss.Color:
+		s = string(c)
+	case lipgloss.AdaptiveColor:
+		s = c.Light
+		if dark {
+			s = c.Dark
+		}
+	case lipgloss.CompleteColor:
+		s = c.TrueColor
+	case lipgloss.CompleteAdaptiveColor:
+		s = c.Light.TrueColor
+		if dark {
+			s = c.Dark.TrueColor
+		}
+	default:
+		return 0, 0, 0, false
+	}
+
+	if hex, found := strings.CutPrefix(s, "#"); found {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return 0, 0, 0, false
+		}
+		return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 255 {
+		return 0, 0, 0, false
+	}
+	r, g, b = ansiRGB(n)
+	return r, g, b, true
+}
+
+// ansi16 are the xterm defaults for the 16 basic colors.
+var ansi16 = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// ansiRGB returns the xterm RGB value of an ANSI 256 color.
+func ansiRGB(n int) (r, g, b uint8) {
+	switch {
+	case n < 16:
+		c := ansi16[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return level(n / 36), level(n / 6 % 6), level(n % 6)
+	default:
+		v := uint8(8 + (n-232)*10)
+		return v, v, v
+	}
+}
+
+func isNoColor(c lipgloss.TerminalColor) bool {
+	_, ok := c.(lipgloss.NoColor)
+	return c == nil || ok
+}
+
+// isAdaptive reports whether any color in the palette depends on the
+// terminal background.
+func isAdaptive(c Colors) bool {
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		switch v.Field(i).Interface().(type) {
+		case lipgloss.AdaptiveColor, lipgloss.CompleteAdaptiveColor:
+			return true
+		}
+	}
+	return false
+}
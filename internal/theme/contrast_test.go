@@ -0,0 +1,58 @@
+package theme
+
+import (
+	"math"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestContrast(t *testing.T) {
+	tests := []struct {
+		fg, bg lipgloss.TerminalColor
+		want   float64
+	}{
+		{lipgloss.Color("#000000"), lipgloss.Color("#ffffff"), 21},
+		{lipgloss.Color("#fff"), lipgloss.Color("#fff"), 1},
+		{lipgloss.Color("15"), lipgloss.Color("16"), 21}, // ANSI white on cube black
+		{lipgloss.Color("#777777"), lipgloss.Color("#ffffff"), 4.48},
+		{lipgloss.AdaptiveColor{Light: "#000", Dark: "#fff"}, lipgloss.Color("#000"), 21},
+	}
+	for _, tt := range tests {
+		got, ok := Contrast(tt.fg, tt.bg, true)
+		if !ok || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("Contrast(%v, %v) = %.2f, %v; want %.2f", tt.fg, tt.bg, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := Contrast(lipgloss.Color("red"), lipgloss.Color("#000"), true); ok {
+		t.Error("Contrast resolved a color name")
+	}
+}
+
+func TestHighContrastThemes(t *testing.T) {
+	for _, th := range []*Theme{&HighContrastDark, &HighContrastLight} {
+		if Available[th.ID] != th {
+			t.Errorf("%s is not registered", th.ID)
+		}
+		for _, issue := range CheckContrast(th) {
+			t.Errorf("%s: %s", th.ID, issue)
+		}
+	}
+}
+
+func TestCheckContrastFindsIssues(t *testing.T) {
+	th := HighContrastDark
+	th.Colors.TextDim = lipgloss.Color("#333333")
+
+	found := make(map[string]bool)
+	for _, issue := range CheckContrast(&th) {
+		found[issue.Element] = true
+	}
+	// Dim text, and the borders drawn in it
+	for _, want := range []string{"Colors.TextDim", "Styles.InputField border"} {
+		if !found[want] {
+			t.Errorf("CheckContrast missed %s; found %v", want, found)
+		}
+	}
+}
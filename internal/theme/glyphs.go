@@ -0,0 +1,199 @@
+package theme
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Glyph profiles for SetGlyphs and Theme.Glyphs.
+const (
+	GlyphsUnicode = "unicode"
+	GlyphsASCII   = "ascii"
+)
+
+// GlyphSet holds the symbols the UI draws, so that terminals, fonts and
+// braille displays that render emoji and uncommon Unicode poorly can use
+// plain ASCII instead.
+type GlyphSet struct {
+	// Message prefixes, the cursor after text still streaming, and the
+	// prompt before each line of the input
+	User      string
+	Assistant string
+	Cursor    string
+	Prompt    string
+
+	// Status icons
+	Success string
+	Warning string
+	Error   string
+	Info    string
+
+	// Progress steps and connection state: done, running or connected,
+	// and pending or waiting
+	Filled string
+	Empty  string
+	Bullet string
+
+	// Focus markers, aligned with each other, and the marker of a
+	// collapsed message
+	Focus string
+	Blur  string
+	Fold  string
+
+	// Form controls
+	Check string // inside a checked checkbox's brackets
+	Mask  rune   // echoed for each character of a secret
+
+	// Prompt and message decorations
+	Lock       string
+	Attachment string
+	Goodbye    string
+
+	// Progress bars
+	BarFull  string
+	BarEmpty string
+
+	// Key hints and separators
+	Up        string
+	Down      string
+	Separator string
+	Ellipsis  string
+
+	// Borders of boxes and of tables
+	Border lipgloss.Border
+	Table  lipgloss.Border
+}
+
+// UnicodeGlyphs is the default profile.
+var UnicodeGlyphs = GlyphSet{
+	User:      "👤 ",
+	Assistant: "🤖 ",
+	Cursor:    "▌",
+	Prompt:    "┃ ",
+
+	Success: "✓",
+	Warning: "⚠",
+	Error:   "✗",
+	Info:    "ℹ",
+
+	Filled: "●",
+	Empty:  "○",
+	Bullet: "•",
+
+	Focus: "▸ ",
+	Blur:  "  ",
+	Fold:  "▸ ",
+
+	Check: "✓",
+	Mask:  '•',
+
+	Lock:       "🔒 ",
+	Attachment: "📎 ",
+	Goodbye:    " 👋",
+
+	BarFull:  "█",
+	BarEmpty: "░",
+
+	Up:        "↑",
+	Down:      "↓",
+	Separator: " · ",
+	Ellipsis:  "…",
+
+	Border: lipgloss.RoundedBorder(),
+	Table:  lipgloss.NormalBorder(),
+}
+
+// asciiBorder draws boxes with plus signs, dashes and pipes.
+var asciiBorder = lipgloss.Border{
+	Top: "-", Bottom: "-", Left: "|", Right: "|",
+	TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+	MiddleLeft: "+", MiddleRight: "+", Middle: "+", MiddleTop: "+", MiddleBottom: "+",
+}
+
+// ASCIIGlyphs uses only printable ASCII.
+var ASCIIGlyphs = GlyphSet{
+	User:      "> ",
+	Assistant: "< ",
+	Cursor:    "_",
+	Prompt:    "| ",
+
+	Success: "+",
+	Warning: "!",
+	Error:   "x",
+	Info:    "i",
+
+	Filled: "*",
+	Empty:  "o",
+	Bullet: "-",
+
+	Focus: "> ",
+	Blur:  "  ",
+	Fold:  "+ ",
+
+	Check: "x",
+	Mask:  '*',
+
+	Lock:       "",
+	Attachment: "+ ",
+	Goodbye:    "",
+
+	BarFull:  "#",
+	BarEmpty: "-",
+
+	Up:        "^",
+	Down:      "v",
+	Separator: " - ",
+	Ellipsis:  "...",
+
+	Border: asciiBorder,
+	Table:  asciiBorder,
+}
+
+var glyphProfiles = map[string]*GlyphSet{
+	GlyphsUnicode: &UnicodeGlyphs,
+	GlyphsASCII:   &ASCIIGlyphs,
+}
+
+// Glyphs is the active glyph set, chosen by SetGlyphs or else by the
+// current theme.
+var Glyphs = UnicodeGlyphs
+
+// glyphProfile overrides the theme's profile when set.
+var glyphProfile string
+
+// ValidGlyphs reports whether profile names a glyph profile.
+func ValidGlyphs(profile string) bool {
+	_, ok := glyphProfiles[profile]
+	return ok
+}
+
+// SetGlyphs selects a glyph profile for every theme, overriding the one a
+// theme asks for. An empty profile follows the theme again.
+func SetGlyphs(profile string) error {
+	if profile != "" && !ValidGlyphs(profile) {
+		return fmt.Errorf("unknown glyph profile %q (want %s or %s)", profile, GlyphsUnicode, GlyphsASCII)
+	}
+	glyphProfile = profile
+	if t, ok := Available[Current.ID]; ok {
+		SetTheme(t.ID)
+	}
+	return nil
+}
+
+// applyGlyphs selects the glyphs for Current, rebuilding its styles if
+// they were built with other borders.
+func applyGlyphs() {
+	profile := glyphProfile
+	if profile == "" {
+		profile = Current.Glyphs
+	}
+	set, ok := glyphProfiles[profile]
+	if !ok {
+		set = &UnicodeGlyphs
+	}
+	Glyphs = *set
+	if Current.Styles.Border.GetBorderStyle() != Glyphs.Border {
+		Current.Styles = BuildStyles(Current.Colors)
+	}
+}
@@ -0,0 +1,92 @@
+package theme
+
+import (
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestSetGlyphs(t *testing.T) {
+	defer SetTheme(CharmDark.ID)
+	defer SetGlyphs("")
+
+	if err := SetGlyphs("braille"); err == nil {
+		t.Error("SetGlyphs accepted an unknown profile")
+	}
+
+	SetTheme(CharmDark.ID)
+	if err := SetGlyphs(GlyphsASCII); err != nil {
+		t.Fatalf("SetGlyphs failed: %v", err)
+	}
+	if Glyphs != ASCIIGlyphs {
+		t.Error("the ASCII glyphs were not selected")
+	}
+	if Current.Styles.Border.GetBorderStyle() != ASCIIGlyphs.Border {
+		t.Error("the styles were not rebuilt with ASCII borders")
+	}
+
+	// It stays on when switching themes
+	SetTheme(CharmLight.ID)
+	if Glyphs != ASCIIGlyphs || Current.Styles.FormContainer.GetBorderStyle() != ASCIIGlyphs.Border {
+		t.Error("the profile was lost when switching themes")
+	}
+
+	SetGlyphs("")
+	if Glyphs != UnicodeGlyphs || Current.Styles.Border.GetBorderStyle() != UnicodeGlyphs.Border {
+		t.Error("an empty profile did not restore the theme's glyphs")
+	}
+}
+
+func TestThemeGlyphs(t *testing.T) {
+	defer SetTheme(CharmDark.ID)
+	defer delete(Available, "plain")
+
+	tj := ThemeJSON{ID: "plain", Name: "Plain", Glyphs: GlyphsASCII}
+	theme, err := tj.ToTheme()
+	if err != nil {
+		t.Fatalf("ToTheme failed: %v", err)
+	}
+	Register(theme)
+
+	SetTheme("plain")
+	if Glyphs != ASCIIGlyphs {
+		t.Error("the theme's glyph profile was not applied")
+	}
+	SetGlyphs(GlyphsUnicode)
+	if Glyphs != UnicodeGlyphs {
+		t.Error("SetGlyphs did not override the theme's profile")
+	}
+	SetGlyphs("")
+	if Glyphs != ASCIIGlyphs {
+		t.Error("an empty profile did not follow the theme again")
+	}
+
+	tj.Glyphs = "emoji"
+	if _, err := tj.ToTheme(); err == nil {
+		t.Error("ToTheme accepted an unknown glyph profile")
+	}
+}
+
+func TestASCIIGlyphs(t *testing.T) {
+	v := reflect.ValueOf(ASCIIGlyphs)
+	var check func(name string, v reflect.Value)
+	check = func(name string, v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				check(name+"."+v.Type().Field(i).Name, v.Field(i))
+			}
+		case reflect.String:
+			for _, r := range v.String() {
+				if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+					t.Errorf("%s contains %q", name, r)
+				}
+			}
+		case reflect.Int32:
+			if r := rune(v.Int()); r > unicode.MaxASCII || !unicode.IsPrint(r) {
+				t.Errorf("%s is %q", name, r)
+			}
+		}
+	}
+	check("ASCIIGlyphs", v)
+}
@@ -0,0 +1,76 @@
+package theme
+
+import "github.com/charmbracelet/lipgloss"
+
+// HighContrastDark is an accessibility theme: every text color has at
+// least 4.5:1 contrast with what it is drawn on, and borders at least 3:1.
+// See CheckContrast.
+var HighContrastDark = Theme{
+	ID:          "high-contrast-dark",
+	Name:        "High Contrast Dark",
+	Description: "Maximum legibility on a black background",
+	Author:      "AgentUI Team",
+	Version:     "1.0.0",
+	Colors: Colors{
+		// Core - pure black, with surfaces just distinct enough to see
+		Primary:    lipgloss.Color("#ffd700"), // Gold
+		Secondary:  lipgloss.Color("#00e5ff"), // Cyan
+		Background: lipgloss.Color("#000000"),
+		Surface:    lipgloss.Color("#101010"),
+		Overlay:    lipgloss.Color("#1c1c1c"),
+
+		// Text - even dim text stays well above 4.5:1
+		Text:      lipgloss.Color("#ffffff"),
+		TextMuted: lipgloss.Color("#e0e0e0"),
+		TextDim:   lipgloss.Color("#b8b8b8"),
+
+		// Semantic colors
+		Success: lipgloss.Color("#3dff8f"),
+		Warning: lipgloss.Color("#ffb020"),
+		Error:   lipgloss.Color("#ff7070"),
+		Info:    lipgloss.Color("#6cd4ff"),
+
+		// Accents
+		Accent1: lipgloss.Color("#ff8cff"),
+		Accent2: lipgloss.Color("#00e5ff"),
+		Accent3: lipgloss.Color("#3dff8f"),
+	},
+}
+
+// HighContrastLight is the light counterpart of HighContrastDark.
+var HighContrastLight = Theme{
+	ID:          "high-contrast-light",
+	Name:        "High Contrast Light",
+	Description: "Maximum legibility on a white background",
+	Author:      "AgentUI Team",
+	Version:     "1.0.0",
+	Colors: Colors{
+		// Core - pure white, with faint gray surfaces
+		Primary:    lipgloss.Color("#0033b3"), // Deep blue
+		Secondary:  lipgloss.Color("#6a00a8"), // Deep violet
+		Background: lipgloss.Color("#ffffff"),
+		Surface:    lipgloss.Color("#f2f2f2"),
+		Overlay:    lipgloss.Color("#e6e6e6"),
+
+		// Text
+		Text:      lipgloss.Color("#000000"),
+		TextMuted: lipgloss.Color("#262626"),
+		TextDim:   lipgloss.Color("#4a4a4a"),
+
+		// Semantic colors - darkened until they read as text
+		Success: lipgloss.Color("#00622e"),
+		Warning: lipgloss.Color("#8a4600"),
+		Error:   lipgloss.Color("#b00020"),
+		Info:    lipgloss.Color("#00528a"),
+
+		// Accents
+		Accent1: lipgloss.Color("#a3007d"),
+		Accent2: lipgloss.Color("#6a00a8"),
+		Accent3: lipgloss.Color("#00622e"),
+	},
+}
+
+func init() {
+	Register(&HighContrastDark)
+	Register(&HighContrastLight)
+}
@@ -19,6 +19,7 @@ type ThemeJSON struct {
 	Author      string     `json:"author,omitempty"`
 	Version     string     `json:"version,omitempty"`
 	Colors      ColorsJSON `json:"colors"`
+	Glyphs      string     `json:"glyphs,omitempty"` // "unicode" or "ascii"
 }
 
 // ColorsJSON represents color definitions in JSON format.
@@ -67,6 +68,10 @@ func LoadThemeFromJSON(data []byte) (*Theme, error) {
 
 // ToTheme converts a ThemeJSON to a Theme.
 func (tj *ThemeJSON) ToTheme() (*Theme, error) {
+	if tj.Glyphs != "" && !ValidGlyphs(tj.Glyphs) {
+		return nil, fmt.Errorf("unknown glyph profile %q (want %s or %s)", tj.Glyphs, GlyphsUnicode, GlyphsASCII)
+	}
+
 	colors := Colors{
 		Primary:    parseColor(tj.Colors.Primary),
 		Secondary:  parseColor(tj.Colors.Secondary),
@@ -92,7 +97,9 @@ func (tj *ThemeJSON) ToTheme() (*Theme, error) {
 		Author:      tj.Author,
 		Version:     tj.Version,
 		Colors:      colors,
+		Glyphs:      tj.Glyphs,
 		Styles:      BuildStyles(colors),
+		stylesBuilt: true,
 	}, nil
 }
 
@@ -178,6 +185,7 @@ func ExportThemeToJSON(t *Theme) ([]byte, error) {
 		Description: t.Description,
 		Author:      t.Author,
 		Version:     t.Version,
+		Glyphs:      t.Glyphs,
 		Colors: ColorsJSON{
 			Primary:    colorToString(t.Colors.Primary),
 			Secondary:  colorToString(t.Colors.Secondary),
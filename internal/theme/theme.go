@@ -16,7 +16,13 @@ type Theme struct {
 
 	// Visual
 	Colors Colors
-	Styles Styles
+	Styles Styles // built from Colors when the theme is first selected
+
+	// Glyphs is the glyph profile the theme is designed for, e.g.
+	// GlyphsASCII; empty uses Unicode. See SetGlyphs.
+	Glyphs string
+
+	stylesBuilt bool
 }
 
 // Colors defines the color palette using TerminalColor interface.
@@ -94,6 +100,10 @@ type Styles struct {
 	ProgressBar       lipgloss.Style
 	ProgressComplete  lipgloss.Style
 
+	// Focus - the item in a list or menu that keyboard input goes to,
+	// marked with Glyphs.Focus as well so it does not rely on color alone
+	Focused lipgloss.Style
+
 	// Misc
 	Spinner   lipgloss.Style
 	Border    lipgloss.Style
@@ -104,18 +114,37 @@ type Styles struct {
 // Current holds the active theme (set to CharmDark by default in charm.go init)
 var Current Theme
 
+// Hints is whether components show the line of keyboard hints under
+// them. Users who know the keys can turn it off; the help overlay still
+// lists them.
+var Hints = true
+
 // Available lists all available themes.
 var Available = make(map[string]*Theme)
 
-// SetTheme changes the current theme.
+// SetTheme changes the current theme, building its styles the first time
+// it is selected. Its glyphs and the color vision overlay, if any, are
+// applied on top.
 func SetTheme(name string) bool {
 	if theme, ok := Available[name]; ok {
+		theme.buildStyles()
 		Current = *theme
+		applyGlyphs()
+		applyColorVision()
 		return true
 	}
 	return false
 }
 
+// buildStyles builds the theme's styles from its colors unless they have
+// been built already. Themes only pay for styles once they are used.
+func (t *Theme) buildStyles() {
+	if !t.stylesBuilt {
+		t.Styles = BuildStyles(t.Colors)
+		t.stylesBuilt = true
+	}
+}
+
 // Register adds a theme to the available themes.
 func Register(t *Theme) {
 	Available[t.ID] = t
@@ -125,7 +154,7 @@ func Register(t *Theme) {
 // Uses Charm aesthetic: rounded borders, clean spacing, high contrast.
 func BuildStyles(c Colors) Styles {
 	// Charm consistently uses rounded borders
-	border := lipgloss.RoundedBorder()
+	border := Glyphs.Border
 
 	return Styles{
 		// Header/Footer
@@ -217,6 +246,7 @@ func BuildStyles(c Colors) Styles {
 		FormButtonFocus: lipgloss.NewStyle().
 			Background(c.Primary).
 			Foreground(c.Background).
+			Bold(true).
 			Border(border).
 			BorderForeground(c.Primary).
 			Padding(0, 2).
@@ -298,6 +328,12 @@ func BuildStyles(c Colors) Styles {
 		ProgressComplete: lipgloss.NewStyle().
 			Foreground(c.Success),
 
+		// Focus
+		Focused: lipgloss.NewStyle().
+			Background(c.Surface).
+			Foreground(c.Primary).
+			Bold(true),
+
 		// Misc
 		Spinner: lipgloss.NewStyle().
 			Foreground(c.Primary),
@@ -166,3 +166,21 @@ func TestThemeMetadata(t *testing.T) {
 		t.Errorf("CharmAuto ID = %s, want 'charm-auto'", CharmAuto.ID)
 	}
 }
+
+func TestStylesBuiltOnSelect(t *testing.T) {
+	RegisterCommunityThemes()
+	defer SetTheme("charm-dark")
+
+	if Available["nord"].stylesBuilt {
+		t.Fatal("nord styles built before the theme was selected")
+	}
+	if !SetTheme("nord") {
+		t.Fatal("SetTheme failed for 'nord'")
+	}
+	if !Available["nord"].stylesBuilt {
+		t.Error("nord styles not built after SetTheme")
+	}
+	if Current.Styles.Header.GetForeground() != nordColors.Primary {
+		t.Errorf("Header foreground = %v, want %v", Current.Styles.Header.GetForeground(), nordColors.Primary)
+	}
+}
@@ -0,0 +1,61 @@
+package theme
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Color vision modes for SetColorVision.
+const (
+	VisionDefault      = ""
+	VisionDeuteranopia = "deuteranopia" // green-weak
+	VisionProtanopia   = "protanopia"   // red-weak
+)
+
+// visionPalettes replace the semantic colors of any theme so that success,
+// warning and error stay apart for red-green color blindness: success turns
+// blue, and warning and error differ in lightness as well as hue. Colors
+// adapt to the terminal background, since themes are drawn on it.
+var visionPalettes = map[string]struct{ success, warning, err lipgloss.TerminalColor }{
+	VisionDeuteranopia: {
+		success: lipgloss.AdaptiveColor{Light: "#005A9C", Dark: "#56B4E9"}, // blue
+		warning: lipgloss.AdaptiveColor{Light: "#6E5A00", Dark: "#F0E442"}, // yellow
+		err:     lipgloss.AdaptiveColor{Light: "#A83A00", Dark: "#F07830"}, // vermillion
+	},
+	VisionProtanopia: {
+		// Reds look dark to protanopes, so errors are a brighter orange
+		success: lipgloss.AdaptiveColor{Light: "#005A9C", Dark: "#56B4E9"}, // blue
+		warning: lipgloss.AdaptiveColor{Light: "#6E5A00", Dark: "#F0E442"}, // yellow
+		err:     lipgloss.AdaptiveColor{Light: "#8F3D00", Dark: "#FFA040"}, // orange
+	},
+}
+
+// colorVision is applied on top of every theme selected with SetTheme.
+var colorVision string
+
+// SetColorVision overlays color blind safe semantic colors on the current
+// theme and every theme selected after it. VisionDefault keeps each theme's
+// own colors.
+func SetColorVision(mode string) error {
+	if _, ok := visionPalettes[mode]; !ok && mode != VisionDefault {
+		return fmt.Errorf("unknown color vision %q (want %s or %s)", mode, VisionDeuteranopia, VisionProtanopia)
+	}
+	colorVision = mode
+	if t, ok := Available[Current.ID]; ok {
+		SetTheme(t.ID)
+	}
+	return nil
+}
+
+// applyColorVision overlays the color vision palette on Current.
+func applyColorVision() {
+	palette, ok := visionPalettes[colorVision]
+	if !ok {
+		return
+	}
+	Current.Colors.Success = palette.success
+	Current.Colors.Warning = palette.warning
+	Current.Colors.Error = palette.err
+	Current.Styles = BuildStyles(Current.Colors)
+}
@@ -0,0 +1,55 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSetColorVision(t *testing.T) {
+	defer SetTheme(CharmDark.ID)
+	defer SetColorVision(VisionDefault)
+
+	if err := SetColorVision("tritanopia"); err == nil {
+		t.Error("SetColorVision accepted an unknown mode")
+	}
+
+	SetTheme(CharmDark.ID)
+	if err := SetColorVision(VisionDeuteranopia); err != nil {
+		t.Fatalf("SetColorVision failed: %v", err)
+	}
+	palette := visionPalettes[VisionDeuteranopia]
+	if Current.Colors.Success != palette.success || Current.Colors.Error != palette.err {
+		t.Error("the overlay was not applied to the current theme")
+	}
+
+	// It stays on when switching themes, without changing the themes
+	SetTheme(CharmLight.ID)
+	if Current.Colors.Warning != palette.warning {
+		t.Error("the overlay was lost when switching themes")
+	}
+	if CharmLight.Colors.Warning == palette.warning {
+		t.Error("the overlay changed the registered theme")
+	}
+
+	SetColorVision(VisionDefault)
+	if Current.Colors.Warning != CharmLight.Colors.Warning {
+		t.Error("VisionDefault did not restore the theme's colors")
+	}
+}
+
+func TestVisionPaletteContrast(t *testing.T) {
+	for mode, palette := range visionPalettes {
+		for _, bg := range []struct {
+			theme *Theme
+			dark  bool
+		}{{&CharmDark, true}, {&CharmLight, false}} {
+			for name, c := range map[string]lipgloss.TerminalColor{"success": palette.success, "warning": palette.warning, "error": palette.err} {
+				ratio, ok := Contrast(c, bg.theme.Colors.Background, bg.dark)
+				if !ok || ratio < MinTextContrast {
+					t.Errorf("%s %s on %s: contrast %.2f", mode, name, bg.theme.ID, ratio)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// autocompleteVisible is the number of suggestions shown at once.
+const autocompleteVisible = 8
+
+// Autocomplete is a text prompt with a filtered suggestion dropdown.
+// The user can pick a suggestion or submit free text.
+type Autocomplete struct {
+	Label       string
+	Suggestions []string
+
+	input     textinput.Model
+	filtered  []string
+	cursor    int // index into filtered, -1 when no suggestion is highlighted
+	offset    int // first visible index into filtered
+	responded bool
+	cancelled bool
+	width     int
+}
+
+// NewAutocomplete creates a new autocomplete prompt.
+func NewAutocomplete(payload *protocol.AutocompletePayload) *Autocomplete {
+	ti := textinput.New()
+	ti.Placeholder = payload.Placeholder
+	ti.CharLimit = 512
+	ti.SetValue(payload.Default)
+	ti.Focus()
+
+	a := &Autocomplete{
+		Label:       payload.Label,
+		Suggestions: payload.Suggestions,
+		input:       ti,
+	}
+	a.refilter()
+	return a
+}
+
+// SetWidth sets the prompt width.
+func (a *Autocomplete) SetWidth(width int) {
+	a.width = width
+	a.input.Width = min(56, width-10)
+}
+
+// SetSuggestions replaces the suggestion list.
+func (a *Autocomplete) SetSuggestions(suggestions []string) {
+	a.Suggestions = suggestions
+	a.refilter()
+}
+
+// AppendSuggestions adds suggestions to the end of the list.
+func (a *Autocomplete) AppendSuggestions(suggestions []string) {
+	a.Suggestions = append(a.Suggestions, suggestions...)
+	a.refilter()
+}
+
+// refilter recomputes the filtered list from the current input.
+// Prefix matches are listed before substring matches.
+func (a *Autocomplete) refilter() {
+	query := strings.ToLower(a.input.Value())
+
+	var prefix, contains []string
+	for _, s := range a.Suggestions {
+		lower := strings.ToLower(s)
+		switch {
+		case strings.HasPrefix(lower, query):
+			prefix = append(prefix, s)
+		case strings.Contains(lower, query):
+			contains = append(contains, s)
+		}
+	}
+	a.filtered = append(prefix, contains...)
+
+	if len(a.filtered) == 0 {
+		a.cursor = -1
+	} else if a.cursor >= len(a.filtered) {
+		a.cursor = len(a.filtered) - 1
+	}
+	a.clampOffset()
+}
+
+func (a *Autocomplete) clampOffset() {
+	if a.cursor < a.offset {
+		a.offset = max(a.cursor, 0)
+	}
+	if a.cursor >= a.offset+autocompleteVisible {
+		a.offset = a.cursor - autocompleteVisible + 1
+	}
+	if a.offset > len(a.filtered)-1 {
+		a.offset = max(len(a.filtered)-autocompleteVisible, 0)
+	}
+}
+
+// Update handles input for the prompt.
+func (a *Autocomplete) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "down", "ctrl+n":
+			if a.cursor < len(a.filtered)-1 {
+				a.cursor++
+				a.clampOffset()
+			}
+			return nil
+		case "up", "ctrl+p":
+			if a.cursor > -1 {
+				a.cursor--
+				a.clampOffset()
+			}
+			return nil
+		case "tab":
+			if sel := a.highlighted(); sel != "" {
+				a.input.SetValue(sel)
+				a.input.CursorEnd()
+				a.refilter()
+			}
+			return nil
+		case "enter":
+			a.responded = true
+			return nil
+		case "esc":
+			a.cancelled = true
+			a.responded = true
+			return nil
+		}
+	}
+
+	before := a.input.Value()
+	var cmd tea.Cmd
+	a.input, cmd = a.input.Update(msg)
+	if a.input.Value() != before {
+		a.cursor = -1
+		a.offset = 0
+		a.refilter()
+	}
+	return cmd
+}
+
+func (a *Autocomplete) highlighted() string {
+	if a.cursor >= 0 && a.cursor < len(a.filtered) {
+		return a.filtered[a.cursor]
+	}
+	return ""
+}
+
+// HasResponded returns true if the user submitted or cancelled.
+func (a *Autocomplete) HasResponded() bool {
+	return a.responded
+}
+
+// IsCancelled returns true if the user cancelled.
+func (a *Autocomplete) IsCancelled() bool {
+	return a.cancelled
+}
+
+// Typed returns the text currently in the input.
+func (a *Autocomplete) Typed() string {
+	return a.input.Value()
+}
+
+// Value returns the highlighted suggestion, or the typed text if none is highlighted.
+func (a *Autocomplete) Value() string {
+	if a.cancelled {
+		return ""
+	}
+	if sel := a.highlighted(); sel != "" {
+		return sel
+	}
+	return a.input.Value()
+}
+
+// View renders the prompt and dropdown.
+func (a *Autocomplete) View() string {
+	styles := theme.Current.Styles
+	colors := theme.Current.Colors
+	var sb strings.Builder
+
+	if a.Label != "" {
+		sb.WriteString(styles.FormTitle.Render(a.Label))
+		sb.WriteString("\n\n")
+	}
+
+	inputStyle := lipgloss.NewStyle().
+		Background(colors.Surface).
+		Foreground(colors.Text).
+		Padding(0, 1).
+		Border(theme.Glyphs.Border).
+		BorderForeground(colors.Primary)
+	sb.WriteString(inputStyle.Render(a.input.View()))
+	sb.WriteString("\n")
+
+	end := min(a.offset+autocompleteVisible, len(a.filtered))
+	for i := a.offset; i < end; i++ {
+		if i == a.cursor {
+			sb.WriteString(styles.Focused.Padding(0, 1).Render(theme.Glyphs.Focus + a.filtered[i]))
+		} else {
+			style := lipgloss.NewStyle().Foreground(colors.Text).Padding(0, 1)
+			sb.WriteString(style.Render(theme.Glyphs.Blur + a.filtered[i]))
+		}
+		sb.WriteString("\n")
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	if len(a.filtered) == 0 && len(a.Suggestions) > 0 {
+		sb.WriteString(mutedStyle.Render("  No matches — Enter submits typed text"))
+		sb.WriteString("\n")
+	} else if len(a.filtered) > autocompleteVisible {
+		sb.WriteString(mutedStyle.Render(fmt.Sprintf("  %d of %d matches", end-a.offset, len(a.filtered))))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(HintBar(AutocompleteKeys()))
+
+	containerStyle := styles.FormContainer
+	if a.width > 0 {
+		containerStyle = containerStyle.Width(min(64, a.width-4))
+	} else {
+		containerStyle = containerStyle.Width(64)
+	}
+
+	return containerStyle.Render(sb.String())
+}
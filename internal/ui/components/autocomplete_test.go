@@ -0,0 +1,65 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+func TestAutocompleteFiltering(t *testing.T) {
+	a := NewAutocomplete(&protocol.AutocompletePayload{
+		Label:       "Branch",
+		Suggestions: []string{"main", "feature/login", "fix-main-build", "release"},
+	})
+
+	for _, r := range "main" {
+		a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+
+	want := []string{"main", "fix-main-build"}
+	if !reflect.DeepEqual(a.filtered, want) {
+		t.Errorf("filtered = %v, want %v (prefix matches first)", a.filtered, want)
+	}
+}
+
+func TestAutocompleteValue(t *testing.T) {
+	a := NewAutocomplete(&protocol.AutocompletePayload{
+		Suggestions: []string{"gpt-4o", "claude-sonnet"},
+	})
+
+	// Nothing highlighted: typed text is returned
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("custom")})
+	a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !a.HasResponded() || a.Value() != "custom" {
+		t.Errorf("Value() = %q, want typed text 'custom'", a.Value())
+	}
+
+	// Highlighted suggestion wins over typed text
+	b := NewAutocomplete(&protocol.AutocompletePayload{
+		Suggestions: []string{"gpt-4o", "claude-sonnet"},
+	})
+	b.Update(tea.KeyMsg{Type: tea.KeyDown})
+	b.Update(tea.KeyMsg{Type: tea.KeyDown})
+	b.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if b.Value() != "claude-sonnet" {
+		t.Errorf("Value() = %q, want 'claude-sonnet'", b.Value())
+	}
+}
+
+func TestAutocompleteStreamedSuggestions(t *testing.T) {
+	a := NewAutocomplete(&protocol.AutocompletePayload{})
+	a.AppendSuggestions([]string{"one"})
+	a.AppendSuggestions([]string{"two", "three"})
+
+	if len(a.filtered) != 3 {
+		t.Errorf("filtered has %d entries, want 3", len(a.filtered))
+	}
+
+	a.SetSuggestions([]string{"only"})
+	if !reflect.DeepEqual(a.filtered, []string{"only"}) {
+		t.Errorf("filtered = %v after SetSuggestions", a.filtered)
+	}
+}
@@ -0,0 +1,288 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// maxAlignCells bounds the work of aligning two texts line by line. Texts
+// with more pairs of lines than this are compared line for line instead.
+const maxAlignCells = 1 << 22
+
+// compareRow is one row of a comparison: a line of either text, or of
+// both. Line numbers are 1-based, and 0 on a side without a line.
+type compareRow struct {
+	left, right     string
+	leftNo, rightNo int
+}
+
+// changed reports whether the row differs between the two sides.
+func (r compareRow) changed() bool {
+	return r.leftNo == 0 || r.rightNo == 0 || r.left != r.right
+}
+
+// Comparison shows two texts side by side, such as two versions of an
+// artifact, with the lines that differ colored as in a diff. Both sides
+// scroll together.
+type Comparison struct {
+	leftTitle, rightTitle string
+
+	rows   []compareRow
+	offset int
+	closed bool
+	width  int
+	height int
+}
+
+// NewComparison compares left with right.
+func NewComparison(leftTitle, left, rightTitle, right string) *Comparison {
+	return &Comparison{
+		leftTitle:  leftTitle,
+		rightTitle: rightTitle,
+		rows:       alignLines(splitLines(left), splitLines(right)),
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(s, "\n"), "\n")
+}
+
+// alignLines lines up a and b along their longest common subsequence of
+// lines. A run of removed lines followed by added ones shares rows, so a
+// changed line is shown beside what replaced it.
+func alignLines(a, b []string) []compareRow {
+	if len(a)*len(b) > maxAlignCells {
+		rows := make([]compareRow, max(len(a), len(b)))
+		for i := range rows {
+			if i < len(a) {
+				rows[i].left, rows[i].leftNo = a[i], i+1
+			}
+			if i < len(b) {
+				rows[i].right, rows[i].rightNo = b[i], i+1
+			}
+		}
+		return rows
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var rows []compareRow
+	var removed, added []compareRow
+	flush := func() {
+		for k := range max(len(removed), len(added)) {
+			var row compareRow
+			if k < len(removed) {
+				row.left, row.leftNo = removed[k].left, removed[k].leftNo
+			}
+			if k < len(added) {
+				row.right, row.rightNo = added[k].right, added[k].rightNo
+			}
+			rows = append(rows, row)
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			rows = append(rows, compareRow{left: a[i], right: b[j], leftNo: i + 1, rightNo: j + 1})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			removed = append(removed, compareRow{left: a[i], leftNo: i + 1})
+			i++
+		default:
+			added = append(added, compareRow{right: b[j], rightNo: j + 1})
+			j++
+		}
+	}
+	flush()
+	return rows
+}
+
+// SetSize sets the size of the comparison.
+func (c *Comparison) SetSize(width, height int) {
+	c.width = width
+	c.height = height
+	c.scrollTo(c.offset)
+}
+
+// Update handles scrolling keys.
+func (c *Comparison) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		c.scrollTo(c.offset - 1)
+	case "down", "j":
+		c.scrollTo(c.offset + 1)
+	case "pgup":
+		c.scrollTo(c.offset - c.visibleRows())
+	case "pgdown", " ":
+		c.scrollTo(c.offset + c.visibleRows())
+	case "home", "g":
+		c.scrollTo(0)
+	case "end", "G":
+		c.scrollTo(len(c.rows))
+	case "n":
+		c.jumpChange(1)
+	case "p", "N":
+		c.jumpChange(-1)
+	case "esc", "q", "enter":
+		c.closed = true
+	}
+	return nil
+}
+
+// jumpChange scrolls to the start of the next run of changed rows below
+// the top row, or the previous one above it if step is -1.
+func (c *Comparison) jumpChange(step int) {
+	for i := c.offset + step; i >= 0 && i < len(c.rows); i += step {
+		if c.rows[i].changed() && (i == 0 || !c.rows[i-1].changed()) {
+			c.scrollTo(i)
+			return
+		}
+	}
+}
+
+func (c *Comparison) scrollTo(offset int) {
+	c.offset = max(min(offset, len(c.rows)-c.visibleRows()), 0)
+}
+
+// visibleRows is how many rows fit between the titles and the hint.
+func (c *Comparison) visibleRows() int {
+	if c.height <= 0 {
+		return 20
+	}
+	return max(c.height-6, 1)
+}
+
+// Closed returns true once the user has closed the comparison.
+func (c *Comparison) Closed() bool {
+	return c.closed
+}
+
+// View renders the visible rows of both sides with a summary of changes.
+func (c *Comparison) View() string {
+	styles := theme.Current.Styles
+	colors := theme.Current.Colors
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+
+	width := c.width - 4
+	if c.width <= 0 {
+		width = 100
+	}
+	// Both sides get the same width, with a divider between them
+	side := max((width-3)/2, 10)
+	numWidth := len(strconv.Itoa(len(c.rows)))
+
+	// The titles head their sides, on one line
+	title := styles.FormTitle.UnsetMarginBottom()
+	var sb strings.Builder
+	sb.WriteString(title.Render(fitWidth(c.leftTitle, side)))
+	sb.WriteString(muted.Render(" " + theme.Glyphs.Separator + " "))
+	sb.WriteString(title.Render(c.rightTitle))
+	sb.WriteString("\n")
+	sb.WriteString(muted.Render(c.summary()))
+	sb.WriteString("\n\n")
+
+	end := min(c.offset+c.visibleRows(), len(c.rows))
+	for _, row := range c.rows[c.offset:end] {
+		left, right := " ", " "
+		if row.changed() {
+			left, right = "-", "+"
+		}
+		sb.WriteString(renderCompareSide(row.left, row.leftNo, left, numWidth, side))
+		sb.WriteString(muted.Render(" " + theme.Glyphs.Border.Left + " "))
+		sb.WriteString(renderCompareSide(row.right, row.rightNo, right, numWidth, side))
+		sb.WriteString("\n")
+	}
+	if len(c.rows) == 0 {
+		sb.WriteString(lipgloss.NewStyle().Foreground(colors.TextDim).Render("Both are empty"))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(HintBar(ComparisonKeys()))
+
+	return lipgloss.NewStyle().Padding(0, 2).Render(sb.String())
+}
+
+// summary counts the changed rows and says which rows are shown.
+func (c *Comparison) summary() string {
+	changed := 0
+	for _, row := range c.rows {
+		if row.changed() {
+			changed++
+		}
+	}
+	shown := ""
+	if len(c.rows) > c.visibleRows() {
+		shown = fmt.Sprintf(" %s rows %d-%d of %d", theme.Glyphs.Separator,
+			c.offset+1, min(c.offset+c.visibleRows(), len(c.rows)), len(c.rows))
+	}
+	switch changed {
+	case 0:
+		return "No differences" + shown
+	case 1:
+		return "1 changed line" + shown
+	}
+	return fmt.Sprintf("%d changed lines", changed) + shown
+}
+
+// renderCompareSide renders one side of a row: its line number and the
+// line, colored as a diff line by marker and cut to width.
+func renderCompareSide(line string, no int, marker string, numWidth, width int) string {
+	dim := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextDim)
+	if no == 0 {
+		return strings.Repeat(" ", width)
+	}
+	num := dim.Render(fmt.Sprintf("%*d ", numWidth, no))
+	text := fitWidth(marker+strings.ReplaceAll(line, "\t", "    "), width-numWidth-1)
+	return num + renderDiffLine(text)
+}
+
+// fitWidth cuts s to width cells, or pads it to them.
+func fitWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if w := lipgloss.Width(s); w <= width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	ellipsis := theme.Glyphs.Ellipsis
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+lipgloss.Width(ellipsis) > width {
+		runes = runes[:len(runes)-1]
+	}
+	cut := string(runes) + ellipsis
+	return cut + strings.Repeat(" ", max(width-lipgloss.Width(cut), 0))
+}
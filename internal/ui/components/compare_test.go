@@ -0,0 +1,65 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAlignLines(t *testing.T) {
+	rows := alignLines(
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "B", "c", "d", "e"},
+	)
+	want := []compareRow{
+		{left: "a", right: "a", leftNo: 1, rightNo: 1},
+		{left: "b", right: "B", leftNo: 2, rightNo: 2}, // changed lines share a row
+		{left: "c", right: "c", leftNo: 3, rightNo: 3},
+		{left: "d", right: "d", leftNo: 4, rightNo: 4},
+		{right: "e", rightNo: 5},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestComparisonJumpsBetweenChanges(t *testing.T) {
+	var left, right []string
+	for i := range 100 {
+		line := strings.Repeat("x", i%7)
+		left = append(left, line)
+		if i == 40 || i == 80 {
+			line += "!"
+		}
+		right = append(right, line)
+	}
+	c := NewComparison("before", strings.Join(left, "\n"), "after", strings.Join(right, "\n"))
+	c.SetSize(80, 16)
+
+	c.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if c.offset != 40 {
+		t.Errorf("first change at row %d, want 40", c.offset)
+	}
+	c.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if c.offset != 80 {
+		t.Errorf("second change at row %d, want 80", c.offset)
+	}
+	c.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if c.offset != 40 {
+		t.Errorf("back to row %d, want 40", c.offset)
+	}
+	if !strings.Contains(c.View(), "2 changed lines") {
+		t.Error("the summary does not count the changes")
+	}
+
+	c.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !c.Closed() {
+		t.Error("Esc did not close the comparison")
+	}
+}
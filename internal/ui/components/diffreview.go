@@ -0,0 +1,264 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// hunkDecision is the user's verdict on a single hunk.
+type hunkDecision int
+
+const (
+	hunkPending hunkDecision = iota
+	hunkAccepted
+	hunkRejected
+)
+
+// DiffReview shows a diff one hunk at a time and lets the user accept or
+// reject each hunk. Hunks left undecided on submit count as rejected.
+type DiffReview struct {
+	Title string
+	Path  string
+
+	hunks     []protocol.DiffHunk
+	decisions []hunkDecision
+	cursor    int
+	responded bool
+	cancelled bool
+	width     int
+}
+
+// NewDiffReview creates a new diff review from a payload.
+func NewDiffReview(payload *protocol.DiffPayload) *DiffReview {
+	hunks := payload.Hunks
+	if len(hunks) == 0 && payload.Diff != "" {
+		hunks = ParseDiffHunks(payload.Diff)
+	}
+
+	title := payload.Title
+	if title == "" {
+		title = "Review changes"
+	}
+
+	return &DiffReview{
+		Title:     title,
+		Path:      payload.Path,
+		hunks:     hunks,
+		decisions: make([]hunkDecision, len(hunks)),
+	}
+}
+
+// ParseDiffHunks splits a unified diff into hunks. File headers before the
+// first "@@" line are dropped.
+func ParseDiffHunks(diff string) []protocol.DiffHunk {
+	var hunks []protocol.DiffHunk
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			hunks = append(hunks, protocol.DiffHunk{Header: line})
+			continue
+		}
+		if len(hunks) == 0 {
+			continue
+		}
+		last := &hunks[len(hunks)-1]
+		last.Lines = append(last.Lines, line)
+	}
+	return hunks
+}
+
+// SetWidth sets the review width.
+func (d *DiffReview) SetWidth(width int) {
+	d.width = width
+}
+
+// Update handles review keys.
+func (d *DiffReview) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch keyMsg.String() {
+	case "y":
+		d.decide(hunkAccepted)
+	case "n":
+		d.decide(hunkRejected)
+	case "a":
+		d.decideAll(hunkAccepted)
+	case "r":
+		d.decideAll(hunkRejected)
+	case "up", "k", "left", "h", "shift+tab":
+		if d.cursor > 0 {
+			d.cursor--
+		}
+	case "down", "j", "right", "l", "tab":
+		if d.cursor < len(d.hunks)-1 {
+			d.cursor++
+		}
+	case "enter":
+		d.responded = true
+	case "esc":
+		d.cancelled = true
+		d.responded = true
+	}
+	return nil
+}
+
+// decide records a verdict for the current hunk and moves to the next one.
+func (d *DiffReview) decide(decision hunkDecision) {
+	if len(d.hunks) == 0 {
+		return
+	}
+	d.decisions[d.cursor] = decision
+	if d.cursor < len(d.hunks)-1 {
+		d.cursor++
+	}
+}
+
+// decideAll records the same verdict for every hunk.
+func (d *DiffReview) decideAll(decision hunkDecision) {
+	for i := range d.decisions {
+		d.decisions[i] = decision
+	}
+}
+
+// HasResponded returns true if the user submitted or cancelled.
+func (d *DiffReview) HasResponded() bool {
+	return d.responded
+}
+
+// IsCancelled returns true if the user cancelled.
+func (d *DiffReview) IsCancelled() bool {
+	return d.cancelled
+}
+
+// Accepted returns the indices of accepted hunks, or nil if cancelled.
+func (d *DiffReview) Accepted() []int {
+	if d.cancelled {
+		return nil
+	}
+	accepted := []int{}
+	for i, decision := range d.decisions {
+		if decision == hunkAccepted {
+			accepted = append(accepted, i)
+		}
+	}
+	return accepted
+}
+
+// View renders the current hunk with a summary of all decisions.
+func (d *DiffReview) View() string {
+	styles := theme.Current.Styles
+	colors := theme.Current.Colors
+	var sb strings.Builder
+
+	sb.WriteString(styles.FormTitle.Render(d.Title))
+	sb.WriteString("\n")
+	if d.Path != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(colors.TextMuted).Render(d.Path))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	if len(d.hunks) == 0 {
+		sb.WriteString(lipgloss.NewStyle().Foreground(colors.TextDim).Render("No changes"))
+	} else {
+		sb.WriteString(d.renderSummary())
+		sb.WriteString("\n\n")
+		sb.WriteString(d.renderHunk(d.cursor))
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString(HintBar(DiffReviewKeys()))
+
+	containerStyle := styles.FormContainer
+	if d.width > 0 {
+		containerStyle = containerStyle.Width(min(100, d.width-4))
+	} else {
+		containerStyle = containerStyle.Width(80)
+	}
+
+	return containerStyle.Render(sb.String())
+}
+
+// renderSummary renders one badge per hunk, highlighting the current one.
+func (d *DiffReview) renderSummary() string {
+	colors := theme.Current.Colors
+	badges := make([]string, len(d.hunks))
+	for i, decision := range d.decisions {
+		symbol, color := theme.Glyphs.Bullet, colors.TextDim
+		switch decision {
+		case hunkAccepted:
+			symbol, color = theme.Glyphs.Success, colors.Success
+		case hunkRejected:
+			symbol, color = theme.Glyphs.Error, colors.Error
+		}
+		style := lipgloss.NewStyle().Foreground(color)
+		if i == d.cursor {
+			// Bracketed, so the current hunk does not rely on color alone
+			badges[i] = style.Background(colors.Surface).Bold(true).Render("[" + symbol + "]")
+			continue
+		}
+		badges[i] = style.Padding(0, 1).Render(symbol)
+	}
+
+	label := lipgloss.NewStyle().Foreground(colors.TextMuted).
+		Render(fmt.Sprintf("Hunk %d/%d ", d.cursor+1, len(d.hunks)))
+	return label + strings.Join(badges, "")
+}
+
+// renderHunk renders a hunk with added and removed lines colored.
+func (d *DiffReview) renderHunk(i int) string {
+	colors := theme.Current.Colors
+	hunk := d.hunks[i]
+
+	headerStyle := lipgloss.NewStyle().Foreground(colors.Info)
+
+	// The decision is spelled out in the header, not only in the border color
+	header := headerStyle.Render(hunk.Header)
+	switch d.decisions[i] {
+	case hunkAccepted:
+		header += lipgloss.NewStyle().Foreground(colors.Success).Render("  " + theme.Glyphs.Success + " accepted")
+	case hunkRejected:
+		header += lipgloss.NewStyle().Foreground(colors.Error).Render("  " + theme.Glyphs.Error + " rejected")
+	}
+
+	lines := make([]string, 0, len(hunk.Lines)+1)
+	lines = append(lines, header)
+	for _, line := range hunk.Lines {
+		lines = append(lines, renderDiffLine(line))
+	}
+
+	borderColor := colors.Overlay
+	switch d.decisions[i] {
+	case hunkAccepted:
+		borderColor = colors.Success
+	case hunkRejected:
+		borderColor = colors.Error
+	}
+	return lipgloss.NewStyle().
+		Border(theme.Glyphs.Border).
+		BorderForeground(borderColor).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderDiffLine colors a line of a diff by its prefix: added lines start
+// with "+", removed ones with "-".
+func renderDiffLine(line string) string {
+	colors := theme.Current.Colors
+	color := colors.Text
+	switch {
+	case strings.HasPrefix(line, "+"):
+		color = colors.Success
+	case strings.HasPrefix(line, "-"):
+		color = colors.Error
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(line)
+}
@@ -0,0 +1,83 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+const sampleDiff = `--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-import "fmt"
++import "log"
+@@ -10,2 +10,3 @@ func main() {
+ 	run()
++	cleanup()
+@@ -20 +21 @@
+-// old
++// new
+`
+
+func TestParseDiffHunks(t *testing.T) {
+	hunks := ParseDiffHunks(sampleDiff)
+	if len(hunks) != 3 {
+		t.Fatalf("got %d hunks, want 3", len(hunks))
+	}
+	if hunks[1].Header != "@@ -10,2 +10,3 @@ func main() {" {
+		t.Errorf("hunk 1 header = %q", hunks[1].Header)
+	}
+	want := []string{" package main", `-import "fmt"`, `+import "log"`}
+	if !reflect.DeepEqual(hunks[0].Lines, want) {
+		t.Errorf("hunk 0 lines = %q, want %q", hunks[0].Lines, want)
+	}
+}
+
+func TestDiffReviewAccepted(t *testing.T) {
+	key := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want []int
+	}{
+		{name: "accept each", keys: []tea.KeyMsg{key("y"), key("y"), key("y")}, want: []int{0, 1, 2}},
+		{name: "reject middle", keys: []tea.KeyMsg{key("y"), key("n"), key("y")}, want: []int{0, 2}},
+		{name: "undecided counts as rejected", keys: []tea.KeyMsg{key("j"), key("y")}, want: []int{1}},
+		{name: "accept all then reject one", keys: []tea.KeyMsg{key("a"), key("j"), key("n")}, want: []int{0, 2}},
+		{name: "reject all", keys: []tea.KeyMsg{key("a"), key("r")}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiffReview(&protocol.DiffPayload{Diff: sampleDiff})
+			for _, k := range tt.keys {
+				d.Update(k)
+			}
+			d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+			if !d.HasResponded() || d.IsCancelled() {
+				t.Fatal("expected a submitted response")
+			}
+			if got := d.Accepted(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Accepted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffReviewCancel(t *testing.T) {
+	d := NewDiffReview(&protocol.DiffPayload{Diff: sampleDiff})
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if !d.IsCancelled() || d.Accepted() != nil {
+		t.Errorf("cancelled review should accept nothing, got %v", d.Accepted())
+	}
+}
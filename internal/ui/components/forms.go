@@ -312,10 +312,12 @@ func (f *Form) View() string {
 		}
 
 		labelStyle := styles.FormLabel
+		marker := theme.Glyphs.Blur
 		if focused {
 			labelStyle = labelStyle.Foreground(colors.Primary).Bold(true)
+			marker = theme.Glyphs.Focus
 		}
-		sb.WriteString(labelStyle.Render(label))
+		sb.WriteString(labelStyle.Render(marker + label))
 
 		// Description
 		if field.Description != "" {
@@ -355,6 +357,12 @@ func (f *Form) View() string {
 	sb.WriteString("  ")
 	sb.WriteString(cancelStyle.Render(f.CancelLabel))
 
+	// Hint
+	if hints := HintBar(FormKeys()); hints != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(hints)
+	}
+
 	// Wrap in container
 	containerStyle := styles.FormContainer
 	if f.width > 0 {
@@ -374,11 +382,11 @@ func (f *Form) renderTextInput(field FormField, focused bool) string {
 
 	if focused {
 		inputStyle = inputStyle.
-			Border(lipgloss.RoundedBorder()).
+			Border(theme.Glyphs.Border).
 			BorderForeground(colors.Primary)
 	} else {
 		inputStyle = inputStyle.
-			Border(lipgloss.RoundedBorder()).
+			Border(theme.Glyphs.Border).
 			BorderForeground(colors.TextDim)
 	}
 
@@ -396,15 +404,15 @@ func (f *Form) renderSelect(field FormField, focused bool) string {
 		var style lipgloss.Style
 
 		if selected {
-			prefix = "● "
+			prefix = theme.Glyphs.Filled + " "
 			style = lipgloss.NewStyle().Foreground(colors.Primary).Bold(true)
 		} else {
-			prefix = "○ "
+			prefix = theme.Glyphs.Empty + " "
 			style = lipgloss.NewStyle().Foreground(colors.TextMuted)
 		}
 
 		if focused && selected {
-			style = style.Background(colors.Surface)
+			style = theme.Current.Styles.Focused
 		}
 
 		sb.WriteString(style.Render(prefix + opt))
@@ -423,7 +431,7 @@ func (f *Form) renderCheckbox(field FormField, focused bool) string {
 	var style lipgloss.Style
 
 	if field.checked {
-		box = "[✓]"
+		box = "[" + theme.Glyphs.Check + "]"
 		style = lipgloss.NewStyle().Foreground(colors.Success)
 	} else {
 		box = "[ ]"
@@ -447,6 +455,7 @@ type ConfirmDialog struct {
 	ConfirmLabel string
 	CancelLabel  string
 	Destructive  bool
+	Default      bool // the answer given if the user does not answer in time
 
 	focusConfirm bool
 	confirmed    bool
@@ -471,6 +480,7 @@ func NewConfirmDialog(payload *protocol.ConfirmPayload) *ConfirmDialog {
 		ConfirmLabel: confirmLabel,
 		CancelLabel:  cancelLabel,
 		Destructive:  payload.Destructive,
+		Default:      payload.Default,
 		focusConfirm: true,
 	}
 }
@@ -517,25 +527,37 @@ func (c *ConfirmDialog) View() string {
 	colors := theme.Current.Colors
 	var sb strings.Builder
 
+	// Destructive dialogs are marked with a glyph as well as the warning
+	// color, which not everyone can tell apart
+	title, message := c.Title, c.Message
+	if c.Destructive {
+		if title != "" {
+			title = theme.Glyphs.Warning + " " + title
+		} else {
+			message = theme.Glyphs.Warning + " " + message
+		}
+	}
+
 	// Title
-	if c.Title != "" {
+	if title != "" {
 		titleStyle := styles.FormTitle
 		if c.Destructive {
 			titleStyle = titleStyle.Foreground(colors.Warning)
 		}
-		sb.WriteString(titleStyle.Render(c.Title))
+		sb.WriteString(titleStyle.Render(title))
 		sb.WriteString("\n\n")
 	}
 
 	// Message
 	msgStyle := lipgloss.NewStyle().Foreground(colors.Text)
-	sb.WriteString(msgStyle.Render(c.Message))
+	sb.WriteString(msgStyle.Render(message))
 	sb.WriteString("\n\n")
 
 	// Hint
-	hintStyle := lipgloss.NewStyle().Foreground(colors.TextDim).Italic(true)
-	sb.WriteString(hintStyle.Render("Press Y for yes, N for no, or use arrow keys"))
-	sb.WriteString("\n\n")
+	if hints := HintBar(ConfirmKeys()); hints != "" {
+		sb.WriteString(hints)
+		sb.WriteString("\n\n")
+	}
 
 	// Buttons
 	confirmStyle := styles.FormButton
@@ -571,7 +593,7 @@ func (c *ConfirmDialog) View() string {
 // SelectMenu is a selection menu component.
 type SelectMenu struct {
 	Label   string
-	Options []string
+	Options []protocol.SelectOption
 	Default string
 
 	selectedIndex int
@@ -591,7 +613,7 @@ func NewSelectMenu(payload *protocol.SelectPayload) *SelectMenu {
 	// Find default index
 	if menu.Default != "" {
 		for i, opt := range menu.Options {
-			if opt == menu.Default {
+			if opt.OptionValue() == menu.Default {
 				menu.selectedIndex = i
 				break
 			}
@@ -644,7 +666,15 @@ func (s *SelectMenu) GetSelected() string {
 	if s.cancelled || len(s.Options) == 0 {
 		return ""
 	}
-	return s.Options[s.selectedIndex]
+	return s.Options[s.selectedIndex].OptionValue()
+}
+
+// SelectedLabel returns the label of the highlighted option.
+func (s *SelectMenu) SelectedLabel() string {
+	if len(s.Options) == 0 {
+		return ""
+	}
+	return s.Options[s.selectedIndex].Label
 }
 
 // View renders the menu.
@@ -665,27 +695,29 @@ func (s *SelectMenu) View() string {
 		var style lipgloss.Style
 
 		if selected {
-			prefix = "▸ "
-			style = lipgloss.NewStyle().
-				Foreground(colors.Primary).
-				Bold(true).
-				Background(colors.Surface).
-				Padding(0, 1)
+			prefix = theme.Glyphs.Focus
+			style = styles.Focused.Padding(0, 1)
 		} else {
-			prefix = "  "
+			prefix = theme.Glyphs.Blur
 			style = lipgloss.NewStyle().
 				Foreground(colors.Text).
 				Padding(0, 1)
 		}
 
-		sb.WriteString(style.Render(prefix + opt))
+		sb.WriteString(style.Render(prefix + opt.Label))
 		sb.WriteString("\n")
+		if opt.Description != "" {
+			descStyle := lipgloss.NewStyle().Foreground(colors.TextMuted).PaddingLeft(4)
+			sb.WriteString(descStyle.Render(opt.Description))
+			sb.WriteString("\n")
+		}
 	}
 
 	// Hint
-	sb.WriteString("\n")
-	hintStyle := lipgloss.NewStyle().Foreground(colors.TextDim).Italic(true)
-	sb.WriteString(hintStyle.Render("↑↓ to move, Enter to select, Esc to cancel"))
+	if hints := HintBar(SelectKeys()); hints != "" {
+		sb.WriteString("\n")
+		sb.WriteString(hints)
+	}
 
 	// Container
 	containerStyle := styles.FormContainer
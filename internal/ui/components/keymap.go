@@ -0,0 +1,124 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// Binding is a key of a component and what it does, as shown in the
+// component's hint bar and in the TUI's help overlay.
+type Binding struct {
+	Keys string // e.g. "↑↓" or "Enter"
+	Does string // e.g. "move"
+}
+
+// The keymaps of the components. They are functions since the arrows
+// follow the glyph profile.
+
+// FormKeys returns the keys of a Form.
+func FormKeys() []Binding {
+	return []Binding{
+		{"Tab/" + arrows(), "move"},
+		{"Left/Right/Space", "choose or toggle"},
+		{"Enter", "press the button"},
+		{"Esc", "cancel"},
+	}
+}
+
+// ConfirmKeys returns the keys of a ConfirmDialog.
+func ConfirmKeys() []Binding {
+	return []Binding{
+		{"y/n", "yes or no"},
+		{"Left/Right", "choose"},
+		{"Enter", "answer"},
+		{"Esc", "no"},
+	}
+}
+
+// SelectKeys returns the keys of a SelectMenu.
+func SelectKeys() []Binding {
+	return []Binding{
+		{arrows(), "move"},
+		{"Enter", "select"},
+		{"Esc", "cancel"},
+	}
+}
+
+// SecretKeys returns the keys of a SecretPrompt.
+func SecretKeys() []Binding {
+	return []Binding{
+		{"Enter", "submit"},
+		{"Esc", "cancel"},
+	}
+}
+
+// AutocompleteKeys returns the keys of an Autocomplete.
+func AutocompleteKeys() []Binding {
+	return []Binding{
+		{arrows(), "move"},
+		{"Tab", "complete"},
+		{"Enter", "select"},
+		{"Esc", "cancel"},
+	}
+}
+
+// QuestionKeys returns the keys of a Question.
+func QuestionKeys() []Binding {
+	return []Binding{
+		{arrows(), "move, or type your own answer"},
+		{"Enter", "answer"},
+		{"Esc", "cancel"},
+	}
+}
+
+// DiffReviewKeys returns the keys of a DiffReview.
+func DiffReviewKeys() []Binding {
+	return []Binding{
+		{"y/n", "accept/reject"},
+		{"a/r", "all"},
+		{arrows(), "move"},
+		{"Enter", "apply"},
+		{"Esc", "cancel"},
+	}
+}
+
+// ComparisonKeys returns the keys of a Comparison.
+func ComparisonKeys() []Binding {
+	return []Binding{
+		{arrows(), "scroll"},
+		{"n/p", "next/previous change"},
+		{"Esc", "close"},
+	}
+}
+
+// PagerKeys returns the keys of a Pager.
+func PagerKeys() []Binding {
+	return []Binding{
+		{arrows(), "scroll"},
+		{"PgUp/PgDn", "page"},
+		{"g/G", "top/bottom"},
+		{"Esc", "close"},
+	}
+}
+
+func arrows() string {
+	return theme.Glyphs.Up + "/" + theme.Glyphs.Down
+}
+
+// HintBar renders bindings as the dim line of keyboard hints shown under
+// a component, e.g. "↑/↓ move • Enter select • Esc cancel", or "" when
+// hints are turned off; see theme.Hints.
+func HintBar(bindings []Binding) string {
+	if !theme.Hints || len(bindings) == 0 {
+		return ""
+	}
+	hints := make([]string, len(bindings))
+	for i, b := range bindings {
+		hints[i] = b.Keys + " " + b.Does
+	}
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Current.Colors.TextDim).Italic(true)
+	return hintStyle.Render(strings.Join(hints, " "+theme.Glyphs.Bullet+" "))
+}
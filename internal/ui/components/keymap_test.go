@@ -0,0 +1,31 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+func TestHintBar(t *testing.T) {
+	hints := ansi.Strip(HintBar([]Binding{{"Enter", "select"}, {"Esc", "cancel"}}))
+	if want := "Enter select " + theme.Glyphs.Bullet + " Esc cancel"; hints != want {
+		t.Errorf("HintBar() = %q, want %q", hints, want)
+	}
+}
+
+func TestHintBarHidden(t *testing.T) {
+	theme.Hints = false
+	defer func() { theme.Hints = true }()
+
+	if hints := HintBar(SelectKeys()); hints != "" {
+		t.Errorf("HintBar() = %q with hints off, want none", hints)
+	}
+	menu := NewSelectMenu(&protocol.SelectPayload{Label: "Pick", Options: protocol.OptionsFromStrings([]string{"a", "b"})})
+	if view := ansi.Strip(menu.View()); strings.Contains(view, "cancel") {
+		t.Errorf("select menu shows hints with hints off:\n%s", view)
+	}
+}
@@ -0,0 +1,123 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// Pager shows a long text in full, such as output too large for the
+// transcript, as plain numbered lines. Only the lines in view are drawn,
+// so it stays quick however long the text is.
+type Pager struct {
+	title string
+	lines []string
+
+	offset int
+	closed bool
+	width  int
+	height int
+}
+
+// NewPager shows text under title.
+func NewPager(title, text string) *Pager {
+	return &Pager{title: title, lines: splitLines(text)}
+}
+
+// SetSize sets the size of the pager.
+func (p *Pager) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.scrollTo(p.offset)
+}
+
+// Update handles scrolling keys.
+func (p *Pager) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		p.scrollTo(p.offset - 1)
+	case "down", "j":
+		p.scrollTo(p.offset + 1)
+	case "pgup":
+		p.scrollTo(p.offset - p.visibleLines())
+	case "pgdown", " ":
+		p.scrollTo(p.offset + p.visibleLines())
+	case "home", "g":
+		p.scrollTo(0)
+	case "end", "G":
+		p.scrollTo(len(p.lines))
+	case "esc", "q", "enter":
+		p.closed = true
+	}
+	return nil
+}
+
+func (p *Pager) scrollTo(offset int) {
+	p.offset = max(min(offset, len(p.lines)-p.visibleLines()), 0)
+}
+
+// visibleLines is how many lines fit between the title and the hint.
+func (p *Pager) visibleLines() int {
+	if p.height <= 0 {
+		return 20
+	}
+	return max(p.height-5, 1)
+}
+
+// Closed returns true once the user has closed the pager.
+func (p *Pager) Closed() bool {
+	return p.closed
+}
+
+// View renders the lines in view, numbered and cut to the width.
+func (p *Pager) View() string {
+	styles := theme.Current.Styles
+	colors := theme.Current.Colors
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	dim := lipgloss.NewStyle().Foreground(colors.TextDim)
+
+	width := p.width - 4
+	if p.width <= 0 {
+		width = 100
+	}
+	numWidth := len(strconv.Itoa(len(p.lines)))
+
+	var sb strings.Builder
+	sb.WriteString(styles.FormTitle.UnsetMarginBottom().Render(p.title))
+	sb.WriteString("\n")
+	sb.WriteString(muted.Render(p.summary()))
+	sb.WriteString("\n\n")
+
+	end := min(p.offset+p.visibleLines(), len(p.lines))
+	for i, line := range p.lines[p.offset:end] {
+		sb.WriteString(dim.Render(fmt.Sprintf("%*d ", numWidth, p.offset+i+1)))
+		sb.WriteString(strings.TrimRight(fitWidth(strings.ReplaceAll(line, "\t", "    "), width-numWidth-1), " "))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(HintBar(PagerKeys()))
+
+	return lipgloss.NewStyle().Padding(0, 2).Render(sb.String())
+}
+
+// summary says which lines are shown.
+func (p *Pager) summary() string {
+	if len(p.lines) <= p.visibleLines() {
+		if len(p.lines) == 1 {
+			return "1 line"
+		}
+		return fmt.Sprintf("%d lines", len(p.lines))
+	}
+	return fmt.Sprintf("Lines %d-%d of %d", p.offset+1, min(p.offset+p.visibleLines(), len(p.lines)), len(p.lines))
+}
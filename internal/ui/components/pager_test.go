@@ -0,0 +1,43 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPagerScrolls(t *testing.T) {
+	var lines []string
+	for i := range 1000 {
+		lines = append(lines, fmt.Sprintf("line %d", i+1))
+	}
+	p := NewPager("Full output", strings.Join(lines, "\n"))
+	p.SetSize(80, 15)
+
+	view := p.View()
+	if !strings.Contains(view, "line 10") || strings.Contains(view, "line 11") {
+		t.Errorf("the first page does not show lines 1-10:\n%s", view)
+	}
+	if !strings.Contains(view, "Lines 1-10 of 1000") {
+		t.Error("the summary does not say which lines are shown")
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if p.offset != 990 {
+		t.Errorf("end scrolled to line %d, want 990", p.offset)
+	}
+	if !strings.Contains(p.View(), "line 1000") {
+		t.Error("the last page does not show the last line")
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if p.offset != 980 {
+		t.Errorf("page up scrolled to line %d, want 980", p.offset)
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !p.Closed() {
+		t.Error("esc did not close the pager")
+	}
+}
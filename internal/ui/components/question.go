@@ -0,0 +1,183 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// Question asks the user to pick one of the suggested answers or type
+// their own in the "Other" row below them.
+type Question struct {
+	Text    string
+	Options []protocol.SelectOption
+	Other   string
+
+	input     textinput.Model
+	cursor    int // index into Options; len(Options) is the Other row
+	responded bool
+	cancelled bool
+	width     int
+}
+
+// NewQuestion creates a new question prompt.
+func NewQuestion(payload *protocol.QuestionPayload) *Question {
+	ti := textinput.New()
+	ti.Placeholder = payload.Placeholder
+	if ti.Placeholder == "" {
+		ti.Placeholder = "Type your own answer"
+	}
+	ti.CharLimit = 512
+	ti.Prompt = ""
+
+	q := &Question{
+		Text:    payload.Question,
+		Options: payload.Options,
+		Other:   payload.Other,
+		input:   ti,
+	}
+	if q.Other == "" {
+		q.Other = "Other"
+	}
+	for i, opt := range q.Options {
+		if payload.Default != "" && opt.OptionValue() == payload.Default {
+			q.cursor = i
+			break
+		}
+	}
+	q.focusInput()
+	return q
+}
+
+// SetWidth sets the prompt width.
+func (q *Question) SetWidth(width int) {
+	q.width = width
+	q.input.Width = min(44, width-16)
+}
+
+// onOther reports whether the Other row is highlighted.
+func (q *Question) onOther() bool {
+	return q.cursor == len(q.Options)
+}
+
+// focusInput focuses the text input when the Other row is highlighted.
+func (q *Question) focusInput() {
+	if q.onOther() {
+		q.input.Focus()
+	} else {
+		q.input.Blur()
+	}
+}
+
+// Update handles input for the prompt. Typing on an option moves to the
+// Other row, so the user can answer in their own words at any time.
+func (q *Question) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "shift+tab":
+			if q.cursor > 0 {
+				q.cursor--
+				q.focusInput()
+			}
+			return nil
+		case "down", "tab":
+			if q.cursor < len(q.Options) {
+				q.cursor++
+				q.focusInput()
+			}
+			return nil
+		case "enter":
+			// An empty answer of their own is no answer
+			if !q.onOther() || strings.TrimSpace(q.input.Value()) != "" {
+				q.responded = true
+			}
+			return nil
+		case "esc":
+			q.cancelled = true
+			q.responded = true
+			return nil
+		}
+		if !q.onOther() && msg.Type == tea.KeyRunes {
+			q.cursor = len(q.Options)
+			q.focusInput()
+		}
+	}
+
+	if !q.onOther() {
+		return nil
+	}
+	var cmd tea.Cmd
+	q.input, cmd = q.input.Update(msg)
+	return cmd
+}
+
+// HasResponded returns true if the user answered or cancelled.
+func (q *Question) HasResponded() bool {
+	return q.responded
+}
+
+// IsCancelled returns true if the user cancelled.
+func (q *Question) IsCancelled() bool {
+	return q.cancelled
+}
+
+// IsCustom returns true if the answer is the user's own text.
+func (q *Question) IsCustom() bool {
+	return !q.cancelled && q.onOther()
+}
+
+// Value returns the chosen option's value or the user's own text.
+func (q *Question) Value() string {
+	switch {
+	case q.cancelled:
+		return ""
+	case q.onOther():
+		return strings.TrimSpace(q.input.Value())
+	}
+	return q.Options[q.cursor].OptionValue()
+}
+
+// View renders the question, its options and the Other row.
+func (q *Question) View() string {
+	styles := theme.Current.Styles
+	colors := theme.Current.Colors
+	var sb strings.Builder
+
+	sb.WriteString(styles.FormTitle.Render(q.Text))
+	sb.WriteString("\n\n")
+
+	row := func(i int, label string) {
+		if i == q.cursor {
+			sb.WriteString(styles.Focused.Padding(0, 1).Render(theme.Glyphs.Focus + label))
+		} else {
+			sb.WriteString(lipgloss.NewStyle().Foreground(colors.Text).Padding(0, 1).Render(theme.Glyphs.Blur + label))
+		}
+		sb.WriteString("\n")
+	}
+	for i, opt := range q.Options {
+		row(i, opt.Label)
+		if opt.Description != "" {
+			descStyle := lipgloss.NewStyle().Foreground(colors.TextMuted).PaddingLeft(4)
+			sb.WriteString(descStyle.Render(opt.Description))
+			sb.WriteString("\n")
+		}
+	}
+	row(len(q.Options), q.Other+": "+q.input.View())
+
+	sb.WriteString("\n")
+	sb.WriteString(HintBar(QuestionKeys()))
+
+	containerStyle := styles.FormContainer
+	if q.width > 0 {
+		containerStyle = containerStyle.Width(min(64, q.width-4))
+	} else {
+		containerStyle = containerStyle.Width(64)
+	}
+
+	return containerStyle.Render(sb.String())
+}
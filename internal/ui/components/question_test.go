@@ -0,0 +1,56 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/flight505/agentui/internal/protocol"
+)
+
+func newTestQuestion() *Question {
+	return NewQuestion(&protocol.QuestionPayload{
+		Question: "Which database?",
+		Options: []protocol.SelectOption{
+			{Label: "PostgreSQL", Value: "postgres"},
+			{Label: "SQLite"},
+		},
+		Default: "SQLite",
+	})
+}
+
+func TestQuestionOption(t *testing.T) {
+	q := newTestQuestion()
+	q.Update(tea.KeyMsg{Type: tea.KeyUp})
+	q.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !q.HasResponded() || q.IsCustom() || q.Value() != "postgres" {
+		t.Errorf("answer = %q (custom %v), want the option postgres", q.Value(), q.IsCustom())
+	}
+}
+
+func TestQuestionCustom(t *testing.T) {
+	q := newTestQuestion()
+
+	// An empty answer of their own is not accepted
+	q.Update(tea.KeyMsg{Type: tea.KeyDown})
+	q.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if q.HasResponded() {
+		t.Error("an empty custom answer was accepted")
+	}
+
+	// Typing on an option switches to the Other row
+	q.Update(tea.KeyMsg{Type: tea.KeyUp})
+	q.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("DuckDB")})
+	q.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !q.HasResponded() || !q.IsCustom() || q.Value() != "DuckDB" {
+		t.Errorf("answer = %q (custom %v), want the custom answer DuckDB", q.Value(), q.IsCustom())
+	}
+}
+
+func TestQuestionCancel(t *testing.T) {
+	q := newTestQuestion()
+	q.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !q.IsCancelled() || q.IsCustom() || q.Value() != "" {
+		t.Errorf("cancelled question answered %q", q.Value())
+	}
+}
@@ -0,0 +1,133 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// SecretPrompt is a single masked input for API keys and passwords.
+// The entered value is only held until it is sent back to Python.
+type SecretPrompt struct {
+	Label       string
+	Description string
+
+	input     textinput.Model
+	responded bool
+	cancelled bool
+	width     int
+}
+
+// NewSecretPrompt creates a new secret prompt.
+func NewSecretPrompt(payload *protocol.SecretPayload) *SecretPrompt {
+	ti := textinput.New()
+	ti.Placeholder = payload.Placeholder
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = theme.Glyphs.Mask
+	ti.CharLimit = 1024
+	ti.Focus()
+
+	label := payload.Label
+	if label == "" {
+		label = "Enter secret"
+	}
+
+	return &SecretPrompt{
+		Label:       label,
+		Description: payload.Description,
+		input:       ti,
+	}
+}
+
+// SetWidth sets the prompt width.
+func (s *SecretPrompt) SetWidth(width int) {
+	s.width = width
+	s.input.Width = min(50, width-10)
+}
+
+// Update handles input for the prompt.
+func (s *SecretPrompt) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "enter":
+			s.responded = true
+			return nil
+		case "esc":
+			s.cancelled = true
+			s.responded = true
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return cmd
+}
+
+// HasResponded returns true if the user submitted or cancelled.
+func (s *SecretPrompt) HasResponded() bool {
+	return s.responded
+}
+
+// IsCancelled returns true if the user cancelled.
+func (s *SecretPrompt) IsCancelled() bool {
+	return s.cancelled
+}
+
+// Value returns the entered secret, or "" if cancelled.
+func (s *SecretPrompt) Value() string {
+	if s.cancelled {
+		return ""
+	}
+	return s.input.Value()
+}
+
+// Clear wipes the entered value from the input.
+func (s *SecretPrompt) Clear() {
+	s.input.Reset()
+}
+
+// View renders the prompt.
+func (s *SecretPrompt) View() string {
+	styles := theme.Current.Styles
+	colors := theme.Current.Colors
+	var sb strings.Builder
+
+	sb.WriteString(styles.FormTitle.Render(theme.Glyphs.Lock + s.Label))
+	sb.WriteString("\n\n")
+
+	if s.Description != "" {
+		descStyle := lipgloss.NewStyle().Foreground(colors.TextMuted)
+		sb.WriteString(descStyle.Render(s.Description))
+		sb.WriteString("\n\n")
+	}
+
+	inputStyle := lipgloss.NewStyle().
+		Background(colors.Surface).
+		Foreground(colors.Text).
+		Padding(0, 1).
+		Border(theme.Glyphs.Border).
+		BorderForeground(colors.Primary)
+	sb.WriteString(inputStyle.Render(s.input.View()))
+	sb.WriteString("\n\n")
+
+	noteStyle := lipgloss.NewStyle().Foreground(colors.TextDim).Italic(true)
+	sb.WriteString(noteStyle.Render("Input is hidden and never saved."))
+	if hints := HintBar(SecretKeys()); hints != "" {
+		sb.WriteString("\n" + hints)
+	}
+
+	containerStyle := styles.FormContainer
+	if s.width > 0 {
+		containerStyle = containerStyle.Width(min(60, s.width-4))
+	} else {
+		containerStyle = containerStyle.Width(60)
+	}
+
+	return containerStyle.Render(sb.String())
+}
@@ -141,3 +141,21 @@ func TestCodeView_HighlightCode(t *testing.T) {
 		t.Error("Highlighted code is shorter than original (unexpected)")
 	}
 }
+
+func TestCodeView_SetHighlight(t *testing.T) {
+	theme.SetTheme("charm-dark")
+
+	view := NewCodeView()
+	view.SetLanguage("python")
+	view.SetCode("def f():\n    return 1")
+	view.SetHighlight(false)
+
+	if got := view.highlightCode(); got != "def f():\n    return 1" {
+		t.Errorf("highlightCode() with highlighting off = %q, want the code unchanged", got)
+	}
+
+	view.SetHighlight(true)
+	if got := view.highlightCode(); !strings.Contains(got, "\x1b[") {
+		t.Errorf("highlightCode() with highlighting on has no ANSI codes: %q", got)
+	}
+}
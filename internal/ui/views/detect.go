@@ -0,0 +1,81 @@
+package views
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// shebangLanguages maps the interpreter a shebang line names to its
+// language.
+var shebangLanguages = map[string]string{
+	"python":  "python",
+	"python3": "python",
+	"sh":      "bash",
+	"bash":    "bash",
+	"zsh":     "bash",
+	"node":    "javascript",
+	"deno":    "typescript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+}
+
+// languageSignals are lines that give a language away. The first language
+// with a matching line wins, so those with the most distinctive syntax
+// come first.
+var languageSignals = []struct {
+	language string
+	pattern  *regexp.Regexp
+}{
+	{"go", regexp.MustCompile(`(?m)^package \w+$|^func (\(\w+ \*?\w+\) )?\w+\(|^\s*\w+(, \w+)* := `)},
+	{"rust", regexp.MustCompile(`(?m)^\s*(pub )?fn \w+|^\s*let mut |^use \w+::|^\s*impl\b`)},
+	{"python", regexp.MustCompile(`(?m)^\s*(async )?def \w+\(.*:$|^\s*(from [\w.]+ )?import \w+(\.\w+)*( as \w+)?$|^\s*class \w+(\(.*\))?:$|^if __name__ ==`)},
+	{"typescript", regexp.MustCompile(`(?m)^\s*(export )?(interface|type) \w+ (=|\{)|: (string|number|boolean)\b`)},
+	{"javascript", regexp.MustCompile(`(?m)^\s*(const|let|var) \w+ = |^\s*function \w+\(|=> \{|console\.log\(|require\(`)},
+	{"java", regexp.MustCompile(`(?m)^\s*(public|private|protected) (static )?(class|void|[A-Z]\w*) \w+`)},
+	{"c", regexp.MustCompile(`(?m)^#include [<"]|^int main\(`)},
+	{"sql", regexp.MustCompile(`(?im)^\s*(SELECT .+ FROM|INSERT INTO|CREATE TABLE|UPDATE \w+ SET|DELETE FROM)\b`)},
+	{"html", regexp.MustCompile(`(?i)^\s*(<!doctype html|<html\b)`)},
+	{"bash", regexp.MustCompile(`(?m)^\s*(echo|export|cd|sudo|apt|brew|pip|npm|go|git|curl) |^\s*\$ \w`)},
+	{"yaml", regexp.MustCompile(`(?m)^[\w-]+:( .*)?$\n^(  |- )`)},
+}
+
+// DetectLanguage guesses the language of code an agent did not tag, from a
+// shebang line, lines that give a language away or Chroma's analysers. It
+// returns "" when it cannot tell, so the code is shown plain.
+func DetectLanguage(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+
+	if first, _, _ := strings.Cut(code, "\n"); strings.HasPrefix(first, "#!") {
+		fields := strings.Fields(strings.TrimPrefix(first, "#!"))
+		if len(fields) > 0 {
+			interpreter := path.Base(fields[0])
+			if interpreter == "env" && len(fields) > 1 {
+				interpreter = fields[1]
+			}
+			if lang, ok := shebangLanguages[interpreter]; ok {
+				return lang
+			}
+		}
+	}
+
+	if (code[0] == '{' || code[0] == '[') && json.Valid([]byte(code)) {
+		return "json"
+	}
+	for _, s := range languageSignals {
+		if s.pattern.MatchString(code) {
+			return s.language
+		}
+	}
+	if lexer := lexers.Analyse(code); lexer != nil {
+		return strings.ToLower(lexer.Config().Name)
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package views
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "shebang through env", code: "#!/usr/bin/env python3\nprint('hi')", want: "python"},
+		{name: "shell shebang", code: "#!/bin/sh\nset -e", want: "bash"},
+		{name: "go", code: "package main\n\nfunc main() {}", want: "go"},
+		{name: "go short variable", code: "n, err := f.Read(buf)\nif err != nil {\n\treturn err\n}", want: "go"},
+		{name: "python", code: "def greet(name: str) -> str:\n    return f\"hi {name}\"", want: "python"},
+		{name: "python import", code: "import os\nprint(os.getcwd())", want: "python"},
+		{name: "rust", code: "fn main() {\n    let mut v = Vec::new();\n}", want: "rust"},
+		{name: "typescript", code: "interface User {\n  name: string\n}", want: "typescript"},
+		{name: "javascript", code: "const xs = [1, 2].map(x => x * 2)\nconsole.log(xs)", want: "javascript"},
+		{name: "json", code: `{"name": "agentui", "tags": ["tui"]}`, want: "json"},
+		{name: "sql", code: "select id, name from users where id = 1;", want: "sql"},
+		{name: "shell commands", code: "cd agentui\nmake build", want: "bash"},
+		{name: "yaml", code: "services:\n  web:\n    image: nginx", want: "yaml"},
+		{name: "prose", code: "Nothing here looks like code at all.", want: ""},
+		{name: "empty", code: "  \n", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.code); got != tt.want {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
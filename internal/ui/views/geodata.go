@@ -0,0 +1,135 @@
+package views
+
+// Simplified world outline used by MapView, as {longitude, latitude}
+// polygons. The shapes are coarse on purpose: they only need to read as
+// continents at terminal resolution.
+var landPolygons = [][][2]float64{
+	// North and Central America
+	{
+		{-165, 54}, {-158, 57}, {-152, 60}, {-146, 61}, {-137, 59}, {-131, 55},
+		{-125, 49}, {-124, 42}, {-120, 34}, {-117, 32.5}, {-110, 23}, {-105, 20},
+		{-97, 16}, {-92, 14.5}, {-87, 13}, {-83, 9}, {-79, 8}, {-77, 8.5},
+		{-83, 15}, {-88, 16}, {-87, 21}, {-90, 21}, {-91, 19}, {-97, 22},
+		{-97, 26}, {-95, 29}, {-90, 29}, {-84, 30}, {-82, 27}, {-80, 25},
+		{-80, 31}, {-76, 35}, {-74, 40}, {-70, 42}, {-66, 44}, {-60, 46},
+		{-53, 47}, {-56, 52}, {-61, 56}, {-64, 60}, {-70, 59}, {-77, 62},
+		{-78, 58}, {-80, 51}, {-87, 55}, {-93, 59}, {-95, 62}, {-88, 64},
+		{-82, 67}, {-86, 69}, {-95, 72}, {-105, 68}, {-115, 68}, {-128, 70},
+		{-141, 70}, {-156, 71.5}, {-166, 68}, {-165, 64}, {-162, 60},
+	},
+	// Canadian Arctic islands
+	{
+		{-118, 72}, {-105, 74}, {-95, 76}, {-85, 80}, {-70, 83}, {-62, 82},
+		{-75, 78}, {-85, 76}, {-95, 74}, {-100, 70}, {-115, 70},
+	},
+	// Baffin Island
+	{{-80, 73}, {-68, 71}, {-62, 67}, {-65, 63}, {-73, 64}, {-78, 68}, {-85, 70}},
+	// Greenland
+	{
+		{-73, 78}, {-60, 82}, {-30, 83.5}, {-20, 80}, {-20, 72}, {-24, 69},
+		{-35, 66}, {-43, 60}, {-50, 62}, {-54, 67}, {-56, 72}, {-68, 76},
+	},
+	// Cuba
+	{{-85, 22}, {-82, 23.2}, {-77, 22}, {-74, 20}, {-77.5, 19.8}, {-80, 21.5}},
+	// South America
+	{
+		{-77, 8}, {-72, 12}, {-64, 10.5}, {-60, 8}, {-52, 5}, {-50, 0},
+		{-44, -2.5}, {-35, -5}, {-35, -9}, {-39, -14}, {-39, -18}, {-41, -22},
+		{-48, -26}, {-52, -33}, {-58, -38}, {-62, -39}, {-65, -42}, {-65, -46},
+		{-68, -50}, {-69, -52}, {-68, -55}, {-72, -54}, {-75, -50}, {-74, -43},
+		{-73, -37}, {-71.5, -30}, {-70, -18}, {-76, -14}, {-81, -6}, {-80, -2},
+		{-80, 1}, {-78, 4},
+	},
+	// Africa
+	{
+		{-6, 36}, {10, 37}, {11, 33}, {20, 31}, {25, 32}, {32, 31},
+		{34, 31.3}, {34.5, 29.5}, {38, 18}, {43, 12}, {51, 11.5}, {49, 6},
+		{41, -2}, {40, -10}, {35, -18}, {33, -26}, {27, -34}, {20, -35},
+		{18, -32}, {15, -27}, {12, -18}, {13, -9}, {9, -1}, {9, 4},
+		{4, 6}, {-3, 5}, {-8, 4.5}, {-13, 8}, {-17, 14}, {-17, 21},
+		{-13, 27}, {-10, 30},
+	},
+	// Madagascar
+	{{44, -25}, {47, -25}, {50, -15}, {49, -12}, {44, -16}, {43.5, -22}},
+	// Eurasia
+	{
+		{-9, 37}, {-9, 43}, {-2, 43.5}, {-4.5, 48}, {2, 51}, {5, 53},
+		{8, 54}, {9, 57}, {11, 56}, {10, 54}, {14, 54}, {21, 55},
+		{22, 58}, {28, 60}, {22, 60.5}, {25, 65}, {21, 65}, {17, 61},
+		{19, 60}, {16, 56}, {12, 56}, {8, 58}, {5, 61}, {12, 65},
+		{18, 69.5}, {25, 71}, {31, 70}, {41, 67}, {44, 68}, {53, 68},
+		{60, 69}, {68, 70}, {73, 73}, {80, 73}, {87, 75}, {100, 78},
+		{113, 74}, {130, 72}, {140, 72.5}, {150, 71}, {160, 70}, {170, 70},
+		{180, 69}, {180, 65}, {178, 62}, {172, 60}, {163, 57.5}, {162, 55},
+		{156.5, 51}, {156, 57}, {160, 61.5}, {155, 60}, {150, 59.5}, {143, 59},
+		{137, 54}, {141, 52}, {140, 48}, {135, 43}, {131, 42}, {129, 40},
+		{129, 35}, {126, 34.5}, {126, 37}, {125, 39.5}, {122, 40}, {121, 37},
+		{119, 35}, {122, 31}, {121, 28}, {117, 23.5}, {110, 21}, {108, 21.5},
+		{106, 19}, {109, 15}, {109, 11}, {105, 9}, {105, 10.5}, {102, 12},
+		{100, 13.5}, {98.5, 8}, {100.5, 3}, {103.5, 1.5}, {103, 5}, {101, 6.5},
+		{99, 10.5}, {98, 16}, {94, 16}, {94, 19}, {92, 22}, {90, 22},
+		{87, 21}, {85, 19.5}, {80, 15}, {80, 10}, {77, 8}, {76, 10},
+		{73, 16}, {72.5, 21}, {70, 22.5}, {67, 24.5}, {62, 25}, {57, 25.5},
+		{56.5, 27}, {52, 27.8}, {50, 30}, {48, 30}, {50, 26.5}, {51.5, 24},
+		{54, 24}, {56, 26}, {56.5, 24}, {59.5, 22.5}, {57, 19}, {52, 16},
+		{45, 13}, {43, 13}, {39, 21}, {35, 28}, {35, 29.5}, {35, 33},
+		{36, 36}, {30, 36.5}, {27, 37}, {26, 40.5}, {23, 40.5}, {24, 38},
+		{22, 36.5}, {20, 39.5}, {19, 42}, {15, 45.5}, {12.5, 45.5}, {12, 44},
+		{18.5, 40}, {16, 38}, {15.5, 40}, {12, 42}, {10, 44}, {7, 43.5},
+		{3, 43.3}, {3, 42}, {0, 39}, {-2, 37}, {-5, 36},
+	},
+	// Chukotka, east of the antimeridian
+	{{-180, 65}, {-172, 64.5}, {-170, 66}, {-180, 69}},
+	// Great Britain and Ireland
+	{
+		{-5.5, 50}, {1.5, 51}, {1.5, 53}, {-0.5, 54}, {-2, 57}, {-3, 58.6},
+		{-5, 58.6}, {-6.2, 56.5}, {-4.8, 54.8}, {-3, 54}, {-4.8, 52},
+	},
+	{{-6, 52}, {-6, 54}, {-8, 55}, {-10, 54}, {-10, 51.5}},
+	// Iceland
+	{{-24, 65.5}, {-22, 66.5}, {-15, 66.5}, {-13.5, 65}, {-18, 63.5}, {-22, 63.8}},
+	// Japan
+	{
+		{130, 31}, {132, 33.5}, {135, 33.5}, {140, 35}, {141, 38}, {141.5, 41.5},
+		{145.5, 43.3}, {141.8, 45.5}, {140, 42}, {140, 40}, {139, 38}, {137, 37},
+		{133, 35.5}, {130.5, 34},
+	},
+	// Philippines
+	{{120, 18.5}, {122, 18.5}, {124, 13}, {126, 7}, {125.5, 6}, {122, 7}, {118, 10}, {120, 14}},
+	// Borneo, Sumatra, Java and New Guinea
+	{{109, 1.5}, {111, 2.5}, {116, 7}, {119, 5}, {118, 1}, {116, -4}, {110, -3}, {109, -1}},
+	{{95, 5.5}, {98, 4}, {104, -2}, {106, -6}, {104, -5.8}, {101, -3}, {98, 0}, {95, 3}},
+	{{105, -6}, {114, -7}, {114.5, -8.5}, {106, -7.5}},
+	{
+		{131, -1}, {137, -1.5}, {141, -2.5}, {146, -5}, {150, -10.5}, {147, -10},
+		{143, -9}, {141, -9}, {138, -8}, {135, -4.5}, {132, -3},
+	},
+	// Australia and Tasmania
+	{
+		{114, -22}, {114, -26}, {115, -34}, {118, -35}, {123, -34}, {129, -31.5},
+		{131, -31.5}, {135, -35}, {138, -35.5}, {140, -38}, {144, -38.5}, {146, -39},
+		{150, -37.5}, {153, -32}, {153.5, -28}, {153, -25}, {150, -22}, {146, -19},
+		{145.5, -15}, {143.5, -14}, {142, -10.8}, {141.5, -13}, {141, -17}, {139, -17.5},
+		{136, -15}, {137, -12}, {132, -11.3}, {130, -13}, {129, -15}, {126, -14},
+		{122, -17}, {121, -19.5}, {116.5, -20.5},
+	},
+	{{144.5, -40.7}, {148, -40.8}, {148, -43}, {146, -43.6}},
+	// New Zealand
+	{{173, -34.5}, {178.5, -37.7}, {177, -39.5}, {175, -41.5}, {174.5, -39}, {174, -36.5}},
+	{{172.7, -40.5}, {174.3, -41.7}, {172.5, -43.7}, {171, -45}, {169, -46.7}, {166.5, -46}, {168, -44}, {171, -42}},
+	// Antarctica
+	{
+		{-180, -84}, {-150, -77}, {-120, -74}, {-100, -73}, {-75, -72}, {-62, -64},
+		{-60, -66}, {-62, -73}, {-45, -78}, {-30, -76}, {0, -70}, {30, -69.5},
+		{60, -67}, {90, -66}, {120, -66.5}, {150, -68}, {165, -71}, {170, -77},
+		{180, -78}, {180, -90}, {-180, -90},
+	},
+}
+
+// waterPolygons are inland seas cut out of the land polygons.
+var waterPolygons = [][][2]float64{
+	// Black Sea
+	{{28, 44}, {29, 41}, {41, 41}, {41.5, 42}, {38, 44.5}, {34, 46}, {30, 46.5}},
+	// Caspian Sea
+	{{47, 45}, {53, 47}, {54, 42}, {53, 37}, {49, 38}, {47, 43}},
+}
@@ -0,0 +1,291 @@
+package views
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// MapPoint is a labelled location plotted on a MapView.
+type MapPoint struct {
+	Label string
+	Lat   float64
+	Lon   float64
+}
+
+// MapBounds is a longitude/latitude rectangle.
+type MapBounds struct {
+	West, South, East, North float64
+}
+
+// MapRegions are the named areas a map can show. "world" leaves out most of
+// Antarctica, which would otherwise take a third of the rows.
+var MapRegions = map[string]MapBounds{
+	"world":         {-180, -60, 180, 85},
+	"north-america": {-170, 5, -50, 75},
+	"south-america": {-85, -57, -33, 13},
+	"europe":        {-25, 34, 45, 72},
+	"africa":        {-20, -36, 55, 38},
+	"asia":          {25, -10, 150, 75},
+	"oceania":       {105, -48, 180, 0},
+}
+
+// MaxMapZoom is the deepest zoom level; each level halves the view's span.
+const MaxMapZoom = 8
+
+// mapMarkerKeys label markers whose names do not fit beside them.
+const mapMarkerKeys = "123456789abcdefghijklmnopqrstuvwxyz"
+
+// MapView plots labelled points on an equirectangular projection of a world
+// outline, drawn with braille dots.
+type MapView struct {
+	title  string
+	points []MapPoint
+	region string
+	zoom   int
+	center *MapPoint
+	width  int
+}
+
+// NewMapView creates a map view showing the whole world.
+func NewMapView() *MapView {
+	return &MapView{region: "world"}
+}
+
+// SetTitle sets the map title.
+func (v *MapView) SetTitle(title string) {
+	v.title = title
+}
+
+// SetPoints sets the points to plot.
+func (v *MapView) SetPoints(points []MapPoint) {
+	v.points = points
+}
+
+// SetRegion selects one of MapRegions. An empty name selects "world".
+func (v *MapView) SetRegion(region string) error {
+	if region == "" {
+		region = "world"
+	}
+	if _, ok := MapRegions[region]; !ok {
+		return fmt.Errorf("unknown map region %q", region)
+	}
+	v.region = region
+	return nil
+}
+
+// SetZoom zooms into the region around center, halving the span at each
+// level. A nil center zooms around the middle of the plotted points.
+func (v *MapView) SetZoom(zoom int, center *MapPoint) {
+	v.zoom = max(0, min(zoom, MaxMapZoom))
+	v.center = center
+}
+
+// SetWidth sets the rendering width.
+func (v *MapView) SetWidth(width int) {
+	v.width = width
+}
+
+// Bounds returns the area the map shows after zooming.
+func (v *MapView) Bounds() MapBounds {
+	b := MapRegions[v.region]
+	if v.zoom == 0 {
+		return b
+	}
+
+	lonSpan := (b.East - b.West) / math.Exp2(float64(v.zoom))
+	latSpan := (b.North - b.South) / math.Exp2(float64(v.zoom))
+	lon, lat := (b.West+b.East)/2, (b.South+b.North)/2
+	switch {
+	case v.center != nil:
+		lon, lat = v.center.Lon, v.center.Lat
+	case len(v.points) > 0:
+		w, s, e, n := v.points[0].Lon, v.points[0].Lat, v.points[0].Lon, v.points[0].Lat
+		for _, p := range v.points[1:] {
+			w, e = math.Min(w, p.Lon), math.Max(e, p.Lon)
+			s, n = math.Min(s, p.Lat), math.Max(n, p.Lat)
+		}
+		lon, lat = (w+e)/2, (s+n)/2
+	}
+
+	// Shift rather than shrink the view to keep it on the map
+	west := math.Max(-180, math.Min(lon-lonSpan/2, 180-lonSpan))
+	south := math.Max(-90, math.Min(lat-latSpan/2, 90-latSpan))
+	return MapBounds{West: west, South: south, East: west + lonSpan, North: south + latSpan}
+}
+
+// mapCell is one character of the rendered map.
+type mapCell struct {
+	text  string
+	style lipgloss.Style
+	taken bool // holds a marker or label
+}
+
+// View renders the map with markers, labels and a legend for any labels
+// that did not fit.
+func (v *MapView) View() string {
+	colors := theme.Current.Colors
+	b := v.Bounds()
+
+	cols := 76
+	if v.width > 0 {
+		cols = max(20, v.width-6)
+	}
+	// Terminal cells are about twice as tall as wide
+	rows := int(math.Round(float64(cols) * (b.North - b.South) / (b.East - b.West) / 2))
+	rows = max(4, min(rows, 24))
+
+	land := lipgloss.NewStyle().Foreground(colors.Accent3)
+	grid := make([][]mapCell, rows)
+	for y := range grid {
+		grid[y] = make([]mapCell, cols)
+		for x := range grid[y] {
+			grid[y][x] = mapCell{text: brailleLand(b, cols, rows, x, y), style: land}
+		}
+	}
+
+	marker := lipgloss.NewStyle().Foreground(colors.Accent1).Bold(true)
+	label := lipgloss.NewStyle().Foreground(colors.Text)
+
+	type placed struct {
+		point MapPoint
+		x, y  int
+	}
+	var visible []placed
+	outside := 0
+	for _, p := range v.points {
+		x := int(math.Floor((p.Lon - b.West) / (b.East - b.West) * float64(cols)))
+		y := int(math.Floor((b.North - p.Lat) / (b.North - b.South) * float64(rows)))
+		if x < 0 || y < 0 || x >= cols || y >= rows {
+			outside++
+			continue
+		}
+		grid[y][x] = mapCell{text: theme.Glyphs.Filled, style: marker, taken: true}
+		visible = append(visible, placed{p, x, y})
+	}
+
+	var legend []string
+	for _, p := range visible {
+		if p.point.Label == "" {
+			continue
+		}
+		text := []rune(p.point.Label)
+		start := -1
+		if p.x+2+len(text) <= cols && mapFree(grid[p.y], p.x+1, p.x+2+len(text)) {
+			start = p.x + 2
+		} else if p.x-1-len(text) >= 0 && mapFree(grid[p.y], p.x-1-len(text), p.x) {
+			start = p.x - 1 - len(text)
+		}
+		if start >= 0 {
+			for i, r := range text {
+				grid[p.y][start+i] = mapCell{text: string(r), style: label, taken: true}
+			}
+			continue
+		}
+		if len(legend) < len(mapMarkerKeys) {
+			key := mapMarkerKeys[len(legend) : len(legend)+1]
+			grid[p.y][p.x].text = key
+			legend = append(legend, marker.Render(key)+" "+label.Render(p.point.Label))
+		}
+	}
+
+	var body strings.Builder
+	for y, row := range grid {
+		for _, cell := range row {
+			body.WriteString(cell.style.Render(cell.text))
+		}
+		if y < rows-1 {
+			body.WriteString("\n")
+		}
+	}
+
+	var sb strings.Builder
+	if v.title != "" {
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colors.Primary).Render(v.title))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(lipgloss.NewStyle().
+		Border(theme.Glyphs.Border).
+		BorderForeground(colors.Overlay).
+		Render(body.String()))
+
+	muted := lipgloss.NewStyle().Foreground(colors.TextMuted)
+	if len(legend) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(strings.Join(legend, "  "))
+	}
+	footer := v.region
+	if v.zoom > 0 {
+		footer += fmt.Sprintf(" · zoom %d", v.zoom)
+	}
+	if outside > 0 {
+		footer += fmt.Sprintf(" · %d outside view", outside)
+	}
+	sb.WriteString("\n")
+	sb.WriteString(muted.Render(footer))
+
+	return sb.String()
+}
+
+// mapFree reports whether row[from:to] holds no markers or labels.
+func mapFree(row []mapCell, from, to int) bool {
+	for _, cell := range row[from:to] {
+		if cell.taken {
+			return false
+		}
+	}
+	return true
+}
+
+// brailleLand renders the land in one cell as a braille character, sampling
+// a 2x4 grid of dots.
+func brailleLand(b MapBounds, cols, rows, x, y int) string {
+	// Dot bit for column dx, row dy of a braille cell
+	bits := [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+	var dots rune
+	for dx := 0; dx < 2; dx++ {
+		for dy := 0; dy < 4; dy++ {
+			lon := b.West + (float64(x*2+dx)+0.5)/float64(cols*2)*(b.East-b.West)
+			lat := b.North - (float64(y*4+dy)+0.5)/float64(rows*4)*(b.North-b.South)
+			if isLand(lon, lat) {
+				dots |= bits[dx][dy]
+			}
+		}
+	}
+	if dots == 0 {
+		return " "
+	}
+	return string(0x2800 + dots)
+}
+
+// isLand reports whether a coordinate falls on the simplified world outline.
+func isLand(lon, lat float64) bool {
+	for _, poly := range waterPolygons {
+		if inPolygon(poly, lon, lat) {
+			return false
+		}
+	}
+	for _, poly := range landPolygons {
+		if inPolygon(poly, lon, lat) {
+			return true
+		}
+	}
+	return false
+}
+
+// inPolygon is the even-odd ray casting test.
+func inPolygon(poly [][2]float64, lon, lat float64) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		xi, yi := poly[i][0], poly[i][1]
+		xj, yj := poly[j][0], poly[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,82 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+func TestIsLand(t *testing.T) {
+	tests := []struct {
+		name     string
+		lon, lat float64
+		want     bool
+	}{
+		{"Paris", 2.35, 48.9, true},
+		{"Kansas", -98, 38, true},
+		{"Sahara", 10, 23, true},
+		{"Sydney inland", 149, -33, true},
+		{"mid Atlantic", -40, 30, false},
+		{"Pacific", -150, 0, false},
+		{"Black Sea", 34, 43, false},
+		{"Caspian Sea", 51, 42, false},
+	}
+	for _, tt := range tests {
+		if got := isLand(tt.lon, tt.lat); got != tt.want {
+			t.Errorf("isLand(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMapBounds(t *testing.T) {
+	v := NewMapView()
+	if got := v.Bounds(); got != MapRegions["world"] {
+		t.Errorf("default bounds = %+v", got)
+	}
+
+	v.SetPoints([]MapPoint{{Lat: 40, Lon: -10}, {Lat: 60, Lon: 30}})
+	v.SetZoom(2, nil)
+	want := MapBounds{West: -35, South: 31.875, East: 55, North: 68.125}
+	if got := v.Bounds(); got != want {
+		t.Errorf("zoom around points = %+v, want %+v", got, want)
+	}
+
+	// Views near the edge shift to stay on the map
+	v.SetZoom(1, &MapPoint{Lat: 80, Lon: 170})
+	if got := v.Bounds(); got.East != 180 || got.North != 90 {
+		t.Errorf("clamped bounds = %+v", got)
+	}
+
+	if err := v.SetRegion("atlantis"); err == nil {
+		t.Error("expected error for unknown region")
+	}
+}
+
+func TestMapView(t *testing.T) {
+	theme.SetTheme("charm-dark")
+
+	v := NewMapView()
+	v.SetWidth(80)
+	v.SetTitle("Regions")
+	v.SetPoints([]MapPoint{
+		{Label: "Frankfurt", Lat: 50.1, Lon: 8.7},
+		{Label: "Sydney", Lat: -33.9, Lon: 151.2},
+		{Label: "Paris is a long label that fits nowhere on this row of the map", Lat: 48.9, Lon: 2.35},
+	})
+	if err := v.SetRegion("europe"); err != nil {
+		t.Fatal(err)
+	}
+	out := ansi.Strip(v.View())
+
+	for _, want := range []string{"Regions", "Frankfurt", "1 Paris is a long label", "europe · 1 outside view"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Sydney") {
+		t.Error("point outside the region should not be labelled")
+	}
+}
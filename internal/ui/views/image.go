@@ -0,0 +1,125 @@
+package views
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultImageRows is the most terminal rows an image is drawn in unless
+// set otherwise.
+const DefaultImageRows = 24
+
+// ImageView draws an image with half-block characters, two pixels to a
+// terminal cell, scaled down to fit the width and number of rows. Each
+// cell is the average of the pixels it covers, so it works in any
+// terminal with color, at a coarse resolution.
+type ImageView struct {
+	img   image.Image
+	width int
+	rows  int
+}
+
+// NewImageView creates a new image view.
+func NewImageView() *ImageView {
+	return &ImageView{rows: DefaultImageRows}
+}
+
+// SetImage sets the image to draw.
+func (v *ImageView) SetImage(img image.Image) {
+	v.img = img
+}
+
+// SetWidth sets the rendering width.
+func (v *ImageView) SetWidth(width int) {
+	v.width = width
+}
+
+// SetRows sets the most rows the image is drawn in.
+func (v *ImageView) SetRows(rows int) {
+	v.rows = rows
+}
+
+// size returns the size the image is drawn at, in cells and pixel rows,
+// keeping its aspect ratio and never scaling it up.
+func (v *ImageView) size() (int, int) {
+	b := v.img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0, 0
+	}
+	maxW, maxH := w, h
+	if v.width > 0 {
+		maxW = min(maxW, v.width)
+	}
+	if v.rows > 0 {
+		maxH = min(maxH, 2*v.rows)
+	}
+	// Cells are about twice as tall as wide, which the half blocks undo
+	scale := min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+	return max(1, int(float64(w)*scale)), max(2, int(float64(h)*scale))
+}
+
+// View draws the image.
+func (v *ImageView) View() string {
+	if v.img == nil {
+		return ""
+	}
+	cols, pixelRows := v.size()
+	if cols == 0 {
+		return ""
+	}
+	b := v.img.Bounds()
+	sample := func(x, y int) (lipgloss.Color, bool) {
+		x0 := b.Min.X + x*b.Dx()/cols
+		x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/cols)
+		y0 := b.Min.Y + y*b.Dy()/pixelRows
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/pixelRows)
+		return averageColor(v.img, image.Rect(x0, y0, x1, y1))
+	}
+
+	var sb strings.Builder
+	for y := 0; y < pixelRows; y += 2 {
+		for x := 0; x < cols; x++ {
+			top, topOpaque := sample(x, y)
+			bottom, bottomOpaque := sample(x, y+1)
+			if y+1 >= pixelRows {
+				bottomOpaque = false
+			}
+			switch {
+			case topOpaque && bottomOpaque:
+				sb.WriteString(lipgloss.NewStyle().Foreground(top).Background(bottom).Render("▀"))
+			case topOpaque:
+				sb.WriteString(lipgloss.NewStyle().Foreground(top).Render("▀"))
+			case bottomOpaque:
+				sb.WriteString(lipgloss.NewStyle().Foreground(bottom).Render("▄"))
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		if y+2 < pixelRows {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// averageColor returns the average color of the pixels of img in r, and
+// whether they are mostly opaque.
+func averageColor(img image.Image, r image.Rectangle) (lipgloss.Color, bool) {
+	var red, green, blue, alpha, n uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cr, cg, cb, ca := img.At(x, y).RGBA()
+			red, green, blue, alpha = red+uint64(cr), green+uint64(cg), blue+uint64(cb), alpha+uint64(ca)
+			n++
+		}
+	}
+	if n == 0 || alpha/n < 0x8000 {
+		return "", false
+	}
+	// The components are premultiplied by alpha
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", red*0xff/alpha, green*0xff/alpha, blue*0xff/alpha)), true
+}
@@ -0,0 +1,55 @@
+package views
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestImageViewSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		w, h        int
+		width, rows int
+		cols, lines int
+	}{
+		{"small image is not scaled up", 6, 4, 80, 24, 6, 2},
+		{"wide image fits the width", 200, 100, 50, 24, 50, 13},
+		{"tall image fits the rows", 100, 400, 80, 10, 5, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewImageView()
+			v.SetImage(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)))
+			v.SetWidth(tt.width)
+			v.SetRows(tt.rows)
+			// A transparent image draws as spaces, to measure
+			out := v.View()
+			lines := strings.Split(out, "\n")
+			if len(lines) != tt.lines || ansi.StringWidth(lines[0]) != tt.cols {
+				t.Errorf("drew %d lines of %d cells, want %d of %d", len(lines), ansi.StringWidth(lines[0]), tt.lines, tt.cols)
+			}
+		})
+	}
+}
+
+func TestImageViewColors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	img.Set(0, 0, red)
+	img.Set(0, 1, blue)
+	img.Set(1, 1, blue) // the top right pixel is transparent
+
+	v := NewImageView()
+	v.SetImage(img)
+	got := v.View()
+	want := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Background(lipgloss.Color("#0000ff")).Render("▀") +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#0000ff")).Render("▄")
+	if got != want {
+		t.Errorf("View() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package views
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Hyperlink modes accepted by the --links flag.
+const (
+	LinksAuto = "auto"
+	LinksOn   = "on"
+	LinksOff  = "off"
+)
+
+// hyperlinks controls whether URLs are emitted as OSC 8 hyperlinks.
+var hyperlinks bool
+
+// urlPattern matches bare http(s) URLs, stopping at whitespace and escapes.
+var urlPattern = regexp.MustCompile(`https?://[^\s\x1b<>"]+`)
+
+// SetHyperlinks enables or disables OSC 8 hyperlink output.
+func SetHyperlinks(enabled bool) {
+	hyperlinks = enabled
+}
+
+// HyperlinksEnabled reports whether OSC 8 hyperlinks are emitted.
+func HyperlinksEnabled() bool {
+	return hyperlinks
+}
+
+// ResolveHyperlinks turns a --links mode into on/off, detecting terminal
+// support for "auto" from the environment.
+func ResolveHyperlinks(mode string, getenv func(string) string) bool {
+	switch mode {
+	case LinksOn:
+		return true
+	case LinksOff:
+		return false
+	}
+	return DetectHyperlinks(getenv)
+}
+
+// DetectHyperlinks reports whether the terminal is known to support OSC 8.
+// Unknown terminals are assumed not to, so output degrades to plain text.
+func DetectHyperlinks(getenv func(string) string) bool {
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "tabby":
+		return true
+	}
+	if getenv("WT_SESSION") != "" || getenv("KITTY_WINDOW_ID") != "" {
+		return true
+	}
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	term := getenv("TERM")
+	return strings.Contains(term, "kitty") || strings.Contains(term, "foot") || strings.Contains(term, "alacritty")
+}
+
+// Hyperlink renders text as a link to url when hyperlinks are enabled, and
+// returns text unchanged otherwise.
+func Hyperlink(url, text string) string {
+	if !hyperlinks || url == "" {
+		return text
+	}
+	return ansi.SetHyperlink(url) + text + ansi.ResetHyperlink()
+}
+
+// Linkify turns bare URLs in already-rendered text into hyperlinks. Text that
+// already contains hyperlinks is returned unchanged.
+func Linkify(s string) string {
+	if !hyperlinks || strings.Contains(s, "\x1b]8;") {
+		return s
+	}
+	return urlPattern.ReplaceAllStringFunc(s, func(match string) string {
+		url := trimURL(match)
+		return Hyperlink(url, url) + match[len(url):]
+	})
+}
+
+// URLs returns the http(s) URLs in s, each once, in the order they appear.
+func URLs(s string) []string {
+	var urls []string
+	for _, match := range urlPattern.FindAllString(s, -1) {
+		if url := trimURL(match); !slices.Contains(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// trimURL drops punctuation ending a sentence or bracket after a URL.
+func trimURL(match string) string {
+	return strings.TrimRight(match, ".,;:!?)]}'")
+}
+
+// isURL reports whether s is a single http(s) URL.
+func isURL(s string) bool {
+	loc := urlPattern.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
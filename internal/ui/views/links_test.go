@@ -0,0 +1,90 @@
+package views
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLinkify(t *testing.T) {
+	defer SetHyperlinks(false)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		in      string
+		want    string
+	}{
+		{
+			name:    "disabled leaves text alone",
+			enabled: false,
+			in:      "see https://example.com",
+			want:    "see https://example.com",
+		},
+		{
+			name:    "wraps bare URL",
+			enabled: true,
+			in:      "see https://example.com/docs",
+			want:    "see \x1b]8;;https://example.com/docs\x07https://example.com/docs\x1b]8;;\x07",
+		},
+		{
+			name:    "keeps trailing punctuation outside the link",
+			enabled: true,
+			in:      "(https://example.com).",
+			want:    "(\x1b]8;;https://example.com\x07https://example.com\x1b]8;;\x07).",
+		},
+		{
+			name:    "already linked text is unchanged",
+			enabled: true,
+			in:      "\x1b]8;;https://a.dev\x07a\x1b]8;;\x07 https://b.dev",
+			want:    "\x1b]8;;https://a.dev\x07a\x1b]8;;\x07 https://b.dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetHyperlinks(tt.enabled)
+			if got := Linkify(tt.in); got != tt.want {
+				t.Errorf("Linkify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLs(t *testing.T) {
+	got := URLs("See [the docs](https://a.dev/docs), https://b.dev. And https://a.dev/docs again")
+	want := []string{"https://a.dev/docs", "https://b.dev"}
+	if !slices.Equal(got, want) {
+		t.Errorf("URLs() = %q, want %q", got, want)
+	}
+	if got := URLs("no links"); got != nil {
+		t.Errorf("URLs() = %q, want none", got)
+	}
+}
+
+func TestResolveHyperlinks(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		env  map[string]string
+		want bool
+	}{
+		{name: "forced on", mode: LinksOn, want: true},
+		{name: "forced off", mode: LinksOff, env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: false},
+		{name: "auto iTerm", mode: LinksAuto, env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: true},
+		{name: "auto new VTE", mode: LinksAuto, env: map[string]string{"VTE_VERSION": "6003"}, want: true},
+		{name: "auto old VTE", mode: LinksAuto, env: map[string]string{"VTE_VERSION": "4800"}, want: false},
+		{name: "auto unknown terminal", mode: LinksAuto, env: map[string]string{"TERM": "xterm-256color"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveHyperlinks(tt.mode, env(tt.env)); got != tt.want {
+				t.Errorf("ResolveHyperlinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
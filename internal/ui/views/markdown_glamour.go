@@ -0,0 +1,30 @@
+//go:build !noglamour
+
+package views
+
+import "github.com/charmbracelet/glamour"
+
+// markdownRenderer renders markdown with glamour. Build with the noglamour
+// tag to leave glamour out of the binary.
+type markdownRenderer = glamour.TermRenderer
+
+func newMarkdownRenderer(width int) *markdownRenderer {
+	if width <= 0 {
+		width = 80
+	}
+
+	// Create renderer with dark style
+	// TODO: Customize colors to match theme once we have color conversion helper
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle("dark"),
+		glamour.WithWordWrap(width-4),
+	)
+	if err != nil {
+		// Fallback to auto style
+		r, _ = glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(width-4),
+		)
+	}
+	return r
+}
@@ -0,0 +1,71 @@
+//go:build noglamour
+
+package views
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// markdownRenderer is the lightweight stand-in for glamour used in
+// noglamour builds. It styles headings, code blocks and inline emphasis and
+// wraps the rest as plain text.
+type markdownRenderer struct {
+	width int
+}
+
+func newMarkdownRenderer(width int) *markdownRenderer {
+	if width <= 0 {
+		width = 80
+	}
+	return &markdownRenderer{width: width - 4}
+}
+
+// Render renders markdown to styled text.
+func (r *markdownRenderer) Render(markdown string) (string, error) {
+	colors := theme.Current.Colors
+	heading := lipgloss.NewStyle().Bold(true).Foreground(colors.Primary)
+	code := lipgloss.NewStyle().Foreground(colors.Accent2)
+	text := lipgloss.NewStyle().Width(r.width)
+
+	var out []string
+	fenced := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			fenced = !fenced
+		case fenced:
+			out = append(out, code.Render("  "+line))
+		case strings.HasPrefix(trimmed, "#"):
+			out = append(out, heading.Render(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+		default:
+			out = append(out, text.Render(renderInline(line)))
+		}
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// inlineMarkup matches **strong**, *emphasized* and `code` text.
+var inlineMarkup = regexp.MustCompile(`\*\*([^*]+)\*\*|\*([^*\s][^*]*)\*|` + "`([^`]+)`")
+
+// renderInline styles the emphasis and inline code in line in place of
+// their markers.
+func renderInline(line string) string {
+	colors := theme.Current.Colors
+	return inlineMarkup.ReplaceAllStringFunc(line, func(m string) string {
+		parts := inlineMarkup.FindStringSubmatch(m)
+		switch {
+		case parts[1] != "":
+			return lipgloss.NewStyle().Bold(true).Render(parts[1])
+		case parts[2] != "":
+			return lipgloss.NewStyle().Italic(true).Render(parts[2])
+		default:
+			return lipgloss.NewStyle().Foreground(colors.Accent2).Render(parts[3])
+		}
+	})
+}
@@ -0,0 +1,24 @@
+//go:build noglamour
+
+package views
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRenderInline(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Some **bold** text", "Some bold text"},
+		{"an *emphasized* word", "an emphasized word"},
+		{"run `go test`", "run go test"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+		{"snake_case_name", "snake_case_name"},
+	}
+	for _, tt := range tests {
+		if got := ansi.Strip(renderInline(tt.in)); got != tt.want {
+			t.Errorf("renderInline(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
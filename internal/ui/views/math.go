@@ -0,0 +1,505 @@
+package views
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// texSymbols maps LaTeX commands to Unicode.
+var texSymbols = map[string]string{
+	// Greek
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε",
+	"varepsilon": "ε", "zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ",
+	"iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ",
+	"pi": "π", "varpi": "ϖ", "rho": "ρ", "sigma": "σ", "tau": "τ",
+	"upsilon": "υ", "phi": "φ", "varphi": "φ", "chi": "χ", "psi": "ψ",
+	"omega": "ω", "Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ",
+	"Xi": "Ξ", "Pi": "Π", "Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ",
+	"Omega": "Ω",
+
+	// Operators and relations
+	"times": "×", "cdot": "·", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠",
+	"approx": "≈", "sim": "∼", "simeq": "≃", "cong": "≅", "equiv": "≡",
+	"propto": "∝", "ll": "≪", "gg": "≫", "circ": "∘", "bullet": "•",
+	"sum": "∑", "prod": "∏", "int": "∫", "iint": "∬", "oint": "∮",
+	"partial": "∂", "nabla": "∇", "infty": "∞", "prime": "′", "degree": "°",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆",
+	"supset": "⊃", "supseteq": "⊇", "cup": "∪", "cap": "∩", "emptyset": "∅",
+	"varnothing": "∅", "forall": "∀", "exists": "∃", "neg": "¬", "lnot": "¬",
+	"land": "∧", "wedge": "∧", "lor": "∨", "vee": "∨", "oplus": "⊕",
+	"otimes": "⊗", "perp": "⊥", "parallel": "∥", "angle": "∠",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "iff": "⇔",
+	"implies": "⇒", "mapsto": "↦", "uparrow": "↑", "downarrow": "↓",
+	"ldots": "…", "cdots": "⋯", "dots": "…", "vdots": "⋮", "ddots": "⋱",
+	"hbar": "ℏ", "ell": "ℓ", "Re": "ℜ", "Im": "ℑ", "aleph": "ℵ",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋",
+	"lceil": "⌈", "rceil": "⌉", "mid": "∣", "vert": "|", "Vert": "‖",
+	"star": "⋆", "dagger": "†",
+
+	// Function names print as written
+	"sin": "sin", "cos": "cos", "tan": "tan", "log": "log", "ln": "ln",
+	"exp": "exp", "lim": "lim", "max": "max", "min": "min", "det": "det",
+	"arcsin": "arcsin", "arccos": "arccos", "arctan": "arctan",
+	"sinh": "sinh", "cosh": "cosh", "tanh": "tanh", "sup": "sup", "inf": "inf",
+
+	// Spacing
+	"quad": "  ", "qquad": "    ",
+}
+
+// texAccents are the combining characters for accent commands.
+var texAccents = map[string]string{
+	"hat": "\u0302", "bar": "\u0304", "vec": "\u20d7", "dot": "\u0307",
+	"tilde": "\u0303", "overline": "\u0305",
+}
+
+// texDoubleStruck maps \mathbb letters.
+var texDoubleStruck = map[rune]string{
+	'R': "ℝ", 'N': "ℕ", 'Z': "ℤ", 'Q': "ℚ", 'C': "ℂ", 'P': "ℙ", 'H': "ℍ",
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶',
+	'7': '⁷', '8': '⁸', '9': '⁹', '+': '⁺', '-': '⁻', '−': '⁻', '=': '⁼',
+	'(': '⁽', ')': '⁾', 'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ',
+	'f': 'ᶠ', 'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ',
+	'm': 'ᵐ', 'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ',
+	'u': 'ᵘ', 'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ', 'A': 'ᴬ',
+	'B': 'ᴮ', 'D': 'ᴰ', 'E': 'ᴱ', 'G': 'ᴳ', 'H': 'ᴴ', 'I': 'ᴵ', 'J': 'ᴶ',
+	'K': 'ᴷ', 'L': 'ᴸ', 'M': 'ᴹ', 'N': 'ᴺ', 'O': 'ᴼ', 'P': 'ᴾ', 'R': 'ᴿ',
+	'T': 'ᵀ', 'U': 'ᵁ', 'V': 'ⱽ', 'W': 'ᵂ', '′': '′', '°': '°', '∘': '°',
+	'*': '*', ' ': ' ',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆',
+	'7': '₇', '8': '₈', '9': '₉', '+': '₊', '-': '₋', '−': '₋', '=': '₌',
+	'(': '₍', ')': '₎', 'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ',
+	'k': 'ₖ', 'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ',
+	's': 'ₛ', 't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ', ' ': ' ',
+}
+
+// vulgarFractions are the single-character fractions Unicode provides.
+var vulgarFractions = map[string]string{
+	"1/2": "½", "1/3": "⅓", "2/3": "⅔", "1/4": "¼", "3/4": "¾", "1/5": "⅕",
+	"2/5": "⅖", "3/5": "⅗", "4/5": "⅘", "1/6": "⅙", "5/6": "⅚", "1/8": "⅛",
+	"3/8": "⅜", "5/8": "⅝", "7/8": "⅞",
+}
+
+// TexToUnicode approximates a LaTeX math expression with Unicode: Greek
+// letters and symbols, super- and subscripts, fractions and roots.
+// Unknown commands are left as written.
+func TexToUnicode(tex string) string {
+	p := texParser{src: []rune(tex)}
+	return strings.TrimSpace(p.parse(false))
+}
+
+type texParser struct {
+	src []rune
+	pos int
+}
+
+// parse converts until the end of input, or until the closing brace of the
+// current group when inGroup is set.
+func (p *texParser) parse(inGroup bool) string {
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch r {
+		case '}':
+			p.pos++
+			if inGroup {
+				return sb.String()
+			}
+		case '{':
+			p.pos++
+			sb.WriteString(p.parse(true))
+		case '^':
+			p.pos++
+			sb.WriteString(script(p.arg(), superscripts, "^"))
+		case '_':
+			p.pos++
+			sb.WriteString(script(p.arg(), subscripts, "_"))
+		case '~':
+			p.pos++
+			sb.WriteRune(' ')
+		case '\\':
+			sb.WriteString(p.command())
+		default:
+			p.pos++
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// arg reads one argument: a braced group, a command or a single character.
+func (p *texParser) arg() string {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	switch p.src[p.pos] {
+	case '{':
+		p.pos++
+		return p.parse(true)
+	case '\\':
+		return p.command()
+	}
+	p.pos++
+	return string(p.src[p.pos-1])
+}
+
+// rawArg reads a braced argument without converting it.
+func (p *texParser) rawArg() string {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+		return p.arg()
+	}
+	depth, start := 0, p.pos+1
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return string(p.src[start : p.pos-1])
+			}
+		}
+	}
+	return string(p.src[start:])
+}
+
+// command converts the command starting at the current backslash.
+func (p *texParser) command() string {
+	p.pos++ // backslash
+	if p.pos >= len(p.src) {
+		return "\\"
+	}
+	start := p.pos
+	for p.pos < len(p.src) && unicode.IsLetter(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		// Single-character command: escapes, spacing and line breaks
+		r := p.src[p.pos]
+		p.pos++
+		switch r {
+		case ',', ':', ';', ' ':
+			return " "
+		case '!':
+			return ""
+		case '\\':
+			return "\n"
+		}
+		return string(r)
+	}
+
+	name := string(p.src[start:p.pos])
+	switch name {
+	case "frac", "dfrac", "tfrac":
+		return fraction(p.arg(), p.arg())
+	case "sqrt":
+		index := ""
+		if p.pos < len(p.src) && p.src[p.pos] == '[' {
+			end := p.pos
+			for end < len(p.src) && p.src[end] != ']' {
+				end++
+			}
+			index = string(p.src[p.pos+1 : min(end, len(p.src))])
+			p.pos = min(end+1, len(p.src))
+		}
+		root := "√"
+		switch index {
+		case "3":
+			root = "∛"
+		case "4":
+			root = "∜"
+		case "":
+		default:
+			root = script(index, superscripts, "") + "√"
+		}
+		return root + wrap(p.arg())
+	case "text", "textrm", "textbf", "textit", "mbox":
+		return p.rawArg()
+	case "mathrm", "mathbf", "mathit", "mathsf", "mathtt", "boldsymbol", "operatorname":
+		return p.arg()
+	case "mathbb":
+		var sb strings.Builder
+		for _, r := range p.arg() {
+			if ds, ok := texDoubleStruck[r]; ok {
+				sb.WriteString(ds)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+		return sb.String()
+	case "left", "right", "big", "Big", "bigg", "Bigg":
+		if p.pos < len(p.src) && p.src[p.pos] == '.' {
+			p.pos++
+		}
+		return ""
+	case "hat", "bar", "vec", "dot", "tilde", "overline":
+		a := p.arg()
+		if len([]rune(a)) == 1 {
+			return a + texAccents[name]
+		}
+		return a
+	}
+	if sym, ok := texSymbols[name]; ok {
+		return sym
+	}
+	return "\\" + name
+}
+
+// script converts s to super- or subscript characters, falling back to
+// marker(s) when some character has no Unicode form.
+func script(s string, table map[rune]rune, marker string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		mapped, ok := table[r]
+		if !ok {
+			if len([]rune(s)) == 1 {
+				return marker + s
+			}
+			return marker + "(" + s + ")"
+		}
+		sb.WriteRune(mapped)
+	}
+	return sb.String()
+}
+
+// fraction renders num/den, using a vulgar fraction or super/subscript digits
+// for numeric fractions.
+func fraction(num, den string) string {
+	num, den = strings.TrimSpace(num), strings.TrimSpace(den)
+	if v, ok := vulgarFractions[num+"/"+den]; ok {
+		return v
+	}
+	if isDigits(num) && isDigits(den) {
+		return script(num, superscripts, "") + "⁄" + script(den, subscripts, "")
+	}
+	return wrap(num) + "/" + wrap(den)
+}
+
+// wrap parenthesizes s unless it is a single term.
+func wrap(s string) string {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && !strings.ContainsRune("⁰¹²³⁴⁵⁶⁷⁸⁹₀₁₂₃₄₅₆₇₈₉′", r) {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ConvertMath replaces $...$ and $$...$$ math in markdown with Unicode
+// approximations, leaving code spans and fenced code blocks alone. As in
+// pandoc, an opening $ must not be followed by a space and a closing $ must
+// not be preceded by a space or followed by a digit, so prices like $5 and
+// $10 stay as written.
+func ConvertMath(markdown string) string {
+	if !strings.Contains(markdown, "$") {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+	out := make([]string, 0, len(lines))
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			inFence = !inFence
+			out = append(out, line)
+		case inFence:
+			out = append(out, line)
+		case trimmed == "$$":
+			// Display math over several lines, up to the closing $$
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "$$" {
+				end++
+			}
+			if end == len(lines) {
+				out = append(out, line)
+				continue
+			}
+			tex := strings.Join(lines[i+1:end], "\n")
+			for _, l := range strings.Split(TexToUnicode(tex), "\n") {
+				out = append(out, escapeMarkdown(strings.TrimSpace(l))+"  ")
+			}
+			i = end
+		default:
+			out = append(out, convertMathLine(line))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// convertMathLine converts math outside the backtick code spans of a line.
+func convertMathLine(line string) string {
+	var sb strings.Builder
+	rs := []rune(line)
+	for i := 0; i < len(rs); {
+		switch {
+		case rs[i] == '`':
+			// Copy the code span through its closing run of backticks
+			n := 0
+			for i+n < len(rs) && rs[i+n] == '`' {
+				n++
+			}
+			end := indexRun(rs, i+n, '`', n)
+			if end < 0 {
+				sb.WriteString(string(rs[i:]))
+				return sb.String()
+			}
+			sb.WriteString(string(rs[i : end+n]))
+			i = end + n
+		case rs[i] == '\\' && i+1 < len(rs) && rs[i+1] == '$':
+			sb.WriteString(`\$`)
+			i += 2
+		case rs[i] == '$':
+			display := i+1 < len(rs) && rs[i+1] == '$'
+			open := 1
+			if display {
+				open = 2
+			}
+			end := closingDollar(rs, i+open, display)
+			if end < 0 {
+				sb.WriteRune('$')
+				i++
+				continue
+			}
+			tex := string(rs[i+open : end])
+			sb.WriteString(escapeMarkdown(TexToUnicode(tex)))
+			i = end + open
+		default:
+			sb.WriteRune(rs[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// closingDollar finds the $ (or $$) closing math that opens at start, or -1.
+func closingDollar(rs []rune, start int, display bool) int {
+	if start >= len(rs) || (!display && unicode.IsSpace(rs[start])) {
+		return -1
+	}
+	for j := start; j < len(rs); j++ {
+		if rs[j] == '\\' {
+			j++
+			continue
+		}
+		if rs[j] != '$' {
+			continue
+		}
+		if display {
+			if j+1 < len(rs) && rs[j+1] == '$' && j > start {
+				return j
+			}
+			continue
+		}
+		if j == start || unicode.IsSpace(rs[j-1]) || (j+1 < len(rs) && unicode.IsDigit(rs[j+1])) {
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// indexRun finds the next run of exactly n copies of r at or after start.
+func indexRun(rs []rune, start int, r rune, n int) int {
+	for j := start; j < len(rs); {
+		if rs[j] != r {
+			j++
+			continue
+		}
+		k := j
+		for k < len(rs) && rs[k] == r {
+			k++
+		}
+		if k-j == n {
+			return j
+		}
+		j = k
+	}
+	return -1
+}
+
+// escapeMarkdown escapes characters markdown would treat as emphasis.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer(`*`, `\*`, `_`, `\_`).Replace(s)
+}
+
+// MathView renders a LaTeX expression as centered Unicode display math.
+type MathView struct {
+	tex   string
+	title string
+	width int
+}
+
+// NewMathView creates a new math view.
+func NewMathView() *MathView {
+	return &MathView{}
+}
+
+// SetTex sets the LaTeX source. Lines are separated with \\.
+func (v *MathView) SetTex(tex string) {
+	v.tex = tex
+}
+
+// SetTitle sets an optional title.
+func (v *MathView) SetTitle(title string) {
+	v.title = title
+}
+
+// SetWidth sets the rendering width.
+func (v *MathView) SetWidth(width int) {
+	v.width = width
+}
+
+// View renders the expression.
+func (v *MathView) View() string {
+	colors := theme.Current.Colors
+	var sb strings.Builder
+
+	if v.title != "" {
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colors.Primary).Render(v.title))
+		sb.WriteString("\n")
+	}
+
+	style := lipgloss.NewStyle().Foreground(colors.Accent2)
+	if v.width > 0 {
+		style = style.Width(v.width - 4).Align(lipgloss.Center)
+	}
+	lines := strings.Split(TexToUnicode(v.tex), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	sb.WriteString(style.Render(strings.Join(lines, "\n")))
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package views
+
+import "testing"
+
+func TestTexToUnicode(t *testing.T) {
+	tests := []struct {
+		tex  string
+		want string
+	}{
+		{`E = mc^2`, "E = mc²"},
+		{`x_{i+1} = x_i - \alpha \nabla f`, "xᵢ₊₁ = xᵢ - α ∇ f"},
+		{`\frac{1}{2} + \frac{3}{16} + \frac{a+b}{c}`, "½ + ³⁄₁₆ + (a+b)/c"},
+		{`\sqrt{2} \sqrt[3]{x} \sqrt{a+b}`, "√2 ∛x √(a+b)"},
+		{`\sum_{n=0}^{\infty} \frac{x^n}{n!}`, "∑ₙ₌₀^∞ xⁿ/(n!)"},
+		{`e^{i\pi} + 1 = 0`, "e^(iπ) + 1 = 0"},
+		{`f: \mathbb{R} \to \mathbb{R}`, "f: ℝ → ℝ"},
+		{`\left( \frac{\partial u}{\partial t} \right)`, "( (∂ u)/(∂ t) )"},
+		{`30^\circ`, "30°"},
+		{`\text{if } x \geq 0`, "if  x ≥ 0"},
+		{`a \\ b`, "a \n b"},
+		{`\unknown{x}`, `\unknownx`},
+	}
+	for _, tt := range tests {
+		if got := TexToUnicode(tt.tex); got != tt.want {
+			t.Errorf("TexToUnicode(%q) = %q, want %q", tt.tex, got, tt.want)
+		}
+	}
+}
+
+func TestConvertMath(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"inline", `Energy is $E = mc^2$.`, "Energy is E = mc²."},
+		{"display on one line", `$$\alpha + \beta$$`, "α + β"},
+		{"display block", "$$\n\\pi r^2\n$$", "π r²  "},
+		{"prices are not math", "costs $5 and $10", "costs $5 and $10"},
+		{"space after opening", "$ x$", "$ x$"},
+		{"escaped dollar", `\$x$`, `\$x$`},
+		{"code span", "`$x^2$` and $x^2$", "`$x^2$` and x²"},
+		{"fenced code", "```\n$x^2$\n```", "```\n$x^2$\n```"},
+		{"markdown in output is escaped", `$a_{bc}$`, `a\_(bc)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertMath(tt.in); got != tt.want {
+				t.Errorf("ConvertMath(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
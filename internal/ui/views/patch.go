@@ -0,0 +1,44 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ApplyCodePatch edits code line by line and returns the new code along with
+// the 1-based numbers of the lines that were written. op is "append" or
+// "replace"; for "replace", lines start through end (inclusive) are swapped
+// for the new lines, with end = start-1 meaning a pure insertion.
+func ApplyCodePatch(code, op string, start, end int, lines []string) (string, []int, error) {
+	var current []string
+	if code != "" {
+		current = strings.Split(code, "\n")
+	}
+
+	switch op {
+	case "append":
+		start = len(current) + 1
+		end = len(current)
+	case "replace":
+		if start < 1 || start > len(current)+1 {
+			return code, nil, fmt.Errorf("start line %d out of range (1-%d)", start, len(current)+1)
+		}
+		if end < start-1 || end > len(current) {
+			return code, nil, fmt.Errorf("end line %d out of range (%d-%d)", end, start-1, len(current))
+		}
+	default:
+		return code, nil, fmt.Errorf("unknown patch op %q", op)
+	}
+
+	patched := make([]string, 0, len(current)-(end-start+1)+len(lines))
+	patched = append(patched, current[:start-1]...)
+	patched = append(patched, lines...)
+	patched = append(patched, current[end:]...)
+
+	changed := make([]int, len(lines))
+	for i := range lines {
+		changed[i] = start + i
+	}
+
+	return strings.Join(patched, "\n"), changed, nil
+}
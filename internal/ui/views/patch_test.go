@@ -0,0 +1,55 @@
+package views
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyCodePatch(t *testing.T) {
+	const code = "a\nb\nc"
+
+	tests := []struct {
+		name        string
+		code        string
+		op          string
+		start, end  int
+		lines       []string
+		want        string
+		wantChanged []int
+		wantErr     bool
+	}{
+		{name: "append", code: code, op: "append", lines: []string{"d", "e"}, want: "a\nb\nc\nd\ne", wantChanged: []int{4, 5}},
+		{name: "append to empty", code: "", op: "append", lines: []string{"x"}, want: "x", wantChanged: []int{1}},
+		{name: "replace middle", code: code, op: "replace", start: 2, end: 2, lines: []string{"B"}, want: "a\nB\nc", wantChanged: []int{2}},
+		{name: "replace with more lines", code: code, op: "replace", start: 1, end: 2, lines: []string{"1", "2", "3"}, want: "1\n2\n3\nc", wantChanged: []int{1, 2, 3}},
+		{name: "insert", code: code, op: "replace", start: 2, end: 1, lines: []string{"x"}, want: "a\nx\nb\nc", wantChanged: []int{2}},
+		{name: "delete", code: code, op: "replace", start: 2, end: 3, want: "a", wantChanged: []int{}},
+		{name: "start out of range", code: code, op: "replace", start: 5, end: 5, wantErr: true},
+		{name: "end out of range", code: code, op: "replace", start: 2, end: 4, wantErr: true},
+		{name: "unknown op", code: code, op: "prepend", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := ApplyCodePatch(tt.code, tt.op, tt.start, tt.end, tt.lines)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if got != tt.code {
+					t.Errorf("code changed on error: %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("code = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/flight505/agentui/internal/qr"
+	"github.com/flight505/agentui/internal/theme"
+)
+
+// qrQuietZone is the light border, in modules, scanners need around a code.
+const qrQuietZone = 4
+
+// QRCodeView renders a QR code using half-block characters, two modules per
+// terminal row. The code is always drawn dark-on-light regardless of theme
+// so phones can scan it.
+type QRCodeView struct {
+	code    *qr.Code
+	data    string
+	title   string
+	caption string
+	width   int
+}
+
+// NewQRCodeView creates a new QR code view.
+func NewQRCodeView() *QRCodeView {
+	return &QRCodeView{}
+}
+
+// SetData encodes data at the given error correction level.
+func (q *QRCodeView) SetData(data string, level qr.Level) error {
+	code, err := qr.Encode([]byte(data), level)
+	if err != nil {
+		return err
+	}
+	q.code = code
+	q.data = data
+	return nil
+}
+
+// SetTitle sets the title shown above the code.
+func (q *QRCodeView) SetTitle(title string) {
+	q.title = title
+}
+
+// SetCaption sets the caption shown below the code.
+func (q *QRCodeView) SetCaption(caption string) {
+	q.caption = caption
+}
+
+// SetWidth sets the rendering width.
+func (q *QRCodeView) SetWidth(width int) {
+	q.width = width
+}
+
+// View renders the code with its title, data and caption. If the terminal is
+// too narrow for the code only the text is shown.
+func (q *QRCodeView) View() string {
+	if q.code == nil {
+		return ""
+	}
+
+	colors := theme.Current.Colors
+	var sb strings.Builder
+
+	if q.title != "" {
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colors.Primary).Render(q.title))
+		sb.WriteString("\n")
+	}
+
+	size := q.code.Size + 2*qrQuietZone
+	if q.width > 0 && size > q.width-4 {
+		sb.WriteString(lipgloss.NewStyle().Foreground(colors.Warning).Render("Terminal too narrow to show QR code"))
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString(q.renderCode())
+	}
+
+	data := q.data
+	if isURL(data) {
+		data = Hyperlink(data, data)
+	}
+	sb.WriteString(lipgloss.NewStyle().Foreground(colors.Text).Render(data))
+	if q.caption != "" {
+		sb.WriteString("\n")
+		sb.WriteString(lipgloss.NewStyle().Foreground(colors.TextMuted).Italic(true).Render(q.caption))
+	}
+
+	return sb.String()
+}
+
+// renderCode draws the modules, pairing rows into upper and lower halves of
+// each character cell.
+func (q *QRCodeView) renderCode() string {
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color("#FFFFFF"))
+
+	var sb strings.Builder
+	for y := -qrQuietZone; y < q.code.Size+qrQuietZone; y += 2 {
+		var row strings.Builder
+		for x := -qrQuietZone; x < q.code.Size+qrQuietZone; x++ {
+			top, bottom := q.code.Dark(x, y), q.code.Dark(x, y+1)
+			switch {
+			case top && bottom:
+				row.WriteString("█")
+			case top:
+				row.WriteString("▀")
+			case bottom:
+				row.WriteString("▄")
+			default:
+				row.WriteString(" ")
+			}
+		}
+		sb.WriteString(style.Render(row.String()))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -3,6 +3,7 @@ package views
 
 import (
 	"bytes"
+	"cmp"
 	"strconv"
 	"strings"
 
@@ -10,7 +11,6 @@ import (
 	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
-	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/flight505/agentui/internal/theme"
@@ -21,7 +21,7 @@ type MarkdownView struct {
 	content  string
 	title    string
 	width    int
-	renderer *glamour.TermRenderer
+	renderer *markdownRenderer
 }
 
 // NewMarkdownView creates a new markdown view.
@@ -45,32 +45,11 @@ func (m *MarkdownView) SetWidth(width int) {
 	m.renderer = nil // Reset renderer to rebuild with new width
 }
 
-func (m *MarkdownView) getRenderer() *glamour.TermRenderer {
-	if m.renderer != nil {
-		return m.renderer
+func (m *MarkdownView) getRenderer() *markdownRenderer {
+	if m.renderer == nil {
+		m.renderer = newMarkdownRenderer(m.width)
 	}
-
-	width := m.width
-	if width <= 0 {
-		width = 80
-	}
-
-	// Create renderer with dark style
-	// TODO: Customize colors to match theme once we have color conversion helper
-	r, err := glamour.NewTermRenderer(
-		glamour.WithStandardStyle("dark"),
-		glamour.WithWordWrap(width-4),
-	)
-	if err != nil {
-		// Fallback to auto style
-		r, _ = glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
-			glamour.WithWordWrap(width-4),
-		)
-	}
-
-	m.renderer = r
-	return r
+	return m.renderer
 }
 
 // View renders the markdown.
@@ -88,7 +67,7 @@ func (m *MarkdownView) View() string {
 	}
 
 	renderer := m.getRenderer()
-	rendered, err := renderer.Render(m.content)
+	rendered, err := renderer.Render(ConvertMath(m.content))
 	if err != nil {
 		// Fallback to plain text
 		sb.WriteString(m.content)
@@ -190,12 +169,13 @@ func (t *TableView) View() string {
 	}
 
 	// Top border
-	sb.WriteString(t.renderBorder("┌", "┬", "┐", "─", colWidths))
+	b := theme.Glyphs.Table
+	sb.WriteString(t.renderBorder(b.TopLeft, b.MiddleTop, b.TopRight, b.Top, colWidths))
 	sb.WriteString("\n")
 
 	// Header
 	headerStyle := styles.TableHeader
-	sb.WriteString("│")
+	sb.WriteString(b.Left)
 	for i, col := range t.columns {
 		cell := lipgloss.NewStyle().
 			Width(colWidths[i]).
@@ -204,12 +184,12 @@ func (t *TableView) View() string {
 			Render(truncate(col, colWidths[i]))
 		sb.WriteString(" ")
 		sb.WriteString(cell)
-		sb.WriteString(" │")
+		sb.WriteString(" " + b.Right)
 	}
 	sb.WriteString("\n")
 
 	// Header/body separator
-	sb.WriteString(t.renderBorder("├", "┼", "┤", "─", colWidths))
+	sb.WriteString(t.renderBorder(b.MiddleLeft, b.Middle, b.MiddleRight, b.Top, colWidths))
 	sb.WriteString("\n")
 
 	// Rows
@@ -225,7 +205,7 @@ func (t *TableView) View() string {
 			rowStyle = styles.TableSelected
 		}
 
-		sb.WriteString("│")
+		sb.WriteString(b.Left)
 		for i, cell := range row {
 			if i >= len(colWidths) {
 				break
@@ -233,21 +213,25 @@ func (t *TableView) View() string {
 			cellStyle := lipgloss.NewStyle().
 				Width(colWidths[i]).
 				Inherit(rowStyle)
+			rendered := cellStyle.Render(truncate(cell, colWidths[i]))
+			if isURL(cell) {
+				rendered = Hyperlink(cell, rendered)
+			}
 			sb.WriteString(" ")
-			sb.WriteString(cellStyle.Render(truncate(cell, colWidths[i])))
-			sb.WriteString(" │")
+			sb.WriteString(rendered)
+			sb.WriteString(" " + b.Right)
 		}
 		// Fill missing columns
 		for i := len(row); i < len(colWidths); i++ {
 			sb.WriteString(" ")
 			sb.WriteString(strings.Repeat(" ", colWidths[i]))
-			sb.WriteString(" │")
+			sb.WriteString(" " + b.Right)
 		}
 		sb.WriteString("\n")
 	}
 
 	// Bottom border
-	sb.WriteString(t.renderBorder("└", "┴", "┘", "─", colWidths))
+	sb.WriteString(t.renderBorder(b.BottomLeft, b.MiddleBottom, b.BottomRight, b.Bottom, colWidths))
 	sb.WriteString("\n")
 
 	// Footer
@@ -335,6 +319,8 @@ type CodeView struct {
 	title       string
 	lineNumbers bool
 	width       int
+	changed     map[int]bool
+	plain       bool
 }
 
 // NewCodeView creates a new code view.
@@ -354,6 +340,12 @@ func (c *CodeView) SetLanguage(language string) {
 	c.language = language
 }
 
+// SetHighlight enables/disables syntax highlighting. Unhighlighted code is
+// much cheaper to render.
+func (c *CodeView) SetHighlight(enabled bool) {
+	c.plain = !enabled
+}
+
 // SetTitle sets an optional title.
 func (c *CodeView) SetTitle(title string) {
 	c.title = title
@@ -369,6 +361,14 @@ func (c *CodeView) SetWidth(width int) {
 	c.width = width
 }
 
+// SetChangedLines marks 1-based line numbers to highlight as recently edited.
+func (c *CodeView) SetChangedLines(lines []int) {
+	c.changed = make(map[int]bool, len(lines))
+	for _, n := range lines {
+		c.changed[n] = true
+	}
+}
+
 // View renders the code block with syntax highlighting.
 func (c *CodeView) View() string {
 	styles := theme.Current.Styles
@@ -395,10 +395,16 @@ func (c *CodeView) View() string {
 			Foreground(colors.TextDim).
 			Width(lineNumWidth).
 			Align(lipgloss.Right)
+		changedStyle := lineNumStyle.Foreground(colors.Accent3).Bold(true)
 
 		for i, line := range lines {
-			codeContent.WriteString(lineNumStyle.Render(strconv.Itoa(i + 1)))
-			codeContent.WriteString(" │ ")
+			if c.changed[i+1] {
+				codeContent.WriteString(changedStyle.Render(strconv.Itoa(i + 1)))
+				codeContent.WriteString(changedStyle.UnsetWidth().Render(" ┃ "))
+			} else {
+				codeContent.WriteString(lineNumStyle.Render(strconv.Itoa(i + 1)))
+				codeContent.WriteString(" │ ")
+			}
 			codeContent.WriteString(line)
 			if i < len(lines)-1 {
 				codeContent.WriteString("\n")
@@ -421,13 +427,17 @@ func (c *CodeView) View() string {
 
 // highlightCode applies syntax highlighting using Chroma.
 func (c *CodeView) highlightCode() string {
+	if c.plain {
+		return c.code
+	}
+
 	// Register custom Charm style
 	styles.Register(BuildChromaStyle())
 
-	// Get lexer for the language
+	// Get lexer for the language, guessing it if the agent did not say
 	var lexer chroma.Lexer
-	if c.language != "" {
-		lexer = lexers.Get(c.language)
+	if language := cmp.Or(c.language, DetectLanguage(c.code)); language != "" {
+		lexer = lexers.Get(language)
 	}
 	if lexer == nil {
 		lexer = lexers.Fallback
@@ -467,6 +477,7 @@ type ProgressView struct {
 	message string
 	percent float64
 	steps   []ProgressStep
+	eta     string
 	width   int
 }
 
@@ -499,6 +510,11 @@ func (p *ProgressView) SetSteps(steps []ProgressStep) {
 	p.steps = steps
 }
 
+// SetETA sets the formatted time remaining. Empty hides it.
+func (p *ProgressView) SetETA(eta string) {
+	p.eta = eta
+}
+
 // SetWidth sets the rendering width.
 func (p *ProgressView) SetWidth(width int) {
 	p.width = width
@@ -534,12 +550,18 @@ func (p *ProgressView) View() string {
 		emptyStyle := lipgloss.NewStyle().Foreground(colors.TextDim)
 		percentStyle := lipgloss.NewStyle().Foreground(colors.TextMuted)
 
-		bar := barStyle.Render(strings.Repeat("█", filled)) +
-			emptyStyle.Render(strings.Repeat("░", barWidth-filled))
+		bar := barStyle.Render(strings.Repeat(theme.Glyphs.BarFull, filled)) +
+			emptyStyle.Render(strings.Repeat(theme.Glyphs.BarEmpty, barWidth-filled))
 
 		sb.WriteString(bar)
 		sb.WriteString(" ")
 		sb.WriteString(percentStyle.Render(strconv.Itoa(int(p.percent)) + "%"))
+		if p.eta != "" {
+			sb.WriteString(percentStyle.Render(theme.Glyphs.Separator + p.eta + " left"))
+		}
+		sb.WriteString("\n")
+	} else if p.eta != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(colors.TextMuted).Render(p.eta + " left"))
 		sb.WriteString("\n")
 	}
 
@@ -552,16 +574,16 @@ func (p *ProgressView) View() string {
 
 			switch step.Status {
 			case "complete":
-				icon = "✓"
+				icon = theme.Glyphs.Success
 				style = lipgloss.NewStyle().Foreground(colors.Success)
 			case "running":
-				icon = "●"
+				icon = theme.Glyphs.Filled
 				style = lipgloss.NewStyle().Foreground(colors.Primary).Bold(true)
 			case "error":
-				icon = "✗"
+				icon = theme.Glyphs.Error
 				style = lipgloss.NewStyle().Foreground(colors.Error)
 			default: // pending
-				icon = "○"
+				icon = theme.Glyphs.Empty
 				style = lipgloss.NewStyle().Foreground(colors.TextDim)
 			}
 
@@ -624,16 +646,16 @@ func (a *AlertView) View() string {
 	switch a.severity {
 	case "success":
 		style = styles.AlertSuccess
-		icon = "✓"
+		icon = theme.Glyphs.Success
 	case "warning":
 		style = styles.AlertWarning
-		icon = "⚠"
+		icon = theme.Glyphs.Warning
 	case "error":
 		style = styles.AlertError
-		icon = "✗"
+		icon = theme.Glyphs.Error
 	default: // info
 		style = styles.AlertInfo
-		icon = "ℹ"
+		icon = theme.Glyphs.Info
 	}
 
 	if a.width > 0 {
@@ -0,0 +1,133 @@
+// Package render draws agentui's components as strings, so other Go
+// programs can show tables, markdown, code and progress the way the TUI
+// does without running it. Each function takes a protocol payload, the
+// width to draw at and the name of a theme, such as "charm-dark"; an empty
+// name draws in the theme in use.
+//
+//	out, err := render.RenderTable(render.TablePayload{
+//		Columns: []any{"name", "size"},
+//		Rows:    [][]string{{"agentui", "12 MB"}},
+//	}, 80, "charm-dark")
+//
+// Colors follow lipgloss's color profile for standard output, so they are
+// left out when it is not a terminal; call lipgloss.SetColorProfile to
+// choose.
+package render
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flight505/agentui/internal/clock"
+	"github.com/flight505/agentui/internal/protocol"
+	"github.com/flight505/agentui/internal/theme"
+	"github.com/flight505/agentui/internal/ui/views"
+)
+
+// Payloads, as agents send them in protocol messages.
+type (
+	TablePayload    = protocol.TablePayload
+	MarkdownPayload = protocol.MarkdownPayload
+	CodePayload     = protocol.CodePayload
+	ProgressPayload = protocol.ProgressPayload
+	ProgressStep    = protocol.ProgressStep
+)
+
+// mu serializes drawing, since the components draw in the global theme.
+var mu sync.Mutex
+
+// Themes returns the names of the built-in themes.
+func Themes() []string {
+	names := make([]string, 0, len(theme.Available))
+	for name := range theme.Available {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderTable draws a table with its title and footer, fitting its columns
+// to width.
+func RenderTable(p TablePayload, width int, themeName string) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	return draw(themeName, func() string {
+		columns := make([]string, len(p.Columns))
+		for i, c := range p.Columns {
+			columns[i] = fmt.Sprint(c)
+		}
+		view := views.NewTableView()
+		view.SetTitle(p.Title)
+		view.SetColumns(columns)
+		view.SetRows(p.Rows)
+		view.SetFooter(p.Footer)
+		view.SetWidth(width)
+		return view.View()
+	})
+}
+
+// RenderMarkdown draws markdown, with its title above it, wrapped to width.
+func RenderMarkdown(p MarkdownPayload, width int, themeName string) (string, error) {
+	return draw(themeName, func() string {
+		view := views.NewMarkdownView()
+		view.SetContent(p.Content)
+		view.SetTitle(p.Title)
+		view.SetWidth(width)
+		return view.View()
+	})
+}
+
+// RenderCode draws a syntax-highlighted code block with line numbers, as
+// the TUI does.
+func RenderCode(p CodePayload, width int, themeName string) (string, error) {
+	return draw(themeName, func() string {
+		view := views.NewCodeView()
+		view.SetCode(p.Code)
+		view.SetLanguage(p.Language)
+		view.SetTitle(p.Title)
+		view.SetWidth(width)
+		return view.View()
+	})
+}
+
+// RenderProgress draws a progress bar with its message, time remaining and
+// steps. Without a percentage it is drawn as indeterminate.
+func RenderProgress(p ProgressPayload, width int, themeName string) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	return draw(themeName, func() string {
+		view := views.NewProgressView()
+		view.SetMessage(p.Message)
+		if p.Percent != nil {
+			view.SetPercent(*p.Percent)
+		}
+		if p.ETA != nil {
+			view.SetETA(clock.Clock{}.Duration(time.Duration(*p.ETA * float64(time.Second))))
+		}
+		steps := make([]views.ProgressStep, len(p.Steps))
+		for i, s := range p.Steps {
+			steps[i] = views.ProgressStep{Label: s.Label, Status: s.Status, Detail: s.Detail}
+		}
+		view.SetSteps(steps)
+		view.SetWidth(width)
+		return view.View()
+	})
+}
+
+// draw runs fn in the named theme, putting back the theme in use after.
+func draw(themeName string, fn func() string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if themeName != "" {
+		current, glyphs := theme.Current, theme.Glyphs
+		defer func() { theme.Current, theme.Glyphs = current, glyphs }()
+		if !theme.SetTheme(themeName) {
+			return "", fmt.Errorf("unknown theme: %s", themeName)
+		}
+	}
+	return fn(), nil
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/flight505/agentui/internal/theme"
+)
+
+func TestRenderTable(t *testing.T) {
+	out, err := RenderTable(TablePayload{
+		Title:   "Files",
+		Columns: []any{"name", "size"},
+		Rows:    [][]string{{"main.go", "12 KB"}},
+	}, 40, "charm-dark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Files", "name", "main.go", "12 KB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table lacks %q:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if w := ansi.StringWidth(line); w > 40 {
+			t.Errorf("line %q is %d wide, want at most 40", line, w)
+		}
+	}
+
+	if _, err := RenderTable(TablePayload{Columns: []any{"a"}, Rows: [][]string{{"1", "2"}}}, 40, ""); err == nil {
+		t.Error("accepted a row with too many cells")
+	}
+}
+
+func TestRenderMarkdownAndCode(t *testing.T) {
+	out, err := RenderMarkdown(MarkdownPayload{Content: "Some **bold** text", Title: "Notes"}, 60, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain := ansi.Strip(out); !strings.Contains(plain, "Notes") || !strings.Contains(plain, "Some bold text") {
+		t.Errorf("markdown = %q", plain)
+	}
+
+	out, err = RenderCode(CodePayload{Code: "x := 1", Language: "go"}, 60, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain := ansi.Strip(out); !strings.Contains(plain, "x := 1") {
+		t.Errorf("code = %q", plain)
+	}
+}
+
+func TestRenderProgress(t *testing.T) {
+	percent, eta := 50.0, 90.0
+	out, err := RenderProgress(ProgressPayload{
+		Message: "Indexing",
+		Percent: &percent,
+		ETA:     &eta,
+		Steps:   []ProgressStep{{Label: "Scan", Status: "complete"}},
+	}, 50, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Indexing", "50%", "Scan"} {
+		if !strings.Contains(ansi.Strip(out), want) {
+			t.Errorf("progress lacks %q:\n%s", want, out)
+		}
+	}
+
+	percent = 150
+	if _, err := RenderProgress(ProgressPayload{Percent: &percent}, 50, ""); err == nil {
+		t.Error("accepted a percentage over 100")
+	}
+}
+
+func TestRenderKeepsTheme(t *testing.T) {
+	before := theme.Current.ID
+	other := "charm-light"
+	if before == other {
+		other = "charm-dark"
+	}
+	if _, err := RenderCode(CodePayload{Code: "x"}, 40, other); err != nil {
+		t.Fatal(err)
+	}
+	if theme.Current.ID != before {
+		t.Errorf("theme = %s after rendering, want %s", theme.Current.ID, before)
+	}
+	if _, err := RenderCode(CodePayload{Code: "x"}, 40, "no-such-theme"); err == nil {
+		t.Error("accepted an unknown theme")
+	}
+	if len(Themes()) == 0 {
+		t.Error("no themes")
+	}
+}
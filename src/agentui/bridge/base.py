@@ -105,6 +105,8 @@ class BaseBridge(ABC):
         message: str,
         percent: float | None = None,
         steps: list[dict] | None = None,
+        progress_id: str | None = None,
+        done: bool = False,
     ) -> None:
         """
         Send progress indicator update.
@@ -113,6 +115,8 @@ class BaseBridge(ABC):
             message: Progress message
             percent: Optional percentage (0-100)
             steps: Optional list of step dictionaries
+            progress_id: Optional ID to run several progress bars at once
+            done: Remove the progress bar with this ID
         """
         pass
 
@@ -122,6 +126,7 @@ class BaseBridge(ABC):
         fields: list[dict],
         title: str | None = None,
         description: str | None = None,
+        timeout_seconds: float | None = None,
     ) -> dict | None:
         """
         Show a form and block until user submits.
@@ -130,6 +135,7 @@ class BaseBridge(ABC):
             fields: List of field dictionaries
             title: Optional form title
             description: Optional form description
+            timeout_seconds: Submit the defaults if left untouched this long
 
         Returns:
             Dictionary mapping field names to values, or None if cancelled
@@ -143,7 +149,7 @@ class BaseBridge(ABC):
         rows: list[list[str]],
         title: str | None = None,
         footer: str | None = None,
-    ) -> None:
+    ) -> str | None:
         """
         Send a data table.
 
@@ -152,6 +158,9 @@ class BaseBridge(ABC):
             rows: List of row lists
             title: Optional table title
             footer: Optional footer text
+
+        Returns:
+            Table ID for streaming more rows, or None if unsupported
         """
         pass
 
@@ -161,7 +170,7 @@ class BaseBridge(ABC):
         code: str,
         language: str = "text",
         title: str | None = None,
-    ) -> None:
+    ) -> str | None:
         """
         Send a syntax-highlighted code block.
 
@@ -169,6 +178,9 @@ class BaseBridge(ABC):
             code: Source code
             language: Language for syntax highlighting
             title: Optional title/filename
+
+        Returns:
+            Block ID for later code patches, or None if patches are unsupported
         """
         pass
 
@@ -178,6 +190,8 @@ class BaseBridge(ABC):
         message: str,
         title: str | None = None,
         destructive: bool = False,
+        timeout_seconds: float | None = None,
+        default: bool = False,
     ) -> bool:
         """
         Show confirmation dialog and block until user responds.
@@ -186,6 +200,8 @@ class BaseBridge(ABC):
             message: Confirmation message
             title: Optional dialog title
             destructive: If True, styles as dangerous action
+            timeout_seconds: Answer default if left untouched this long
+            default: The answer given when the timeout lapses
 
         Returns:
             True if confirmed, False if cancelled
@@ -198,6 +214,7 @@ class BaseBridge(ABC):
         label: str,
         options: list[str],
         default: str | None = None,
+        timeout_seconds: float | None = None,
     ) -> str | None:
         """
         Show selection menu and block until user chooses.
@@ -206,6 +223,8 @@ class BaseBridge(ABC):
             label: Selection prompt
             options: List of choices
             default: Default selection
+            timeout_seconds: Choose the default, or else the first option,
+                if left untouched this long
 
         Returns:
             Selected option string, or None if cancelled
@@ -2,11 +2,11 @@
 
 import logging
 from collections.abc import AsyncIterator
-from typing import Literal
+from typing import Any, Literal
 
 from agentui.bridge.base import BaseBridge
 from agentui.bridge.tui_bridge import TUIConfig
-from agentui.protocol import Message
+from agentui.protocol import Message, mime_bundle, split_diff_hunks
 
 logger = logging.getLogger(__name__)
 
@@ -73,8 +73,12 @@ class CLIBridge(BaseBridge):
         message: str,
         percent: float | None = None,
         steps: list[dict] | None = None,
+        progress_id: str | None = None,
+        done: bool = False,
     ) -> None:
         """Show progress."""
+        if done:
+            return
         if self._console:
             if percent is not None:
                 self._console.print(f"[dim]{message}[/dim] [{percent:.0f}%]")
@@ -97,8 +101,9 @@ class CLIBridge(BaseBridge):
         fields: list[dict],
         title: str | None = None,
         description: str | None = None,
+        timeout_seconds: float | None = None,
     ) -> dict | None:
-        """Collect form input via CLI."""
+        """Collect form input via CLI. Prompts here do not time out."""
         if not self._console:
             return {}
 
@@ -144,12 +149,14 @@ class CLIBridge(BaseBridge):
         message: str,
         title: str | None = None,
         destructive: bool = False,
+        timeout_seconds: float | None = None,
+        default: bool = False,
     ) -> bool:
-        """Get confirmation via CLI."""
+        """Get confirmation via CLI. Prompts here do not time out."""
         if self._console:
             from rich.prompt import Confirm
             style = "[yellow]" if destructive else ""
-            return Confirm.ask(f"{style}{message}")
+            return Confirm.ask(f"{style}{message}", default=default)
         else:
             response = input(f"{message} [y/N]: ").strip().lower()
             return response in ("y", "yes")
@@ -159,8 +166,9 @@ class CLIBridge(BaseBridge):
         label: str,
         options: list[str],
         default: str | None = None,
+        timeout_seconds: float | None = None,
     ) -> str | None:
-        """Get selection via CLI."""
+        """Get selection via CLI. Prompts here do not time out."""
         if self._console:
             self._console.print(f"\n[bold]{label}[/bold]")
             for i, opt in enumerate(options, 1):
@@ -185,6 +193,78 @@ class CLIBridge(BaseBridge):
             except ValueError:
                 return default
 
+    async def request_question(
+        self,
+        question: str,
+        options: list[str] | list[dict[str, str]],
+        other: str | None = None,
+        placeholder: str | None = None,
+        default: str | None = None,
+    ) -> tuple[str, bool] | None:
+        """Ask a question via CLI, taking an option number or the user's own answer."""
+        values = [opt if isinstance(opt, str) else opt.get("value") or opt["label"] for opt in options]
+        labels = [opt if isinstance(opt, str) else opt["label"] for opt in options]
+        print(f"\n{question}")
+        for i, label in enumerate(labels, 1):
+            print(f"  {i}. {label}")
+        try:
+            answer = input(f"Enter number or {(other or 'your own answer').lower()}: ").strip()
+        except (EOFError, KeyboardInterrupt):
+            return None
+        if not answer:
+            return (default, False) if default else None
+        if answer.isdigit() and 0 < int(answer) <= len(values):
+            return values[int(answer) - 1], False
+        return answer, True
+
+    async def request_secret(
+        self,
+        label: str,
+        description: str | None = None,
+        placeholder: str | None = None,
+    ) -> str | None:
+        """Get a secret via CLI without echoing it."""
+        import getpass
+
+        if description:
+            if self._console:
+                self._console.print(f"[dim]{description}[/dim]")
+            else:
+                print(description)
+        try:
+            return getpass.getpass(f"{label}: ")
+        except (EOFError, KeyboardInterrupt):
+            return None
+
+    async def request_diff(
+        self,
+        diff: str | None = None,
+        hunks: list[dict] | None = None,
+        title: str | None = None,
+        path: str | None = None,
+    ) -> list[int] | None:
+        """Review a diff hunk by hunk via CLI."""
+        if hunks is None:
+            hunks = split_diff_hunks(diff or "")
+        if title or path:
+            print(" ".join(part for part in (title, path) if part))
+
+        accepted = []
+        try:
+            for i, hunk in enumerate(hunks):
+                text = "\n".join([hunk.get("header", ""), *hunk.get("lines", [])])
+                if self._console:
+                    from rich.syntax import Syntax
+                    self._console.print(Syntax(text, "diff", theme="monokai"))
+                else:
+                    print(text)
+                answer = input(f"Apply hunk {i + 1}/{len(hunks)}? [y/N]: ")
+                if answer.strip().lower() in ("y", "yes"):
+                    accepted.append(i)
+        except (EOFError, KeyboardInterrupt):
+            return None
+        return accepted
+
     async def send_table(
         self,
         columns: list[str],
@@ -236,6 +316,102 @@ class CLIBridge(BaseBridge):
                 self._console.print(f"[{style} bold]{title}[/{style} bold]")
             self._console.print(f"[{style}]{message}[/{style}]")
 
+    async def send_artifact(
+        self,
+        title: str | None = None,
+        markdown: str | None = None,
+        code: str | None = None,
+        language: str = "text",
+        table: dict[str, Any] | None = None,
+        artifact_id: str | None = None,
+    ) -> str:
+        """Show an artifact inline; the CLI has no artifacts panel."""
+        if table is not None:
+            await self.send_table(table["columns"], table["rows"], title or table.get("title"), table.get("footer"))
+        elif code is not None:
+            await self.send_code(code, language, title)
+        else:
+            await self.send_markdown(markdown or "", title)
+        return artifact_id or ""
+
+    async def send_error(
+        self,
+        message: str,
+        code: str | None = None,
+        details: str | None = None,
+        retryable: bool = False,
+    ) -> None:
+        """Show an error; the CLI offers no retry."""
+        await self.send_alert(
+            "\n".join(filter(None, [message, details])),
+            "error",
+            f"Error: {code}" if code else "Error",
+        )
+
+    async def send_metadata(
+        self,
+        input_tokens: int = 0,
+        output_tokens: int = 0,
+        cost: float = 0.0,
+    ) -> None:
+        """Usage is not tracked in the CLI."""
+        pass
+
+    async def send_qrcode(
+        self,
+        data: str,
+        title: str | None = None,
+        caption: str | None = None,
+        level: Literal["L", "M", "Q", "H"] | None = None,
+    ) -> None:
+        """Show QR code data as plain text."""
+        if self._console:
+            if title:
+                self._console.print(f"[bold]{title}[/bold]")
+            self._console.print(data)
+            if caption:
+                self._console.print(f"[dim]{caption}[/dim]")
+
+    async def send_map(
+        self,
+        points: list[dict[str, Any]],
+        title: str | None = None,
+        region: str | None = None,
+        zoom: int = 0,
+        center: tuple[float, float] | None = None,
+    ) -> None:
+        """Show map points as a table."""
+        if self._console:
+            from rich.table import Table
+            table = Table(title=title)
+            for column in ("Label", "Lat", "Lon"):
+                table.add_column(column)
+            for point in points:
+                table.add_row(str(point.get("label", "")), str(point["lat"]), str(point["lon"]))
+            self._console.print(table)
+
+    async def send_math(self, tex: str, title: str | None = None) -> None:
+        """Show the LaTeX source as-is."""
+        if self._console:
+            if title:
+                self._console.print(f"[bold]{title}[/bold]")
+            self._console.print(tex, markup=False)
+
+    async def send_rich(self, obj: Any) -> None:
+        """Print the markdown of an object's MIME bundle, or its text/plain."""
+        data = mime_bundle(obj)
+        for mime in ("text/markdown", "text/plain"):
+            if mime in data:
+                value = data[mime]
+                text = "".join(value) if isinstance(value, list) else str(value)
+                if mime == "text/markdown":
+                    await self.send_markdown(text)
+                elif self._console:
+                    self._console.print(text, markup=False)
+                else:
+                    print(text)
+                return
+
     async def send_spinner(self, message: str) -> None:
         if self._console:
             self._console.print(f"[dim]⟳ {message}[/dim]")
@@ -1,40 +1,92 @@
 """TUI Bridge for Go subprocess communication."""
 
 import asyncio
+import base64
 import json
 import logging
+import os
 import shutil
+import socket
+import struct
 import subprocess
-from collections.abc import AsyncGenerator, AsyncIterator
-from contextlib import asynccontextmanager
+import tempfile
+import uuid
+from collections.abc import AsyncGenerator, AsyncIterator, Iterator
+from contextlib import asynccontextmanager, contextmanager
+from contextvars import ContextVar
 from pathlib import Path
 from typing import Any, Literal
 
 from agentui.bridge.base import BaseBridge
 from agentui.config import TUIConfig
 from agentui.exceptions import ConnectionError, ProtocolError, ValidationError
+from agentui.layout import UILayout
 from agentui.protocol import (
+    BLOB_CHUNK_SIZE,
+    BLOB_FILE,
+    COMPRESSION_GZIP,
     Message,
     MessageType,
     alert_payload,
+    artifact_payload,
+    batch_to_json,
+    batch_to_msgpack,
+    blob_file_payload,
+    blob_payloads,
+    autocomplete_payload,
     clear_payload,
+    code_chunk_payload,
+    code_patch_payload,
+    collapse_payload,
     code_payload,
+    commands_payload,
     confirm_payload,
     create_message,
     create_request,
+    diff_payload,
     done_payload,
+    error_payload,
+    metadata_payload,
+    fallback_message,
     form_payload,
+    hello_payload,
+    input_suggestion_payload,
+    map_payload,
     markdown_payload,
+    math_payload,
+    mime_bundle,
+    pong_payload,
     progress_payload,
+    qrcode_payload,
+    question_payload,
+    rich_payload,
+    scroll_to_payload,
+    secret_payload,
     select_payload,
     spinner_payload,
     status_payload,
+    table_chunk_payload,
+    table_update_payload,
     table_payload,
     text_payload,
+    tool_payload,
+    update_payload,
 )
 
 logger = logging.getLogger(__name__)
 
+# The logical agent messages sent from the current task come from; see
+# TUIBridge.agent
+_current_agent: ContextVar[str | None] = ContextVar("agentui_agent", default=None)
+
+# The dashboard region messages sent from the current task draw into; see
+# TUIBridge.region
+_current_region: ContextVar[str | None] = ContextVar("agentui_region", default=None)
+
+# Whether messages sent from the current task are pinned; see
+# TUIBridge.pinned
+_current_pinned: ContextVar[bool] = ContextVar("agentui_pinned", default=False)
+
 
 class TUIBridge(BaseBridge):
     """
@@ -50,11 +102,21 @@ class TUIBridge(BaseBridge):
     def __init__(self, config: TUIConfig | None = None):
         self.config = config or TUIConfig()
         self._process: subprocess.Popen | None = None
+        # With protocol_fd, the socket to the TUI and its read and write ends
+        self._channel: socket.socket | None = None
+        self._channel_files: tuple[Any, Any] | None = None
         self._reader_task: asyncio.Task | None = None
         self._writer_task: asyncio.Task | None = None
         self._pending_requests: dict[str, asyncio.Future] = {}
+        self._attachment_chunks: dict[str, dict[int, bytes]] = {}
         self._event_queue: asyncio.Queue[Message] = asyncio.Queue()
-        self._outgoing_queue: asyncio.Queue[Message] = asyncio.Queue()
+        self._outgoing_queue: asyncio.Queue[Message | list[Message]] = asyncio.Queue()
+        self._tui_hello: dict[str, Any] | None = None
+        self._journal_started = False
+        self._tui_compression: list[str] = []  # from the TUI's hello
+        self._user_typing = False
+        self._credits: int | None = None  # under the TUI's flow control
+        self._credit_granted = asyncio.Event()
         self._running = False
         self._shutting_down = False
         self._lock = asyncio.Lock()
@@ -110,28 +172,142 @@ class TUIBridge(BaseBridge):
             "--tagline", self.config.tagline,
         ]
 
+        if self.config.keepalive_interval:
+            cmd += ["--keepalive", f"{self.config.keepalive_interval}s"]
+        if self.config.flow_window:
+            cmd += ["--flow-window", str(self.config.flow_window)]
+        if self.config.status_rate is not None:
+            cmd += ["--status-rate", str(self.config.status_rate)]
+        if self.config.budget:
+            cmd += ["--budget", str(self.config.budget)]
+        if self.config.token_budget:
+            cmd += ["--token-budget", str(self.config.token_budget)]
+        if self.config.max_code_lines is not None:
+            cmd += ["--max-code-lines", str(self.config.max_code_lines)]
+        if self.config.max_table_rows is not None:
+            cmd += ["--max-table-rows", str(self.config.max_table_rows)]
+        if self.config.max_markdown_bytes is not None:
+            cmd += ["--max-markdown-bytes", str(self.config.max_markdown_bytes)]
+        if self.config.fold_lines is not None:
+            cmd += ["--fold-lines", str(self.config.fold_lines)]
+        if self.config.reveal_escapes:
+            cmd.append("--reveal-escapes")
+        if self.config.typing_idle:
+            cmd += ["--typing-idle", f"{self.config.typing_idle}s"]
+        if self.config.heartbeat_interval:
+            cmd += ["--heartbeat", f"{self.config.heartbeat_interval}s"]
+        if self.config.startup_timeout:
+            cmd += ["--startup-timeout", f"{self.config.startup_timeout}s"]
+        if self.config.compress_threshold:
+            cmd += ["--compress-threshold", str(self.config.compress_threshold)]
+        if self.config.strict_protocol:
+            cmd.append("--strict-protocol")
+        if self.config.agent_control:
+            cmd.append("--agent-control")
+        if not self.config.hints:
+            cmd.append("--hints=false")
+        if self.config.timestamps != "minute":
+            cmd += ["--timestamps", self.config.timestamps]
+        if self.config.time_format:
+            cmd += ["--time-format", self.config.time_format]
+        if self.config.retry_replaces:
+            cmd.append("--retry-replaces")
+        if self.config.turn_timing:
+            cmd.append("--turn-timing")
+        if self.config.layout != "full":
+            cmd += ["--layout", self.config.layout]
+        if self.config.max_width:
+            cmd += ["--max-width", str(self.config.max_width)]
+        if self.config.sidebar:
+            cmd.append("--sidebar")
+        if self.config.links != "auto":
+            cmd += ["--links", self.config.links]
+        if self.config.record_path:
+            cmd += ["--record", self.config.record_path]
+        if self.config.protocol_log:
+            cmd += ["--protocol-log", self.config.protocol_log]
+        if self.config.journal_path:
+            # A restarted TUI picks up where the one that died left off
+            flag = "--resume-journal" if self._journal_started else "--journal"
+            cmd += [flag, self.config.journal_path]
+            self._journal_started = True
+        if self.config.history_dir is not None:
+            cmd += ["--history-dir", self.config.history_dir]
+        if self.config.input_history is not None:
+            cmd += ["--input-history", self.config.input_history]
+        if self.config.resume:
+            cmd += ["--resume", self.config.resume]
+        for spec in self.config.schedules or []:
+            cmd += ["--schedule", spec]
+        if self.config.encoding != "json":
+            cmd += ["--encoding", self.config.encoding]
+        if self.config.framing != "lines":
+            cmd += ["--framing", self.config.framing]
+
+        # With protocol_fd the protocol runs over a socket pair, leaving the
+        # TUI's stdin and stdout to the terminal
+        self._close_channel()
+        child: socket.socket | None = None
+        if self.config.protocol_fd:
+            self._channel, child = socket.socketpair()
+            cmd += ["--protocol-fd", str(child.fileno())]
+
         if self.config.debug:
             logger.info(f"Starting TUI: {' '.join(cmd)}")
 
+        # Binary formats are read and written unbuffered in bytes mode
+        text = self.config.encoding == "json" and self.config.framing == "lines"
         try:
-            self._process = subprocess.Popen(
-                cmd,
-                stdin=subprocess.PIPE,
-                stdout=subprocess.PIPE,
-                stderr=subprocess.PIPE,
-                text=True,
-                bufsize=1,
-            )
+            if child is not None:
+                self._process = subprocess.Popen(
+                    cmd,
+                    stderr=subprocess.PIPE,
+                    pass_fds=(child.fileno(),),
+                    text=text,
+                )
+            else:
+                self._process = subprocess.Popen(
+                    cmd,
+                    stdin=subprocess.PIPE,
+                    stdout=subprocess.PIPE,
+                    stderr=subprocess.PIPE,
+                    text=text,
+                    bufsize=1 if text else 0,
+                )
         except OSError as e:
+            self._close_channel()
             raise ConnectionError(f"Failed to start TUI process: {e}")
+        finally:
+            if child is not None:
+                child.close()
+        if self._channel is not None:
+            mode = "" if text else "b"
+            self._channel_files = (
+                self._channel.makefile("r" + mode, encoding="utf-8" if text else None),
+                self._channel.makefile("w" + mode, encoding="utf-8" if text else None),
+            )
 
         self._running = True
         self._shutting_down = False
+        self._tui_hello = None
+        self._tui_compression = []
+        self._user_typing = False
+        self._credits = None
 
         # Start reader and writer tasks
         self._reader_task = asyncio.create_task(self._read_loop())
         self._writer_task = asyncio.create_task(self._write_loop())
 
+        # Payloads are compressed once both sides know what the other
+        # accepts; the hello also names the agent for the TUI's bug reports
+        from agentui import __version__
+
+        hello = hello_payload(
+            telemetry=None if self.config.telemetry else False,
+            agent={"name": self.config.app_name, "sdk": f"agentui-python {__version__}"},
+        )
+        await self._send_raw(create_message(MessageType.HELLO, hello))
+
         # Start stderr reader for debugging
         if self.config.debug:
             asyncio.create_task(self._stderr_loop())
@@ -173,10 +349,43 @@ class TUIBridge(BaseBridge):
                 logger.warning(f"Error stopping TUI process: {e}")
             finally:
                 self._process = None
+        self._close_channel()
+
+    def _close_channel(self) -> None:
+        """Close the socket to the TUI, if the protocol runs over one."""
+        for f in self._channel_files or ():
+            try:
+                f.close()
+            except OSError:
+                pass
+        self._channel_files = None
+        if self._channel is not None:
+            self._channel.close()
+            self._channel = None
+
+    @property
+    def _from_tui(self) -> Any:
+        """The stream messages from the TUI are read from."""
+        if self._channel_files is not None:
+            return self._channel_files[0]
+        return self._process.stdout if self._process else None
+
+    @property
+    def _to_tui(self) -> Any:
+        """The stream messages to the TUI are written to."""
+        if self._channel_files is not None:
+            return self._channel_files[1]
+        return self._process.stdin if self._process else None
 
     async def _read_loop(self) -> None:
         """Read messages from TUI stdout."""
-        if not self._process or not self._process.stdout:
+        if not self._from_tui:
+            return
+        if self.config.framing == "length":
+            await self._read_framed_loop()
+            return
+        if self.config.encoding == "msgpack":
+            await self._read_msgpack_loop()
             return
 
         loop = asyncio.get_event_loop()
@@ -184,7 +393,7 @@ class TUIBridge(BaseBridge):
         while self._running:
             try:
                 line = await loop.run_in_executor(
-                    None, self._process.stdout.readline
+                    None, self._from_tui.readline
                 )
 
                 if not line:
@@ -200,6 +409,91 @@ class TUIBridge(BaseBridge):
                     logger.error(f"Error reading from TUI: {e}")
                 break
 
+    async def _read_msgpack_loop(self) -> None:
+        """Read MessagePack messages from TUI stdout."""
+        if not self._from_tui:
+            return
+
+        from agentui.protocol import _msgpack
+
+        loop = asyncio.get_event_loop()
+        stdout = self._from_tui
+        unpacker = _msgpack().Unpacker(raw=False)
+
+        while self._running:
+            try:
+                chunk = await loop.run_in_executor(None, stdout.read1, 65536)
+
+                if not chunk:
+                    await self._handle_closed_stdout()
+                    break
+
+                unpacker.feed(chunk)
+                for data in unpacker:
+                    try:
+                        msg = Message.from_dict(data)
+                    except (ValueError, OSError) as e:
+                        logger.error(f"Invalid message from TUI: {e}")
+                        continue
+                    await self._receive(msg, str(data))
+
+            except asyncio.CancelledError:
+                break
+            except Exception as e:
+                if self._running and not self._shutting_down:
+                    logger.error(f"Error reading from TUI: {e}")
+                break
+
+    async def _read_framed_loop(self) -> None:
+        """Read length-prefixed messages from TUI stdout."""
+        if not self._from_tui:
+            return
+
+        loop = asyncio.get_event_loop()
+        stdout = self._from_tui
+
+        def read_frame() -> bytes | None:
+            # None means stdout closed, possibly partway through a frame
+            def read_exact(size: int) -> bytes | None:
+                data = b""
+                while len(data) < size:
+                    chunk = stdout.read(size - len(data))
+                    if not chunk:
+                        return None
+                    data += chunk
+                return data
+
+            header = read_exact(4)
+            if header is None:
+                return None
+            return read_exact(struct.unpack(">I", header)[0])
+
+        while self._running:
+            try:
+                body = await loop.run_in_executor(None, read_frame)
+
+                if body is None:
+                    await self._handle_closed_stdout()
+                    break
+
+                try:
+                    if self.config.encoding == "msgpack":
+                        from agentui.protocol import _msgpack
+                        msg = Message.from_dict(_msgpack().unpackb(body, raw=False))
+                    else:
+                        msg = Message.from_json(body.decode("utf-8"))
+                except (ValueError, OSError) as e:
+                    logger.error(f"Invalid message from TUI: {e}")
+                    continue
+                await self._receive(msg, body[:100].decode("utf-8", errors="replace"))
+
+            except asyncio.CancelledError:
+                break
+            except Exception as e:
+                if self._running and not self._shutting_down:
+                    logger.error(f"Error reading from TUI: {e}")
+                break
+
     async def _handle_closed_stdout(self) -> None:
         """Handle TUI process closing stdout."""
         if not self._shutting_down:
@@ -211,19 +505,74 @@ class TUIBridge(BaseBridge):
         if not line:
             return
 
-        if self.config.debug:
-            logger.debug(f"← TUI: {line[:100]}...")
-
         try:
             msg = Message.from_json(line)
-        except json.JSONDecodeError as e:
-            logger.error(f"Invalid JSON from TUI: {e}")
+        except (json.JSONDecodeError, ValueError, OSError) as e:
+            logger.error(f"Invalid message from TUI: {e}")
             return
 
+        await self._receive(msg, line)
+
+    async def _receive(self, msg: Message, raw: str) -> None:
+        """Log and route a decoded message."""
+        if self.config.debug:
+            if msg.type == MessageType.SECRET_RESPONSE.value:
+                logger.debug("← TUI: secret_response [redacted]")
+            else:
+                logger.debug(f"← TUI: {raw[:100]}...")
+
         await self._route_message(msg)
 
     async def _route_message(self, msg: Message) -> None:
         """Route message to pending request or event queue."""
+        if msg.type == MessageType.ATTACHMENT.value and msg.payload:
+            assembled = self._assemble_attachment(msg.payload)
+            if assembled is None:
+                return
+            msg = Message(type=msg.type, id=msg.id, payload=assembled)
+
+        if msg.type == MessageType.HELLO.value:
+            self._tui_hello = msg.payload or {}
+            self._tui_compression = list(self._tui_hello.get("compression", []))
+            return
+
+        if msg.type == MessageType.PING.value:
+            # Answered from the event loop, so a blocked loop goes quiet
+            seq = (msg.payload or {}).get("seq", 0)
+            await self._send_raw(
+                create_message(MessageType.PONG, pong_payload(seq), msg_id=msg.id)
+            )
+            return
+
+        if msg.type == MessageType.READY_FOR_MORE.value:
+            self._credits = (self._credits or 0) + int((msg.payload or {}).get("credits", 0))
+            self._credit_granted.set()
+            return
+
+        if msg.type == MessageType.TYPING.value:
+            # Still queued, for agents that act on the transitions
+            self._user_typing = (msg.payload or {}).get("state") == "start"
+
+        if msg.type == MessageType.UNSUPPORTED.value:
+            unsupported = (msg.payload or {}).get("type")
+            logger.warning(f"TUI does not support message type {unsupported!r}")
+            if msg.id and msg.id in self._pending_requests:
+                future = self._pending_requests.pop(msg.id)
+                if not future.done():
+                    future.set_exception(
+                        ProtocolError(f"TUI does not support message type {unsupported!r}")
+                    )
+                return
+
+        if msg.type == MessageType.PROTOCOL_ERROR.value:
+            error = _describe_protocol_error(msg.payload or {})
+            logger.warning(f"TUI rejected a message: {error}")
+            if msg.id and msg.id in self._pending_requests:
+                future = self._pending_requests.pop(msg.id)
+                if not future.done():
+                    future.set_exception(ProtocolError(f"TUI rejected a message: {error}"))
+                return
+
         if msg.id and msg.id in self._pending_requests:
             future = self._pending_requests.pop(msg.id)
             if not future.done():
@@ -231,6 +580,27 @@ class TUIBridge(BaseBridge):
         else:
             await self._event_queue.put(msg)
 
+    def _assemble_attachment(self, payload: dict[str, Any]) -> dict[str, Any] | None:
+        """Collect attachment chunks; return the full payload once all have arrived."""
+        chunks = int(payload.get("chunks", 1))
+        if chunks <= 1:
+            return payload
+
+        attachment_id = str(payload.get("id", ""))
+        parts = self._attachment_chunks.setdefault(attachment_id, {})
+        parts[int(payload.get("chunk", 0))] = base64.b64decode(payload.get("data", ""))
+        if len(parts) < chunks:
+            return None
+
+        del self._attachment_chunks[attachment_id]
+        data = b"".join(parts[i] for i in range(chunks))
+        return {
+            **payload,
+            "data": base64.b64encode(data).decode("ascii"),
+            "chunk": 0,
+            "chunks": 1,
+        }
+
     async def _write_loop(self) -> None:
         """Write messages to TUI stdin."""
         while self._running:
@@ -239,6 +609,7 @@ class TUIBridge(BaseBridge):
                     self._outgoing_queue.get(),
                     timeout=0.1
                 )
+                await self._spend_credit()
                 await self._send_raw(msg)
             except TimeoutError:
                 continue
@@ -248,6 +619,14 @@ class TUIBridge(BaseBridge):
                 if self._running:
                     logger.error(f"Error writing to TUI: {e}")
 
+    async def _spend_credit(self) -> None:
+        """Wait for credit to send a message, if the TUI uses flow control."""
+        while self._credits is not None and self._credits <= 0:
+            self._credit_granted.clear()
+            await self._credit_granted.wait()
+        if self._credits is not None:
+            self._credits -= 1
+
     async def _stderr_loop(self) -> None:
         """Read and log stderr from TUI."""
         if not self._process or not self._process.stderr:
@@ -260,6 +639,8 @@ class TUIBridge(BaseBridge):
                 line = await loop.run_in_executor(
                     None, self._process.stderr.readline
                 )
+                if isinstance(line, bytes):
+                    line = line.decode("utf-8", errors="replace")
                 if line:
                     logger.debug(f"TUI stderr: {line.strip()}")
                 elif not self._running:
@@ -289,35 +670,178 @@ class TUIBridge(BaseBridge):
         logger.error("Failed to reconnect to TUI")
         self._running = False
 
-    async def _send_raw(self, message: Message) -> None:
-        """Send a message directly to TUI stdin."""
-        if not self._process or not self._process.stdin:
+    @property
+    def tui_info(self) -> dict[str, Any] | None:
+        """What the TUI said it supports in its hello message.
+
+        Holds "protocol_version", "types", "compression", "rich" (the MIME
+        types rich messages may carry, best first) and "terminal" (with
+        "truecolor", "images" and "hyperlinks"). None until the hello
+        arrives, and for TUIs that predate it.
+        """
+        return self._tui_hello
+
+    def supports(self, msg_type: MessageType | str) -> bool:
+        """Whether the TUI renders a message type, assuming so until it says."""
+        if isinstance(msg_type, MessageType):
+            msg_type = msg_type.value
+        if self._tui_hello is None or msg_type == MessageType.HELLO.value:
+            return True
+        return msg_type in self._tui_hello.get("types", [])
+
+    def _degrade(self, message: Message) -> Message | None:
+        """Degrade types the TUI lacks rather than have it report an error."""
+        if self.supports(message.type):
+            return message
+        fallback = fallback_message(message)
+        if fallback is None:
+            logger.warning(f"TUI does not support message type {message.type!r}; not sent")
+        return fallback
+
+    async def _send_raw(self, message: Message | list[Message]) -> None:
+        """Send a message, or a batch of messages, directly to TUI stdin."""
+        if not self._to_tui:
             raise ConnectionError("TUI not connected")
 
-        line = message.to_json() + "\n"
+        threshold = None
+        if COMPRESSION_GZIP in self._tui_compression:
+            threshold = self.config.compress_threshold
+
+        data: str | bytes
+        if isinstance(message, list):
+            if self._tui_hello is not None and not self._tui_hello.get("batch"):
+                # TUIs that predate batches get the messages one by one
+                for msg in message:
+                    await self._send_raw(msg)
+                return
+            batch = [m for m in map(self._degrade, message) if m is not None]
+            if not batch:
+                return
+            if self.config.encoding == "msgpack":
+                data = batch_to_msgpack(batch, threshold)
+            else:
+                data = batch_to_json(batch, threshold)
+        else:
+            degraded = self._degrade(message)
+            if degraded is None:
+                return
+            if self.config.encoding == "msgpack":
+                data = degraded.to_msgpack(threshold)
+            else:
+                data = degraded.to_json(threshold)
+        if self.config.framing == "length":
+            if isinstance(data, str):
+                data = data.encode("utf-8")
+            data = struct.pack(">I", len(data)) + data
+        elif isinstance(data, str):
+            data += "\n"
 
         if self.config.debug:
-            logger.debug(f"→ TUI: {line[:100]}...")
+            logger.debug(f"→ TUI: {data[:100]}...")
 
         try:
-            self._process.stdin.write(line)
-            self._process.stdin.flush()
+            self._to_tui.write(data)
+            self._to_tui.flush()
         except BrokenPipeError:
             raise ConnectionError("TUI connection broken")
         except Exception as e:
             raise ProtocolError(f"Failed to send message: {e}")
 
+    @contextmanager
+    def agent(self, agent_id: str) -> Iterator[None]:
+        """Send messages as one of several agents sharing the TUI.
+
+        Within the block, messages sent by the current task carry
+        ``agent_id``, and the TUI shows them under that name, apart from
+        the other agents' messages. Tasks started within it inherit it, so
+        an orchestrator can run each worker under its own name::
+
+            async def worker(name: str) -> None:
+                with bridge.agent(name):
+                    await bridge.send_markdown("Working on it")
+
+        Answers to requests made within the block carry ``agent_id`` too.
+        """
+        token = _current_agent.set(agent_id)
+        try:
+            yield
+        finally:
+            _current_agent.reset(token)
+
+    @contextmanager
+    def region(self, region_id: str) -> Iterator[None]:
+        """Send messages into a region of a dashboard.
+
+        Within the block, text, markdown, tables, code, progress and alerts
+        sent by the current task draw into the region ``region_id`` of the
+        latest layout that has it (see ``send_layout``) instead of adding
+        to the transcript. Text is appended; other messages replace what
+        the region showed, and ``send_clear`` empties it::
+
+            with bridge.region("cpu"):
+                await bridge.send_progress("CPU", percent=load)
+
+        Messages for a region no dashboard has go to the transcript.
+        """
+        token = _current_region.set(region_id)
+        try:
+            yield
+        finally:
+            _current_region.reset(token)
+
+    @contextmanager
+    def pinned(self) -> Iterator[None]:
+        """Pin messages to the strip at the top of the transcript.
+
+        Within the block, what messages sent by the current task add to
+        the transcript stays in view above it, however far it scrolls, so
+        key results are not lost in long streams::
+
+            with bridge.pinned():
+                await bridge.send_markdown(f"Deployed to {url}")
+
+        Streamed text is pinned by the message that ends the stream. The
+        user can unpin messages, and pin others, with p.
+        """
+        token = _current_pinned.set(True)
+        try:
+            yield
+        finally:
+            _current_pinned.reset(token)
+
+    def _address(self, message: Message) -> Message:
+        """Stamp a message with the current agent and region, and pin it,
+        unless it says otherwise."""
+        if message.agent_id is None:
+            message.agent_id = _current_agent.get()
+        if message.region is None:
+            message.region = _current_region.get()
+        if message.pinned is None and _current_pinned.get():
+            message.pinned = True
+        return message
+
     async def send(self, message: Message) -> None:
         """Queue a message to be sent to the TUI."""
         if not self._running:
             raise ConnectionError("TUI not running")
-        await self._outgoing_queue.put(message)
+        await self._outgoing_queue.put(self._address(message))
+
+    async def send_batch(self, messages: list[Message]) -> None:
+        """Queue messages for the TUI to apply together.
+
+        The TUI draws the result once, instead of after each message, so a
+        burst such as clear, markdown, table and status appears at once.
+        TUIs that predate batches get the messages one by one.
+        """
+        if not self._running:
+            raise ConnectionError("TUI not running")
+        await self._outgoing_queue.put([self._address(m) for m in messages])
 
     async def send_sync(self, message: Message) -> None:
         """Send a message synchronously (bypass queue)."""
         if not self._running:
             raise ConnectionError("TUI not running")
-        await self._send_raw(message)
+        await self._send_raw(self._address(message))
 
     async def request(self, message: Message, timeout: float = 30.0) -> Any:
         """Send a request and wait for response."""
@@ -326,6 +850,12 @@ class TUIBridge(BaseBridge):
 
         if not self._running:
             raise ConnectionError("TUI not running")
+        if not self.supports(message.type):
+            raise ProtocolError(f"TUI does not support message type {message.type!r}")
+
+        # The TUI closes the prompt once nobody waits for the answer
+        message.timeout = timeout
+        self._address(message)
 
         future = asyncio.get_event_loop().create_future()
         self._pending_requests[message.id] = future
@@ -354,6 +884,16 @@ class TUIBridge(BaseBridge):
             except asyncio.CancelledError:
                 break
 
+    @property
+    def is_user_typing(self) -> bool:
+        """Whether the user is composing a prompt.
+
+        Follows the TUI's typing events, so it is always False unless
+        ``TUIConfig.typing_idle`` is set. Agents can check it to hold back
+        proactive messages until the user has finished typing.
+        """
+        return self._user_typing
+
     @property
     def is_running(self) -> bool:
         """Check if the bridge is running."""
@@ -371,16 +911,43 @@ class TUIBridge(BaseBridge):
         msg = create_message(MessageType.MARKDOWN, markdown_payload(content, title))
         await self.send(msg)
 
+    async def stream_markdown(
+        self,
+        chunks: AsyncIterator[str],
+        title: str | None = None,
+    ) -> str:
+        """Stream a markdown document into one growing message.
+
+        Returns:
+            ID of the markdown message
+        """
+        msg_id = str(uuid.uuid4())
+        async for chunk in chunks:
+            await self.send(create_message(
+                MessageType.MARKDOWN,
+                markdown_payload(chunk, title, append=True),
+                msg_id,
+            ))
+        await self.send(create_message(
+            MessageType.MARKDOWN,
+            markdown_payload("", title, append=True, done=True),
+            msg_id,
+        ))
+        return msg_id
+
     async def send_progress(
         self,
         message: str,
         percent: float | None = None,
         steps: list[dict] | None = None,
+        progress_id: str | None = None,
+        done: bool = False,
+        eta: float | None = None,
     ) -> None:
         """Send progress update."""
         msg = create_message(
             MessageType.PROGRESS,
-            progress_payload(message, percent, steps)
+            progress_payload(message, percent, steps, progress_id, done, eta)
         )
         await self.send(msg)
 
@@ -389,13 +956,19 @@ class TUIBridge(BaseBridge):
         fields: list[dict],
         title: str | None = None,
         description: str | None = None,
+        timeout_seconds: float | None = None,
     ) -> dict | None:
-        """Show a form and wait for response."""
+        """Show a form and wait for response.
+
+        With timeout_seconds the defaults are submitted if the user leaves
+        the form untouched that long.
+        """
         msg = create_request(
             MessageType.FORM,
-            form_payload(fields, title, description)
+            form_payload(fields, title, description, timeout_seconds=timeout_seconds)
         )
-        result = await self.request(msg)
+        # Let the countdown run out before giving up on an answer
+        result = await self.request(msg, timeout=30.0 + (timeout_seconds or 0))
         return result.get("values") if result else None
 
     async def send_table(
@@ -404,56 +977,334 @@ class TUIBridge(BaseBridge):
         rows: list[list[str]],
         title: str | None = None,
         footer: str | None = None,
-    ) -> None:
-        """Send a data table."""
+    ) -> str:
+        """Send a data table and return its ID for streaming more rows."""
         from typing import cast
-        msg = create_message(
+        msg = create_request(
             MessageType.TABLE,
             table_payload(cast(list, columns), rows, title, footer)
         )
         await self.send(msg)
+        return msg.id or ""
+
+    async def send_table_chunk(
+        self,
+        table_id: str,
+        rows: list[list[str]],
+        total: int | None = None,
+        done: bool = False,
+    ) -> None:
+        """Stream more rows into a table sent with send_table.
+
+        Args:
+            table_id: ID returned by send_table
+            rows: Rows to append
+            total: Expected final row count, used for the progress display
+            done: Whether this is the last chunk
+        """
+        msg = create_message(
+            MessageType.TABLE_CHUNK,
+            table_chunk_payload(table_id, rows, total, done)
+        )
+        await self.send(msg)
 
     async def send_code(
         self,
         code: str,
-        language: str = "text",
+        language: str = "",
         title: str | None = None,
-    ) -> None:
-        """Send a code block."""
-        msg = create_message(
+    ) -> str:
+        """Send a code block and return its ID for later patches.
+
+        Without a language the TUI guesses it from the code.
+        """
+        msg = create_request(
             MessageType.CODE,
             code_payload(code, language, title)
         )
         await self.send(msg)
+        return msg.id or ""
+
+    async def update_table(
+        self,
+        table_id: str,
+        rows: list[list[str]],
+        replace: bool = False,
+        start: int | None = None,
+        footer: str | None = None,
+    ) -> None:
+        """Append or replace rows in a table sent with send_table.
+
+        Args:
+            table_id: ID returned by send_table
+            rows: Rows to append, or replacement rows
+            replace: Replace rows instead of appending
+            start: With replace, overwrite rows from this zero-based index
+            footer: New footer text
+        """
+        msg = create_message(
+            MessageType.TABLE_UPDATE,
+            table_update_payload(table_id, rows, replace, start, footer)
+        )
+        await self.send(msg)
+
+    async def send_code_chunk(
+        self,
+        code_id: str,
+        code: str,
+        total: int | None = None,
+        done: bool = False,
+    ) -> None:
+        """Stream more text onto a code block sent with send_code.
+
+        Args:
+            code_id: ID returned by send_code
+            code: Text to append
+            total: Expected final size in bytes, used for the progress display
+            done: Whether this is the last chunk
+        """
+        msg = create_message(
+            MessageType.CODE_CHUNK,
+            code_chunk_payload(code_id, code, total, done)
+        )
+        await self.send(msg)
+
+    async def send_file(
+        self,
+        path: str | Path,
+        language: str = "",
+        chunk_size: int = 64 * 1024,
+    ) -> str:
+        """Send a file's contents as a code block, streamed in chunks.
+
+        Returns:
+            ID of the code block
+        """
+        path = Path(path)
+        total = path.stat().st_size
+        code_id = await self.send_code("", language, title=path.name)
+        with path.open(encoding="utf-8", errors="replace") as f:
+            while chunk := f.read(chunk_size):
+                await self.send_code_chunk(code_id, chunk, total)
+        await self.send_code_chunk(code_id, "", total, done=True)
+        return code_id
+
+    async def send_blob(
+        self,
+        source: bytes | str | Path,
+        name: str | None = None,
+        mime_type: str | None = None,
+        chunk_size: int = BLOB_CHUNK_SIZE,
+    ) -> str:
+        """Send binary content, such as an image or a generated file.
+
+        The TUI keeps it until it exits and notes it in the transcript with
+        a link to its copy. TUIs on this machine are handed a file written
+        to the directory from their hello; others get base64 chunks.
+
+        Args:
+            source: The content, or the path of a file holding it
+            name: File name shown for it (defaults to the file's)
+            mime_type: Its type, guessed by the TUI from the name or content
+                if not given
+            chunk_size: Raw bytes per message when sending chunks
+
+        Returns:
+            ID of the blob
+        """
+        blob_id = f"blob-{uuid.uuid4().hex[:12]}"
+        if not isinstance(source, bytes):
+            path = Path(source)
+            name = name or path.name
+            source = path.read_bytes()
+
+        hello = self._tui_hello or {}
+        blob_dir = hello.get("blob_dir")
+        if BLOB_FILE in hello.get("blobs", []) and blob_dir:
+            fd, handoff = tempfile.mkstemp(dir=blob_dir, suffix=Path(name or "").suffix)
+            with os.fdopen(fd, "wb") as f:
+                f.write(source)
+            await self.send(create_message(
+                MessageType.BLOB, blob_file_payload(blob_id, handoff, name, mime_type)
+            ))
+            return blob_id
+
+        for payload in blob_payloads(blob_id, source, name, mime_type, chunk_size):
+            await self.send(create_message(MessageType.BLOB, payload))
+        return blob_id
+
+    async def send_code_patch(
+        self,
+        code_id: str,
+        lines: list[str],
+        start: int | None = None,
+        end: int | None = None,
+    ) -> None:
+        """Append or replace lines of a code block sent with send_code."""
+        msg = create_message(
+            MessageType.CODE_PATCH,
+            code_patch_payload(code_id, lines, start, end)
+        )
+        await self.send(msg)
 
     async def request_confirm(
         self,
         message: str,
         title: str | None = None,
         destructive: bool = False,
+        timeout_seconds: float | None = None,
+        default: bool = False,
     ) -> bool:
-        """Show confirmation dialog and wait for response."""
+        """Show confirmation dialog and wait for response.
+
+        With timeout_seconds, default is answered if the user leaves the
+        dialog untouched that long.
+        """
         msg = create_request(
             MessageType.CONFIRM,
-            confirm_payload(message, title, destructive=destructive)
+            confirm_payload(
+                message, title, destructive=destructive,
+                timeout_seconds=timeout_seconds, default=default,
+            )
         )
-        result = await self.request(msg)
+        result = await self.request(msg, timeout=30.0 + (timeout_seconds or 0))
         return result.get("confirmed", False) if result else False
 
     async def request_select(
         self,
         label: str,
-        options: list[str],
+        options: list[str] | list[dict[str, str]],
         default: str | None = None,
+        timeout_seconds: float | None = None,
     ) -> str | None:
-        """Show selection and wait for response."""
+        """Show selection and wait for response.
+
+        Options may be strings or dicts with "label", "value" and
+        "description"; the chosen option's value is returned. With
+        timeout_seconds the default, or else the first option, is chosen if
+        the user leaves the menu untouched that long.
+        """
         msg = create_request(
             MessageType.SELECT,
-            select_payload(label, options, default)
+            select_payload(label, options, default, timeout_seconds)
         )
-        result = await self.request(msg)
+        result = await self.request(msg, timeout=30.0 + (timeout_seconds or 0))
         return result.get("value") if result else None
 
+    async def request_question(
+        self,
+        question: str,
+        options: list[str] | list[dict[str, str]],
+        other: str | None = None,
+        placeholder: str | None = None,
+        default: str | None = None,
+    ) -> tuple[str, bool] | None:
+        """Ask a clarifying question with suggested answers or the user's own.
+
+        Returns:
+            The chosen option's value or the typed answer, with True if the
+            user typed it, or None if they cancelled
+        """
+        msg = create_request(
+            MessageType.QUESTION,
+            question_payload(question, options, other, placeholder, default)
+        )
+        result = await self.request(msg)
+        if not result or result.get("cancelled"):
+            return None
+        return str(result.get("value", "")), bool(result.get("custom"))
+
+    async def request_secret(
+        self,
+        label: str,
+        description: str | None = None,
+        placeholder: str | None = None,
+    ) -> str | None:
+        """Show a masked secret prompt and wait for the value.
+
+        The value is never echoed or stored in the TUI transcript.
+        Returns None if the user cancelled.
+        """
+        msg = create_request(
+            MessageType.SECRET,
+            secret_payload(label, description, placeholder)
+        )
+        result = await self.request(msg)
+        if not result or result.get("cancelled"):
+            return None
+        return str(result.get("value", ""))
+
+    async def request_diff(
+        self,
+        diff: str | None = None,
+        hunks: list[dict] | None = None,
+        title: str | None = None,
+        path: str | None = None,
+        timeout: float = 600.0,
+    ) -> list[int] | None:
+        """Show a diff for per-hunk review and wait for the decision.
+
+        Returns:
+            Zero-based indices of accepted hunks, or None if cancelled
+        """
+        msg = create_request(
+            MessageType.DIFF,
+            diff_payload(diff, hunks, title, path)
+        )
+        result = await self.request(msg, timeout=timeout)
+        if not result or result.get("cancelled"):
+            return None
+        return [int(i) for i in result.get("accepted", [])]
+
+    async def request_autocomplete(
+        self,
+        label: str,
+        suggestions: list[str] | None = None,
+        placeholder: str | None = None,
+        default: str | None = None,
+        more_suggestions: AsyncIterator[list[str]] | None = None,
+    ) -> str | None:
+        """Show a prompt with a filtered suggestion dropdown and wait for the value.
+
+        Args:
+            label: Prompt label
+            suggestions: Initial suggestion list
+            placeholder: Input placeholder
+            default: Initial input text
+            more_suggestions: Optional async iterator of suggestion batches that
+                are streamed into the open prompt as they arrive
+
+        Returns:
+            The chosen suggestion or typed text, or None if cancelled
+        """
+        msg = create_request(
+            MessageType.AUTOCOMPLETE,
+            autocomplete_payload(label, suggestions, placeholder, default)
+        )
+
+        feeder: asyncio.Task | None = None
+        if more_suggestions is not None:
+            request_id = msg.id or ""
+
+            async def feed() -> None:
+                async for batch in more_suggestions:
+                    await self.send(create_message(
+                        MessageType.UPDATE,
+                        update_payload(request_id, suggestions=batch, append=True),
+                    ))
+
+            feeder = asyncio.create_task(feed())
+
+        try:
+            result = await self.request(msg)
+        finally:
+            if feeder and not feeder.done():
+                feeder.cancel()
+
+        if not result or result.get("cancelled"):
+            return None
+        return str(result.get("value", ""))
+
     async def send_alert(
         self,
         message: str,
@@ -467,6 +1318,160 @@ class TUIBridge(BaseBridge):
         )
         await self.send(msg)
 
+    async def send_layout(self, layout: UILayout) -> None:
+        """Show a layout: its components, then its dashboard regions, if
+        it has rows, for later messages to draw into; see ``region``."""
+        msg = create_message(MessageType.LAYOUT, layout.to_dict())
+        await self.send(msg)
+
+    async def send_artifact(
+        self,
+        title: str | None = None,
+        markdown: str | None = None,
+        code: str | None = None,
+        language: str = "text",
+        table: dict[str, Any] | None = None,
+        artifact_id: str | None = None,
+    ) -> str:
+        """Keep a key output in the artifacts panel beside the chat.
+
+        Args:
+            title: Tab title (defaults to one from the content)
+            markdown: Markdown content
+            code: Code content, highlighted as language
+            language: Language of code
+            table: Table content, as made by table_payload
+            artifact_id: ID of an artifact to replace
+
+        Returns:
+            The artifact's ID, for replacing it later
+        """
+        msg = create_message(
+            MessageType.ARTIFACT,
+            artifact_payload(title, markdown, code, language, table),
+            artifact_id or str(uuid.uuid4()),
+        )
+        await self.send(msg)
+        return msg.id or ""
+
+    async def send_error(
+        self,
+        message: str,
+        code: str | None = None,
+        details: str | None = None,
+        retryable: bool = False,
+    ) -> None:
+        """Report that the agent failed, ending its turn.
+
+        Args:
+            message: What went wrong
+            code: Short machine-readable name, e.g. "rate_limited"
+            details: Longer explanation, shown below the message
+            retryable: Offer the user a retry; when they take it, a
+                ``retry`` event arrives carrying the code
+        """
+        msg = create_message(
+            MessageType.ERROR,
+            error_payload(message, code, details, retryable)
+        )
+        await self.send(msg)
+
+    async def send_metadata(
+        self,
+        input_tokens: int = 0,
+        output_tokens: int = 0,
+        cost: float = 0.0,
+    ) -> None:
+        """Report what a step such as a model call used.
+
+        The TUI adds up tokens and cost over the session. If it has a
+        budget and the session goes over it, a ``budget_exceeded`` event
+        arrives with the totals.
+
+        Args:
+            input_tokens: Tokens sent to the model
+            output_tokens: Tokens the model generated
+            cost: Cost of the step in dollars
+        """
+        msg = create_message(
+            MessageType.METADATA,
+            metadata_payload(input_tokens, output_tokens, cost)
+        )
+        await self.send(msg)
+
+    async def send_qrcode(
+        self,
+        data: str,
+        title: str | None = None,
+        caption: str | None = None,
+        level: Literal["L", "M", "Q", "H"] | None = None,
+    ) -> None:
+        """Show a URL or text as a scannable QR code.
+
+        Args:
+            data: URL or text to encode
+            title: Title shown above the code
+            caption: Caption shown below the code
+            level: Error correction level (defaults to "M")
+        """
+        msg = create_message(
+            MessageType.QRCODE,
+            qrcode_payload(data, title, caption, level)
+        )
+        await self.send(msg)
+
+    async def send_map(
+        self,
+        points: list[dict[str, Any]],
+        title: str | None = None,
+        region: str | None = None,
+        zoom: int = 0,
+        center: tuple[float, float] | None = None,
+    ) -> None:
+        """Plot labelled points on an ASCII world map.
+
+        Args:
+            points: Dicts with "lat", "lon" and an optional "label"
+            title: Map title
+            region: Named area to show (defaults to "world")
+            zoom: Zoom level 0-8; each level halves the span
+            center: (lat, lon) to zoom around, defaulting to the points
+        """
+        msg = create_message(
+            MessageType.MAP,
+            map_payload(points, title, region, zoom, center)
+        )
+        await self.send(msg)
+
+    async def send_math(self, tex: str, title: str | None = None) -> None:
+        """Show a LaTeX expression as display math.
+
+        The terminal approximates the expression with Unicode, so common
+        symbols, scripts and fractions render well and layout-heavy
+        constructs such as matrices do not.
+
+        Args:
+            tex: LaTeX expression without surrounding $$
+            title: Title shown above the expression
+        """
+        msg = create_message(MessageType.MATH, math_payload(tex, title))
+        await self.send(msg)
+
+    async def send_rich(self, obj: Any) -> None:
+        """Show an object the way Jupyter would, from its MIME bundle.
+
+        The TUI shows the best representation it can render, such as
+        markdown, a PNG drawn in blocks or, failing all else, text/plain.
+        Representations it did not list in its hello are not sent.
+
+        Args:
+            obj: A MIME bundle dict, or an object with _repr_mimebundle_,
+                _repr_markdown_, _repr_png_ and the like
+        """
+        accept = (self._tui_hello or {}).get("rich")
+        msg = create_message(MessageType.RICH, rich_payload(mime_bundle(obj), accept))
+        await self.send(msg)
+
     async def send_spinner(self, message: str) -> None:
         """Show a loading spinner."""
         msg = create_message(MessageType.SPINNER, spinner_payload(message))
@@ -485,9 +1490,96 @@ class TUIBridge(BaseBridge):
         msg = create_message(MessageType.STATUS, status_payload(message, tokens))
         await self.send(msg)
 
-    async def send_clear(self, scope: str = "chat") -> None:
-        """Clear part of the UI."""
-        msg = create_message(MessageType.CLEAR, clear_payload(scope))
+    async def scroll_to(self, target: str) -> None:
+        """Scroll the transcript to a message, e.g. the step that failed.
+
+        Ignored unless the user allows it (``agent_control``).
+
+        Args:
+            target: ID of the message to scroll to, or "top" or "bottom"
+        """
+        await self.send(create_message(MessageType.SCROLL_TO, scroll_to_payload(target)))
+
+    async def focus_input(self) -> None:
+        """Move keyboard focus to the input. Ignored unless ``agent_control`` is set."""
+        await self.send(create_message(MessageType.FOCUS_INPUT))
+
+    async def collapse(self, target: str, summary: str | None = None) -> None:
+        """Collapse a message to one line. Ignored unless ``agent_control`` is set.
+
+        Args:
+            target: ID of the message to collapse
+            summary: Line shown instead of the message's first line
+        """
+        await self.send(create_message(MessageType.COLLAPSE, collapse_payload(target, summary)))
+
+    async def expand(self, target: str) -> None:
+        """Expand a collapsed message. Ignored unless ``agent_control`` is set."""
+        await self.send(create_message(MessageType.EXPAND, collapse_payload(target)))
+
+    async def suggest_input(self, draft: str, completion: str) -> None:
+        """Suggest how to complete the user's draft, shown as ghost text.
+
+        The user accepts it with Tab or dismisses it with Esc. Drafts arrive
+        in typing events with state "stop" when the user pauses, which
+        requires ``TUIConfig.typing_idle``.
+
+        Args:
+            draft: The draft being completed
+            completion: Text to add after the draft; empty withdraws it
+        """
+        await self.send(create_message(
+            MessageType.INPUT_SUGGESTION, input_suggestion_payload(draft, completion)
+        ))
+
+    async def register_commands(self, commands: list[dict[str, str]]) -> None:
+        """Register slash commands the user can type, listed in /help and
+        the menu shown while typing one.
+
+        Typing one sends a command event, {"name": ..., "args": ...}, rather
+        than a prompt. Registering again replaces the commands.
+
+        Args:
+            commands: Each with a "name" and optionally a "description" and
+                an "args" hint such as "<path>"
+        """
+        await self.send(create_message(MessageType.COMMANDS, commands_payload(commands)))
+
+    @asynccontextmanager
+    async def tool_call(self, name: str, detail: str | None = None) -> AsyncIterator[None]:
+        """Show a tool call among the activity in the TUI's sidebar.
+
+        The call shows as running within the block, then as done, or as
+        failed if the block raises::
+
+            async with bridge.tool_call("search", detail=query):
+                results = await search(query)
+
+        Args:
+            name: Name of the tool
+            detail: Short description, such as the call's arguments
+        """
+        tool_id = f"tool-{uuid.uuid4().hex[:12]}"
+        await self.send(create_message(MessageType.TOOL, tool_payload(tool_id, name, "running", detail)))
+        try:
+            yield
+        except BaseException as e:
+            await self.send(create_message(MessageType.TOOL, tool_payload(tool_id, name, "failed", str(e) or detail)))
+            raise
+        await self.send(create_message(MessageType.TOOL, tool_payload(tool_id, name, "done", detail)))
+
+    async def send_clear(self, scope: str = "chat", target: str | None = None) -> None:
+        """Clear part of the UI.
+
+        The user can put back what a clear removed from the transcript with
+        ctrl+z.
+
+        Args:
+            scope: "chat", "progress", "all", "last_turn", "system", "by_id"
+                or "before_id"
+            target: Message ID the by_id and before_id scopes need
+        """
+        msg = create_message(MessageType.CLEAR, clear_payload(scope, target))
         await self.send(msg)
 
     async def send_done(self, summary: str | None = None) -> None:
@@ -506,3 +1598,13 @@ async def managed_bridge(config: TUIConfig | None = None, fallback: bool = True)
         yield bridge
     finally:
         await bridge.stop()
+
+
+def _describe_protocol_error(payload: dict[str, Any]) -> str:
+    """Summarize a protocol_error payload, field by field where known."""
+    problems = [
+        f"{f['field']}: {f.get('problem', '')}" if f.get("field") else str(f.get("problem", ""))
+        for f in payload.get("fields") or []
+    ]
+    subject = f"invalid {payload['type']} message" if payload.get("type") else "invalid message"
+    return f"{subject} ({'; '.join(problems) or payload.get('error', '')})"
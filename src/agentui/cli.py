@@ -7,10 +7,15 @@ Commands:
     init    - Create a new agent app scaffold
     quick   - Quick one-shot interaction
     themes  - List available themes
+    replay  - Replay a recorded session and check it against golden frames
+    stress  - Generate synthetic traffic and report render performance
+    bundle  - Pack a saved session into a bundle to share
+    open    - Open a shared bundle read-only
 """
 
 import argparse
 import asyncio
+import subprocess
 import sys
 from pathlib import Path
 
@@ -206,6 +211,8 @@ def cmd_themes(args: argparse.Namespace) -> None:
         ("charm-dark", "Charm signature pink/purple/teal on dark (default)"),
         ("charm-light", "Charm light mode with purple accents"),
         ("charm-auto", "Auto-detect terminal background"),
+        ("high-contrast-dark", "Accessible high contrast on black"),
+        ("high-contrast-light", "Accessible high contrast on white"),
         ("catppuccin-mocha", "Soothing dark with purple accents"),
         ("catppuccin-latte", "Light mode with soft colors"),
         ("dracula", "Classic dark theme"),
@@ -220,6 +227,87 @@ def cmd_themes(args: argparse.Namespace) -> None:
         print(f"  {marker} {name:<20} {desc}")
 
 
+def _tui_binary(tui_path: str | None) -> str:
+    """Locate the agentui-tui binary or exit with an error."""
+    from agentui.bridge.tui_bridge import TUIBridge
+    from agentui.config import TUIConfig
+
+    try:
+        return TUIBridge(TUIConfig(tui_path=tui_path))._find_tui_binary()
+    except FileNotFoundError as e:
+        print(f"Error: {e}", file=sys.stderr)
+        sys.exit(1)
+
+
+def cmd_replay(args: argparse.Namespace) -> None:
+    """Replay a recorded session through the TUI renderer."""
+    cmd = [
+        _tui_binary(args.tui_path), "replay",
+        "--width", str(args.width), "--height", str(args.height),
+    ]
+    if args.theme:
+        cmd += ["--theme", args.theme]
+    if args.assert_frames:
+        cmd += ["--assert-frames", args.assert_frames]
+    if args.update:
+        cmd.append("--update")
+    cmd.append(args.session)
+
+    sys.exit(subprocess.call(cmd))
+
+
+def cmd_bundle(args: argparse.Namespace) -> None:
+    """Pack a saved session into a bundle to share."""
+    cmd = [_tui_binary(args.tui_path), "bundle"]
+    if args.output:
+        cmd += ["-o", args.output]
+    if args.theme:
+        cmd += ["--theme", args.theme]
+    if args.history_dir:
+        cmd += ["--history-dir", args.history_dir]
+    cmd.append(args.session)
+
+    sys.exit(subprocess.call(cmd))
+
+
+def cmd_open(args: argparse.Namespace) -> None:
+    """Open a shared bundle read-only."""
+    cmd = [_tui_binary(args.tui_path), "open"]
+    if args.theme:
+        cmd += ["--theme", args.theme]
+    cmd.append(args.bundle)
+
+    sys.exit(subprocess.call(cmd))
+
+
+def cmd_render_server(args: argparse.Namespace) -> None:
+    """Serve the renderer over HTTP."""
+    cmd = [
+        _tui_binary(args.tui_path), "render-server",
+        "--addr", args.addr,
+        "--width", str(args.width),
+        "--theme", args.theme,
+    ]
+    if args.strict_protocol:
+        cmd.append("--strict-protocol")
+
+    sys.exit(subprocess.call(cmd))
+
+
+def cmd_stress(args: argparse.Namespace) -> None:
+    """Generate synthetic traffic and report render performance."""
+    cmd = [
+        _tui_binary(args.tui_path), "stress",
+        "--rate", str(args.rate),
+        "--types", args.types,
+        "--duration", f"{args.duration}s",
+    ]
+    if args.live:
+        cmd.append("--live")
+
+    sys.exit(subprocess.call(cmd))
+
+
 def main() -> None:
     """Main CLI entry point."""
     parser = argparse.ArgumentParser(
@@ -259,6 +347,66 @@ def main() -> None:
     themes_parser = subparsers.add_parser("themes", help="List available themes")
     themes_parser.set_defaults(func=cmd_themes)
 
+    # replay command
+    replay_parser = subparsers.add_parser(
+        "replay", help="Replay a recorded session and compare frames to goldens"
+    )
+    replay_parser.add_argument("session", help="Session file written with --record")
+    replay_parser.add_argument("--assert-frames", metavar="DIR", help="Golden frame directory")
+    replay_parser.add_argument("--update", action="store_true", help="Rewrite golden frames")
+    replay_parser.add_argument("--width", type=int, default=80, help="Terminal width")
+    replay_parser.add_argument("--height", type=int, default=24, help="Terminal height")
+    replay_parser.add_argument("--theme", "-t", help="UI theme")
+    replay_parser.add_argument("--tui-path", help="Path to agentui-tui binary")
+    replay_parser.set_defaults(func=cmd_replay)
+
+    # stress command
+    stress_parser = subparsers.add_parser(
+        "stress", help="Generate synthetic traffic and report render latencies"
+    )
+    stress_parser.add_argument("--rate", type=int, default=500, help="Messages per second")
+    stress_parser.add_argument(
+        "--types", default="text,progress,table",
+        help="Comma-separated message types (text, markdown, progress, table, code, alert)",
+    )
+    stress_parser.add_argument("--duration", type=float, default=10, help="Seconds to run")
+    stress_parser.add_argument("--live", action="store_true", help="Render to the terminal")
+    stress_parser.add_argument("--tui-path", help="Path to agentui-tui binary")
+    stress_parser.set_defaults(func=cmd_stress)
+
+    # bundle command
+    bundle_parser = subparsers.add_parser(
+        "bundle", help="Pack a saved session into a bundle to share"
+    )
+    bundle_parser.add_argument(
+        "session", help='Session name, "last" for the most recent, or a session file'
+    )
+    bundle_parser.add_argument("--output", "-o", help="Bundle file (default: <session>.aui)")
+    bundle_parser.add_argument("--theme", "-t", help="Theme to bundle (default: configured)")
+    bundle_parser.add_argument("--history-dir", help="Directory of saved sessions")
+    bundle_parser.add_argument("--tui-path", help="Path to agentui-tui binary")
+    bundle_parser.set_defaults(func=cmd_bundle)
+
+    # open command
+    open_parser = subparsers.add_parser("open", help="Open a shared bundle read-only")
+    open_parser.add_argument("bundle", help="Bundle file written by agentui bundle")
+    open_parser.add_argument("--theme", "-t", help="UI theme (default: the bundled one)")
+    open_parser.add_argument("--tui-path", help="Path to agentui-tui binary")
+    open_parser.set_defaults(func=cmd_open)
+
+    # render-server command
+    render_parser = subparsers.add_parser(
+        "render-server", help="Render protocol messages over HTTP, without a terminal"
+    )
+    render_parser.add_argument("--addr", default=":8080", help="Address to listen on")
+    render_parser.add_argument("--width", type=int, default=80, help="Default width")
+    render_parser.add_argument("--theme", "-t", default="charm-dark", help="Default theme")
+    render_parser.add_argument(
+        "--strict-protocol", action="store_true", help="Reject messages with unknown fields"
+    )
+    render_parser.add_argument("--tui-path", help="Path to agentui-tui binary")
+    render_parser.set_defaults(func=cmd_render_server)
+
     args = parser.parse_args()
 
     if not args.command:
@@ -116,6 +116,104 @@ class TUIConfig:
         debug: Enable debug logging
         reconnect_attempts: Number of reconnection attempts on failure
         reconnect_delay: Delay between reconnection attempts (seconds)
+        keepalive_interval: Seconds between keepalive events while the user
+            is composing a prompt (None disables keep-alives)
+        typing_idle: Send typing events with state "start" when the user
+            starts composing a prompt and "stop" when they send or clear it,
+            or after this many seconds without a keystroke (None disables
+            typing events). A pause includes the draft, which the agent can
+            complete with ``suggest_input``
+        heartbeat_interval: Seconds between the TUI's pings; if the agent
+            leaves them unanswered for two intervals, the user is asked
+            whether to keep waiting or quit (None disables pings)
+        startup_timeout: Keep the TUI's input closed until the bridge's hello
+            arrives, and show what to check if it has not in this many
+            seconds (None opens the input at once)
+        compress_threshold: Gzip message payloads of at least this many bytes
+            in both directions, once the TUI has answered the bridge's hello
+            message (None disables compression)
+        flow_window: Have the TUI grant credits for at most this many
+            messages waiting to be shown; the bridge holds back messages
+            until it has credit, so fast streams cannot overrun the TUI
+            (None disables flow control)
+        status_rate: Most times a second the TUI draws floods of status and
+            progress updates, showing the latest values (None keeps the
+            TUI's default of 20, 0 draws every update)
+        budget: Session budget in dollars; when the cost reported with
+            ``send_metadata`` goes over it, the TUI warns the user and sends
+            a ``budget_exceeded`` event (None sets no budget)
+        token_budget: Session budget in tokens, as for budget
+        max_code_lines: Most lines of a code block the TUI draws; the rest
+            is cut with a note and can be opened in a full view (None keeps
+            the TUI's default of 2000, 0 draws all)
+        max_table_rows: Most rows of a table the TUI draws, as for
+            max_code_lines (None keeps the default of 500)
+        max_markdown_bytes: Most bytes of a reply the TUI draws, as for
+            max_code_lines (None keeps the default of 256 KiB)
+        fold_lines: Replies longer than this many lines are folded to a
+            preview the user expands with o (None keeps the TUI's default of
+            40, 0 never folds)
+        reveal_escapes: Show terminal escape sequences the TUI strips from
+            agent text as visible text, such as ``␛[2J`` (for debugging)
+        strict_protocol: Have the TUI reject malformed messages, unknown
+            payload fields and invalid values, answering each with a
+            protocol_error message (for SDK development)
+        agent_control: Let the agent scroll the transcript, focus the input
+            and collapse messages; the TUI ignores such requests otherwise
+        hints: Show the line of keyboard hints under prompts and viewers;
+            the help overlay (F1) lists the keys either way
+        layout: How the chat uses a wide terminal: "full", "center" to keep
+            it at most max_width columns wide, or "columns" to also show
+            messages the user pins beside it
+        max_width: Widest the chat grows in the center and columns layouts
+            (None keeps the TUI's default of 120)
+        sidebar: Start with the sidebar of recent sessions, tool calls
+            and pinned artifacts shown; ctrl+b toggles it
+        timestamps: When messages arrived: "minute" to mark each minute
+            something happened in, "message" for the time beside every
+            message, "relative" for how long ago, or "off", which also hides
+            how long replies took
+        time_format: Layout for times in Go's reference format, such as
+            "15:04:05" (None follows the hour cycle)
+        retry_replaces: Retrying the last exchange (ctrl+r, /retry) removes
+            the answer it replaces from the transcript instead of showing
+            the input again over the new answer
+        turn_timing: Show under each turn how long the agent took to start
+            answering, to stream its reply and to finish, tool calls included
+        links: Clickable OSC 8 hyperlinks for URLs: "auto" (detect terminal
+            support), "on" or "off"
+        record_path: Record every incoming protocol message to this file for
+            later use with ``agentui replay``
+        protocol_log: Log every message sent and received, with timestamps,
+            to this JSONL file; secret values are redacted
+        journal_path: Journal incoming messages to this file before the TUI
+            shows them; when the bridge restarts a TUI that died, the
+            transcript is rebuilt from it
+        history_dir: Where the TUI saves each session's transcript to reopen
+            later (None keeps the TUI's default of
+            ``~/.local/share/agentui/sessions``, "" disables)
+        input_history: File the TUI keeps sent prompts in, to recall with
+            Up in later sessions (None keeps the TUI's default of
+            ``~/.local/share/agentui/prompts.jsonl``, "" keeps them for the
+            session only)
+        resume: Reopen this saved session, or "last" for the most recent,
+            and continue it
+        schedules: Prompts the TUI sends by itself while the session is
+            open, e.g. "every 15m: Summarize new errors" or
+            "0 9 * * 1-5: Daily report"; a schedule without a prompt sends
+            ``tick`` events instead (None keeps the user's configured
+            schedules)
+        encoding: Wire encoding, "json" (JSON Lines) or "msgpack" for less
+            serialization overhead on high-frequency streams (requires the
+            msgpack package)
+        framing: Message framing, "lines" or "length" to prefix each message
+            with its 4-byte length so messages may contain raw newlines
+        protocol_fd: Run the protocol over a socket pair handed to the TUI as
+            an extra file descriptor rather than over its stdin/stdout, which
+            then stay attached to the terminal, so nothing else written to
+            them can corrupt the message stream (POSIX only)
+        telemetry: Allow the TUI's usage statistics for users who opted in to
+            them; False turns them off for every session this app starts
     """
 
     theme: str = "catppuccin-mocha"
@@ -125,6 +223,42 @@ class TUIConfig:
     debug: bool = False
     reconnect_attempts: int = 3
     reconnect_delay: float = 1.0
+    keepalive_interval: float | None = None
+    typing_idle: float | None = None
+    heartbeat_interval: float | None = None
+    startup_timeout: float | None = None
+    compress_threshold: int | None = None
+    flow_window: int | None = None
+    status_rate: int | None = None
+    budget: float | None = None
+    token_budget: int | None = None
+    max_code_lines: int | None = None
+    max_table_rows: int | None = None
+    max_markdown_bytes: int | None = None
+    fold_lines: int | None = None
+    reveal_escapes: bool = False
+    strict_protocol: bool = False
+    agent_control: bool = False
+    hints: bool = True
+    timestamps: str = "minute"
+    time_format: str | None = None
+    retry_replaces: bool = False
+    turn_timing: bool = False
+    layout: str = "full"
+    max_width: int | None = None
+    sidebar: bool = False
+    links: str = "auto"
+    record_path: str | None = None
+    protocol_log: str | None = None
+    journal_path: str | None = None
+    history_dir: str | None = None
+    input_history: str | None = None
+    resume: str | None = None
+    schedules: list[str] | None = None
+    encoding: str = "json"
+    framing: str = "lines"
+    protocol_fd: bool = False
+    telemetry: bool = True
 
     @classmethod
     def from_env(cls) -> "TUIConfig":
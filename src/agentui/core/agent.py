@@ -351,6 +351,8 @@ class AgentCore:
 
         if event_type == MessageType.INPUT.value:
             await self._handle_input_event(event)
+        elif event_type == MessageType.RETRY.value:
+            await self._handle_retry_event(event)
         elif event_type == MessageType.QUIT.value:
             await self._handle_quit_event()
         elif event_type == MessageType.CANCEL.value:
@@ -361,11 +363,25 @@ class AgentCore:
 
     async def _handle_input_event(self, event: Any) -> None:
         """Handle user input event."""
-        assert self.bridge is not None, "Bridge must be set before handling input"
-        bridge = self.bridge  # Local variable for type safety
         user_input = event.payload.get("content", "")
         if not user_input:
             return
+        await self._respond(user_input)
+
+    async def _handle_retry_event(self, event: Any) -> None:
+        """Handle the user retrying the last exchange: forget it and answer
+        its input again. Retries of failed work carry no input; this agent
+        never offers them."""
+        user_input = event.payload.get("input", "")
+        if not user_input:
+            return
+        self.message_handler.forget_last_exchange()
+        await self._respond(user_input)
+
+    async def _respond(self, user_input: str) -> None:
+        """Answer the user's input, streaming the reply to the bridge."""
+        assert self.bridge is not None, "Bridge must be set before handling input"
+        bridge = self.bridge  # Local variable for type safety
 
         logger.debug(f"Processing input: {user_input[:50]}...")
 
@@ -121,6 +121,16 @@ class MessageHandler:
         """Add a user message to the state."""
         self.state.messages.append(Message(role="user", content=content))
 
+    def forget_last_exchange(self) -> None:
+        """Drop the user's last message and everything after it, for the
+        turn to be answered again. Tool results, also sent as the user,
+        are part of the exchange."""
+        for i in range(len(self.state.messages) - 1, -1, -1):
+            msg = self.state.messages[i]
+            if msg.role == "user" and not msg.tool_results:
+                del self.state.messages[i:]
+                return
+
     def add_tool_results(self, tool_results: list[Any]) -> None:
         """Add tool results to message state."""
         for result in tool_results:
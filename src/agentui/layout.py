@@ -19,6 +19,26 @@ class LayoutComponent:
     height: int | None = None  # Height hint (percentage or rows)
 
 
+@dataclass
+class LayoutRegion:
+    """A named cell of a dashboard grid, filled by messages sent to it."""
+    id: str
+    title: str | None = None
+    span: int | None = None  # Share of its row's width (default 1)
+    height: int | None = None  # Most lines shown, keeping the latest
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert region to dict, leaving out unset fields."""
+        data: dict[str, Any] = {"id": self.id}
+        if self.title:
+            data["title"] = self.title
+        if self.span:
+            data["span"] = self.span
+        if self.height:
+            data["height"] = self.height
+        return data
+
+
 class UILayout:
     """
     Multi-component layout for dashboard-style UIs (Phase 5).
@@ -61,6 +81,7 @@ class UILayout:
         self.title = title
         self.description = description
         self.components: list[LayoutComponent] = []
+        self.rows: list[list[LayoutRegion]] = []
 
     def add_table(
         self,
@@ -102,7 +123,7 @@ class UILayout:
     def add_code(
         self,
         code: str,
-        language: str = "text",
+        language: str = "",
         title: str | None = None,
         area: str | None = None,
         **kwargs: Any
@@ -112,7 +133,7 @@ class UILayout:
 
         Args:
             code: Source code
-            language: Programming language
+            language: Programming language; empty lets the TUI guess it
             title: Code block title
             area: Layout area hint
             **kwargs: Any: Additional layout hints
@@ -229,6 +250,27 @@ class UILayout:
         ))
         return self
 
+    def add_row(self, *regions: LayoutRegion) -> "UILayout":
+        """
+        Add a row of dashboard regions below the previous ones.
+
+        Messages sent to a region afterwards, see ``TUIBridge.region``,
+        draw into it and keep the dashboard live:
+
+            layout = UILayout(title="Ops").add_row(
+                LayoutRegion("cpu", "CPU", span=2),
+                LayoutRegion("mem", "Memory"),
+            ).add_row(LayoutRegion("log", "Log", height=10))
+
+        Args:
+            *regions: Regions left to right; IDs are unique in the layout
+
+        Returns:
+            Self for chaining
+        """
+        self.rows.append(list(regions))
+        return self
+
     def to_dict(self) -> dict[str, Any]:
         """
         Convert layout to dict for protocol serialization.
@@ -236,7 +278,7 @@ class UILayout:
         Returns:
             Dict representation
         """
-        return {
+        data: dict[str, Any] = {
             "title": self.title,
             "description": self.description,
             "components": [
@@ -250,6 +292,9 @@ class UILayout:
                 for comp in self.components
             ]
         }
+        if self.rows:
+            data["rows"] = [[r.to_dict() for r in row] for row in self.rows]
+        return data
 
     def __repr__(self) -> str:
         """String representation."""
@@ -4,6 +4,8 @@ Protocol handling for communication with Go TUI.
 JSON Lines protocol over stdio.
 """
 
+import base64
+import gzip
 import json
 import uuid
 from dataclasses import dataclass
@@ -29,15 +31,71 @@ class MessageType(str, Enum):
     DONE = "done"
     UPDATE = "update"  # Phase 3: Progressive streaming - update existing component
     LAYOUT = "layout"  # Phase 5: Multi-component layouts
+    SECRET = "secret"
+    AUTOCOMPLETE = "autocomplete"
+    CODE_PATCH = "code_patch"
+    TABLE_CHUNK = "table_chunk"
+    CODE_CHUNK = "code_chunk"
+    DIFF = "diff"
+    TABLE_UPDATE = "table_update"
+    QRCODE = "qrcode"
+    MAP = "map"
+    MATH = "math"
+    HELLO = "hello"  # sent both ways to negotiate compression
+    PONG = "pong"
+    SCROLL_TO = "scroll_to"  # control messages; see TUIConfig.agent_control
+    FOCUS_INPUT = "focus_input"
+    COLLAPSE = "collapse"
+    EXPAND = "expand"
+    INPUT_SUGGESTION = "input_suggestion"
+    COMMANDS = "commands"
+    TOOL = "tool"
+    QUESTION = "question"
+    BLOB = "blob"
+    ERROR = "error"
+    ARTIFACT = "artifact"
+    METADATA = "metadata"
+    RICH = "rich"
 
     # Go → Python (user events)
     INPUT = "input"
     FORM_RESPONSE = "form_response"
     CONFIRM_RESPONSE = "confirm_response"
     SELECT_RESPONSE = "select_response"
+    SECRET_RESPONSE = "secret_response"
+    AUTOCOMPLETE_RESPONSE = "autocomplete_response"
     CANCEL = "cancel"
     QUIT = "quit"
     RESIZE = "resize"
+    KEEPALIVE = "keepalive"
+    TYPING = "typing"
+    READY_FOR_MORE = "ready_for_more"
+    QUESTION_RESPONSE = "question_response"
+    RETRY = "retry"
+    BUDGET_EXCEEDED = "budget_exceeded"
+    TICK = "tick"
+    COMMAND = "command"
+    ATTACHMENT = "attachment"
+    UNSUPPORTED = "unsupported"
+    DIFF_RESPONSE = "diff_response"
+    PING = "ping"
+    PROTOCOL_ERROR = "protocol_error"
+
+
+COMPRESSION_GZIP = "gzip"
+
+# Compressions Message.from_dict can decode, advertised in hello messages.
+SUPPORTED_COMPRESSIONS = [COMPRESSION_GZIP]
+
+# Payload format version; the TUI upconverts messages from older versions.
+PROTOCOL_VERSION = 2
+
+# Ways of sending blobs, listed under "blobs" in the TUI's hello.
+BLOB_BASE64 = "base64"
+BLOB_FILE = "file"
+
+# Raw bytes per blob message when sending base64 chunks.
+BLOB_CHUNK_SIZE = 192 * 1024
 
 
 @dataclass
@@ -46,27 +104,98 @@ class Message:
     type: str
     id: str | None = None
     payload: dict | None = None
+    version: int | None = PROTOCOL_VERSION
+    timeout: float | None = None  # seconds a request's sender waits for the answer
+    agent_id: str | None = None  # the logical agent, when several share the TUI
+    region: str | None = None  # the dashboard region the message draws into
+    pinned: bool | None = None  # keep what it adds in the strip atop the transcript
+
+    def to_json(self, compress_threshold: int | None = None) -> str:
+        """Serialize to JSON line.
+
+        Args:
+            compress_threshold: Gzip the payload when its JSON encoding is at
+                least this many bytes (None disables compression)
+        """
+        return json.dumps(self.to_dict(compress_threshold))
 
-    def to_json(self) -> str:
-        """Serialize to JSON line."""
+    def to_msgpack(self, compress_threshold: int | None = None) -> bytes:
+        """Serialize to MessagePack (requires the msgpack package)."""
+        return _msgpack().packb(self.to_dict(compress_threshold))
+
+    def to_dict(self, compress_threshold: int | None = None) -> dict[str, Any]:
+        """Convert to the wire structure shared by all encodings."""
         data: dict[str, Any] = {"type": self.type}
         if self.id:
             data["id"] = self.id
+        if self.version:
+            data["version"] = self.version
+        if self.timeout:
+            data["timeout"] = self.timeout
+        if self.agent_id:
+            data["agent_id"] = self.agent_id
+        if self.region:
+            data["region"] = self.region
+        if self.pinned:
+            data["pinned"] = True
         if self.payload:
-            data["payload"] = self.payload
-        return json.dumps(data)
+            encoded = json.dumps(self.payload)
+            if compress_threshold and len(encoded) >= compress_threshold:
+                data["payload"] = base64.b64encode(
+                    gzip.compress(encoded.encode("utf-8"))
+                ).decode("ascii")
+                data["compression"] = COMPRESSION_GZIP
+            else:
+                data["payload"] = self.payload
+        return data
 
     @classmethod
     def from_json(cls, line: str) -> "Message":
-        """Deserialize from JSON line."""
-        data = json.loads(line)
+        """Deserialize from JSON line, decompressing the payload if needed."""
+        return cls.from_dict(json.loads(line))
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> "Message":
+        """Build from the wire structure, decompressing the payload if needed."""
+        payload = data.get("payload")
+        compression = data.get("compression")
+        if compression:
+            if compression != COMPRESSION_GZIP:
+                raise ValueError(f"Unsupported compression: {compression}")
+            payload = json.loads(gzip.decompress(base64.b64decode(payload)))
         return cls(
             type=data.get("type", ""),
             id=data.get("id"),
-            payload=data.get("payload"),
+            payload=payload,
+            version=data.get("version"),
+            timeout=data.get("timeout"),
+            agent_id=data.get("agent_id"),
+            region=data.get("region"),
+            pinned=data.get("pinned"),
         )
 
 
+def _msgpack() -> Any:
+    """Import msgpack, which is only needed for the msgpack encoding."""
+    try:
+        import msgpack
+    except ImportError as e:
+        raise ImportError(
+            "The msgpack encoding requires the msgpack package: pip install msgpack"
+        ) from e
+    return msgpack
+
+
+def batch_to_json(messages: list[Message], compress_threshold: int | None = None) -> str:
+    """Serialize messages as one JSON line, which the TUI applies together."""
+    return json.dumps([m.to_dict(compress_threshold) for m in messages])
+
+
+def batch_to_msgpack(messages: list[Message], compress_threshold: int | None = None) -> bytes:
+    """Serialize messages as one MessagePack array, applied together."""
+    return _msgpack().packb([m.to_dict(compress_threshold) for m in messages])
+
+
 # --- Payload builders for Python → Go ---
 
 def text_payload(content: str, done: bool = False) -> dict[str, Any]:
@@ -74,11 +203,25 @@ def text_payload(content: str, done: bool = False) -> dict[str, Any]:
     return {"content": content, "done": done}
 
 
-def markdown_payload(content: str, title: str | None = None) -> dict[str, Any]:
-    """Create markdown payload."""
+def markdown_payload(
+    content: str,
+    title: str | None = None,
+    append: bool = False,
+    done: bool = False,
+) -> dict[str, Any]:
+    """
+    Create markdown payload.
+
+    With append=True the content is added to the open markdown message
+    sharing the same message ID; done=True closes that message.
+    """
     payload: dict[str, Any] = {"content": content}
     if title:
         payload["title"] = title
+    if append:
+        payload["append"] = True
+    if done:
+        payload["done"] = True
     return payload
 
 
@@ -86,13 +229,28 @@ def progress_payload(
     message: str,
     percent: float | None = None,
     steps: list[dict] | None = None,
+    progress_id: str | None = None,
+    done: bool = False,
+    eta: float | None = None,
 ) -> dict[str, Any]:
-    """Create progress payload."""
+    """
+    Create progress payload.
+
+    progress_id names one of several concurrent progress bars; done
+    removes that bar. eta is the estimated number of seconds remaining,
+    shown in the user's preferred format.
+    """
     payload: dict[str, Any] = {"message": message}
     if percent is not None:
         payload["percent"] = percent
     if steps:
         payload["steps"] = steps
+    if progress_id:
+        payload["id"] = progress_id
+    if done:
+        payload["done"] = True
+    if eta is not None:
+        payload["eta"] = eta
     return payload
 
 
@@ -102,8 +260,14 @@ def form_payload(
     description: str | None = None,
     submit_label: str = "Submit",
     cancel_label: str = "Cancel",
+    timeout_seconds: float | None = None,
 ) -> dict[str, Any]:
-    """Create form payload."""
+    """
+    Create form payload.
+
+    With ``timeout_seconds`` the TUI submits the fields' defaults if the
+    form goes that long untouched.
+    """
     payload: dict[str, Any] = {"fields": fields}
     if title:
         payload["title"] = title
@@ -111,6 +275,8 @@ def form_payload(
         payload["description"] = description
     payload["submit_label"] = submit_label
     payload["cancel_label"] = cancel_label
+    if timeout_seconds:
+        payload["timeout_seconds"] = timeout_seconds
     return payload
 
 
@@ -160,29 +326,116 @@ def table_payload(
 
 def code_payload(
     code: str,
-    language: str = "text",
+    language: str = "",
     title: str | None = None,
     line_numbers: bool = True,
 ) -> dict[str, Any]:
-    """Create code payload."""
+    """Create code payload.
+
+    Leave out the language to have the TUI guess it from the code.
+    """
     payload: dict[str, Any] = {
         "code": code,
-        "language": language,
         "line_numbers": line_numbers,
     }
+    if language:
+        payload["language"] = language
     if title:
         payload["title"] = title
     return payload
 
 
+def code_patch_payload(
+    code_id: str,
+    lines: list[str],
+    start: int | None = None,
+    end: int | None = None,
+) -> dict[str, Any]:
+    """
+    Create code patch payload to edit a previously sent code block.
+
+    Without start the lines are appended. Otherwise lines start..end
+    (1-based, inclusive) are replaced; end defaults to start, and
+    end = start - 1 inserts before start without removing anything.
+    """
+    if start is None:
+        return {"id": code_id, "op": "append", "lines": lines}
+    return {
+        "id": code_id,
+        "op": "replace",
+        "start": start,
+        "end": start if end is None else end,
+        "lines": lines,
+    }
+
+
+def table_chunk_payload(
+    table_id: str,
+    rows: list[list[str]],
+    total: int | None = None,
+    done: bool = False,
+) -> dict[str, Any]:
+    """Create payload streaming more rows into a previously sent table."""
+    payload: dict[str, Any] = {"id": table_id, "rows": rows, "done": done}
+    if total is not None:
+        payload["total"] = total
+    return payload
+
+
+def table_update_payload(
+    table_id: str,
+    rows: list[list[str]],
+    replace: bool = False,
+    start: int | None = None,
+    footer: str | None = None,
+) -> dict[str, Any]:
+    """
+    Create payload changing the rows of a previously sent table.
+
+    Rows are appended unless replace is set. With replace and start, rows
+    are overwritten from that zero-based index; without start, all rows
+    are replaced.
+    """
+    payload: dict[str, Any] = {
+        "id": table_id,
+        "op": "replace" if replace else "append",
+        "rows": rows,
+    }
+    if replace and start is not None:
+        payload["start"] = start
+    if footer:
+        payload["footer"] = footer
+    return payload
+
+
+def code_chunk_payload(
+    code_id: str,
+    code: str,
+    total: int | None = None,
+    done: bool = False,
+) -> dict[str, Any]:
+    """Create payload streaming more text onto a previously sent code block."""
+    payload: dict[str, Any] = {"id": code_id, "code": code, "done": done}
+    if total is not None:
+        payload["total"] = total
+    return payload
+
+
 def confirm_payload(
     message: str,
     title: str | None = None,
     confirm_label: str = "Yes",
     cancel_label: str = "No",
     destructive: bool = False,
+    timeout_seconds: float | None = None,
+    default: bool = False,
 ) -> dict[str, Any]:
-    """Create confirm payload."""
+    """
+    Create confirm payload.
+
+    With ``timeout_seconds`` the TUI answers ``default`` if the dialog goes
+    that long untouched.
+    """
     payload: dict[str, Any] = {
         "message": message,
         "confirm_label": confirm_label,
@@ -191,16 +444,125 @@ def confirm_payload(
     }
     if title:
         payload["title"] = title
+    if timeout_seconds:
+        payload["timeout_seconds"] = timeout_seconds
+        payload["default"] = default
     return payload
 
 
 def select_payload(
     label: str,
-    options: list[str],
+    options: list[str] | list[dict[str, str]],
+    default: str | None = None,
+    timeout_seconds: float | None = None,
+) -> dict[str, Any]:
+    """
+    Create select payload.
+
+    Options may be plain strings or dicts with "label" and optional
+    "value" and "description" keys. With ``timeout_seconds`` the TUI
+    selects the default, or else the first option, if the menu goes that
+    long untouched.
+    """
+    rich = [
+        {"label": opt, "value": opt} if isinstance(opt, str) else opt
+        for opt in options
+    ]
+    payload: dict[str, Any] = {"label": label, "options": rich}
+    if default:
+        payload["default"] = default
+    if timeout_seconds:
+        payload["timeout_seconds"] = timeout_seconds
+    return payload
+
+
+def question_payload(
+    question: str,
+    options: list[str] | list[dict[str, str]],
+    other: str | None = None,
+    placeholder: str | None = None,
+    default: str | None = None,
+) -> dict[str, Any]:
+    """
+    Create question payload: suggested answers plus a row for the user's own.
+
+    Options are as for select_payload. ``other`` labels the free-text row
+    (default "Other").
+    """
+    payload: dict[str, Any] = {
+        "question": question,
+        "options": select_payload("", options)["options"],
+    }
+    if other:
+        payload["other"] = other
+    if placeholder:
+        payload["placeholder"] = placeholder
+    if default:
+        payload["default"] = default
+    return payload
+
+
+def secret_payload(
+    label: str,
+    description: str | None = None,
+    placeholder: str | None = None,
+) -> dict[str, Any]:
+    """Create secret payload for a masked single-value prompt."""
+    payload: dict[str, Any] = {"label": label}
+    if description:
+        payload["description"] = description
+    if placeholder:
+        payload["placeholder"] = placeholder
+    return payload
+
+
+def diff_payload(
+    diff: str | None = None,
+    hunks: list[dict] | None = None,
+    title: str | None = None,
+    path: str | None = None,
+) -> dict[str, Any]:
+    """
+    Create diff payload for per-hunk review.
+
+    Pass either a unified diff string or pre-split hunks
+    ({"header": "@@ ... @@", "lines": [...]}).
+    """
+    payload: dict[str, Any] = {}
+    if diff:
+        payload["diff"] = diff
+    if hunks:
+        payload["hunks"] = hunks
+    if title:
+        payload["title"] = title
+    if path:
+        payload["path"] = path
+    return payload
+
+
+def split_diff_hunks(diff: str) -> list[dict[str, Any]]:
+    """Split a unified diff into hunks, dropping file headers."""
+    hunks: list[dict[str, Any]] = []
+    for line in diff.rstrip("\n").split("\n"):
+        if line.startswith("@@"):
+            hunks.append({"header": line, "lines": []})
+        elif hunks:
+            hunks[-1]["lines"].append(line)
+    return hunks
+
+
+def autocomplete_payload(
+    label: str,
+    suggestions: list[str] | None = None,
+    placeholder: str | None = None,
     default: str | None = None,
 ) -> dict[str, Any]:
-    """Create select payload."""
-    payload: dict[str, Any] = {"label": label, "options": options}
+    """Create autocomplete payload for a prompt with a suggestion dropdown."""
+    payload: dict[str, Any] = {"label": label}
+    if suggestions:
+        payload["suggestions"] = suggestions
+    if placeholder:
+        payload["placeholder"] = placeholder
     if default:
         payload["default"] = default
     return payload
@@ -218,6 +580,177 @@ def alert_payload(
     return payload
 
 
+def artifact_payload(
+    title: str | None = None,
+    markdown: str | None = None,
+    code: str | None = None,
+    language: str = "",
+    table: dict[str, Any] | None = None,
+) -> dict[str, Any]:
+    """
+    Create artifact payload holding one of markdown, code or a table
+    (a table_payload).
+    """
+    given = [v is not None for v in (markdown, code, table)]
+    if sum(given) != 1:
+        raise ValueError("an artifact holds one of markdown, code or table")
+    payload: dict[str, Any] = {}
+    if title:
+        payload["title"] = title
+    if markdown is not None:
+        payload["markdown"] = markdown
+    elif code is not None:
+        payload["code"] = code_payload(code, language)
+    else:
+        payload["table"] = table
+    return payload
+
+
+def error_payload(
+    message: str,
+    code: str | None = None,
+    details: str | None = None,
+    retryable: bool = False,
+) -> dict[str, Any]:
+    """Create error payload. A retryable error offers the user a retry."""
+    payload: dict[str, Any] = {"message": message}
+    if code:
+        payload["code"] = code
+    if details:
+        payload["details"] = details
+    if retryable:
+        payload["retryable"] = True
+    return payload
+
+
+def metadata_payload(
+    input_tokens: int = 0,
+    output_tokens: int = 0,
+    cost: float = 0.0,
+) -> dict[str, Any]:
+    """
+    Create metadata payload: what a step such as a model call used.
+
+    The TUI adds these up over the session against its budget.
+    """
+    payload: dict[str, Any] = {}
+    if input_tokens or output_tokens:
+        payload["tokens"] = {"input": input_tokens, "output": output_tokens}
+    if cost:
+        payload["cost"] = cost
+    return payload
+
+
+def qrcode_payload(
+    data: str,
+    title: str | None = None,
+    caption: str | None = None,
+    level: Literal["L", "M", "Q", "H"] | None = None,
+) -> dict[str, Any]:
+    """Create QR code payload."""
+    payload: dict[str, Any] = {"data": data}
+    if title:
+        payload["title"] = title
+    if caption:
+        payload["caption"] = caption
+    if level:
+        payload["level"] = level
+    return payload
+
+
+def map_payload(
+    points: list[dict[str, Any]],
+    title: str | None = None,
+    region: str | None = None,
+    zoom: int = 0,
+    center: tuple[float, float] | None = None,
+) -> dict[str, Any]:
+    """Create map payload.
+
+    Args:
+        points: Dicts with "lat", "lon" and an optional "label"
+        title: Map title
+        region: "world" (default), "north-america", "south-america",
+            "europe", "africa", "asia" or "oceania"
+        zoom: Zoom level 0-8; each level halves the span
+        center: (lat, lon) to zoom around, defaulting to the points
+    """
+    payload: dict[str, Any] = {"points": points}
+    if title:
+        payload["title"] = title
+    if region:
+        payload["region"] = region
+    if zoom:
+        payload["zoom"] = zoom
+    if center:
+        payload["center"] = {"lat": center[0], "lon": center[1]}
+    return payload
+
+
+def math_payload(tex: str, title: str | None = None) -> dict[str, Any]:
+    """Create math payload.
+
+    Args:
+        tex: LaTeX expression without surrounding $$; separate lines with \\\\
+        title: Title shown above the expression
+    """
+    payload: dict[str, Any] = {"tex": tex}
+    if title:
+        payload["title"] = title
+    return payload
+
+
+def mime_bundle(obj: Any) -> dict[str, Any]:
+    """Return the MIME bundle of obj, the way Jupyter displays it.
+
+    A dict is taken as a bundle already. Otherwise the object's
+    _repr_mimebundle_ is used, or its _repr_markdown_, _repr_png_ and the
+    like, with repr() as text/plain.
+    """
+    if isinstance(obj, dict):
+        return obj
+    if hasattr(obj, "_repr_mimebundle_"):
+        data = obj._repr_mimebundle_()
+        if isinstance(data, tuple):
+            data = data[0]
+        if data:
+            return data
+    data: dict[str, Any] = {}
+    for mime, method in (
+        ("text/markdown", "_repr_markdown_"),
+        ("image/png", "_repr_png_"),
+        ("image/jpeg", "_repr_jpeg_"),
+        ("text/latex", "_repr_latex_"),
+        ("application/json", "_repr_json_"),
+        ("text/html", "_repr_html_"),
+    ):
+        if hasattr(obj, method):
+            value = getattr(obj, method)()
+            if isinstance(value, tuple):
+                value = value[0]
+            if value is not None:
+                data[mime] = value
+    data["text/plain"] = repr(obj)
+    return data
+
+
+def rich_payload(data: dict[str, Any], accept: list[str] | None = None) -> dict[str, Any]:
+    """Create rich payload from a MIME bundle.
+
+    Args:
+        data: Representations by MIME type, such as text/markdown and
+            image/png; images may be bytes or base64
+        accept: MIME types the TUI renders, from its hello; others are
+            dropped unless none would be left
+    """
+    if accept is not None:
+        data = {mime: value for mime, value in data.items() if mime in accept} or data
+    return {"data": {
+        mime: base64.b64encode(value).decode("ascii") if isinstance(value, bytes) else value
+        for mime, value in data.items()
+    }}
+
+
 def spinner_payload(message: str) -> dict[str, Any]:
     """Create spinner payload."""
     return {"message": message}
@@ -234,9 +767,19 @@ def status_payload(
     return payload
 
 
-def clear_payload(scope: str = "chat") -> dict[str, Any]:
-    """Create clear payload."""
-    return {"scope": scope}
+def clear_payload(scope: str = "chat", target: str | None = None) -> dict[str, Any]:
+    """Create clear payload.
+
+    Args:
+        scope: "chat", "progress", "all", "last_turn" (everything after the
+            user's latest input), "system" (the TUI's notes), "by_id" or
+            "before_id"
+        target: Message ID the by_id and before_id scopes need
+    """
+    payload: dict[str, Any] = {"scope": scope}
+    if target:
+        payload["target"] = target
+    return payload
 
 
 def done_payload(summary: str | None = None) -> dict[str, Any]:
@@ -267,6 +810,7 @@ def layout_payload(
     title: str | None = None,
     description: str | None = None,
     components: list[dict] | None = None,
+    rows: list[list[dict]] | None = None,
 ) -> dict[str, Any]:
     """
     Create layout payload for multi-component layouts (Phase 5).
@@ -275,6 +819,9 @@ def layout_payload(
         title: Layout title
         description: Layout description
         components: List of component dicts with type, payload, area
+        rows: Dashboard grid, top to bottom, of region dicts with "id" and
+            optional "title", "span" and "height"; messages whose region is
+            one of the IDs draw into it
 
     Returns:
         Payload dict for layout message
@@ -286,9 +833,209 @@ def layout_payload(
         payload["description"] = description
     if components:
         payload["components"] = components
+    if rows:
+        payload["rows"] = rows
+    return payload
+
+
+def hello_payload(
+    compression: list[str] | None = None,
+    telemetry: bool | None = None,
+    agent: dict[str, str] | None = None,
+) -> dict[str, Any]:
+    """Create hello payload.
+
+    Args:
+        compression: Payload compressions this side can decode
+            (defaults to SUPPORTED_COMPRESSIONS)
+        telemetry: False turns the TUI's opt-in usage statistics off for
+            the session
+        agent: Identifies the agent in the TUI's session bundles and crash
+            reports, with "name", "version" and "sdk" keys
+    """
+    if compression is None:
+        compression = SUPPORTED_COMPRESSIONS
+    payload: dict[str, Any] = {"compression": list(compression)}
+    if telemetry is not None:
+        payload["telemetry"] = telemetry
+    if agent:
+        payload["agent"] = {k: v for k, v in agent.items() if v}
+    return payload
+
+
+def blob_payloads(
+    blob_id: str,
+    data: bytes,
+    name: str | None = None,
+    mime_type: str | None = None,
+    chunk_size: int = BLOB_CHUNK_SIZE,
+) -> list[dict[str, Any]]:
+    """Split binary content into base64 blob payloads of chunk_size raw bytes."""
+    chunks = max(1, -(-len(data) // chunk_size))
+    payloads = []
+    for i in range(chunks):
+        payload: dict[str, Any] = {
+            "id": blob_id,
+            "size": len(data),
+            "encoding": BLOB_BASE64,
+            "data": base64.b64encode(data[i * chunk_size:(i + 1) * chunk_size]).decode("ascii"),
+            "chunk": i,
+            "chunks": chunks,
+        }
+        if name:
+            payload["name"] = name
+        if mime_type:
+            payload["mime_type"] = mime_type
+        payloads.append(payload)
+    return payloads
+
+
+def blob_file_payload(
+    blob_id: str,
+    path: str,
+    name: str | None = None,
+    mime_type: str | None = None,
+) -> dict[str, Any]:
+    """Create blob payload handing off a file written to the TUI's blob_dir."""
+    payload: dict[str, Any] = {"id": blob_id, "path": path}
+    if name:
+        payload["name"] = name
+    if mime_type:
+        payload["mime_type"] = mime_type
+    return payload
+
+
+def pong_payload(seq: int) -> dict[str, Any]:
+    """Create pong payload answering the TUI's ping numbered seq."""
+    return {"seq": seq}
+
+
+def scroll_to_payload(target: str) -> dict[str, Any]:
+    """Create scroll_to payload.
+
+    Args:
+        target: ID of the message to scroll to, or "top" or "bottom"
+    """
+    return {"target": target}
+
+
+def collapse_payload(target: str, summary: str | None = None) -> dict[str, Any]:
+    """Create collapse or expand payload.
+
+    Args:
+        target: ID of the message to collapse or expand
+        summary: Line shown in place of a collapsed message
+    """
+    payload: dict[str, Any] = {"target": target}
+    if summary:
+        payload["summary"] = summary
     return payload
 
 
+def input_suggestion_payload(draft: str, completion: str) -> dict[str, Any]:
+    """Create input suggestion payload.
+
+    Args:
+        draft: The user's draft being completed, from a typing event
+        completion: Text to add after the draft; empty withdraws the suggestion
+    """
+    return {"draft": draft, "completion": completion}
+
+
+def commands_payload(commands: list[dict[str, str]]) -> dict[str, Any]:
+    """Create commands payload.
+
+    Args:
+        commands: Slash commands, each with a "name" and optionally a
+            "description" and an "args" hint such as "<path>"
+    """
+    return {"commands": commands}
+
+
+def tool_payload(
+    tool_id: str,
+    name: str,
+    status: Literal["running", "done", "failed"] = "running",
+    detail: str | None = None,
+) -> dict[str, Any]:
+    """Create tool payload.
+
+    Args:
+        tool_id: ID of the call; later messages with it update the call
+        name: Name of the tool
+        status: "running", "done" or "failed"
+        detail: Short description, such as the arguments or the result
+    """
+    payload: dict[str, Any] = {"id": tool_id, "name": name, "status": status}
+    if detail:
+        payload["detail"] = detail
+    return payload
+
+
+# --- Fallbacks for TUIs without a message type ---
+
+def fallback_message(msg: Message) -> Message | None:
+    """Rewrite a message for a TUI that lacks its type: as markdown, or
+    errors as alerts and table artifacts as tables.
+
+    Returns None if the type has no fallback.
+    """
+    payload = msg.payload or {}
+    if msg.type == MessageType.ERROR.value:
+        title = f"Error: {payload['code']}" if payload.get("code") else "Error"
+        message = "\n\n".join(filter(None, [payload.get("message"), payload.get("details")]))
+        fallback = create_message(MessageType.ALERT, alert_payload(message, "error", title), msg.id)
+        fallback.agent_id = msg.agent_id
+        return fallback
+
+    title = payload.get("title")
+    parts = [f"**{title}**"] if title else []
+
+    if msg.type == MessageType.ARTIFACT.value:
+        if "table" in payload:
+            fallback = create_message(MessageType.TABLE, payload["table"], msg.id)
+            fallback.agent_id = msg.agent_id
+            return fallback
+        if "code" in payload:
+            code = payload["code"]
+            payload = {"markdown": f"```{code.get('language', '')}\n{code.get('code', '')}\n```"}
+        parts.append(payload.get("markdown", ""))
+    elif msg.type == MessageType.QRCODE.value:
+        parts.append(payload.get("data", ""))
+        if payload.get("caption"):
+            parts.append(payload["caption"])
+    elif msg.type == MessageType.MATH.value:
+        parts.append(f"```latex\n{payload.get('tex', '')}\n```")
+    elif msg.type == MessageType.MAP.value:
+        parts.append("\n".join(
+            f"- {p.get('label') or 'Point'}: {p.get('lat')}, {p.get('lon')}"
+            for p in payload.get("points", [])
+        ))
+    elif msg.type == MessageType.RICH.value:
+        data = {
+            mime: "".join(value) if isinstance(value, list) else str(value)
+            for mime, value in payload.get("data", {}).items()
+        }
+        if "text/markdown" in data:
+            parts.append(data["text/markdown"])
+        elif "text/plain" in data:
+            parts.append(f"```\n{data['text/plain']}\n```")
+        else:
+            return None
+    else:
+        return None
+    fallback = create_message(MessageType.MARKDOWN, markdown_payload("\n\n".join(parts)), msg.id)
+    fallback.agent_id = msg.agent_id
+    return fallback
+
+
+# --- Payload helpers for Go → Python ---
+
+def decode_attachment(payload: dict[str, Any]) -> bytes:
+    """Decode the file contents of a (reassembled) attachment payload."""
+    return base64.b64decode(payload.get("data", ""))
+
+
 # --- Message constructors ---
 
 def create_message(
@@ -93,6 +93,7 @@ class UIStream:
                     message=message,
                     percent=percent,
                     steps=steps,
+                    progress_id=self.component_id,
                 )
                 self._current_type = "progress"
         except BridgeError:
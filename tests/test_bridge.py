@@ -131,3 +131,319 @@ class TestTUIConfig:
         assert config.app_name == "My App"
         assert config.tagline == "Custom tagline"
         assert config.debug is True
+
+
+class TestTUIBridgeHello:
+    """Tests for adapting to the TUI's hello message."""
+
+    @pytest.mark.asyncio
+    async def test_degrades_unsupported_types(self):
+        """Test that types the TUI lacks are sent as markdown or dropped."""
+        import io
+        import json
+        from agentui.bridge.tui_bridge import TUIBridge
+        from agentui.protocol import MessageType, create_message, hello_payload
+
+        bridge = TUIBridge(TUIConfig())
+        assert bridge.tui_info is None
+        assert bridge.supports(MessageType.MATH)
+
+        hello = hello_payload()
+        hello["types"] = ["text", "markdown"]
+        await bridge._route_message(create_message(MessageType.HELLO, hello))
+        assert bridge.tui_info == hello
+        assert not bridge.supports(MessageType.MATH)
+
+        class FakeProcess:
+            stdin = io.StringIO()
+
+        bridge._process = FakeProcess()
+        await bridge._send_raw(create_message(MessageType.MATH, {"tex": "x^2"}))
+        await bridge._send_raw(create_message(MessageType.PROGRESS, {"message": "hi"}))
+
+        sent = [json.loads(line) for line in FakeProcess.stdin.getvalue().splitlines()]
+        assert [m["type"] for m in sent] == ["markdown"]
+        assert "x^2" in sent[0]["payload"]["content"]
+
+
+class TestTUIBridgeProtocolErrors:
+    """Tests for messages the TUI rejects in strict mode."""
+
+    @pytest.mark.asyncio
+    async def test_protocol_error_fails_request(self):
+        """Test that a protocol_error fails the request it names, with the fields at fault."""
+        import asyncio
+        from agentui.bridge.tui_bridge import TUIBridge
+        from agentui.exceptions import ProtocolError
+        from agentui.protocol import MessageType, create_message
+
+        bridge = TUIBridge(TUIConfig())
+        future = asyncio.get_running_loop().create_future()
+        bridge._pending_requests["req-1"] = future
+
+        await bridge._route_message(create_message(
+            MessageType.PROTOCOL_ERROR,
+            {
+                "type": "select",
+                "error": "select has no options",
+                "fields": [{"field": "options", "problem": "select has no options"}],
+            },
+            msg_id="req-1",
+        ))
+
+        with pytest.raises(ProtocolError, match="invalid select message \\(options: select has no options\\)"):
+            future.result()
+        assert bridge._event_queue.empty()
+
+
+class TestTUIBridgeBatch:
+    """Tests for sending messages applied together."""
+
+    @pytest.mark.asyncio
+    async def test_batch_on_one_line(self):
+        """Test that a batch is written as one JSON array, or one by one for older TUIs."""
+        import io
+        import json
+        from agentui.bridge.tui_bridge import TUIBridge
+        from agentui.protocol import MessageType, clear_payload, create_message, status_payload
+
+        batch = [
+            create_message(MessageType.CLEAR, clear_payload()),
+            create_message(MessageType.STATUS, status_payload("Ready")),
+        ]
+        for hello, want in [({"batch": True}, 1), ({}, 2)]:
+            bridge = TUIBridge(TUIConfig())
+            bridge._tui_hello = {"types": ["clear", "status"], **hello}
+
+            class FakeProcess:
+                stdin = io.StringIO()
+
+            bridge._process = FakeProcess()
+            await bridge._send_raw(batch)
+
+            lines = FakeProcess.stdin.getvalue().splitlines()
+            assert len(lines) == want
+            sent = json.loads(lines[0]) if want == 1 else [json.loads(line) for line in lines]
+            assert [m["type"] for m in sent] == ["clear", "status"]
+
+
+class TestTUIBridgeProtocolFD:
+    """Tests for running the protocol over an inherited file descriptor."""
+
+    @pytest.mark.asyncio
+    async def test_messages_use_the_socket(self, tmp_path):
+        """Test that messages travel over the socket, not the TUI's stdout."""
+        import stat
+        import sys
+        from agentui.bridge.tui_bridge import TUIBridge
+
+        # A stand-in TUI that greets over the descriptor, prints to stdout
+        # as a terminal UI would, and echoes text back as input
+        fake = tmp_path / "fake-tui"
+        fake.write_text(f"""#!{sys.executable}
+import json, socket, sys
+fd = int(sys.argv[sys.argv.index("--protocol-fd") + 1])
+channel = socket.socket(fileno=fd)
+reader, writer = channel.makefile("r"), channel.makefile("w")
+print("drawing the UI")
+def send(msg):
+    writer.write(json.dumps(msg) + "\\n")
+    writer.flush()
+send({{"type": "hello", "payload": {{"types": ["text"]}}}})
+for line in reader:
+    msg = json.loads(line)
+    if msg["type"] == "text":
+        send({{"type": "input", "payload": {{"content": msg["payload"]["content"]}}}})
+""")
+        fake.chmod(fake.stat().st_mode | stat.S_IEXEC)
+
+        bridge = TUIBridge(TUIConfig(tui_path=str(fake), protocol_fd=True))
+        await bridge.start()
+        try:
+            await bridge.send_text("echo me")
+            event = await asyncio.wait_for(anext(bridge.events()), timeout=5)
+            assert event.payload == {"content": "echo me"}
+            assert bridge.tui_info == {"types": ["text"]}
+        finally:
+            await bridge.stop()
+
+
+class TestTUIBridgeAgents:
+    """Tests for several agents sharing one TUI."""
+
+    @pytest.mark.asyncio
+    async def test_messages_carry_the_current_agent(self):
+        """Test that messages sent within agent() are addressed from that agent."""
+        import asyncio
+        from agentui.bridge.tui_bridge import TUIBridge
+
+        bridge = TUIBridge(TUIConfig())
+        bridge._running = True
+
+        async def worker(name: str) -> None:
+            with bridge.agent(name):
+                await bridge.send_text("working")
+
+        await bridge.send_text("plan")
+        with bridge.agent("orchestrator"):
+            await bridge.send_markdown("Starting workers")
+            await asyncio.gather(worker("worker-1"), worker("worker-2"))
+            await bridge.send_text("done")
+
+        sent = []
+        while not bridge._outgoing_queue.empty():
+            sent.append(bridge._outgoing_queue.get_nowait().agent_id)
+        assert sent == [None, "orchestrator", "worker-1", "worker-2", "orchestrator"]
+
+
+class TestTUIBridgeDashboard:
+    """Tests for dashboards fed region by region."""
+
+    @pytest.mark.asyncio
+    async def test_messages_carry_the_current_region(self):
+        """Test that messages sent within region() draw into that region."""
+        from agentui.bridge.tui_bridge import TUIBridge
+        from agentui.layout import LayoutRegion, UILayout
+
+        bridge = TUIBridge(TUIConfig())
+        bridge._running = True
+
+        await bridge.send_layout(UILayout().add_row(LayoutRegion("cpu"), LayoutRegion("log")))
+        with bridge.region("cpu"):
+            await bridge.send_progress("CPU", percent=40)
+        with bridge.region("log"):
+            await bridge.send_text("started\n")
+        await bridge.send_text("done")
+
+        sent = []
+        while not bridge._outgoing_queue.empty():
+            msg = bridge._outgoing_queue.get_nowait()
+            sent.append((msg.type, msg.region))
+        assert sent == [("layout", None), ("progress", "cpu"), ("text", "log"), ("text", None)]
+
+
+class TestTUIBridgePinned:
+    """Tests for pinning messages."""
+
+    @pytest.mark.asyncio
+    async def test_messages_sent_within_pinned_are_pinned(self):
+        """Test that only messages sent within pinned() are pinned."""
+        from agentui.bridge.tui_bridge import TUIBridge
+
+        bridge = TUIBridge(TUIConfig())
+        bridge._running = True
+
+        await bridge.send_markdown("working")
+        with bridge.pinned():
+            await bridge.send_markdown("result")
+        await bridge.send_markdown("more")
+
+        sent = []
+        while not bridge._outgoing_queue.empty():
+            sent.append(bridge._outgoing_queue.get_nowait().pinned)
+        assert sent == [None, True, None]
+
+
+class TestTUIBridgeBlob:
+    """Tests for sending binary content."""
+
+    @pytest.mark.asyncio
+    async def test_chunks_or_file_handoff(self, tmp_path):
+        """Test that blobs are handed off as files when the TUI offers it, else chunked."""
+        import base64
+        from agentui.bridge.tui_bridge import TUIBridge
+
+        data = bytes(range(256)) * 10
+        for hello, want in [({"blobs": ["base64"]}, 3), ({"blobs": ["base64", "file"], "blob_dir": str(tmp_path)}, 1)]:
+            bridge = TUIBridge(TUIConfig())
+            bridge._tui_hello = hello
+            bridge._running = True
+            blob_id = await bridge.send_blob(data, "data.bin", chunk_size=1000)
+
+            sent = []
+            while not bridge._outgoing_queue.empty():
+                sent.append(bridge._outgoing_queue.get_nowait())
+            assert len(sent) == want
+            assert all(m.type == "blob" and m.payload["id"] == blob_id for m in sent)
+            if "path" in sent[0].payload:
+                with open(sent[0].payload["path"], "rb") as f:
+                    assert f.read() == data
+                assert sent[0].payload["path"].startswith(str(tmp_path))
+            else:
+                assert b"".join(base64.b64decode(m.payload["data"]) for m in sent) == data
+
+
+class TestTUIBridgeFlowControl:
+    """Tests for honouring the TUI's flow control credits."""
+
+    @pytest.mark.asyncio
+    async def test_waits_for_credit(self):
+        """Test that messages are held back until the TUI grants credit."""
+        import asyncio
+        from agentui.bridge.tui_bridge import TUIBridge
+        from agentui.protocol import MessageType, create_message
+
+        bridge = TUIBridge(TUIConfig(flow_window=1))
+        await bridge._spend_credit()  # no flow control until the first grant
+
+        await bridge._route_message(
+            create_message(MessageType.READY_FOR_MORE, {"credits": 1, "window": 1})
+        )
+        await bridge._spend_credit()
+        waiting = asyncio.create_task(bridge._spend_credit())
+        await asyncio.sleep(0)
+        assert not waiting.done()
+
+        await bridge._route_message(
+            create_message(MessageType.READY_FOR_MORE, {"credits": 1, "window": 1})
+        )
+        await asyncio.wait_for(waiting, 1)
+        assert bridge._event_queue.empty()
+
+
+class TestTUIBridgeTyping:
+    """Tests for following the user's typing."""
+
+    @pytest.mark.asyncio
+    async def test_typing_events(self):
+        """Test that typing events update is_user_typing and are still queued."""
+        from agentui.bridge.tui_bridge import TUIBridge
+        from agentui.protocol import MessageType, create_message
+
+        bridge = TUIBridge(TUIConfig(typing_idle=2))
+        assert not bridge.is_user_typing
+
+        await bridge._route_message(create_message(MessageType.TYPING, {"state": "start"}))
+        assert bridge.is_user_typing
+        await bridge._route_message(create_message(MessageType.TYPING, {"state": "stop"}))
+        assert not bridge.is_user_typing
+
+        assert bridge._event_queue.qsize() == 2
+
+
+class TestTUIBridgeHeartbeat:
+    """Tests for answering the TUI's pings."""
+
+    @pytest.mark.asyncio
+    async def test_answers_ping(self):
+        """Test that a ping is answered with a pong carrying its ID and number."""
+        import io
+        import json
+        from agentui.bridge.tui_bridge import TUIBridge
+        from agentui.protocol import MessageType, create_message
+
+        bridge = TUIBridge(TUIConfig())
+
+        class FakeProcess:
+            stdin = io.StringIO()
+
+        bridge._process = FakeProcess()
+        await bridge._route_message(
+            create_message(MessageType.PING, {"seq": 7}, msg_id="tui-7")
+        )
+
+        sent = [json.loads(line) for line in FakeProcess.stdin.getvalue().splitlines()]
+        assert [(m["type"], m.get("id"), m["payload"]) for m in sent] == [
+            ("pong", "tui-7", {"seq": 7})
+        ]
+        assert bridge._event_queue.empty()
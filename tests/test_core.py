@@ -9,7 +9,7 @@ import pytest
 
 from agentui.core import AgentCore, ToolExecutionError
 from agentui.primitives import UITable, UICode, UIForm, UIConfirm, UIText, UIMarkdown
-from agentui.types import AgentConfig, ToolDefinition, ToolResult
+from agentui.types import AgentConfig, Message, ToolDefinition, ToolResult
 
 
 @pytest.fixture
@@ -703,6 +703,37 @@ class TestCancelation:
         assert core._running is False
 
 
+class TestRetry:
+    """Test retrying the last exchange."""
+
+    @pytest.mark.asyncio
+    async def test_retry_answers_last_input_again(self, mock_bridge):
+        """Test a retry forgets the last exchange and answers its input again."""
+        core = AgentCore(bridge=mock_bridge)
+        core.message_handler.add_user_message("first")
+        core.state.messages.append(Message(role="assistant", content="one"))
+        core.message_handler.add_user_message("second")
+        core.state.messages.append(Message(role="assistant", content="bad answer"))
+        core._respond = AsyncMock()
+
+        await core._handle_event(MagicMock(type="retry", payload={"input": "second"}))
+
+        assert [m.content for m in core.state.messages] == ["first", "one"]
+        core._respond.assert_awaited_once_with("second")
+
+    @pytest.mark.asyncio
+    async def test_retry_without_input_is_ignored(self, mock_bridge):
+        """Test a retry of failed work, which carries no input, changes nothing."""
+        core = AgentCore(bridge=mock_bridge)
+        core.message_handler.add_user_message("first")
+        core._respond = AsyncMock()
+
+        await core._handle_event(MagicMock(type="retry", payload={"code": "rate_limited"}))
+
+        assert len(core.state.messages) == 1
+        core._respond.assert_not_awaited()
+
+
 class TestProviderManagement:
     """Test provider initialization and management."""
 
@@ -17,7 +17,7 @@ from agentui.core import AgentCore
 from agentui.types import AgentConfig
 from agentui.component_catalog import ComponentCatalog
 from agentui.component_selector import ComponentSelector, prefer_component
-from agentui.layout import UILayout
+from agentui.layout import LayoutRegion, UILayout
 from agentui.primitives import UITable, UICode, UIProgress, UIAlert, UIMarkdown, UIText
 from agentui.protocol import MessageType
 from agentui.types import ToolDefinition
@@ -263,6 +263,20 @@ class TestPhase5Integration:
             assert "type" in comp
             assert "payload" in comp
 
+    def test_layout_rows_serialization(self):
+        """Test dashboard rows serialize as region dicts without unset fields."""
+        layout = (
+            UILayout(title="Ops")
+            .add_row(LayoutRegion("cpu", "CPU", span=2), LayoutRegion("mem"))
+            .add_row(LayoutRegion("log", "Log", height=10))
+        )
+
+        assert layout.to_dict()["rows"] == [
+            [{"id": "cpu", "title": "CPU", "span": 2}, {"id": "mem"}],
+            [{"id": "log", "title": "Log", "height": 10}],
+        ]
+        assert "rows" not in UILayout(title="Plain").to_dict()
+
 
 class TestEndToEndIntegration:
     """Test complete end-to-end integration of all phases."""
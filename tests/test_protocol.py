@@ -7,14 +7,32 @@ import pytest
 from agentui.protocol import (
     Message,
     MessageType,
+    artifact_payload,
+    blob_payloads,
     create_message,
     create_request,
+    clear_payload,
+    collapse_payload,
+    commands_payload,
+    confirm_payload,
     form_field,
+    error_payload,
+    fallback_message,
     form_payload,
+    metadata_payload,
+    mime_bundle,
+    hello_payload,
+    input_suggestion_payload,
+    question_payload,
+    rich_payload,
     table_payload,
     code_payload,
     text_payload,
+    tool_payload,
     progress_payload,
+    secret_payload,
+    select_payload,
+    PROTOCOL_VERSION,
 )
 
 
@@ -109,6 +127,11 @@ def test_code_payload():
     assert payload["line_numbers"] is True
 
 
+def test_code_payload_untagged():
+    """Test that code without a language leaves it for the TUI to guess."""
+    assert "language" not in code_payload("print('hello')")
+
+
 def test_progress_payload():
     """Test progress payload creation."""
     payload = progress_payload(
@@ -123,3 +146,287 @@ def test_progress_payload():
     assert payload["message"] == "Processing..."
     assert payload["percent"] == 50.0
     assert len(payload["steps"]) == 2
+    assert "eta" not in payload
+    assert progress_payload("Downloading...", eta=90.0)["eta"] == 90.0
+
+
+def test_secret_payload():
+    """Test secret payload creation."""
+    payload = secret_payload("API key", description="Used for requests")
+
+    assert payload["label"] == "API key"
+    assert payload["description"] == "Used for requests"
+    assert "placeholder" not in payload
+
+
+def test_select_payload_rich_options():
+    """Test that string options are upgraded to option objects."""
+    payload = select_payload(
+        "Model",
+        ["fast", {"label": "Smart", "value": "smart", "description": "Slower"}],
+    )
+
+    assert payload["options"][0] == {"label": "fast", "value": "fast"}
+    assert payload["options"][1]["description"] == "Slower"
+
+
+def test_prompt_timeouts():
+    """Test that prompts carry a timeout and default answer only when given."""
+    assert "timeout_seconds" not in confirm_payload("Deploy?")
+    payload = confirm_payload("Deploy?", timeout_seconds=30, default=True)
+    assert payload["timeout_seconds"] == 30
+    assert payload["default"] is True
+
+    payload = select_payload("Target", ["staging", "prod"], "staging", timeout_seconds=10)
+    assert payload["timeout_seconds"] == 10
+    assert payload["default"] == "staging"
+
+    payload = form_payload([form_field("name", "Name", default="x")], timeout_seconds=5)
+    assert payload["timeout_seconds"] == 5
+
+
+def test_metadata_payload():
+    """Test that metadata payloads leave out what was not used."""
+    assert metadata_payload() == {}
+    assert metadata_payload(1200, 340, 0.0042) == {
+        "tokens": {"input": 1200, "output": 340},
+        "cost": 0.0042,
+    }
+    assert metadata_payload(cost=0.5) == {"cost": 0.5}
+
+
+def test_clear_payload():
+    """Test that a clear target is only sent when given."""
+    assert clear_payload() == {"scope": "chat"}
+    assert clear_payload("by_id", "m3") == {"scope": "by_id", "target": "m3"}
+
+
+def test_collapse_payload():
+    """Test that a collapse summary is only sent when given."""
+    assert collapse_payload("step-3") == {"target": "step-3"}
+    assert collapse_payload("step-3", "Installed 42 packages") == {
+        "target": "step-3",
+        "summary": "Installed 42 packages",
+    }
+
+
+def test_input_suggestion_payload():
+    """Test that a suggestion names the draft it completes."""
+    assert input_suggestion_payload("Write a", " haiku about Go") == {
+        "draft": "Write a",
+        "completion": " haiku about Go",
+    }
+
+
+def test_commands_payload():
+    """Test that commands are sent as given."""
+    commands = [{"name": "deploy", "description": "Deploy the app", "args": "<env>"}]
+    assert commands_payload(commands) == {"commands": commands}
+    msg = create_message(MessageType.COMMANDS, commands_payload(commands))
+    assert msg.type == "commands"
+
+
+def test_tool_payload():
+    """Test that a tool call's detail is left out unless given."""
+    assert tool_payload("t1", "search") == {"id": "t1", "name": "search", "status": "running"}
+    assert tool_payload("t1", "search", "done", "3 results") == {
+        "id": "t1", "name": "search", "status": "done", "detail": "3 results",
+    }
+
+
+def test_blob_payloads():
+    """Test that blobs are split into base64 chunks."""
+    payloads = blob_payloads("b1", b"abcdefg", name="x.bin", chunk_size=3)
+    assert [p["data"] for p in payloads] == ["YWJj", "ZGVm", "Zw=="]
+    assert [p["chunk"] for p in payloads] == [0, 1, 2]
+    assert all(p["chunks"] == 3 and p["size"] == 7 and p["name"] == "x.bin" for p in payloads)
+
+    empty = blob_payloads("b2", b"")
+    assert len(empty) == 1 and empty[0]["data"] == ""
+
+
+def test_question_payload():
+    """Test that question options take the select option shapes."""
+    payload = question_payload(
+        "Which database?",
+        ["SQLite", {"label": "PostgreSQL", "value": "postgres"}],
+        other="Something else",
+    )
+    assert payload == {
+        "question": "Which database?",
+        "options": [
+            {"label": "SQLite", "value": "SQLite"},
+            {"label": "PostgreSQL", "value": "postgres"},
+        ],
+        "other": "Something else",
+    }
+
+
+def test_error_payload():
+    """Test that error payloads leave out unset fields."""
+    assert error_payload("Boom") == {"message": "Boom"}
+    assert error_payload("Rate limited", "rate_limited", "Try again in 20s", retryable=True) == {
+        "message": "Rate limited",
+        "code": "rate_limited",
+        "details": "Try again in 20s",
+        "retryable": True,
+    }
+
+
+def test_error_falls_back_to_alert():
+    """Test that TUIs without error messages get an error alert."""
+    msg = create_message(MessageType.ERROR, error_payload("Boom", "crash", "stack"))
+    fallback = fallback_message(msg)
+    assert fallback is not None
+    assert fallback.type == MessageType.ALERT.value
+    assert fallback.payload == {"message": "Boom\n\nstack", "severity": "error", "title": "Error: crash"}
+
+
+def test_artifact_payload():
+    """Test that artifacts hold exactly one kind of content."""
+    assert artifact_payload("Plan", markdown="# Plan") == {"title": "Plan", "markdown": "# Plan"}
+    assert artifact_payload(code="x = 1", language="python")["code"]["language"] == "python"
+    table = table_payload(["a"], [["1"]])
+    assert artifact_payload(table=table) == {"table": table}
+    with pytest.raises(ValueError):
+        artifact_payload("Empty")
+    with pytest.raises(ValueError):
+        artifact_payload(markdown="x", code="y")
+
+
+def test_artifact_falls_back():
+    """Test that TUIs without artifacts get them in the chat."""
+    code = create_message(MessageType.ARTIFACT, artifact_payload("Fix", code="x = 1", language="python"))
+    fallback = fallback_message(code)
+    assert fallback is not None and fallback.type == MessageType.MARKDOWN.value
+    assert fallback.payload["content"] == "**Fix**\n\n```python\nx = 1\n```"
+
+    table = create_message(MessageType.ARTIFACT, artifact_payload(table=table_payload(["a"], [["1"]])))
+    fallback = fallback_message(table)
+    assert fallback is not None and fallback.type == MessageType.TABLE.value
+
+
+def test_rich_payload():
+    """Test that rich payloads keep what the TUI accepts and encode images."""
+    data = {"text/plain": "x", "image/png": b"\x89PNG", "application/vnd.custom": "y"}
+    payload = rich_payload(data, accept=["image/png", "text/plain"])
+    assert payload == {"data": {"text/plain": "x", "image/png": "iVBORw=="}}
+    # Nothing accepted sends everything rather than nothing
+    assert rich_payload({"application/vnd.custom": "y"}, accept=["text/plain"])["data"] == {
+        "application/vnd.custom": "y"
+    }
+
+
+def test_mime_bundle():
+    """Test that objects give their representations like in Jupyter."""
+    class Chart:
+        def _repr_markdown_(self):
+            return "# Chart"
+
+        def _repr_png_(self):
+            return b"\x89PNG", {"width": 10}
+
+        def __repr__(self):
+            return "<Chart>"
+
+    assert mime_bundle(Chart()) == {"text/markdown": "# Chart", "image/png": b"\x89PNG", "text/plain": "<Chart>"}
+
+    class Bundled:
+        def _repr_mimebundle_(self, include=None, exclude=None):
+            return {"text/plain": "bundled"}, {}
+
+    assert mime_bundle(Bundled()) == {"text/plain": "bundled"}
+    assert mime_bundle({"text/plain": "as is"}) == {"text/plain": "as is"}
+
+
+def test_rich_falls_back():
+    """Test that TUIs without rich messages get markdown or plain text."""
+    msg = create_message(MessageType.RICH, rich_payload({"text/markdown": ["# A", "\nb"], "text/plain": "A"}))
+    fallback = fallback_message(msg)
+    assert fallback is not None and fallback.payload["content"] == "# A\nb"
+
+    msg = create_message(MessageType.RICH, rich_payload({"text/plain": "<Chart>", "image/png": b"x"}))
+    assert fallback_message(msg).payload["content"] == "```\n<Chart>\n```"
+
+    msg = create_message(MessageType.RICH, rich_payload({"image/png": b"x"}))
+    assert fallback_message(msg) is None
+
+
+def test_message_carries_version():
+    """Test that outgoing messages declare the protocol version."""
+    msg = create_message(MessageType.TEXT, text_payload("Hi"))
+    parsed = json.loads(msg.to_json())
+
+    assert parsed["version"] == PROTOCOL_VERSION
+
+
+def test_message_dict_roundtrip():
+    """Test the wire structure shared by the JSON and msgpack encodings."""
+    msg = create_message(MessageType.TEXT, text_payload("Hi " * 100))
+    data = msg.to_dict(compress_threshold=64)
+
+    assert data["compression"] == "gzip"
+    restored = Message.from_dict(data)
+    assert restored.type == "text"
+    assert restored.payload == msg.payload
+
+
+def test_request_timeout_roundtrip():
+    """Test that a request's timeout is sent along for the TUI."""
+    msg = create_request(MessageType.CONFIRM, {"message": "Deploy?"})
+    msg.timeout = 30.0
+    parsed = json.loads(msg.to_json())
+
+    assert parsed["timeout"] == 30.0
+    assert Message.from_json(msg.to_json()).timeout == 30.0
+    assert "timeout" not in create_message(MessageType.TEXT, text_payload("Hi")).to_dict()
+
+
+def test_agent_id_roundtrip():
+    """Test that the agent a message comes from is named in the envelope."""
+    msg = create_message(MessageType.TEXT, text_payload("Hi"))
+    assert "agent_id" not in msg.to_dict()
+
+    msg.agent_id = "worker-1"
+    assert json.loads(msg.to_json())["agent_id"] == "worker-1"
+    assert Message.from_json(msg.to_json()).agent_id == "worker-1"
+
+
+def test_region_roundtrip():
+    """Test that the dashboard region a message draws into is named in the envelope."""
+    msg = create_message(MessageType.PROGRESS, progress_payload("CPU", percent=40))
+    assert "region" not in msg.to_dict()
+
+    msg.region = "cpu"
+    assert json.loads(msg.to_json())["region"] == "cpu"
+    assert Message.from_json(msg.to_json()).region == "cpu"
+
+
+def test_pinned_roundtrip():
+    """Test that pinning is named in the envelope only when set."""
+    msg = create_message(MessageType.MARKDOWN, {"content": "Deployed"})
+    assert "pinned" not in msg.to_dict()
+
+    msg.pinned = True
+    assert json.loads(msg.to_json())["pinned"] is True
+    assert Message.from_json(msg.to_json()).pinned is True
+
+
+def test_hello_payload():
+    """Test that hello messages advertise the decodable compressions."""
+    msg = create_message(MessageType.HELLO, hello_payload())
+    parsed = json.loads(msg.to_json())
+
+    assert parsed["type"] == "hello"
+    assert parsed["payload"] == {"compression": ["gzip"]}
+    assert hello_payload(telemetry=False)["telemetry"] is False
+    assert hello_payload(agent={"name": "helper", "version": ""})["agent"] == {"name": "helper"}
+
+
+def test_message_to_msgpack():
+    """Test MessagePack serialization."""
+    msgpack = pytest.importorskip("msgpack")
+    msg = create_message(MessageType.TEXT, text_payload("Hello"))
+
+    restored = Message.from_dict(msgpack.unpackb(msg.to_msgpack()))
+    assert restored.payload == msg.payload